@@ -199,8 +199,8 @@ func (d *BasicLoopData) GetScratchPad() []*Iteration {
 }
 
 // SetScratchPad sets the iterations to be used in next iteration.
-// Sets the SGScratchpadLength gauge and publishes a CommonDiffEvent
-// if ExecutionContext is set.
+// Sets the SGScratchpadLength and SGScratchpadBytes gauges and
+// publishes a CommonDiffEvent if ExecutionContext is set.
 func (d *BasicLoopData) SetScratchPad(iterations []*Iteration) {
 	before := d.scratchpad
 	d.scratchpad = iterations
@@ -208,6 +208,9 @@ func (d *BasicLoopData) SetScratchPad(iterations []*Iteration) {
 		d.execCtx.Stats().SetGauge(
 			SGScratchpadLength, float64(len(iterations)),
 		)
+		d.execCtx.Stats().SetGauge(
+			SGScratchpadBytes, float64(ScratchpadBytes(iterations)),
+		)
 		d.execCtx.PublishScratchPadChange(before, iterations)
 	}
 }
@@ -274,6 +277,15 @@ type IterationMetadataKey string
 //	score, ok := gent.GetImportanceScore(iter)
 const IMKImportanceScore IterationMetadataKey = "gent:importance_score"
 
+// IMKReasoning is the string reasoning content the model
+// returned through its provider's native reasoning channel
+// (ContentChoice.ReasoningContent) for this iteration's
+// response. Set by AgentLoops that route native reasoning,
+// e.g. the react agent's WithNativeReasoning. It is not part
+// of the iteration's messages, so it is never sent back to
+// the model.
+const IMKReasoning IterationMetadataKey = "gent:reasoning"
+
 // ImportanceScorePinned is the minimum importance score for
 // an iteration to be considered "pinned" by the standard
 // compaction strategies. Pinned iterations are always
@@ -344,6 +356,32 @@ func (i *Iteration) GetMetadata(
 	return val, ok
 }
 
+// Clone returns a copy of the iteration. Messages, their parts slices and the metadata
+// map are copied, so changes to the copy do not affect the original. Content parts and
+// metadata values themselves are shared.
+func (i *Iteration) Clone() *Iteration {
+	clone := &Iteration{Origin: i.Origin}
+	if i.Messages != nil {
+		clone.Messages = make([]*MessageContent, len(i.Messages))
+		for j, msg := range i.Messages {
+			if msg == nil {
+				continue
+			}
+			clone.Messages[j] = &MessageContent{
+				Role:  msg.Role,
+				Parts: append([]ContentPart(nil), msg.Parts...),
+			}
+		}
+	}
+	if i.Metadata != nil {
+		clone.Metadata = make(map[IterationMetadataKey]any, len(i.Metadata))
+		for key, value := range i.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+	return clone
+}
+
 // GetImportanceScore is a convenience function that returns
 // the importance score for an iteration.
 // Returns (0, false) if the key is absent or not a float64.
@@ -407,4 +445,18 @@ type AgentLoopResult struct {
 	// Result is only set when Action is [LATerminate].
 	// This is a slice of ContentPart to support multimodal outputs.
 	Result []ContentPart
+
+	// TerminatedBy is the name of the Termination that accepted Result, if any. The
+	// Executor copies it to [ExecutionResult].TerminatedBy so callers can tell which
+	// answer section fired when an agent routes several sections to terminations.
+	TerminatedBy string
+
+	// TerminatedByTool is true when a successful call to a [TerminalTool] ended the
+	// loop. TerminatedBy then holds the tool name and Result the tool's output.
+	TerminatedByTool bool
+
+	// Candidates holds the validated candidate answers when the accepting Termination
+	// takes several of them (see [TerminationResult].Candidates). The Executor copies it
+	// to [ExecutionResult].Candidates.
+	Candidates []AnswerCandidate
 }
@@ -1,6 +1,7 @@
 package react
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -20,7 +21,8 @@ import (
 // Flow: Think -> Act -> Observe -> Repeat until termination.
 //
 // The message construction follows this structure:
-//  1. System prompt(s) - from SystemPromptBuilder (default: behavior, re_act, critical_rules, tools, output_format)
+//  1. System prompt(s) - from SystemPromptBuilder (default: behavior, re_act, critical_rules,
+//     tools, output_format, examples)
 //  2. Task message (user) - formatted task text + media
 //  3. Scratchpad messages - interleaved AI responses and observations from previous iterations
 //  4. BEGIN!/CONTINUE! (user) - signals start or continuation of the loop
@@ -29,34 +31,76 @@ import (
 type Agent struct {
 	behaviorAndContext  string
 	criticalRules       string
+	fewShotExamples     []Example
 	systemPromptBuilder SystemPromptBuilder
 	model               gent.Model
+	modelRouter         func(execCtx *gent.ExecutionContext) gent.Model
 	format              gent.TextFormat
+	fallbackFormat      gent.TextFormat
+	fallbackAfter       int
 	toolChain           gent.ToolChain
-	termination         gent.Termination
+	toolFilter          func(execCtx *gent.ExecutionContext) []string
+	terminations        []gent.Termination
 	thinkingSection     gent.TextSection
+	nativeReasoning     bool
 	timeProvider        gent.TimeProvider
 	useStreaming        bool
+	emptyResponseNudge  string
+	minIterations       int
+	minIterationsNudge  string
 }
 
+// DefaultEmptyResponseNudge is the observation sent when the model returns an empty
+// response. Override it with WithEmptyResponseNudge.
+const DefaultEmptyResponseNudge = "You produced no output; please respond using the " +
+	"required format."
+
+// DefaultMinIterationsNudge is the observation sent when the model answers before the
+// minimum number of iterations set with WithMinIterations. Override it with
+// WithMinIterationsNudge.
+const DefaultMinIterationsNudge = "Please gather more information before answering."
+
 // NewAgent creates a new Agent with the given model and default settings.
 // Defaults:
 //   - Format: format.NewXML()
 //   - ToolChain: toolchain.NewYAML()
 //   - Termination: termination.NewText("answer")
-//   - TimeProvider: gent.NewDefaultTimeProvider()
+//   - TimeProvider: the execution context's clock (see gent.ExecutionContext.SetClock)
 //   - SystemPromptBuilder: DefaultSystemPromptBuilder
+//   - EmptyResponseNudge: DefaultEmptyResponseNudge
+//   - MinIterationsNudge: DefaultMinIterationsNudge
 func NewAgent(model gent.Model) *Agent {
 	return &Agent{
 		model:               model,
 		format:              format.NewXML(),
 		toolChain:           toolchain.NewYAML(),
-		termination:         termination.NewText("answer"),
-		timeProvider:        gent.NewDefaultTimeProvider(),
+		terminations:        []gent.Termination{termination.NewText("answer")},
 		systemPromptBuilder: DefaultSystemPromptBuilder,
+		emptyResponseNudge:  DefaultEmptyResponseNudge,
+		minIterationsNudge:  DefaultMinIterationsNudge,
 	}
 }
 
+// WithModelRouter sets a function that picks the model for each iteration, e.g. a strong
+// model for planning and a cheaper one for the iterations that follow:
+//
+//	agent := react.NewAgent(cheap).WithModelRouter(func(execCtx *gent.ExecutionContext) gent.Model {
+//	    if execCtx.Iteration() == 1 {
+//	        return strong
+//	    }
+//	    return nil // the model given to NewAgent
+//	})
+//
+// The router runs once at the start of each Next, before the model call, and its model
+// serves every call of that iteration, including the reflection pass. Returning nil uses
+// the model given to NewAgent. Token stats are attributed to the name the chosen model
+// publishes in AfterModelCallEvent, so SCInputTokensFor and similar model-specific limits
+// only count the iterations routed to that model.
+func (r *Agent) WithModelRouter(router func(execCtx *gent.ExecutionContext) gent.Model) *Agent {
+	r.modelRouter = router
+	return r
+}
+
 // WithBehaviorAndContext sets behavior instructions and context to include in the system prompt.
 // This is passed to the SystemPromptBuilder and formatted as a "behavior" section.
 // Use WithSystemPromptBuilder() to completely replace how the system prompt is built.
@@ -72,6 +116,22 @@ func (r *Agent) WithCriticalRules(rules string) *Agent {
 	return r
 }
 
+// WithFewShotExamples sets worked examples to include in the system prompt. Each example's
+// response sections are rendered with the agent's TextFormat, so they always match the
+// output structure the model is asked to use.
+//
+// DefaultSystemPromptBuilder places them in an "examples" section at the end of the system
+// prompt, after behavior, critical_rules, available_tools and output_format, so the model
+// reads the rules and the format before seeing them applied. Custom builders receive the
+// rendered text in SystemPromptContext.Examples.
+//
+// Examples add to every model call's prompt; use FewShotIterations to count them toward a
+// token estimate.
+func (r *Agent) WithFewShotExamples(examples []Example) *Agent {
+	r.fewShotExamples = examples
+	return r
+}
+
 // WithSystemPromptBuilder sets a custom function to build the system prompt.
 // Use this for full control over the system prompt structure.
 // See DefaultSystemPromptBuilder for the expected behavior.
@@ -86,15 +146,79 @@ func (r *Agent) WithFormat(f gent.TextFormat) *Agent {
 	return r
 }
 
+// WithFallbackFormat makes the agent switch to a simpler format once the model keeps
+// failing the configured one. While SGFormatParseErrorConsecutive is at least
+// afterConsecutive, each iteration instructs the model with the fallback format and
+// parses its response with it. The first successful parse resets the gauge, so the
+// next iteration reverts to the configured format.
+//
+// afterConsecutive must not exceed the MaxValue of the SGFormatParseErrorConsecutive
+// limit (3 in gent.DefaultLimits), or execution terminates before the fallback engages.
+// Panics if afterConsecutive is less than 1.
+//
+//	agent := react.NewAgent(model).
+//	    WithFallbackFormat(format.NewMarkdown(), 2)
+func (r *Agent) WithFallbackFormat(f gent.TextFormat, afterConsecutive int) *Agent {
+	if afterConsecutive < 1 {
+		panic(fmt.Sprintf(
+			"react: WithFallbackFormat requires afterConsecutive >= 1, got %d",
+			afterConsecutive))
+	}
+	r.fallbackFormat = f
+	r.fallbackAfter = afterConsecutive
+	return r
+}
+
 // WithToolChain sets the tool chain.
 func (r *Agent) WithToolChain(tc gent.ToolChain) *Agent {
 	r.toolChain = tc
 	return r
 }
 
-// WithTermination sets the termination handler.
+// WithToolFilter sets a function that returns the names of the tools available in the
+// current iteration, e.g. to unlock refund tools only after identity verification:
+//
+//	agent.WithToolFilter(func(execCtx *gent.ExecutionContext) []string {
+//	    tools := []string{"lookup_order", "verify_identity"}
+//	    if execCtx.Stats().GetCounter("app:verified") > 0 {
+//	        tools = append(tools, "refund")
+//	    }
+//	    return tools
+//	})
+//
+// The filter runs before each model call and is applied with
+// gent.FilterableToolChain.SetAvailableTools, so the tool catalog, the tool list given
+// to the SystemPromptBuilder and the tools the model may call all reflect the subset.
+// Returning nil makes all tools available. Next returns an error if the ToolChain does
+// not implement gent.FilterableToolChain.
+func (r *Agent) WithToolFilter(filter func(execCtx *gent.ExecutionContext) []string) *Agent {
+	r.toolFilter = filter
+	return r
+}
+
+// WithTermination sets the termination handler, replacing any set with WithTerminations.
 func (r *Agent) WithTermination(t gent.Termination) *Agent {
-	r.termination = t
+	r.terminations = []gent.Termination{t}
+	return r
+}
+
+// WithTerminations registers several terminations, each keyed by its section name, so
+// the model can finish with different kinds of answer (e.g. "answer" and "escalate").
+// Whichever section the model emits is routed to its termination, and the accepted
+// result reports the section in [gent.AgentLoopResult].TerminatedBy. When the model emits
+// more than one, terminations are checked in registration order.
+//
+// Replaces any termination set with WithTermination. Panics if two terminations share a
+// section name.
+func (r *Agent) WithTerminations(ts ...gent.Termination) *Agent {
+	seen := make(map[string]bool, len(ts))
+	for _, t := range ts {
+		if seen[t.Name()] {
+			panic(fmt.Sprintf("react: duplicate termination section %q", t.Name()))
+		}
+		seen[t.Name()] = true
+	}
+	r.terminations = ts
 	return r
 }
 
@@ -105,8 +229,12 @@ func (r *Agent) WithTimeProvider(tp gent.TimeProvider) *Agent {
 	return r
 }
 
-// TimeProvider returns the current time provider.
+// TimeProvider returns the current time provider. When none was set, it returns a
+// provider reading the system clock; during a run the execution context's clock is used.
 func (r *Agent) TimeProvider() gent.TimeProvider {
+	if r.timeProvider == nil {
+		return gent.NewDefaultTimeProvider()
+	}
 	return r.timeProvider
 }
 
@@ -123,6 +251,58 @@ func (r *Agent) WithThinkingSection(s gent.TextSection) *Agent {
 	return r
 }
 
+// WithNativeReasoning routes the reasoning a model returns through its provider's native
+// reasoning channel ([gent.ContentChoice].ReasoningContent) into the thinking section, so
+// the model doesn't have to write its reasoning in the response text.
+//
+// The thinking section (the one set with WithThinking or WithThinkingSection, or a
+// virtual text section named "thinking" if none is) is left out of the output format
+// instructions. Each response's reasoning is parsed as that section's content, unless
+// the response also contains the section itself, and is recorded on the iteration
+// under [gent.IMKReasoning]; it is never sent back to the model.
+//
+// Default: false (native reasoning is ignored)
+func (r *Agent) WithNativeReasoning() *Agent {
+	r.nativeReasoning = true
+	return r
+}
+
+// WithEmptyResponseNudge sets the observation sent when the model returns an empty or
+// whitespace-only response.
+//
+// Empty responses are not parsed. Instead, the agent records the iteration with this
+// nudge as the observation and increments SCEmptyResponseTotal and
+// SGEmptyResponseConsecutive, both of which are limited by DefaultLimits.
+//
+// Default: DefaultEmptyResponseNudge
+func (r *Agent) WithEmptyResponseNudge(nudge string) *Agent {
+	r.emptyResponseNudge = nudge
+	return r
+}
+
+// WithMinIterations defers answers given before iteration k, so the model has to reason
+// or call tools for at least k iterations instead of guessing on the first one.
+//
+// A valid answer before iteration k is neither accepted nor rejected: validators and the
+// reflection pass don't run, no rejection is counted, and the loop continues with the
+// nudge set by WithMinIterationsNudge as the observation. The answer given on iteration
+// k or later is handled normally. Terminal tool calls are not affected.
+//
+// Default: 0 (answers are accepted on any iteration)
+func (r *Agent) WithMinIterations(k int) *Agent {
+	r.minIterations = k
+	return r
+}
+
+// WithMinIterationsNudge sets the observation sent when the model answers before the
+// iteration set with WithMinIterations.
+//
+// Default: DefaultMinIterationsNudge
+func (r *Agent) WithMinIterationsNudge(nudge string) *Agent {
+	r.minIterationsNudge = nudge
+	return r
+}
+
 // WithStreaming enables streaming mode for model calls.
 // When enabled and the model implements StreamingModel, responses are streamed
 // token-by-token. This allows ExecutionContext subscribers to receive chunks
@@ -151,18 +331,21 @@ func (r *Agent) RegisterTool(tool any) *Agent {
 // This order ensures that tool calls are always executed before termination. If the model
 // outputs both an action and an answer in the same response, the action takes priority.
 // This prevents premature termination when tools might fail or produce unexpected results.
+// The discarded answer is reported with a gent.AnswerDiscardedEvent.
 func (r *Agent) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
-	data := execCtx.Data()
-
-	// Register output sections and generate prompts
-	for _, section := range r.buildOutputSections() {
-		r.format.RegisterSection(section)
+	if fallback := r.withEscalatedFormat(execCtx); fallback != r {
+		return fallback.Next(execCtx)
 	}
-	outputPrompt := r.format.DescribeStructure()
-	toolsPrompt := r.toolChain.AvailableToolsPrompt()
+	if routed := r.withRoutedModel(execCtx); routed != r {
+		return routed.Next(execCtx)
+	}
+	data := execCtx.Data()
 
 	// Build messages for model call
-	messages := r.buildMessages(data, outputPrompt, toolsPrompt)
+	messages, err := r.BuildMessages(execCtx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate stream ID based on iteration for unique identification
 	streamId := fmt.Sprintf("iter-%d", execCtx.Iteration())
@@ -175,26 +358,68 @@ func (r *Agent) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, err
 	}
 
 	// Extract response content
-	responseContent := ""
+	responseContent, reasoning := "", ""
 	if len(response.Choices) > 0 {
 		responseContent = response.Choices[0].Content
+		reasoning = strings.TrimSpace(response.Choices[0].ReasoningContent)
+	}
+	if r.nativeReasoning && reasoning != "" {
+		defer recordReasoning(data, len(data.GetIterationHistory()), reasoning)
 	}
 
+	// Empty response: nudge the model instead of parsing nothing
+	if strings.TrimSpace(responseContent) == "" {
+		execCtx.Stats().IncrCounter(gent.SCEmptyResponseTotal, 1)
+		execCtx.Stats().IncrGauge(gent.SGEmptyResponseConsecutive, 1)
+
+		observation := r.buildObservation(r.emptyResponseNudge, nil)
+		r.addIteration(data, responseContent, observation)
+
+		return &gent.AgentLoopResult{
+			Action:     gent.LAContinue,
+			NextPrompt: observation,
+		}, nil
+	}
+	execCtx.Stats().ResetGauge(gent.SGEmptyResponseConsecutive)
+
 	// Parse complete response to identify all available sections
 	// The format handles tracing of parse errors and resetting consecutive counter
 	parsed, parseErr := r.format.Parse(execCtx, responseContent)
 
+	// Native reasoning stands in for a thinking section the response doesn't contain
+	if r.nativeReasoning && reasoning != "" {
+		name := r.thinkingSectionName()
+		if len(parsed[name]) == 0 {
+			if parsed == nil {
+				parsed = make(map[string][]string)
+			}
+			parsed[name] = []string{reasoning}
+		}
+	}
+
 	// Process thinking section if configured and present
 	// This validates structured thinking output and tracks section parse errors.
 	// Section parse errors don't stop the current iteration, but the executor
 	// will terminate if section parse error limits are exceeded.
+	// Sections with repair guidance contribute a hint to the next observation.
+	var repairs []string
 	if r.thinkingSection != nil {
-		if thinkingContents, ok := parsed[r.thinkingSection.Name()]; ok {
-			for _, content := range thinkingContents {
-				// ParseSection handles stats tracking:
-				// - On error: publishes ParseErrorEvent, increments total/consecutive counters
-				// - On success: resets consecutive counter
-				_, _ = r.thinkingSection.ParseSection(execCtx, content)
+		thinkingContents, dupErr := gent.SectionContents(
+			r.thinkingSection, parsed[r.thinkingSection.Name()])
+		if dupErr != nil {
+			raw := strings.Join(parsed[r.thinkingSection.Name()], "\n\n")
+			execCtx.PublishParseError(gent.ParseErrorTypeSection, raw, dupErr)
+			repairs = append(repairs, duplicateRepair(r.thinkingSection, dupErr))
+		}
+		for _, content := range thinkingContents {
+			// ParseSection handles stats tracking:
+			// - On error: publishes ParseErrorEvent, increments total/consecutive counters
+			// - On success: resets consecutive counter
+			_, sectionErr := r.thinkingSection.ParseSection(execCtx, content)
+			if sectionErr != nil {
+				if repair := sectionRepair(r.thinkingSection, sectionErr); repair != "" {
+					repairs = append(repairs, repair)
+				}
 			}
 		}
 	}
@@ -203,17 +428,33 @@ func (r *Agent) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, err
 	// This ensures tools are executed even if the model also outputs an answer
 	actionContents, hasActions := parsed[r.toolChain.Name()]
 	if hasActions && len(actionContents) > 0 {
+		// Any answer in the same response is discarded; report it for observability
+		r.publishDiscardedAnswers(execCtx, parsed, actionContents)
+
 		// Execute tool calls (automatically traced via execCtx)
-		observation := r.executeToolCalls(execCtx, actionContents)
+		toolOutput, terminal := r.executeToolCalls(execCtx, actionContents)
+		observation := r.buildObservation(toolOutput, repairs)
+		ephemeral := execCtx.TakeEphemeralObservations()
 
-		// Build iteration and update data
-		iter := r.buildIteration(responseContent, observation)
-		data.AddIterationHistory(iter)
+		// A successful terminal tool call is the final action: its output is the answer
+		if terminal != nil {
+			data.AddIterationHistory(r.buildIteration(responseContent, observation))
+			return &gent.AgentLoopResult{
+				Action:           gent.LATerminate,
+				Result:           []gent.ContentPart{llms.TextContent{Text: terminalAnswer(terminal)}},
+				TerminatedBy:     terminal.Name,
+				TerminatedByTool: true,
+			}, nil
+		}
 
-		// Add to scratchpad for next call
-		scratchpad := data.GetScratchPad()
-		scratchpad = append(scratchpad, iter)
-		data.SetScratchPad(scratchpad)
+		// Record iteration in history and scratchpad for next call
+		r.addIteration(data, responseContent, observation)
+
+		// Content attached by tools goes to the next model call only
+		if len(ephemeral) > 0 {
+			execCtx.EnqueueEphemeralMessage(llms.ChatMessageTypeHuman,
+				r.buildObservation(strings.Join(ephemeral, "\n\n"), nil))
+		}
 
 		return &gent.AgentLoopResult{
 			Action:     gent.LAContinue,
@@ -221,53 +462,92 @@ func (r *Agent) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, err
 		}, nil
 	}
 
-	// No actions present - check for termination
-	if terminationContents, ok := parsed[r.termination.Name()]; ok && len(terminationContents) > 0 {
-		var terminationParseErrors []string
-
-		for _, content := range terminationContents {
+	// No actions present - check terminations in registration order. The reflection pass
+	// runs at most once per iteration, on the first valid candidate.
+	var terminationParseErrors []string
+	reflected := false
+	for _, term := range r.terminations {
+		termContents, dupErr := gent.SectionContents(term, parsed[term.Name()])
+		if dupErr != nil {
+			raw := strings.Join(parsed[term.Name()], "\n\n")
+			execCtx.PublishParseError(gent.ParseErrorTypeTermination, raw, dupErr)
+			execCtx.Stats().IncrCounter(
+				gent.SCTerminationParseErrorFor+gent.StatKey(term.Name()), 1)
+			terminationParseErrors = append(terminationParseErrors,
+				fmt.Sprintf("Termination parse error: %v", dupErr))
+		}
+		for _, content := range termContents {
 			// First validate by calling ParseSection (traces errors for stats)
-			_, termParseErr := r.termination.ParseSection(execCtx, content)
+			_, termParseErr := term.ParseSection(execCtx, content)
+
+			// Too early to answer: defer it instead of accepting or rejecting it
+			if termParseErr == nil && execCtx.Iteration() < r.minIterations {
+				observation := r.buildObservation(r.minIterationsNudge, repairs)
+				r.addIteration(data, responseContent, observation)
+				return &gent.AgentLoopResult{
+					Action:     gent.LAContinue,
+					NextPrompt: observation,
+				}, nil
+			}
+
+			// A valid answer would terminate: the reflection pass may revise it first,
+			// and the revision must parse too
+			if termParseErr == nil && !reflected && execCtx.ReflectionPass().Enabled {
+				reflected = true
+				revised, err := r.reflect(execCtx, messages, responseContent, term.Name(), content)
+				if err != nil {
+					return nil, fmt.Errorf("reflection call failed: %w", err)
+				}
+				if revised != content {
+					content = revised
+					_, termParseErr = term.ParseSection(execCtx, content)
+				}
+			}
+
 			if termParseErr != nil {
+				execCtx.Stats().IncrCounter(
+					gent.SCTerminationParseErrorFor+gent.StatKey(term.Name()), 1)
 				terminationParseErrors = append(terminationParseErrors,
 					fmt.Sprintf("Termination parse error: %v\nContent: %s", termParseErr, content))
 				continue
 			}
 
 			// ParseSection succeeded, check if we should terminate
-			result := r.termination.ShouldTerminate(execCtx, content)
+			result := term.ShouldTerminate(execCtx, content)
 			switch result.Status {
 			case gent.TerminationAnswerAccepted:
 				// Add final iteration to history
 				iter := r.buildIteration(responseContent, "")
 				data.AddIterationHistory(iter)
 				return &gent.AgentLoopResult{
-					Action: gent.LATerminate,
-					Result: result.Content,
+					Action:       gent.LATerminate,
+					Result:       result.Content,
+					TerminatedBy: term.Name(),
+					Candidates:   result.Candidates,
 				}, nil
 
-			case gent.TerminationAnswerRejected:
-				// Build observation from rejection feedback
+			case gent.TerminationAnswerRejected, gent.TerminationAnswerContinued:
+				if result.Status == gent.TerminationAnswerRejected {
+					execCtx.Stats().IncrCounter(
+						gent.SCAnswerRejectedFor+gent.StatKey(term.Name()), 1)
+				}
+
+				// Build observation from rejection feedback or continuation guidance
 				var feedbackText string
 				for _, part := range result.Content {
 					if tc, ok := part.(llms.TextContent); ok {
 						feedbackText += tc.Text + "\n"
 					}
 				}
-				if feedbackText == "" {
+				if feedbackText == "" && result.Status == gent.TerminationAnswerContinued {
+					feedbackText = "Continue refining your answer."
+				} else if feedbackText == "" {
 					feedbackText = "Answer validation failed. Please try again."
 				}
 
-				observation := r.format.FormatSections([]gent.FormattedSection{
-					{Name: "observation", Content: strings.TrimSpace(feedbackText)},
-				})
-
-				iter := r.buildIteration(responseContent, observation)
-				data.AddIterationHistory(iter)
+				observation := r.buildObservation(strings.TrimSpace(feedbackText), repairs)
 
-				scratchpad := data.GetScratchPad()
-				scratchpad = append(scratchpad, iter)
-				data.SetScratchPad(scratchpad)
+				r.addIteration(data, responseContent, observation)
 
 				return &gent.AgentLoopResult{
 					Action:     gent.LAContinue,
@@ -279,27 +559,20 @@ func (r *Agent) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, err
 				continue
 			}
 		}
+	}
 
-		// If we had termination parse errors but no successful termination, feed back errors
-		if len(terminationParseErrors) > 0 {
-			errorContent := strings.Join(terminationParseErrors, "\n\n") +
-				"\n\nPlease try again with proper formatting."
-			observation := r.format.FormatSections([]gent.FormattedSection{
-				{Name: "observation", Content: errorContent},
-			})
-
-			iter := r.buildIteration(responseContent, observation)
-			data.AddIterationHistory(iter)
+	// If we had termination parse errors but no successful termination, feed back errors
+	if len(terminationParseErrors) > 0 {
+		errorContent := strings.Join(terminationParseErrors, "\n\n") +
+			"\n\nPlease try again with proper formatting."
+		observation := r.buildObservation(errorContent, repairs)
 
-			scratchpad := data.GetScratchPad()
-			scratchpad = append(scratchpad, iter)
-			data.SetScratchPad(scratchpad)
+		r.addIteration(data, responseContent, observation)
 
-			return &gent.AgentLoopResult{
-				Action:     gent.LAContinue,
-				NextPrompt: observation,
-			}, nil
-		}
+		return &gent.AgentLoopResult{
+			Action:     gent.LAContinue,
+			NextPrompt: observation,
+		}, nil
 	}
 
 	// Handle parse error - feed back to agent as observation to allow recovery
@@ -313,17 +586,10 @@ Your raw response was:
 
 Please try again with proper formatting.`, parseErr, responseContent)
 
-		observation := r.format.FormatSections([]gent.FormattedSection{
-			{Name: "observation", Content: errorContent},
-		})
-
-		// Build iteration with parse error feedback
-		iter := r.buildIteration(responseContent, observation)
-		data.AddIterationHistory(iter)
+		observation := r.buildObservation(errorContent, repairs)
 
-		scratchpad := data.GetScratchPad()
-		scratchpad = append(scratchpad, iter)
-		data.SetScratchPad(scratchpad)
+		// Record iteration with parse error feedback
+		r.addIteration(data, responseContent, observation)
 
 		return &gent.AgentLoopResult{
 			Action:     gent.LAContinue,
@@ -332,61 +598,218 @@ Please try again with proper formatting.`, parseErr, responseContent)
 	}
 
 	// No actions and no valid termination - continue loop with empty observation
+	// (unless a section needs repair guidance).
 	// This handles edge cases where the model didn't output a properly formatted response
-	iter := r.buildIteration(responseContent, "")
-	data.AddIterationHistory(iter)
-
-	scratchpad := data.GetScratchPad()
-	scratchpad = append(scratchpad, iter)
-	data.SetScratchPad(scratchpad)
+	observation := r.buildObservation("", repairs)
+	r.addIteration(data, responseContent, observation)
 
 	return &gent.AgentLoopResult{
 		Action:     gent.LAContinue,
-		NextPrompt: "",
+		NextPrompt: observation,
 	}, nil
 }
 
+// BuildMessages returns the messages Next would send to the model for the current state
+// of execCtx: the system prompt, the task, the scratchpad and the BEGIN!/CONTINUE!
+// message. It applies the tool filter and registers output sections exactly like Next,
+// but does not call the model or change the loop data.
+//
+// Use it to golden-test prompt rendering, so changes to templates, tool descriptions or
+// schema generation show up as test diffs. Panics if the task in execCtx has neither
+// text nor media, like Next.
+func (r *Agent) BuildMessages(execCtx *gent.ExecutionContext) ([]llms.MessageContent, error) {
+	if fallback := r.withEscalatedFormat(execCtx); fallback != r {
+		return fallback.BuildMessages(execCtx)
+	}
+	outputPrompt, toolsPrompt, err := r.preparePrompts(execCtx)
+	if err != nil {
+		return nil, err
+	}
+	return r.buildMessages(
+		execCtx.Data(), execCtx.Clock(), execCtx.ScratchpadLayout(), outputPrompt, toolsPrompt,
+	), nil
+}
+
+// BuildSystemPrompt returns the text of the system prompt Next would send to the model
+// for the current state of execCtx. When the SystemPromptBuilder returns several
+// messages, their text parts are joined with a blank line; media parts are left out.
+//
+// Like BuildMessages, it is meant for golden tests of prompt rendering:
+//
+//	prompt, err := agent.BuildSystemPrompt(execCtx)
+//	require.NoError(t, err)
+//	want, _ := os.ReadFile("testdata/system_prompt.golden")
+//	assert.Equal(t, string(want), prompt)
+func (r *Agent) BuildSystemPrompt(execCtx *gent.ExecutionContext) (string, error) {
+	outputPrompt, toolsPrompt, err := r.preparePrompts(execCtx)
+	if err != nil {
+		return "", err
+	}
+	var texts []string
+	systemMessages := r.systemPromptBuilder(
+		r.systemPromptContext(execCtx.Clock(), outputPrompt, toolsPrompt),
+	)
+	for _, msg := range systemMessages {
+		for _, part := range msg.Parts {
+			if tc, ok := part.(llms.TextContent); ok {
+				texts = append(texts, tc.Text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// BuildObservation returns the observation Next sends back to the model for the given
+// content, such as formatted tool results or validator feedback. Returns an empty string
+// if content is empty.
+func (r *Agent) BuildObservation(content string) string {
+	return r.buildObservation(content, nil)
+}
+
+// withEscalatedFormat returns a copy of the agent using the fallback format if
+// WithFallbackFormat's threshold of consecutive format parse errors is reached, and the
+// agent itself otherwise.
+func (r *Agent) withEscalatedFormat(execCtx *gent.ExecutionContext) *Agent {
+	if r.fallbackFormat == nil {
+		return r
+	}
+	consecutive := execCtx.Stats().GetGauge(gent.SGFormatParseErrorConsecutive)
+	if consecutive < float64(r.fallbackAfter) {
+		return r
+	}
+	escalated := *r
+	escalated.format = r.fallbackFormat
+	escalated.fallbackFormat = nil
+	return &escalated
+}
+
+// withRoutedModel returns a copy of the agent using the model chosen by WithModelRouter's
+// router for this iteration, and the agent itself if there is no router.
+func (r *Agent) withRoutedModel(execCtx *gent.ExecutionContext) *Agent {
+	if r.modelRouter == nil {
+		return r
+	}
+	routed := *r
+	routed.modelRouter = nil
+	if model := r.modelRouter(execCtx); model != nil {
+		routed.model = model
+	}
+	return &routed
+}
+
+// preparePrompts restricts the tools for this iteration, registers the output sections
+// and generates the output format and tool prompts.
+func (r *Agent) preparePrompts(execCtx *gent.ExecutionContext) (string, string, error) {
+	// Restrict the tools for this iteration before any prompt or schema is built
+	if err := r.applyToolFilter(execCtx); err != nil {
+		return "", "", err
+	}
+
+	// Register output sections and generate prompts
+	for _, section := range r.buildOutputSections() {
+		r.format.RegisterSection(section)
+	}
+	return r.format.DescribeStructure(), r.toolChain.AvailableToolsPrompt(), nil
+}
+
+// publishDiscardedAnswers publishes an AnswerDiscardedEvent for each termination section
+// with content in a response whose actions took priority.
+func (r *Agent) publishDiscardedAnswers(
+	execCtx *gent.ExecutionContext,
+	parsed map[string][]string,
+	actionContents []string,
+) {
+	for _, term := range r.terminations {
+		answer := strings.TrimSpace(strings.Join(parsed[term.Name()], "\n\n"))
+		if answer == "" {
+			continue
+		}
+		execCtx.PublishAnswerDiscarded(term.Name(), answer, actionContents)
+	}
+}
+
+// thinkingSectionName returns the name of the thinking section, or "thinking" for the
+// virtual section native reasoning uses when none is configured.
+func (r *Agent) thinkingSectionName() string {
+	if r.thinkingSection == nil {
+		return "thinking"
+	}
+	return r.thinkingSection.Name()
+}
+
+// recordReasoning sets [gent.IMKReasoning] on the iterations added to the history since
+// index from.
+func recordReasoning(data gent.LoopData, from int, reasoning string) {
+	history := data.GetIterationHistory()
+	for i := from; i < len(history); i++ {
+		history[i].SetMetadata(gent.IMKReasoning, reasoning)
+	}
+}
+
 // buildOutputSections constructs the list of output sections.
 func (r *Agent) buildOutputSections() []gent.TextOutputSection {
 	var sections []gent.TextOutputSection
 
-	// Add thinking section if configured
-	if r.thinkingSection != nil {
+	// Add thinking section if configured, unless native reasoning replaces it
+	if r.thinkingSection != nil && !r.nativeReasoning {
 		sections = append(sections, r.thinkingSection)
 	}
 
 	// Add tool chain section
 	sections = append(sections, r.toolChain)
 
-	// Add termination section
-	sections = append(sections, r.termination)
+	// Add termination sections
+	for _, t := range r.terminations {
+		sections = append(sections, t)
+	}
 
 	return sections
 }
 
+// applyToolFilter restricts the ToolChain to the tools returned by the tool filter, if
+// one is set.
+func (r *Agent) applyToolFilter(execCtx *gent.ExecutionContext) error {
+	if r.toolFilter == nil {
+		return nil
+	}
+	filterable, ok := r.toolChain.(gent.FilterableToolChain)
+	if !ok {
+		return fmt.Errorf(
+			"tool filter requires a ToolChain implementing gent.FilterableToolChain, got %T",
+			r.toolChain,
+		)
+	}
+	filterable.SetAvailableTools(r.toolFilter(execCtx))
+	return nil
+}
+
+// availableTools returns the ToolChain's tools if it implements gent.ToolCatalog.
+func (r *Agent) availableTools() []gent.ToolInfo {
+	if catalog, ok := r.toolChain.(gent.ToolCatalog); ok {
+		return catalog.Tools()
+	}
+	return nil
+}
+
 // buildMessages constructs the message list for the model call.
 // Message structure:
 //  1. System prompt (from SystemPromptBuilder) - typically 1 message
 //  2. Task (role: user) - x1, text + media parts, panics if both empty
-//  3. Scratchpad (N messages interleaved: role: AI, then role: human)
+//  3. Scratchpad (N messages interleaved: role: AI, then role: human), in layout order
 //  4. BEGIN!/CONTINUE! (role: user) - x1
 func (r *Agent) buildMessages(
 	data gent.LoopData,
+	clock gent.Clock,
+	layout gent.ScratchpadLayout,
 	outputPrompt string,
 	toolsPrompt string,
 ) []llms.MessageContent {
 	var messages []llms.MessageContent
 
 	// 1. System prompt(s) from builder
-	ctx := SystemPromptContext{
-		Format:             r.format,
-		BehaviorAndContext: r.behaviorAndContext,
-		CriticalRules:      r.criticalRules,
-		OutputPrompt:       outputPrompt,
-		ToolsPrompt:        toolsPrompt,
-		Time:               r.timeProvider,
-	}
-	systemMessages := r.systemPromptBuilder(ctx)
+	systemMessages := r.systemPromptBuilder(
+		r.systemPromptContext(clock, outputPrompt, toolsPrompt),
+	)
 	for _, msg := range systemMessages {
 		messages = append(messages, llms.MessageContent{
 			Role:  msg.Role,
@@ -398,7 +821,7 @@ func (r *Agent) buildMessages(
 	messages = append(messages, r.buildTaskMessage(data))
 
 	// 3. Scratchpad messages (interleaved AI and human messages)
-	scratchpad := data.GetScratchPad()
+	scratchpad := layout.Arrange(data.GetScratchPad())
 	for _, iter := range scratchpad {
 		for _, msg := range iter.Messages {
 			messages = append(messages, llms.MessageContent{
@@ -421,6 +844,28 @@ func (r *Agent) buildMessages(
 	return messages
 }
 
+// systemPromptContext returns the context passed to the SystemPromptBuilder. Without an
+// explicit time provider, template time follows clock.
+func (r *Agent) systemPromptContext(
+	clock gent.Clock,
+	outputPrompt, toolsPrompt string,
+) SystemPromptContext {
+	timeProvider := r.timeProvider
+	if timeProvider == nil {
+		timeProvider = gent.NewClockTimeProvider(clock)
+	}
+	return SystemPromptContext{
+		Format:             r.format,
+		BehaviorAndContext: r.behaviorAndContext,
+		CriticalRules:      r.criticalRules,
+		OutputPrompt:       outputPrompt,
+		ToolsPrompt:        toolsPrompt,
+		Examples:           renderExamples(r.format, r.fewShotExamples),
+		Tools:              r.availableTools(),
+		Time:               timeProvider,
+	}
+}
+
 // buildTaskMessage constructs the task message with text and media parts.
 // Panics if task is nil or has both empty text and no media.
 func (r *Agent) buildTaskMessage(data gent.LoopData) llms.MessageContent {
@@ -459,14 +904,26 @@ func toLLMParts(parts []gent.ContentPart) []llms.ContentPart {
 
 // executeToolCalls executes tool calls from the parsed action contents.
 // The result.Text contains formatted sections from the ToolChain. This method
-// collects all sections and wraps them in a single observation section.
+// collects all sections and returns them joined, ready to be wrapped by
+// buildObservation, along with the first successful terminal tool call, if any.
+// Every call in the response runs even when an earlier one is terminal.
 func (r *Agent) executeToolCalls(
 	execCtx *gent.ExecutionContext,
 	contents []string,
-) string {
+) (string, *gent.RawToolCallResult) {
 	var allSections []string
+	var terminal *gent.RawToolCallResult
+
+	calls, dupErr := gent.SectionContents(r.toolChain, contents)
+	if dupErr != nil {
+		execCtx.PublishParseError(
+			gent.ParseErrorTypeToolchain, strings.Join(contents, "\n\n"), dupErr)
+		return r.format.FormatSections([]gent.FormattedSection{
+			{Name: "error", Content: fmt.Sprintf("Error: %v", dupErr)},
+		}), nil
+	}
 
-	for _, content := range contents {
+	for _, content := range calls {
 		result, err := r.toolChain.Execute(execCtx, content, r.format)
 		if err != nil {
 			// Format error using the text format
@@ -480,21 +937,109 @@ func (r *Agent) executeToolCalls(
 		if result.Text != "" {
 			allSections = append(allSections, result.Text)
 		}
+		if terminal == nil && result.Raw != nil {
+			for _, callResult := range result.Raw.Results {
+				if callResult != nil && callResult.Terminal {
+					terminal = callResult
+					break
+				}
+			}
+		}
 
 		// TODO: Handle result.Media for multimodal support
 		// For now, media is not included in the observation text
 	}
 
-	if len(allSections) == 0 {
-		return ""
+	return strings.Join(allSections, "\n"), terminal
+}
+
+// terminalAnswer renders the output of a terminal tool call as the final answer:
+// strings as is, other values as JSON.
+func terminalAnswer(result *gent.RawToolCallResult) string {
+	if text, ok := result.Output.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(result.Output)
+	if err != nil {
+		return fmt.Sprint(result.Output)
 	}
+	return string(data)
+}
 
-	// Wrap all sections in a single observation
+// buildObservation wraps content and any section repair guidance in a single
+// observation section. Returns an empty string if there is nothing to observe.
+func (r *Agent) buildObservation(content string, repairs []string) string {
+	parts := make([]string, 0, len(repairs)+1)
+	if content != "" {
+		parts = append(parts, content)
+	}
+	parts = append(parts, repairs...)
+	if len(parts) == 0 {
+		return ""
+	}
 	return r.format.FormatSections([]gent.FormattedSection{
-		{Name: "observation", Content: strings.Join(allSections, "\n")},
+		{Name: "observation", Content: strings.Join(parts, "\n\n")},
 	})
 }
 
+// sectionRepair builds the repair hint for a section that failed to parse.
+// Returns an empty string if the section provides no repair guidance.
+func sectionRepair(s gent.TextSection, err error) string {
+	repairable, ok := s.(gent.RepairableSection)
+	if !ok || repairable.RepairGuidance() == "" {
+		return ""
+	}
+	return fmt.Sprintf("Section %q parse error: %v\n%s",
+		s.Name(), err, repairable.RepairGuidance())
+}
+
+// duplicateRepair describes a section that appeared more than once, with the
+// section's repair guidance if it has any.
+func duplicateRepair(s gent.TextSection, err error) string {
+	repair := fmt.Sprintf("Section %q parse error: %v", s.Name(), err)
+	if repairable, ok := s.(gent.RepairableSection); ok && repairable.RepairGuidance() != "" {
+		repair += "\n" + repairable.RepairGuidance()
+	}
+	return repair
+}
+
+// addIteration records an iteration in the history and appends it to the scratchpad.
+// The scratchpad copy has ephemeral sections removed from the response, so they don't
+// appear in later prompts.
+func (r *Agent) addIteration(data gent.LoopData, response, observation string) {
+	iter := r.buildIteration(response, observation)
+	data.AddIterationHistory(iter)
+
+	if promptResponse := r.removeEphemeralSections(response); promptResponse != response {
+		iter = r.buildIteration(promptResponse, observation)
+	}
+
+	scratchpad := data.GetScratchPad()
+	scratchpad = append(scratchpad, iter)
+	data.SetScratchPad(scratchpad)
+}
+
+// removeEphemeralSections removes sections implementing [gent.EphemeralSection] from the
+// response. The response is returned unchanged if the format can't remove sections.
+func (r *Agent) removeEphemeralSections(response string) string {
+	remover, ok := r.format.(gent.SectionRemover)
+	if !ok {
+		return response
+	}
+
+	var names []string
+	for _, section := range r.buildOutputSections() {
+		if ephemeral, ok := section.(gent.EphemeralSection); ok && ephemeral.Ephemeral() {
+			names = append(names, section.Name())
+		}
+	}
+	if len(names) == 0 {
+		return response
+	}
+
+	return remover.RemoveSections(response, names...)
+}
+
 // buildIteration creates an Iteration from response and observation.
 // The response is stored as AI role, and observation as Human role.
 // Note: We use Human role for observations because the text-based ReAct pattern
@@ -529,15 +1074,108 @@ func (r *Agent) callModel(
 	streamTopicId string,
 	messages []llms.MessageContent,
 ) (*gent.ContentResponse, error) {
+	options := r.callOptions()
+
 	// Check if streaming is enabled and model supports it
 	if r.useStreaming {
 		if streamingModel, ok := r.model.(gent.StreamingModel); ok {
-			return r.callModelStreaming(execCtx, streamingModel, streamId, streamTopicId, messages)
+			return r.callModelStreaming(
+				execCtx, streamingModel, streamId, streamTopicId, messages, options...,
+			)
 		}
 	}
 
 	// Fall back to non-streaming
-	return r.model.GenerateContent(execCtx, streamId, streamTopicId, messages)
+	return r.model.GenerateContent(execCtx, streamId, streamTopicId, messages, options...)
+}
+
+// callOptions returns the call options of every model call of an iteration: structured
+// output when supported, and the format's stop sequences.
+func (r *Agent) callOptions() []llms.CallOption {
+	options := r.structuredOutputOptions()
+	// LCGWrapper merges these with ModelParams.Stop rather than replacing them.
+	if stops := gent.FormatStopSequences(r.format); len(stops) > 0 {
+		options = append(options, llms.WithStopWords(stops))
+	}
+	return options
+}
+
+// reflect runs the reflection pass (see [gent.ReflectionPass]) on a candidate answer of
+// the termination section named section: one extra model call with the request
+// messages, the model's response and the reflection prompt, using the iteration's call
+// options. It returns the revised answer, or the candidate unchanged if the model
+// replied with nothing.
+func (r *Agent) reflect(
+	execCtx *gent.ExecutionContext,
+	messages []llms.MessageContent,
+	response string,
+	section string,
+	candidate string,
+) (string, error) {
+	prompt := execCtx.ReflectionPass().PromptFor(candidate)
+	reflectionMessages := append(messages[:len(messages):len(messages)],
+		llms.MessageContent{
+			Role:  llms.ChatMessageTypeAI,
+			Parts: []llms.ContentPart{llms.TextContent{Text: response}},
+		},
+		llms.MessageContent{
+			Role:  llms.ChatMessageTypeHuman,
+			Parts: []llms.ContentPart{llms.TextContent{Text: prompt}},
+		},
+	)
+
+	streamId := fmt.Sprintf("iter-%d-reflection", execCtx.Iteration())
+	reply, err := r.model.GenerateContent(
+		execCtx, streamId, "reflection", reflectionMessages, r.callOptions()...,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	execCtx.Stats().IncrCounter(gent.SCReflectionCalls, 1)
+	if reply == nil {
+		return candidate, nil
+	}
+	if reply.Info != nil {
+		execCtx.Stats().IncrCounter(gent.SCReflectionInputTokens, int64(reply.Info.InputTokens))
+		execCtx.Stats().IncrCounter(gent.SCReflectionOutputTokens, int64(reply.Info.OutputTokens))
+	}
+
+	var revised string
+	if len(reply.Choices) > 0 {
+		revised = strings.TrimSpace(reply.Choices[0].Content)
+	}
+	if revised == "" {
+		return candidate, nil
+	}
+
+	// A reply in the output format (always the case with structured output) carries
+	// the answer in its termination section
+	if parsed, parseErr := r.format.Parse(nil, revised); parseErr == nil {
+		if contents := parsed[section]; len(contents) > 0 && strings.TrimSpace(contents[0]) != "" {
+			revised = strings.TrimSpace(contents[0])
+		}
+	}
+	return revised, nil
+}
+
+// structuredOutputOptions returns the call options requesting constrained decoding when
+// both the model and the format support structured output. Otherwise it returns nil and
+// the model relies on the schema described in the prompt.
+func (r *Agent) structuredOutputOptions() []llms.CallOption {
+	model, ok := r.model.(gent.StructuredOutputModel)
+	if !ok || !model.SupportsStructuredOutput() {
+		return nil
+	}
+	structuredFormat, ok := r.format.(gent.StructuredOutputFormat)
+	if !ok {
+		return nil
+	}
+	schema := structuredFormat.OutputSchema()
+	if schema == nil {
+		return nil
+	}
+	return []llms.CallOption{gent.WithStructuredOutput(schema)}
 }
 
 // callModelStreaming calls the model with streaming and accumulates the response.
@@ -547,8 +1185,11 @@ func (r *Agent) callModelStreaming(
 	streamId string,
 	streamTopicId string,
 	messages []llms.MessageContent,
+	options ...llms.CallOption,
 ) (*gent.ContentResponse, error) {
-	stream, err := model.GenerateContentStream(execCtx, streamId, streamTopicId, messages)
+	stream, err := model.GenerateContentStream(
+		execCtx, streamId, streamTopicId, messages, options...,
+	)
 	if err != nil {
 		return nil, err
 	}
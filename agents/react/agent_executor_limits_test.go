@@ -3,13 +3,16 @@ package react
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/compaction"
 	"github.com/rickchristie/gent/executor"
 	"github.com/rickchristie/gent/internal/tt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
 )
 
 // ----------------------------------------------------------------------------
@@ -193,7 +196,7 @@ func TestExecutorLimits_Iterations(t *testing.T) {
 			tt.BeforeIter(0, 2),
 			tt.BeforeModelCall(0, 2, "parent"),
 			tt.AfterModelCall(0, 2, "parent", 100, 50),
-			tt.AfterIter(0, 2, tt.Terminate("parent done")),
+			tt.AfterIter(0, 2, tt.TerminateBy("answer", "parent done")),
 			tt.AfterExec(0, 2, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedParentEvents, tt.CollectLifecycleEvents(execCtx))
@@ -222,7 +225,7 @@ func TestExecutorLimits_Iterations(t *testing.T) {
 			tt.BeforeIter(1, 3),
 			tt.BeforeModelCall(1, 3, "child"),
 			tt.AfterModelCall(1, 3, "child", 100, 50),
-			tt.AfterIter(1, 3, tt.Terminate("child done")),
+			tt.AfterIter(1, 3, tt.TerminateBy("answer", "child done")),
 			tt.AfterExec(1, 3, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedChildEvents, tt.CollectLifecycleEvents(childCtx))
@@ -2127,6 +2130,186 @@ func TestExecutorLimits_FormatParseErrorConsecutive(t *testing.T) {
 	})
 }
 
+func TestExecutorLimits_FormatParseErrorIdenticalConsecutive(t *testing.T) {
+	type input struct {
+		responses []string
+	}
+
+	type expected struct {
+		reason    gent.TerminationReason
+		iteration int
+		exceeded  bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "stops when the model repeats the same malformed output",
+			input: input{responses: []string{"stuck", "stuck", "stuck"}},
+			expected: expected{
+				reason:    gent.TerminationLimitExceeded,
+				iteration: 2,
+				exceeded:  true,
+			},
+		},
+		{
+			name:  "tolerates varied malformed outputs",
+			input: input{responses: []string{"try 1", "try 2", "try 3"}},
+			expected: expected{
+				reason:    gent.TerminationSuccess,
+				iteration: 4,
+				exceeded:  false,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel()
+			format := tt.NewMockFormat()
+			for _, response := range tc.input.responses {
+				model.AddResponse(response, 100, 50)
+				format.AddParseError(gent.ErrNoSectionsFound)
+			}
+			model.AddResponse("<answer>done</answer>", 100, 50)
+			format.AddParseResult(map[string][]string{"answer": {"done"}})
+
+			limit := tt.ExactLimit(gent.SGFormatParseErrorIdenticalConsecutive, 1)
+
+			execCtx := runWithLimit(
+				t, model, format,
+				tt.NewMockToolChain(), tt.NewMockTermination(),
+				[]gent.Limit{limit},
+			)
+
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iteration, execCtx.Iteration())
+			if tc.expected.exceeded {
+				require.NotNil(t, execCtx.ExceededLimit())
+				assert.Equal(t, limit, *execCtx.ExceededLimit())
+			} else {
+				assert.Nil(t, execCtx.ExceededLimit())
+			}
+		})
+	}
+}
+
+func TestExecutorLimits_EmptyResponseConsecutive(t *testing.T) {
+	t.Run("stops when empty response consecutive exceeded", func(t *testing.T) {
+		// Empty responses are not parsed, so no ParseErrorEvent is published and the
+		// format is never called.
+		model := tt.NewMockModel().
+			AddResponse("", 100, 50).
+			AddResponse("  \n", 100, 50).
+			AddResponse("", 100, 50).
+			AddResponse("<answer>done</answer>", 100, 50)
+
+		format := tt.NewMockFormat().
+			AddParseResult(map[string][]string{"answer": {"done"}})
+		toolChain := tt.NewMockToolChain()
+		termination := tt.NewMockTermination()
+
+		limit := tt.ExactLimit(gent.SGEmptyResponseConsecutive, 2)
+		limits := []gent.Limit{limit}
+
+		execCtx := runWithLimit(t, model, format, toolChain, termination, limits)
+
+		assert.Equal(t, gent.TerminationLimitExceeded, execCtx.TerminationReason())
+		assert.Equal(t, limit, *execCtx.ExceededLimit())
+		assert.Equal(t, int64(3), execCtx.Stats().GetCounter(gent.SCEmptyResponseTotal))
+		assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCFormatParseErrorTotal))
+
+		nudgeObs := tt.Observation(format, DefaultEmptyResponseNudge)
+		expectedEvents := []gent.Event{
+			tt.BeforeExec(0, 0),
+			// Iteration 1: empty response (consecutive: 1)
+			tt.BeforeIter(0, 1),
+			tt.BeforeModelCall(0, 1, "test-model"),
+			tt.AfterModelCall(0, 1, "test-model", 100, 50),
+			tt.AfterIter(0, 1, tt.ContinueWithPrompt(nudgeObs)),
+			// Iteration 2: whitespace-only response (consecutive: 2)
+			tt.BeforeIter(0, 2),
+			tt.BeforeModelCall(0, 2, "test-model"),
+			tt.AfterModelCall(0, 2, "test-model", 100, 50),
+			tt.AfterIter(0, 2, tt.ContinueWithPrompt(nudgeObs)),
+			// Iteration 3: empty response (consecutive: 3 > limit 2)
+			tt.BeforeIter(0, 3),
+			tt.BeforeModelCall(0, 3, "test-model"),
+			tt.AfterModelCall(0, 3, "test-model", 100, 50),
+			tt.LimitExceeded(0, 3, limit, 3, gent.SGEmptyResponseConsecutive),
+			tt.AfterIter(0, 3, tt.ContinueWithPrompt(nudgeObs)),
+			tt.AfterExec(0, 3, gent.TerminationLimitExceeded),
+		}
+		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
+	})
+
+	t.Run("stops when empty response total exceeded", func(t *testing.T) {
+		// Empty responses alternate with tool calls, so the consecutive gauge never
+		// passes 1, but the total counter keeps growing.
+		model := tt.NewMockModel().
+			AddResponse("", 100, 50).
+			AddResponse("<action>tool: test</action>", 100, 50).
+			AddResponse("", 100, 50).
+			AddResponse("<action>tool: test</action>", 100, 50).
+			AddResponse("", 100, 50).
+			AddResponse("<answer>done</answer>", 100, 50)
+
+		format := tt.NewMockFormat().
+			AddParseResult(map[string][]string{"action": {"tool: test"}}).
+			AddParseResult(map[string][]string{"action": {"tool: test"}}).
+			AddParseResult(map[string][]string{"answer": {"done"}})
+		toolChain := tt.NewMockToolChain()
+		termination := tt.NewMockTermination()
+
+		limit := tt.ExactLimit(gent.SCEmptyResponseTotal, 2)
+		limits := []gent.Limit{tt.ExactLimit(gent.SGEmptyResponseConsecutive, 1), limit}
+
+		execCtx := runWithLimit(t, model, format, toolChain, termination, limits)
+
+		assert.Equal(t, gent.TerminationLimitExceeded, execCtx.TerminationReason())
+		assert.Equal(t, limit, *execCtx.ExceededLimit())
+		assert.Equal(t, 5, execCtx.Iteration())
+		assert.Equal(t, int64(3), execCtx.Stats().GetCounter(gent.SCEmptyResponseTotal))
+		assert.Equal(t, 1.0, execCtx.Stats().GetGauge(gent.SGEmptyResponseConsecutive))
+	})
+
+	t.Run("non-empty response resets consecutive count", func(t *testing.T) {
+		model := tt.NewMockModel().
+			AddResponse("", 100, 50).
+			AddResponse("<action>tool: test</action>", 100, 50).
+			AddResponse("", 100, 50).
+			AddResponse("<answer>done</answer>", 100, 50)
+
+		format := tt.NewMockFormat().
+			AddParseResult(map[string][]string{"action": {"tool: test"}}).
+			AddParseResult(map[string][]string{"answer": {"done"}})
+		toolChain := tt.NewMockToolChain()
+		termination := tt.NewMockTermination()
+
+		limits := []gent.Limit{tt.ExactLimit(gent.SGEmptyResponseConsecutive, 1)}
+
+		agent := NewAgent(model).
+			WithFormat(format).
+			WithToolChain(toolChain).
+			WithTermination(termination).
+			WithEmptyResponseNudge("Say something.")
+
+		data := gent.NewBasicLoopData(&gent.Task{Text: "Test task"})
+		execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+		execCtx.SetLimits(limits)
+		executor.New[*gent.BasicLoopData](agent, executor.DefaultConfig()).Execute(execCtx)
+
+		assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+		assert.Equal(t, int64(2), execCtx.Stats().GetCounter(gent.SCEmptyResponseTotal))
+		assert.Equal(t, 0.0, execCtx.Stats().GetGauge(gent.SGEmptyResponseConsecutive))
+		assert.Equal(t, tt.Observation(format, "Say something."),
+			data.GetScratchPad()[0].Messages[1].Parts[0].(llms.TextContent).Text)
+	})
+}
+
 func TestExecutorLimits_ToolchainParseErrorConsecutive(t *testing.T) {
 	t.Run("stops when toolchain parse error consecutive exceeded", func(t *testing.T) {
 		model := tt.NewMockModel().
@@ -3207,7 +3390,7 @@ func TestExecutorLimits_ConsecutiveReset_FormatParseError(t *testing.T) {
 			tt.BeforeIter(0, 5),
 			tt.BeforeModelCall(0, 5, "test-model"),
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
-			tt.AfterIter(0, 5, tt.Terminate("done")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "done")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3285,7 +3468,7 @@ func TestExecutorLimits_ConsecutiveReset_ToolchainParseError(t *testing.T) {
 			tt.BeforeIter(0, 5),
 			tt.BeforeModelCall(0, 5, "test-model"),
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
-			tt.AfterIter(0, 5, tt.Terminate("done")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "done")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3377,7 +3560,7 @@ func TestExecutorLimits_ConsecutiveReset_TerminationParseError(t *testing.T) {
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
 			tt.ValidatorCalled(0, 5, "test_validator", "accepted"),
 			tt.ValidatorResult(0, 5, "test_validator", "accepted", true, nil),
-			tt.AfterIter(0, 5, tt.Terminate("accepted")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "accepted")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3469,7 +3652,7 @@ func TestExecutorLimits_ConsecutiveReset_SectionParseError(t *testing.T) {
 			tt.BeforeIter(0, 5),
 			tt.BeforeModelCall(0, 5, "test-model"),
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
-			tt.AfterIter(0, 5, tt.Terminate("done")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "done")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3553,7 +3736,7 @@ func TestExecutorLimits_ConsecutiveReset_ToolCallError(t *testing.T) {
 			tt.BeforeIter(0, 5),
 			tt.BeforeModelCall(0, 5, "test-model"),
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
-			tt.AfterIter(0, 5, tt.Terminate("done")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "done")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3636,7 +3819,7 @@ func TestExecutorLimits_ConsecutiveReset_ToolCallErrorPerTool(t *testing.T) {
 			tt.BeforeIter(0, 5),
 			tt.BeforeModelCall(0, 5, "test-model"),
 			tt.AfterModelCall(0, 5, "test-model", 100, 50),
-			tt.AfterIter(0, 5, tt.Terminate("done")),
+			tt.AfterIter(0, 5, tt.TerminateBy("answer", "done")),
 			tt.AfterExec(0, 5, gent.TerminationSuccess),
 		}
 		tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
@@ -3803,6 +3986,70 @@ func TestExecutorLimits_AnswerRejectedTotal(t *testing.T) {
 	})
 }
 
+func TestExecutorLimits_AnswerContinuedWithoutRejection(t *testing.T) {
+	model := tt.NewMockModel().
+		AddResponse("<answer>draft 1</answer>", 100, 50).
+		AddResponse("<answer>draft 2</answer>", 100, 50).
+		AddResponse("<answer>final</answer>", 100, 50)
+
+	format := tt.NewMockFormat().
+		AddParseResult(map[string][]string{"answer": {"draft 1"}}).
+		AddParseResult(map[string][]string{"answer": {"draft 2"}}).
+		AddParseResult(map[string][]string{"answer": {"final"}})
+
+	guidance := []gent.FormattedSection{{Name: "guidance", Content: "Almost done, refine it"}}
+	termination := tt.NewMockTermination()
+	termination.SetValidator(tt.NewMockValidator("refiner").WithValidateFunc(
+		func(_ *gent.ExecutionContext, answer any) *gent.ValidationResult {
+			if answer == "final" {
+				return &gent.ValidationResult{Accepted: true}
+			}
+			return &gent.ValidationResult{ContinueWithoutRejection: true, Feedback: guidance}
+		},
+	))
+
+	// Any rejection would exceed these limits
+	limits := []gent.Limit{
+		tt.ExactLimit(gent.SCAnswerRejectedTotal, 0),
+		tt.PrefixLimit(gent.SCAnswerRejectedBy, 0),
+	}
+
+	execCtx := runWithLimit(t, model, format, tt.NewMockToolChain(), termination, limits)
+
+	assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+	assert.Nil(t, execCtx.ExceededLimit())
+	assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCAnswerRejectedTotal))
+	assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCAnswerRejectedBy+"refiner"))
+
+	continueObs := tt.ValidatorFeedbackObservation(format, guidance...)
+	expectedEvents := []gent.Event{
+		tt.BeforeExec(0, 0),
+		// Iteration 1: validator asks for another pass
+		tt.BeforeIter(0, 1),
+		tt.BeforeModelCall(0, 1, "test-model"),
+		tt.AfterModelCall(0, 1, "test-model", 100, 50),
+		tt.ValidatorCalled(0, 1, "refiner", "draft 1"),
+		tt.ValidatorContinue(0, 1, "refiner", "draft 1", guidance),
+		tt.AfterIter(0, 1, tt.ContinueWithPrompt(continueObs)),
+		// Iteration 2: validator asks for another pass
+		tt.BeforeIter(0, 2),
+		tt.BeforeModelCall(0, 2, "test-model"),
+		tt.AfterModelCall(0, 2, "test-model", 100, 50),
+		tt.ValidatorCalled(0, 2, "refiner", "draft 2"),
+		tt.ValidatorContinue(0, 2, "refiner", "draft 2", guidance),
+		tt.AfterIter(0, 2, tt.ContinueWithPrompt(continueObs)),
+		// Iteration 3: accepted
+		tt.BeforeIter(0, 3),
+		tt.BeforeModelCall(0, 3, "test-model"),
+		tt.AfterModelCall(0, 3, "test-model", 100, 50),
+		tt.ValidatorCalled(0, 3, "refiner", "final"),
+		tt.ValidatorResult(0, 3, "refiner", "final", true, nil),
+		tt.AfterIter(0, 3, tt.TerminateBy("answer", "final")),
+		tt.AfterExec(0, 3, gent.TerminationSuccess),
+	}
+	tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
+}
+
 // ----------------------------------------------------------------------------
 // Test: Answer rejection by validator limit (prefix)
 // ----------------------------------------------------------------------------
@@ -4445,7 +4692,7 @@ func TestExecutorLimits_GaugeNonPropagation(
 				),
 				tt.AfterIter(
 					0, 2,
-					tt.Terminate("parent done"),
+					tt.TerminateBy("answer", "parent done"),
 				),
 				tt.AfterExec(
 					0, 2,
@@ -5680,7 +5927,7 @@ func TestExecutorLimits_ScratchpadLength(t *testing.T) {
 				),
 				tt.AfterIter(
 					0, 2,
-					tt.Terminate("parent done"),
+					tt.TerminateBy("answer", "parent done"),
 				),
 				tt.AfterExec(
 					0, 2, gent.TerminationSuccess,
@@ -5741,7 +5988,7 @@ func TestExecutorLimits_ScratchpadLength(t *testing.T) {
 				),
 				tt.AfterIter(
 					1, 3,
-					tt.Terminate("child done"),
+					tt.TerminateBy("answer", "child done"),
 				),
 				tt.AfterExec(
 					1, 3, gent.TerminationSuccess,
@@ -5755,6 +6002,115 @@ func TestExecutorLimits_ScratchpadLength(t *testing.T) {
 	)
 }
 
+// ----------------------------------------------------------------------------
+// Test: Scratchpad bytes limit
+// ----------------------------------------------------------------------------
+
+func TestExecutorLimits_ScratchpadBytes(t *testing.T) {
+	type input struct {
+		observationBytes int
+		compact          bool
+	}
+
+	type expected struct {
+		reason      gent.TerminationReason
+		iteration   int
+		compactions int64
+		exceeded    bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "terminates without compaction",
+			input: input{observationBytes: 1000},
+			expected: expected{
+				reason:    gent.TerminationLimitExceeded,
+				iteration: 2,
+				exceeded:  true,
+			},
+		},
+		{
+			name:  "compacts instead of terminating",
+			input: input{observationBytes: 1000, compact: true},
+			expected: expected{
+				reason:      gent.TerminationSuccess,
+				iteration:   3,
+				compactions: 1,
+			},
+		},
+		{
+			name:  "terminates when compaction is not enough",
+			input: input{observationBytes: 2000, compact: true},
+			expected: expected{
+				reason:      gent.TerminationLimitExceeded,
+				iteration:   1,
+				compactions: 1,
+				exceeded:    true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Each tool call adds a large observation to the scratchpad
+			model := tt.NewMockModel()
+			format := tt.NewMockFormat()
+			for range 2 {
+				model.AddResponse("<action>tool: fetch</action>", 100, 50)
+				format.AddParseResult(map[string][]string{"action": {"tool: fetch"}})
+			}
+			model.AddResponse("<answer>done</answer>", 100, 50)
+			format.AddParseResult(map[string][]string{"answer": {"done"}})
+
+			observation := strings.Repeat("x", tc.input.observationBytes)
+			toolChain := tt.NewMockToolChain().
+				WithTool("fetch", func(map[string]any) (string, error) {
+					return observation, nil
+				})
+
+			agent := NewAgent(model).
+				WithFormat(format).
+				WithToolChain(toolChain).
+				WithTermination(tt.NewMockTermination())
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Test task"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			execCtx.SetLimits([]gent.Limit{gent.ScratchpadBytesLimit(1500)})
+			if tc.input.compact {
+				// The trigger never fires on its own; only the limit compacts
+				execCtx.SetCompaction(
+					compaction.NewStatThresholdTrigger().
+						OnGauge(gent.SGScratchpadLength, 100),
+					compaction.NewSlidingWindow(1),
+				)
+			}
+
+			executor.New[*gent.BasicLoopData](agent, executor.DefaultConfig()).
+				Execute(execCtx)
+
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iteration, execCtx.Iteration())
+			assert.Equal(t, tc.expected.compactions,
+				execCtx.Stats().GetCounter(gent.SCCompactions))
+			assert.Equal(t,
+				float64(gent.ScratchpadBytes(data.GetScratchPad())),
+				execCtx.Stats().GetGauge(gent.SGScratchpadBytes))
+			if !tc.expected.exceeded {
+				assert.Nil(t, execCtx.ExceededLimit())
+				return
+			}
+			require.NotNil(t, execCtx.ExceededLimit())
+			assert.Equal(t, gent.SGScratchpadBytes, execCtx.ExceededLimitKey())
+			assert.Equal(t, 1500.0, execCtx.ExceededLimit().MaxValue)
+			assert.Greater(t, execCtx.Stats().GetGauge(gent.SGScratchpadBytes), 1500.0)
+		})
+	}
+}
+
 // --------------------------------------------------------------------
 // Test: Last-iteration total token gauge limit
 // --------------------------------------------------------------------
@@ -6117,7 +6473,7 @@ func TestExecutorLimits_TotalTokensLastIteration(
 				),
 				tt.AfterIter(
 					0, 2,
-					tt.Terminate("parent done"),
+					tt.TerminateBy("answer", "parent done"),
 				),
 				tt.AfterExec(
 					0, 2,
@@ -6824,3 +7180,220 @@ func TestExecutorLimits_CodeExecutionErrorConsecutive(
 		},
 	)
 }
+
+// ----------------------------------------------------------------------------
+// Test: Section byte gauge limit
+// ----------------------------------------------------------------------------
+
+func TestExecutorLimits_SectionBytes(t *testing.T) {
+	// Thinking grows 10 -> 30 -> 60 bytes across three tool-call iterations.
+	thinking := []string{
+		strings.Repeat("a", 10),
+		strings.Repeat("b", 30),
+		strings.Repeat("c", 60),
+	}
+
+	type input struct {
+		maxValue float64
+	}
+
+	type expected struct {
+		reason    gent.TerminationReason
+		iteration int
+		gauge     float64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "stops when growing thinking section exceeds limit",
+			input: input{maxValue: 50},
+			expected: expected{
+				reason:    gent.TerminationLimitExceeded,
+				iteration: 3,
+				gauge:     60,
+			},
+		},
+		{
+			name:  "succeeds when thinking section stays within limit",
+			input: input{maxValue: 60},
+			expected: expected{
+				reason:    gent.TerminationSuccess,
+				iteration: 4,
+				gauge:     0,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel()
+			format := tt.NewMockFormat()
+			for _, text := range thinking {
+				model.AddResponse("<action>tool: test</action>", 100, 50)
+				format.AddParseResult(map[string][]string{
+					"thinking": {text},
+					"action":   {"tool: test"},
+				})
+			}
+			model.AddResponse("<answer>done</answer>", 100, 50)
+			format.AddParseResult(map[string][]string{"answer": {"done"}})
+
+			limit := tt.ExactLimit(
+				gent.SGSectionBytesFor+"thinking", tc.input.maxValue,
+			)
+
+			execCtx := runWithLimitAndThinking(
+				t, model, format,
+				tt.NewMockToolChain(), tt.NewMockTermination(),
+				tt.NewMockSection("thinking"),
+				[]gent.Limit{limit},
+			)
+
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iteration, execCtx.Iteration())
+			assert.Equal(t,
+				tc.expected.gauge,
+				execCtx.Stats().GetGauge(gent.SGSectionBytesFor+"thinking"),
+			)
+			if tc.expected.reason == gent.TerminationLimitExceeded {
+				require.NotNil(t, execCtx.ExceededLimit())
+				assert.Equal(t, limit, *execCtx.ExceededLimit())
+			} else {
+				assert.Nil(t, execCtx.ExceededLimit())
+			}
+		})
+	}
+}
+
+// --------------------------------------------------------------------
+// Test: Model-specific limits with a model router
+// --------------------------------------------------------------------
+
+func TestExecutorLimits_ModelRouter(t *testing.T) {
+	type input struct {
+		limits []gent.Limit
+	}
+
+	type expected struct {
+		reason       gent.TerminationReason
+		iteration    int
+		strongInput  int64
+		cheapInput   int64
+		strongOutput int64
+		cheapOutput  int64
+		exceededKey  gent.StatKey
+	}
+
+	// Odd iterations go to "strong" (100 in / 10 out), even ones to the default "cheap"
+	// model (10 in / 5 out). Iterations 1-3 call a tool, iteration 4 answers.
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "no limit attributes tokens per model",
+			expected: expected{
+				reason:       gent.TerminationSuccess,
+				iteration:    4,
+				strongInput:  200,
+				cheapInput:   20,
+				strongOutput: 20,
+				cheapOutput:  10,
+			},
+		},
+		{
+			name: "cheap model limit ignores strong model tokens",
+			input: input{
+				limits: []gent.Limit{tt.ExactLimit(gent.SCInputTokensFor+"cheap", 5)},
+			},
+			expected: expected{
+				reason:       gent.TerminationLimitExceeded,
+				iteration:    2,
+				strongInput:  100,
+				cheapInput:   10,
+				strongOutput: 10,
+				cheapOutput:  5,
+				exceededKey:  gent.SCInputTokensFor + "cheap",
+			},
+		},
+		{
+			name: "strong model limit ignores cheap model tokens",
+			input: input{
+				limits: []gent.Limit{tt.ExactLimit(gent.SCInputTokensFor+"strong", 150)},
+			},
+			expected: expected{
+				reason:       gent.TerminationLimitExceeded,
+				iteration:    3,
+				strongInput:  200,
+				cheapInput:   10,
+				strongOutput: 20,
+				cheapOutput:  5,
+				exceededKey:  gent.SCInputTokensFor + "strong",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			strong := tt.NewMockModel().WithName("strong").
+				AddResponse("<action>tool: test</action>", 100, 10).
+				AddResponse("<action>tool: test</action>", 100, 10)
+			cheap := tt.NewMockModel().WithName("cheap").
+				AddResponse("<action>tool: test</action>", 10, 5).
+				AddResponse("<answer>done</answer>", 10, 5)
+
+			format := tt.NewMockFormat()
+			for range 3 {
+				format.AddParseResult(map[string][]string{"action": {"tool: test"}})
+			}
+			format.AddParseResult(map[string][]string{"answer": {"done"}})
+			toolChain := tt.NewMockToolChain().
+				WithTool("test", func(map[string]any) (string, error) {
+					return "ok", nil
+				})
+
+			agent := NewAgent(cheap).
+				WithFormat(format).
+				WithToolChain(toolChain).
+				WithTermination(tt.NewMockTermination()).
+				WithModelRouter(func(execCtx *gent.ExecutionContext) gent.Model {
+					if execCtx.Iteration()%2 == 1 {
+						return strong
+					}
+					return nil
+				})
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Test task"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			execCtx.SetLimits(tc.input.limits)
+
+			executor.New[*gent.BasicLoopData](agent, executor.DefaultConfig()).
+				Execute(execCtx)
+
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iteration, execCtx.Iteration())
+			assert.Equal(t, tc.expected.strongInput,
+				stats.GetCounter(gent.SCInputTokensFor+"strong"))
+			assert.Equal(t, tc.expected.cheapInput,
+				stats.GetCounter(gent.SCInputTokensFor+"cheap"))
+			assert.Equal(t, tc.expected.strongOutput,
+				stats.GetCounter(gent.SCOutputTokensFor+"strong"))
+			assert.Equal(t, tc.expected.cheapOutput,
+				stats.GetCounter(gent.SCOutputTokensFor+"cheap"))
+			assert.Equal(t, tc.expected.strongInput+tc.expected.cheapInput,
+				stats.GetCounter(gent.SCInputTokens))
+			if tc.expected.exceededKey == "" {
+				assert.Nil(t, execCtx.ExceededLimit())
+				return
+			}
+			require.NotNil(t, execCtx.ExceededLimit())
+			assert.Equal(t, tc.expected.exceededKey, execCtx.ExceededLimitKey())
+		})
+	}
+}
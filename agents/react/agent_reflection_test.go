@@ -0,0 +1,302 @@
+package react
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/format"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/rickchristie/gent/termination"
+	"github.com/rickchristie/gent/toolchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// answerRecorder is an AnswerValidator that accepts every answer and records it.
+type answerRecorder struct {
+	answers []any
+}
+
+func (v *answerRecorder) Name() string { return "recorder" }
+
+func (v *answerRecorder) Validate(_ *gent.ExecutionContext, answer any) *gent.ValidationResult {
+	v.answers = append(v.answers, answer)
+	return &gent.ValidationResult{Accepted: true}
+}
+
+func TestAgent_ReflectionPass(t *testing.T) {
+	type input struct {
+		pass        *gent.ReflectionPass
+		contextPass gent.ReflectionPass
+	}
+
+	type expected struct {
+		modelCalls       int
+		answer           string
+		reflectionCalls  int64
+		reflectionInput  int64
+		reflectionOutput int64
+		inputTokens      int64
+		reflectionPrompt string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "disabled answers directly",
+			input: input{},
+			expected: expected{
+				modelCalls:  2,
+				answer:      "It ships Monday",
+				inputTokens: 20,
+			},
+		},
+		{
+			name:  "enabled revises the answer before validators",
+			input: input{pass: &gent.ReflectionPass{Enabled: true}},
+			expected: expected{
+				modelCalls:       3,
+				answer:           "Order 42 ships Monday.",
+				reflectionCalls:  1,
+				reflectionInput:  30,
+				reflectionOutput: 7,
+				inputTokens:      50,
+				reflectionPrompt: "Your candidate answer:\nIt ships Monday",
+			},
+		},
+		{
+			name:  "enabled on the context is kept",
+			input: input{contextPass: gent.ReflectionPass{Enabled: true}},
+			expected: expected{
+				modelCalls:       3,
+				answer:           "Order 42 ships Monday.",
+				reflectionCalls:  1,
+				reflectionInput:  30,
+				reflectionOutput: 7,
+				inputTokens:      50,
+				reflectionPrompt: "Your candidate answer:\nIt ships Monday",
+			},
+		},
+		{
+			name: "custom prompt",
+			input: input{pass: &gent.ReflectionPass{
+				Enabled: true,
+				Prompt:  "Critique and improve: %s",
+			}},
+			expected: expected{
+				modelCalls:       3,
+				answer:           "Order 42 ships Monday.",
+				reflectionCalls:  1,
+				reflectionInput:  30,
+				reflectionOutput: 7,
+				inputTokens:      50,
+				reflectionPrompt: "Critique and improve: It ships Monday",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Iteration 1 calls a tool (no reflection), iteration 2 answers
+			model := tt.NewMockModel().
+				AddResponse("<action>\ntool: lookup\n</action>", 10, 5).
+				AddResponse("<answer>It ships Monday</answer>", 10, 5).
+				AddResponse("Order 42 ships Monday.", 30, 7)
+			recorder := &answerRecorder{}
+			answer := termination.NewText("answer")
+			answer.SetValidator(recorder)
+			loop := NewAgent(model).
+				WithToolChain(toolchain.NewYAML().RegisterTool(gent.NewToolFunc(
+					"lookup", "Look up an order", nil,
+					func(_ context.Context, _ map[string]any) (string, error) {
+						return "ships monday", nil
+					},
+				))).
+				WithTermination(answer)
+			data := gent.NewBasicLoopData(&gent.Task{Text: "When does order 42 ship?"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			execCtx.SetReflectionPass(tc.input.contextPass)
+
+			executor.New[*gent.BasicLoopData](loop, executor.Config{
+				ReflectionPass: tc.input.pass,
+			}).Execute(execCtx)
+
+			require.NoError(t, execCtx.Error())
+			require.Len(t, model.CapturedMessages, tc.expected.modelCalls)
+			assert.Equal(t, []any{tc.expected.answer}, recorder.answers)
+			assert.Equal(t,
+				[]gent.ContentPart{llms.TextContent{Text: tc.expected.answer}},
+				execCtx.Result().Output)
+
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.reflectionCalls, stats.GetCounter(gent.SCReflectionCalls))
+			assert.Equal(t, tc.expected.reflectionInput,
+				stats.GetCounter(gent.SCReflectionInputTokens))
+			assert.Equal(t, tc.expected.reflectionOutput,
+				stats.GetCounter(gent.SCReflectionOutputTokens))
+			assert.Equal(t, tc.expected.inputTokens, stats.GetCounter(gent.SCInputTokens))
+
+			if tc.expected.reflectionPrompt != "" {
+				reflection := model.CapturedMessages[2]
+				last := reflection[len(reflection)-1]
+				assert.Equal(t, llms.ChatMessageTypeHuman, last.Role)
+				assert.Contains(t, last.Parts[0].(llms.TextContent).Text,
+					tc.expected.reflectionPrompt)
+				previous := reflection[len(reflection)-2]
+				assert.Equal(t, llms.ChatMessageTypeAI, previous.Role)
+			}
+		})
+	}
+}
+
+// nilReplyModel is a MockModel that returns a nil response without an error once the
+// first after calls were made.
+type nilReplyModel struct {
+	*tt.MockModel
+	after int
+}
+
+func (m *nilReplyModel) GenerateContent(
+	execCtx *gent.ExecutionContext,
+	streamId string,
+	streamTopicId string,
+	messages []llms.MessageContent,
+	opts ...llms.CallOption,
+) (*gent.ContentResponse, error) {
+	if m.CallCount() >= m.after {
+		return nil, nil
+	}
+	return m.MockModel.GenerateContent(execCtx, streamId, streamTopicId, messages, opts...)
+}
+
+func TestAgent_ReflectionPass_CallOptions(t *testing.T) {
+	type input struct {
+		format           gent.TextFormat
+		structuredOutput bool
+		responses        []string
+	}
+
+	type expected struct {
+		answer    string
+		jsonMode  bool
+		stopWords []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "stop sequences",
+			input: input{
+				format: format.NewXML().WithStopSections("observation"),
+				responses: []string{
+					"<answer>It ships Monday</answer>",
+					"Order 42 ships Monday.",
+				},
+			},
+			expected: expected{
+				answer:    "Order 42 ships Monday.",
+				stopWords: []string{"<observation>"},
+			},
+		},
+		{
+			name: "structured output reply is parsed",
+			input: input{
+				format:           format.NewJSON(),
+				structuredOutput: true,
+				responses: []string{
+					`{"answer": "It ships Monday"}`,
+					`{"answer": "Order 42 ships Monday."}`,
+				},
+			},
+			expected: expected{
+				answer:   "Order 42 ships Monday.",
+				jsonMode: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().WithStructuredOutput(tc.input.structuredOutput)
+			for _, response := range tc.input.responses {
+				model.AddResponse(response, 10, 5)
+			}
+			loop := NewAgent(model).
+				WithFormat(tc.input.format).
+				WithTermination(termination.NewText("answer"))
+			data := gent.NewBasicLoopData(&gent.Task{Text: "When does order 42 ship?"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+
+			executor.New[*gent.BasicLoopData](loop, executor.Config{
+				ReflectionPass: &gent.ReflectionPass{Enabled: true},
+			}).Execute(execCtx)
+
+			require.NoError(t, execCtx.Error())
+			assert.Equal(t,
+				[]gent.ContentPart{llms.TextContent{Text: tc.expected.answer}},
+				execCtx.Result().Output)
+
+			// The reflection call uses the same options as the iteration's call
+			require.Len(t, model.CapturedOptions, 2)
+			for _, opts := range model.CapturedOptions {
+				assert.Equal(t, tc.expected.jsonMode, opts.JSONMode)
+				assert.Equal(t, tc.expected.stopWords, opts.StopWords)
+			}
+		})
+	}
+}
+
+func TestAgent_ReflectionPass_OncePerIteration(t *testing.T) {
+	// The reflected "answer" no longer parses as JSON, so the loop moves on to "final",
+	// which must not be reflected again
+	model := tt.NewMockModel().
+		AddResponse("<answer>{\"eta\": \"monday\"}</answer>\n<final>It ships Monday</final>",
+			10, 5).
+		AddResponse("Order 42 ships Monday.", 30, 7)
+	loop := NewAgent(model).WithTerminations(
+		termination.NewJSON[map[string]string]("answer"),
+		termination.NewText("final"),
+	)
+	data := gent.NewBasicLoopData(&gent.Task{Text: "When does order 42 ship?"})
+	execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+
+	executor.New[*gent.BasicLoopData](loop, executor.Config{
+		ReflectionPass: &gent.ReflectionPass{Enabled: true},
+	}).Execute(execCtx)
+
+	require.NoError(t, execCtx.Error())
+	assert.Equal(t, 2, model.CallCount())
+	assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCReflectionCalls))
+	assert.Equal(t,
+		[]gent.ContentPart{llms.TextContent{Text: "It ships Monday"}},
+		execCtx.Result().Output)
+}
+
+func TestAgent_ReflectionPass_NilReply(t *testing.T) {
+	model := &nilReplyModel{
+		MockModel: tt.NewMockModel().AddResponse("<answer>It ships Monday</answer>", 10, 5),
+		after:     1,
+	}
+	loop := NewAgent(model).WithTermination(termination.NewText("answer"))
+	data := gent.NewBasicLoopData(&gent.Task{Text: "When does order 42 ship?"})
+	execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+
+	executor.New[*gent.BasicLoopData](loop, executor.Config{
+		ReflectionPass: &gent.ReflectionPass{Enabled: true},
+	}).Execute(execCtx)
+
+	require.NoError(t, execCtx.Error())
+	assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCReflectionCalls))
+	assert.Equal(t,
+		[]gent.ContentPart{llms.TextContent{Text: "It ships Monday"}},
+		execCtx.Result().Output)
+}
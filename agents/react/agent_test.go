@@ -9,6 +9,13 @@ import (
 	"time"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/clocktest"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/format"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/rickchristie/gent/section"
+	"github.com/rickchristie/gent/termination"
+	"github.com/rickchristie/gent/toolchain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
@@ -322,7 +329,13 @@ func TestAgent_BuildMessages(t *testing.T) {
 
 		data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
 
-		messages := loop.buildMessages(data, "output prompt", "tools prompt")
+		messages := loop.buildMessages(
+			data,
+			gent.SystemClock,
+			gent.ScratchpadChronologicalOldestFirst,
+			"output prompt",
+			"tools prompt",
+		)
 
 		// Expected structure: system, task, BEGIN!
 		require.Len(t, messages, 3, "expected 3 messages: system, task, BEGIN!")
@@ -371,7 +384,13 @@ func TestAgent_BuildMessages(t *testing.T) {
 		}
 		data.SetScratchPad([]*gent.Iteration{iter})
 
-		messages := loop.buildMessages(data, "output prompt", "tools prompt")
+		messages := loop.buildMessages(
+			data,
+			gent.SystemClock,
+			gent.ScratchpadChronologicalOldestFirst,
+			"output prompt",
+			"tools prompt",
+		)
 
 		// Expected: system, task, AI, observation, CONTINUE!
 		require.Len(t, messages, 5, "expected 5 messages: system, task, AI, observation, CONTINUE!")
@@ -402,7 +421,13 @@ func TestAgent_BuildMessages(t *testing.T) {
 		data := gent.NewBasicLoopData(&gent.Task{Text: "", Media: nil})
 
 		assert.Panics(t, func() {
-			loop.buildMessages(data, "output prompt", "tools prompt")
+			loop.buildMessages(
+				data,
+				gent.SystemClock,
+				gent.ScratchpadChronologicalOldestFirst,
+				"output prompt",
+				"tools prompt",
+			)
 		})
 	})
 
@@ -420,7 +445,13 @@ func TestAgent_BuildMessages(t *testing.T) {
 		data := gent.NewBasicLoopData(nil)
 
 		assert.Panics(t, func() {
-			loop.buildMessages(data, "output prompt", "tools prompt")
+			loop.buildMessages(
+				data,
+				gent.SystemClock,
+				gent.ScratchpadChronologicalOldestFirst,
+				"output prompt",
+				"tools prompt",
+			)
 		})
 	})
 }
@@ -455,6 +486,158 @@ func TestAgent_Next_Termination(t *testing.T) {
 	assert.Equal(t, "The answer is 42", tc2.Text)
 }
 
+func TestAgent_Next_MultipleTerminations(t *testing.T) {
+	type input struct {
+		parsed map[string][]string
+	}
+
+	type mocks struct {
+		answerParseErr  error
+		escalateRejects bool
+	}
+
+	type expected struct {
+		action       gent.LoopAction
+		terminatedBy string
+		result       string
+		counters     map[gent.StatKey]int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		mocks    mocks
+		expected expected
+	}{
+		{
+			name:  "answer section routes to answer termination",
+			input: input{parsed: map[string][]string{"answer": {"42"}}},
+			expected: expected{
+				action:       gent.LATerminate,
+				terminatedBy: "answer",
+				result:       "42",
+			},
+		},
+		{
+			name:  "escalate section routes to escalate termination",
+			input: input{parsed: map[string][]string{"escalate": {"needs a human"}}},
+			expected: expected{
+				action:       gent.LATerminate,
+				terminatedBy: "escalate",
+				result:       "needs a human",
+			},
+		},
+		{
+			name: "both sections use registration order",
+			input: input{parsed: map[string][]string{
+				"escalate": {"needs a human"},
+				"answer":   {"42"},
+			}},
+			expected: expected{
+				action:       gent.LATerminate,
+				terminatedBy: "answer",
+				result:       "42",
+			},
+		},
+		{
+			name: "parse error falls through to next termination",
+			input: input{parsed: map[string][]string{
+				"answer":   {"bad"},
+				"escalate": {"needs a human"},
+			}},
+			mocks: mocks{answerParseErr: gent.ErrInvalidJSON},
+			expected: expected{
+				action:       gent.LATerminate,
+				terminatedBy: "escalate",
+				result:       "needs a human",
+				counters: map[gent.StatKey]int64{
+					gent.SCTerminationParseErrorFor + "answer":   1,
+					gent.SCTerminationParseErrorFor + "escalate": 0,
+				},
+			},
+		},
+		{
+			name:  "parse error is attributed to its termination",
+			input: input{parsed: map[string][]string{"answer": {"bad"}}},
+			mocks: mocks{answerParseErr: gent.ErrInvalidJSON},
+			expected: expected{
+				action: gent.LAContinue,
+				counters: map[gent.StatKey]int64{
+					gent.SCTerminationParseErrorTotal:            1,
+					gent.SCTerminationParseErrorFor + "answer":   1,
+					gent.SCTerminationParseErrorFor + "escalate": 0,
+				},
+			},
+		},
+		{
+			name:  "rejection is attributed to its termination",
+			input: input{parsed: map[string][]string{"escalate": {"needs a human"}}},
+			mocks: mocks{escalateRejects: true},
+			expected: expected{
+				action: gent.LAContinue,
+				counters: map[gent.StatKey]int64{
+					gent.SCAnswerRejectedTotal:            1,
+					gent.SCAnswerRejectedFor + "escalate": 1,
+					gent.SCAnswerRejectedFor + "answer":   0,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			answer := newMockTermination().WithParseError(tc.mocks.answerParseErr)
+			escalate := termination.NewText("escalate")
+			if tc.mocks.escalateRejects {
+				escalate.SetValidator(tt.NewMockValidator("policy").WithReject(
+					gent.FormattedSection{Name: "error", Content: "Resolve it yourself."},
+				))
+			}
+
+			model := newMockModel(&gent.ContentResponse{
+				Choices: []*gent.ContentChoice{{Content: "response"}},
+			})
+			loop := NewAgent(model).
+				WithFormat(newMockFormat().WithParseResult(tc.input.parsed)).
+				WithToolChain(newMockToolChain()).
+				WithTerminations(answer, escalate)
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Help me"})
+			execCtx := newTestExecCtx(data)
+			result, err := loop.Next(execCtx)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.action, result.Action)
+			assert.Equal(t, tc.expected.terminatedBy, result.TerminatedBy)
+			if tc.expected.result != "" {
+				assert.Equal(t,
+					[]gent.ContentPart{llms.TextContent{Text: tc.expected.result}},
+					result.Result)
+			}
+			for key, value := range tc.expected.counters {
+				assert.Equal(t, value, execCtx.Stats().GetCounter(key), "counter %s", key)
+			}
+		})
+	}
+}
+
+func TestAgent_WithTerminations(t *testing.T) {
+	answer := termination.NewText("answer")
+	escalate := termination.NewText("escalate")
+	loop := NewAgent(newMockModel()).WithTerminations(answer, escalate)
+
+	sections := loop.buildOutputSections()
+	names := make([]string, len(sections))
+	for i, s := range sections {
+		names[i] = s.Name()
+	}
+	assert.Equal(t, []string{"action", "answer", "escalate"}, names)
+
+	assert.PanicsWithValue(t, `react: duplicate termination section "answer"`, func() {
+		NewAgent(newMockModel()).WithTerminations(answer, termination.NewText("answer"))
+	})
+}
+
 func TestAgent_Next_ToolExecution(t *testing.T) {
 	response := &gent.ContentResponse{
 		Choices: []*gent.ContentChoice{{Content: "<action>tool: search\nargs:\n  q: test</action>"}},
@@ -1051,6 +1234,97 @@ func TestAgent_Next_ActionTakesPriorityOverTermination(t *testing.T) {
 	}
 }
 
+func TestAgent_Next_AnswerDiscardedEvent(t *testing.T) {
+	toolResult := &gent.ToolChainResult{
+		Text: "<observation>\n<search>\nresults\n</search>\n</observation>",
+		Raw: &gent.RawToolChainResult{
+			Calls:   []*gent.ToolCall{{Name: "search"}},
+			Results: []*gent.RawToolCallResult{{Name: "search", Output: "results"}},
+			Errors:  []error{nil},
+		},
+	}
+
+	type input struct {
+		parsedSections map[string][]string
+	}
+
+	type expected struct {
+		events    []*gent.AnswerDiscardedEvent
+		discarded int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "action and answer fires the event",
+			input: input{parsedSections: map[string][]string{
+				"action": {"tool: search"},
+				"answer": {"It is sunny."},
+			}},
+			expected: expected{
+				events: []*gent.AnswerDiscardedEvent{{
+					Section: "answer",
+					Answer:  "It is sunny.",
+					Actions: []string{"tool: search"},
+				}},
+				discarded: 1,
+			},
+		},
+		{
+			name: "only action does not fire",
+			input: input{parsedSections: map[string][]string{
+				"action": {"tool: search"},
+			}},
+			expected: expected{discarded: 0},
+		},
+		{
+			name: "only answer does not fire",
+			input: input{parsedSections: map[string][]string{
+				"answer": {"It is sunny."},
+			}},
+			expected: expected{discarded: 0},
+		},
+		{
+			name: "blank answer next to action does not fire",
+			input: input{parsedSections: map[string][]string{
+				"action": {"tool: search"},
+				"answer": {"  "},
+			}},
+			expected: expected{discarded: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newMockModel(&gent.ContentResponse{
+				Choices: []*gent.ContentChoice{{Content: "response"}},
+			})
+			loop := NewAgent(model).
+				WithFormat(newMockFormat().WithParseResult(tt.input.parsedSections)).
+				WithToolChain(newMockToolChain().WithResults(toolResult)).
+				WithTermination(newMockTermination())
+
+			execCtx := newTestExecCtx(gent.NewBasicLoopData(&gent.Task{Text: "Weather?"}))
+			_, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			var events []*gent.AnswerDiscardedEvent
+			for _, event := range execCtx.Events() {
+				if discarded, ok := event.(*gent.AnswerDiscardedEvent); ok {
+					discarded.BaseEvent = gent.BaseEvent{}
+					events = append(events, discarded)
+				}
+			}
+			assert.Equal(t, tt.expected.events, events)
+			assert.Equal(t, tt.expected.discarded,
+				execCtx.Stats().GetCounter(gent.SCAnswersDiscarded))
+		})
+	}
+}
+
 func TestAgent_RegisterTool(t *testing.T) {
 	model := newMockModel()
 	tc := newMockToolChain()
@@ -1061,111 +1335,1285 @@ func TestAgent_RegisterTool(t *testing.T) {
 	assert.Equal(t, loop, result, "expected RegisterTool to return same loop for chaining")
 }
 
-func TestNewAgent_Defaults(t *testing.T) {
-	model := newMockModel()
-	loop := NewAgent(model)
+func TestAgent_Next_StructuredOutput(t *testing.T) {
+	type input struct {
+		supportsStructured bool
+		format             gent.TextFormat
+	}
 
-	assert.NotNil(t, loop.format, "expected default format to be set")
-	assert.NotNil(t, loop.toolChain, "expected default toolChain to be set")
-	assert.NotNil(t, loop.termination, "expected default termination to be set")
-	assert.NotNil(t, loop.timeProvider, "expected default timeProvider to be set")
-	assert.NotNil(t, loop.systemPromptBuilder, "expected default systemPromptBuilder to be set")
-}
+	type expected struct {
+		jsonMode  bool
+		hasSchema bool
+	}
 
-func TestAgent_WithTimeProvider(t *testing.T) {
-	model := newMockModel()
-	mockTime := gent.NewMockTimeProvider(time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC))
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "model and format support structured output",
+			input: input{
+				supportsStructured: true,
+				format:             format.NewJSON(),
+			},
+			expected: expected{jsonMode: true, hasSchema: true},
+		},
+		{
+			name: "model without capability falls back to prompt",
+			input: input{
+				supportsStructured: false,
+				format:             format.NewJSON(),
+			},
+			expected: expected{jsonMode: false, hasSchema: false},
+		},
+		{
+			name: "format without output schema",
+			input: input{
+				supportsStructured: true,
+				format:             format.NewXML(),
+			},
+			expected: expected{jsonMode: false, hasSchema: false},
+		},
+	}
 
-	loop := NewAgent(model).WithTimeProvider(mockTime)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().
+				WithStructuredOutput(tc.input.supportsStructured).
+				AddResponse(`{"answer": "42"}`, 10, 5)
 
-	assert.Equal(t, mockTime, loop.TimeProvider())
-	assert.Equal(t, "2025-06-15", loop.TimeProvider().Today())
-	assert.Equal(t, "Sunday", loop.TimeProvider().Weekday())
-}
+			loop := NewAgent(model).
+				WithFormat(tc.input.format).
+				WithToolChain(toolchain.NewJSON()).
+				WithTermination(termination.NewText("answer"))
 
-func TestDefaultSystemPromptBuilder(t *testing.T) {
-	t.Run("formats all sections with TextFormat", func(t *testing.T) {
-		format := newMockFormat()
-		ctx := SystemPromptContext{
-			Format:             format,
-			BehaviorAndContext: "You are helpful.",
-			CriticalRules:      "Never lie.",
-			OutputPrompt:       "Use XML tags.",
-			ToolsPrompt:        "Available tools: search",
-			Time:               gent.NewDefaultTimeProvider(),
-		}
+			data := gent.NewBasicLoopData(&gent.Task{Text: "What is 6*7?"})
+			_, err := loop.Next(newTestExecCtx(data))
+			require.NoError(t, err)
 
-		messages := DefaultSystemPromptBuilder(ctx)
+			require.Len(t, model.CapturedOptions, 1)
+			opts := model.CapturedOptions[0]
+			assert.Equal(t, tc.expected.jsonMode, opts.JSONMode)
+			assert.Equal(t, tc.expected.hasSchema, gent.StructuredOutputSchema(opts) != nil)
+		})
+	}
+}
 
-		require.Len(t, messages, 1)
-		assert.Equal(t, llms.ChatMessageTypeSystem, messages[0].Role)
+func TestAgent_Next_TerminalTool(t *testing.T) {
+	type receipt struct {
+		OrderID string `json:"order_id"`
+		Status  string `json:"status"`
+	}
 
-		// Check that content contains all formatted sections
-		content, ok := messages[0].Parts[0].(llms.TextContent)
-		require.True(t, ok)
+	type input struct {
+		response string
+	}
 
-		// All sections should be formatted with XML tags (from mockFormat)
-		assert.Contains(t, content.Text, "<behavior>")
-		assert.Contains(t, content.Text, "You are helpful.")
-		assert.Contains(t, content.Text, "<re_act>")
-		assert.Contains(t, content.Text, "<critical_rules>")
-		assert.Contains(t, content.Text, "Never lie.")
-		assert.Contains(t, content.Text, "<available_tools>")
-		assert.Contains(t, content.Text, "<output_format>")
-	})
+	type mocks struct {
+		submitErr error
+	}
 
-	t.Run("skips empty optional sections", func(t *testing.T) {
-		format := newMockFormat()
-		ctx := SystemPromptContext{
-			Format:             format,
-			BehaviorAndContext: "", // empty
-			CriticalRules:      "", // empty
-			OutputPrompt:       "Use XML.",
-			ToolsPrompt:        "tools",
-			Time:               gent.NewDefaultTimeProvider(),
-		}
+	type expected struct {
+		result     *gent.AgentLoopResult
+		historyLen int
+		scratchLen int
+	}
 
-		messages := DefaultSystemPromptBuilder(ctx)
+	tests := []struct {
+		name     string
+		input    input
+		mocks    mocks
+		expected expected
+	}{
+		{
+			name:  "successful terminal call ends the loop with its output",
+			input: input{response: "<action>\ntool: submit_order\nargs: {}\n</action>"},
+			expected: expected{
+				result: tt.TerminateByTool("submit_order",
+					`{"order_id":"O1","status":"submitted"}`),
+				historyLen: 1,
+			},
+		},
+		{
+			name: "terminal call ends the loop after the other calls run",
+			input: input{response: "<action>\n- tool: lookup\n  args: {}\n" +
+				"- tool: submit_order\n  args: {}\n</action>"},
+			expected: expected{
+				result: tt.TerminateByTool("submit_order",
+					`{"order_id":"O1","status":"submitted"}`),
+				historyLen: 1,
+			},
+		},
+		{
+			name:  "failed terminal call continues the loop",
+			input: input{response: "<action>\ntool: submit_order\nargs: {}\n</action>"},
+			mocks: mocks{submitErr: errors.New("payment declined")},
+			expected: expected{
+				result: tt.ContinueWithPrompt("<observation>\n<submit_order>\n" +
+					"Error: payment declined\n</submit_order>\n</observation>"),
+				historyLen: 1,
+				scratchLen: 1,
+			},
+		},
+		{
+			name:  "non-terminal call continues the loop",
+			input: input{response: "<action>\ntool: lookup\nargs: {}\n</action>"},
+			expected: expected{
+				result: tt.ContinueWithPrompt("<observation>\n<lookup>\nfound\n" +
+					"</lookup>\n</observation>"),
+				historyLen: 1,
+				scratchLen: 1,
+			},
+		},
+	}
 
-		require.Len(t, messages, 1)
-		content, ok := messages[0].Parts[0].(llms.TextContent)
-		require.True(t, ok)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			toolChain := toolchain.NewYAML().
+				RegisterTool(gent.NewToolFunc(
+					"lookup", "Look up the order", nil,
+					func(_ context.Context, _ map[string]any) (string, error) {
+						return "found", nil
+					},
+				)).
+				RegisterTool(gent.NewToolFunc(
+					"submit_order", "Submit the order", nil,
+					func(_ context.Context, _ map[string]any) (receipt, error) {
+						if tc.mocks.submitErr != nil {
+							return receipt{}, tc.mocks.submitErr
+						}
+						return receipt{OrderID: "O1", Status: "submitted"}, nil
+					},
+				).WithTerminal())
+			model := tt.NewMockModel().AddResponse(tc.input.response, 10, 5)
 
-		// Required sections should be present
-		assert.Contains(t, content.Text, "<re_act>")
-		assert.Contains(t, content.Text, "<available_tools>")
-		assert.Contains(t, content.Text, "<output_format>")
+			loop := NewAgent(model).
+				WithToolChain(toolChain).
+				WithTermination(termination.NewText("answer"))
 
-		// Optional sections should not be present when empty
-		assert.NotContains(t, content.Text, "<behavior>")
-		assert.NotContains(t, content.Text, "<critical_rules>")
-	})
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Buy the book"})
+			result, err := loop.Next(newTestExecCtx(data))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected.result, result)
+			assert.Len(t, data.GetIterationHistory(), tc.expected.historyLen)
+			assert.Len(t, data.GetScratchPad(), tc.expected.scratchLen)
+		})
+	}
 }
 
-func TestAgent_WithSystemPromptBuilder(t *testing.T) {
-	t.Run("custom builder is used", func(t *testing.T) {
-		model := newMockModel()
-		format := newMockFormat()
-		tc := newMockToolChain()
-		term := newMockTermination()
+func TestAgent_Next_StopSequences(t *testing.T) {
+	type input struct {
+		format    gent.TextFormat
+		response  string
+		stopWords bool // whether the model honors stop words
+	}
 
-		customBuilder := func(ctx SystemPromptContext) []gent.MessageContent {
-			return []gent.MessageContent{
-				{
-					Role:  llms.ChatMessageTypeSystem,
-					Parts: []gent.ContentPart{llms.TextContent{Text: "Custom system prompt"}},
-				},
-			}
-		}
+	type expected struct {
+		stopWords    []string
+		answer       string
+		outputTokens int64
+	}
 
-		loop := NewAgent(model).
-			WithFormat(format).
-			WithToolChain(tc).
-			WithTermination(term).
+	// The mock reports one output token per byte, so savings show up directly
+	xmlResponse := "<answer>42</answer>\n<observation>Correct!</observation>"
+	mdResponse := "# answer\n42\n# observation\nCorrect!"
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "xml stops before imagined observation",
+			input: input{
+				format:    format.NewXML().WithStopSections("observation"),
+				response:  xmlResponse,
+				stopWords: true,
+			},
+			expected: expected{
+				stopWords:    []string{"<observation>"},
+				answer:       "42",
+				outputTokens: int64(len("<answer>42</answer>\n")),
+			},
+		},
+		{
+			name: "markdown stops before imagined observation",
+			input: input{
+				format:    format.NewMarkdown().WithStopSections("observation"),
+				response:  mdResponse,
+				stopWords: true,
+			},
+			expected: expected{
+				stopWords:    []string{"\n# observation"},
+				answer:       "42",
+				outputTokens: int64(len("# answer\n42")),
+			},
+		},
+		{
+			name: "format without stops generates the whole response",
+			input: input{
+				format:    format.NewXML(),
+				response:  xmlResponse,
+				stopWords: true,
+			},
+			expected: expected{
+				answer:       "42",
+				outputTokens: int64(len(xmlResponse)),
+			},
+		},
+		{
+			name: "model ignoring stops generates the whole response",
+			input: input{
+				format:   format.NewXML().WithStopSections("observation"),
+				response: xmlResponse,
+			},
+			expected: expected{
+				stopWords:    []string{"<observation>"},
+				answer:       "42",
+				outputTokens: int64(len(xmlResponse)),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().
+				WithStopWordSupport(tc.input.stopWords).
+				AddResponse(tc.input.response, 10, len(tc.input.response))
+
+			loop := NewAgent(model).
+				WithFormat(tc.input.format).
+				WithTermination(termination.NewText("answer"))
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "What is 6*7?"})
+			execCtx := newTestExecCtx(data)
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			require.Len(t, model.CapturedOptions, 1)
+			assert.Equal(t, tc.expected.stopWords, model.CapturedOptions[0].StopWords)
+			assert.Equal(t, tt.TerminateBy("answer", tc.expected.answer), result)
+			assert.Equal(t, tc.expected.outputTokens,
+				execCtx.Stats().GetCounter(gent.SCOutputTokens))
+		})
+	}
+}
+
+func TestAgent_Next_SectionRepairGuidance(t *testing.T) {
+	type classification struct {
+		Category string `json:"category"`
+	}
+
+	type input struct {
+		response       string
+		repairGuidance string
+	}
+
+	type expected struct {
+		observation       string
+		sectionErrorTotal int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "failing section appends repair guidance to action observation",
+			input: input{
+				response: "<classify>{category: billing}</classify>\n" +
+					"<action>tool: lookup</action>",
+				repairGuidance: "Remember to quote the category string.",
+			},
+			expected: expected{
+				observation: "<observation>\n" +
+					"<lookup>\nfound\n</lookup>\n\n" +
+					"Section \"classify\" parse error: invalid JSON in section content: " +
+					"invalid character 'c' looking for beginning of object key string\n" +
+					"Remember to quote the category string.\n" +
+					"</observation>",
+				sectionErrorTotal: 1,
+			},
+		},
+		{
+			name: "failing section without other observation creates one",
+			input: input{
+				response:       "<classify>{category: billing}</classify>",
+				repairGuidance: "Remember to quote the category string.",
+			},
+			expected: expected{
+				observation: "<observation>\n" +
+					"Section \"classify\" parse error: invalid JSON in section content: " +
+					"invalid character 'c' looking for beginning of object key string\n" +
+					"Remember to quote the category string.\n" +
+					"</observation>",
+				sectionErrorTotal: 1,
+			},
+		},
+		{
+			name: "successful parse omits repair guidance",
+			input: input{
+				response: `<classify>{"category": "billing"}</classify>` +
+					"\n<action>tool: lookup</action>",
+				repairGuidance: "Remember to quote the category string.",
+			},
+			expected: expected{
+				observation:       "<observation>\n<lookup>\nfound\n</lookup>\n</observation>",
+				sectionErrorTotal: 0,
+			},
+		},
+		{
+			name: "failing section without repair guidance",
+			input: input{
+				response: "<classify>{category: billing}</classify>\n" +
+					"<action>tool: lookup</action>",
+			},
+			expected: expected{
+				observation:       "<observation>\n<lookup>\nfound\n</lookup>\n</observation>",
+				sectionErrorTotal: 1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().AddResponse(tc.input.response, 10, 5)
+			toolChain := toolchain.NewYAML().RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up the account", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "found", nil
+				},
+			))
+			classify := section.NewJSON[classification]("classify").
+				WithRepairGuidance(tc.input.repairGuidance)
+
+			loop := NewAgent(model).
+				WithToolChain(toolChain).
+				WithTermination(tt.NewMockTermination()).
+				WithThinkingSection(classify)
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Classify"})
+			execCtx := newTestExecCtx(data)
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			assert.Equal(t, gent.LAContinue, result.Action)
+			assert.Equal(t, tc.expected.observation, result.NextPrompt)
+			assert.Equal(t, tc.expected.sectionErrorTotal,
+				execCtx.Stats().GetCounter(gent.SCSectionParseErrorTotal))
+		})
+	}
+}
+
+func TestAgent_Next_ToolFilter(t *testing.T) {
+	type expected struct {
+		catalogHasRefund bool
+		observation      string
+	}
+
+	// Each step is one iteration; refund is unlocked once verify has been called
+	steps := []struct {
+		name     string
+		input    string // model response
+		expected expected
+	}{
+		{
+			name:  "refund hidden before verification",
+			input: "<action>tool: refund</action>",
+			expected: expected{
+				catalogHasRefund: false,
+				observation: "<observation>\n<refund>\nError: unknown tool \"refund\". " +
+					"Review the available tools section for valid tool names.\n" +
+					"</refund>\n</observation>",
+			},
+		},
+		{
+			name:  "verification",
+			input: "<action>tool: verify</action>",
+			expected: expected{
+				catalogHasRefund: false,
+				observation:      "<observation>\n<verify>\nverified\n</verify>\n</observation>",
+			},
+		},
+		{
+			name:  "refund unlocked after verification",
+			input: "<action>tool: refund</action>",
+			expected: expected{
+				catalogHasRefund: true,
+				observation:      "<observation>\n<refund>\nrefunded\n</refund>\n</observation>",
+			},
+		},
+	}
+
+	model := tt.NewMockModel()
+	for _, step := range steps {
+		model.AddResponse(step.input, 10, 5)
+	}
+	toolChain := toolchain.NewYAML().
+		RegisterTool(gent.NewToolFunc(
+			"verify", "Verify the user's identity", nil,
+			func(_ context.Context, _ map[string]any) (string, error) {
+				return "verified", nil
+			},
+		)).
+		RegisterTool(gent.NewToolFunc(
+			"refund", "Refund the order", nil,
+			func(_ context.Context, _ map[string]any) (string, error) {
+				return "refunded", nil
+			},
+		).WithCategory("billing"))
+
+	loop := NewAgent(model).
+		WithToolChain(toolChain).
+		WithTermination(tt.NewMockTermination()).
+		WithToolFilter(func(execCtx *gent.ExecutionContext) []string {
+			if execCtx.Stats().GetCounter(gent.SCToolCallsFor+"verify") > 0 {
+				return []string{"verify", "refund"}
+			}
+			return []string{"verify"}
+		})
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: "Refund my order"})
+	execCtx := newTestExecCtx(data)
+
+	for i, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			systemPrompt := model.CapturedMessages[i][0].Parts[0].(llms.TextContent).Text
+			assert.Equal(t, step.expected.catalogHasRefund,
+				strings.Contains(systemPrompt, "- refund: Refund the order"))
+			assert.Equal(t, step.expected.catalogHasRefund,
+				strings.Contains(systemPrompt, "Category: billing"))
+			assert.Equal(t, step.expected.observation, result.NextPrompt)
+		})
+	}
+}
+
+func TestAgent_Next_ObservationFormatter(t *testing.T) {
+	type input struct {
+		tool gent.Tool[map[string]any, any]
+	}
+
+	type expected struct {
+		observation string
+	}
+
+	heading := func(name string, output string) string {
+		return "## " + name + "\n" + output
+	}
+	lookup := func(output any) func(context.Context, map[string]any) (any, error) {
+		return func(context.Context, map[string]any) (any, error) {
+			return output, nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "default rendering without formatter",
+			input: input{
+				tool: gent.NewToolFunc("lookup", "Look up the order", nil, lookup("shipped")),
+			},
+			expected: expected{
+				observation: "<observation>\n<lookup>\nshipped\n</lookup>\n</observation>",
+			},
+		},
+		{
+			name: "formatter renders string output",
+			input: input{
+				tool: gent.NewToolFunc("lookup", "Look up the order", nil, lookup("shipped")).
+					WithObservationFormatter(heading),
+			},
+			expected: expected{
+				observation: "<observation>\n<lookup>\n## lookup\nshipped\n</lookup>\n" +
+					"</observation>",
+			},
+		},
+		{
+			name: "formatter receives default rendering of structured output",
+			input: input{
+				tool: gent.NewToolFunc("lookup", "Look up the order", nil,
+					lookup(map[string]any{"status": "shipped"})).
+					WithObservationFormatter(heading),
+			},
+			expected: expected{
+				observation: "<observation>\n<lookup>\n## lookup\nstatus: shipped\n" +
+					"</lookup>\n</observation>",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().
+				AddResponse("<action>tool: lookup</action>", 10, 5).
+				AddResponse("<answer>done</answer>", 10, 5)
+			loop := NewAgent(model).
+				WithToolChain(toolchain.NewYAML().RegisterTool(tc.input.tool)).
+				WithTermination(tt.NewMockTermination())
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Where is my order?"})
+			execCtx := newTestExecCtx(data)
+			executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).
+				Execute(execCtx)
+			require.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+
+			// The observation comes right before the second model call's CONTINUE! message
+			require.Len(t, model.CapturedMessages, 2)
+			prompt := model.CapturedMessages[1]
+			var promptText []string
+			for _, part := range prompt[len(prompt)-2].Parts {
+				promptText = append(promptText, part.(llms.TextContent).Text)
+			}
+			assert.Contains(t, strings.Join(promptText, "\n"), tc.expected.observation)
+		})
+	}
+}
+
+func TestAgent_Next_EphemeralObservation(t *testing.T) {
+	const reference = "REFERENCE: full refund policy text"
+	const attached = "<observation>\n" + reference + "\n</observation>"
+
+	type expected struct {
+		lastMessage  string
+		hasReference bool
+		observation  string
+	}
+
+	steps := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name:  "tool attaches reference material",
+			input: "<action>tool: policy</action>",
+			expected: expected{
+				lastMessage:  "BEGIN!",
+				hasReference: false,
+				observation:  "<observation>\n<policy>\nloaded policy\n</policy>\n</observation>",
+			},
+		},
+		{
+			name:  "next prompt carries it once",
+			input: "<action>tool: echo</action>",
+			expected: expected{
+				lastMessage:  attached,
+				hasReference: true,
+				observation:  "<observation>\n<echo>\nok\n</echo>\n</observation>",
+			},
+		},
+		{
+			name:  "later prompts no longer include it",
+			input: "<action>tool: echo</action>",
+			expected: expected{
+				lastMessage:  "CONTINUE!",
+				hasReference: false,
+				observation:  "<observation>\n<echo>\nok\n</echo>\n</observation>",
+			},
+		},
+	}
+
+	model := tt.NewMockModel()
+	for _, step := range steps {
+		model.AddResponse(step.input, 10, 5)
+	}
+	toolChain := toolchain.NewYAML().
+		RegisterTool(gent.NewToolFunc(
+			"policy", "Load the refund policy", nil,
+			func(ctx context.Context, _ map[string]any) (string, error) {
+				gent.ExecutionContextFrom(ctx).AttachEphemeralObservation(reference)
+				return "loaded policy", nil
+			},
+		)).
+		RegisterTool(gent.NewToolFunc(
+			"echo", "Echo", nil,
+			func(_ context.Context, _ map[string]any) (string, error) {
+				return "ok", nil
+			},
+		))
+
+	loop := NewAgent(model).
+		WithToolChain(toolChain).
+		WithTermination(tt.NewMockTermination())
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: "Can I get a refund?"})
+	execCtx := newTestExecCtx(data)
+
+	for i, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			messages := model.CapturedMessages[i]
+			last := messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+			assert.Equal(t, step.expected.lastMessage, last)
+
+			var prompt []string
+			for _, msg := range messages {
+				prompt = append(prompt, msg.Parts[0].(llms.TextContent).Text)
+			}
+			assert.Equal(t, step.expected.hasReference,
+				strings.Contains(strings.Join(prompt, "\n"), reference))
+			assert.Equal(t, step.expected.observation, result.NextPrompt)
+		})
+	}
+
+	for _, iter := range execCtx.IterationHistoryView() {
+		for _, msg := range iter.Messages {
+			assert.NotContains(t, msg.Parts[0].(llms.TextContent).Text, reference)
+		}
+	}
+	assert.Empty(t, execCtx.PendingEphemeralMessages())
+}
+
+func TestAgent_WithMinIterations(t *testing.T) {
+	const deferred = "<observation>\n" + DefaultMinIterationsNudge + "\n</observation>"
+	const custom = "<observation>\nCheck the forecast tool first.\n</observation>"
+
+	type input struct {
+		minIterations int
+		nudge         string
+	}
+
+	type expected struct {
+		iterations   int
+		answer       string
+		observations []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "answers accepted on iteration 1 by default",
+			input: input{minIterations: 0},
+			expected: expected{
+				iterations:   1,
+				answer:       "guess 1",
+				observations: []string{""},
+			},
+		},
+		{
+			name:  "early answers deferred until iteration k",
+			input: input{minIterations: 3},
+			expected: expected{
+				iterations:   3,
+				answer:       "guess 3",
+				observations: []string{deferred, deferred, ""},
+			},
+		},
+		{
+			name: "custom nudge",
+			input: input{
+				minIterations: 2,
+				nudge:         "Check the forecast tool first.",
+			},
+			expected: expected{
+				iterations:   2,
+				answer:       "guess 2",
+				observations: []string{custom, ""},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel()
+			for i := 1; i <= 3; i++ {
+				model.AddResponse(fmt.Sprintf("<answer>guess %d</answer>", i), 10, 5)
+			}
+			loop := NewAgent(model).
+				WithTermination(termination.NewText("answer")).
+				WithMinIterations(tc.input.minIterations)
+			if tc.input.nudge != "" {
+				loop.WithMinIterationsNudge(tc.input.nudge)
+			}
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "What's the weather?"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+			assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iterations, execCtx.Iteration())
+			require.Len(t, execCtx.FinalResult(), 1)
+			assert.Equal(t, tc.expected.answer,
+				execCtx.FinalResult()[0].(llms.TextContent).Text)
+			assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCAnswerRejectedTotal))
+
+			var observations []string
+			for _, iter := range data.GetIterationHistory() {
+				observation := ""
+				if len(iter.Messages) > 1 {
+					observation = iter.Messages[1].Parts[0].(llms.TextContent).Text
+				}
+				observations = append(observations, observation)
+			}
+			assert.Equal(t, tc.expected.observations, observations)
+		})
+	}
+}
+
+func TestAgent_Next_ToolFilter_RequiresFilterableToolChain(t *testing.T) {
+	loop := NewAgent(newMockModel()).
+		WithToolChain(newMockToolChain()).
+		WithToolFilter(func(_ *gent.ExecutionContext) []string { return nil })
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
+	_, err := loop.Next(newTestExecCtx(data))
+
+	assert.ErrorContains(t, err, "tool filter requires a ToolChain implementing "+
+		"gent.FilterableToolChain")
+}
+
+func TestAgent_Next_DuplicateSections(t *testing.T) {
+	const twoActions = "<action>tool: lookup</action>\n<action>tool: lookup</action>"
+	const twoThoughts = "<thinking>first</thinking>\n<thinking>second</thinking>\n" +
+		"<action>tool: lookup</action>"
+
+	type input struct {
+		response  string
+		toolChain func(tc *toolchain.YAML) *toolchain.YAML
+		thinking  *section.Text
+	}
+
+	type expected struct {
+		toolCalls           int64
+		toolchainParseErrs  int64
+		sectionParseErrs    int64
+		observationContains string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "repeated action sections are each executed by default",
+			input: input{
+				response:  twoActions,
+				toolChain: func(tc *toolchain.YAML) *toolchain.YAML { return tc },
+			},
+			expected: expected{toolCalls: 2, observationContains: "found"},
+		},
+		{
+			name: "repeated action sections with WithMultiple",
+			input: input{
+				response:  twoActions,
+				toolChain: (*toolchain.YAML).WithMultiple,
+			},
+			expected: expected{toolCalls: 2, observationContains: "found"},
+		},
+		{
+			name: "repeated action sections with WithSingle is a parse error",
+			input: input{
+				response:  twoActions,
+				toolChain: (*toolchain.YAML).WithSingle,
+			},
+			expected: expected{
+				toolCalls:          0,
+				toolchainParseErrs: 1,
+				observationContains: "section appears more than once: " +
+					"section \"action\" appears 2 times, expected exactly one",
+			},
+		},
+		{
+			name: "repeated thinking sections are joined by default",
+			input: input{
+				response:  twoThoughts,
+				toolChain: func(tc *toolchain.YAML) *toolchain.YAML { return tc },
+				thinking:  section.NewText("thinking"),
+			},
+			expected: expected{toolCalls: 1, observationContains: "found"},
+		},
+		{
+			name: "repeated thinking sections with WithSingle is a parse error",
+			input: input{
+				response:  twoThoughts,
+				toolChain: func(tc *toolchain.YAML) *toolchain.YAML { return tc },
+				thinking:  section.NewText("thinking").WithSingle(),
+			},
+			expected: expected{
+				toolCalls:        1,
+				sectionParseErrs: 1,
+				observationContains: "Section \"thinking\" parse error: section appears " +
+					"more than once: section \"thinking\" appears 2 times, expected exactly one",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().AddResponse(tc.input.response, 10, 5)
+			toolChain := tc.input.toolChain(toolchain.NewYAML())
+			toolChain.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up the order", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "found", nil
+				},
+			))
+
+			loop := NewAgent(model).
+				WithToolChain(toolChain).
+				WithTermination(tt.NewMockTermination())
+			if tc.input.thinking != nil {
+				loop.WithThinkingSection(tc.input.thinking)
+			}
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Where is my order?"})
+			execCtx := newTestExecCtx(data)
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+
+			stats := execCtx.Stats()
+			assert.Equal(t, gent.LAContinue, result.Action)
+			assert.Equal(t, tc.expected.toolCalls, stats.GetCounter(gent.SCToolCalls))
+			assert.Equal(t, tc.expected.toolchainParseErrs,
+				stats.GetCounter(gent.SCToolchainParseErrorTotal))
+			assert.Equal(t, tc.expected.sectionParseErrs,
+				stats.GetCounter(gent.SCSectionParseErrorTotal))
+			assert.Contains(t, result.NextPrompt, tc.expected.observationContains)
+		})
+	}
+}
+
+func TestAgent_BuildPrompts(t *testing.T) {
+	newAgent := func(model gent.Model) *Agent {
+		toolChain := toolchain.NewYAML().RegisterTool(gent.NewToolFunc(
+			"lookup", "Look up an order", nil,
+			func(_ context.Context, _ map[string]any) (string, error) {
+				return "shipped", nil
+			},
+		))
+		return NewAgent(model).
+			WithBehaviorAndContext("You are a support agent.").
+			WithToolChain(toolChain).
+			WithTermination(tt.NewMockTermination()).
+			WithTimeProvider(gent.NewMockTimeProvider(
+				time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC)))
+	}
+
+	model := tt.NewMockModel().AddResponse("<action>tool: lookup</action>", 10, 5)
+	loop := newAgent(model)
+	data := gent.NewBasicLoopData(&gent.Task{Text: "Where is my order?"})
+	execCtx := newTestExecCtx(data)
+
+	// Before the first iteration, the built messages match what the model receives
+	messages, err := loop.BuildMessages(execCtx)
+	require.NoError(t, err)
+	systemPrompt, err := loop.BuildSystemPrompt(execCtx)
+	require.NoError(t, err)
+
+	result, err := loop.Next(execCtx)
+	require.NoError(t, err)
+
+	require.Len(t, model.CapturedMessages, 1)
+	assert.Equal(t, model.CapturedMessages[0], messages)
+	assert.Equal(t, model.CapturedMessages[0][0].Parts[0].(llms.TextContent).Text, systemPrompt)
+	assert.Contains(t, systemPrompt, "You are a support agent.")
+	assert.Contains(t, systemPrompt, "- lookup: Look up an order")
+
+	// Observations are rendered like the ones Next feeds back
+	assert.Equal(t, "<observation>\n<lookup>\nshipped\n</lookup>\n</observation>",
+		result.NextPrompt)
+	assert.Equal(t, result.NextPrompt,
+		loop.BuildObservation("<lookup>\nshipped\n</lookup>"))
+	assert.Equal(t, "", loop.BuildObservation(""))
+
+	// Later iterations include the scratchpad and CONTINUE!
+	messages, err = loop.BuildMessages(execCtx)
+	require.NoError(t, err)
+	last := messages[len(messages)-1]
+	assert.Equal(t, []llms.ContentPart{llms.TextContent{Text: "CONTINUE!"}}, last.Parts)
+}
+
+func TestAgent_WithFewShotExamples(t *testing.T) {
+	textMessage := func(role llms.ChatMessageType, text string) *gent.MessageContent {
+		return &gent.MessageContent{
+			Role:  role,
+			Parts: []gent.ContentPart{llms.TextContent{Text: text}},
+		}
+	}
+
+	examples := []Example{
+		{
+			Task: "What is 2+2?",
+			Sections: []gent.FormattedSection{
+				{Name: "thinking", Content: "Simple arithmetic."},
+				{Name: "answer", Content: "4"},
+			},
+		},
+		{
+			Task: "Weather in Paris?",
+			Sections: []gent.FormattedSection{
+				{Name: "action", Content: "tool: weather\nargs:\n  city: Paris"},
+			},
+		},
+	}
+
+	type input struct {
+		examples []Example
+	}
+
+	type expected struct {
+		hasExamples bool
+		iterations  []*gent.Iteration
+		tokens      int
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "renders examples last with the active format",
+			input: input{examples: examples},
+			expected: expected{
+				hasExamples: true,
+				iterations: []*gent.Iteration{
+					{Messages: []*gent.MessageContent{
+						textMessage(llms.ChatMessageTypeHuman, "What is 2+2?"),
+						textMessage(llms.ChatMessageTypeAI,
+							"<thinking>\nSimple arithmetic.\n</thinking>\n"+
+								"<answer>\n4\n</answer>"),
+					}},
+					{Messages: []*gent.MessageContent{
+						textMessage(llms.ChatMessageTypeHuman, "Weather in Paris?"),
+						textMessage(llms.ChatMessageTypeAI,
+							"<action>\ntool: weather\nargs:\n  city: Paris\n</action>"),
+					}},
+				},
+				// (12 + 62 + 17 + 52 chars + 3) / 4
+				tokens: 36,
+			},
+		},
+		{
+			name:     "no examples section without examples",
+			input:    input{},
+			expected: expected{iterations: []*gent.Iteration{}, tokens: 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loop := NewAgent(newMockModel()).
+				WithCriticalRules("Never guess.").
+				WithFewShotExamples(tc.input.examples)
+			execCtx := newTestExecCtx(gent.NewBasicLoopData(&gent.Task{Text: "Hi"}))
+
+			systemPrompt, err := loop.BuildSystemPrompt(execCtx)
+			require.NoError(t, err)
+
+			if !tc.expected.hasExamples {
+				assert.NotContains(t, systemPrompt, "<examples>")
+			} else {
+				want := "<examples>\n" +
+					"<example>\n" +
+					"<task>\nWhat is 2+2?\n</task>\n" +
+					"<response>\n" +
+					"<thinking>\nSimple arithmetic.\n</thinking>\n" +
+					"<answer>\n4\n</answer>\n" +
+					"</response>\n" +
+					"</example>\n" +
+					"<example>\n" +
+					"<task>\nWeather in Paris?\n</task>\n" +
+					"<response>\n" +
+					"<action>\ntool: weather\nargs:\n  city: Paris\n</action>\n" +
+					"</response>\n" +
+					"</example>\n" +
+					"</examples>"
+				assert.True(t, strings.HasSuffix(systemPrompt, want), systemPrompt)
+				// Examples follow the rules and format they illustrate
+				assert.Less(t,
+					strings.Index(systemPrompt, "<critical_rules>"),
+					strings.Index(systemPrompt, "<output_format>"))
+				assert.Less(t,
+					strings.Index(systemPrompt, "<output_format>"),
+					strings.Index(systemPrompt, "<examples>"))
+			}
+
+			iterations := loop.FewShotIterations()
+			assert.Equal(t, tc.expected.iterations, iterations)
+			assert.Equal(t,
+				tc.expected.tokens,
+				gent.CharTokenEstimator{}.EstimateTokens(iterations))
+		})
+	}
+}
+
+func TestAgent_BuildPrompts_ToolFilterError(t *testing.T) {
+	loop := NewAgent(newMockModel()).
+		WithToolChain(newMockToolChain()).
+		WithToolFilter(func(_ *gent.ExecutionContext) []string { return nil })
+	execCtx := newTestExecCtx(gent.NewBasicLoopData(&gent.Task{Text: "Hello"}))
+
+	_, err := loop.BuildMessages(execCtx)
+	assert.ErrorContains(t, err, "gent.FilterableToolChain")
+	_, err = loop.BuildSystemPrompt(execCtx)
+	assert.ErrorContains(t, err, "gent.FilterableToolChain")
+}
+
+func TestAgent_Next_EphemeralSection(t *testing.T) {
+	const response = "<thinking>\nThe account id is probably 7.\n</thinking>\n" +
+		"<action>tool: lookup</action>"
+
+	type expected struct {
+		historyResponse    string
+		scratchpadResponse string
+	}
+
+	tests := []struct {
+		name     string
+		input    *section.Text
+		expected expected
+	}{
+		{
+			name:  "ephemeral thinking is removed from scratchpad only",
+			input: section.NewText("thinking").WithEphemeral(),
+			expected: expected{
+				historyResponse:    response,
+				scratchpadResponse: "<action>tool: lookup</action>",
+			},
+		},
+		{
+			name:  "regular thinking is kept in scratchpad",
+			input: section.NewText("thinking"),
+			expected: expected{
+				historyResponse:    response,
+				scratchpadResponse: response,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().AddResponse(response, 10, 5)
+			toolChain := toolchain.NewYAML().RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up the account", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "found", nil
+				},
+			))
+
+			loop := NewAgent(model).
+				WithToolChain(toolChain).
+				WithTermination(tt.NewMockTermination()).
+				WithThinkingSection(tc.input)
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Find the account"})
+			execCtx := newTestExecCtx(data)
+			result, err := loop.Next(execCtx)
+			require.NoError(t, err)
+			assert.Equal(t, gent.LAContinue, result.Action)
+
+			observation := "<observation>\n<lookup>\nfound\n</lookup>\n</observation>"
+			history := data.GetIterationHistory()
+			require.Len(t, history, 1)
+			assert.Equal(t, []*gent.MessageContent{
+				{
+					Role:  llms.ChatMessageTypeAI,
+					Parts: []gent.ContentPart{llms.TextContent{Text: tc.expected.historyResponse}},
+				},
+				{
+					Role:  llms.ChatMessageTypeHuman,
+					Parts: []gent.ContentPart{llms.TextContent{Text: observation}},
+				},
+			}, history[0].Messages)
+
+			scratchpad := data.GetScratchPad()
+			require.Len(t, scratchpad, 1)
+			assert.Equal(t, []*gent.MessageContent{
+				{
+					Role: llms.ChatMessageTypeAI,
+					Parts: []gent.ContentPart{
+						llms.TextContent{Text: tc.expected.scratchpadResponse},
+					},
+				},
+				{
+					Role:  llms.ChatMessageTypeHuman,
+					Parts: []gent.ContentPart{llms.TextContent{Text: observation}},
+				},
+			}, scratchpad[0].Messages)
+		})
+	}
+}
+
+func TestNewAgent_Defaults(t *testing.T) {
+	model := newMockModel()
+	loop := NewAgent(model)
+
+	assert.NotNil(t, loop.format, "expected default format to be set")
+	assert.NotNil(t, loop.toolChain, "expected default toolChain to be set")
+	assert.NotNil(t, loop.terminations[0], "expected default termination to be set")
+	assert.NotNil(t, loop.TimeProvider(), "expected default TimeProvider to be available")
+	assert.NotNil(t, loop.systemPromptBuilder, "expected default systemPromptBuilder to be set")
+}
+
+func TestAgent_WithTimeProvider(t *testing.T) {
+	model := newMockModel()
+	mockTime := gent.NewMockTimeProvider(time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC))
+
+	loop := NewAgent(model).WithTimeProvider(mockTime)
+
+	assert.Equal(t, mockTime, loop.TimeProvider())
+	assert.Equal(t, "2025-06-15", loop.TimeProvider().Today())
+	assert.Equal(t, "Sunday", loop.TimeProvider().Weekday())
+}
+
+func TestAgent_TimeFollowsExecutionClock(t *testing.T) {
+	type input struct {
+		timeProvider gent.TimeProvider
+	}
+
+	type expected struct {
+		today string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "template time uses the execution clock by default",
+			input:    input{},
+			expected: expected{today: "Today is 2025-06-16"},
+		},
+		{
+			name: "explicit time provider wins over the clock",
+			input: input{
+				timeProvider: gent.NewMockTimeProvider(
+					time.Date(2025, 6, 15, 14, 30, 0, 0, time.UTC)),
+			},
+			expected: expected{today: "Today is 2025-06-15"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			todayBuilder := func(ctx SystemPromptContext) []gent.MessageContent {
+				return []gent.MessageContent{{
+					Role: llms.ChatMessageTypeSystem,
+					Parts: []gent.ContentPart{
+						llms.TextContent{Text: "Today is " + ctx.Time.Today()},
+					},
+				}}
+			}
+			loop := NewAgent(tt.NewMockModel()).
+				WithTermination(tt.NewMockTermination()).
+				WithSystemPromptBuilder(todayBuilder)
+			if tc.input.timeProvider != nil {
+				loop = loop.WithTimeProvider(tc.input.timeProvider)
+			}
+			execCtx := newTestExecCtx(gent.NewBasicLoopData(&gent.Task{Text: "Hi"}))
+			execCtx.SetClock(clocktest.NewFakeClock(
+				time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)))
+
+			systemPrompt, err := loop.BuildSystemPrompt(execCtx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected.today, systemPrompt)
+		})
+	}
+}
+
+func TestDefaultSystemPromptBuilder(t *testing.T) {
+	t.Run("formats all sections with TextFormat", func(t *testing.T) {
+		format := newMockFormat()
+		ctx := SystemPromptContext{
+			Format:             format,
+			BehaviorAndContext: "You are helpful.",
+			CriticalRules:      "Never lie.",
+			OutputPrompt:       "Use XML tags.",
+			ToolsPrompt:        "Available tools: search",
+			Time:               gent.NewDefaultTimeProvider(),
+		}
+
+		messages := DefaultSystemPromptBuilder(ctx)
+
+		require.Len(t, messages, 1)
+		assert.Equal(t, llms.ChatMessageTypeSystem, messages[0].Role)
+
+		// Check that content contains all formatted sections
+		content, ok := messages[0].Parts[0].(llms.TextContent)
+		require.True(t, ok)
+
+		// All sections should be formatted with XML tags (from mockFormat)
+		assert.Contains(t, content.Text, "<behavior>")
+		assert.Contains(t, content.Text, "You are helpful.")
+		assert.Contains(t, content.Text, "<re_act>")
+		assert.Contains(t, content.Text, "<critical_rules>")
+		assert.Contains(t, content.Text, "Never lie.")
+		assert.Contains(t, content.Text, "<available_tools>")
+		assert.Contains(t, content.Text, "<output_format>")
+	})
+
+	t.Run("skips empty optional sections", func(t *testing.T) {
+		format := newMockFormat()
+		ctx := SystemPromptContext{
+			Format:             format,
+			BehaviorAndContext: "", // empty
+			CriticalRules:      "", // empty
+			OutputPrompt:       "Use XML.",
+			ToolsPrompt:        "tools",
+			Time:               gent.NewDefaultTimeProvider(),
+		}
+
+		messages := DefaultSystemPromptBuilder(ctx)
+
+		require.Len(t, messages, 1)
+		content, ok := messages[0].Parts[0].(llms.TextContent)
+		require.True(t, ok)
+
+		// Required sections should be present
+		assert.Contains(t, content.Text, "<re_act>")
+		assert.Contains(t, content.Text, "<available_tools>")
+		assert.Contains(t, content.Text, "<output_format>")
+
+		// Optional sections should not be present when empty
+		assert.NotContains(t, content.Text, "<behavior>")
+		assert.NotContains(t, content.Text, "<critical_rules>")
+	})
+}
+
+func TestAgent_WithSystemPromptBuilder(t *testing.T) {
+	t.Run("custom builder is used", func(t *testing.T) {
+		model := newMockModel()
+		format := newMockFormat()
+		tc := newMockToolChain()
+		term := newMockTermination()
+
+		customBuilder := func(ctx SystemPromptContext) []gent.MessageContent {
+			return []gent.MessageContent{
+				{
+					Role:  llms.ChatMessageTypeSystem,
+					Parts: []gent.ContentPart{llms.TextContent{Text: "Custom system prompt"}},
+				},
+			}
+		}
+
+		loop := NewAgent(model).
+			WithFormat(format).
+			WithToolChain(tc).
+			WithTermination(term).
 			WithSystemPromptBuilder(customBuilder)
 
 		data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
-		messages := loop.buildMessages(data, "output", "tools")
+		messages := loop.buildMessages(
+			data, gent.SystemClock, gent.ScratchpadChronologicalOldestFirst, "output", "tools",
+		)
 
 		// First message should be our custom system prompt
 		require.GreaterOrEqual(t, len(messages), 1)
@@ -1205,7 +2653,9 @@ func TestAgent_WithSystemPromptBuilder(t *testing.T) {
 			WithSystemPromptBuilder(multiMessageBuilder)
 
 		data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
-		messages := loop.buildMessages(data, "output", "tools")
+		messages := loop.buildMessages(
+			data, gent.SystemClock, gent.ScratchpadChronologicalOldestFirst, "output", "tools",
+		)
 
 		// Should have: 3 from builder + 1 task + 1 BEGIN!
 		require.Len(t, messages, 5)
@@ -1244,7 +2694,13 @@ func TestAgent_WithSystemPromptBuilder(t *testing.T) {
 			WithSystemPromptBuilder(capturingBuilder)
 
 		data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
-		loop.buildMessages(data, "output prompt", "tools prompt")
+		loop.buildMessages(
+			data,
+			gent.SystemClock,
+			gent.ScratchpadChronologicalOldestFirst,
+			"output prompt",
+			"tools prompt",
+		)
 
 		assert.Equal(t, format, capturedCtx.Format)
 		assert.Equal(t, "Be helpful", capturedCtx.BehaviorAndContext)
@@ -1252,5 +2708,348 @@ func TestAgent_WithSystemPromptBuilder(t *testing.T) {
 		assert.Equal(t, "output prompt", capturedCtx.OutputPrompt)
 		assert.Equal(t, "tools prompt", capturedCtx.ToolsPrompt)
 		assert.Equal(t, mockTime, capturedCtx.Time)
+		assert.Empty(t, capturedCtx.Tools, "mock toolchain is not a gent.ToolCatalog")
+	})
+
+	t.Run("builder receives tool metadata", func(t *testing.T) {
+		toolChain := toolchain.NewYAML().
+			RegisterTool(gent.NewToolFunc(
+				"refund", "Refund an order", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "refunded", nil
+				},
+			).WithCategory("billing")).
+			RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up the account", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "found", nil
+				},
+			))
+
+		var capturedCtx SystemPromptContext
+		loop := NewAgent(newMockModel()).
+			WithToolChain(toolChain).
+			WithSystemPromptBuilder(func(ctx SystemPromptContext) []gent.MessageContent {
+				capturedCtx = ctx
+				return DefaultSystemPromptBuilder(ctx)
+			})
+
+		data := gent.NewBasicLoopData(&gent.Task{Text: "Hello"})
+		loop.buildMessages(
+			data,
+			gent.SystemClock,
+			gent.ScratchpadChronologicalOldestFirst,
+			"output prompt",
+			"tools prompt",
+		)
+
+		assert.Equal(t, []gent.ToolInfo{
+			{Name: "refund", Description: "Refund an order", Category: "billing"},
+			{Name: "lookup", Description: "Look up the account"},
+		}, capturedCtx.Tools)
+	})
+}
+
+func TestAgent_ScratchpadLayout(t *testing.T) {
+	iteration := func(origin gent.IterationOrigin, text string) *gent.Iteration {
+		return &gent.Iteration{
+			Origin: origin,
+			Messages: []*gent.MessageContent{{
+				Role:  llms.ChatMessageTypeAI,
+				Parts: []gent.ContentPart{llms.TextContent{Text: text}},
+			}},
+		}
+	}
+
+	type input struct {
+		layout        gent.ScratchpadLayout
+		contextLayout gent.ScratchpadLayout
+	}
+
+	type expected struct {
+		scratchpad []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "chronological oldest first keeps stored order",
+			input: input{layout: gent.ScratchpadChronologicalOldestFirst},
+			expected: expected{
+				scratchpad: []string{"retrieved", "summary", "recent 1", "recent 2"},
+			},
+		},
+		{
+			name:  "summary first then recent",
+			input: input{layout: gent.ScratchpadSummaryFirstThenRecent},
+			expected: expected{
+				scratchpad: []string{"summary", "retrieved", "recent 1", "recent 2"},
+			},
+		},
+		{
+			name:  "layout set on the context is kept",
+			input: input{contextLayout: gent.ScratchpadSummaryFirstThenRecent},
+			expected: expected{
+				scratchpad: []string{"summary", "retrieved", "recent 1", "recent 2"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().AddResponse("<answer>done</answer>", 10, 5)
+			loop := NewAgent(model).WithTermination(tt.NewMockTermination())
+			data := gent.NewBasicLoopData(&gent.Task{Text: "Hi"})
+			data.SetScratchPad([]*gent.Iteration{
+				iteration(gent.IterationRetrievedHistory, "retrieved"),
+				iteration(gent.IterationCompactedSynthetic, "summary"),
+				iteration(gent.IterationOriginal, "recent 1"),
+				iteration(gent.IterationOriginal, "recent 2"),
+			})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			execCtx.SetScratchpadLayout(tc.input.contextLayout)
+
+			executor.New[*gent.BasicLoopData](loop, executor.Config{
+				ScratchpadLayout: tc.input.layout,
+			}).Execute(execCtx)
+
+			require.Len(t, model.CapturedMessages, 1)
+			messages := model.CapturedMessages[0]
+			// system, task, scratchpad..., CONTINUE!
+			require.Len(t, messages, 3+len(tc.expected.scratchpad))
+			var scratchpad []string
+			for _, msg := range messages[2 : len(messages)-1] {
+				scratchpad = append(scratchpad, msg.Parts[0].(llms.TextContent).Text)
+			}
+			assert.Equal(t, tc.expected.scratchpad, scratchpad)
+			stored := data.GetScratchPad()
+			assert.Equal(t, gent.IterationRetrievedHistory, stored[0].Origin,
+				"stored scratchpad keeps its order")
+		})
+	}
+}
+
+func TestAgent_Next_AnswerCandidates(t *testing.T) {
+	model := tt.NewMockModel().
+		AddResponse(`<answer>["Sunny", "My guess is rain", "Clear skies"]</answer>`, 10, 5)
+	term := termination.NewCandidates("answer", 3)
+	loop := NewAgent(model).WithTermination(term)
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: "What's the weather?"})
+	execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+	executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+	assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+	assert.Equal(t, []gent.ContentPart{
+		llms.TextContent{Text: "Sunny"},
+		llms.TextContent{Text: "My guess is rain"},
+		llms.TextContent{Text: "Clear skies"},
+	}, execCtx.FinalResult())
+	assert.Equal(t, []gent.AnswerCandidate{
+		{Answer: "Sunny", Accepted: true},
+		{Answer: "My guess is rain", Accepted: true},
+		{Answer: "Clear skies", Accepted: true},
+	}, execCtx.AnswerCandidates())
+	require.NotNil(t, execCtx.Result())
+	assert.Equal(t, execCtx.AnswerCandidates(), execCtx.Result().Candidates)
+}
+
+// capturingSection is a thinking section that records the contents it parses.
+type capturingSection struct {
+	*tt.MockSection
+	contents []string
+}
+
+func (s *capturingSection) ParseSection(
+	execCtx *gent.ExecutionContext,
+	content string,
+) (any, error) {
+	s.contents = append(s.contents, content)
+	return s.MockSection.ParseSection(execCtx, content)
+}
+
+func TestAgent_WithNativeReasoning(t *testing.T) {
+	type input struct {
+		nativeReasoning bool
+		thinking        bool
+	}
+
+	type expected struct {
+		thinkingContents []string
+		reasoning        any
+		promptThinking   bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "reasoning routed to the thinking section",
+			input: input{nativeReasoning: true, thinking: true},
+			expected: expected{
+				thinkingContents: []string{"Check the forecast first."},
+				reasoning:        "Check the forecast first.",
+			},
+		},
+		{
+			name:  "virtual thinking section when none is configured",
+			input: input{nativeReasoning: true},
+			expected: expected{
+				reasoning: "Check the forecast first.",
+			},
+		},
+		{
+			name:  "reasoning ignored by default",
+			input: input{thinking: true},
+			expected: expected{
+				promptThinking: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().AddRawResponse(&gent.ContentResponse{
+				Choices: []*gent.ContentChoice{{
+					Content:          "<answer>Sunny</answer>",
+					ReasoningContent: " Check the forecast first.\n",
+				}},
+				Info: &gent.GenerationInfo{InputTokens: 10, OutputTokens: 25, ReasoningTokens: 20},
+			})
+			thinking := &capturingSection{MockSection: tt.NewMockSection("thinking")}
+			loop := NewAgent(model)
+			if tc.input.thinking {
+				loop.WithThinkingSection(thinking)
+			}
+			if tc.input.nativeReasoning {
+				loop.WithNativeReasoning()
+			}
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "What's the weather?"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+			assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.thinkingContents, thinking.contents)
+			assert.Equal(t, int64(20), execCtx.Stats().GetCounter(gent.SCReasoningTokens))
+
+			history := data.GetIterationHistory()
+			require.Len(t, history, 1)
+			reasoning, _ := history[0].GetMetadata(gent.IMKReasoning)
+			assert.Equal(t, tc.expected.reasoning, reasoning)
+			for _, msg := range history[0].Messages {
+				assert.NotContains(t, msg.Parts[0].(llms.TextContent).Text, "forecast")
+			}
+
+			systemPrompt := model.CapturedMessages[0][0].Parts[0].(llms.TextContent).Text
+			assert.Equal(t, tc.expected.promptThinking,
+				strings.Contains(systemPrompt, "<thinking>"))
+		})
+	}
+}
+
+func TestAgent_WithFallbackFormat(t *testing.T) {
+	const (
+		xmlPrompt      = "using XML-style tags"
+		markdownPrompt = "using markdown headers"
+	)
+
+	type input struct {
+		afterConsecutive int
+		responses        []string
+	}
+
+	type expected struct {
+		prompts []string // output format instructions of each model call
+		answer  string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "fallback engages after the threshold and reverts on success",
+			input: input{
+				afterConsecutive: 2,
+				responses: []string{
+					"Sunny",
+					"It's sunny",
+					"# thinking\nI should use the XML tags next time.",
+					"<answer>Sunny</answer>",
+				},
+			},
+			expected: expected{
+				prompts: []string{xmlPrompt, xmlPrompt, markdownPrompt, xmlPrompt},
+				answer:  "Sunny",
+			},
+		},
+		{
+			name: "fallback answers directly",
+			input: input{
+				afterConsecutive: 1,
+				responses:        []string{"Sunny", "# answer\nSunny"},
+			},
+			expected: expected{
+				prompts: []string{xmlPrompt, markdownPrompt},
+				answer:  "Sunny",
+			},
+		},
+		{
+			name: "below the threshold keeps the configured format",
+			input: input{
+				afterConsecutive: 3,
+				responses:        []string{"Sunny", "It's sunny", "<answer>Sunny</answer>"},
+			},
+			expected: expected{
+				prompts: []string{xmlPrompt, xmlPrompt, xmlPrompt},
+				answer:  "Sunny",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel()
+			for _, response := range tc.input.responses {
+				model.AddResponse(response, 10, 5)
+			}
+			loop := NewAgent(model).
+				WithThinking("Think step by step.").
+				WithFallbackFormat(format.NewMarkdown(), tc.input.afterConsecutive)
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "What's the weather?"})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+			assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+			require.Len(t, execCtx.FinalResult(), 1)
+			assert.Equal(t, tc.expected.answer, execCtx.FinalResult()[0].(llms.TextContent).Text)
+			assert.Equal(t, float64(0),
+				execCtx.Stats().GetGauge(gent.SGFormatParseErrorConsecutive))
+
+			require.Len(t, model.CapturedMessages, len(tc.expected.prompts))
+			for i, expectedPrompt := range tc.expected.prompts {
+				systemPrompt := model.CapturedMessages[i][0].Parts[0].(llms.TextContent).Text
+				assert.Contains(t, systemPrompt, expectedPrompt, "model call %d", i+1)
+				other := xmlPrompt
+				if expectedPrompt == xmlPrompt {
+					other = markdownPrompt
+				}
+				assert.NotContains(t, systemPrompt, other, "model call %d", i+1)
+			}
+		})
+	}
+}
+
+func TestAgent_WithFallbackFormat_InvalidThreshold(t *testing.T) {
+	assert.Panics(t, func() {
+		NewAgent(tt.NewMockModel()).WithFallbackFormat(format.NewMarkdown(), 0)
 	})
 }
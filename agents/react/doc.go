@@ -34,6 +34,16 @@
 //  3. Discard the premature answer
 //  4. Allow the next iteration to provide an answer based on actual results
 //
+// The exception is a tool marked as terminal ([gent.TerminalTool], e.g. with
+// WithTerminal): once a call to it succeeds, the loop ends with the tool's output as the
+// answer, without another model round-trip. The result reports the tool name in
+// TerminatedBy with TerminatedByTool set. A failed call continues the loop normally.
+//
+// Tools can attach content for the next model call only with
+// [gent.ExecutionContext.AttachEphemeralObservation]. It is sent once as a separate
+// observation after the CONTINUE! message and never enters the scratchpad, so large
+// reference material doesn't weigh on every later prompt.
+//
 // ## 2. Parse Error Handling
 //
 // Parse errors are only raised if there are no actions to execute and no valid termination.
@@ -50,10 +60,16 @@
 // The agent can be configured with:
 //   - WithBehaviorAndContext: Custom behavior instructions (formatted as "behavior" section)
 //   - WithCriticalRules: Critical rules the agent must follow (formatted as "critical_rules" section)
+//   - WithFewShotExamples: Worked examples rendered with the format (as "examples" section)
+//   - WithModelRouter: Pick the model per iteration (default: the model given to NewAgent)
 //   - WithFormat: Custom output format (default: XML)
+//   - WithFallbackFormat: Simpler format used after repeated format parse errors
 //   - WithToolChain: Custom tool chain (default: YAML)
 //   - WithTermination: Custom termination handler (default: Text)
+//   - WithTerminations: Several answer sections, each routed to its own termination
+//   - WithMinIterations: Defer answers given before a minimum iteration
 //   - WithThinking: Enable thinking section
+//   - WithNativeReasoning: Route the model's native reasoning into the thinking section
 //   - WithStreaming: Enable streaming responses
 //   - WithSystemPromptBuilder: Custom function to build system prompt messages
 //   - WithTimeProvider: Custom time provider
@@ -62,7 +78,9 @@
 //
 // The system prompt is built using a SystemPromptBuilder function. The default builder
 // (DefaultSystemPromptBuilder) formats all sections using the configured TextFormat for
-// consistency. Sections include: behavior, re_act, critical_rules, available_tools, output_format.
+// consistency. Sections include, in order: behavior, re_act, critical_rules, available_tools,
+// output_format, examples. Few-shot examples come last so the model reads the rules and the
+// output format before seeing them applied.
 //
 // For full control over the system prompt, use WithSystemPromptBuilder to provide a custom
 // function that returns []gent.MessageContent. This allows for multi-message system prompts
@@ -75,4 +93,30 @@
 //	        {Role: llms.ChatMessageTypeSystem, Parts: []gent.ContentPart{...}},
 //	    }
 //	})
+//
+// SystemPromptContext.ToolsPrompt is the tool catalog rendered by the ToolChain. To lay
+// out the catalog yourself, e.g. grouped by category, use SystemPromptContext.Tools,
+// which lists each tool's Name, Description, Policy, Category, Schema and OutputSchema:
+//
+//	byCategory := map[string][]gent.ToolInfo{}
+//	for _, tool := range ctx.Tools {
+//	    byCategory[tool.Category] = append(byCategory[tool.Category], tool)
+//	}
+//
+// Tools is populated when the ToolChain implements gent.ToolCatalog (the YAML and JSON
+// toolchains and the JS wrapper do; the search toolchain, which reveals tools on demand,
+// does not).
+//
+// # Testing Prompts
+//
+// BuildMessages, BuildSystemPrompt and BuildObservation render the prompt exactly as
+// Next would, without calling the model. They are part of the stable public API, meant
+// for golden tests that catch accidental prompt drift when templates, tool descriptions
+// or schema generation change:
+//
+//	execCtx := gent.NewExecutionContext(ctx, "golden", gent.NewBasicLoopData(task))
+//	prompt, err := agent.BuildSystemPrompt(execCtx)
+//
+// If a custom SystemPromptBuilder renders the current time, set a fixed TimeProvider with
+// WithTimeProvider so the output is stable.
 package react
@@ -0,0 +1,69 @@
+package react
+
+import (
+	"github.com/rickchristie/gent"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Example is a worked few-shot example shown to the model in the system prompt: a task
+// and the ideal response to it.
+//
+// Sections hold the response as output sections (e.g. "thinking", "action", "answer"),
+// in the order the model should write them. They are rendered with the agent's
+// TextFormat, so examples always match the output structure the model is asked to use.
+type Example struct {
+	// Task is the task text given to the model in the example.
+	Task string
+
+	// Sections are the ideal output sections for the task.
+	Sections []gent.FormattedSection
+}
+
+// renderExamples formats the examples as the content of the "examples" system prompt
+// section. Each example becomes an "example" section with a "task" and a "response"
+// child. Returns an empty string if there are no examples.
+func renderExamples(f gent.TextFormat, examples []Example) string {
+	if len(examples) == 0 {
+		return ""
+	}
+
+	sections := make([]gent.FormattedSection, 0, len(examples))
+	for _, ex := range examples {
+		sections = append(sections, gent.FormattedSection{
+			Name: "example",
+			Children: []gent.FormattedSection{
+				{Name: "task", Content: ex.Task},
+				{Name: "response", Content: f.FormatSections(ex.Sections)},
+			},
+		})
+	}
+	return f.FormatSections(sections)
+}
+
+// FewShotIterations returns the few-shot examples as iterations: a user message with the
+// task followed by an AI message with the formatted response. They are not added to the
+// scratchpad; pass them to a [gent.TokenEstimator] to count the examples toward a prompt
+// token estimate:
+//
+//	tokens := estimator.EstimateTokens(data.GetScratchPad()) +
+//		estimator.EstimateTokens(agent.FewShotIterations())
+func (r *Agent) FewShotIterations() []*gent.Iteration {
+	iterations := make([]*gent.Iteration, 0, len(r.fewShotExamples))
+	for _, ex := range r.fewShotExamples {
+		iterations = append(iterations, &gent.Iteration{
+			Messages: []*gent.MessageContent{
+				{
+					Role:  llms.ChatMessageTypeHuman,
+					Parts: []gent.ContentPart{llms.TextContent{Text: ex.Task}},
+				},
+				{
+					Role: llms.ChatMessageTypeAI,
+					Parts: []gent.ContentPart{
+						llms.TextContent{Text: r.format.FormatSections(ex.Sections)},
+					},
+				},
+			},
+		})
+	}
+	return iterations
+}
@@ -22,6 +22,16 @@ type SystemPromptContext struct {
 	// ToolsPrompt describes available tools and how to call them (from ToolChain).
 	ToolsPrompt string
 
+	// Examples contains the few-shot examples set with Agent.WithFewShotExamples,
+	// rendered with Format. Empty if no examples are set.
+	Examples string
+
+	// Tools lists the available tools (Name, Description, Policy, Category, Schema and
+	// OutputSchema), for builders that render the catalog in their own layout instead
+	// of using ToolsPrompt. Empty if the ToolChain does not implement
+	// [gent.ToolCatalog].
+	Tools []gent.ToolInfo
+
 	// Time provides access to time-related functions.
 	Time gent.TimeProvider
 }
@@ -94,6 +104,14 @@ func DefaultSystemPromptBuilder(ctx SystemPromptContext) []gent.MessageContent {
 		})
 	}
 
+	// Few-shot examples, after the rules and format they illustrate
+	if ctx.Examples != "" {
+		sections = append(sections, gent.FormattedSection{
+			Name:    "examples",
+			Content: ctx.Examples,
+		})
+	}
+
 	systemContent := ctx.Format.FormatSections(sections)
 
 	return []gent.MessageContent{
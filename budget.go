@@ -0,0 +1,95 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExhausted is the error for tasks refused or stopped because their shared
+// budget exceeded one of its limits. See [BudgetRoot].
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// BudgetRoot runs independent tasks under shared limits, such as a per-tenant token or
+// cost budget for a queue of small jobs.
+//
+// Each task is a child ExecutionContext of the budget's own context. A task keeps its
+// own stats and limits (DefaultLimits unless changed with SetLimits), while its counters
+// also aggregate into the budget, where the budget's limits are checked across all tasks.
+// When the budget exceeds a limit, in-flight tasks are cancelled and end with
+// [TerminationLimitExceeded], and NewTask refuses new tasks with [ErrBudgetExhausted].
+//
+// Example:
+//
+//	budget := gent.NewBudgetRoot([]gent.Limit{
+//	    {Type: gent.LimitExactKey, Key: gent.SCInputTokens, MaxValue: 1_000_000},
+//	})
+//	for _, job := range jobs {
+//	    task, err := budget.NewTask(job.ID, gent.NewBasicLoopData(job.Task))
+//	    if err != nil {
+//	        break // budget exhausted
+//	    }
+//	    exec.Execute(task)
+//	    budget.CompleteTask(task)
+//	}
+type BudgetRoot struct {
+	execCtx *ExecutionContext
+}
+
+// NewBudgetRoot creates a budget enforcing limits across all of its tasks.
+func NewBudgetRoot(limits []Limit) *BudgetRoot {
+	execCtx := NewExecutionContext(context.Background(), "budget", nil)
+	execCtx.SetLimits(limits)
+	execCtx.budget = true
+	return &BudgetRoot{execCtx: execCtx}
+}
+
+// NewTask creates the ExecutionContext for a task charged to this budget. It returns
+// [ErrBudgetExhausted] once the budget has exceeded a limit.
+func (b *BudgetRoot) NewTask(name string, data LoopData) (*ExecutionContext, error) {
+	if limit := b.execCtx.ExceededLimit(); limit != nil {
+		return nil, fmt.Errorf("%w: %s > %v", ErrBudgetExhausted, limit.Key, limit.MaxValue)
+	}
+	task := b.execCtx.SpawnChild(name, data)
+	task.SetLimits(DefaultLimits())
+	return task, nil
+}
+
+// CompleteTask records that task finished and detaches it from the budget, so a
+// long-lived budget does not keep every task it ran. The task's counters are already
+// aggregated into the budget's stats. The budget's context records no child spawn or
+// complete events, unlike [ExecutionContext.CompleteChild]. The task's context is
+// cancelled: do not run it again, though its stats, events and result stay readable.
+func (b *BudgetRoot) CompleteTask(task *ExecutionContext) {
+	task.mu.Lock()
+	task.endTime = task.now()
+	task.mu.Unlock()
+	b.execCtx.detachChild(task)
+}
+
+// ExecutionContext returns the budget's own context. Its stats aggregate all tasks, and
+// its Children are the tasks not completed yet.
+func (b *BudgetRoot) ExecutionContext() *ExecutionContext {
+	return b.execCtx
+}
+
+// Stats returns the stats aggregated across all tasks.
+func (b *BudgetRoot) Stats() *ExecutionStats {
+	return b.execCtx.Stats()
+}
+
+// ExceededLimit returns the budget limit that was exceeded, or nil.
+func (b *BudgetRoot) ExceededLimit() *Limit {
+	return b.execCtx.ExceededLimit()
+}
+
+// ExceededBudgetLimit returns the limit exceeded by the [BudgetRoot] this context runs
+// under, or nil when it runs under no budget or the budget is within its limits.
+func (ctx *ExecutionContext) ExceededBudgetLimit() *Limit {
+	for p := ctx.Parent(); p != nil; p = p.Parent() {
+		if p.budget {
+			return p.ExceededLimit()
+		}
+	}
+	return nil
+}
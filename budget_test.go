@@ -0,0 +1,111 @@
+package gent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetRoot(t *testing.T) {
+	type input struct {
+		charges []int64 // input tokens charged to the first and second task
+	}
+
+	type expected struct {
+		taskTokens     []int64
+		budgetTokens   int64
+		exceeded       bool
+		tasksCanceled  bool
+		newTaskRefused bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "within budget",
+			input: input{charges: []int64{40, 50}},
+			expected: expected{
+				taskTokens:   []int64{40, 50},
+				budgetTokens: 90,
+			},
+		},
+		{
+			name:  "aggregate over budget cancels every task",
+			input: input{charges: []int64{60, 50}},
+			expected: expected{
+				taskTokens:     []int64{60, 50},
+				budgetTokens:   110,
+				exceeded:       true,
+				tasksCanceled:  true,
+				newTaskRefused: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			budget := NewBudgetRoot([]Limit{
+				{Type: LimitExactKey, Key: SCInputTokens, MaxValue: 100},
+			})
+
+			var tasks []*ExecutionContext
+			for range tc.input.charges {
+				task, err := budget.NewTask("task", nil)
+				require.NoError(t, err)
+				tasks = append(tasks, task)
+			}
+			var taskTokens []int64
+			for i, task := range tasks {
+				task.Stats().IncrCounter(SCInputTokens, tc.input.charges[i])
+			}
+			for _, task := range tasks {
+				taskTokens = append(taskTokens, task.Stats().GetCounter(SCInputTokens))
+				assert.Equal(t, tc.expected.tasksCanceled, task.Context().Err() != nil)
+				assert.Nil(t, task.ExceededLimit(), "task limits are its own")
+				assert.Equal(t, tc.expected.exceeded, task.ExceededBudgetLimit() != nil)
+			}
+
+			assert.Equal(t, tc.expected.taskTokens, taskTokens)
+			assert.Equal(t, tc.expected.budgetTokens, budget.Stats().GetCounter(SCInputTokens))
+			assert.Equal(t, tc.expected.exceeded, budget.ExceededLimit() != nil)
+
+			_, err := budget.NewTask("late", nil)
+			if tc.expected.newTaskRefused {
+				assert.ErrorIs(t, err, ErrBudgetExhausted)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBudgetRoot_CompleteTask(t *testing.T) {
+	budget := NewBudgetRoot([]Limit{
+		{Type: LimitExactKey, Key: SCInputTokens, MaxValue: 100},
+	})
+
+	first, err := budget.NewTask("first", nil)
+	require.NoError(t, err)
+	second, err := budget.NewTask("second", nil)
+	require.NoError(t, err)
+	first.Stats().IncrCounter(SCInputTokens, 40)
+
+	budget.CompleteTask(first)
+
+	// The completed task is released; its tokens stay charged to the budget
+	assert.Equal(t, []*ExecutionContext{second}, budget.ExecutionContext().Children())
+	assert.Empty(t, budget.ExecutionContext().Events())
+	assert.Error(t, first.Context().Err())
+	assert.NoError(t, second.Context().Err())
+	assert.Equal(t, int64(40), first.Stats().GetCounter(SCInputTokens))
+	assert.Equal(t, int64(40), budget.Stats().GetCounter(SCInputTokens))
+
+	// Later tasks are still charged to the budget and checked against its limits
+	second.Stats().IncrCounter(SCInputTokens, 70)
+	assert.NotNil(t, budget.ExceededLimit())
+	assert.Error(t, second.Context().Err())
+}
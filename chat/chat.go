@@ -0,0 +1,238 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// DefaultStreamTopic is the stream topic written to the output writer. It matches the
+// topic react.Agent uses for model responses.
+const DefaultStreamTopic = "llm-response"
+
+// Role identifies who sent a message.
+type Role string
+
+const (
+	// RoleUser marks a message sent with SendMessage.
+	RoleUser Role = "user"
+
+	// RoleAgent marks the agent's answer to a user message.
+	RoleAgent Role = "agent"
+)
+
+// Message is a single turn of the conversation.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// TaskFormatter renders the conversation into the task text for the agent loop.
+// The last message in history is always the newest user message.
+type TaskFormatter func(history []Message) string
+
+// FormatHistory is the default [TaskFormatter]. It renders the history as a
+// <message_history> block and marks the newest user message as most recent:
+//
+//	<message_history>
+//	user:
+//	Where is my order?
+//	agent:
+//	Which order number?
+//	user(most_recent):
+//	#1234
+//	</message_history>
+func FormatHistory(history []Message) string {
+	var sb strings.Builder
+	sb.WriteString("<message_history>\n")
+	for i, msg := range history {
+		if msg.Role == RoleUser && i == len(history)-1 {
+			sb.WriteString("user(most_recent):\n")
+		} else {
+			sb.WriteString(string(msg.Role) + ":\n")
+		}
+		sb.WriteString(msg.Content)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</message_history>")
+	return sb.String()
+}
+
+// Chat maintains a conversation with an agent loop across messages.
+//
+// Each SendMessage call runs a new execution with the full history as its task. Calls
+// are serialized, so a Chat is safe for concurrent use, but messages are processed one
+// at a time.
+type Chat struct {
+	mu sync.Mutex
+
+	loop           gent.AgentLoop[*gent.BasicLoopData]
+	w              io.Writer
+	name           string
+	limits         []gent.Limit
+	executorConfig executor.Config
+	setup          func(execCtx *gent.ExecutionContext)
+	formatTask     TaskFormatter
+	streamTopic    string
+
+	history []Message
+}
+
+// New creates a Chat that runs loop for every message and writes output to w.
+func New(loop gent.AgentLoop[*gent.BasicLoopData], w io.Writer) *Chat {
+	return &Chat{
+		loop:        loop,
+		w:           w,
+		name:        "chat",
+		formatTask:  FormatHistory,
+		streamTopic: DefaultStreamTopic,
+	}
+}
+
+// WithName sets the execution name used for each message's ExecutionContext.
+// Default: "chat".
+func (c *Chat) WithName(name string) *Chat {
+	c.name = name
+	return c
+}
+
+// WithLimits replaces the default limits applied to each message's execution.
+func (c *Chat) WithLimits(limits []gent.Limit) *Chat {
+	c.limits = limits
+	return c
+}
+
+// WithExecutorConfig sets the executor configuration, including the event registry.
+// AsyncCompaction is ignored: each message starts from a fresh scratchpad, so a
+// background compaction's result would never be used.
+func (c *Chat) WithExecutorConfig(config executor.Config) *Chat {
+	config.AsyncCompaction = false
+	c.executorConfig = config
+	return c
+}
+
+// WithSetup registers a function that configures each message's ExecutionContext
+// before execution starts, e.g. to set compaction.
+func (c *Chat) WithSetup(setup func(execCtx *gent.ExecutionContext)) *Chat {
+	c.setup = setup
+	return c
+}
+
+// WithTaskFormatter replaces [FormatHistory] as the task formatter.
+func (c *Chat) WithTaskFormatter(formatter TaskFormatter) *Chat {
+	c.formatTask = formatter
+	return c
+}
+
+// WithStreamTopic sets the stream topic written to the output writer.
+// Default: [DefaultStreamTopic].
+func (c *Chat) WithStreamTopic(topic string) *Chat {
+	c.streamTopic = topic
+	return c
+}
+
+// History returns a copy of the conversation so far.
+func (c *Chat) History() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]Message, len(c.history))
+	copy(result, c.history)
+	return result
+}
+
+// Reset clears the conversation history.
+func (c *Chat) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = nil
+}
+
+// SendMessage sends a user message to the agent and waits for its answer.
+//
+// Streamed output is written to the writer while the agent works, followed by the
+// final answer. On success, the message and the answer are appended to the history.
+// If the execution fails (error, limit exceeded or cancellation), the history is left
+// unchanged so the message can be retried, and the execution error is returned.
+func (c *Chat) SendMessage(ctx context.Context, text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	userMessage := Message{Role: RoleUser, Content: text}
+	history := append(c.history[:len(c.history):len(c.history)], userMessage)
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: c.formatTask(history)})
+	execCtx := gent.NewExecutionContext(ctx, c.name, data)
+	if c.limits != nil {
+		execCtx.SetLimits(c.limits)
+	}
+	if c.setup != nil {
+		c.setup(execCtx)
+	}
+
+	streamed := c.streamOutput(execCtx)
+	executor.New[*gent.BasicLoopData](c.loop, c.executorConfig).Execute(execCtx)
+	wroteChunks := <-streamed
+
+	result := execCtx.Result()
+	if result.Error != nil {
+		return result.Error
+	}
+
+	answer := textOutput(result.Output)
+	if wroteChunks {
+		if _, err := fmt.Fprintln(c.w); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(c.w, answer); err != nil {
+		return err
+	}
+
+	c.history = append(history, Message{Role: RoleAgent, Content: answer})
+	return nil
+}
+
+// streamOutput copies streamed content chunks to the writer until the execution closes
+// its streams. The returned channel reports whether anything was written.
+func (c *Chat) streamOutput(execCtx *gent.ExecutionContext) <-chan bool {
+	done := make(chan bool, 1)
+
+	chunks, unsubscribe := execCtx.SubscribeToTopic(c.streamTopic)
+	if chunks == nil {
+		done <- false
+		return done
+	}
+
+	go func() {
+		defer unsubscribe()
+		wrote := false
+		for chunk := range chunks {
+			if chunk.Content == "" {
+				continue
+			}
+			// Write errors are ignored here; they surface when the answer is written.
+			_, _ = io.WriteString(c.w, chunk.Content)
+			wrote = true
+		}
+		done <- wrote
+	}()
+
+	return done
+}
+
+// textOutput joins the text parts of an execution's output.
+func textOutput(parts []gent.ContentPart) string {
+	var texts []string
+	for _, part := range parts {
+		if tc, ok := part.(llms.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scriptedLoop answers each execution with the next scripted reply, streaming the given
+// chunks first. It records the task it received.
+type scriptedLoop struct {
+	chunks  []string
+	replies []string
+	errs    []error
+	tasks   []string
+}
+
+func (l *scriptedLoop) Next(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+	call := len(l.tasks)
+	l.tasks = append(l.tasks, execCtx.Data().GetTask().Text)
+
+	for _, chunk := range l.chunks {
+		execCtx.EmitChunk(gent.StreamChunk{Content: chunk, StreamTopicId: DefaultStreamTopic})
+	}
+	if call < len(l.errs) && l.errs[call] != nil {
+		return nil, l.errs[call]
+	}
+	return &gent.AgentLoopResult{
+		Action: gent.LATerminate,
+		Result: []gent.ContentPart{llms.TextContent{Text: l.replies[call]}},
+	}, nil
+}
+
+func TestChat_SendMessage(t *testing.T) {
+	type input struct {
+		chunks   []string
+		messages []string
+	}
+
+	type expected struct {
+		output  string
+		history []Message
+		tasks   []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "non-streaming agent writes answers only",
+			input: input{messages: []string{"Where is my order?", "#1234"}},
+			expected: expected{
+				output: "Which order?\nIt has shipped.\n",
+				history: []Message{
+					{Role: RoleUser, Content: "Where is my order?"},
+					{Role: RoleAgent, Content: "Which order?"},
+					{Role: RoleUser, Content: "#1234"},
+					{Role: RoleAgent, Content: "It has shipped."},
+				},
+				tasks: []string{
+					"<message_history>\nuser(most_recent):\nWhere is my order?\n" +
+						"</message_history>",
+					"<message_history>\nuser:\nWhere is my order?\n" +
+						"agent:\nWhich order?\n" +
+						"user(most_recent):\n#1234\n</message_history>",
+				},
+			},
+		},
+		{
+			name: "streamed chunks are written before the answer",
+			input: input{
+				chunks:   []string{"<answer>", "Which order?", "</answer>"},
+				messages: []string{"Where is my order?"},
+			},
+			expected: expected{
+				output: "<answer>Which order?</answer>\nWhich order?\n",
+				history: []Message{
+					{Role: RoleUser, Content: "Where is my order?"},
+					{Role: RoleAgent, Content: "Which order?"},
+				},
+				tasks: []string{
+					"<message_history>\nuser(most_recent):\nWhere is my order?\n" +
+						"</message_history>",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loop := &scriptedLoop{
+				chunks:  tc.input.chunks,
+				replies: []string{"Which order?", "It has shipped."},
+			}
+			var out bytes.Buffer
+			session := New(loop, &out)
+
+			for _, msg := range tc.input.messages {
+				require.NoError(t, session.SendMessage(context.Background(), msg))
+			}
+
+			assert.Equal(t, tc.expected.output, out.String())
+			assert.Equal(t, tc.expected.history, session.History())
+			assert.Equal(t, tc.expected.tasks, loop.tasks)
+		})
+	}
+}
+
+func TestChat_SendMessage_ErrorKeepsHistory(t *testing.T) {
+	loopErr := errors.New("model unavailable")
+	loop := &scriptedLoop{
+		replies: []string{"Hello!", "", "Hello again!"},
+		errs:    []error{nil, loopErr},
+	}
+	var out bytes.Buffer
+	session := New(loop, &out)
+
+	require.NoError(t, session.SendMessage(context.Background(), "Hi"))
+	err := session.SendMessage(context.Background(), "Are you there?")
+	assert.ErrorIs(t, err, loopErr)
+	assert.Equal(t, []Message{
+		{Role: RoleUser, Content: "Hi"},
+		{Role: RoleAgent, Content: "Hello!"},
+	}, session.History())
+
+	// Retrying sends the same history as the failed attempt
+	require.NoError(t, session.SendMessage(context.Background(), "Are you there?"))
+	assert.Equal(t, loop.tasks[1], loop.tasks[2])
+	assert.Len(t, session.History(), 4)
+}
+
+func TestChat_Options(t *testing.T) {
+	loop := &scriptedLoop{replies: []string{"ok"}}
+	var setupName string
+	session := New(loop, &bytes.Buffer{}).
+		WithName("support").
+		WithLimits([]gent.Limit{{Type: gent.LimitExactKey, Key: gent.SCIterations, MaxValue: 3}}).
+		WithSetup(func(execCtx *gent.ExecutionContext) {
+			setupName = execCtx.Name()
+			assert.Equal(t, 3.0, execCtx.Limits()[0].MaxValue)
+		}).
+		WithTaskFormatter(func(history []Message) string {
+			return history[len(history)-1].Content
+		})
+
+	require.NoError(t, session.SendMessage(context.Background(), "Hi"))
+	assert.Equal(t, "support", setupName)
+	assert.Equal(t, []string{"Hi"}, loop.tasks)
+
+	session.Reset()
+	assert.Empty(t, session.History())
+}
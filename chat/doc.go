@@ -0,0 +1,58 @@
+// Package chat provides a multi-turn conversation on top of an agent loop.
+//
+// A [Chat] keeps the conversation history between messages. Each call to
+// [Chat.SendMessage] renders the history into a task, runs the agent loop with an
+// executor, streams the model output to an io.Writer, and records the agent's answer.
+// The agent loop is supplied by the caller, so the model, format, toolchain and
+// termination are configured exactly as for a single execution.
+//
+// # Quick Start
+//
+//	agent := react.NewAgent(model).
+//	    WithBehaviorAndContext("You are a helpful customer service agent.").
+//	    WithToolChain(toolchain.NewYAML().RegisterTool(lookupOrder)).
+//	    WithStreaming(true)
+//
+//	session := chat.New(agent, os.Stdout)
+//
+//	if err := session.SendMessage(ctx, "Where is my order #1234?"); err != nil {
+//	    // handle error; the message was not added to the history
+//	}
+//	if err := session.SendMessage(ctx, "Can you cancel it?"); err != nil {
+//	    // ...
+//	}
+//
+// # Output
+//
+// While the agent works, streamed content chunks on the [DefaultStreamTopic] topic are
+// written to the writer as they arrive. Once execution finishes, the final answer is
+// written on its own line. If the agent does not stream, only the final answer is
+// written. Use [Chat.WithStreamTopic] for agent loops that stream on another topic.
+//
+// # Per-Message Executions
+//
+// Every message runs in a fresh [gent.ExecutionContext], so limits and stats apply per
+// message. Use [Chat.WithLimits] to replace the default limits and [Chat.WithSetup] for
+// anything else, such as compaction or drain modes:
+//
+//	session := chat.New(agent, os.Stdout).
+//	    WithLimits([]gent.Limit{
+//	        {Type: gent.LimitExactKey, Key: gent.SCIterations, MaxValue: 10},
+//	    }).
+//	    WithSetup(func(execCtx *gent.ExecutionContext) {
+//	        execCtx.SetCompaction(trigger, strategy)
+//	    })
+//
+// Subscribers are registered through the executor configuration:
+//
+//	registry := events.NewRegistry()
+//	registry.Subscribe(logger)
+//	session := chat.New(agent, os.Stdout).
+//	    WithExecutorConfig(executor.Config{Events: registry})
+//
+// # Conversation History
+//
+// The task for each message is produced by a [TaskFormatter]. The default,
+// [FormatHistory], renders the history as a <message_history> block. Replace it with
+// [Chat.WithTaskFormatter] to add instructions or use a different layout.
+package chat
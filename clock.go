@@ -0,0 +1,98 @@
+package gent
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of the current time for an [ExecutionContext]. Inject a fake clock
+// with SetClock (e.g. clocktest.FakeClock) to make time-dependent behavior reproducible
+// in tests and replays.
+//
+// Framework code paths that read the time through the execution clock:
+//   - Event timestamps (BaseEvent.Timestamp), including child spawn/complete events
+//   - Execution start and end times, and ExecutionContext.Duration
+//   - Iteration and compaction durations measured by the executor
+//   - Tool call durations measured by the toolchains (AfterToolCallEvent.Duration)
+//   - Model call durations measured by models.LCGWrapper and model middleware
+//   - The time provider handed to react system prompt builders (SystemPromptContext.Time),
+//     unless the agent was given one with WithTimeProvider
+//
+// Timers are not driven by the clock: the limit drain grace period and the JavaScript
+// runtime timeout always use real time. Code that needs "now" during a run (including
+// custom tools, via ExecutionContextFrom) should read it through ExecutionContext.Clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// SystemClock is the [Clock] reading the system time. It is the default clock of every
+// ExecutionContext.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockBox wraps a Clock so implementations of different types can share an
+// atomic.Pointer.
+type clockBox struct {
+	clock Clock
+}
+
+// clockRef holds the clock of an ExecutionContext. It is read without the context's
+// mutex, since the time is read while the mutex is held (e.g. when stamping events).
+type clockRef struct {
+	p atomic.Pointer[clockBox]
+}
+
+func (r *clockRef) load() Clock {
+	if box := r.p.Load(); box != nil {
+		return box.clock
+	}
+	return SystemClock
+}
+
+func (r *clockRef) store(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	r.p.Store(&clockBox{clock: clock})
+}
+
+// SetClock sets the clock used for every framework time read of this context (see
+// [Clock]). Child contexts spawned afterwards inherit it. A nil clock restores
+// [SystemClock].
+//
+// The execution start time is reset to the new clock's current time, so call SetClock
+// right after NewExecutionContext, before execution starts.
+func (ctx *ExecutionContext) SetClock(clock Clock) {
+	ctx.clock.store(clock)
+	now := ctx.clock.load().Now()
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.startTime = now
+}
+
+// Clock returns the clock set with SetClock, or [SystemClock]. It is safe to call on a
+// nil ExecutionContext, which returns SystemClock, so code that may run without an
+// execution context (such as toolchains in unit tests) can read the time through it.
+func (ctx *ExecutionContext) Clock() Clock {
+	if ctx == nil {
+		return SystemClock
+	}
+	return ctx.clock.load()
+}
+
+// now returns the current time of the context's clock.
+func (ctx *ExecutionContext) now() time.Time {
+	return ctx.clock.load().Now()
+}
+
+// since returns the time elapsed since t on the context's clock.
+func (ctx *ExecutionContext) since(t time.Time) time.Duration {
+	return ctx.now().Sub(t)
+}
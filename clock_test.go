@@ -0,0 +1,109 @@
+package gent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock is a Clock that only moves when advanced.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestExecutionContext_SetClock(t *testing.T) {
+	start := time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)
+
+	type expected struct {
+		startTime      time.Time
+		eventTimes     []time.Time
+		childStartTime time.Time
+		childDuration  time.Duration
+		duration       time.Duration
+	}
+
+	tests := []struct {
+		name     string
+		expected expected
+	}{
+		{
+			name: "all time reads follow the injected clock",
+			expected: expected{
+				startTime: start,
+				eventTimes: []time.Time{
+					start,
+					start.Add(time.Second),
+					start.Add(3 * time.Second),
+				},
+				childStartTime: start.Add(time.Second),
+				childDuration:  2 * time.Second,
+				duration:       5 * time.Second,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &manualClock{now: start}
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetClock(clock)
+			assert.Same(t, clock, execCtx.Clock())
+
+			execCtx.PublishBeforeExecution()
+			clock.advance(time.Second)
+			child := execCtx.SpawnChild("child", nil)
+			assert.Same(t, clock, child.Clock())
+			clock.advance(2 * time.Second)
+			execCtx.CompleteChild(child)
+			clock.advance(2 * time.Second)
+			execCtx.SetTermination(TerminationSuccess, nil, nil)
+			clock.advance(time.Hour)
+
+			events := execCtx.Events()
+			require.Len(t, events, len(tc.expected.eventTimes))
+			var eventTimes []time.Time
+			for _, ev := range events {
+				switch e := ev.(type) {
+				case *BeforeExecutionEvent:
+					eventTimes = append(eventTimes, e.Timestamp)
+				case *CommonEvent:
+					eventTimes = append(eventTimes, e.Timestamp)
+				}
+			}
+			assert.Equal(t, tc.expected.eventTimes, eventTimes)
+			assert.Equal(t, tc.expected.startTime, execCtx.StartTime())
+			assert.Equal(t, tc.expected.childStartTime, child.StartTime())
+			assert.Equal(t, tc.expected.childDuration, child.Duration())
+			assert.Equal(t, tc.expected.duration, execCtx.Duration())
+		})
+	}
+}
+
+func TestExecutionContext_Clock_Default(t *testing.T) {
+	var nilCtx *ExecutionContext
+	assert.Equal(t, SystemClock, nilCtx.Clock())
+
+	execCtx := NewExecutionContext(context.Background(), "test", nil)
+	assert.Equal(t, SystemClock, execCtx.Clock())
+
+	execCtx.SetClock(&manualClock{})
+	execCtx.SetClock(nil)
+	assert.Equal(t, SystemClock, execCtx.Clock())
+}
@@ -0,0 +1,56 @@
+// Package clocktest provides a controllable [gent.Clock] for deterministic tests.
+//
+// Install a FakeClock on the execution context before running the agent, and every
+// framework time read (event timestamps, durations, the time given to prompt builders)
+// follows it:
+//
+//	clock := clocktest.NewFakeClock(time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC))
+//	execCtx := gent.NewExecutionContext(ctx, "test", data)
+//	execCtx.SetClock(clock)
+//
+//	clock.Advance(2 * time.Second)
+//	assert.Equal(t, 2*time.Second, execCtx.Duration())
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rickchristie/gent"
+)
+
+// FakeClock is a [gent.Clock] that only moves when told to. It is safe for concurrent
+// use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Compile-time check that FakeClock implements gent.Clock.
+var _ gent.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock frozen at t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
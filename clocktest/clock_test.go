@@ -0,0 +1,62 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)
+
+	type input struct {
+		advance []time.Duration
+		set     *time.Time
+	}
+
+	type expected struct {
+		now time.Time
+	}
+
+	later := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "frozen at start",
+			input:    input{},
+			expected: expected{now: start},
+		},
+		{
+			name:     "advance accumulates",
+			input:    input{advance: []time.Duration{time.Second, 2 * time.Minute}},
+			expected: expected{now: start.Add(2*time.Minute + time.Second)},
+		},
+		{
+			name: "set then advance",
+			input: input{
+				set:     &later,
+				advance: []time.Duration{time.Hour},
+			},
+			expected: expected{now: later.Add(time.Hour)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := NewFakeClock(start)
+			if tc.input.set != nil {
+				clock.Set(*tc.input.set)
+			}
+			for _, d := range tc.input.advance {
+				clock.Advance(d)
+			}
+
+			assert.Equal(t, tc.expected.now, clock.Now())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package gent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUndeclaredCommonEvent is returned by [ExecutionContext.PublishCommonEventStrict] when
+// the event name was not declared with the event publisher.
+var ErrUndeclaredCommonEvent = errors.New("undeclared common event")
+
+// CommonEventDeclarations is implemented by EventPublishers that keep a set of declared
+// CommonEvent names (events.Registry.DeclareCommonEvent). It is what
+// [ExecutionContext.PublishCommonEventStrict] checks names against.
+type CommonEventDeclarations interface {
+	// IsCommonEventDeclared reports whether eventName was declared.
+	IsCommonEventDeclared(eventName string) bool
+}
+
+// PublishCommonEventStrict publishes a CommonEvent like [ExecutionContext.PublishCommonEvent],
+// but only if eventName was declared with the event publisher, so a misspelled name
+// surfaces instead of silently never matching a subscriber:
+//
+//	const EventCacheHit = "myapp:cache_hit"
+//
+//	registry := events.NewRegistry().DeclareCommonEvent(EventCacheHit)
+//	...
+//	_, err := execCtx.PublishCommonEventStrict(EventCacheHit, "Cache lookup succeeded", data)
+//
+// An undeclared name is not published. Instead an ErrorEvent wrapping
+// [ErrUndeclaredCommonEvent] is published, and the same error is returned. If the event
+// publisher does not implement [CommonEventDeclarations] (or none is set), there is no
+// name contract to enforce and the event is published as is.
+func (ctx *ExecutionContext) PublishCommonEventStrict(
+	eventName string,
+	description string,
+	data any,
+) (*CommonEvent, error) {
+	ctx.mu.RLock()
+	declarations, ok := ctx.eventPublisher.(CommonEventDeclarations)
+	ctx.mu.RUnlock()
+
+	if ok && !declarations.IsCommonEventDeclared(eventName) {
+		err := fmt.Errorf("%w: %q", ErrUndeclaredCommonEvent, eventName)
+		ctx.PublishError(err)
+		return nil, err
+	}
+	return ctx.PublishCommonEvent(eventName, description, data), nil
+}
@@ -0,0 +1,52 @@
+package gent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchOnlyPublisher is an EventPublisher without CommonEventDeclarations.
+type dispatchOnlyPublisher struct {
+	dispatched []Event
+}
+
+func (p *dispatchOnlyPublisher) Dispatch(_ *ExecutionContext, event Event) {
+	p.dispatched = append(p.dispatched, event)
+}
+
+func (p *dispatchOnlyPublisher) MaxRecursion() int { return 10 }
+
+func TestExecutionContext_PublishCommonEventStrict_NoDeclarations(t *testing.T) {
+	type input struct {
+		publisher EventPublisher
+	}
+
+	tests := []struct {
+		name  string
+		input input
+	}{
+		{name: "no event publisher", input: input{}},
+		{
+			name:  "publisher without declarations",
+			input: input{publisher: &dispatchOnlyPublisher{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			if tc.input.publisher != nil {
+				execCtx.SetEventPublisher(tc.input.publisher)
+			}
+
+			event, err := execCtx.PublishCommonEventStrict("myapp:cache_hit", "desc", nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, "myapp:cache_hit", event.EventName)
+			assert.Equal(t, []Event{event}, execCtx.Events())
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package gent
 
+import "github.com/tmc/langchaingo/llms"
+
 // CompactionTrigger decides WHEN scratchpad compaction should
 // run.
 //
@@ -85,3 +87,50 @@ type CompactionStrategy interface {
 	// Returning a non-nil error terminates execution.
 	Compact(execCtx *ExecutionContext) error
 }
+
+// TokenEstimator estimates how many prompt tokens a
+// scratchpad takes. The executor uses it to report the
+// tokens saved by each compaction (see
+// SCCompactionTokensSaved).
+//
+// Configure with ExecutionContext.SetTokenEstimator. The
+// default is CharTokenEstimator; use a tokenizer for the
+// target model for more accurate numbers.
+type TokenEstimator interface {
+	// EstimateTokens returns the estimated token count of
+	// the scratchpad's messages.
+	EstimateTokens(scratchpad []*Iteration) int
+}
+
+// CharTokenEstimator estimates tokens from text length, at
+// roughly four characters per token. Non-text parts are
+// not counted.
+type CharTokenEstimator struct{}
+
+// EstimateTokens implements TokenEstimator.
+func (CharTokenEstimator) EstimateTokens(scratchpad []*Iteration) int {
+	return (ScratchpadBytes(scratchpad) + 3) / 4
+}
+
+// ScratchpadBytes returns the byte length of the text in the
+// scratchpad's messages, as tracked by SGScratchpadBytes.
+// Non-text parts are not counted.
+func ScratchpadBytes(scratchpad []*Iteration) int {
+	bytes := 0
+	for _, iter := range scratchpad {
+		if iter == nil {
+			continue
+		}
+		for _, msg := range iter.Messages {
+			if msg == nil {
+				continue
+			}
+			for _, part := range msg.Parts {
+				if text, ok := part.(llms.TextContent); ok {
+					bytes += len(text.Text)
+				}
+			}
+		}
+	}
+	return bytes
+}
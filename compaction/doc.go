@@ -11,5 +11,20 @@
 //
 //   - [SlidingWindowStrategy]: keeps last N iterations
 //   - [SummarizationStrategy]: progressive summarization
-//     with configurable keep-recent window
+//     with configurable keep-recent window, optionally into
+//     a typed JSON summary (see [WithStructuredSchema])
+//
+// # Background Compaction
+//
+// With executor.Config.AsyncCompaction, a strategy may run
+// after the execution has returned its answer, on a copy of
+// the scratchpad in a child context. Strategies only need to
+// read and set the scratchpad of the context they are given.
+//
+// # Ephemeral Sections
+//
+// Agent loops remove [gent.EphemeralSection] content before
+// iterations reach the scratchpad, so strategies never see,
+// summarize or retain it. The iteration history keeps the
+// full response.
 package compaction
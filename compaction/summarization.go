@@ -1,10 +1,12 @@
 package compaction
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/schema"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -81,6 +83,12 @@ import (
 //     detail before any framing. This gets worse as
 //     pinned count grows.
 //
+// This is the stored order. The order the agent renders
+// it in is set by executor.Config.ScratchpadLayout; with
+// [gent.ScratchpadSummaryFirstThenRecent] the synthetic
+// iteration is rendered first even if other iterations
+// were placed before it after compaction.
+//
 // Note: [SlidingWindowStrategy] does not have this
 // limitation — it preserves relative ordering because it
 // only drops iterations without merging them.
@@ -109,6 +117,16 @@ import (
 // can be cached. Override with [WithPrompt] to use a single
 // user message instead (disables prompt caching).
 //
+// # Structured Summaries
+//
+// By default the summary is free text. [WithStructuredSchema]
+// makes the strategy ask for a JSON object matching a Go type
+// instead, so downstream prompts and code can reference
+// individual fields. A summary that parses and validates is
+// stored pinned, with the decoded value available through
+// [StructuredSummary]. If the model's output does not match
+// the schema, the raw text is stored as a plain synopsis.
+//
 // # Example
 //
 //	strategy := compaction.NewSummarization(model).
@@ -118,6 +136,22 @@ type SummarizationStrategy struct {
 	keepRecent   int
 	systemPrompt string
 	userPrompt   string
+	structured   *structuredSummary
+}
+
+// IMKStructuredSummary is the iteration metadata key holding
+// the decoded value of a structured summary. It is only set
+// on synthetic iterations produced by a
+// [SummarizationStrategy] configured with
+// [WithStructuredSchema]. Use [StructuredSummary] to read it.
+const IMKStructuredSummary gent.IterationMetadataKey = "gent:structured_summary"
+
+// structuredSummary holds the target type set by
+// WithStructuredSchema.
+type structuredSummary struct {
+	schema    map[string]any
+	validator *schema.Schema
+	decode    func(content string) (any, error)
 }
 
 // NewSummarization creates a SummarizationStrategy with the
@@ -183,6 +217,133 @@ func (s *SummarizationStrategy) WithUserPrompt(
 	return s
 }
 
+// WithStructuredSchema makes s summarize into a JSON object
+// matching type T instead of free text. Go does not allow
+// type parameters on methods, so this is a function taking
+// the strategy rather than a method on it.
+//
+// The JSON Schema of T (see [schema.For]) is appended to the
+// instructions: to the system prompt when one is set, or to
+// the user message after [SummarizationStrategy.WithPrompt].
+// The model's output is validated against the schema and
+// decoded into T. On success the synthetic iteration holds
+// the JSON, is pinned (so strategies like
+// [SlidingWindowStrategy] never drop it), and carries the
+// decoded T under [IMKStructuredSummary]. On failure the raw
+// output is stored as an unpinned plain-text synopsis, so a
+// malformed response never loses the summary.
+//
+// Example:
+//
+//	type Synopsis struct {
+//	    KeyFacts      []string `json:"key_facts"`
+//	    OpenQuestions []string `json:"open_questions"`
+//	    Decisions     []string `json:"decisions"`
+//	}
+//
+//	strategy := compaction.WithStructuredSchema[Synopsis](
+//	    compaction.NewSummarization(model),
+//	)
+func WithStructuredSchema[T any](
+	s *SummarizationStrategy,
+) *SummarizationStrategy {
+	raw := schema.For[T]()
+	s.structured = &structuredSummary{
+		schema:    raw,
+		validator: schema.MustCompile(raw),
+		decode: func(content string) (any, error) {
+			var value T
+			if err := json.Unmarshal(
+				[]byte(content), &value,
+			); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	}
+	return s
+}
+
+// StructuredSummary returns the decoded structured summary
+// stored on iter by a [SummarizationStrategy] configured
+// with [WithStructuredSchema]. Returns the zero value and
+// false if iter has none, or if it was decoded into a
+// different type.
+func StructuredSummary[T any](iter *gent.Iteration) (T, bool) {
+	val, ok := iter.GetMetadata(IMKStructuredSummary)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	value, ok := val.(T)
+	return value, ok
+}
+
+// structuredSummaryInstructions is appended to the
+// summarization prompt by WithStructuredSchema. It takes the
+// JSON Schema of the target type as its only placeholder.
+const structuredSummaryInstructions = `
+
+## Structured Output
+
+Instead of writing the sections as prose, write the ` +
+	`summary as a single JSON object matching this ` +
+	`schema. Carry the same information into the ` +
+	`matching fields. Respond with the JSON object ` +
+	`only, without code fences or surrounding text.
+
+%s`
+
+// instructions returns the prompt text describing the
+// target schema.
+func (st *structuredSummary) instructions() string {
+	schemaJSON, err := json.MarshalIndent(st.schema, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(
+		structuredSummaryInstructions, schemaJSON,
+	)
+}
+
+// parse validates the model output against the schema and
+// decodes it. Returns the JSON content with any surrounding
+// code fence removed, and the decoded value.
+func (st *structuredSummary) parse(
+	output string,
+) (string, any, error) {
+	content := stripCodeFence(strings.TrimSpace(output))
+
+	var raw any
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return "", nil, err
+	}
+	if err := st.validator.ValidateValue(raw); err != nil {
+		return "", nil, err
+	}
+	value, err := st.decode(content)
+	if err != nil {
+		return "", nil, err
+	}
+	return content, value, nil
+}
+
+// stripCodeFence removes a surrounding markdown code fence
+// (```json ... ```), which models frequently add even when
+// asked not to.
+func stripCodeFence(content string) string {
+	if !strings.HasPrefix(content, "```") ||
+		!strings.HasSuffix(content, "```") {
+		return content
+	}
+	content = strings.TrimSuffix(content, "```")
+	newline := strings.Index(content, "\n")
+	if newline < 0 {
+		return ""
+	}
+	return strings.TrimSpace(content[newline+1:])
+}
+
 // DefaultSummarizationPrompt is the combined prompt used
 // by [SummarizationStrategy.WithPrompt] for single-message
 // mode. It contains both instructions and dynamic content
@@ -635,17 +796,20 @@ func (s *SummarizationStrategy) Compact(
 		nonPinned       []*gent.Iteration
 	)
 
-	// First pass: extract pinned and existing summary
+	// First pass: extract pinned and existing summary.
+	// Synthetic iterations are checked first because
+	// structured summaries are pinned, yet must still be
+	// updated rather than kept alongside the new summary.
 	for _, iter := range scratchpad {
-		if isPinned(iter) {
-			pinned = append(pinned, iter)
-			continue
-		}
 		if iter.Origin ==
 			gent.IterationCompactedSynthetic {
 			existingSummary = iter
 			continue
 		}
+		if isPinned(iter) {
+			pinned = append(pinned, iter)
+			continue
+		}
 		nonPinned = append(nonPinned, iter)
 	}
 
@@ -683,6 +847,14 @@ func (s *SummarizationStrategy) Compact(
 	userText := fmt.Sprintf(
 		s.userPrompt, existingText, newMessages,
 	)
+	systemPrompt := s.systemPrompt
+	if s.structured != nil {
+		if systemPrompt != "" {
+			systemPrompt += s.structured.instructions()
+		} else {
+			userText += s.structured.instructions()
+		}
+	}
 
 	// Call model directly without TextFormat/TextSection.
 	// This is a one-shot call where the entire output is
@@ -694,13 +866,13 @@ func (s *SummarizationStrategy) Compact(
 	// (e.g., after WithPrompt), we send a single user
 	// message containing everything.
 	var messages []llms.MessageContent
-	if s.systemPrompt != "" {
+	if systemPrompt != "" {
 		messages = []llms.MessageContent{
 			{
 				Role: llms.ChatMessageTypeSystem,
 				Parts: []llms.ContentPart{
 					llms.TextContent{
-						Text: s.systemPrompt,
+						Text: systemPrompt,
 					},
 				},
 			},
@@ -764,6 +936,22 @@ func (s *SummarizationStrategy) Compact(
 		Origin: gent.IterationCompactedSynthetic,
 	}
 
+	// A structured summary that fails to parse falls
+	// back to the raw text as a plain synopsis.
+	if s.structured != nil {
+		content, value, err := s.structured.parse(summaryText)
+		if err == nil {
+			synthetic.Messages[0].Parts = []gent.ContentPart{
+				llms.TextContent{Text: content},
+			}
+			synthetic.SetMetadata(
+				gent.IMKImportanceScore,
+				gent.ImportanceScorePinned,
+			)
+			synthetic.SetMetadata(IMKStructuredSummary, value)
+		}
+	}
+
 	// Rebuild scratchpad: synthetic + pinned + toKeep.
 	// See "Result Ordering" in the type doc for why this
 	// ordering is used and why chronological ordering
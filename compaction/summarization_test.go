@@ -740,3 +740,208 @@ func TestSummarization_DefaultTwoMessages(t *testing.T) {
 			"static instructions",
 	)
 }
+
+type testSynopsis struct {
+	KeyFacts      []string `json:"key_facts"`
+	OpenQuestions []string `json:"open_questions"`
+	Decisions     []string `json:"decisions"`
+}
+
+func TestSummarization_WithStructuredSchema(t *testing.T) {
+	type input struct {
+		singleMessage bool
+		scratchpad    []*gent.Iteration
+		modelResponse string
+	}
+
+	type expected struct {
+		promptContains string // in system, or user in single-message mode
+		scratchpadLen  int
+		summaryText    string
+		pinned         bool
+		synopsis       *testSynopsis
+	}
+
+	structuredSynthetic := &gent.Iteration{
+		Messages: []*gent.MessageContent{
+			{
+				Role: llms.ChatMessageTypeGeneric,
+				Parts: []gent.ContentPart{
+					llms.TextContent{
+						Text: `{"key_facts":["old"],` +
+							`"open_questions":[],"decisions":[]}`,
+					},
+				},
+			},
+		},
+		Origin: gent.IterationCompactedSynthetic,
+		Metadata: map[gent.IterationMetadataKey]any{
+			gent.IMKImportanceScore: gent.ImportanceScorePinned,
+		},
+	}
+
+	validJSON := `{"key_facts":["uses postgres"],` +
+		`"open_questions":["which index?"],` +
+		`"decisions":["batch size 100"]}`
+	validSynopsis := &testSynopsis{
+		KeyFacts:      []string{"uses postgres"},
+		OpenQuestions: []string{"which index?"},
+		Decisions:     []string{"batch size 100"},
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "valid JSON stored as pinned synopsis",
+			input: input{
+				scratchpad: []*gent.Iteration{
+					makeIter("step 1"),
+					makeIter("step 2"),
+				},
+				modelResponse: validJSON,
+			},
+			expected: expected{
+				promptContains: `"key_facts"`,
+				scratchpadLen:  1,
+				summaryText:    validJSON,
+				pinned:         true,
+				synopsis:       validSynopsis,
+			},
+		},
+		{
+			name: "code fence stripped",
+			input: input{
+				scratchpad: []*gent.Iteration{
+					makeIter("step 1"),
+				},
+				modelResponse: "```json\n" + validJSON + "\n```",
+			},
+			expected: expected{
+				promptContains: "## Structured Output",
+				scratchpadLen:  1,
+				summaryText:    validJSON,
+				pinned:         true,
+				synopsis:       validSynopsis,
+			},
+		},
+		{
+			name: "schema appended to user message in single-message mode",
+			input: input{
+				singleMessage: true,
+				scratchpad: []*gent.Iteration{
+					makeIter("step 1"),
+				},
+				modelResponse: validJSON,
+			},
+			expected: expected{
+				promptContains: `"open_questions"`,
+				scratchpadLen:  1,
+				summaryText:    validJSON,
+				pinned:         true,
+				synopsis:       validSynopsis,
+			},
+		},
+		{
+			name: "invalid JSON falls back to plain text",
+			input: input{
+				scratchpad: []*gent.Iteration{
+					makeIter("step 1"),
+				},
+				modelResponse: "Task: migrate the database",
+			},
+			expected: expected{
+				promptContains: "## Structured Output",
+				scratchpadLen:  1,
+				summaryText:    "Task: migrate the database",
+			},
+		},
+		{
+			name: "schema mismatch falls back to plain text",
+			input: input{
+				scratchpad: []*gent.Iteration{
+					makeIter("step 1"),
+				},
+				modelResponse: `{"key_facts":"not a list"}`,
+			},
+			expected: expected{
+				promptContains: "## Structured Output",
+				scratchpadLen:  1,
+				summaryText:    `{"key_facts":"not a list"}`,
+			},
+		},
+		{
+			name: "pinned structured summary is replaced not kept",
+			input: input{
+				scratchpad: []*gent.Iteration{
+					structuredSynthetic,
+					makeIter("step 3"),
+				},
+				modelResponse: validJSON,
+			},
+			expected: expected{
+				promptContains: "## Structured Output",
+				scratchpadLen:  1,
+				summaryText:    validJSON,
+				pinned:         true,
+				synopsis:       validSynopsis,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel()
+			model.AddResponse(tc.input.modelResponse, 10, 5)
+
+			strategy := NewSummarization(model)
+			if tc.input.singleMessage {
+				strategy.WithPrompt("Custom: %s\n%s")
+			}
+			strategy = WithStructuredSchema[testSynopsis](strategy)
+
+			data := gent.NewBasicLoopData(nil)
+			data.SetScratchPad(tc.input.scratchpad)
+			execCtx := gent.NewExecutionContext(
+				context.Background(), "test", data,
+			)
+			execCtx.SetLimits(nil)
+
+			err := strategy.Compact(execCtx)
+			assert.NoError(t, err)
+
+			msgs := model.CapturedMessages[0]
+			prompt := msgs[0].Parts[0].(llms.TextContent)
+			assert.Contains(t, prompt.Text,
+				tc.expected.promptContains,
+			)
+			if tc.input.scratchpad[0] == structuredSynthetic {
+				user := msgs[1].Parts[0].(llms.TextContent)
+				assert.Contains(t, user.Text, `"old"`)
+			}
+
+			result := data.GetScratchPad()
+			assert.Len(t, result, tc.expected.scratchpadLen)
+			synthetic := result[0]
+			assert.Equal(t,
+				gent.IterationCompactedSynthetic,
+				synthetic.Origin,
+			)
+			assert.Equal(t,
+				tc.expected.summaryText,
+				extractText(synthetic),
+			)
+			assert.Equal(t, tc.expected.pinned, isPinned(synthetic))
+
+			synopsis, ok := StructuredSummary[testSynopsis](synthetic)
+			if tc.expected.synopsis == nil {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, *tc.expected.synopsis, synopsis)
+		})
+	}
+}
@@ -3,12 +3,14 @@ package gent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/pmezard/go-difflib/difflib"
+	"github.com/tmc/langchaingo/llms"
 )
 
 // EventPublisher is an interface for dispatching events to subscribers.
@@ -83,7 +85,61 @@ type ExecutionContext struct {
 
 	// Limits that trigger execution termination
 	limits        []Limit
-	exceededLimit *Limit // set when a limit is exceeded
+	exceededLimit *Limit          // set when a limit is exceeded
+	exceededKey   StatKey         // the stat key that exceeded exceededLimit
+	limitsFired   map[*Limit]bool // OnExceeded limits whose callback chose to continue
+
+	// Limit drain: defer cancellation after a limit is exceeded (see SetLimitDrain)
+	limitDrain      bool
+	limitDrainGrace time.Duration // 0 means wait for FinishLimitDrain
+	pendingCancel   error         // cancel cause held back while draining
+	drainTimer      *time.Timer   // fires the pending cancel after the grace period
+
+	// Tool call loop detection (see SetRepeatedToolCallPolicy)
+	toolCalls toolCallTracker
+
+	// Unique tool names invoked here or in children (see SGDistinctToolsUsed)
+	distinctTools map[string]bool
+
+	// Raw output of the last format parse error (see
+	// SGFormatParseErrorIdenticalConsecutive)
+	lastFormatParseErrorOutput string
+
+	// Number of validator scores recorded (see SGValidatorScoreAvg)
+	validatorScores int
+
+	// How subscriber panics are handled (see SetHookPanicPolicy)
+	hookPanicPolicy HookPanicPolicy
+
+	// Order in which AgentLoops render the scratchpad (see SetScratchpadLayout)
+	scratchpadLayout ScratchpadLayout
+
+	// Self-critique step before an answer is final (see SetReflectionPass)
+	reflectionPass ReflectionPass
+
+	// Receives the raw I/O of every model call (see SetRawIOSink)
+	rawIOSink RawIOSink
+
+	// Opaque per-run metadata for model adapters (see SetRequestMetadata)
+	requestMetadata map[string]string
+
+	// Source of the current time (see SetClock)
+	clock clockRef
+
+	// Whether this is the shared context of a BudgetRoot; set before children exist
+	budget bool
+
+	// One-time messages for the next model call (see EnqueueEphemeralMessage)
+	ephemeralMessages []llms.MessageContent
+
+	// Supplementary tool output for the next prompt only (see AttachEphemeralObservation)
+	ephemeralObservations []string
+
+	// Tool calls and output of the current iteration (see IterationToolUsage)
+	toolUsage iterationToolUsage
+
+	// Hooks run on each BeforeModelCallEvent (see InterceptModelCalls)
+	modelCallInterceptors []*modelCallInterceptor
 
 	// Execution result (populated on termination)
 	result *ExecutionResult
@@ -114,8 +170,13 @@ type ExecutionContext struct {
 
 	// Termination
 	terminationReason TerminationReason
+	customReason      TerminationReason // reported instead of TerminationSuccess
+	terminatedBy      string            // name of the termination that accepted the answer
+	terminatedByTool  bool              // terminatedBy names a TerminalTool
+	candidates        []AnswerCandidate // candidate answers of the accepting step
 	finalResult       []ContentPart
 	err               error
+	lastError         error // error of the last ErrorEvent (see Outcome)
 
 	// Event publisher for dispatching events to subscribers (set by Executor)
 	eventPublisher EventPublisher
@@ -127,6 +188,8 @@ type ExecutionContext struct {
 	// Compaction configuration (optional)
 	compactionTrigger  CompactionTrigger
 	compactionStrategy CompactionStrategy
+	tokenEstimator     TokenEstimator
+	compactionRequest  *Limit // set by RequestCompaction, cleared by FinishCompactionRequest
 }
 
 // NewExecutionContext creates a new root ExecutionContext with the given name and data.
@@ -151,6 +214,8 @@ func NewExecutionContext(ctx context.Context, name string, data LoopData) *Execu
 	}
 	// Create stats with back-reference for limit checking
 	execCtx.stats = newExecutionStatsWithContext(execCtx)
+	// Let tools reach the ExecutionContext through context.Context (see ExecutionContextFrom)
+	execCtx.goCtx = context.WithValue(ctx, execCtxKey{}, execCtx)
 	// Set execution context on LoopData for automatic event publishing
 	if data != nil {
 		data.SetExecutionContext(execCtx)
@@ -158,6 +223,10 @@ func NewExecutionContext(ctx context.Context, name string, data LoopData) *Execu
 	return execCtx
 }
 
+// execCtxKey is the context key under which an ExecutionContext stores itself in its
+// context.Context.
+type execCtxKey struct{}
+
 // -----------------------------------------------------------------------------
 // Data Access
 // -----------------------------------------------------------------------------
@@ -169,6 +238,89 @@ func (ctx *ExecutionContext) Data() LoopData {
 	return ctx.data
 }
 
+// ScratchpadView returns a copy of the loop's current scratchpad: the iterations the
+// AgentLoop will send to the model in its next iteration. It reflects compaction, so
+// iterations removed by a CompactionStrategy (or replaced by a summary) are not
+// included. Returns nil if the context has no LoopData.
+//
+// The view is taken at call time and each iteration is cloned (see [Iteration.Clone]),
+// so tools and subscribers can read it freely without affecting loop state.
+func (ctx *ExecutionContext) ScratchpadView() []*Iteration {
+	data := ctx.Data()
+	if data == nil {
+		return nil
+	}
+	return cloneIterations(data.GetScratchPad())
+}
+
+// IterationHistoryView returns a copy of the loop's full iteration history, including
+// iterations compacted away from the scratchpad. Returns nil if the context has no
+// LoopData.
+//
+// Like ScratchpadView, the view is taken at call time and each iteration is cloned.
+func (ctx *ExecutionContext) IterationHistoryView() []*Iteration {
+	data := ctx.Data()
+	if data == nil {
+		return nil
+	}
+	return cloneIterations(data.GetIterationHistory())
+}
+
+// cloneIterations clones every iteration in iterations.
+func cloneIterations(iterations []*Iteration) []*Iteration {
+	if iterations == nil {
+		return nil
+	}
+	result := make([]*Iteration, len(iterations))
+	for i, iter := range iterations {
+		if iter != nil {
+			result[i] = iter.Clone()
+		}
+	}
+	return result
+}
+
+// ExecutionContextFrom returns the ExecutionContext that ctx belongs to, or nil if ctx
+// was not derived from ExecutionContext.Context(). Tools receive such a context in
+// their Call method, so they can use it to inspect the execution:
+//
+//	func(ctx context.Context, input RefundInput) (string, error) {
+//	    if execCtx := gent.ExecutionContextFrom(ctx); execCtx != nil {
+//	        for _, iter := range execCtx.IterationHistoryView() {
+//	            // look up the user's original constraints
+//	        }
+//	    }
+//	    ...
+//	}
+func ExecutionContextFrom(ctx context.Context) *ExecutionContext {
+	if ctx == nil {
+		return nil
+	}
+	execCtx, _ := ctx.Value(execCtxKey{}).(*ExecutionContext)
+	return execCtx
+}
+
+// toolNameKey is the context key under which toolchains store the name of the tool
+// being called.
+type toolNameKey struct{}
+
+// ContextWithToolName returns a copy of ctx that records the name of the tool being
+// called. ToolChains wrap the context they pass to a tool's Call method with it, so
+// [ReportToolProgress] can attribute progress to the tool.
+func ContextWithToolName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, toolNameKey{}, name)
+}
+
+// ToolNameFrom returns the name of the tool being called, as set by
+// [ContextWithToolName], or "" if ctx is not a tool call context.
+func ToolNameFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(toolNameKey{}).(string)
+	return name
+}
+
 // Name returns the name of this execution context.
 func (ctx *ExecutionContext) Name() string {
 	ctx.mu.RLock()
@@ -192,24 +344,101 @@ func (ctx *ExecutionContext) SetEventPublisher(publisher EventPublisher) {
 // Use this when calling external APIs that require context.Context.
 //
 // The context is cancelled when:
-//   - A configured limit is exceeded
+//   - A configured limit is exceeded (deferred while draining, see SetLimitDrain)
 //   - The parent context is cancelled
 //   - The execution terminates
 func (ctx *ExecutionContext) Context() context.Context {
 	return ctx.goCtx
 }
 
+// IsDryRun reports whether this execution is a dry run, i.e. its context was created
+// with [WithDryRun]. Tools should skip side-effecting operations during a dry run.
+func (ctx *ExecutionContext) IsDryRun() bool {
+	return IsDryRun(ctx.goCtx)
+}
+
 // SetLimits configures the limits for this execution.
 // Replaces any previously set limits, including defaults.
 //
 // Limits are evaluated on every stat update. When a limit is exceeded,
 // the context is cancelled and ExceededLimit() returns the exceeded limit.
+// Use [ExecutionContext.SetLimitDrain] to defer the cancellation instead.
 //
 // Must be called before execution starts.
 func (ctx *ExecutionContext) SetLimits(limits []Limit) {
 	ctx.mu.Lock()
 	defer ctx.mu.Unlock()
 	ctx.limits = limits
+	ctx.limitsFired = nil
+}
+
+// SetLimitDrain controls what happens to the context when a limit is exceeded.
+//
+// With drain disabled (the default), the context is cancelled as soon as the limit is
+// exceeded, interrupting any in-flight model or tool call.
+//
+// With drain enabled, ExceededLimit() is set and the LimitExceededEvent is published
+// immediately, but cancellation is held back until [ExecutionContext.FinishLimitDrain] is
+// called, so in-flight calls can finish cleanly. If grace is positive, the context is
+// cancelled anyway once grace has elapsed since the limit was exceeded.
+//
+// Child contexts spawned afterwards inherit the setting. Usually configured through
+// executor.Config.LimitDrainMode rather than called directly.
+//
+// Must be called before execution starts.
+func (ctx *ExecutionContext) SetLimitDrain(enabled bool, grace time.Duration) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.limitDrain = enabled
+	ctx.limitDrainGrace = grace
+}
+
+// FinishLimitDrain cancels this context and its descendants if they are holding back
+// cancellation for an exceeded limit. It is a no-op when nothing is pending.
+//
+// The Executor calls this at iteration boundaries when draining is enabled.
+func (ctx *ExecutionContext) FinishLimitDrain() {
+	var cause error
+	var children []*ExecutionContext
+
+	ctx.updateContextState(func() {
+		cause = ctx.pendingCancel
+		ctx.pendingCancel = nil
+		if ctx.drainTimer != nil {
+			ctx.drainTimer.Stop()
+			ctx.drainTimer = nil
+		}
+		children = append(children, ctx.children...)
+	})
+
+	if cause != nil {
+		ctx.cancel(cause)
+	}
+	for _, child := range children {
+		child.FinishLimitDrain()
+	}
+}
+
+// cancelForLimit cancels the context for an exceeded limit, or holds the cancellation
+// back when draining is enabled.
+func (ctx *ExecutionContext) cancelForLimit(cause error) {
+	drain := false
+	ctx.updateContextState(func() {
+		if !ctx.limitDrain {
+			return
+		}
+		drain = true
+		ctx.pendingCancel = cause
+		if ctx.limitDrainGrace > 0 {
+			ctx.drainTimer = time.AfterFunc(ctx.limitDrainGrace, func() {
+				ctx.cancel(cause)
+			})
+		}
+	})
+
+	if !drain {
+		ctx.cancel(cause)
+	}
 }
 
 // Limits returns the configured limits.
@@ -252,6 +481,29 @@ func (ctx *ExecutionContext) SetCompaction(
 	ctx.compactionStrategy = strategy
 }
 
+// SetTokenEstimator sets the estimator used to measure the
+// scratchpad before and after each compaction, reported in
+// CompactionEvent and SCCompactionTokensSaved. Defaults to
+// CharTokenEstimator.
+//
+// Must be called before execution starts.
+func (ctx *ExecutionContext) SetTokenEstimator(estimator TokenEstimator) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.tokenEstimator = estimator
+}
+
+// TokenEstimator returns the configured estimator, or
+// CharTokenEstimator if none is set.
+func (ctx *ExecutionContext) TokenEstimator() TokenEstimator {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	if ctx.tokenEstimator == nil {
+		return CharTokenEstimator{}
+	}
+	return ctx.tokenEstimator
+}
+
 // CompactionTrigger returns the configured trigger, or nil.
 func (ctx *ExecutionContext) CompactionTrigger() CompactionTrigger {
 	ctx.mu.RLock()
@@ -267,13 +519,74 @@ func (ctx *ExecutionContext) CompactionStrategy() CompactionStrategy {
 	return ctx.compactionStrategy
 }
 
+// RequestCompaction asks the executor to compact the scratchpad
+// before the next iteration, even if the compaction trigger doesn't
+// fire, to bring limit back within bounds. Typically called from a
+// [Limit.OnExceeded] callback, see ScratchpadBytesLimit.
+//
+// If limit is still exceeded after compacting, execution terminates
+// with TerminationLimitExceeded, as for a limit without callback.
+// Has no effect unless compaction is configured with SetCompaction.
+func (ctx *ExecutionContext) RequestCompaction(limit Limit) {
+	limit.OnExceeded = nil
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.compactionRequest = &limit
+}
+
+// CompactionRequested reports whether RequestCompaction was called
+// since the last FinishCompactionRequest.
+func (ctx *ExecutionContext) CompactionRequested() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.compactionRequest != nil
+}
+
+// FinishCompactionRequest clears the pending compaction request. Called
+// by the executor after compacting; if the request's limit is still
+// exceeded, the limit is reported by ExceededLimit and the context is
+// cancelled.
+func (ctx *ExecutionContext) FinishCompactionRequest() {
+	var info *limitExceededInfo
+	ctx.updateContextState(func() {
+		limit := ctx.compactionRequest
+		ctx.compactionRequest = nil
+		if limit == nil || ctx.exceededLimit != nil {
+			return
+		}
+		info = ctx.checkLimitLocked(limit)
+		if info != nil {
+			ctx.exceededLimit = info.limit
+			ctx.exceededKey = info.matchedKey
+		}
+	})
+	if info == nil {
+		return
+	}
+
+	ctx.PublishLimitExceeded(*info.limit, info.currentValue, info.matchedKey)
+	ctx.cancelForLimit(
+		fmt.Errorf("limit exceeded: %s > %v", info.limit.Key, info.limit.MaxValue),
+	)
+}
+
 // ExceededLimit returns the limit that was exceeded, or nil if no limit was exceeded.
+// See [Limit] for which limit is reported when several are exceeded at once.
 func (ctx *ExecutionContext) ExceededLimit() *Limit {
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
 	return ctx.exceededLimit
 }
 
+// ExceededLimitKey returns the stat key that exceeded [ExecutionContext.ExceededLimit],
+// or "" if no limit was exceeded. For prefix limits this is the specific key, e.g.
+// SCToolCallsFor+"search" for a limit on SCToolCallsFor.
+func (ctx *ExecutionContext) ExceededLimitKey() StatKey {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.exceededKey
+}
+
 // Result returns the execution result. Only valid after execution completes.
 // Returns nil if execution has not completed.
 func (ctx *ExecutionContext) Result() *ExecutionResult {
@@ -312,7 +625,16 @@ func (ctx *ExecutionContext) checkLimits() {
 			return
 		}
 
+		if info.limit.OnExceeded != nil {
+			// Fire the callback once until the stat drops back within the limit
+			if ctx.limitsFired == nil {
+				ctx.limitsFired = make(map[*Limit]bool)
+			}
+			ctx.limitsFired[info.limit] = true
+			return
+		}
 		ctx.exceededLimit = info.limit
+		ctx.exceededKey = info.matchedKey
 	})
 
 	if info == nil {
@@ -322,21 +644,62 @@ func (ctx *ExecutionContext) checkLimits() {
 	// Publish event outside lock to avoid deadlock
 	ctx.PublishLimitExceeded(*info.limit, info.currentValue, info.matchedKey)
 
+	if info.limit.OnExceeded != nil {
+		if info.limit.OnExceeded(ctx) == LimitActionContinue {
+			return
+		}
+
+		terminate := false
+		ctx.updateContextState(func() {
+			if ctx.exceededLimit == nil {
+				ctx.exceededLimit = info.limit
+				ctx.exceededKey = info.matchedKey
+				terminate = true
+			}
+		})
+		if !terminate {
+			return // another limit was exceeded while the callback ran
+		}
+	}
+
 	// Cancel context after publishing event
-	ctx.cancel(fmt.Errorf("limit exceeded: %s > %v", info.limit.Key, info.limit.MaxValue))
+	ctx.cancelForLimit(
+		fmt.Errorf("limit exceeded: %s > %v", info.limit.Key, info.limit.MaxValue),
+	)
 }
 
 // evaluateLimitsLocked evaluates all limits against current stats.
-// Returns info about the first exceeded limit, or nil if all limits are within bounds.
+// Returns info about the exceeded limit that takes precedence (see limitPrecedes), or
+// nil if all limits are within bounds.
 // Must be called with lock held.
 func (ctx *ExecutionContext) evaluateLimitsLocked() *limitExceededInfo {
+	var exceeded *limitExceededInfo
 	for i := range ctx.limits {
 		limit := &ctx.limits[i]
-		if info := ctx.checkLimitLocked(limit); info != nil {
-			return info
+		info := ctx.checkLimitLocked(limit)
+
+		if limit.OnExceeded != nil && ctx.limitsFired[limit] {
+			if info == nil {
+				delete(ctx.limitsFired, limit) // back within the limit, re-arm
+			}
+			continue
+		}
+
+		if info != nil && (exceeded == nil || limitPrecedes(info.limit, exceeded.limit)) {
+			exceeded = info
 		}
 	}
-	return nil
+	return exceeded
+}
+
+// limitPrecedes reports whether exceeded limit a takes precedence over b: exact keys
+// over prefixes, then the lower MaxValue. Equal limits keep slice order.
+func limitPrecedes(a, b *Limit) bool {
+	aExact, bExact := a.Type == LimitExactKey, b.Type == LimitExactKey
+	if aExact != bExact {
+		return aExact
+	}
+	return a.MaxValue < b.MaxValue
 }
 
 // checkLimitLocked checks if a single limit is exceeded.
@@ -388,31 +751,32 @@ func (ctx *ExecutionContext) checkPrefixLimit(
 	limit *Limit,
 ) *limitExceededInfo {
 	prefix := string(limit.Key)
-	// Check all counters with matching prefix
-	for key, val := range ctx.stats.Counters() {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			if float64(val) > limit.MaxValue {
-				return &limitExceededInfo{
-					limit:        limit,
-					currentValue: float64(val),
-					matchedKey:   StatKey(key),
-				}
+	// Keys are checked in sorted order so the reported key is deterministic when
+	// several keys exceed the limit
+	var info *limitExceededInfo
+	consider := func(key string, val float64) {
+		if !strings.HasPrefix(key, prefix) || val <= limit.MaxValue {
+			return
+		}
+		if info == nil || key < string(info.matchedKey) {
+			info = &limitExceededInfo{
+				limit:        limit,
+				currentValue: val,
+				matchedKey:   StatKey(key),
 			}
 		}
 	}
-	// Check all gauges with matching prefix
-	for key, val := range ctx.stats.Gauges() {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			if val > limit.MaxValue {
-				return &limitExceededInfo{
-					limit:        limit,
-					currentValue: val,
-					matchedKey:   StatKey(key),
-				}
-			}
+	// Check all counters with matching prefix
+	for key, val := range ctx.stats.Counters() {
+		consider(key, float64(val))
+	}
+	// Check all gauges with matching prefix, unless a counter already matched
+	if info == nil {
+		for key, val := range ctx.stats.Gauges() {
+			consider(key, val)
 		}
 	}
-	return nil
+	return info
 }
 
 // -----------------------------------------------------------------------------
@@ -466,8 +830,17 @@ func (ctx *ExecutionContext) publish(event Event) {
 		// Append to event log
 		ctx.events = append(ctx.events, event)
 
+		// Record the result of a call checked by CheckRepeatedToolCall
+		if e, ok := event.(*AfterToolCallEvent); ok {
+			ctx.toolCalls.recordToolCallResult(e)
+		}
+
 		publisher = ctx.eventPublisher
 	})
+	// Deferred so the depth stays correct when a subscriber panic propagates
+	defer ctx.updateContextState(func() {
+		ctx.eventDepth--
+	})
 
 	// Update stats based on event type (outside lock because incrCounterDirect calls checkLimits)
 	ctx.updateStatsForEvent(event)
@@ -476,10 +849,27 @@ func (ctx *ExecutionContext) publish(event Event) {
 	if publisher != nil {
 		publisher.Dispatch(ctx, event)
 	}
+}
 
-	ctx.updateContextState(func() {
-		ctx.eventDepth--
-	})
+// recordDistinctTool adds name to the distinct tool set of this context and every
+// ancestor, updating SGDistinctToolsUsed wherever the set grew.
+func (ctx *ExecutionContext) recordDistinctTool(name string) {
+	for c := ctx; c != nil; c = c.parent {
+		var count int
+		c.updateContextState(func() {
+			if c.distinctTools[name] {
+				return
+			}
+			if c.distinctTools == nil {
+				c.distinctTools = make(map[string]bool)
+			}
+			c.distinctTools[name] = true
+			count = len(c.distinctTools)
+		})
+		if count > 0 {
+			c.stats.SetGauge(SGDistinctToolsUsed, float64(count))
+		}
+	}
 }
 
 // Publish records a custom event, updates stats, checks limits, and dispatches to subscribers.
@@ -493,67 +883,87 @@ func (ctx *ExecutionContext) Publish(event Event) {
 func (ctx *ExecutionContext) populateBaseEvent(event Event) {
 	switch e := event.(type) {
 	case *BeforeExecutionEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *AfterExecutionEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *BeforeIterationEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *AfterIterationEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *BeforeModelCallEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *AfterModelCallEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *BeforeToolCallEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *AfterToolCallEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
+		e.Iteration = ctx.iteration
+		e.Depth = ctx.depth
+	case *RepeatedToolCallEvent:
+		e.Timestamp = ctx.now()
+		e.Iteration = ctx.iteration
+		e.Depth = ctx.depth
+	case *ToolProgressEvent:
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *ParseErrorEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
+		e.Iteration = ctx.iteration
+		e.Depth = ctx.depth
+	case *FormatFallbackEvent:
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *ValidatorCalledEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *ValidatorResultEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *ErrorEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *CommonEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *CommonDiffEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *LimitExceededEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	case *CompactionEvent:
-		e.Timestamp = time.Now()
+		e.Timestamp = ctx.now()
+		e.Iteration = ctx.iteration
+		e.Depth = ctx.depth
+	case *HandoffEvent:
+		e.Timestamp = ctx.now()
+		e.Iteration = ctx.iteration
+		e.Depth = ctx.depth
+	case *AnswerDiscardedEvent:
+		e.Timestamp = ctx.now()
 		e.Iteration = ctx.iteration
 		e.Depth = ctx.depth
 	}
@@ -586,19 +996,36 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 			ctx.stats.incrCounterDirect(
 				SCToolCallsFor+StatKey(e.ToolName), 1,
 			)
+			ctx.recordDistinctTool(e.ToolName)
+		}
+
+	case *RepeatedToolCallEvent:
+		ctx.stats.incrCounterDirect(SCRepeatedToolCalls, 1)
+		if e.ToolName != "" {
+			ctx.stats.incrCounterDirect(
+				SCRepeatedToolCallsFor+StatKey(e.ToolName), 1,
+			)
 		}
 
+	case *FormatFallbackEvent:
+		ctx.stats.incrCounterDirect(SCFormatFallbacks, 1)
+
+	case *AnswerDiscardedEvent:
+		ctx.stats.incrCounterDirect(SCAnswersDiscarded, 1)
+
 	// Increment AFTER events (for recording)
 	case *AfterModelCallEvent:
 		totalTokens := int64(e.InputTokens) + int64(e.OutputTokens)
+		// Output tokens counted mid-stream by a StreamTokenMeter are not counted twice
+		outputDelta := int64(max(e.OutputTokens-e.StreamedOutputTokens, 0))
 		ctx.stats.incrCounterDirect(
 			SCInputTokens, int64(e.InputTokens),
 		)
 		ctx.stats.incrCounterDirect(
-			SCOutputTokens, int64(e.OutputTokens),
+			SCOutputTokens, outputDelta,
 		)
 		ctx.stats.incrCounterDirect(
-			SCTotalTokens, totalTokens,
+			SCTotalTokens, int64(e.InputTokens)+outputDelta,
 		)
 		if e.Model != "" {
 			ctx.stats.incrCounterDirect(
@@ -607,13 +1034,35 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 			)
 			ctx.stats.incrCounterDirect(
 				SCOutputTokensFor+StatKey(e.Model),
-				int64(e.OutputTokens),
+				outputDelta,
 			)
 			ctx.stats.incrCounterDirect(
 				SCTotalTokensFor+StatKey(e.Model),
-				totalTokens,
+				int64(e.InputTokens)+outputDelta,
 			)
 		}
+		if e.CachedInputTokens > 0 {
+			ctx.stats.incrCounterDirect(
+				SCCachedPromptTokens, int64(e.CachedInputTokens),
+			)
+			if e.Model != "" {
+				ctx.stats.incrCounterDirect(
+					SCCachedPromptTokensFor+StatKey(e.Model),
+					int64(e.CachedInputTokens),
+				)
+			}
+		}
+		if e.ReasoningTokens > 0 {
+			ctx.stats.incrCounterDirect(
+				SCReasoningTokens, int64(e.ReasoningTokens),
+			)
+			if e.Model != "" {
+				ctx.stats.incrCounterDirect(
+					SCReasoningTokensFor+StatKey(e.Model),
+					int64(e.ReasoningTokens),
+				)
+			}
+		}
 
 		// Per-iteration gauge tracking (local-only, reset each
 		// iteration)
@@ -665,6 +1114,12 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 					1,
 				)
 			}
+			switch {
+			case errors.Is(e.Error, ErrToolUnavailable):
+				ctx.stats.incrCounterDirect(SCToolCallsUnavailable, 1)
+			case errors.Is(e.Error, ErrUnknownTool):
+				ctx.stats.incrCounterDirect(SCToolCallsUnknown, 1)
+			}
 		}
 
 	case *ParseErrorEvent:
@@ -676,6 +1131,7 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 			ctx.stats.incrGaugeInternal(
 				SGFormatParseErrorConsecutive, 1,
 			)
+			ctx.recordFormatParseErrorOutput(e.RawContent)
 		case ParseErrorTypeToolchain:
 			ctx.stats.incrCounterDirect(
 				SCToolchainParseErrorTotal, 1,
@@ -700,7 +1156,10 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 		}
 
 	case *ValidatorResultEvent:
-		if !e.Accepted {
+		if e.Scored {
+			ctx.recordValidatorScore(e.Score)
+		}
+		if !e.Accepted && !e.ContinueWithoutRejection {
 			ctx.stats.incrCounterDirect(
 				SCAnswerRejectedTotal, 1,
 			)
@@ -712,9 +1171,34 @@ func (ctx *ExecutionContext) updateStatsForEvent(event Event) {
 			}
 		}
 
+	case *ErrorEvent:
+		ctx.updateContextState(func() {
+			ctx.lastError = e.Error
+		})
+
 	case *CompactionEvent:
 		ctx.stats.incrCounterDirect(SCCompactions, 1)
+		if saved := e.EstimatedTokensBefore - e.EstimatedTokensAfter; saved > 0 {
+			ctx.stats.incrCounterDirect(SCCompactionTokensSaved, int64(saved))
+		}
+		ctx.stats.SetGauge(SGScratchpadLength, float64(e.ScratchpadLengthAfter))
+	}
+}
+
+// recordFormatParseErrorOutput updates SGFormatParseErrorIdenticalConsecutive: it is
+// incremented when raw is byte-identical to the previous failing output and no successful
+// parse came in between, and restarts at 1 otherwise.
+func (ctx *ExecutionContext) recordFormatParseErrorOutput(raw string) {
+	var identical bool
+	ctx.updateContextState(func() {
+		identical = raw == ctx.lastFormatParseErrorOutput
+		ctx.lastFormatParseErrorOutput = raw
+	})
+	if identical && ctx.stats.GetGauge(SGFormatParseErrorIdenticalConsecutive) > 0 {
+		ctx.stats.incrGaugeInternal(SGFormatParseErrorIdenticalConsecutive, 1)
+		return
 	}
+	ctx.stats.SetGauge(SGFormatParseErrorIdenticalConsecutive, 1)
 }
 
 // Events returns a copy of all recorded events.
@@ -739,7 +1223,8 @@ func (ctx *ExecutionContext) PublishBeforeExecution() *BeforeExecutionEvent {
 	return event
 }
 
-// PublishAfterExecution publishes an AfterExecutionEvent.
+// PublishAfterExecution publishes an AfterExecutionEvent, with a Summary of the stats
+// at this point.
 func (ctx *ExecutionContext) PublishAfterExecution(
 	reason TerminationReason,
 	err error,
@@ -748,6 +1233,7 @@ func (ctx *ExecutionContext) PublishAfterExecution(
 		BaseEvent:         BaseEvent{EventName: EventNameExecutionAfter},
 		TerminationReason: reason,
 		Error:             err,
+		Summary:           newExecutionSummary(reason, ctx.stats.Counters()),
 	}
 	ctx.publish(event)
 	return event
@@ -779,6 +1265,7 @@ func (ctx *ExecutionContext) PublishAfterIteration(
 
 // PublishBeforeModelCall publishes a BeforeModelCallEvent.
 // Returns the event so callers can use the (potentially modified) Request field.
+// Messages queued with EnqueueEphemeralMessage are appended to Request first.
 func (ctx *ExecutionContext) PublishBeforeModelCall(
 	model string,
 	request any,
@@ -786,34 +1273,83 @@ func (ctx *ExecutionContext) PublishBeforeModelCall(
 	event := &BeforeModelCallEvent{
 		BaseEvent: BaseEvent{EventName: EventNameModelCallBefore},
 		Model:     model,
-		Request:   request,
+		Request:   ctx.appendEphemeralMessages(request),
 	}
 	ctx.publish(event)
+	ctx.interceptModelCall(event)
 	return event
 }
 
 // PublishAfterModelCall publishes an AfterModelCallEvent.
 // Stats updated: InputTokens, OutputTokens (and per-model variants).
+// Also hands the call to the RawIOSink, if one is set.
 func (ctx *ExecutionContext) PublishAfterModelCall(
 	model string,
 	request any,
 	response *ContentResponse,
 	duration time.Duration,
 	err error,
+) *AfterModelCallEvent {
+	return ctx.publishAfterModelCall(model, request, response, duration, err, 0)
+}
+
+// PublishSkippedModelCall publishes the AfterModelCallEvent of a model call answered by
+// a BeforeModelCallEvent subscriber (see BeforeModelCallEvent.Skip), with Cached set and
+// no duration. Model adapters call it instead of PublishAfterModelCall when they skip
+// the provider call. Stats are updated as for PublishAfterModelCall, from the supplied
+// response's token counts.
+func (ctx *ExecutionContext) PublishSkippedModelCall(
+	model string,
+	request any,
+	response *ContentResponse,
+) *AfterModelCallEvent {
+	event := newAfterModelCallEvent(model, request, response, 0, nil, 0)
+	event.Cached = true
+	ctx.publish(event)
+	return event
+}
+
+// publishAfterModelCall publishes an AfterModelCallEvent whose first streamed output
+// tokens were already counted (see StreamTokenMeter).
+func (ctx *ExecutionContext) publishAfterModelCall(
+	model string,
+	request any,
+	response *ContentResponse,
+	duration time.Duration,
+	err error,
+	streamed int,
+) *AfterModelCallEvent {
+	event := newAfterModelCallEvent(model, request, response, duration, err, streamed)
+	ctx.recordRawIO(model, request, response)
+	ctx.publish(event)
+	return event
+}
+
+// newAfterModelCallEvent builds an AfterModelCallEvent, taking token counts from the
+// response's GenerationInfo.
+func newAfterModelCallEvent(
+	model string,
+	request any,
+	response *ContentResponse,
+	duration time.Duration,
+	err error,
+	streamed int,
 ) *AfterModelCallEvent {
 	event := &AfterModelCallEvent{
-		BaseEvent: BaseEvent{EventName: EventNameModelCallAfter},
-		Model:     model,
-		Request:   request,
-		Response:  response,
-		Duration:  duration,
-		Error:     err,
+		BaseEvent:            BaseEvent{EventName: EventNameModelCallAfter},
+		Model:                model,
+		Request:              request,
+		Response:             response,
+		StreamedOutputTokens: streamed,
+		Duration:             duration,
+		Error:                err,
 	}
 	if response != nil && response.Info != nil {
 		event.InputTokens = response.Info.InputTokens
 		event.OutputTokens = response.Info.OutputTokens
+		event.CachedInputTokens = response.Info.CachedInputTokens
+		event.ReasoningTokens = response.Info.ReasoningTokens
 	}
-	ctx.publish(event)
 	return event
 }
 
@@ -828,6 +1364,7 @@ func (ctx *ExecutionContext) PublishBeforeToolCall(
 		BaseEvent: BaseEvent{EventName: EventNameToolCallBefore},
 		ToolName:  toolName,
 		Args:      args,
+		DryRun:    ctx.IsDryRun(),
 	}
 	ctx.publish(event)
 	return event
@@ -849,6 +1386,61 @@ func (ctx *ExecutionContext) PublishAfterToolCall(
 		Output:    output,
 		Duration:  duration,
 		Error:     err,
+		DryRun:    ctx.IsDryRun(),
+	}
+	ctx.publish(event)
+	return event
+}
+
+// PublishRepeatedToolCall publishes a RepeatedToolCallEvent.
+// This is called automatically by CheckRepeatedToolCall.
+// Stats updated: SCRepeatedToolCalls (and per-tool variant).
+func (ctx *ExecutionContext) PublishRepeatedToolCall(
+	toolName string,
+	args any,
+	count int,
+	action RepeatedToolCallAction,
+) *RepeatedToolCallEvent {
+	event := &RepeatedToolCallEvent{
+		BaseEvent: BaseEvent{
+			EventName: EventNameToolCallRepeated,
+		},
+		ToolName: toolName,
+		Args:     args,
+		Count:    count,
+		Action:   action,
+	}
+	ctx.publish(event)
+	return event
+}
+
+// PublishToolProgress publishes a ToolProgressEvent for the named tool.
+// Tools usually call [ReportToolProgress] instead, which fills in the tool name.
+// Stats updated: none.
+func (ctx *ExecutionContext) PublishToolProgress(
+	toolName string,
+	percent float64,
+	message string,
+) *ToolProgressEvent {
+	event := &ToolProgressEvent{
+		BaseEvent: BaseEvent{
+			EventName: EventNameToolCallProgress,
+		},
+		ToolName: toolName,
+		Percent:  percent,
+		Message:  message,
+	}
+	ctx.publish(event)
+	return event
+}
+
+// PublishFormatFallback publishes a FormatFallbackEvent.
+// Stats updated: SCFormatFallbacks.
+func (ctx *ExecutionContext) PublishFormatFallback(primary, matched string) *FormatFallbackEvent {
+	event := &FormatFallbackEvent{
+		BaseEvent: BaseEvent{EventName: EventNameFormatFallback},
+		Primary:   primary,
+		Matched:   matched,
 	}
 	ctx.publish(event)
 	return event
@@ -904,6 +1496,26 @@ func (ctx *ExecutionContext) PublishValidatorResult(
 	return event
 }
 
+// PublishValidatorContinue publishes a ValidatorResultEvent for a validator that asked
+// for another iteration without rejecting the answer (see
+// ValidationResult.ContinueWithoutRejection).
+// Stats updated: none; rejection counters are left unchanged.
+func (ctx *ExecutionContext) PublishValidatorContinue(
+	validatorName string,
+	answer any,
+	feedback []FormattedSection,
+) *ValidatorResultEvent {
+	event := &ValidatorResultEvent{
+		BaseEvent:                BaseEvent{EventName: EventNameValidatorResult},
+		ValidatorName:            validatorName,
+		Answer:                   answer,
+		Feedback:                 feedback,
+		ContinueWithoutRejection: true,
+	}
+	ctx.publish(event)
+	return event
+}
+
 // PublishError publishes an ErrorEvent.
 func (ctx *ExecutionContext) PublishError(err error) *ErrorEvent {
 	event := &ErrorEvent{
@@ -935,11 +1547,28 @@ func (ctx *ExecutionContext) PublishLimitExceeded(
 }
 
 // PublishCompaction publishes a CompactionEvent.
-// Stats updated: SCCompactions counter is incremented.
+// Stats updated: SCCompactions counter is incremented and the
+// SGScratchpadLength gauge is set to lengthAfter.
 func (ctx *ExecutionContext) PublishCompaction(
 	lengthBefore int,
 	lengthAfter int,
 	duration time.Duration,
+) *CompactionEvent {
+	return ctx.PublishCompactionWithTokens(lengthBefore, lengthAfter, 0, 0, duration)
+}
+
+// PublishCompactionWithTokens publishes a CompactionEvent that
+// also carries the scratchpad's estimated token counts (see
+// TokenEstimator).
+// Stats updated: as PublishCompaction, plus
+// SCCompactionTokensSaved by tokensBefore - tokensAfter when
+// positive.
+func (ctx *ExecutionContext) PublishCompactionWithTokens(
+	lengthBefore int,
+	lengthAfter int,
+	tokensBefore int,
+	tokensAfter int,
+	duration time.Duration,
 ) *CompactionEvent {
 	event := &CompactionEvent{
 		BaseEvent: BaseEvent{
@@ -947,12 +1576,59 @@ func (ctx *ExecutionContext) PublishCompaction(
 		},
 		ScratchpadLengthBefore: lengthBefore,
 		ScratchpadLengthAfter:  lengthAfter,
+		EstimatedTokensBefore:  tokensBefore,
+		EstimatedTokensAfter:   tokensAfter,
 		Duration:               duration,
 	}
 	ctx.publish(event)
 	return event
 }
 
+// PublishHandoff publishes a HandoffEvent.
+// This is called automatically by tools created with NewHandoffTool.
+// Stats updated: none.
+func (ctx *ExecutionContext) PublishHandoff(
+	target string,
+	request string,
+	reason TerminationReason,
+	output string,
+	err error,
+) *HandoffEvent {
+	event := &HandoffEvent{
+		BaseEvent: BaseEvent{
+			EventName: EventNameHandoff,
+		},
+		Target:            target,
+		Request:           request,
+		TerminationReason: reason,
+		Output:            output,
+		Error:             err,
+	}
+	ctx.publish(event)
+	return event
+}
+
+// PublishAnswerDiscarded publishes an AnswerDiscardedEvent.
+// This is called automatically by the react agent when a response contains both actions
+// and an answer.
+// Stats updated: SCAnswersDiscarded counter is incremented.
+func (ctx *ExecutionContext) PublishAnswerDiscarded(
+	section string,
+	answer string,
+	actions []string,
+) *AnswerDiscardedEvent {
+	event := &AnswerDiscardedEvent{
+		BaseEvent: BaseEvent{
+			EventName: EventNameAnswerDiscarded,
+		},
+		Section: section,
+		Answer:  answer,
+		Actions: actions,
+	}
+	ctx.publish(event)
+	return event
+}
+
 // PublishCommonEvent publishes a CommonEvent for user-defined events.
 // The eventName should use the format "namespace:event_name" (e.g., "myapp:cache_hit").
 func (ctx *ExecutionContext) PublishCommonEvent(
@@ -1083,9 +1759,20 @@ func (ctx *ExecutionContext) SpawnChild(name string, data LoopData) *ExecutionCo
 		depth:     ctx.depth + 1,
 		parent:    ctx,
 		events:    make([]Event, 0),
-		startTime: time.Now(),
+		startTime: ctx.now(),
 		streamHub: newStreamHub(),
+
+		limitDrain:       ctx.limitDrain,
+		limitDrainGrace:  ctx.limitDrainGrace,
+		toolCalls:        toolCallTracker{policy: ctx.toolCalls.policy},
+		hookPanicPolicy:  ctx.hookPanicPolicy,
+		scratchpadLayout: ctx.scratchpadLayout,
+		reflectionPass:   ctx.reflectionPass,
+		rawIOSink:        ctx.rawIOSink,
+		requestMetadata:  ctx.requestMetadata,
 	}
+	child.clock.store(ctx.Clock())
+	child.goCtx = context.WithValue(childGoCtx, execCtxKey{}, child)
 	// Create stats with back-reference to child for limit checking
 	// Stats also link to parent stats for real-time aggregation
 	child.stats = newExecutionStatsWithContextAndParent(child, ctx.stats)
@@ -1097,11 +1784,16 @@ func (ctx *ExecutionContext) SpawnChild(name string, data LoopData) *ExecutionCo
 
 	ctx.children = append(ctx.children, child)
 
+	// A BudgetRoot spawns a task for as long as it lives, so it keeps no spawn events
+	if ctx.budget {
+		return child
+	}
+
 	// Record child spawn event
 	spawnEvent := &CommonEvent{
 		BaseEvent: BaseEvent{
 			EventName: EventNameChildSpawn,
-			Timestamp: time.Now(),
+			Timestamp: ctx.now(),
 			Iteration: ctx.iteration,
 			Depth:     ctx.depth,
 		},
@@ -1123,7 +1815,7 @@ func (ctx *ExecutionContext) CompleteChild(child *ExecutionContext) {
 	defer ctx.mu.Unlock()
 
 	child.mu.Lock()
-	child.endTime = time.Now()
+	child.endTime = child.now()
 	childDuration := child.endTime.Sub(child.startTime)
 	childReason := child.terminationReason
 	childName := child.name
@@ -1133,7 +1825,7 @@ func (ctx *ExecutionContext) CompleteChild(child *ExecutionContext) {
 	completeEvent := &CommonEvent{
 		BaseEvent: BaseEvent{
 			EventName: EventNameChildComplete,
-			Timestamp: time.Now(),
+			Timestamp: ctx.now(),
 			Iteration: ctx.iteration,
 			Depth:     ctx.depth,
 		},
@@ -1147,6 +1839,20 @@ func (ctx *ExecutionContext) CompleteChild(child *ExecutionContext) {
 	ctx.events = append(ctx.events, completeEvent)
 }
 
+// detachChild removes a finished child from ctx's children and releases its Go
+// context, so ctx no longer references it.
+func (ctx *ExecutionContext) detachChild(child *ExecutionContext) {
+	ctx.mu.Lock()
+	for i, c := range ctx.children {
+		if c == child {
+			ctx.children = append(ctx.children[:i], ctx.children[i+1:]...)
+			break
+		}
+	}
+	ctx.mu.Unlock()
+	child.cancel(context.Canceled)
+}
+
 // Parent returns the parent context, or nil if this is the root.
 func (ctx *ExecutionContext) Parent() *ExecutionContext {
 	ctx.mu.RLock()
@@ -1163,6 +1869,52 @@ func (ctx *ExecutionContext) Children() []*ExecutionContext {
 	return result
 }
 
+// Walk calls fn for ctx and every context below it, depth-first with parents before
+// their children, until fn returns false.
+//
+// Each context's children are snapshotted right after fn visits it, so it is safe to
+// walk a tree that is still running: children spawned after their parent was visited
+// are not visited.
+//
+// Example - input tokens spent by each context itself, excluding its children (counters
+// propagate to ancestors, so root.Stats().GetCounter(gent.SCInputTokens) alone gives
+// the total across the tree):
+//
+//	perContext := make(map[string]int64)
+//	root.Walk(func(c *gent.ExecutionContext) bool {
+//	    perContext[c.Name()] += c.Stats().GetCounter(gent.SCInputTokens.Self())
+//	    return true
+//	})
+func (ctx *ExecutionContext) Walk(fn func(*ExecutionContext) bool) {
+	ctx.walk(fn)
+}
+
+// walk implements Walk, reporting whether the traversal should continue.
+func (ctx *ExecutionContext) walk(fn func(*ExecutionContext) bool) bool {
+	if !fn(ctx) {
+		return false
+	}
+	for _, child := range ctx.Children() {
+		if !child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Descendants returns all contexts below ctx (children, their children, and so on) in
+// Walk order, excluding ctx itself.
+func (ctx *ExecutionContext) Descendants() []*ExecutionContext {
+	var result []*ExecutionContext
+	ctx.Walk(func(c *ExecutionContext) bool {
+		if c != ctx {
+			result = append(result, c)
+		}
+		return true
+	})
+	return result
+}
+
 // Depth returns the nesting depth (0 for root).
 func (ctx *ExecutionContext) Depth() int {
 	ctx.mu.RLock()
@@ -1178,24 +1930,149 @@ func (ctx *ExecutionContext) Depth() int {
 // Called by the Executor when execution ends.
 //
 // This also populates the Result() field with an ExecutionResult containing
-// all termination information.
+// all termination information. If reason is TerminationSuccess and a custom
+// reason was set with SetTerminationReason, the custom reason is used instead.
 func (ctx *ExecutionContext) SetTermination(reason TerminationReason, result []ContentPart, err error) {
 	ctx.mu.Lock()
 	defer ctx.mu.Unlock()
+	if reason == TerminationSuccess && ctx.customReason != "" {
+		reason = ctx.customReason
+	}
 	ctx.terminationReason = reason
 	ctx.finalResult = result
 	ctx.err = err
-	ctx.endTime = time.Now()
+	ctx.endTime = ctx.now()
 
 	// Populate the result for easy access via Result()
 	ctx.result = &ExecutionResult{
 		TerminationReason: reason,
+		TerminatedBy:      ctx.terminatedBy,
+		TerminatedByTool:  ctx.terminatedByTool,
+		Candidates:        ctx.candidates,
 		Output:            result,
 		Error:             err,
 		ExceededLimit:     ctx.exceededLimit,
 	}
 }
 
+// SetTerminatedBy records the name of the Termination that accepted the final answer.
+// Called by the Executor before SetTermination when the AgentLoop terminates with
+// [AgentLoopResult].TerminatedBy set.
+func (ctx *ExecutionContext) SetTerminatedBy(name string) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.terminatedBy = name
+}
+
+// SetTerminatedByTool records the name of the [TerminalTool] whose successful call ended
+// the execution. Called by the Executor before SetTermination when the AgentLoop
+// terminates with [AgentLoopResult].TerminatedByTool set.
+func (ctx *ExecutionContext) SetTerminatedByTool(name string) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.terminatedBy = name
+	ctx.terminatedByTool = true
+}
+
+// SetAnswerCandidates records the candidate answers of the step that ended the
+// execution. Called by the Executor before SetTermination when the AgentLoop terminates
+// with [AgentLoopResult].Candidates set.
+func (ctx *ExecutionContext) SetAnswerCandidates(candidates []AnswerCandidate) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.candidates = candidates
+}
+
+// AnswerCandidates returns the candidate answers, with their validation results, of the
+// step that ended the execution, or nil if the accepting Termination took a single
+// answer. See termination.Candidates.
+func (ctx *ExecutionContext) AnswerCandidates() []AnswerCandidate {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.candidates
+}
+
+// TerminatedBy returns the name of the Termination that accepted the final answer, or
+// of the TerminalTool that ended the execution (see TerminatedByTool). Returns "" if
+// the execution ended otherwise.
+func (ctx *ExecutionContext) TerminatedBy() string {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.terminatedBy
+}
+
+// TerminatedByTool returns true if a [TerminalTool] ended the execution.
+func (ctx *ExecutionContext) TerminatedByTool() bool {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.terminatedByTool
+}
+
+// SetTerminationReason records a domain-specific outcome for the execution, such as
+// "support:escalated". Call it from a Termination, a tool, or any other code running
+// during execution; the last call wins.
+//
+// When the execution terminates successfully, the custom reason replaces
+// TerminationSuccess in TerminationReason(), Result() and the AfterExecutionEvent, so
+// callers can branch on the outcome without inspecting the answer. Executions that end
+// in an error, a limit or cancellation keep their built-in reason.
+//
+// Panics if reason is not namespaced ("namespace:outcome"), see
+// [TerminationReason.IsCustom].
+func (ctx *ExecutionContext) SetTerminationReason(reason TerminationReason) {
+	if !reason.IsCustom() {
+		panic(fmt.Sprintf(
+			"gent: SetTerminationReason requires a namespaced reason "+
+				"like \"myapp:escalated\", got %q",
+			reason,
+		))
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.customReason = reason
+}
+
+// SetTerminationReason records a custom termination reason from code that only has the
+// context.Context, such as a tool's Call method. It sets the reason on the
+// ExecutionContext the context belongs to (see [ExecutionContext.SetTerminationReason])
+// and returns false if ctx does not belong to an ExecutionContext.
+//
+//	func(ctx context.Context, input EscalateInput) (string, error) {
+//	    ticket := openTicket(ctx, input)
+//	    gent.SetTerminationReason(ctx, "support:escalated")
+//	    return "Escalated as " + ticket, nil
+//	}
+func SetTerminationReason(ctx context.Context, reason TerminationReason) bool {
+	execCtx := ExecutionContextFrom(ctx)
+	if execCtx == nil {
+		return false
+	}
+	execCtx.SetTerminationReason(reason)
+	return true
+}
+
+// ReportToolProgress publishes a [ToolProgressEvent] from a tool's Call method, so
+// subscribers can show progress while a long-running tool works. percent ranges from 0
+// to 100; pass a negative value if progress cannot be estimated. Returns false if ctx
+// does not belong to an ExecutionContext.
+//
+//	func(ctx context.Context, input ImportInput) (string, error) {
+//	    for i, batch := range batches {
+//	        importBatch(ctx, batch)
+//	        gent.ReportToolProgress(ctx, float64(i+1)*100/float64(len(batches)),
+//	            fmt.Sprintf("Imported batch %d of %d", i+1, len(batches)))
+//	    }
+//	    return "Import complete", nil
+//	}
+func ReportToolProgress(ctx context.Context, percent float64, message string) bool {
+	execCtx := ExecutionContextFrom(ctx)
+	if execCtx == nil {
+		return false
+	}
+	execCtx.PublishToolProgress(ToolNameFrom(ctx), percent, message)
+	return true
+}
+
 // TerminationReason returns why execution terminated.
 func (ctx *ExecutionContext) TerminationReason() TerminationReason {
 	ctx.mu.RLock()
@@ -1237,7 +2114,7 @@ func (ctx *ExecutionContext) Duration() time.Duration {
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
 	if ctx.endTime.IsZero() {
-		return time.Since(ctx.startTime)
+		return ctx.since(ctx.startTime)
 	}
 	return ctx.endTime.Sub(ctx.startTime)
 }
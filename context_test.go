@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
 )
 
 func TestSetCompaction_PanicsOnMixedNil(t *testing.T) {
@@ -110,3 +111,350 @@ func (s *stubStrategy) Compact(
 ) error {
 	return nil
 }
+
+func TestSetTerminationReason(t *testing.T) {
+	type input struct {
+		custom TerminationReason
+		reason TerminationReason
+		viaCtx bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected TerminationReason
+	}{
+		{
+			name:     "no custom reason",
+			input:    input{reason: TerminationSuccess},
+			expected: TerminationSuccess,
+		},
+		{
+			name:     "custom reason replaces success",
+			input:    input{custom: "support:escalated", reason: TerminationSuccess},
+			expected: "support:escalated",
+		},
+		{
+			name: "custom reason set through context.Context",
+			input: input{
+				custom: "support:escalated", reason: TerminationSuccess, viaCtx: true,
+			},
+			expected: "support:escalated",
+		},
+		{
+			name:     "built-in failure reason is kept",
+			input:    input{custom: "support:escalated", reason: TerminationLimitExceeded},
+			expected: TerminationLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			if tt.input.custom != "" {
+				if tt.input.viaCtx {
+					assert.True(t, SetTerminationReason(execCtx.Context(), tt.input.custom))
+				} else {
+					execCtx.SetTerminationReason(tt.input.custom)
+				}
+			}
+
+			execCtx.SetTermination(tt.input.reason, nil, nil)
+
+			assert.Equal(t, tt.expected, execCtx.TerminationReason())
+			assert.Equal(t, tt.expected, execCtx.Result().TerminationReason)
+		})
+	}
+}
+
+func TestSetTerminationReason_RequiresNamespace(t *testing.T) {
+	execCtx := NewExecutionContext(context.Background(), "test", nil)
+
+	for _, reason := range []TerminationReason{"escalated", "success", ":escalated", "app:"} {
+		assert.False(t, reason.IsCustom())
+		assert.Panics(t, func() { execCtx.SetTerminationReason(reason) })
+	}
+	assert.True(t, TerminationReason("support:escalated").IsCustom())
+	assert.False(t, SetTerminationReason(context.Background(), "support:escalated"))
+}
+
+func TestExecutionContext_ScratchpadView(t *testing.T) {
+	iteration := func(text string) *Iteration {
+		iter := &Iteration{
+			Messages: []*MessageContent{
+				{Role: llms.ChatMessageTypeAI, Parts: []ContentPart{llms.TextContent{Text: text}}},
+			},
+		}
+		iter.SetMetadata(IMKImportanceScore, 1.0)
+		return iter
+	}
+
+	data := NewBasicLoopData(&Task{Text: "refund order #1"})
+	first, second := iteration("constraints"), iteration("lookup")
+	data.AddIterationHistory(first)
+	data.AddIterationHistory(second)
+	// Simulate compaction dropping the first iteration
+	data.SetScratchPad([]*Iteration{second})
+
+	execCtx := NewExecutionContext(context.Background(), "test", data)
+	assert.Same(t, execCtx, ExecutionContextFrom(execCtx.Context()))
+
+	scratchpad := execCtx.ScratchpadView()
+	history := execCtx.IterationHistoryView()
+	assert.Equal(t, []*Iteration{second}, scratchpad)
+	assert.Equal(t, []*Iteration{first, second}, history)
+
+	// Mutating the views leaves loop state untouched
+	scratchpad[0].Messages[0].Parts[0] = llms.TextContent{Text: "changed"}
+	scratchpad[0].SetMetadata(IMKImportanceScore, 5.0)
+	history[0] = nil
+	assert.Equal(t, llms.TextContent{Text: "lookup"}, second.Messages[0].Parts[0])
+	score, _ := GetImportanceScore(second)
+	assert.Equal(t, 1.0, score)
+	assert.Same(t, first, data.GetIterationHistory()[0])
+
+	assert.Nil(t, NewExecutionContext(context.Background(), "empty", nil).ScratchpadView())
+	assert.Nil(t, ExecutionContextFrom(context.Background()))
+}
+
+func TestExecutionContext_Walk(t *testing.T) {
+	root := NewExecutionContext(context.Background(), "root", nil)
+	a := root.SpawnChild("a", nil)
+	a1 := a.SpawnChild("a1", nil)
+	a2 := a.SpawnChild("a2", nil)
+	b := root.SpawnChild("b", nil)
+	b1 := b.SpawnChild("b1", nil)
+
+	tests := []struct {
+		name     string
+		input    string // name of the context where the walk stops
+		expected []string
+	}{
+		{
+			name:     "visits whole tree depth-first",
+			expected: []string{"root", "a", "a1", "a2", "b", "b1"},
+		},
+		{
+			name:     "stops when fn returns false",
+			input:    "a2",
+			expected: []string{"root", "a", "a1", "a2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var visited []string
+			root.Walk(func(c *ExecutionContext) bool {
+				visited = append(visited, c.Name())
+				return c.Name() != tt.input
+			})
+			assert.Equal(t, tt.expected, visited)
+		})
+	}
+
+	assert.Equal(t, []*ExecutionContext{a, a1, a2, b, b1}, root.Descendants())
+	assert.Equal(t, []*ExecutionContext{a1, a2}, a.Descendants())
+	assert.Nil(t, b1.Descendants())
+
+	t.Run("children spawned after their parent was visited are skipped", func(t *testing.T) {
+		var visited []string
+		b.Walk(func(c *ExecutionContext) bool {
+			visited = append(visited, c.Name())
+			if c == b1 {
+				b.SpawnChild("b2", nil)
+			}
+			return true
+		})
+		assert.Equal(t, []string{"b", "b1"}, visited)
+		assert.Len(t, b.Children(), 2)
+	})
+}
+
+func TestExecutionContext_EnqueueEphemeralMessage(t *testing.T) {
+	history := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "What's the weather?"),
+	}
+	wrapUp := llms.TextParts(llms.ChatMessageTypeSystem, "The user cancelled; wrap up.")
+	brief := llms.TextParts(llms.ChatMessageTypeHuman, "Keep it brief.")
+
+	type input struct {
+		enqueue []llms.MessageContent
+		request any
+	}
+
+	type expected struct {
+		request any
+		next    any // request of a second model call with the same input request
+		pending []llms.MessageContent
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "no queued messages",
+			input: input{request: history},
+			expected: expected{
+				request: history,
+				next:    history,
+			},
+		},
+		{
+			name: "queued messages are sent once, in order",
+			input: input{
+				enqueue: []llms.MessageContent{wrapUp, brief},
+				request: history,
+			},
+			expected: expected{
+				request: []llms.MessageContent{history[0], wrapUp, brief},
+				next:    history,
+			},
+		},
+		{
+			name: "other request types keep the queue",
+			input: input{
+				enqueue: []llms.MessageContent{wrapUp},
+				request: "raw prompt",
+			},
+			expected: expected{
+				request: "raw prompt",
+				next:    "raw prompt",
+				pending: []llms.MessageContent{wrapUp},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			for _, msg := range tc.input.enqueue {
+				execCtx.EnqueueEphemeralMessage(msg.Role, msg.Parts[0].(llms.TextContent).Text)
+			}
+
+			first := execCtx.PublishBeforeModelCall("test-model", tc.input.request)
+			second := execCtx.PublishBeforeModelCall("test-model", tc.input.request)
+
+			assert.Equal(t, tc.expected.request, first.Request)
+			assert.Equal(t, tc.expected.next, second.Request)
+			assert.Equal(t, tc.expected.pending, execCtx.PendingEphemeralMessages())
+			assert.Len(t, history, 1, "caller's request must not be modified")
+		})
+	}
+}
+
+func TestSetTerminatedBy(t *testing.T) {
+	execCtx := NewExecutionContext(context.Background(), "test", nil)
+	assert.Equal(t, "", execCtx.TerminatedBy())
+
+	execCtx.SetTerminatedBy("escalate")
+	execCtx.SetTermination(TerminationSuccess, nil, nil)
+
+	assert.Equal(t, "escalate", execCtx.TerminatedBy())
+	assert.Equal(t, "escalate", execCtx.Result().TerminatedBy)
+	assert.False(t, execCtx.Result().TerminatedByTool)
+}
+
+func TestSetTerminatedByTool(t *testing.T) {
+	execCtx := NewExecutionContext(context.Background(), "test", nil)
+	assert.False(t, execCtx.TerminatedByTool())
+
+	execCtx.SetTerminatedByTool("submit_order")
+	execCtx.SetTermination(TerminationSuccess, nil, nil)
+
+	assert.Equal(t, "submit_order", execCtx.TerminatedBy())
+	assert.True(t, execCtx.TerminatedByTool())
+	assert.Equal(t, "submit_order", execCtx.Result().TerminatedBy)
+	assert.True(t, execCtx.Result().TerminatedByTool)
+}
+
+func TestDistinctToolsUsed(t *testing.T) {
+	type input struct {
+		rootTools  []string // tools called in the root context
+		childTools []string // tools called in a child context
+		maxValue   float64  // limit on SGDistinctToolsUsed, 0 for none
+	}
+
+	type expected struct {
+		rootGauge  float64
+		childGauge float64
+		exceeded   bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "repeated use of the same tool keeps gauge flat",
+			input: input{
+				rootTools: []string{"search", "search", "search"},
+			},
+			expected: expected{rootGauge: 1},
+		},
+		{
+			name: "distinct tools each count once",
+			input: input{
+				rootTools: []string{"search", "fetch", "search", "calc"},
+			},
+			expected: expected{rootGauge: 3},
+		},
+		{
+			name: "child tools merge into parent without double counting",
+			input: input{
+				rootTools:  []string{"search"},
+				childTools: []string{"search", "fetch", "fetch"},
+			},
+			expected: expected{rootGauge: 2, childGauge: 2},
+		},
+		{
+			name: "limit exceeded by too many distinct tools",
+			input: input{
+				rootTools: []string{"a", "b", "c", "a"},
+				maxValue:  2,
+			},
+			expected: expected{rootGauge: 3, exceeded: true},
+		},
+		{
+			name: "limit not exceeded by repeating tools",
+			input: input{
+				rootTools: []string{"a", "b", "a", "b", "a"},
+				maxValue:  2,
+			},
+			expected: expected{rootGauge: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewExecutionContext(context.Background(), "root", nil)
+			if tt.input.maxValue > 0 {
+				root.SetLimits([]Limit{
+					{Type: LimitExactKey, Key: SGDistinctToolsUsed, MaxValue: tt.input.maxValue},
+				})
+			}
+			for _, name := range tt.input.rootTools {
+				root.PublishBeforeToolCall(name, nil)
+			}
+			if tt.input.childTools != nil {
+				child := root.SpawnChild("child", nil)
+				for _, name := range tt.input.childTools {
+					child.PublishBeforeToolCall(name, nil)
+				}
+				assert.Equal(t, tt.expected.childGauge,
+					child.Stats().GetGauge(SGDistinctToolsUsed))
+			}
+
+			assert.Equal(t, tt.expected.rootGauge, root.Stats().GetGauge(SGDistinctToolsUsed))
+			if tt.expected.exceeded {
+				if assert.NotNil(t, root.ExceededLimit()) {
+					assert.Equal(t, SGDistinctToolsUsed, root.ExceededLimit().Key)
+				}
+			} else {
+				assert.Nil(t, root.ExceededLimit())
+			}
+		})
+	}
+}
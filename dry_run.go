@@ -0,0 +1,61 @@
+package gent
+
+import "context"
+
+// dryRunKey is the context key marking a dry run.
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of ctx marked as a dry run.
+//
+// A dry run executes the agent normally, but tools are expected to skip their
+// side-effecting operations. Use it to test prompts against production tools without
+// sending emails, issuing refunds, or writing to databases.
+//
+// Pass the returned context to [NewExecutionContext]. The flag is visible to:
+//   - Agent code via [ExecutionContext.IsDryRun]
+//   - Tools via [IsDryRun] on the context.Context passed to Call
+//   - Child contexts created with SpawnChild (inherited automatically)
+//
+// Toolchains can additionally stub tools implementing [SideEffectTool] without
+// calling them (see toolchain.YAML.WithDryRunStubs). Tool call events published during
+// a dry run have their DryRun field set.
+//
+// Example:
+//
+//	execCtx := gent.NewExecutionContext(gent.WithDryRun(ctx), "main", data)
+//
+//	// Inside a tool:
+//	func(ctx context.Context, input RefundInput) (string, error) {
+//	    if gent.IsDryRun(ctx) {
+//	        return fmt.Sprintf("would refund %s", input.OrderID), nil
+//	    }
+//	    return issueRefund(ctx, input.OrderID)
+//	}
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx (or any of its parents) was marked by [WithDryRun].
+func IsDryRun(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// SideEffectTool is an optional interface for tools that declare whether calling them
+// has side effects (writes, payments, notifications, etc.).
+//
+// Toolchains with dry-run stubbing enabled skip calling such tools during a dry run
+// and return a canned observation instead. Tools not implementing this interface are
+// assumed to be side-effect free and are always called.
+//
+// [ToolFunc] implements this interface; mark it with WithSideEffects:
+//
+//	refund := gent.NewToolFunc("refund", "Issue a refund", schema, refundFn).
+//	    WithSideEffects()
+type SideEffectTool interface {
+	// HasSideEffects returns true if calling the tool changes external state.
+	HasSideEffects() bool
+}
@@ -0,0 +1,65 @@
+package gent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    context.Context
+		expected bool
+	}{
+		{name: "plain context", input: context.Background(), expected: false},
+		{name: "marked context", input: WithDryRun(context.Background()), expected: true},
+		{
+			name: "derived from marked context",
+			input: context.WithValue(
+				WithDryRun(context.Background()), struct{}{}, "x",
+			),
+			expected: true,
+		},
+		{name: "nil context", input: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsDryRun(tt.input))
+		})
+	}
+}
+
+func TestExecutionContext_IsDryRun(t *testing.T) {
+	t.Run("regular execution", func(t *testing.T) {
+		execCtx := NewExecutionContext(context.Background(), "main", nil)
+		assert.False(t, execCtx.IsDryRun())
+		assert.False(t, execCtx.PublishBeforeToolCall("refund", nil).DryRun)
+		assert.False(t, execCtx.PublishAfterToolCall("refund", nil, nil, 0, nil).DryRun)
+	})
+
+	t.Run("dry run propagates to children, tool context and events", func(t *testing.T) {
+		execCtx := NewExecutionContext(WithDryRun(context.Background()), "main", nil)
+		child := execCtx.SpawnChild("child", nil)
+
+		assert.True(t, execCtx.IsDryRun())
+		assert.True(t, IsDryRun(execCtx.Context()))
+		assert.True(t, child.IsDryRun())
+		assert.True(t, execCtx.PublishBeforeToolCall("refund", nil).DryRun)
+		assert.True(t, child.PublishAfterToolCall("refund", nil, nil, 0, nil).DryRun)
+	})
+}
+
+func TestToolFunc_WithSideEffects(t *testing.T) {
+	fn := func(_ context.Context, _ map[string]any) (string, error) { return "", nil }
+
+	readOnly := NewToolFunc("lookup", "Look up", nil, fn)
+	assert.False(t, readOnly.HasSideEffects())
+
+	writer := NewToolFunc("refund", "Refund", nil, fn).WithSideEffects()
+	assert.True(t, writer.HasSideEffects())
+
+	var _ SideEffectTool = writer
+}
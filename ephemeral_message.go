@@ -0,0 +1,97 @@
+package gent
+
+import (
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// EnqueueEphemeralMessage schedules a one-time message for the next model call made with
+// this context. Use it from a tool or hook to nudge the model without subscribing to
+// [BeforeModelCallEvent], e.g. when the user cancels:
+//
+//	execCtx.EnqueueEphemeralMessage(llms.ChatMessageTypeSystem,
+//	    "The user just cancelled; wrap up with what you have.")
+//
+// Enqueued messages are appended, in order, to the request of the next
+// [ExecutionContext.PublishBeforeModelCall] whose Request is a []llms.MessageContent,
+// before subscribers are notified, and the queue is cleared. They are sent once: they
+// are not persisted to the scratchpad or iteration history, so later model calls do not
+// see them. Messages stay queued until such a model call happens on this context; child
+// contexts have their own queues.
+func (ctx *ExecutionContext) EnqueueEphemeralMessage(role llms.ChatMessageType, text string) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.ephemeralMessages = append(ctx.ephemeralMessages, llms.TextParts(role, text))
+}
+
+// PendingEphemeralMessages returns a copy of the messages queued by
+// [ExecutionContext.EnqueueEphemeralMessage] that have not been sent yet.
+func (ctx *ExecutionContext) PendingEphemeralMessages() []llms.MessageContent {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	if len(ctx.ephemeralMessages) == 0 {
+		return nil
+	}
+	return append([]llms.MessageContent(nil), ctx.ephemeralMessages...)
+}
+
+// appendEphemeralMessages drains the queued ephemeral messages into request if it is a
+// []llms.MessageContent. Other request types are returned unchanged and the queue is
+// kept. The caller's slice is never modified.
+func (ctx *ExecutionContext) appendEphemeralMessages(request any) any {
+	messages, ok := request.([]llms.MessageContent)
+	if !ok {
+		return request
+	}
+
+	ctx.mu.Lock()
+	pending := ctx.ephemeralMessages
+	ctx.ephemeralMessages = nil
+	ctx.mu.Unlock()
+
+	if len(pending) == 0 {
+		return request
+	}
+	merged := make([]llms.MessageContent, 0, len(messages)+len(pending))
+	merged = append(merged, messages...)
+	return append(merged, pending...)
+}
+
+// AttachEphemeralObservation attaches supplementary content to the observation of the
+// tool call being executed, for the next model call only. Use it from a tool for large
+// reference material the model needs for exactly one step, such as a full document the
+// tool looked up, while the tool's regular output stays in the scratchpad:
+//
+//	func(ctx context.Context, input LookupInput) (string, error) {
+//	    doc := store.Get(input.ID)
+//	    if execCtx := gent.ExecutionContextFrom(ctx); execCtx != nil {
+//	        execCtx.AttachEphemeralObservation(doc.Body)
+//	    }
+//	    return fmt.Sprintf("Loaded %s (%d bytes)", doc.Title, len(doc.Body)), nil
+//	}
+//
+// Attached content is held until the agent collects it with
+// [ExecutionContext.TakeEphemeralObservations]. The react agent does so after running
+// the iteration's tool calls and sends it, as its own observation, with the next model
+// call through [ExecutionContext.EnqueueEphemeralMessage]; it is never added to the
+// scratchpad or iteration history. Blank text is ignored.
+func (ctx *ExecutionContext) AttachEphemeralObservation(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.ephemeralObservations = append(ctx.ephemeralObservations, text)
+}
+
+// TakeEphemeralObservations returns the content attached with
+// [ExecutionContext.AttachEphemeralObservation], in order, and clears it. Returns nil if
+// nothing is attached. Agents call it after executing tool calls.
+func (ctx *ExecutionContext) TakeEphemeralObservations() []string {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	taken := ctx.ephemeralObservations
+	ctx.ephemeralObservations = nil
+	return taken
+}
@@ -29,11 +29,14 @@ const (
 	EventNameModelCallAfter  = "gent:model_call:after"
 
 	// Tool calls
-	EventNameToolCallBefore = "gent:tool_call:before"
-	EventNameToolCallAfter  = "gent:tool_call:after"
+	EventNameToolCallBefore   = "gent:tool_call:before"
+	EventNameToolCallAfter    = "gent:tool_call:after"
+	EventNameToolCallRepeated = "gent:tool_call:repeated"
+	EventNameToolCallProgress = "gent:tool_call:progress"
 
 	// Errors and validation
 	EventNameParseError      = "gent:parse_error"
+	EventNameFormatFallback  = "gent:format:fallback"
 	EventNameValidatorCalled = "gent:validator:called"
 	EventNameValidatorResult = "gent:validator:result"
 	EventNameError           = "gent:error"
@@ -44,6 +47,18 @@ const (
 	// Compaction
 	EventNameCompaction = "gent:compaction"
 
+	// Background compaction lifecycle (published as CommonEvent on the "compaction"
+	// child context). The finish event's data holds the tokens the compaction used,
+	// which the execution summary excludes.
+	EventNameCompactionAsyncStart  = "gent:compaction:async_start"
+	EventNameCompactionAsyncFinish = "gent:compaction:async_finish"
+
+	// Handoffs to other agents
+	EventNameHandoff = "gent:handoff"
+
+	// Answers discarded because actions took priority
+	EventNameAnswerDiscarded = "gent:answer:discarded"
+
 	// Child context lifecycle (published as CommonEvent)
 	EventNameChildSpawn    = "gent:child:spawn"
 	EventNameChildComplete = "gent:child:complete"
@@ -1,6 +1,7 @@
 package gent
 
 import (
+	"strings"
 	"time"
 )
 
@@ -9,6 +10,11 @@ import (
 // -----------------------------------------------------------------------------
 
 // TerminationReason indicates why execution terminated.
+//
+// Built-in reasons are the constants below. Applications can report domain-specific
+// outcomes, such as "support:escalated", with [ExecutionContext.SetTerminationReason].
+// Custom reasons are namespaced ("namespace:outcome") so they never collide with
+// built-in reasons.
 type TerminationReason string
 
 const (
@@ -30,8 +36,20 @@ const (
 	// returned an error. Inspect ExecutionResult.Error for
 	// details.
 	TerminationCompactionFailed TerminationReason = "compaction_failed"
+
+	// TerminationRepeatedToolCall means the agent kept repeating the
+	// same tool call and the RepeatedToolCallPolicy action was
+	// RepeatedToolCallTerminate.
+	TerminationRepeatedToolCall TerminationReason = "repeated_tool_call"
 )
 
+// IsCustom reports whether r is a namespaced reason set with
+// ExecutionContext.SetTerminationReason rather than a built-in constant.
+func (r TerminationReason) IsCustom() bool {
+	namespace, outcome, ok := strings.Cut(string(r), ":")
+	return ok && namespace != "" && outcome != ""
+}
+
 // -----------------------------------------------------------------------------
 // Execution Result
 // -----------------------------------------------------------------------------
@@ -39,9 +57,24 @@ const (
 // ExecutionResult contains the final result of an execution run.
 // Access this via ExecutionContext.Result() after execution completes.
 type ExecutionResult struct {
-	// TerminationReason indicates how execution ended.
+	// TerminationReason indicates how execution ended. Successful executions report
+	// the custom reason set with ExecutionContext.SetTerminationReason, if any.
 	TerminationReason TerminationReason
 
+	// TerminatedBy is the name of the Termination that accepted the final answer, for
+	// agents that route several answer sections to different terminations. Empty if the
+	// execution did not end through a Termination.
+	TerminatedBy string
+
+	// TerminatedByTool is true when a successful call to a [TerminalTool] ended the
+	// execution. TerminatedBy then holds the tool name.
+	TerminatedByTool bool
+
+	// Candidates holds every candidate answer with its validation result when the
+	// accepting Termination takes several candidates in one step. Output then holds one
+	// TextContent per candidate, in the same order. Nil otherwise.
+	Candidates []AnswerCandidate
+
 	// Output is the final output from the AgentLoop (set when terminated successfully).
 	// This is a slice of ContentPart to support multimodal outputs.
 	// Nil if terminated due to error, limit, or cancellation.
@@ -137,12 +170,18 @@ type BeforeExecutionEvent struct {
 type AfterExecutionEvent struct {
 	BaseEvent
 
-	// TerminationReason indicates how execution ended.
+	// TerminationReason indicates how execution ended. Successful executions report
+	// the custom reason set with ExecutionContext.SetTerminationReason, if any.
 	TerminationReason TerminationReason
 
 	// Error is the error that caused termination, if any.
 	// Nil for successful termination or limit exceeded.
 	Error error
+
+	// Summary is a snapshot of the execution's stats at completion, including its
+	// descendants: tokens by model, tool calls and errors, rejections by validator,
+	// iterations and the termination reason. See [ExecutionSummary].
+	Summary *ExecutionSummary
 }
 
 // -----------------------------------------------------------------------------
@@ -184,10 +223,30 @@ type BeforeModelCallEvent struct {
 	// This is typically []llms.MessageContent but typed as any to avoid import.
 	// Subscribers can modify this slice for ephemeral context injection.
 	Request any
+
+	// Response, together with Skip, lets a subscriber answer the call itself, e.g. from
+	// a prompt-level cache. The model adapter then returns Response verbatim without
+	// calling the provider, and publishes AfterModelCallEvent with Cached set and the
+	// token counts of Response.Info. Only the first choice is emitted to streaming
+	// subscribers.
+	Response *ContentResponse
+
+	// Skip makes the model adapter skip the provider call and use Response instead.
+	// Ignored while Response is nil.
+	Skip bool
+}
+
+// SkippedResponse returns the response a subscriber supplied with Skip, and false if
+// the model should be called.
+func (e *BeforeModelCallEvent) SkippedResponse() (*ContentResponse, bool) {
+	if !e.Skip || e.Response == nil {
+		return nil, false
+	}
+	return e.Response, true
 }
 
 // AfterModelCallEvent is published after each model API call completes.
-// Stats updated: InputTokens, OutputTokens (and per-model variants).
+// Stats updated: InputTokens, OutputTokens, CachedPromptTokens (and per-model variants).
 type AfterModelCallEvent struct {
 	BaseEvent
 
@@ -206,6 +265,24 @@ type AfterModelCallEvent struct {
 	// OutputTokens is the number of output/completion tokens generated.
 	OutputTokens int
 
+	// CachedInputTokens is the number of input tokens served from the provider's prompt
+	// cache. Zero when the provider does not report it.
+	CachedInputTokens int
+
+	// ReasoningTokens is the number of output tokens the model spent on native
+	// reasoning. Zero when the provider does not report it.
+	ReasoningTokens int
+
+	// Cached is true when the provider was not called because a BeforeModelCallEvent
+	// subscriber supplied the response (see BeforeModelCallEvent.Skip). Token counts
+	// are those of the supplied response.
+	Cached bool
+
+	// StreamedOutputTokens is the number of output tokens already counted while the
+	// response streamed (see [StreamTokenMeter]). Stats only add the part of
+	// OutputTokens beyond it.
+	StreamedOutputTokens int
+
 	// Duration is how long the call took.
 	Duration time.Duration
 
@@ -229,10 +306,14 @@ type BeforeToolCallEvent struct {
 	// Args contains the arguments for the tool.
 	// Subscribers can modify this for interception/transformation.
 	Args any
+
+	// DryRun is true when the execution is a dry run (see [WithDryRun]).
+	DryRun bool
 }
 
 // AfterToolCallEvent is published after each tool execution completes.
-// Stats updated: ToolCallsErrorTotal, ToolCallsErrorConsecutive (on error).
+// Stats updated: ToolCallsErrorTotal, ToolCallsErrorConsecutive (on error),
+// ToolCallsUnknown / ToolCallsUnavailable (on ErrUnknownTool / ErrToolUnavailable).
 type AfterToolCallEvent struct {
 	BaseEvent
 
@@ -250,12 +331,70 @@ type AfterToolCallEvent struct {
 
 	// Error is any error that occurred (nil if successful).
 	Error error
+
+	// DryRun is true when the execution is a dry run (see [WithDryRun]). Side-effecting
+	// tools stubbed by the toolchain report the canned dry-run observation as Output.
+	DryRun bool
+}
+
+// RepeatedToolCallEvent is published when the same tool call (same tool name and
+// identical arguments) is made enough times in a row to trigger the
+// RepeatedToolCallPolicy. The repeated call is not executed.
+// Stats updated: SCRepeatedToolCalls (and per-tool variant).
+type RepeatedToolCallEvent struct {
+	BaseEvent
+
+	// ToolName is the name of the repeated tool.
+	ToolName string
+
+	// Args contains the repeated arguments.
+	Args any
+
+	// Count is how many times in a row the call has been made, including this one.
+	Count int
+
+	// Action is how the repeated call is handled.
+	Action RepeatedToolCallAction
+}
+
+// ToolProgressEvent is published by a long-running tool to report how far along it is,
+// so UIs and loggers can render progress while the tool runs. Tools publish it with
+// [ReportToolProgress].
+//
+// Progress events do not update stats and do not count as iterations. They count toward
+// the event recursion depth like any other event, so a subscriber that publishes
+// ToolProgressEvent from OnToolProgress hits the recursion limit.
+type ToolProgressEvent struct {
+	BaseEvent
+
+	// ToolName is the name of the tool reporting progress.
+	ToolName string
+
+	// Percent is the completion percentage, from 0 to 100. Negative if the tool cannot
+	// estimate it.
+	Percent float64
+
+	// Message describes the current step, e.g. "Indexed 4,000 of 10,000 rows".
+	Message string
 }
 
 // -----------------------------------------------------------------------------
 // Parse Error Event
 // -----------------------------------------------------------------------------
 
+// FormatFallbackEvent is published when a format with fallbacks (see format.NewAuto)
+// could not parse the output with its primary format and a fallback format matched.
+// Stats updated: SCFormatFallbacks.
+type FormatFallbackEvent struct {
+	BaseEvent
+
+	// Primary is the name of the primary format, e.g. "xml".
+	Primary string
+
+	// Matched is the name of the fallback format that parsed the output.
+	Matched string
+}
+
 // ParseErrorEvent is published when parsing fails.
 // Stats updated: Based on ErrorType - format, toolchain, termination, or section errors.
 type ParseErrorEvent struct {
@@ -268,7 +407,8 @@ type ParseErrorEvent struct {
 	// RawContent is the content that failed to parse.
 	RawContent string
 
-	// Error is the parse error that occurred.
+	// Error is the parse error that occurred. When the parser can locate the failure
+	// it is a *[ParseError] with the line, column and expected tokens; use errors.As.
 	Error error
 }
 
@@ -288,7 +428,8 @@ type ValidatorCalledEvent struct {
 }
 
 // ValidatorResultEvent is published after a validator completes.
-// Stats updated: AnswerRejectedTotal, AnswerRejectedBy (when rejected).
+// Stats updated: AnswerRejectedTotal, AnswerRejectedBy (when rejected, unless
+// ContinueWithoutRejection is set).
 type ValidatorResultEvent struct {
 	BaseEvent
 
@@ -304,6 +445,17 @@ type ValidatorResultEvent struct {
 	// Feedback contains the rejection feedback sections.
 	// Only set when Accepted is false.
 	Feedback []FormattedSection
+
+	// ContinueWithoutRejection is true when the validator asked for another iteration
+	// without rejecting the answer. Accepted is false and Feedback holds the guidance.
+	ContinueWithoutRejection bool
+
+	// Scored is true when the event carries the validator's Score, published with
+	// PublishScoredValidatorResult.
+	Scored bool
+
+	// Score is the validator's score (see ValidationResult.Score). Only set when Scored.
+	Score float64
 }
 
 // -----------------------------------------------------------------------------
@@ -350,7 +502,8 @@ type LimitExceededEvent struct {
 // -----------------------------------------------------------------------------
 
 // CompactionEvent is published after a successful compaction.
-// Stats updated: SCCompactions counter is incremented.
+// Stats updated: SCCompactions counter is incremented, and
+// SCCompactionTokensSaved by the estimated tokens saved.
 type CompactionEvent struct {
 	BaseEvent
 
@@ -362,10 +515,68 @@ type CompactionEvent struct {
 	// compaction.
 	ScratchpadLengthAfter int
 
+	// EstimatedTokensBefore is the scratchpad's estimated
+	// token count before compaction (see TokenEstimator).
+	EstimatedTokensBefore int
+
+	// EstimatedTokensAfter is the scratchpad's estimated
+	// token count after compaction.
+	EstimatedTokensAfter int
+
 	// Duration is how long the compaction took.
 	Duration time.Duration
 }
 
+// -----------------------------------------------------------------------------
+// Handoff Event
+// -----------------------------------------------------------------------------
+
+// HandoffEvent is published when a handoff tool (see [NewHandoffTool]) finished running
+// its target agent in a child context. It is published on the context that made the
+// handoff, after the child completed.
+// Stats updated: none (the child's stats already propagated to the parent).
+type HandoffEvent struct {
+	BaseEvent
+
+	// Target is the name of the handoff tool, which also names the child context.
+	Target string
+
+	// Request is what the agent asked the target agent to do.
+	Request string
+
+	// TerminationReason is how the target agent's execution ended.
+	TerminationReason TerminationReason
+
+	// Output is the target agent's final answer, returned as the tool's observation.
+	// Empty if the target agent did not finish successfully.
+	Output string
+
+	// Error is the error the target agent's execution ended with, if any.
+	Error error
+}
+
+// -----------------------------------------------------------------------------
+// Answer Discarded Event
+// -----------------------------------------------------------------------------
+
+// AnswerDiscardedEvent is published by agent loops that give actions priority over
+// answers, such as the react agent, when one response contains both: the actions run and
+// the answer is discarded. Frequent discards usually mean the prompt lets the model
+// answer before it has seen the tool results. Publishing it does not change behavior.
+// Stats updated: SCAnswersDiscarded.
+type AnswerDiscardedEvent struct {
+	BaseEvent
+
+	// Section is the name of the answer section that was discarded.
+	Section string
+
+	// Answer is the discarded answer text.
+	Answer string
+
+	// Actions are the contents of the action sections that took priority.
+	Actions []string
+}
+
 // -----------------------------------------------------------------------------
 // Common Event (User-Defined)
 // -----------------------------------------------------------------------------
@@ -378,6 +589,8 @@ type CompactionEvent struct {
 //
 // The EventName field in BaseEvent is set from the first argument.
 // Use a namespaced format to avoid collisions (e.g., "myapp:event_name").
+// To catch misspelled names, declare them with events.Registry.DeclareCommonEvent and
+// publish with ExecutionContext.PublishCommonEventStrict.
 type CommonEvent struct {
 	BaseEvent
 
@@ -0,0 +1,369 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/rickchristie/gent"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Event type discriminators written to the "event_type" key by [Encode].
+//
+// Unlike BaseEvent.EventName, which is free-form for CommonEvent and CommonDiffEvent,
+// the event type identifies the Go struct and is what [Decode] uses to reconstruct it.
+const (
+	EventTypeBeforeExecution  = "before_execution"
+	EventTypeAfterExecution   = "after_execution"
+	EventTypeBeforeIteration  = "before_iteration"
+	EventTypeAfterIteration   = "after_iteration"
+	EventTypeBeforeModelCall  = "before_model_call"
+	EventTypeAfterModelCall   = "after_model_call"
+	EventTypeBeforeToolCall   = "before_tool_call"
+	EventTypeAfterToolCall    = "after_tool_call"
+	EventTypeRepeatedToolCall = "repeated_tool_call"
+	EventTypeToolProgress     = "tool_progress"
+	EventTypeParseError       = "parse_error"
+	EventTypeFormatFallback   = "format_fallback"
+	EventTypeValidatorCalled  = "validator_called"
+	EventTypeValidatorResult  = "validator_result"
+	EventTypeError            = "error"
+	EventTypeLimitExceeded    = "limit_exceeded"
+	EventTypeCompaction       = "compaction"
+	EventTypeHandoff          = "handoff"
+	EventTypeAnswerDiscarded  = "answer_discarded"
+	EventTypeCommon           = "common"
+	EventTypeCommonDiff       = "common_diff"
+)
+
+// ErrUnknownEventType is returned by [Encode] and [Decode] for event types they
+// don't recognize.
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// eventTypes maps discriminators to event struct types.
+var eventTypes = map[string]reflect.Type{
+	EventTypeBeforeExecution:  reflect.TypeOf(gent.BeforeExecutionEvent{}),
+	EventTypeAfterExecution:   reflect.TypeOf(gent.AfterExecutionEvent{}),
+	EventTypeBeforeIteration:  reflect.TypeOf(gent.BeforeIterationEvent{}),
+	EventTypeAfterIteration:   reflect.TypeOf(gent.AfterIterationEvent{}),
+	EventTypeBeforeModelCall:  reflect.TypeOf(gent.BeforeModelCallEvent{}),
+	EventTypeAfterModelCall:   reflect.TypeOf(gent.AfterModelCallEvent{}),
+	EventTypeBeforeToolCall:   reflect.TypeOf(gent.BeforeToolCallEvent{}),
+	EventTypeAfterToolCall:    reflect.TypeOf(gent.AfterToolCallEvent{}),
+	EventTypeRepeatedToolCall: reflect.TypeOf(gent.RepeatedToolCallEvent{}),
+	EventTypeToolProgress:     reflect.TypeOf(gent.ToolProgressEvent{}),
+	EventTypeParseError:       reflect.TypeOf(gent.ParseErrorEvent{}),
+	EventTypeFormatFallback:   reflect.TypeOf(gent.FormatFallbackEvent{}),
+	EventTypeValidatorCalled:  reflect.TypeOf(gent.ValidatorCalledEvent{}),
+	EventTypeValidatorResult:  reflect.TypeOf(gent.ValidatorResultEvent{}),
+	EventTypeError:            reflect.TypeOf(gent.ErrorEvent{}),
+	EventTypeLimitExceeded:    reflect.TypeOf(gent.LimitExceededEvent{}),
+	EventTypeCompaction:       reflect.TypeOf(gent.CompactionEvent{}),
+	EventTypeHandoff:          reflect.TypeOf(gent.HandoffEvent{}),
+	EventTypeAnswerDiscarded:  reflect.TypeOf(gent.AnswerDiscardedEvent{}),
+	EventTypeCommon:           reflect.TypeOf(gent.CommonEvent{}),
+	EventTypeCommonDiff:       reflect.TypeOf(gent.CommonDiffEvent{}),
+}
+
+// eventTypeNames is the reverse of eventTypes.
+var eventTypeNames = func() map[reflect.Type]string {
+	names := make(map[reflect.Type]string, len(eventTypes))
+	for name, typ := range eventTypes {
+		names[typ] = name
+	}
+	return names
+}()
+
+var (
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	baseEventType       = reflect.TypeOf(gent.BaseEvent{})
+	agentLoopResultType = reflect.TypeOf(&gent.AgentLoopResult{})
+)
+
+// agentLoopResultJSON is the wire form of gent.AgentLoopResult.
+type agentLoopResultJSON struct {
+	Action           gent.LoopAction   `json:"action"`
+	NextPrompt       string            `json:"next_prompt,omitempty"`
+	Result           []json.RawMessage `json:"result,omitempty"`
+	TerminatedBy     string            `json:"terminated_by,omitempty"`
+	TerminatedByTool bool              `json:"terminated_by_tool,omitempty"`
+}
+
+// Encode serializes a framework event to self-describing JSON for persistence.
+//
+// The output is a flat object whose first key is "event_type" (see the EventType
+// constants), followed by the BaseEvent fields and the event's own fields in
+// declaration order, with snake_case keys:
+//
+//	{"event_type":"after_tool_call","event_name":"gent:tool_call:after",
+//	 "timestamp":"2025-01-02T15:04:05Z","iteration":1,"depth":0,
+//	 "tool_name":"search","args":{"query":"weather"},"output":"sunny",
+//	 "duration":1500000,"error":null,"dry_run":false}
+//
+// Field encoding:
+//   - error fields are written as their message string (null when nil)
+//   - time.Duration fields are written as integer nanoseconds
+//   - any fields (Request, Args, Output, Answer, Data, Before, After) are JSON-encoded;
+//     values that can't be encoded (funcs, channels, cyclic data) are written as their
+//     String() if they implement fmt.Stringer, otherwise redacted to an
+//     "<unserializable T>" placeholder instead of failing the whole event
+//   - AgentLoopResult content parts use langchaingo's content part encoding
+//
+// Returns [ErrUnknownEventType] for events not defined by the gent package.
+func Encode(event gent.Event) ([]byte, error) {
+	val := reflect.ValueOf(event)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, fmt.Errorf("%w: nil event", ErrUnknownEventType)
+		}
+		val = val.Elem()
+	}
+	eventType, ok := eventTypeNames[val.Type()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnknownEventType, event)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"event_type":`)
+	writeJSON(&buf, eventType)
+	if err := encodeFields(&buf, val); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeFields writes the fields of struct val, flattening the embedded BaseEvent.
+func encodeFields(buf *bytes.Buffer, val reflect.Value) error {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+		if field.Anonymous && field.Type == baseEventType {
+			if err := encodeFields(buf, fieldVal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		buf.WriteByte(',')
+		writeJSON(buf, snakeCase(field.Name))
+		buf.WriteByte(':')
+
+		encoded, err := encodeValue(fieldVal)
+		if err != nil {
+			return fmt.Errorf("encode %s.%s: %w", typ.Name(), field.Name, err)
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
+
+// encodeValue encodes a single field value.
+func encodeValue(val reflect.Value) ([]byte, error) {
+	switch {
+	case val.Type() == errorType:
+		if val.IsNil() {
+			return []byte("null"), nil
+		}
+		return json.Marshal(val.Interface().(error).Error())
+	case val.Type() == agentLoopResultType:
+		if val.IsNil() {
+			return []byte("null"), nil
+		}
+		return encodeAgentLoopResult(val.Interface().(*gent.AgentLoopResult))
+	case val.Kind() == reflect.Interface:
+		if val.IsNil() {
+			return []byte("null"), nil
+		}
+		return encodeAny(val.Interface())
+	default:
+		return json.Marshal(val.Interface())
+	}
+}
+
+// encodeAny encodes an arbitrary payload. Errors are written as their message. Values
+// that can't be JSON-encoded are stringified via fmt.Stringer, or replaced by an
+// "<unserializable T>" placeholder so the rest of the event is still persisted.
+func encodeAny(v any) ([]byte, error) {
+	if err, ok := v.(error); ok {
+		return json.Marshal(err.Error())
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		return data, nil
+	}
+	if stringer, ok := v.(fmt.Stringer); ok {
+		return json.Marshal(stringer.String())
+	}
+	return json.Marshal(fmt.Sprintf("<unserializable %T>", v))
+}
+
+// encodeAgentLoopResult encodes an AgentLoopResult with its content parts.
+func encodeAgentLoopResult(result *gent.AgentLoopResult) ([]byte, error) {
+	wire := agentLoopResultJSON{
+		Action:           result.Action,
+		NextPrompt:       result.NextPrompt,
+		TerminatedBy:     result.TerminatedBy,
+		TerminatedByTool: result.TerminatedByTool,
+	}
+	for _, part := range result.Result {
+		data, err := json.Marshal(part)
+		if err != nil {
+			data, err = json.Marshal(llms.TextContent{Text: fmt.Sprint(part)})
+			if err != nil {
+				return nil, err
+			}
+		}
+		wire.Result = append(wire.Result, data)
+	}
+	return json.Marshal(wire)
+}
+
+// Decode reconstructs a typed event from JSON produced by [Encode].
+//
+// The returned event is a pointer to the concrete event struct (e.g.,
+// *gent.AfterToolCallEvent), matching what subscribers receive. Fields round-trip
+// with these exceptions:
+//   - error fields are restored as errors.New(message); sentinel identity is lost
+//   - any fields hold generic JSON values (map[string]any, []any, string, float64,
+//     bool) rather than their original Go types
+//
+// Unknown keys are ignored so older readers can decode newer records. Returns
+// [ErrUnknownEventType] if the "event_type" discriminator is missing or unknown.
+func Decode(data []byte) (gent.Event, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+
+	var eventType string
+	if typeJSON, ok := raw["event_type"]; ok {
+		if err := json.Unmarshal(typeJSON, &eventType); err != nil {
+			return nil, fmt.Errorf("decode event_type: %w", err)
+		}
+	}
+	typ, ok := eventTypes[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+	}
+
+	ptr := reflect.New(typ)
+	if err := decodeFields(raw, ptr.Elem()); err != nil {
+		return nil, err
+	}
+	return ptr.Interface().(gent.Event), nil
+}
+
+// decodeFields sets the fields of struct val from raw, flattening the embedded
+// BaseEvent.
+func decodeFields(raw map[string]json.RawMessage, val reflect.Value) error {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+		if field.Anonymous && field.Type == baseEventType {
+			if err := decodeFields(raw, fieldVal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, ok := raw[snakeCase(field.Name)]
+		if !ok || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+			continue
+		}
+		if err := decodeValue(data, fieldVal); err != nil {
+			return fmt.Errorf("decode %s.%s: %w", typ.Name(), field.Name, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes data into a single field value.
+func decodeValue(data []byte, val reflect.Value) error {
+	switch {
+	case val.Type() == errorType:
+		var msg string
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(errors.New(msg)))
+		return nil
+	case val.Type() == agentLoopResultType:
+		result, err := decodeAgentLoopResult(data)
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(result))
+		return nil
+	case val.Kind() == reflect.Interface:
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(&generic).Elem())
+		return nil
+	default:
+		return json.Unmarshal(data, val.Addr().Interface())
+	}
+}
+
+// decodeAgentLoopResult decodes an AgentLoopResult and its content parts.
+func decodeAgentLoopResult(data []byte) (*gent.AgentLoopResult, error) {
+	var wire agentLoopResultJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, err
+	}
+	result := &gent.AgentLoopResult{
+		Action:           wire.Action,
+		NextPrompt:       wire.NextPrompt,
+		TerminatedBy:     wire.TerminatedBy,
+		TerminatedByTool: wire.TerminatedByTool,
+	}
+	if len(wire.Result) == 0 {
+		return result, nil
+	}
+
+	// Reuse langchaingo's content part decoding via MessageContent
+	partsJSON, err := json.Marshal(map[string]any{"parts": wire.Result})
+	if err != nil {
+		return nil, err
+	}
+	var message llms.MessageContent
+	if err := json.Unmarshal(partsJSON, &message); err != nil {
+		return nil, err
+	}
+	for _, part := range message.Parts {
+		result.Result = append(result.Result, part)
+	}
+	return result, nil
+}
+
+// writeJSON writes v as JSON. Only used for strings, which never fail to encode.
+func writeJSON(buf *bytes.Buffer, v string) {
+	data, _ := json.Marshal(v)
+	buf.Write(data)
+}
+
+// snakeCase converts a Go field name to snake_case (e.g., "DryRun" -> "dry_run").
+func snakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,269 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var codecTestBase = gent.BaseEvent{
+	EventName: gent.EventNameToolCallAfter,
+	Timestamp: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+	Iteration: 2,
+	Depth:     1,
+}
+
+// withName returns codecTestBase with the given event name.
+func withName(name string) gent.BaseEvent {
+	base := codecTestBase
+	base.EventName = name
+	return base
+}
+
+func TestEncode_Format(t *testing.T) {
+	event := &gent.AfterToolCallEvent{
+		BaseEvent: codecTestBase,
+		ToolName:  "search",
+		Args:      map[string]any{"query": "weather"},
+		Output:    "sunny",
+		Duration:  1500 * time.Microsecond,
+		Error:     errors.New("timeout"),
+	}
+
+	data, err := Encode(event)
+
+	require.NoError(t, err)
+	expected := `{"event_type":"after_tool_call","event_name":"gent:tool_call:after",` +
+		`"timestamp":"2025-01-02T15:04:05Z","iteration":2,"depth":1,` +
+		`"tool_name":"search","args":{"query":"weather"},"output":"sunny",` +
+		`"duration":1500000,"error":"timeout","dry_run":false}`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    gent.Event
+		expected gent.Event
+	}{
+		{
+			name:     "before execution",
+			input:    &gent.BeforeExecutionEvent{BaseEvent: withName(gent.EventNameExecutionBefore)},
+			expected: &gent.BeforeExecutionEvent{BaseEvent: withName(gent.EventNameExecutionBefore)},
+		},
+		{
+			name: "after execution with error",
+			input: &gent.AfterExecutionEvent{
+				BaseEvent:         withName(gent.EventNameExecutionAfter),
+				TerminationReason: gent.TerminationError,
+				Error:             errors.New("model failed"),
+			},
+			expected: &gent.AfterExecutionEvent{
+				BaseEvent:         withName(gent.EventNameExecutionAfter),
+				TerminationReason: gent.TerminationError,
+				Error:             errors.New("model failed"),
+			},
+		},
+		{
+			name: "after execution with summary",
+			input: &gent.AfterExecutionEvent{
+				BaseEvent:         withName(gent.EventNameExecutionAfter),
+				TerminationReason: gent.TerminationSuccess,
+				Summary: &gent.ExecutionSummary{
+					TerminationReason:  gent.TerminationSuccess,
+					Iterations:         2,
+					InputTokens:        1200,
+					InputTokensByModel: map[string]int64{"gpt-4": 1200},
+					ToolCallsByName:    map[string]int64{"search": 1},
+					Errors:             map[gent.StatKey]int64{gent.SCToolCallsErrorTotal: 1},
+					Counters:           map[gent.StatKey]int64{gent.SCIterations: 2},
+				},
+			},
+			expected: &gent.AfterExecutionEvent{
+				BaseEvent:         withName(gent.EventNameExecutionAfter),
+				TerminationReason: gent.TerminationSuccess,
+				Summary: &gent.ExecutionSummary{
+					TerminationReason:  gent.TerminationSuccess,
+					Iterations:         2,
+					InputTokens:        1200,
+					InputTokensByModel: map[string]int64{"gpt-4": 1200},
+					ToolCallsByName:    map[string]int64{"search": 1},
+					Errors:             map[gent.StatKey]int64{gent.SCToolCallsErrorTotal: 1},
+					Counters:           map[gent.StatKey]int64{gent.SCIterations: 2},
+				},
+			},
+		},
+		{
+			name: "after iteration with content parts",
+			input: &gent.AfterIterationEvent{
+				BaseEvent: withName(gent.EventNameIterationAfter),
+				Result: &gent.AgentLoopResult{
+					Action: gent.LATerminate,
+					Result: []gent.ContentPart{
+						llms.TextContent{Text: "done"},
+						llms.ImageURLContent{URL: "https://example.com/a.png"},
+					},
+					TerminatedBy: "answer",
+				},
+				Duration: time.Second,
+			},
+			expected: &gent.AfterIterationEvent{
+				BaseEvent: withName(gent.EventNameIterationAfter),
+				Result: &gent.AgentLoopResult{
+					Action: gent.LATerminate,
+					Result: []gent.ContentPart{
+						llms.TextContent{Text: "done"},
+						llms.ImageURLContent{URL: "https://example.com/a.png"},
+					},
+					TerminatedBy: "answer",
+				},
+				Duration: time.Second,
+			},
+		},
+		{
+			name: "after iteration terminated by tool",
+			input: &gent.AfterIterationEvent{
+				BaseEvent: withName(gent.EventNameIterationAfter),
+				Result: &gent.AgentLoopResult{
+					Action:           gent.LATerminate,
+					Result:           []gent.ContentPart{llms.TextContent{Text: "submitted"}},
+					TerminatedBy:     "submit_order",
+					TerminatedByTool: true,
+				},
+			},
+			expected: &gent.AfterIterationEvent{
+				BaseEvent: withName(gent.EventNameIterationAfter),
+				Result: &gent.AgentLoopResult{
+					Action:           gent.LATerminate,
+					Result:           []gent.ContentPart{llms.TextContent{Text: "submitted"}},
+					TerminatedBy:     "submit_order",
+					TerminatedByTool: true,
+				},
+			},
+		},
+		{
+			name: "after model call with response",
+			input: &gent.AfterModelCallEvent{
+				BaseEvent: withName(gent.EventNameModelCallAfter),
+				Model:     "gpt-4",
+				Request: []llms.MessageContent{
+					llms.TextParts(llms.ChatMessageTypeHuman, "hi"),
+				},
+				Response: &gent.ContentResponse{
+					Choices: []*gent.ContentChoice{{Content: "hello", StopReason: "stop"}},
+					Info:    &gent.GenerationInfo{InputTokens: 10, OutputTokens: 5},
+				},
+				InputTokens:  10,
+				OutputTokens: 5,
+			},
+			expected: &gent.AfterModelCallEvent{
+				BaseEvent: withName(gent.EventNameModelCallAfter),
+				Model:     "gpt-4",
+				Request: []any{
+					map[string]any{"role": "human", "text": "hi"},
+				},
+				Response: &gent.ContentResponse{
+					Choices: []*gent.ContentChoice{{Content: "hello", StopReason: "stop"}},
+					Info:    &gent.GenerationInfo{InputTokens: 10, OutputTokens: 5},
+				},
+				InputTokens:  10,
+				OutputTokens: 5,
+			},
+		},
+		{
+			name: "validator result with feedback",
+			input: &gent.ValidatorResultEvent{
+				BaseEvent:     withName(gent.EventNameValidatorResult),
+				ValidatorName: "schema",
+				Answer:        "42",
+				Feedback:      []gent.FormattedSection{{Name: "error", Content: "bad"}},
+			},
+			expected: &gent.ValidatorResultEvent{
+				BaseEvent:     withName(gent.EventNameValidatorResult),
+				ValidatorName: "schema",
+				Answer:        "42",
+				Feedback:      []gent.FormattedSection{{Name: "error", Content: "bad"}},
+			},
+		},
+		{
+			name: "limit exceeded",
+			input: &gent.LimitExceededEvent{
+				BaseEvent: withName(gent.EventNameLimitExceeded),
+				Limit: gent.Limit{
+					Type: gent.LimitKeyPrefix, Key: gent.SCToolCallsFor, MaxValue: 3,
+				},
+				CurrentValue: 4,
+				MatchedKey:   gent.SCToolCallsFor + "search",
+			},
+			expected: &gent.LimitExceededEvent{
+				BaseEvent: withName(gent.EventNameLimitExceeded),
+				Limit: gent.Limit{
+					Type: gent.LimitKeyPrefix, Key: gent.SCToolCallsFor, MaxValue: 3,
+				},
+				CurrentValue: 4,
+				MatchedKey:   gent.SCToolCallsFor + "search",
+			},
+		},
+		{
+			name: "tool progress",
+			input: &gent.ToolProgressEvent{
+				BaseEvent: withName(gent.EventNameToolCallProgress),
+				ToolName:  "import",
+				Percent:   40,
+				Message:   "Imported 4 of 10 batches",
+			},
+			expected: &gent.ToolProgressEvent{
+				BaseEvent: withName(gent.EventNameToolCallProgress),
+				ToolName:  "import",
+				Percent:   40,
+				Message:   "Imported 4 of 10 batches",
+			},
+		},
+		{
+			name: "common event with non-serializable data is stringified",
+			input: &gent.CommonEvent{
+				BaseEvent:   withName("myapp:callback"),
+				Description: "registered callback",
+				Data:        func() {},
+			},
+			expected: &gent.CommonEvent{
+				BaseEvent:   withName("myapp:callback"),
+				Description: "registered callback",
+				Data:        "<unserializable func()>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.input)
+			require.NoError(t, err)
+
+			decoded, err := Decode(data)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, decoded)
+		})
+	}
+}
+
+func TestEncodeDecode_Errors(t *testing.T) {
+	type customEvent struct{ gent.BaseEvent }
+
+	_, err := Encode(&customEvent{})
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+
+	_, err = Decode([]byte(`{"event_type":"unknown"}`))
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+
+	_, err = Decode([]byte(`{"event_name":"gent:error"}`))
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+
+	_, err = Decode([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -40,12 +40,17 @@
 //   - BeforeIterationEvent, AfterIterationEvent: Iteration lifecycle
 //   - BeforeModelCallEvent, AfterModelCallEvent: Model API calls
 //   - BeforeToolCallEvent, AfterToolCallEvent: Tool executions
+//   - ToolProgressEvent: Progress reported by long-running tools
 //   - ParseErrorEvent: Format/toolchain/termination parse failures
 //   - ValidatorCalledEvent, ValidatorResultEvent: Answer validation
 //   - ErrorEvent: General errors
+//   - HandoffEvent: A handoff tool ran another agent (gent.NewHandoffTool)
+//   - AnswerDiscardedEvent: An answer was discarded because actions took priority
 //
 // Custom events:
-//   - CommonEvent: User-defined events via execCtx.PublishCommonEvent()
+//   - CommonEvent: User-defined events via execCtx.PublishCommonEvent(), or
+//     execCtx.PublishCommonEventStrict() for names declared with
+//     Registry.DeclareCommonEvent()
 //
 // # Subscriber Interfaces
 //
@@ -54,9 +59,12 @@
 //   - gent.BeforeIterationSubscriber, gent.AfterIterationSubscriber
 //   - gent.BeforeModelCallSubscriber, gent.AfterModelCallSubscriber
 //   - gent.BeforeToolCallSubscriber, gent.AfterToolCallSubscriber
+//   - gent.ToolProgressSubscriber
 //   - gent.ParseErrorSubscriber
 //   - gent.ValidatorCalledSubscriber, gent.ValidatorResultSubscriber
 //   - gent.ErrorSubscriber
+//   - gent.HandoffSubscriber
+//   - gent.AnswerDiscardedSubscriber
 //   - gent.CommonEventSubscriber
 //
 // # Modifying Events
@@ -76,6 +84,26 @@
 //	    }
 //	}
 //
+// A BeforeModelCall subscriber can also answer the call itself, e.g. from a prompt-level
+// cache. The model adapter then skips the provider and publishes AfterModelCallEvent with
+// Cached set, counting the supplied response's tokens:
+//
+//	func (c *PromptCache) OnBeforeModelCall(
+//	    execCtx *gent.ExecutionContext,
+//	    event *gent.BeforeModelCallEvent,
+//	) {
+//	    messages, _ := event.Request.([]llms.MessageContent)
+//	    if key, err := models.PromptHash(messages); err == nil {
+//	        if response, ok := c.responses[key]; ok {
+//	            event.Response = response
+//	            event.Skip = true
+//	        }
+//	    }
+//	}
+//
+// To inject a one-time message from a tool or hook without subscribing to
+// BeforeModelCallEvent, use ExecutionContext.EnqueueEphemeralMessage.
+//
 // # Publishing Custom Events
 //
 // Use ExecutionContext.PublishCommonEvent() for application-specific events:
@@ -93,6 +121,38 @@
 //	    }
 //	}
 //
+// Or register a handler that only fires for a name prefix:
+//
+//	registry.SubscribeCommonPrefix("myapp:", func(
+//	    execCtx *gent.ExecutionContext,
+//	    event *gent.CommonEvent,
+//	) {
+//	    s.metrics.Increment(event.EventName)
+//	})
+//
+// # Tool Progress
+//
+// Long-running tools report progress with gent.ReportToolProgress, and subscribers render
+// it without agreeing on an event name convention:
+//
+//	// In the tool's Call method
+//	gent.ReportToolProgress(ctx, 40, "Indexed 4,000 of 10,000 rows")
+//
+//	// A progress bar subscriber
+//	func (s *ProgressBar) OnToolProgress(
+//	    execCtx *gent.ExecutionContext,
+//	    event *gent.ToolProgressEvent,
+//	) {
+//	    if event.Percent < 0 {
+//	        s.spinner(event.ToolName, event.Message)
+//	        return
+//	    }
+//	    s.render(event.ToolName, event.Percent, event.Message)
+//	}
+//
+// Progress events do not update stats or count as iterations. Like any other event,
+// they count toward the recursion depth described below.
+//
 // # Recursion Limits
 //
 // If a subscriber publishes events (which triggers other subscribers), recursion
@@ -103,5 +163,45 @@
 //
 // Exceeding the limit causes a panic with a descriptive message.
 //
+// # Panicking Subscribers
+//
+// By default a panic in a subscriber does not crash the run. The panic is recovered
+// and published as a [gent.ErrorEvent] whose error is a [gent.HookPanicError] naming
+// the subscriber; the remaining subscribers still receive the event and the agent
+// loop continues. Set executor.Config.HookPanicPolicy to [gent.HookPanicPropagate]
+// to let panics propagate instead:
+//
+//	exec := executor.New(loop, executor.Config{
+//	    HookPanicPolicy: gent.HookPanicPropagate,
+//	})
+//
+// # Persisting Events
+//
+// Encode serializes any framework event to self-describing JSON with an "event_type"
+// discriminator, and Decode reconstructs the typed event. Use them for audit logs or
+// replay:
+//
+//	for _, event := range execCtx.Events() {
+//	    data, err := events.Encode(event)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    store.Append(runID, data)
+//	}
+//
+//	event, err := events.Decode(data) // e.g. *gent.AfterToolCallEvent
+//
+// To persist a whole run, including its child contexts, write its timeline. Timeline
+// orders the events of the context tree, tagging each with its index and context ID,
+// and WriteTimeline writes them as JSON Lines. A debugging UI reads them back in order
+// and uses StatsAt to show the stats at any point:
+//
+//	if err := events.WriteTimeline(file, execCtx); err != nil {
+//	    return err
+//	}
+//
+//	records, err := events.ReadTimeline(file)
+//	stats := events.StatsAt(records, selected) // keyed by context ID
+//
 // See the gent package documentation for the complete event system design.
 package events
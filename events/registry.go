@@ -1,6 +1,12 @@
 package events
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
 	"github.com/rickchristie/gent"
 )
 
@@ -58,6 +64,7 @@ import (
 type Registry struct {
 	subscribers  []any
 	maxRecursion int
+	declared     map[string]bool
 }
 
 // DefaultMaxRecursion is the default maximum event recursion depth.
@@ -81,6 +88,78 @@ func (r *Registry) Subscribe(subscriber any) *Registry {
 	return r
 }
 
+// CommonEventHandler handles CommonEvent events registered via SubscribeCommonPrefix.
+type CommonEventHandler func(execCtx *gent.ExecutionContext, event *gent.CommonEvent)
+
+// SubscribeCommonPrefix registers a handler for CommonEvents whose EventName starts with
+// prefix. Handlers for other prefixes are not invoked, so there's no need to switch on
+// EventName inside a catch-all [gent.CommonEventSubscriber]:
+//
+//	registry.SubscribeCommonPrefix("myapp:", func(
+//	    execCtx *gent.ExecutionContext,
+//	    event *gent.CommonEvent,
+//	) {
+//	    log.Printf("%s: %s", event.EventName, event.Description)
+//	})
+//
+// An empty prefix matches every CommonEvent, including framework events published as
+// CommonEvent (e.g., gent.EventNameChildSpawn).
+//
+// # Ordering
+//
+// Prefix handlers share the subscriber list with interface-based subscribers, so all
+// of them are called in registration order. A prefix handler registered between two
+// CommonEventSubscribers runs after the first and before the second.
+func (r *Registry) SubscribeCommonPrefix(prefix string, handler CommonEventHandler) *Registry {
+	return r.Subscribe(&commonPrefixSubscriber{prefix: prefix, handler: handler})
+}
+
+// commonPrefixSubscriber adapts a CommonEventHandler to gent.CommonEventSubscriber,
+// calling the handler only for matching event names.
+type commonPrefixSubscriber struct {
+	prefix  string
+	handler CommonEventHandler
+}
+
+// OnCommonEvent implements gent.CommonEventSubscriber.
+func (s *commonPrefixSubscriber) OnCommonEvent(
+	execCtx *gent.ExecutionContext,
+	event *gent.CommonEvent,
+) {
+	if strings.HasPrefix(event.EventName, s.prefix) {
+		s.handler(execCtx, event)
+	}
+}
+
+// DeclareCommonEvent declares eventName as a CommonEvent name this application
+// publishes. Declared names form the contract checked by
+// [gent.ExecutionContext.PublishCommonEventStrict], which refuses undeclared names so
+// typos surface during development:
+//
+//	const (
+//	    EventCacheHit  = "myapp:cache_hit"
+//	    EventCacheMiss = "myapp:cache_miss"
+//	)
+//
+//	registry := events.NewRegistry().
+//	    DeclareCommonEvent(EventCacheHit).
+//	    DeclareCommonEvent(EventCacheMiss)
+//
+// Declaring is optional: [gent.ExecutionContext.PublishCommonEvent] publishes any name.
+func (r *Registry) DeclareCommonEvent(eventName string) *Registry {
+	if r.declared == nil {
+		r.declared = make(map[string]bool)
+	}
+	r.declared[eventName] = true
+	return r
+}
+
+// IsCommonEventDeclared reports whether eventName was declared with DeclareCommonEvent.
+// Implements [gent.CommonEventDeclarations].
+func (r *Registry) IsCommonEventDeclared(eventName string) bool {
+	return r.declared[eventName]
+}
+
 // SetMaxRecursion sets the maximum event recursion depth.
 // If a subscriber publishes an event that triggers another subscriber
 // that publishes an event, etc., this limit prevents infinite loops.
@@ -98,107 +177,195 @@ func (r *Registry) MaxRecursion() int {
 
 // Dispatch sends an event to all matching subscribers.
 // This is called by ExecutionContext.publish() after recording the event and updating stats.
+//
+// Subscriber panics are handled according to the ExecutionContext's
+// [gent.HookPanicPolicy].
 func (r *Registry) Dispatch(execCtx *gent.ExecutionContext, event gent.Event) {
 	switch e := event.(type) {
 	case *gent.BeforeExecutionEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.BeforeExecutionSubscriber); ok {
-				sub.OnBeforeExecution(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnBeforeExecution(execCtx, e) })
 			}
 		}
 	case *gent.AfterExecutionEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.AfterExecutionSubscriber); ok {
-				sub.OnAfterExecution(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnAfterExecution(execCtx, e) })
 			}
 		}
 	case *gent.BeforeIterationEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.BeforeIterationSubscriber); ok {
-				sub.OnBeforeIteration(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnBeforeIteration(execCtx, e) })
 			}
 		}
 	case *gent.AfterIterationEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.AfterIterationSubscriber); ok {
-				sub.OnAfterIteration(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnAfterIteration(execCtx, e) })
 			}
 		}
 	case *gent.BeforeModelCallEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.BeforeModelCallSubscriber); ok {
-				sub.OnBeforeModelCall(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnBeforeModelCall(execCtx, e) })
 			}
 		}
 	case *gent.AfterModelCallEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.AfterModelCallSubscriber); ok {
-				sub.OnAfterModelCall(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnAfterModelCall(execCtx, e) })
 			}
 		}
 	case *gent.BeforeToolCallEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.BeforeToolCallSubscriber); ok {
-				sub.OnBeforeToolCall(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnBeforeToolCall(execCtx, e) })
 			}
 		}
 	case *gent.AfterToolCallEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.AfterToolCallSubscriber); ok {
-				sub.OnAfterToolCall(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnAfterToolCall(execCtx, e) })
+			}
+		}
+	case *gent.RepeatedToolCallEvent:
+		for _, s := range r.subscribers {
+			if sub, ok := s.(gent.RepeatedToolCallSubscriber); ok {
+				r.notify(execCtx, event, s, func() { sub.OnRepeatedToolCall(execCtx, e) })
+			}
+		}
+	case *gent.ToolProgressEvent:
+		for _, s := range r.subscribers {
+			if sub, ok := s.(gent.ToolProgressSubscriber); ok {
+				r.notify(execCtx, event, s, func() { sub.OnToolProgress(execCtx, e) })
 			}
 		}
 	case *gent.ParseErrorEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.ParseErrorSubscriber); ok {
-				sub.OnParseError(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnParseError(execCtx, e) })
+			}
+		}
+	case *gent.FormatFallbackEvent:
+		for _, s := range r.subscribers {
+			if sub, ok := s.(gent.FormatFallbackSubscriber); ok {
+				r.notify(execCtx, event, s, func() { sub.OnFormatFallback(execCtx, e) })
 			}
 		}
 	case *gent.ValidatorCalledEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.ValidatorCalledSubscriber); ok {
-				sub.OnValidatorCalled(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnValidatorCalled(execCtx, e) })
 			}
 		}
 	case *gent.ValidatorResultEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.ValidatorResultSubscriber); ok {
-				sub.OnValidatorResult(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnValidatorResult(execCtx, e) })
 			}
 		}
 	case *gent.ErrorEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.ErrorSubscriber); ok {
-				sub.OnError(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnError(execCtx, e) })
 			}
 		}
 	case *gent.CommonEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.CommonEventSubscriber); ok {
-				sub.OnCommonEvent(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnCommonEvent(execCtx, e) })
 			}
 		}
 	case *gent.CompactionEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.CompactionSubscriber); ok {
-				sub.OnCompaction(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnCompaction(execCtx, e) })
+			}
+		}
+	case *gent.HandoffEvent:
+		for _, s := range r.subscribers {
+			if sub, ok := s.(gent.HandoffSubscriber); ok {
+				r.notify(execCtx, event, s, func() { sub.OnHandoff(execCtx, e) })
+			}
+		}
+	case *gent.AnswerDiscardedEvent:
+		for _, s := range r.subscribers {
+			if sub, ok := s.(gent.AnswerDiscardedSubscriber); ok {
+				r.notify(execCtx, event, s, func() { sub.OnAnswerDiscarded(execCtx, e) })
 			}
 		}
 	case *gent.LimitExceededEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.LimitExceededSubscriber); ok {
-				sub.OnLimitExceeded(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnLimitExceeded(execCtx, e) })
 			}
 		}
 	case *gent.CommonDiffEvent:
 		for _, s := range r.subscribers {
 			if sub, ok := s.(gent.CommonDiffEventSubscriber); ok {
-				sub.OnCommonDiffEvent(execCtx, e)
+				r.notify(execCtx, event, s, func() { sub.OnCommonDiffEvent(execCtx, e) })
 			}
 		}
 	}
 }
 
+// notify calls a subscriber's handler, applying the ExecutionContext's
+// gent.HookPanicPolicy: with gent.HookPanicRecover (the default), a panic is recovered
+// and published as an ErrorEvent carrying a gent.HookPanicError, and dispatch
+// continues with the next subscriber.
+func (r *Registry) notify(
+	execCtx *gent.ExecutionContext,
+	event gent.Event,
+	subscriber any,
+	handler func(),
+) {
+	if execCtx.HookPanicPolicy() == gent.HookPanicPropagate {
+		handler()
+		return
+	}
+
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+		// A subscriber that panics on the ErrorEvent reporting a hook panic would
+		// otherwise trigger another report, and so on
+		if e, ok := event.(*gent.ErrorEvent); ok && errors.Is(e.Error, gent.ErrHookPanic) {
+			return
+		}
+		execCtx.PublishError(&gent.HookPanicError{
+			Subscriber: subscriberName(subscriber),
+			EventName:  eventName(event),
+			Value:      value,
+			Stack:      debug.Stack(),
+		})
+	}()
+	handler()
+}
+
+// subscriberName identifies a subscriber in a gent.HookPanicError.
+func subscriberName(subscriber any) string {
+	if s, ok := subscriber.(*commonPrefixSubscriber); ok {
+		return fmt.Sprintf("common event handler for prefix %q", s.prefix)
+	}
+	return fmt.Sprintf("%T", subscriber)
+}
+
+// eventName returns the EventName of event's BaseEvent, or its Go type when unset.
+func eventName(event gent.Event) string {
+	v := reflect.Indirect(reflect.ValueOf(event))
+	if v.Kind() == reflect.Struct {
+		name := v.FieldByName("EventName")
+		if name.Kind() == reflect.String && name.String() != "" {
+			return name.String()
+		}
+	}
+	return fmt.Sprintf("%T", event)
+}
+
 // Len returns the number of registered subscribers.
 func (r *Registry) Len() int {
 	return len(r.subscribers)
@@ -208,3 +375,9 @@ func (r *Registry) Len() int {
 func (r *Registry) Clear() {
 	r.subscribers = make([]any, 0)
 }
+
+// Compile-time check that Registry implements gent.EventPublisher.
+var _ gent.EventPublisher = (*Registry)(nil)
+
+// Compile-time check that Registry implements gent.CommonEventDeclarations.
+var _ gent.CommonEventDeclarations = (*Registry)(nil)
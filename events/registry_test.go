@@ -326,6 +326,163 @@ func TestRegistry_Dispatch_CommonDiffEvent(t *testing.T) {
 	assert.Equal(t, event, sub.event)
 }
 
+func TestRegistry_SubscribeCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string // event names dispatched in order
+		expected []string // calls recorded as "<handler>:<event name>"
+	}{
+		{
+			name:  "handlers only fire for matching prefix",
+			input: []string{"myapp:cache_hit", "other:event", "myapp:billing:charge"},
+			expected: []string{
+				"all:myapp:cache_hit",
+				"myapp:myapp:cache_hit",
+				"all:other:event",
+				"all:myapp:billing:charge",
+				"myapp:myapp:billing:charge",
+				"billing:myapp:billing:charge",
+			},
+		},
+		{
+			name:     "no handler fires for unrelated events beyond catch-all",
+			input:    []string{"gent:child:spawn"},
+			expected: []string{"all:gent:child:spawn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			record := func(id string) CommonEventHandler {
+				return func(_ *gent.ExecutionContext, e *gent.CommonEvent) {
+					calls = append(calls, id+":"+e.EventName)
+				}
+			}
+			registry := NewRegistry().
+				SubscribeCommonPrefix("", record("all")).
+				SubscribeCommonPrefix("myapp:", record("myapp")).
+				SubscribeCommonPrefix("myapp:billing:", record("billing"))
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			for _, name := range tt.input {
+				event := &gent.CommonEvent{BaseEvent: gent.BaseEvent{EventName: name}}
+				registry.Dispatch(execCtx, event)
+			}
+
+			assert.Equal(t, tt.expected, calls)
+		})
+	}
+}
+
+func TestRegistry_SubscribeCommonPrefix_OrderWithInterfaceSubscribers(t *testing.T) {
+	var calls []string
+	first := &commonOrderSubscriber{calls: &calls, id: "first"}
+	last := &commonOrderSubscriber{calls: &calls, id: "last"}
+
+	registry := NewRegistry().
+		Subscribe(first).
+		SubscribeCommonPrefix("myapp:", func(_ *gent.ExecutionContext, _ *gent.CommonEvent) {
+			calls = append(calls, "prefix")
+		}).
+		Subscribe(last)
+
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	registry.Dispatch(execCtx, &gent.CommonEvent{BaseEvent: gent.BaseEvent{EventName: "myapp:x"}})
+
+	assert.Equal(t, []string{"first", "prefix", "last"}, calls)
+}
+
+type commonOrderSubscriber struct {
+	calls *[]string
+	id    string
+}
+
+func (s *commonOrderSubscriber) OnCommonEvent(_ *gent.ExecutionContext, _ *gent.CommonEvent) {
+	*s.calls = append(*s.calls, s.id)
+}
+
+func TestRegistry_DeclareCommonEvent_StrictPublish(t *testing.T) {
+	type input struct {
+		declare   []string
+		eventName string
+	}
+
+	type expected struct {
+		err       error
+		delivered []string
+		errors    int
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "declared name is published",
+			input: input{
+				declare:   []string{"myapp:cache_hit", "myapp:cache_miss"},
+				eventName: "myapp:cache_hit",
+			},
+			expected: expected{delivered: []string{"myapp:cache_hit"}},
+		},
+		{
+			name: "misspelled name is refused",
+			input: input{
+				declare:   []string{"myapp:cache_hit"},
+				eventName: "myapp:cahce_hit",
+			},
+			expected: expected{err: gent.ErrUndeclaredCommonEvent, errors: 1},
+		},
+		{
+			name:     "nothing declared refuses every name",
+			input:    input{eventName: "myapp:cache_hit"},
+			expected: expected{err: gent.ErrUndeclaredCommonEvent, errors: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var delivered []string
+			registry := NewRegistry().SubscribeCommonPrefix("myapp:", func(
+				_ *gent.ExecutionContext,
+				e *gent.CommonEvent,
+			) {
+				delivered = append(delivered, e.EventName)
+			})
+			for _, name := range tc.input.declare {
+				registry.DeclareCommonEvent(name)
+			}
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetEventPublisher(registry)
+
+			event, err := execCtx.PublishCommonEventStrict(tc.input.eventName, "desc", nil)
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				assert.Nil(t, event)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.input.eventName, event.EventName)
+			}
+			assert.Equal(t, tc.expected.delivered, delivered)
+			var errorEvents int
+			for _, e := range execCtx.Events() {
+				if errEvent, ok := e.(*gent.ErrorEvent); ok {
+					assert.ErrorIs(t, errEvent.Error, gent.ErrUndeclaredCommonEvent)
+					errorEvents++
+				}
+			}
+			assert.Equal(t, tc.expected.errors, errorEvents)
+
+			// The loose variant publishes any name
+			execCtx.PublishCommonEvent("myapp:anything", "desc", nil)
+			assert.Contains(t, delivered, "myapp:anything")
+		})
+	}
+}
+
 func TestRegistry_Dispatch_OnlyCallsMatchingSubscribers(t *testing.T) {
 	registry := NewRegistry()
 	beforeExecSub := &mockBeforeExecutionSubscriber{}
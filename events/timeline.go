@@ -0,0 +1,310 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rickchristie/gent"
+)
+
+// TimelineRecord is one event of a run's timeline, in the order produced by [Timeline].
+type TimelineRecord struct {
+	// Index is the record's 0-based position in the timeline.
+	Index int
+
+	// ContextID identifies the ExecutionContext that recorded the event: the context
+	// names from the root joined by "/" (e.g., "main/compaction"). A child that shares
+	// its name with an earlier sibling gets a "#n" suffix (e.g., "main/research#2").
+	ContextID string
+
+	// Event is the recorded event. Its BaseEvent carries the timestamp.
+	Event gent.Event
+}
+
+// timelineRecordJSON is the wire form of TimelineRecord, one per line.
+type timelineRecordJSON struct {
+	Index     int             `json:"index"`
+	ContextID string          `json:"context_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// Timeline returns the events of execCtx and all its descendants as a single ordered
+// timeline.
+//
+// Each context's events keep their recorded order. A child's events are merged into
+// its parent's after the parent's gent.EventNameChildSpawn event for that child, by
+// timestamp; when timestamps are equal, the child's events come first. The order is
+// deterministic for a given context tree, so a timeline written twice is identical.
+func Timeline(execCtx *gent.ExecutionContext) []TimelineRecord {
+	records := contextTimeline(execCtx, execCtx.Name())
+	for i := range records {
+		records[i].Index = i
+	}
+	return records
+}
+
+// contextTimeline returns the timeline of execCtx, whose context ID is id.
+func contextTimeline(execCtx *gent.ExecutionContext, id string) []TimelineRecord {
+	var records []TimelineRecord
+	for _, event := range execCtx.Events() {
+		records = append(records, TimelineRecord{ContextID: id, Event: event})
+	}
+
+	siblings := make(map[string]int)
+	for i, child := range execCtx.Children() {
+		name := child.Name()
+		siblings[name]++
+		childID := id + "/" + name
+		if siblings[name] > 1 {
+			childID += "#" + strconv.Itoa(siblings[name])
+		}
+		start := childSpawnPosition(records, id, i)
+		records = mergeTimeline(records, start, contextTimeline(child, childID))
+	}
+	return records
+}
+
+// childSpawnPosition returns the position after the n-th (0-based) child spawn event
+// recorded by context id, or len(records) if there is none.
+func childSpawnPosition(records []TimelineRecord, id string, n int) int {
+	for i, record := range records {
+		if record.ContextID != id {
+			continue
+		}
+		if common, ok := record.Event.(*gent.CommonEvent); ok &&
+			common.EventName == gent.EventNameChildSpawn {
+			if n == 0 {
+				return i + 1
+			}
+			n--
+		}
+	}
+	return len(records)
+}
+
+// mergeTimeline merges child into records from position start on, by timestamp,
+// preferring child records on ties.
+func mergeTimeline(records []TimelineRecord, start int, child []TimelineRecord) []TimelineRecord {
+	merged := make([]TimelineRecord, 0, len(records)+len(child))
+	merged = append(merged, records[:start]...)
+	i, j := start, 0
+	for i < len(records) && j < len(child) {
+		if timestamp(child[j].Event).After(timestamp(records[i].Event)) {
+			merged = append(merged, records[i])
+			i++
+			continue
+		}
+		merged = append(merged, child[j])
+		j++
+	}
+	merged = append(merged, records[i:]...)
+	return append(merged, child[j:]...)
+}
+
+// TimelineWriter writes timeline records as JSON Lines, one record per line:
+//
+//	{"index":0,"context_id":"main","event":{"event_type":"before_execution",...}}
+//
+// Events are encoded with [Encode]. The writer assigns indexes in the order records are
+// written. It is safe for concurrent use.
+type TimelineWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	next int
+}
+
+// NewTimelineWriter returns a TimelineWriter writing to w.
+func NewTimelineWriter(w io.Writer) *TimelineWriter {
+	return &TimelineWriter{w: w}
+}
+
+// Write appends event, recorded by the context contextID, to the timeline.
+func (tw *TimelineWriter) Write(contextID string, event gent.Event) error {
+	encoded, err := Encode(event)
+	if err != nil {
+		return err
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	line, err := json.Marshal(timelineRecordJSON{
+		Index:     tw.next,
+		ContextID: contextID,
+		Event:     encoded,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	tw.next++
+	return nil
+}
+
+// WriteTimeline writes the [Timeline] of execCtx to w:
+//
+//	var buf bytes.Buffer
+//	if err := events.WriteTimeline(&buf, execCtx); err != nil {
+//	    return err
+//	}
+//	store.Put(runID, buf.Bytes())
+func WriteTimeline(w io.Writer, execCtx *gent.ExecutionContext) error {
+	tw := NewTimelineWriter(w)
+	for _, record := range Timeline(execCtx) {
+		if err := tw.Write(record.ContextID, record.Event); err != nil {
+			return fmt.Errorf("timeline record %d: %w", record.Index, err)
+		}
+	}
+	return nil
+}
+
+// TimelineReader reads records written by [TimelineWriter], in order.
+type TimelineReader struct {
+	scanner *bufio.Scanner
+	next    int
+}
+
+// NewTimelineReader returns a TimelineReader reading from r.
+func NewTimelineReader(r io.Reader) *TimelineReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	return &TimelineReader{scanner: scanner}
+}
+
+// Next returns the next record, or io.EOF when there are no more. Events are decoded
+// with [Decode]. Returns an error if a record's index is out of order.
+func (tr *TimelineReader) Next() (TimelineRecord, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !tr.scanner.Scan() {
+			if err := tr.scanner.Err(); err != nil {
+				return TimelineRecord{}, err
+			}
+			return TimelineRecord{}, io.EOF
+		}
+		line = []byte(strings.TrimSpace(tr.scanner.Text()))
+	}
+
+	var wire timelineRecordJSON
+	if err := json.Unmarshal(line, &wire); err != nil {
+		return TimelineRecord{}, fmt.Errorf("timeline record %d: %w", tr.next, err)
+	}
+	if wire.Index != tr.next {
+		return TimelineRecord{}, fmt.Errorf("timeline record %d: unexpected index %d",
+			tr.next, wire.Index)
+	}
+	event, err := Decode(wire.Event)
+	if err != nil {
+		return TimelineRecord{}, fmt.Errorf("timeline record %d: %w", tr.next, err)
+	}
+	tr.next++
+	return TimelineRecord{Index: wire.Index, ContextID: wire.ContextID, Event: event}, nil
+}
+
+// ReadTimeline reads all records from r.
+func ReadTimeline(r io.Reader) ([]TimelineRecord, error) {
+	tr := NewTimelineReader(r)
+	var records []TimelineRecord
+	for {
+		record, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// StatsAt returns the cumulative stats of every context after the records up to and
+// including index, keyed by context ID. Use it to show a run's state at any point of
+// its timeline:
+//
+//	stats := events.StatsAt(records, 42)
+//	root := stats[records[0].ContextID]
+//	fmt.Println(root.GetTotalTokens(), root.GetToolCallCount())
+//
+// The records are replayed into fresh ExecutionContexts without limits, so counters
+// propagate to ancestors as they did in the run. Only stats derived from events are
+// reconstructed; stats set directly by agents or the executor (e.g., SGScratchpadBytes)
+// are not. The records are not modified.
+//
+// Output tokens a StreamTokenMeter counted while a response streamed have no event of
+// their own: the replay counts the AfterModelCallEvent's reported output tokens in full
+// instead. Stats at a record within a stream therefore lack its streamed tokens, and a
+// stream whose estimate exceeded the reported usage counts the reported usage.
+func StatsAt(records []TimelineRecord, index int) map[string]*gent.ExecutionStats {
+	contexts := make(map[string]*gent.ExecutionContext)
+	for _, record := range records {
+		if record.Index > index {
+			break
+		}
+		event := copyEvent(record.Event)
+		// Streamed output tokens were counted without an event: count them here
+		if afterModelCall, ok := event.(*gent.AfterModelCallEvent); ok {
+			afterModelCall.StreamedOutputTokens = 0
+		}
+		replayContext(contexts, record.ContextID).Publish(event)
+	}
+
+	stats := make(map[string]*gent.ExecutionStats, len(contexts))
+	for id, execCtx := range contexts {
+		stats[id] = execCtx.Stats()
+	}
+	return stats
+}
+
+// replayContext returns the replay context for id, creating it and its ancestors.
+func replayContext(contexts map[string]*gent.ExecutionContext, id string) *gent.ExecutionContext {
+	if execCtx, ok := contexts[id]; ok {
+		return execCtx
+	}
+
+	var execCtx *gent.ExecutionContext
+	slash := strings.LastIndex(id, "/")
+	if slash < 0 {
+		execCtx = gent.NewExecutionContext(context.Background(), id, nil)
+		execCtx.SetLimits(nil)
+	} else {
+		name, _, _ := strings.Cut(id[slash+1:], "#")
+		execCtx = replayContext(contexts, id[:slash]).SpawnChild(name, nil)
+	}
+	contexts[id] = execCtx
+	return execCtx
+}
+
+// copyEvent returns a shallow copy of event, so publishing it doesn't overwrite the
+// original's BaseEvent.
+func copyEvent(event gent.Event) gent.Event {
+	val := reflect.ValueOf(event)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return event
+	}
+	copied := reflect.New(val.Elem().Type())
+	copied.Elem().Set(val.Elem())
+	return copied.Interface().(gent.Event)
+}
+
+// timestamp returns the timestamp of event, or the zero time if it has no BaseEvent.
+func timestamp(event gent.Event) time.Time {
+	val := reflect.Indirect(reflect.ValueOf(event))
+	if val.Kind() != reflect.Struct {
+		return time.Time{}
+	}
+	base := val.FieldByName("BaseEvent")
+	if !base.IsValid() || base.Type() != baseEventType {
+		return time.Time{}
+	}
+	return base.Interface().(gent.BaseEvent).Timestamp
+}
@@ -0,0 +1,377 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timelineEntry is the context ID and event name of a timeline record.
+type timelineEntry struct {
+	contextID string
+	eventName string
+}
+
+// timelineEntries returns the context ID and event name of each record.
+func timelineEntries(records []TimelineRecord) []timelineEntry {
+	entries := make([]timelineEntry, len(records))
+	for i, record := range records {
+		entries[i] = timelineEntry{record.ContextID, eventName(record.Event)}
+	}
+	return entries
+}
+
+// newTimelineContext returns a root context named "main" on a fake clock.
+func newTimelineContext() (*gent.ExecutionContext, *clocktest.FakeClock) {
+	clock := clocktest.NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	execCtx := gent.NewExecutionContext(context.Background(), "main", nil)
+	execCtx.SetLimits(nil)
+	execCtx.SetClock(clock)
+	return execCtx, clock
+}
+
+// runSearchChild runs a child that calls the search tool once.
+func runSearchChild(execCtx *gent.ExecutionContext, clock *clocktest.FakeClock) {
+	child := execCtx.SpawnChild("research", nil)
+	clock.Advance(time.Second)
+	child.PublishBeforeIteration()
+	child.PublishBeforeToolCall("search", map[string]any{"query": "weather"})
+	clock.Advance(time.Second)
+	child.PublishAfterToolCall("search", map[string]any{"query": "weather"}, "sunny",
+		time.Second, nil)
+	execCtx.CompleteChild(child)
+}
+
+// buildTimelineRun records a run whose root iterates twice, running a research child in
+// each iteration, and whose second child fails its tool call.
+func buildTimelineRun() *gent.ExecutionContext {
+	execCtx, clock := newTimelineContext()
+	execCtx.PublishBeforeExecution()
+	execCtx.PublishBeforeIteration()
+	runSearchChild(execCtx, clock)
+	execCtx.PublishBeforeIteration()
+
+	child := execCtx.SpawnChild("research", nil)
+	clock.Advance(time.Second)
+	child.PublishBeforeIteration()
+	child.PublishBeforeToolCall("search", map[string]any{"query": "rain"})
+	child.PublishAfterToolCall("search", map[string]any{"query": "rain"}, nil,
+		time.Second, errors.New("timeout"))
+	execCtx.CompleteChild(child)
+
+	execCtx.PublishAfterExecution(gent.TerminationSuccess, nil)
+	return execCtx
+}
+
+func TestTimeline_Order(t *testing.T) {
+	type input struct {
+		run func(execCtx *gent.ExecutionContext, clock *clocktest.FakeClock)
+	}
+
+	type expected struct {
+		entries []timelineEntry
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "sequential children with the same name",
+			input: input{run: func(execCtx *gent.ExecutionContext, clock *clocktest.FakeClock) {
+				execCtx.PublishBeforeIteration()
+				runSearchChild(execCtx, clock)
+				runSearchChild(execCtx, clock)
+				execCtx.PublishAfterExecution(gent.TerminationSuccess, nil)
+			}},
+			expected: expected{entries: []timelineEntry{
+				{"main", gent.EventNameIterationBefore},
+				{"main", gent.EventNameChildSpawn},
+				{"main/research", gent.EventNameIterationBefore},
+				{"main/research", gent.EventNameToolCallBefore},
+				{"main/research", gent.EventNameToolCallAfter},
+				{"main", gent.EventNameChildComplete},
+				{"main", gent.EventNameChildSpawn},
+				{"main/research#2", gent.EventNameIterationBefore},
+				{"main/research#2", gent.EventNameToolCallBefore},
+				{"main/research#2", gent.EventNameToolCallAfter},
+				{"main", gent.EventNameChildComplete},
+				{"main", gent.EventNameExecutionAfter},
+			}},
+		},
+		{
+			name: "parent events interleave with a concurrent child by timestamp",
+			input: input{run: func(execCtx *gent.ExecutionContext, clock *clocktest.FakeClock) {
+				child := execCtx.SpawnChild("compaction", nil)
+				clock.Advance(time.Second)
+				child.PublishBeforeModelCall("summarizer", nil)
+				clock.Advance(time.Second)
+				execCtx.PublishBeforeIteration()
+				clock.Advance(time.Second)
+				child.PublishCommonEvent("app:summarized", "Summarized", nil)
+				execCtx.CompleteChild(child)
+			}},
+			expected: expected{entries: []timelineEntry{
+				{"main", gent.EventNameChildSpawn},
+				{"main/compaction", gent.EventNameModelCallBefore},
+				{"main", gent.EventNameIterationBefore},
+				{"main/compaction", "app:summarized"},
+				{"main", gent.EventNameChildComplete},
+			}},
+		},
+		{
+			name: "nested children",
+			input: input{run: func(execCtx *gent.ExecutionContext, clock *clocktest.FakeClock) {
+				child := execCtx.SpawnChild("planner", nil)
+				clock.Advance(time.Second)
+				child.PublishBeforeIteration()
+				runSearchChild(child, clock)
+				execCtx.CompleteChild(child)
+			}},
+			expected: expected{entries: []timelineEntry{
+				{"main", gent.EventNameChildSpawn},
+				{"main/planner", gent.EventNameIterationBefore},
+				{"main/planner", gent.EventNameChildSpawn},
+				{"main/planner/research", gent.EventNameIterationBefore},
+				{"main/planner/research", gent.EventNameToolCallBefore},
+				{"main/planner/research", gent.EventNameToolCallAfter},
+				{"main/planner", gent.EventNameChildComplete},
+				{"main", gent.EventNameChildComplete},
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx, clock := newTimelineContext()
+			tc.input.run(execCtx, clock)
+
+			records := Timeline(execCtx)
+
+			assert.Equal(t, tc.expected.entries, timelineEntries(records))
+			for i, record := range records {
+				assert.Equal(t, i, record.Index)
+			}
+		})
+	}
+}
+
+func TestTimeline_RoundTrip(t *testing.T) {
+	execCtx := buildTimelineRun()
+	records := Timeline(execCtx)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTimeline(&buf, execCtx))
+	written := buf.String()
+	assert.Len(t, strings.Split(strings.TrimSuffix(written, "\n"), "\n"), len(records))
+	assert.True(t, strings.HasPrefix(written,
+		`{"index":0,"context_id":"main","event":{"event_type":"before_execution",`+
+			`"event_name":"gent:execution:before","timestamp":"2025-01-01T09:00:00Z",`))
+
+	read, err := ReadTimeline(strings.NewReader(written))
+	require.NoError(t, err)
+
+	assert.Equal(t, timelineEntries(records), timelineEntries(read))
+	for i, record := range read {
+		assert.Equal(t, i, record.Index)
+		assert.Equal(t, timestamp(records[i].Event), timestamp(record.Event))
+	}
+	afterToolCall, ok := read[11].Event.(*gent.AfterToolCallEvent)
+	require.True(t, ok)
+	assert.Equal(t, "main/research#2", read[11].ContextID)
+	assert.Equal(t, map[string]any{"query": "rain"}, afterToolCall.Args)
+	assert.EqualError(t, afterToolCall.Error, "timeout")
+
+	// Writing the read records again reproduces the log byte for byte
+	var rewritten bytes.Buffer
+	writer := NewTimelineWriter(&rewritten)
+	for _, record := range read {
+		require.NoError(t, writer.Write(record.ContextID, record.Event))
+	}
+	assert.Equal(t, written, rewritten.String())
+}
+
+func TestTimelineReader_Errors(t *testing.T) {
+	type input struct {
+		log string
+	}
+
+	type expected struct {
+		records int
+		err     string
+	}
+
+	beforeIteration := `"event":{"event_type":"before_iteration",` +
+		`"event_name":"gent:iteration:before"}}`
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "blank lines are skipped",
+			input: input{log: `{"index":0,"context_id":"main",` + beforeIteration + "\n\n" +
+				`{"index":1,"context_id":"main",` + beforeIteration + "\n"},
+			expected: expected{records: 2},
+		},
+		{
+			name: "index out of order",
+			input: input{log: `{"index":0,"context_id":"main",` + beforeIteration + "\n" +
+				`{"index":2,"context_id":"main",` + beforeIteration + "\n"},
+			expected: expected{err: "timeline record 1: unexpected index 2"},
+		},
+		{
+			name:     "unknown event type",
+			input:    input{log: `{"index":0,"context_id":"main","event":{"event_type":"x"}}`},
+			expected: expected{err: `timeline record 0: unknown event type: "x"`},
+		},
+		{
+			name:     "malformed line",
+			input:    input{log: `{"index":0,`},
+			expected: expected{err: "timeline record 0: unexpected end of JSON input"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := ReadTimeline(strings.NewReader(tc.input.log))
+
+			if tc.expected.err != "" {
+				assert.EqualError(t, err, tc.expected.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, records, tc.expected.records)
+		})
+	}
+}
+
+func TestStatsAt(t *testing.T) {
+	type input struct {
+		index int
+	}
+
+	type stats struct {
+		iterations   int64
+		toolCalls    int64
+		toolErrors   int64
+		searchCalls  int64
+		distinctTool float64
+	}
+
+	type expected struct {
+		stats map[string]stats
+	}
+
+	// Timeline of buildTimelineRun:
+	//
+	//	0 main before_execution           7  main before_iteration
+	//	1 main before_iteration           8  main child_spawn
+	//	2 main child_spawn                9  main/research#2 before_iteration
+	//	3 main/research before_iteration  10 main/research#2 before_tool_call
+	//	4 main/research before_tool_call  11 main/research#2 after_tool_call (error)
+	//	5 main/research after_tool_call   12 main child_complete
+	//	6 main child_complete             13 main after_execution
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "before any child",
+			input: input{index: 1},
+			expected: expected{stats: map[string]stats{
+				"main": {iterations: 1},
+			}},
+		},
+		{
+			name:  "first child's tool call",
+			input: input{index: 4},
+			expected: expected{stats: map[string]stats{
+				"main":          {iterations: 2, toolCalls: 1, searchCalls: 1, distinctTool: 1},
+				"main/research": {iterations: 1, toolCalls: 1, searchCalls: 1, distinctTool: 1},
+			}},
+		},
+		{
+			name:  "end of run",
+			input: input{index: 100},
+			expected: expected{stats: map[string]stats{
+				"main": {
+					iterations:   4,
+					toolCalls:    2,
+					toolErrors:   1,
+					searchCalls:  2,
+					distinctTool: 1,
+				},
+				"main/research": {iterations: 1, toolCalls: 1, searchCalls: 1, distinctTool: 1},
+				"main/research#2": {
+					iterations:   1,
+					toolCalls:    1,
+					toolErrors:   1,
+					searchCalls:  1,
+					distinctTool: 1,
+				},
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			records := Timeline(buildTimelineRun())
+			before := timelineEntries(records)
+
+			result := StatsAt(records, tc.input.index)
+
+			actual := make(map[string]stats, len(result))
+			for id, s := range result {
+				actual[id] = stats{
+					iterations:   s.GetCounter(gent.SCIterations),
+					toolCalls:    s.GetCounter(gent.SCToolCalls),
+					toolErrors:   s.GetCounter(gent.SCToolCallsErrorTotal),
+					searchCalls:  s.GetCounter(gent.SCToolCallsFor + "search"),
+					distinctTool: s.GetGauge(gent.SGDistinctToolsUsed),
+				}
+			}
+			assert.Equal(t, tc.expected.stats, actual)
+			assert.Equal(t, before, timelineEntries(records), "records must not change")
+		})
+	}
+}
+
+func TestStatsAt_StreamedModelCall(t *testing.T) {
+	execCtx, _ := newTimelineContext()
+	execCtx.PublishBeforeExecution()
+	meter := execCtx.NewStreamTokenMeter("model")
+	for range 8 {
+		require.NoError(t, meter.Add("abcd"))
+	}
+	meter.PublishAfterModelCall("request", &gent.ContentResponse{
+		Choices: []*gent.ContentChoice{{Content: strings.Repeat("abcd", 8)}},
+		Info:    &gent.GenerationInfo{InputTokens: 10, OutputTokens: 20},
+	}, time.Second, nil)
+	execCtx.PublishAfterExecution(gent.TerminationSuccess, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTimeline(&buf, execCtx))
+	records, err := ReadTimeline(&buf)
+	require.NoError(t, err)
+
+	live := execCtx.Stats()
+	replayed := StatsAt(records, len(records)-1)["main"]
+	require.NotNil(t, replayed)
+	assert.Equal(t, int64(20), live.GetCounter(gent.SCOutputTokens))
+	assert.Equal(t, live.GetCounter(gent.SCOutputTokens), replayed.GetCounter(gent.SCOutputTokens))
+	assert.Equal(t, live.GetCounter(gent.SCTotalTokens), replayed.GetCounter(gent.SCTotalTokens))
+	assert.Equal(t, live.GetCounter(gent.SCOutputTokensFor+"model"),
+		replayed.GetCounter(gent.SCOutputTokensFor+"model"))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -673,6 +674,154 @@ func TestPublish_PanicsOnRecursionLimitExceeded(t *testing.T) {
 	}
 }
 
+// progressRegistry implements EventPublisher, records ToolProgressEvents and reports
+// progress from inside BeforeIterationEvent dispatch.
+type progressRegistry struct {
+	progress     []*ToolProgressEvent
+	maxRecursion int
+}
+
+func (r *progressRegistry) Dispatch(execCtx *ExecutionContext, event Event) {
+	switch e := event.(type) {
+	case *BeforeIterationEvent:
+		ctx := ContextWithToolName(execCtx.Context(), "import")
+		ReportToolProgress(ctx, 50, "nested")
+	case *ToolProgressEvent:
+		r.progress = append(r.progress, e)
+	}
+}
+
+func (r *progressRegistry) MaxRecursion() int {
+	return r.maxRecursion
+}
+
+func TestReportToolProgress(t *testing.T) {
+	type input struct {
+		ctx     func(execCtx *ExecutionContext) context.Context
+		percent float64
+		message string
+	}
+
+	type expected struct {
+		reported bool
+		toolName string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "tool call context",
+			input: input{
+				ctx: func(execCtx *ExecutionContext) context.Context {
+					return ContextWithToolName(execCtx.Context(), "import")
+				},
+				percent: 40,
+				message: "Imported 4 of 10 batches",
+			},
+			expected: expected{reported: true, toolName: "import"},
+		},
+		{
+			name: "execution context without tool name",
+			input: input{
+				ctx: func(execCtx *ExecutionContext) context.Context {
+					return execCtx.Context()
+				},
+				percent: -1,
+				message: "Working",
+			},
+			expected: expected{reported: true, toolName: ""},
+		},
+		{
+			name: "context outside an execution",
+			input: input{
+				ctx: func(_ *ExecutionContext) context.Context {
+					return ContextWithToolName(context.Background(), "import")
+				},
+				percent: 40,
+			},
+			expected: expected{reported: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &progressRegistry{maxRecursion: 10}
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetEventPublisher(registry)
+
+			reported := ReportToolProgress(
+				tt.input.ctx(execCtx), tt.input.percent, tt.input.message)
+
+			assert.Equal(t, tt.expected.reported, reported)
+			if !tt.expected.reported {
+				assert.Empty(t, registry.progress)
+				return
+			}
+			require.Len(t, registry.progress, 1)
+			event := registry.progress[0]
+			assert.Equal(t, EventNameToolCallProgress, event.EventName)
+			assert.Equal(t, tt.expected.toolName, event.ToolName)
+			assert.Equal(t, tt.input.percent, event.Percent)
+			assert.Equal(t, tt.input.message, event.Message)
+			assert.Empty(t, execCtx.Stats().Counters(),
+				"progress events should not update stats")
+		})
+	}
+}
+
+func TestReportToolProgress_RecursionLimit(t *testing.T) {
+	type input struct {
+		maxRecursion int
+	}
+
+	type expected struct {
+		panics   bool
+		progress int
+	}
+
+	// The progress event is reported while BeforeIterationEvent is dispatched, one
+	// level deeper
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "nested progress within the limit",
+			input:    input{maxRecursion: 2},
+			expected: expected{progress: 1},
+		},
+		{
+			name:     "nested progress over the limit",
+			input:    input{maxRecursion: 1},
+			expected: expected{panics: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &progressRegistry{maxRecursion: tt.input.maxRecursion}
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetEventPublisher(registry)
+
+			if tt.expected.panics {
+				assert.PanicsWithValue(t, "event recursion depth exceeded maximum (1)",
+					func() { execCtx.PublishBeforeIteration() })
+			} else {
+				assert.NotPanics(t, func() { execCtx.PublishBeforeIteration() })
+			}
+			assert.Len(t, registry.progress, tt.expected.progress)
+			assert.Equal(t, 0, execCtx.Iteration(), "progress is not an iteration")
+
+			// The depth unwinds, so a top-level progress event is always allowed
+			assert.True(t, ReportToolProgress(execCtx.Context(), 100, "done"))
+		})
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Concurrency Tests
 // -----------------------------------------------------------------------------
@@ -1114,6 +1263,257 @@ func TestLimitExceeded_OnlyPublishedOnce(t *testing.T) {
 	assert.Equal(t, 1, count, "LimitExceededEvent should only be published once")
 }
 
+func TestLimitExceeded_OnExceededCallback(t *testing.T) {
+	const gaugeKey = StatKey("test:gauge")
+	const counterKey = StatKey("test:counter")
+
+	type update struct {
+		key   StatKey
+		value float64 // gauges are set, counters incremented
+	}
+
+	type input struct {
+		action  LimitAction
+		updates []update
+	}
+
+	type expected struct {
+		callbacks     int
+		limitEvents   int
+		exceededLimit StatKey // empty when no limit terminated execution
+		canceled      bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "continue keeps execution running and fires once while exceeded",
+			input: input{
+				action:  LimitActionContinue,
+				updates: []update{{gaugeKey, 4}, {gaugeKey, 5}, {gaugeKey, 6}},
+			},
+			expected: expected{callbacks: 1, limitEvents: 1},
+		},
+		{
+			name: "callback re-arms after the gauge drops back within the limit",
+			input: input{
+				action:  LimitActionContinue,
+				updates: []update{{gaugeKey, 4}, {gaugeKey, 0}, {gaugeKey, 4}},
+			},
+			expected: expected{callbacks: 2, limitEvents: 2},
+		},
+		{
+			name: "terminate cancels like a declarative limit",
+			input: input{
+				action:  LimitActionTerminate,
+				updates: []update{{gaugeKey, 4}, {gaugeKey, 5}},
+			},
+			expected: expected{
+				callbacks:     1,
+				limitEvents:   1,
+				exceededLimit: gaugeKey,
+				canceled:      true,
+			},
+		},
+		{
+			name: "declarative limits still apply after a callback continued",
+			input: input{
+				action:  LimitActionContinue,
+				updates: []update{{gaugeKey, 4}, {counterKey, 3}},
+			},
+			expected: expected{
+				callbacks:     1,
+				limitEvents:   2,
+				exceededLimit: counterKey,
+				canceled:      true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callbacks := 0
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetLimits([]Limit{
+				{
+					Type:     LimitExactKey,
+					Key:      gaugeKey,
+					MaxValue: 3,
+					OnExceeded: func(cbCtx *ExecutionContext) LimitAction {
+						assert.Same(t, execCtx, cbCtx)
+						callbacks++
+						return tt.input.action
+					},
+				},
+				{Type: LimitExactKey, Key: counterKey, MaxValue: 2},
+			})
+
+			for _, u := range tt.input.updates {
+				if u.key == gaugeKey {
+					execCtx.Stats().SetGauge(u.key, u.value)
+				} else {
+					execCtx.Stats().IncrCounter(u.key, int64(u.value))
+				}
+			}
+
+			var limitEvents int
+			for _, event := range execCtx.Events() {
+				if _, ok := event.(*LimitExceededEvent); ok {
+					limitEvents++
+				}
+			}
+
+			assert.Equal(t, tt.expected.callbacks, callbacks)
+			assert.Equal(t, tt.expected.limitEvents, limitEvents)
+			if tt.expected.exceededLimit != "" {
+				if assert.NotNil(t, execCtx.ExceededLimit()) {
+					assert.Equal(t, tt.expected.exceededLimit, execCtx.ExceededLimit().Key)
+				}
+			} else {
+				assert.Nil(t, execCtx.ExceededLimit())
+			}
+			assert.Equal(t, tt.expected.canceled, execCtx.Context().Err() != nil)
+		})
+	}
+}
+
+func TestLimitExceeded_PerValidatorRejectionLimits(t *testing.T) {
+	schemaLimit := Limit{
+		Type:     LimitExactKey,
+		Key:      SCAnswerRejectedBy + "schema_validator",
+		MaxValue: 5,
+	}
+	toxicityLimit := Limit{
+		Type:     LimitExactKey,
+		Key:      SCAnswerRejectedBy + "toxicity_validator",
+		MaxValue: 2,
+	}
+	anyValidatorLimit := Limit{Type: LimitKeyPrefix, Key: SCAnswerRejectedBy, MaxValue: 3}
+
+	type input struct {
+		limits     []Limit
+		rejections []string // validator names, in rejection order
+	}
+
+	type expected struct {
+		exceededLimit *Limit
+		matchedKey    StatKey
+		currentValue  float64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "each validator within its own budget",
+			input: input{
+				limits: []Limit{schemaLimit, toxicityLimit},
+				rejections: []string{
+					"schema_validator", "toxicity_validator", "schema_validator",
+					"schema_validator", "toxicity_validator", "schema_validator",
+					"schema_validator",
+				},
+			},
+			expected: expected{},
+		},
+		{
+			name: "toxicity budget exhausted while schema still under budget",
+			input: input{
+				limits: []Limit{schemaLimit, toxicityLimit},
+				rejections: []string{
+					"schema_validator", "schema_validator", "toxicity_validator",
+					"schema_validator", "toxicity_validator", "toxicity_validator",
+				},
+			},
+			expected: expected{
+				exceededLimit: &toxicityLimit,
+				matchedKey:    SCAnswerRejectedBy + "toxicity_validator",
+				currentValue:  3,
+			},
+		},
+		{
+			name: "schema budget exhausted",
+			input: input{
+				limits: []Limit{schemaLimit, toxicityLimit},
+				rejections: []string{
+					"schema_validator", "schema_validator", "schema_validator",
+					"schema_validator", "schema_validator", "schema_validator",
+				},
+			},
+			expected: expected{
+				exceededLimit: &schemaLimit,
+				matchedKey:    SCAnswerRejectedBy + "schema_validator",
+				currentValue:  6,
+			},
+		},
+		{
+			name: "exact limit does not relax a matching prefix limit",
+			input: input{
+				limits: []Limit{schemaLimit, anyValidatorLimit},
+				rejections: []string{
+					"schema_validator", "schema_validator", "schema_validator",
+					"schema_validator",
+				},
+			},
+			expected: expected{
+				exceededLimit: &anyValidatorLimit,
+				matchedKey:    SCAnswerRejectedBy + "schema_validator",
+				currentValue:  4,
+			},
+		},
+		{
+			name: "first exceeded limit in slice order is reported",
+			input: input{
+				limits: []Limit{
+					{Type: LimitExactKey, Key: toxicityLimit.Key, MaxValue: 1},
+					{Type: LimitKeyPrefix, Key: SCAnswerRejectedBy, MaxValue: 1},
+				},
+				rejections: []string{"toxicity_validator", "toxicity_validator"},
+			},
+			expected: expected{
+				exceededLimit: &Limit{Type: LimitExactKey, Key: toxicityLimit.Key, MaxValue: 1},
+				matchedKey:    SCAnswerRejectedBy + "toxicity_validator",
+				currentValue:  2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetLimits(tt.input.limits)
+
+			for _, name := range tt.input.rejections {
+				execCtx.PublishValidatorResult(name, "answer", false, nil)
+			}
+
+			var limitEvent *LimitExceededEvent
+			for _, event := range execCtx.Events() {
+				if e, ok := event.(*LimitExceededEvent); ok {
+					limitEvent = e
+					break
+				}
+			}
+
+			if tt.expected.exceededLimit == nil {
+				assert.Nil(t, limitEvent, "LimitExceededEvent should not be published")
+				assert.Nil(t, execCtx.ExceededLimit())
+				return
+			}
+			assert.NotNil(t, limitEvent, "LimitExceededEvent should be published")
+			assert.Equal(t, *tt.expected.exceededLimit, limitEvent.Limit)
+			assert.Equal(t, tt.expected.matchedKey, limitEvent.MatchedKey)
+			assert.Equal(t, tt.expected.currentValue, limitEvent.CurrentValue)
+			assert.Equal(t, tt.expected.exceededLimit, execCtx.ExceededLimit())
+		})
+	}
+}
+
 func TestLimitExceeded_EventContainsCorrectTimestamp(t *testing.T) {
 	execCtx := NewExecutionContext(context.Background(), "test", nil)
 	execCtx.SetLimits([]Limit{
@@ -1854,3 +2254,13 @@ func TestDefaultLimits_UsesSelfIterations(t *testing.T) {
 	assert.True(t, found,
 		"DefaultLimits should have SCIterations.Self()")
 }
+
+func TestDefaultLimits_EmptyResponses(t *testing.T) {
+	limits := make(map[StatKey]float64)
+	for _, l := range DefaultLimits() {
+		limits[l.Key] = l.MaxValue
+	}
+
+	assert.Equal(t, float64(3), limits[SGEmptyResponseConsecutive])
+	assert.Equal(t, float64(10), limits[SCEmptyResponseTotal])
+}
@@ -0,0 +1,122 @@
+package gent
+
+import "strings"
+
+// ExecutionSummary is a snapshot of an execution's stats taken when it ends, carried by
+// [AfterExecutionEvent].Summary. Counters propagate from child contexts, so every count
+// includes the execution's descendants. Per-name maps only hold names that were used.
+// A background compaction (executor.Config.AsyncCompaction) runs after the summary is
+// taken and is not included: its tokens are reported by
+// [EventNameCompactionAsyncFinish].
+//
+// Use it to bill or report a run from a single event:
+//
+//	func (b *Billing) OnAfterExecution(
+//	    execCtx *gent.ExecutionContext,
+//	    event *gent.AfterExecutionEvent,
+//	) {
+//	    for model, tokens := range event.Summary.InputTokensByModel {
+//	        b.charge(model, tokens, event.Summary.OutputTokensByModel[model])
+//	    }
+//	}
+type ExecutionSummary struct {
+	// TerminationReason is how the execution ended.
+	TerminationReason TerminationReason `json:"termination_reason"`
+
+	// Iterations is [SCIterations].
+	Iterations int64 `json:"iterations"`
+
+	// InputTokens, OutputTokens and TotalTokens are [SCInputTokens], [SCOutputTokens]
+	// and [SCTotalTokens].
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+
+	// InputTokensByModel and OutputTokensByModel are [SCInputTokensFor] and
+	// [SCOutputTokensFor], keyed by model name.
+	InputTokensByModel  map[string]int64 `json:"input_tokens_by_model"`
+	OutputTokensByModel map[string]int64 `json:"output_tokens_by_model"`
+
+	// ToolCalls is [SCToolCalls], and ToolCallsByName [SCToolCallsFor] keyed by tool
+	// name.
+	ToolCalls       int64            `json:"tool_calls"`
+	ToolCallsByName map[string]int64 `json:"tool_calls_by_name"`
+
+	// ToolCallErrorsByName is [SCToolCallsErrorFor], keyed by tool name.
+	ToolCallErrorsByName map[string]int64 `json:"tool_call_errors_by_name"`
+
+	// Errors holds the error counters that are not zero, keyed by stat key:
+	// [SCToolCallsErrorTotal], [SCFormatParseErrorTotal], [SCToolchainParseErrorTotal],
+	// [SCTerminationParseErrorTotal], [SCSectionParseErrorTotal], [SCEmptyResponseTotal]
+	// and [SCCodeExecutionsError].
+	Errors map[StatKey]int64 `json:"errors"`
+
+	// AnswersRejected is [SCAnswerRejectedTotal], and RejectionsByValidator
+	// [SCAnswerRejectedBy] keyed by validator name.
+	AnswersRejected       int64            `json:"answers_rejected"`
+	RejectionsByValidator map[string]int64 `json:"rejections_by_validator"`
+
+	// Counters holds every counter, including user-defined ones, without the
+	// $self:-prefixed local-only keys.
+	Counters map[StatKey]int64 `json:"counters"`
+}
+
+// summaryErrorKeys are the error counters reported in ExecutionSummary.Errors.
+var summaryErrorKeys = []StatKey{
+	SCToolCallsErrorTotal,
+	SCFormatParseErrorTotal,
+	SCToolchainParseErrorTotal,
+	SCTerminationParseErrorTotal,
+	SCSectionParseErrorTotal,
+	SCEmptyResponseTotal,
+	SCCodeExecutionsError,
+}
+
+// Summary returns a snapshot of the execution's stats as an [ExecutionSummary], with the
+// current termination reason. The executor publishes one with AfterExecutionEvent.
+func (ctx *ExecutionContext) Summary() *ExecutionSummary {
+	return newExecutionSummary(ctx.TerminationReason(), ctx.Stats().Counters())
+}
+
+// newExecutionSummary builds an ExecutionSummary from a copy of the counters.
+func newExecutionSummary(reason TerminationReason, counters map[string]int64) *ExecutionSummary {
+	summary := &ExecutionSummary{
+		TerminationReason:     reason,
+		Iterations:            counters[string(SCIterations)],
+		InputTokens:           counters[string(SCInputTokens)],
+		OutputTokens:          counters[string(SCOutputTokens)],
+		TotalTokens:           counters[string(SCTotalTokens)],
+		InputTokensByModel:    counterSuffixes(counters, SCInputTokensFor),
+		OutputTokensByModel:   counterSuffixes(counters, SCOutputTokensFor),
+		ToolCalls:             counters[string(SCToolCalls)],
+		ToolCallsByName:       counterSuffixes(counters, SCToolCallsFor),
+		ToolCallErrorsByName:  counterSuffixes(counters, SCToolCallsErrorFor),
+		Errors:                make(map[StatKey]int64),
+		AnswersRejected:       counters[string(SCAnswerRejectedTotal)],
+		RejectionsByValidator: counterSuffixes(counters, SCAnswerRejectedBy),
+		Counters:              make(map[StatKey]int64, len(counters)),
+	}
+	for _, key := range summaryErrorKeys {
+		if value := counters[string(key)]; value != 0 {
+			summary.Errors[key] = value
+		}
+	}
+	for key, value := range counters {
+		if !StatKey(key).IsSelf() {
+			summary.Counters[StatKey(key)] = value
+		}
+	}
+	return summary
+}
+
+// counterSuffixes returns the counters whose key starts with prefix, keyed by the rest of
+// the key (e.g., the model name of SCInputTokensFor + model).
+func counterSuffixes(counters map[string]int64, prefix StatKey) map[string]int64 {
+	result := make(map[string]int64)
+	for key, value := range counters {
+		if name, ok := strings.CutPrefix(key, string(prefix)); ok && name != "" {
+			result[name] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryParseErrorKeys maps parse error types to their error counter.
+var summaryParseErrorKeys = map[ParseErrorType]StatKey{
+	ParseErrorTypeFormat:      SCFormatParseErrorTotal,
+	ParseErrorTypeToolchain:   SCToolchainParseErrorTotal,
+	ParseErrorTypeTermination: SCTerminationParseErrorTotal,
+	ParseErrorTypeSection:     SCSectionParseErrorTotal,
+}
+
+// summaryFromEvents adds up the events of execCtx and its descendants into the summary
+// they should produce, leaving Counters unset.
+func summaryFromEvents(execCtx *ExecutionContext, summary *ExecutionSummary) {
+	for _, event := range execCtx.Events() {
+		switch e := event.(type) {
+		case *BeforeIterationEvent:
+			summary.Iterations++
+		case *AfterModelCallEvent:
+			summary.InputTokens += int64(e.InputTokens)
+			summary.OutputTokens += int64(e.OutputTokens)
+			summary.TotalTokens += int64(e.InputTokens + e.OutputTokens)
+			summary.InputTokensByModel[e.Model] += int64(e.InputTokens)
+			summary.OutputTokensByModel[e.Model] += int64(e.OutputTokens)
+		case *BeforeToolCallEvent:
+			summary.ToolCalls++
+			summary.ToolCallsByName[e.ToolName]++
+		case *AfterToolCallEvent:
+			if e.Error != nil {
+				summary.ToolCallErrorsByName[e.ToolName]++
+				summary.Errors[SCToolCallsErrorTotal]++
+			}
+		case *ParseErrorEvent:
+			summary.Errors[summaryParseErrorKeys[e.ErrorType]]++
+		case *ValidatorResultEvent:
+			if !e.Accepted {
+				summary.AnswersRejected++
+				summary.RejectionsByValidator[e.ValidatorName]++
+			}
+		}
+	}
+	for _, child := range execCtx.Children() {
+		summaryFromEvents(child, summary)
+	}
+}
+
+// publishModelCall publishes a model call using the given tokens.
+func publishModelCall(execCtx *ExecutionContext, model string, input, output int) {
+	execCtx.PublishAfterModelCall(model, nil, &ContentResponse{
+		Info: &GenerationInfo{InputTokens: input, OutputTokens: output},
+	}, 0, nil)
+}
+
+// publishToolCall publishes a tool call that fails with err, if not nil.
+func publishToolCall(execCtx *ExecutionContext, tool string, err error) {
+	execCtx.PublishBeforeToolCall(tool, nil)
+	execCtx.PublishAfterToolCall(tool, nil, "ok", 0, err)
+}
+
+func TestExecutionSummary(t *testing.T) {
+	type input struct {
+		run    func(execCtx *ExecutionContext)
+		reason TerminationReason
+	}
+
+	type expected struct {
+		iterations  int64
+		totalTokens int64
+		toolCalls   int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "root context only",
+			input: input{
+				run: func(execCtx *ExecutionContext) {
+					execCtx.PublishBeforeIteration()
+					publishModelCall(execCtx, "gpt-4", 1000, 200)
+					publishToolCall(execCtx, "search", nil)
+					publishToolCall(execCtx, "search", errors.New("timeout"))
+					execCtx.PublishBeforeIteration()
+					publishModelCall(execCtx, "gpt-4", 1500, 100)
+					execCtx.PublishParseError(ParseErrorTypeFormat, "<oops", errors.New("bad"))
+					execCtx.PublishBeforeIteration()
+					publishModelCall(execCtx, "gpt-4", 1800, 50)
+					execCtx.PublishValidatorResult("quality", "short", false, nil)
+				},
+				reason: TerminationLimitExceeded,
+			},
+			expected: expected{iterations: 3, totalTokens: 4650, toolCalls: 2},
+		},
+		{
+			name: "children are aggregated",
+			input: input{
+				run: func(execCtx *ExecutionContext) {
+					execCtx.PublishBeforeIteration()
+					publishModelCall(execCtx, "gpt-4", 1000, 200)
+					publishToolCall(execCtx, "research", nil)
+
+					child := execCtx.SpawnChild("research", nil)
+					child.PublishBeforeIteration()
+					publishModelCall(child, "gpt-4o-mini", 400, 80)
+					publishToolCall(child, "search", errors.New("timeout"))
+					publishToolCall(child, "fetch", nil)
+					child.PublishParseError(ParseErrorTypeToolchain, "- tool", errors.New("bad"))
+					child.PublishValidatorResult("citations", "no sources", false, nil)
+					grandchild := child.SpawnChild("summarize", nil)
+					grandchild.PublishBeforeIteration()
+					publishModelCall(grandchild, "gpt-4o-mini", 300, 60)
+					child.CompleteChild(grandchild)
+					execCtx.CompleteChild(child)
+
+					execCtx.PublishBeforeIteration()
+					publishModelCall(execCtx, "gpt-4", 1600, 150)
+					execCtx.PublishValidatorResult("quality", "answer", true, nil)
+				},
+				reason: TerminationSuccess,
+			},
+			expected: expected{iterations: 4, totalTokens: 3790, toolCalls: 3},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "main", nil)
+			execCtx.SetLimits(nil)
+			tc.input.run(execCtx)
+
+			event := execCtx.PublishAfterExecution(tc.input.reason, nil)
+
+			require.NotNil(t, event.Summary)
+			summary := event.Summary
+			fromEvents := &ExecutionSummary{
+				TerminationReason:     tc.input.reason,
+				InputTokensByModel:    make(map[string]int64),
+				OutputTokensByModel:   make(map[string]int64),
+				ToolCallsByName:       make(map[string]int64),
+				ToolCallErrorsByName:  make(map[string]int64),
+				Errors:                make(map[StatKey]int64),
+				RejectionsByValidator: make(map[string]int64),
+				Counters:              summary.Counters,
+			}
+			summaryFromEvents(execCtx, fromEvents)
+
+			assert.Equal(t, fromEvents, summary)
+			assert.Equal(t, tc.expected.iterations, summary.Iterations)
+			assert.Equal(t, tc.expected.totalTokens, summary.TotalTokens)
+			assert.Equal(t, tc.expected.toolCalls, summary.ToolCalls)
+			assert.Equal(t, summary.ToolCalls, summary.Counters[SCToolCalls])
+			for key := range summary.Counters {
+				assert.False(t, key.IsSelf(), "unexpected local-only key %s", key)
+			}
+		})
+	}
+}
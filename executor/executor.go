@@ -2,6 +2,7 @@ package executor
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rickchristie/gent"
@@ -13,8 +14,102 @@ type Config struct {
 	// Events is the event registry for subscribers.
 	// If nil, a new registry is created automatically.
 	Events *events.Registry
+
+	// LimitDrainMode controls when the context is cancelled after a limit is exceeded.
+	// Defaults to LimitDrainCancel.
+	LimitDrainMode LimitDrainMode
+
+	// LimitDrainGrace bounds how long LimitDrainIteration waits before cancelling the
+	// context anyway. Zero waits until the iteration finishes.
+	LimitDrainGrace time.Duration
+
+	// RepeatedToolCallThreshold enables tool call loop detection. Once the agent calls
+	// the same tool with identical arguments this many times in a row, the call is not
+	// executed and is handled according to RepeatedToolCallAction instead. Zero disables
+	// detection. See [gent.RepeatedToolCallPolicy].
+	RepeatedToolCallThreshold int
+
+	// RepeatedToolCallAction is what to do with a repeated tool call: nudge the model
+	// with the prior result, return the cached result, or terminate execution.
+	// Defaults to gent.RepeatedToolCallNudge.
+	RepeatedToolCallAction gent.RepeatedToolCallAction
+
+	// HookPanicPolicy determines what happens when an event subscriber panics.
+	// Defaults to gent.HookPanicRecover: the panic is recovered, published as an
+	// ErrorEvent identifying the subscriber, and execution continues. The default
+	// leaves a policy set with ExecutionContext.SetHookPanicPolicy as is.
+	HookPanicPolicy gent.HookPanicPolicy
+
+	// ScratchpadLayout determines the order in which the AgentLoop renders the
+	// scratchpad into model requests. Defaults to gent.ScratchpadChronologicalOldestFirst;
+	// gent.ScratchpadSummaryFirstThenRecent puts compaction synopses on top. The default
+	// leaves a layout set with ExecutionContext.SetScratchpadLayout as is.
+	ScratchpadLayout gent.ScratchpadLayout
+
+	// ReflectionPass, if set and enabled, makes the AgentLoop ask the model to critique
+	// and possibly revise a candidate answer before it goes through termination and
+	// validators. If nil, a pass set with ExecutionContext.SetReflectionPass is left as
+	// is. See [gent.ReflectionPass].
+	ReflectionPass *gent.ReflectionPass
+
+	// RawIOSink, if set, receives the exact messages sent to and the completion
+	// received from every model call, including those of child contexts, before the
+	// response is parsed. Redaction is the sink's responsibility. See [gent.RawIOSink].
+	RawIOSink gent.RawIOSink
+
+	// AsyncCompaction moves the compaction due at the end of a successful execution off
+	// the user-facing path. If the compaction trigger fires once the execution has
+	// terminated, the strategy compacts a copy of the scratchpad in a child context in
+	// the background, after AfterExecutionEvent. Compactions between iterations still
+	// run synchronously.
+	//
+	// Call WaitCompaction with the finished execution's context to wait for the
+	// background compaction and set the compacted scratchpad. If the next Execute on the
+	// same LoopData, with the same Executor, starts while the compaction is still running,
+	// it waits and applies it before publishing BeforeExecutionEvent. The result is
+	// discarded if the compaction failed or the scratchpad was changed in between. The
+	// async start and finish events are published on the child context; see
+	// [gent.EventNameCompactionAsyncStart] and [gent.EventNameCompactionAsyncFinish].
+	//
+	// The background compaction's model calls are counted after AfterExecutionEvent, so
+	// its [gent.ExecutionSummary] does not include them. The async finish event carries
+	// them in its "input_tokens", "output_tokens" and "total_tokens" data: add them to
+	// the summary when billing a run.
+	AsyncCompaction bool
+
+	// ProgressFunc, if set, reports whether the iteration that just ran advanced the
+	// task, as defined by domain code. It is called after each iteration that
+	// continues the loop: gent.SGNoProgressConsecutive is incremented when it returns
+	// false and reset when it returns true. Add gent.NoProgressLimit to the limits to
+	// terminate an agent that keeps making well-formed calls without getting anywhere.
+	ProgressFunc func(execCtx *gent.ExecutionContext) bool
 }
 
+// LimitDrainMode controls how the Executor stops work in flight when a limit is exceeded.
+//
+// In both modes the LimitExceededEvent is published as soon as the limit is exceeded,
+// and the modes differ only in when ExecutionContext.Context() is cancelled. Execution
+// terminates with [gent.TerminationLimitExceeded], except under LimitDrainIteration when
+// the drained iteration itself returns LATerminate: the run then ends with that result
+// (e.g., [gent.TerminationSuccess]), and ExecutionResult.ExceededLimit still reports
+// the limit.
+type LimitDrainMode int
+
+const (
+	// LimitDrainCancel cancels the context as soon as the limit is exceeded. Model and
+	// tool calls watching ctx.Done() are interrupted mid-flight and the iteration usually
+	// ends with an error. This is the default.
+	LimitDrainCancel LimitDrainMode = iota
+
+	// LimitDrainIteration lets the current iteration finish, including its tool calls,
+	// and cancels the context before the next iteration starts. Code that wants to stop
+	// early can still check ExecutionContext.ExceededLimit().
+	//
+	// Set Config.LimitDrainGrace to cancel the context anyway if the iteration runs
+	// longer than the grace period after the limit was exceeded.
+	LimitDrainIteration
+)
+
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{}
@@ -35,6 +130,9 @@ type Executor[Data gent.LoopData] struct {
 	loop   gent.AgentLoop[Data]
 	config Config
 	events *events.Registry
+
+	mu      sync.Mutex
+	pending map[gent.LoopData]*asyncCompaction // background compactions by LoopData
 }
 
 // New creates a new Executor with the given AgentLoop and configuration.
@@ -44,9 +142,10 @@ func New[Data gent.LoopData](loop gent.AgentLoop[Data], config Config) *Executor
 		registry = events.NewRegistry()
 	}
 	return &Executor[Data]{
-		loop:   loop,
-		config: config,
-		events: registry,
+		loop:    loop,
+		config:  config,
+		events:  registry,
+		pending: make(map[gent.LoopData]*asyncCompaction),
 	}
 }
 
@@ -88,13 +187,16 @@ func (e *Executor[Data]) Subscribe(subscriber any) *Executor[Data] {
 // Execute runs the AgentLoop until termination.
 //
 // The execution flow:
-//  1. Publish BeforeExecutionEvent
+//  1. Wait for a background compaction of the same LoopData (see
+//     Config.AsyncCompaction), then publish BeforeExecutionEvent
 //  2. Repeatedly call AgentLoop.Next until:
 //     - It returns LATerminate
-//     - A limit is exceeded (context cancelled)
+//     - A limit is exceeded (context cancelled, see [LimitDrainMode])
+//     - A tool call loop is detected (see Config.RepeatedToolCallAction)
 //     - Context is canceled
 //     - An error occurs
-//  3. Publish AfterExecutionEvent
+//  3. Publish AfterExecutionEvent, then start a background compaction if
+//     Config.AsyncCompaction is set and the trigger fires
 //
 // The result is stored in execCtx.Result() after execution completes.
 // Check execCtx.Result().Error for any errors that occurred.
@@ -113,35 +215,64 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 	if e.events != nil {
 		execCtx.SetEventPublisher(e.events)
 	}
+	if e.config.LimitDrainMode == LimitDrainIteration {
+		execCtx.SetLimitDrain(true, e.config.LimitDrainGrace)
+	}
+	if e.config.HookPanicPolicy != gent.HookPanicRecover {
+		execCtx.SetHookPanicPolicy(e.config.HookPanicPolicy)
+	}
+	if e.config.ScratchpadLayout != gent.ScratchpadChronologicalOldestFirst {
+		execCtx.SetScratchpadLayout(e.config.ScratchpadLayout)
+	}
+	if e.config.ReflectionPass != nil {
+		execCtx.SetReflectionPass(*e.config.ReflectionPass)
+	}
+	if e.config.RawIOSink != nil {
+		execCtx.SetRawIOSink(e.config.RawIOSink)
+	}
+	if e.config.RepeatedToolCallThreshold > 0 {
+		execCtx.SetRepeatedToolCallPolicy(gent.RepeatedToolCallPolicy{
+			Threshold: e.config.RepeatedToolCallThreshold,
+			Action:    e.config.RepeatedToolCallAction,
+		})
+	}
 
 	// Ensure streams are closed and AfterExecution is always published if BeforeExecution was
 	beforeExecutionPublished := false
 	defer func() {
+		// Release any cancellation held back by LimitDrainIteration
+		execCtx.FinishLimitDrain()
+
 		// Always close streams when execution ends
 		execCtx.CloseStreams()
 
 		if beforeExecutionPublished {
 			execCtx.PublishAfterExecution(execCtx.TerminationReason(), execCtx.Error())
 		}
+
+		if e.config.AsyncCompaction && execCtx.Error() == nil {
+			e.startAsyncCompaction(execCtx)
+		}
 	}()
 
+	// The previous turn's compaction must land before this one reads the scratchpad
+	e.waitCompaction(execCtx)
+
 	// BeforeExecution event
 	execCtx.PublishBeforeExecution()
 	beforeExecutionPublished = true
 
 	// Main execution loop
 	for {
+		// A limit exceeded during the previous iteration cancels the context now when
+		// draining (no-op otherwise)
+		execCtx.FinishLimitDrain()
+
 		// Check context cancellation (handles both user cancel and limit exceeded)
 		goCtx := execCtx.Context()
 		if goCtx.Err() != nil {
-			if execCtx.ExceededLimit() != nil {
-				execCtx.SetTermination(
-					gent.TerminationLimitExceeded,
-					nil,
-					fmt.Errorf("limit exceeded: %s > %v",
-						execCtx.ExceededLimit().Key,
-						execCtx.ExceededLimit().MaxValue),
-				)
+			if limitErr := exceededLimitError(execCtx); limitErr != nil {
+				execCtx.SetTermination(gent.TerminationLimitExceeded, nil, limitErr)
 			} else {
 				execCtx.SetTermination(
 					gent.TerminationContextCanceled,
@@ -152,6 +283,12 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 			return
 		}
 
+		// A tool call loop detected with RepeatedToolCallTerminate ends execution
+		if err := execCtx.RepeatedToolCallError(); err != nil {
+			execCtx.SetTermination(gent.TerminationRepeatedToolCall, nil, err)
+			return
+		}
+
 		// Compaction check (skip first iteration — nothing to
 		// compact)
 		if execCtx.Iteration() > 0 {
@@ -166,18 +303,23 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 				)
 				return
 			}
+			// A compaction requested by a limit may have left it exceeded
+			if limitErr := exceededLimitError(execCtx); limitErr != nil {
+				execCtx.SetTermination(gent.TerminationLimitExceeded, nil, limitErr)
+				return
+			}
 		}
 
 		// Start iteration: increment counter and publish
 		// BeforeIterationEvent (BeforeIterationEvent updates
 		// SCIterations stat)
 		execCtx.IncrementIteration()
-		iterStart := time.Now()
+		iterStart := execCtx.Clock().Now()
 		execCtx.PublishBeforeIteration()
 
 		// Execute the AgentLoop iteration
 		loopResult, loopErr := e.loop.Next(execCtx)
-		iterDuration := time.Since(iterStart)
+		iterDuration := execCtx.Clock().Now().Sub(iterStart)
 
 		// Handle loop error - check if it was due to limit exceeded
 		if loopErr != nil {
@@ -185,14 +327,8 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 				&gent.AgentLoopResult{Action: gent.LATerminate},
 				iterDuration,
 			)
-			if execCtx.ExceededLimit() != nil {
-				execCtx.SetTermination(
-					gent.TerminationLimitExceeded,
-					nil,
-					fmt.Errorf("limit exceeded: %s > %v",
-						execCtx.ExceededLimit().Key,
-						execCtx.ExceededLimit().MaxValue),
-				)
+			if limitErr := exceededLimitError(execCtx); limitErr != nil {
+				execCtx.SetTermination(gent.TerminationLimitExceeded, nil, limitErr)
 			} else {
 				execErr := fmt.Errorf(
 					"AgentLoop.Next (iteration %d): %w",
@@ -204,11 +340,26 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 			return
 		}
 
+		// Keep the scratchpad gauges current for LoopData implementations that don't set them
+		setScratchpadGauges(execCtx)
+
+		if loopResult.Action != gent.LATerminate {
+			e.trackProgress(execCtx)
+		}
+
 		// Publish AfterIterationEvent
 		execCtx.PublishAfterIteration(loopResult, iterDuration)
 
 		// Check for termination
 		if loopResult.Action == gent.LATerminate {
+			if loopResult.TerminatedByTool {
+				execCtx.SetTerminatedByTool(loopResult.TerminatedBy)
+			} else {
+				execCtx.SetTerminatedBy(loopResult.TerminatedBy)
+			}
+			if loopResult.Candidates != nil {
+				execCtx.SetAnswerCandidates(loopResult.Candidates)
+			}
 			execCtx.SetTermination(gent.TerminationSuccess, loopResult.Result, nil)
 			return
 		}
@@ -218,8 +369,21 @@ func (e *Executor[Data]) Execute(execCtx *gent.ExecutionContext) {
 	}
 }
 
+// trackProgress updates gent.SGNoProgressConsecutive from Config.ProgressFunc.
+func (e *Executor[Data]) trackProgress(execCtx *gent.ExecutionContext) {
+	if e.config.ProgressFunc == nil {
+		return
+	}
+	if e.config.ProgressFunc(execCtx) {
+		execCtx.Stats().ResetGauge(gent.SGNoProgressConsecutive)
+		return
+	}
+	execCtx.Stats().IncrGauge(gent.SGNoProgressConsecutive, 1)
+}
+
 // compactIfNeeded checks the compaction trigger and runs the
-// strategy if triggered.
+// strategy if triggered or requested by a limit (see
+// gent.ExecutionContext.RequestCompaction).
 func (e *Executor[Data]) compactIfNeeded(
 	execCtx *gent.ExecutionContext,
 ) error {
@@ -229,23 +393,221 @@ func (e *Executor[Data]) compactIfNeeded(
 		return nil
 	}
 
-	if !trigger.ShouldCompact(execCtx) {
+	requested := execCtx.CompactionRequested()
+	if !requested && !trigger.ShouldCompact(execCtx) {
 		return nil
 	}
 
-	lengthBefore := len(execCtx.Data().GetScratchPad())
-	compactStart := time.Now()
+	if err := compact(execCtx, strategy); err != nil {
+		return err
+	}
+	setScratchpadGauges(execCtx)
+	trigger.NotifyCompacted(execCtx)
+	if requested {
+		execCtx.FinishCompactionRequest()
+	}
+
+	return nil
+}
+
+// compact runs the strategy and publishes the CompactionEvent.
+func compact(
+	execCtx *gent.ExecutionContext,
+	strategy gent.CompactionStrategy,
+) error {
+	estimator := execCtx.TokenEstimator()
+	before := execCtx.Data().GetScratchPad()
+	lengthBefore := len(before)
+	tokensBefore := estimator.EstimateTokens(before)
+	compactStart := execCtx.Clock().Now()
 
 	if err := strategy.Compact(execCtx); err != nil {
 		return err
 	}
 
-	lengthAfter := len(execCtx.Data().GetScratchPad())
-	duration := time.Since(compactStart)
-	execCtx.PublishCompaction(
-		lengthBefore, lengthAfter, duration,
+	duration := execCtx.Clock().Now().Sub(compactStart)
+	after := execCtx.Data().GetScratchPad()
+	execCtx.PublishCompactionWithTokens(
+		lengthBefore, len(after),
+		tokensBefore, estimator.EstimateTokens(after),
+		duration,
 	)
-	trigger.NotifyCompacted(execCtx)
+	return nil
+}
+
+// asyncCompaction is a background compaction started by startAsyncCompaction.
+type asyncCompaction struct {
+	done     chan struct{}
+	data     gent.LoopData          // LoopData of the execution that started the compaction
+	trigger  gent.CompactionTrigger // notified by the waiter that applies the result
+	snapshot []*gent.Iteration      // scratchpad the compaction started from
+	result   []*gent.Iteration      // compacted scratchpad, valid once done is closed
+	err      error
+	applied  bool // guarded by Executor.mu; set by the first waiter
+}
 
+// compactionData is the LoopData of a background compaction's child context. It carries
+// the compaction, so WaitCompaction can find it from the execution that started it.
+type compactionData struct {
+	*gent.BasicLoopData
+	compaction *asyncCompaction
+}
+
+// startAsyncCompaction checks the compaction trigger of a finished execution and, if it
+// fires, compacts a copy of the scratchpad in the background (see
+// Config.AsyncCompaction). The LoopData and the trigger are only touched by
+// finishCompaction, on the caller's goroutine. A compaction requested by a limit only
+// applies within the execution and is not carried over.
+func (e *Executor[Data]) startAsyncCompaction(execCtx *gent.ExecutionContext) {
+	trigger := execCtx.CompactionTrigger()
+	strategy := execCtx.CompactionStrategy()
+	if trigger == nil || strategy == nil || !trigger.ShouldCompact(execCtx) {
+		return
+	}
+
+	data := execCtx.Data()
+	pending := &asyncCompaction{
+		done:     make(chan struct{}),
+		data:     data,
+		trigger:  trigger,
+		snapshot: append([]*gent.Iteration(nil), data.GetScratchPad()...),
+	}
+
+	// The strategy works on its own LoopData in a child context, so the next execution
+	// can attach the original LoopData to its context while this runs
+	copied := &compactionData{
+		BasicLoopData: gent.NewBasicLoopData(data.GetTask()),
+		compaction:    pending,
+	}
+	copied.SetScratchPad(append([]*gent.Iteration(nil), pending.snapshot...))
+	child := execCtx.SpawnChild("compaction", copied)
+
+	// Only tracked while in flight: a finished compaction is reached through the
+	// execution's child context (see WaitCompaction)
+	e.mu.Lock()
+	e.pending[data] = pending
+	e.mu.Unlock()
+
+	child.PublishCommonEvent(
+		gent.EventNameCompactionAsyncStart,
+		"Background compaction started",
+		map[string]any{"scratchpad_length": len(pending.snapshot)},
+	)
+
+	go func() {
+		start := child.Clock().Now()
+		err := compact(child, strategy)
+		var result []*gent.Iteration
+		description := "Background compaction finished"
+		if err != nil {
+			// The scratchpad is left as is; the next execution's trigger decides again
+			description = "Background compaction failed"
+		} else {
+			result = copied.GetScratchPad()
+		}
+		child.PublishCommonEvent(
+			gent.EventNameCompactionAsyncFinish,
+			description,
+			map[string]any{
+				"duration":          child.Clock().Now().Sub(start),
+				"scratchpad_length": len(result),
+				"error":             err,
+				"input_tokens":      child.Stats().GetTotalInputTokens(),
+				"output_tokens":     child.Stats().GetTotalOutputTokens(),
+				"total_tokens":      child.Stats().GetTotalTokens(),
+			},
+		)
+		execCtx.CompleteChild(child)
+
+		e.mu.Lock()
+		pending.result = result
+		pending.err = err
+		close(pending.done)
+		if e.pending[data] == pending {
+			delete(e.pending, data)
+		}
+		e.mu.Unlock()
+	}()
+}
+
+// WaitCompaction blocks until the background compaction started at the end of execCtx
+// finishes, and sets its result as the scratchpad of execCtx.Data() (see
+// Config.AsyncCompaction). Returns immediately if there is none, or if it was already
+// applied.
+func (e *Executor[Data]) WaitCompaction(execCtx *gent.ExecutionContext) {
+	children := execCtx.Children()
+	for i := len(children) - 1; i >= 0; i-- {
+		if copied, ok := children[i].Data().(*compactionData); ok {
+			e.finishCompaction(copied.compaction, execCtx)
+			return
+		}
+	}
+}
+
+// waitCompaction waits for the background compaction still running on the LoopData of
+// execCtx, if any, and applies its result.
+func (e *Executor[Data]) waitCompaction(execCtx *gent.ExecutionContext) {
+	e.mu.Lock()
+	pending := e.pending[execCtx.Data()]
+	e.mu.Unlock()
+	if pending != nil {
+		e.finishCompaction(pending, execCtx)
+	}
+}
+
+// finishCompaction blocks until pending finishes and applies its result to its LoopData,
+// then notifies its trigger through execCtx. Only the first waiter applies the result,
+// and it is discarded if the compaction failed or the scratchpad changed since the
+// compaction started.
+func (e *Executor[Data]) finishCompaction(
+	pending *asyncCompaction,
+	execCtx *gent.ExecutionContext,
+) {
+	<-pending.done
+
+	e.mu.Lock()
+	applied := pending.applied
+	pending.applied = true
+	e.mu.Unlock()
+	if applied {
+		return
+	}
+
+	if pending.err == nil && sameIterations(pending.data.GetScratchPad(), pending.snapshot) {
+		pending.data.SetScratchPad(pending.result)
+		pending.trigger.NotifyCompacted(execCtx)
+	}
+}
+
+// sameIterations reports whether a and b hold the same iterations in the same order.
+func sameIterations(a, b []*gent.Iteration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setScratchpadGauges sets SGScratchpadLength and SGScratchpadBytes from the current
+// scratchpad.
+func setScratchpadGauges(execCtx *gent.ExecutionContext) {
+	scratchpad := execCtx.Data().GetScratchPad()
+	execCtx.Stats().SetGauge(gent.SGScratchpadLength, float64(len(scratchpad)))
+	execCtx.Stats().SetGauge(gent.SGScratchpadBytes, float64(gent.ScratchpadBytes(scratchpad)))
+}
+
+// exceededLimitError returns the termination error for a limit exceeded by execCtx, or
+// by the budget it runs under (see gent.BudgetRoot), or nil if no limit was exceeded.
+func exceededLimitError(execCtx *gent.ExecutionContext) error {
+	if limit := execCtx.ExceededLimit(); limit != nil {
+		return fmt.Errorf("limit exceeded: %s > %v", limit.Key, limit.MaxValue)
+	}
+	if limit := execCtx.ExceededBudgetLimit(); limit != nil {
+		return fmt.Errorf("%w: %s > %v", gent.ErrBudgetExhausted, limit.Key, limit.MaxValue)
+	}
 	return nil
 }
@@ -0,0 +1,329 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ----------------------------------------------------------------
+// Async Compaction Tests
+//
+// These tests verify Config.AsyncCompaction:
+//   - Execute returns before the background compaction finishes
+//   - The result is applied by WaitCompaction or the next Execute
+//   - Stale and failed results leave the scratchpad as is
+//   - Finished compactions are not kept by the Executor
+// ----------------------------------------------------------------
+
+// keepLastIteration compacts the scratchpad to its last iteration once release is closed.
+func keepLastIteration(
+	release <-chan struct{},
+	err error,
+) func(execCtx *gent.ExecutionContext) error {
+	return func(execCtx *gent.ExecutionContext) error {
+		<-release
+		execCtx.Stats().IncrCounter(gent.SCInputTokens, 100)
+		execCtx.Stats().IncrCounter(gent.SCOutputTokens, 20)
+		if err != nil {
+			return err
+		}
+		sp := execCtx.Data().GetScratchPad()
+		execCtx.Data().SetScratchPad(sp[len(sp)-1:])
+		return nil
+	}
+}
+
+// asyncCompactionEvents returns the names of the AfterExecutionEvent published on
+// execCtx and the background compaction events published on its children, in order.
+func asyncCompactionEvents(execCtx *gent.ExecutionContext) []string {
+	var names []string
+	execCtx.Walk(func(ctx *gent.ExecutionContext) bool {
+		for _, event := range ctx.Events() {
+			switch e := event.(type) {
+			case *gent.AfterExecutionEvent:
+				names = append(names, e.EventName)
+			case *gent.CommonEvent:
+				if e.EventName == gent.EventNameCompactionAsyncStart ||
+					e.EventName == gent.EventNameCompactionAsyncFinish {
+					names = append(names, e.EventName)
+				}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+func TestAsyncCompaction(t *testing.T) {
+	type input struct {
+		shouldCompact    []bool
+		strategyErr      error
+		changeScratchpad bool
+	}
+
+	type expected struct {
+		terminationReason gent.TerminationReason
+		eventsBeforeWait  []string
+		eventsAfterWait   []string
+		scratchpadLen     int
+		notifiedCount     int
+		finishErr         error
+	}
+
+	// Three iterations; the trigger is checked before iterations 2 and 3 and once
+	// the execution has terminated.
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "trigger does not fire after execution",
+			input: input{shouldCompact: []bool{false, false, false}},
+			expected: expected{
+				terminationReason: gent.TerminationSuccess,
+				eventsBeforeWait:  []string{gent.EventNameExecutionAfter},
+				eventsAfterWait:   []string{gent.EventNameExecutionAfter},
+				scratchpadLen:     3,
+			},
+		},
+		{
+			name:  "compaction applied by WaitCompaction",
+			input: input{shouldCompact: []bool{false, false, true}},
+			expected: expected{
+				terminationReason: gent.TerminationSuccess,
+				eventsBeforeWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+				},
+				eventsAfterWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+					gent.EventNameCompactionAsyncFinish,
+				},
+				scratchpadLen: 1,
+				notifiedCount: 1,
+			},
+		},
+		{
+			name: "stale result is discarded",
+			input: input{
+				shouldCompact:    []bool{false, false, true},
+				changeScratchpad: true,
+			},
+			expected: expected{
+				terminationReason: gent.TerminationSuccess,
+				eventsBeforeWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+				},
+				eventsAfterWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+					gent.EventNameCompactionAsyncFinish,
+				},
+				scratchpadLen: 4,
+			},
+		},
+		{
+			name: "failed compaction leaves scratchpad",
+			input: input{
+				shouldCompact: []bool{false, false, true},
+				strategyErr:   errors.New("summarization model failed"),
+			},
+			expected: expected{
+				terminationReason: gent.TerminationSuccess,
+				eventsBeforeWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+				},
+				eventsAfterWait: []string{
+					gent.EventNameExecutionAfter,
+					gent.EventNameCompactionAsyncStart,
+					gent.EventNameCompactionAsyncFinish,
+				},
+				scratchpadLen: 3,
+				finishErr:     errors.New("summarization model failed"),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			release := make(chan struct{})
+			trigger := tt.NewMockCompactionTrigger().
+				WithShouldCompact(tc.input.shouldCompact...)
+			strategy := tt.NewMockCompactionStrategy().
+				WithCompactFunc(keepLastIteration(release, tc.input.strategyErr))
+
+			data := gent.NewBasicLoopData(&gent.Task{Text: "test"})
+			config := executor.DefaultConfig()
+			config.AsyncCompaction = true
+			exec := executor.New[*gent.BasicLoopData](
+				&scratchpadTrackingLoop{terminateAt: 3},
+				config,
+			)
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+			execCtx.SetLimits(nil)
+			execCtx.SetCompaction(trigger, strategy)
+
+			// The strategy is blocked, so Execute must not wait for it
+			exec.Execute(execCtx)
+
+			assert.Equal(t, tc.expected.terminationReason,
+				execCtx.Result().TerminationReason)
+			assert.Equal(t, tc.expected.eventsBeforeWait, asyncCompactionEvents(execCtx))
+			assert.Len(t, data.GetScratchPad(), 3)
+
+			if tc.input.changeScratchpad {
+				data.SetScratchPad(append(data.GetScratchPad(), &gent.Iteration{}))
+			}
+			close(release)
+			exec.WaitCompaction(execCtx)
+
+			assert.Equal(t, tc.expected.eventsAfterWait, asyncCompactionEvents(execCtx))
+			assert.Len(t, data.GetScratchPad(), tc.expected.scratchpadLen)
+			assert.Equal(t, tc.expected.notifiedCount, trigger.NotifiedCount())
+			assert.Equal(t, 0, exec.PendingCompactions())
+
+			// Waiting again does not apply the result twice
+			exec.WaitCompaction(execCtx)
+			assert.Equal(t, tc.expected.notifiedCount, trigger.NotifiedCount())
+
+			var finish *gent.CommonEvent
+			execCtx.Walk(func(ctx *gent.ExecutionContext) bool {
+				for _, event := range ctx.Events() {
+					if e, ok := event.(*gent.CommonEvent); ok &&
+						e.EventName == gent.EventNameCompactionAsyncFinish {
+						finish = e
+					}
+				}
+				return true
+			})
+			if finish != nil {
+				finishData := finish.Data.(map[string]any)
+				if tc.expected.finishErr != nil {
+					assert.EqualError(t, finishData["error"].(error),
+						tc.expected.finishErr.Error())
+				} else {
+					assert.Nil(t, finishData["error"])
+				}
+				assert.Equal(t, int64(100), finishData["input_tokens"])
+				assert.Equal(t, int64(20), finishData["output_tokens"])
+
+				// The summary was taken before the background compaction counted them
+				for _, event := range execCtx.Events() {
+					if e, ok := event.(*gent.AfterExecutionEvent); ok {
+						assert.Equal(t, int64(0), e.Summary.InputTokens)
+					}
+				}
+				assert.Equal(t, int64(100), execCtx.Stats().GetTotalInputTokens())
+			}
+		})
+	}
+}
+
+func TestAsyncCompaction_NextExecuteWaits(t *testing.T) {
+	release := make(chan struct{})
+	compacted := make(chan struct{})
+	trigger := tt.NewMockCompactionTrigger().
+		WithShouldCompact(false, false, true, false)
+	strategy := tt.NewMockCompactionStrategy().
+		WithCompactFunc(func(execCtx *gent.ExecutionContext) error {
+			defer close(compacted)
+			return keepLastIteration(release, nil)(execCtx)
+		})
+
+	data := gent.NewBasicLoopData(&gent.Task{Text: "test"})
+	config := executor.DefaultConfig()
+	config.AsyncCompaction = true
+	loop := &scratchpadTrackingLoop{terminateAt: 3}
+	exec := executor.New[*gent.BasicLoopData](loop, config)
+
+	firstCtx := gent.NewExecutionContext(context.Background(), "first", data)
+	firstCtx.SetLimits(nil)
+	firstCtx.SetCompaction(trigger, strategy)
+	exec.Execute(firstCtx)
+	require.Equal(t, gent.TerminationSuccess, firstCtx.Result().TerminationReason)
+
+	// The next turn starts while the compaction is still running
+	secondCtx := gent.NewExecutionContext(context.Background(), "second", data)
+	secondCtx.SetLimits(nil)
+	secondCtx.SetCompaction(trigger, strategy)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		exec.Execute(secondCtx)
+	}()
+
+	select {
+	case <-finished:
+		t.Fatal("second Execute finished before the background compaction")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, 3, loop.calls, "second Execute must not run its loop yet")
+
+	close(release)
+	<-compacted
+	<-finished
+
+	// The second turn ran on the compacted scratchpad: 1 kept + 1 new iteration
+	assert.Equal(t, gent.TerminationSuccess, secondCtx.Result().TerminationReason)
+	assert.Equal(t, 4, loop.calls)
+	assert.Len(t, data.GetScratchPad(), 2)
+	assert.Equal(t, []string{
+		gent.EventNameExecutionAfter,
+		gent.EventNameCompactionAsyncStart,
+		gent.EventNameCompactionAsyncFinish,
+	}, asyncCompactionEvents(firstCtx))
+	assert.Equal(t, []string{gent.EventNameExecutionAfter}, asyncCompactionEvents(secondCtx))
+	assert.Equal(t, 1, trigger.NotifiedCount())
+}
+
+func TestAsyncCompaction_PendingDrains(t *testing.T) {
+	const executions = 50
+
+	config := executor.DefaultConfig()
+	config.AsyncCompaction = true
+	exec := executor.New[*gent.BasicLoopData](&scratchpadTrackingLoop{terminateAt: 1}, config)
+
+	release := make(chan struct{})
+	contexts := make([]*gent.ExecutionContext, executions)
+	for i := range contexts {
+		// A previous turn's iteration, so the compaction has something to drop
+		data := gent.NewBasicLoopData(&gent.Task{Text: "test"})
+		data.SetScratchPad([]*gent.Iteration{{}})
+		execCtx := gent.NewExecutionContext(context.Background(), "test", data)
+		execCtx.SetLimits(nil)
+		execCtx.SetCompaction(
+			tt.NewMockCompactionTrigger().WithShouldCompact(true),
+			tt.NewMockCompactionStrategy().WithCompactFunc(keepLastIteration(release, nil)),
+		)
+		exec.Execute(execCtx)
+		require.Equal(t, gent.TerminationSuccess, execCtx.Result().TerminationReason)
+		require.Len(t, data.GetScratchPad(), 2)
+		contexts[i] = execCtx
+	}
+	assert.Equal(t, executions, exec.PendingCompactions())
+
+	// Nobody waits: every entry is removed once its compaction finishes
+	close(release)
+	assert.Eventually(t, func() bool {
+		return exec.PendingCompactions() == 0
+	}, time.Second, time.Millisecond)
+
+	// The finished results are still reachable from their executions
+	for _, execCtx := range contexts {
+		exec.WaitCompaction(execCtx)
+		assert.Len(t, execCtx.Data().GetScratchPad(), 1)
+	}
+}
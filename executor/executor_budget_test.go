@@ -0,0 +1,98 @@
+package executor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_BudgetRoot(t *testing.T) {
+	type taskResult struct {
+		reason      gent.TerminationReason
+		inputTokens int64
+		exhausted   bool
+	}
+
+	type input struct {
+		maxInputTokens float64
+		tasks          int
+	}
+
+	type expected struct {
+		tasks        []taskResult
+		refused      int
+		budgetTokens int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "tasks within budget keep their own stats",
+			input: input{maxInputTokens: 1000, tasks: 2},
+			expected: expected{
+				tasks: []taskResult{
+					{reason: gent.TerminationSuccess, inputTokens: 200},
+					{reason: gent.TerminationSuccess, inputTokens: 200},
+				},
+				budgetTokens: 400,
+			},
+		},
+		{
+			name:  "budget limit stops the in-flight task and refuses new ones",
+			input: input{maxInputTokens: 250, tasks: 4},
+			expected: expected{
+				tasks: []taskResult{
+					{reason: gent.TerminationSuccess, inputTokens: 200},
+					{reason: gent.TerminationLimitExceeded, inputTokens: 100, exhausted: true},
+				},
+				refused:      2,
+				budgetTokens: 300,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			budget := gent.NewBudgetRoot([]gent.Limit{
+				{
+					Type:     gent.LimitExactKey,
+					Key:      gent.SCInputTokens,
+					MaxValue: tc.input.maxInputTokens,
+				},
+			})
+
+			var results []taskResult
+			refused := 0
+			for i := 0; i < tc.input.tasks; i++ {
+				task, err := budget.NewTask("task", newMockLoopData())
+				if err != nil {
+					assert.ErrorIs(t, err, gent.ErrBudgetExhausted)
+					refused++
+					continue
+				}
+				loop := &mockAgentLoop{terminateAt: 2, inputTokens: 100}
+				executor.New[*mockLoopData](loop, executor.DefaultConfig()).Execute(task)
+				budget.CompleteTask(task)
+
+				require.NotNil(t, task.Result())
+				results = append(results, taskResult{
+					reason:      task.TerminationReason(),
+					inputTokens: task.Stats().GetCounter(gent.SCInputTokens),
+					exhausted:   errors.Is(task.Result().Error, gent.ErrBudgetExhausted),
+				})
+			}
+
+			assert.Equal(t, tc.expected.tasks, results)
+			assert.Equal(t, tc.expected.refused, refused)
+			assert.Equal(t, tc.expected.budgetTokens,
+				budget.Stats().GetCounter(gent.SCInputTokens))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/compaction"
 	"github.com/rickchristie/gent/executor"
 	"github.com/rickchristie/gent/internal/tt"
 	"github.com/stretchr/testify/assert"
@@ -418,6 +419,131 @@ func collectLifecycleAndCompaction(
 	return result
 }
 
+// ----------------------------------------------------------------
+// Test: scratchpad length gauge reflects the compacted scratchpad
+// ----------------------------------------------------------------
+
+func TestCompaction_ScratchpadLengthGauge(t *testing.T) {
+	type input struct {
+		data gent.LoopData
+	}
+
+	type expected struct {
+		compactions   int64
+		gauge         float64
+		historyLength int
+	}
+
+	// Six iterations, compacting to the last iteration whenever
+	// three are retained: compactions before iterations 4 and 6.
+	// A gauge tracking history length would compact before every
+	// iteration from 4 on.
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "BasicLoopData",
+			input: input{
+				data: gent.NewBasicLoopData(&gent.Task{Text: "test"}),
+			},
+			expected: expected{compactions: 2, gauge: 2, historyLength: 6},
+		},
+		{
+			name:     "LoopData that does not set the gauge",
+			input:    input{data: newMockLoopData()},
+			expected: expected{compactions: 2, gauge: 2, historyLength: 6},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			trigger := &gaugeRecordingTrigger{
+				StatThresholdTrigger: compaction.NewStatThresholdTrigger().
+					OnGauge(gent.SGScratchpadLength, 3),
+			}
+			strategy := tt.NewMockCompactionStrategy().
+				WithCompactFunc(func(execCtx *gent.ExecutionContext) error {
+					scratchpad := execCtx.Data().GetScratchPad()
+					execCtx.Data().SetScratchPad(scratchpad[len(scratchpad)-1:])
+					return nil
+				})
+			execCtx := gent.NewExecutionContext(
+				context.Background(), "test", tc.input.data,
+			)
+			execCtx.SetLimits(nil)
+			execCtx.SetCompaction(trigger, strategy)
+
+			exec := executor.New[gent.LoopData](
+				&historyTrackingLoop{terminateAt: 6},
+				executor.DefaultConfig(),
+			)
+			exec.Execute(execCtx)
+
+			assert.Equal(t,
+				gent.TerminationSuccess,
+				execCtx.TerminationReason(),
+			)
+			assert.Equal(t,
+				tc.expected.compactions,
+				execCtx.Stats().GetCounter(gent.SCCompactions),
+			)
+			assert.Equal(t,
+				tc.expected.gauge,
+				execCtx.Stats().GetGauge(gent.SGScratchpadLength),
+			)
+			assert.Equal(t, []float64{1, 1}, trigger.gauges)
+			assert.Len(t,
+				tc.input.data.GetIterationHistory(),
+				tc.expected.historyLength,
+			)
+		})
+	}
+}
+
+// gaugeRecordingTrigger records SGScratchpadLength each time
+// compaction completes.
+type gaugeRecordingTrigger struct {
+	*compaction.StatThresholdTrigger
+	gauges []float64
+}
+
+func (t *gaugeRecordingTrigger) NotifyCompacted(
+	execCtx *gent.ExecutionContext,
+) {
+	t.gauges = append(
+		t.gauges,
+		execCtx.Stats().GetGauge(gent.SGScratchpadLength),
+	)
+	t.StatThresholdTrigger.NotifyCompacted(execCtx)
+}
+
+// historyTrackingLoop implements AgentLoop that records each
+// iteration in both the history and the scratchpad.
+type historyTrackingLoop struct {
+	calls       int
+	terminateAt int
+}
+
+func (l *historyTrackingLoop) Next(
+	execCtx *gent.ExecutionContext,
+) (*gent.AgentLoopResult, error) {
+	l.calls++
+
+	iter := &gent.Iteration{
+		Messages: []*gent.MessageContent{},
+	}
+	data := execCtx.Data()
+	data.AddIterationHistory(iter)
+	data.SetScratchPad(append(data.GetScratchPad(), iter))
+
+	if l.calls >= l.terminateAt {
+		return tt.Terminate("done"), nil
+	}
+	return tt.ContinueWithPrompt(mockObservation), nil
+}
+
 // ----------------------------------------------------------------
 // Test: per-iteration gauges available during trigger check
 //
@@ -0,0 +1,88 @@
+package executor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingHook panics on every AfterToolCallEvent.
+type panickingHook struct{}
+
+func (h *panickingHook) OnAfterToolCall(*gent.ExecutionContext, *gent.AfterToolCallEvent) {
+	panic("hook bug")
+}
+
+// toolCallCounter counts AfterToolCallEvents.
+type toolCallCounter struct {
+	calls int
+}
+
+func (h *toolCallCounter) OnAfterToolCall(*gent.ExecutionContext, *gent.AfterToolCallEvent) {
+	h.calls++
+}
+
+func TestExecute_HookPanicPolicy(t *testing.T) {
+	newLoop := func() *mockAgentLoop {
+		return &mockAgentLoop{
+			nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+				execCtx.PublishAfterToolCall("search", nil, "sunny", 0, nil)
+				if execCtx.Iteration() == 2 {
+					return tt.Terminate("done"), nil
+				}
+				return tt.ContinueWithPrompt(mockObservation), nil
+			},
+		}
+	}
+
+	t.Run("recovers by default and keeps running", func(t *testing.T) {
+		counter := &toolCallCounter{}
+		execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+		executor.New[*mockLoopData](newLoop(), executor.Config{}).
+			Subscribe(&panickingHook{}).
+			Subscribe(counter).
+			Execute(execCtx)
+
+		assert.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+		assert.Equal(t, 2, execCtx.Iteration())
+		assert.Equal(t, 2, counter.calls, "later subscribers still receive the event")
+
+		var panics []*gent.HookPanicError
+		for _, event := range execCtx.Events() {
+			if e, ok := event.(*gent.ErrorEvent); ok {
+				assert.ErrorIs(t, e.Error, gent.ErrHookPanic)
+				panicErr, ok := e.Error.(*gent.HookPanicError)
+				require.True(t, ok)
+				panics = append(panics, panicErr)
+			}
+		}
+		require.Len(t, panics, 2)
+		assert.Equal(t, "*executor_test.panickingHook", panics[0].Subscriber)
+		assert.Equal(t, gent.EventNameToolCallAfter, panics[0].EventName)
+		assert.Equal(t, "hook bug", panics[0].Value)
+		assert.NotEmpty(t, panics[0].Stack)
+	})
+
+	t.Run("propagates in strict mode", func(t *testing.T) {
+		execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+		exec := executor.New[*mockLoopData](newLoop(), executor.Config{
+			HookPanicPolicy: gent.HookPanicPropagate,
+		}).Subscribe(&panickingHook{})
+
+		assert.PanicsWithValue(t, "hook bug", func() { exec.Execute(execCtx) })
+	})
+
+	t.Run("keeps the policy set on the context", func(t *testing.T) {
+		execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+		execCtx.SetHookPanicPolicy(gent.HookPanicPropagate)
+		exec := executor.New[*mockLoopData](newLoop(), executor.Config{}).
+			Subscribe(&panickingHook{})
+
+		assert.PanicsWithValue(t, "hook bug", func() { exec.Execute(execCtx) })
+	})
+}
@@ -38,11 +38,13 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rickchristie/gent"
 	"github.com/rickchristie/gent/executor"
 	"github.com/rickchristie/gent/internal/tt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // -----------------------------------------------------------------------------
@@ -1124,7 +1126,7 @@ func TestLimits_EdgeCase_ExactlyAtLimit(t *testing.T) {
 }
 
 func TestLimits_EdgeCase_MultipleMatchingLimits(t *testing.T) {
-	// Multiple limits would be exceeded - first one wins
+	// Multiple equal exact limits would be exceeded - first one wins
 	loop := &mockAgentLoop{
 		inputTokens:  1000,
 		outputTokens: 1000,
@@ -1167,6 +1169,132 @@ func TestLimits_EdgeCase_MultipleMatchingLimits(t *testing.T) {
 	tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
 }
 
+func TestLimits_OverlappingExactAndPrefixLimits(t *testing.T) {
+	searchCalls := gent.SCToolCallsFor + "search"
+	modelTokens := gent.SCInputTokensFor + "test-model"
+
+	type input struct {
+		limits []gent.Limit
+		step   func(execCtx *gent.ExecutionContext) // one iteration's work
+	}
+
+	type expected struct {
+		limit      gent.Limit
+		key        gent.StatKey
+		iterations int
+	}
+
+	callSearch := func(execCtx *gent.ExecutionContext) {
+		execCtx.PublishBeforeToolCall("search", nil)
+	}
+	callModel := func(execCtx *gent.ExecutionContext) {
+		_ = simulateModelCall(execCtx, "test-model", 1000, 0)
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "exact tool limit wins over prefix with the same max",
+			input: input{
+				limits: []gent.Limit{
+					tt.PrefixLimit(gent.SCToolCallsFor, 2),
+					tt.ExactLimit(searchCalls, 2),
+				},
+				step: callSearch,
+			},
+			expected: expected{
+				limit:      tt.ExactLimit(searchCalls, 2),
+				key:        searchCalls,
+				iterations: 3,
+			},
+		},
+		{
+			name: "exact token limit wins over prefix with a lower max",
+			input: input{
+				limits: []gent.Limit{
+					tt.PrefixLimit(gent.SCInputTokensFor, 500),
+					tt.ExactLimit(modelTokens, 800),
+				},
+				step: callModel,
+			},
+			expected: expected{
+				limit:      tt.ExactLimit(modelTokens, 800),
+				key:        modelTokens,
+				iterations: 1,
+			},
+		},
+		{
+			name: "lowest max wins between prefix limits",
+			input: input{
+				limits: []gent.Limit{
+					tt.PrefixLimit(gent.SCInputTokensFor, 800),
+					tt.PrefixLimit(gent.SCInputTokensFor, 500),
+				},
+				step: callModel,
+			},
+			expected: expected{
+				limit:      tt.PrefixLimit(gent.SCInputTokensFor, 500),
+				key:        modelTokens,
+				iterations: 1,
+			},
+		},
+		{
+			name: "slice order breaks remaining ties",
+			input: input{
+				limits: []gent.Limit{
+					tt.ExactLimit(gent.SCInputTokens, 500),
+					tt.ExactLimit(gent.SCTotalTokens, 500),
+				},
+				step: callModel,
+			},
+			expected: expected{
+				limit:      tt.ExactLimit(gent.SCInputTokens, 500),
+				key:        gent.SCInputTokens,
+				iterations: 1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					tc.input.step(execCtx)
+					return tt.ContinueWithPrompt(mockObservation), nil
+				},
+			}
+			execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+			execCtx.SetLimits(tc.input.limits)
+
+			executor.New[*mockLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+			require.NotNil(t, execCtx.ExceededLimit())
+			assert.Equal(t, tc.expected.limit, *execCtx.ExceededLimit())
+			assert.Equal(t, tc.expected.key, execCtx.ExceededLimitKey())
+			assert.Equal(t, tc.expected.iterations, execCtx.Iteration())
+		})
+	}
+}
+
+func TestLimits_PrefixLimit_ReportsSmallestExceededKey(t *testing.T) {
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	execCtx.SetLimits(nil)
+	for range 3 {
+		execCtx.Stats().IncrCounter(gent.SCToolCallsFor+"search", 1)
+		execCtx.Stats().IncrCounter(gent.SCToolCallsFor+"lookup", 1)
+	}
+
+	// Both keys already exceed the limit when it is added; the next update reports
+	// the lexically smallest one.
+	execCtx.SetLimits([]gent.Limit{tt.PrefixLimit(gent.SCToolCallsFor, 2)})
+	execCtx.Stats().IncrCounter("test:unrelated", 1)
+
+	assert.Equal(t, gent.SCToolCallsFor+"lookup", execCtx.ExceededLimitKey())
+}
+
 func TestLimits_EdgeCase_ContextAlreadyCancelled(t *testing.T) {
 	loop := &mockAgentLoop{terminateAt: 5}
 	exec := executor.New[*mockLoopData](loop, executor.DefaultConfig())
@@ -1915,3 +2043,193 @@ func TestLimits_LimitExceededEvent_NotPublishedOnSuccess(t *testing.T) {
 	}
 	tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
 }
+
+// -----------------------------------------------------------------------------
+// Limit Drain Mode Tests
+// -----------------------------------------------------------------------------
+
+func TestLimits_DrainMode_ToolObservesContext(t *testing.T) {
+	// The tool call itself exceeds the limit (SCToolCalls > 0), then does work that
+	// watches ctx.Done(). The drain mode decides whether that work is interrupted.
+	type input struct {
+		config       executor.Config
+		toolDuration time.Duration
+	}
+
+	type expected struct {
+		toolOutput string
+		toolErr    error
+		iterResult *gent.AgentLoopResult
+	}
+
+	toolLimit := tt.ExactLimit(gent.SCToolCalls, 0)
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "cancel mode interrupts the tool immediately",
+			input: input{
+				config:       executor.Config{LimitDrainMode: executor.LimitDrainCancel},
+				toolDuration: time.Hour,
+			},
+			expected: expected{
+				toolOutput: "",
+				toolErr:    context.Canceled,
+				iterResult: &gent.AgentLoopResult{Action: gent.LATerminate},
+			},
+		},
+		{
+			name: "iteration mode lets the tool finish",
+			input: input{
+				config:       executor.Config{LimitDrainMode: executor.LimitDrainIteration},
+				toolDuration: 10 * time.Millisecond,
+			},
+			expected: expected{
+				toolOutput: "completed",
+				toolErr:    nil,
+				iterResult: tt.ContinueWithPrompt("completed"),
+			},
+		},
+		{
+			name: "iteration mode cancels the tool after the grace period",
+			input: input{
+				config: executor.Config{
+					LimitDrainMode:  executor.LimitDrainIteration,
+					LimitDrainGrace: 10 * time.Millisecond,
+				},
+				toolDuration: time.Hour,
+			},
+			expected: expected{
+				toolOutput: "",
+				toolErr:    context.Canceled,
+				iterResult: &gent.AgentLoopResult{Action: gent.LATerminate},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					execCtx.PublishBeforeToolCall("slow", nil)
+
+					var output string
+					var err error
+					select {
+					case <-execCtx.Context().Done():
+						err = context.Canceled
+					case <-time.After(tc.input.toolDuration):
+						output = "completed"
+					}
+					execCtx.PublishAfterToolCall("slow", nil, output, 0, err)
+
+					if err != nil {
+						return nil, err
+					}
+					return tt.ContinueWithPrompt(output), nil
+				},
+			}
+			exec := executor.New[*mockLoopData](loop, tc.input.config)
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+			execCtx.SetLimits([]gent.Limit{toolLimit})
+
+			exec.Execute(execCtx)
+
+			result := execCtx.Result()
+			assert.Equal(t, gent.TerminationLimitExceeded, result.TerminationReason)
+			assert.Equal(t, &toolLimit, result.ExceededLimit)
+			assert.Equal(t, 1, loop.GetCalls())
+			assert.Error(t, execCtx.Context().Err())
+
+			expectedEvents := []gent.Event{
+				tt.BeforeExec(0, 0),
+				tt.BeforeIter(0, 1),
+				tt.BeforeToolCall(0, 1, "slow", nil),
+				tt.LimitExceeded(0, 1, toolLimit, 1, gent.SCToolCalls),
+				tt.AfterToolCall(0, 1, "slow", nil, tc.expected.toolOutput, tc.expected.toolErr),
+				tt.AfterIter(0, 1, tc.expected.iterResult),
+				tt.AfterExec(0, 1, gent.TerminationLimitExceeded),
+			}
+			tt.AssertEventsEqual(t, expectedEvents, tt.CollectLifecycleEvents(execCtx))
+		})
+	}
+}
+
+func TestLimits_DrainMode_TerminationOfDrainedIteration(t *testing.T) {
+	type input struct {
+		mode   executor.LimitDrainMode
+		result *gent.AgentLoopResult
+	}
+
+	type expected struct {
+		reason gent.TerminationReason
+	}
+
+	toolLimit := tt.ExactLimit(gent.SCToolCalls, 0)
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "drained iteration that continues ends as limit exceeded",
+			input:    input{mode: executor.LimitDrainIteration, result: tt.ContinueWithPrompt("ok")},
+			expected: expected{reason: gent.TerminationLimitExceeded},
+		},
+		{
+			name:     "drained iteration that terminates ends with its result",
+			input:    input{mode: executor.LimitDrainIteration, result: tt.Terminate("done")},
+			expected: expected{reason: gent.TerminationSuccess},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					execCtx.PublishBeforeToolCall("search", nil)
+					execCtx.PublishAfterToolCall("search", nil, "ok", 0, nil)
+					return tc.input.result, nil
+				},
+			}
+			exec := executor.New[*mockLoopData](loop, executor.Config{
+				LimitDrainMode: tc.input.mode,
+			})
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+			execCtx.SetLimits([]gent.Limit{toolLimit})
+
+			exec.Execute(execCtx)
+
+			result := execCtx.Result()
+			assert.Equal(t, tc.expected.reason, result.TerminationReason)
+			assert.Equal(t, &toolLimit, result.ExceededLimit)
+			assert.Equal(t, 1, loop.GetCalls())
+		})
+	}
+}
+
+func TestLimits_DrainMode_ChildContextInheritsDrain(t *testing.T) {
+	execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+	execCtx.SetLimits([]gent.Limit{tt.ExactLimit(gent.SCToolCalls, 0)})
+	execCtx.SetLimitDrain(true, 0)
+
+	child := execCtx.SpawnChild("child", newMockLoopData())
+	child.PublishBeforeToolCall("search", nil)
+
+	// Exceeded in both child and parent (stats propagate), but nothing is cancelled yet
+	assert.NotNil(t, child.ExceededLimit())
+	assert.NotNil(t, execCtx.ExceededLimit())
+	assert.NoError(t, child.Context().Err())
+	assert.NoError(t, execCtx.Context().Err())
+
+	execCtx.FinishLimitDrain()
+
+	assert.Error(t, child.Context().Err())
+	assert.Error(t, execCtx.Context().Err())
+}
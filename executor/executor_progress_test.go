@@ -0,0 +1,108 @@
+package executor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressFunc_NoProgressLimit(t *testing.T) {
+	type input struct {
+		progress    []bool // progress reported after iteration i+1; nil disables tracking
+		terminateAt int
+	}
+
+	type expected struct {
+		reason        gent.TerminationReason
+		iterations    int
+		gauge         float64
+		progressCalls int
+	}
+
+	// NoProgressLimit(2): exceeded by the third iteration in a row without progress
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "without progress func the gauge stays zero",
+			input:    input{terminateAt: 5},
+			expected: expected{reason: gent.TerminationSuccess, iterations: 5},
+		},
+		{
+			name: "stagnation for three iterations trips the limit",
+			input: input{
+				progress:    []bool{true, false, false, false, true},
+				terminateAt: 6,
+			},
+			expected: expected{
+				reason:        gent.TerminationLimitExceeded,
+				iterations:    4,
+				gauge:         3,
+				progressCalls: 4,
+			},
+		},
+		{
+			name: "progress resets the count",
+			input: input{
+				progress:    []bool{false, false, true, false, false},
+				terminateAt: 6,
+			},
+			expected: expected{
+				reason:        gent.TerminationSuccess,
+				iterations:    6,
+				gauge:         2,
+				progressCalls: 5,
+			},
+		},
+		{
+			name: "terminating iteration is not checked",
+			input: input{
+				progress:    []bool{false, false},
+				terminateAt: 3,
+			},
+			expected: expected{
+				reason:        gent.TerminationSuccess,
+				iterations:    3,
+				gauge:         2,
+				progressCalls: 2,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := executor.DefaultConfig()
+			progressCalls := 0
+			if tc.input.progress != nil {
+				config.ProgressFunc = func(execCtx *gent.ExecutionContext) bool {
+					progressCalls++
+					return tc.input.progress[execCtx.Iteration()-1]
+				}
+			}
+			loop := &mockAgentLoop{terminateAt: tc.input.terminateAt}
+			exec := executor.New[*mockLoopData](loop, config)
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+			execCtx.SetLimits([]gent.Limit{gent.NoProgressLimit(2)})
+			exec.Execute(execCtx)
+
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iterations, execCtx.Iteration())
+			assert.Equal(t, tc.expected.gauge,
+				execCtx.Stats().GetGauge(gent.SGNoProgressConsecutive))
+			assert.Equal(t, tc.expected.progressCalls, progressCalls)
+			if tc.expected.reason != gent.TerminationLimitExceeded {
+				assert.Nil(t, execCtx.ExceededLimit())
+				return
+			}
+			require.NotNil(t, execCtx.ExceededLimit())
+			assert.Equal(t, gent.SGNoProgressConsecutive, execCtx.ExceededLimitKey())
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// rawIORecord is one call captured by recordingSink.
+type rawIORecord struct {
+	execution string
+	model     string
+	request   []llms.MessageContent
+	response  string
+}
+
+// recordingSink implements gent.RawIOSink by keeping every call in memory.
+type recordingSink struct {
+	mu      sync.Mutex
+	records []rawIORecord
+}
+
+func (s *recordingSink) Record(
+	execCtx *gent.ExecutionContext,
+	model string,
+	request []llms.MessageContent,
+	response string,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rawIORecord{
+		execution: execCtx.Name(),
+		model:     model,
+		request:   request,
+		response:  response,
+	})
+}
+
+func TestExecute_RawIOSink(t *testing.T) {
+	request := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "You are helpful. api_key=secret"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "What is 6*7?"),
+	}
+	modelErr := errors.New("provider unavailable")
+
+	type input struct {
+		spawnChild bool
+		modelErr   error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected []rawIORecord
+	}{
+		{
+			name:  "records unparsed request and completion",
+			input: input{},
+			expected: []rawIORecord{
+				{
+					execution: "main",
+					model:     "primary",
+					request:   request,
+					response:  "<thinking>easy</thinking><answer>42</answer>",
+				},
+			},
+		},
+		{
+			name:  "records child context calls",
+			input: input{spawnChild: true},
+			expected: []rawIORecord{
+				{
+					execution: "main",
+					model:     "primary",
+					request:   request,
+					response:  "<thinking>easy</thinking><answer>42</answer>",
+				},
+				{
+					execution: "child",
+					model:     "helper",
+					request:   request,
+					response:  "<answer>done</answer>",
+				},
+			},
+		},
+		{
+			name:  "records failed call with empty response",
+			input: input{modelErr: modelErr},
+			expected: []rawIORecord{
+				{execution: "main", model: "primary", request: request},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			primary := tt.NewMockModel().WithName("primary")
+			if tc.input.modelErr != nil {
+				primary.AddError(tc.input.modelErr)
+			}
+			primary.AddResponse("<thinking>easy</thinking><answer>42</answer>", 10, 5)
+			helper := tt.NewMockModel().WithName("helper")
+
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					_, err := primary.GenerateContent(execCtx, "s", "t", request)
+					if err != nil {
+						return nil, err
+					}
+					if tc.input.spawnChild {
+						child := execCtx.SpawnChild("child", nil)
+						_, _ = helper.GenerateContent(child, "s", "t", request)
+						execCtx.CompleteChild(child)
+					}
+					return tt.Terminate("42"), nil
+				},
+			}
+
+			sink := &recordingSink{}
+			execCtx := gent.NewExecutionContext(context.Background(), "main", newMockLoopData())
+			executor.New[*mockLoopData](loop, executor.Config{RawIOSink: sink}).
+				Execute(execCtx)
+
+			assert.Equal(t, tc.expected, sink.records)
+		})
+	}
+}
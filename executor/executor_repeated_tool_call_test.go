@@ -0,0 +1,91 @@
+package executor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_RepeatedToolCall(t *testing.T) {
+	type expected struct {
+		reason     gent.TerminationReason
+		iterations int
+		repeated   []gent.RepeatedToolCallAction
+	}
+
+	tests := []struct {
+		name     string
+		input    executor.Config
+		expected expected
+	}{
+		{
+			name:  "disabled by default",
+			input: executor.Config{},
+			expected: expected{
+				reason:     gent.TerminationSuccess,
+				iterations: 4,
+			},
+		},
+		{
+			name:  "nudge keeps executing",
+			input: executor.Config{RepeatedToolCallThreshold: 3},
+			expected: expected{
+				reason:     gent.TerminationSuccess,
+				iterations: 4,
+				repeated: []gent.RepeatedToolCallAction{
+					gent.RepeatedToolCallNudge, gent.RepeatedToolCallNudge,
+				},
+			},
+		},
+		{
+			name: "terminate stops after the iteration",
+			input: executor.Config{
+				RepeatedToolCallThreshold: 3,
+				RepeatedToolCallAction:    gent.RepeatedToolCallTerminate,
+			},
+			expected: expected{
+				reason:     gent.TerminationRepeatedToolCall,
+				iterations: 3,
+				repeated:   []gent.RepeatedToolCallAction{gent.RepeatedToolCallTerminate},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]any{"query": "weather"}
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					if execCtx.CheckRepeatedToolCall("search", args) == nil {
+						execCtx.PublishAfterToolCall("search", args, "sunny", 0, nil)
+					}
+					if execCtx.Iteration() == 4 {
+						return tt.Terminate("done"), nil
+					}
+					return tt.ContinueWithPrompt(mockObservation), nil
+				},
+			}
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", newMockLoopData())
+			executor.New[*mockLoopData](loop, tc.input).Execute(execCtx)
+
+			assert.Equal(t, tc.expected.reason, execCtx.TerminationReason())
+			assert.Equal(t, tc.expected.iterations, execCtx.Iteration())
+
+			var repeated []gent.RepeatedToolCallAction
+			for _, event := range execCtx.Events() {
+				if e, ok := event.(*gent.RepeatedToolCallEvent); ok {
+					repeated = append(repeated, e.Action)
+				}
+			}
+			assert.Equal(t, tc.expected.repeated, repeated)
+			if tc.expected.reason == gent.TerminationRepeatedToolCall {
+				assert.ErrorIs(t, execCtx.Error(), gent.ErrRepeatedToolCall)
+			}
+		})
+	}
+}
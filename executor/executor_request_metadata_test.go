@@ -0,0 +1,102 @@
+package executor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// metadataModel wraps a model and captures the request metadata of each call, read
+// both from the ExecutionContext and from its context.Context.
+type metadataModel struct {
+	next        gent.Model
+	fromExecCtx []map[string]string
+	fromGoCtx   []map[string]string
+}
+
+func (m *metadataModel) GenerateContent(
+	execCtx *gent.ExecutionContext,
+	streamId string,
+	streamTopicId string,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*gent.ContentResponse, error) {
+	m.fromExecCtx = append(m.fromExecCtx, execCtx.RequestMetadata())
+	m.fromGoCtx = append(m.fromGoCtx, gent.RequestMetadataFrom(execCtx.Context()))
+	return m.next.GenerateContent(execCtx, streamId, streamTopicId, messages, options...)
+}
+
+func TestExecute_RequestMetadata(t *testing.T) {
+	tenant := map[string]string{"tenant_id": "acme", "trace_id": "t-123", "tier": "pro"}
+
+	type input struct {
+		metadata      map[string]string
+		childMetadata map[string]string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected []map[string]string // parent call, then child call
+	}{
+		{
+			name:     "metadata reaches the model unchanged",
+			input:    input{metadata: tenant},
+			expected: []map[string]string{tenant, tenant},
+		},
+		{
+			name: "child overrides inherited metadata",
+			input: input{
+				metadata:      tenant,
+				childMetadata: map[string]string{"tenant_id": "acme", "tier": "batch"},
+			},
+			expected: []map[string]string{
+				tenant,
+				{"tenant_id": "acme", "tier": "batch"},
+			},
+		},
+		{
+			name:     "no metadata",
+			input:    input{},
+			expected: []map[string]string{nil, nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := &metadataModel{next: tt.NewMockModel()}
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					if _, err := model.GenerateContent(execCtx, "s", "t", nil); err != nil {
+						return nil, err
+					}
+					child := execCtx.SpawnChild("child", nil)
+					if tc.input.childMetadata != nil {
+						child.SetRequestMetadata(tc.input.childMetadata)
+					}
+					_, _ = model.GenerateContent(child, "s", "t", nil)
+					execCtx.CompleteChild(child)
+					return tt.Terminate("done"), nil
+				},
+			}
+
+			execCtx := gent.NewExecutionContext(context.Background(), "main", newMockLoopData())
+			metadata := make(map[string]string)
+			for k, v := range tc.input.metadata {
+				metadata[k] = v
+			}
+			execCtx.SetRequestMetadata(metadata)
+			metadata["tenant_id"] = "changed after set"
+
+			executor.New[*mockLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+
+			assert.Equal(t, tc.expected, model.fromExecCtx)
+			assert.Equal(t, tc.expected, model.fromGoCtx)
+		})
+	}
+}
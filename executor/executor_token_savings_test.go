@@ -0,0 +1,311 @@
+package executor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/executor"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// textIterationLoop appends an iteration with a 40-character
+// message (10 tokens for gent.CharTokenEstimator) to the
+// scratchpad on each call.
+type textIterationLoop struct {
+	calls       int
+	terminateAt int
+}
+
+func (l *textIterationLoop) Next(
+	execCtx *gent.ExecutionContext,
+) (*gent.AgentLoopResult, error) {
+	l.calls++
+
+	iter := &gent.Iteration{
+		Messages: []*gent.MessageContent{{
+			Role: llms.ChatMessageTypeAI,
+			Parts: []gent.ContentPart{
+				llms.TextContent{Text: strings.Repeat("x", 40)},
+			},
+		}},
+	}
+	execCtx.Data().SetScratchPad(
+		append(execCtx.Data().GetScratchPad(), iter),
+	)
+
+	if l.calls >= l.terminateAt {
+		return tt.Terminate("done"), nil
+	}
+	return tt.ContinueWithPrompt(mockObservation), nil
+}
+
+// iterationCountEstimator estimates 100 tokens per
+// iteration.
+type iterationCountEstimator struct{}
+
+func (iterationCountEstimator) EstimateTokens(
+	scratchpad []*gent.Iteration,
+) int {
+	return 100 * len(scratchpad)
+}
+
+func TestStats_CompactionTokensSaved(t *testing.T) {
+	type input struct {
+		estimator gent.TokenEstimator
+		keep      int // iterations kept by the strategy
+	}
+
+	type expected struct {
+		compactions []gent.Event
+		saved       int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "default estimator counts text",
+			input: input{keep: 1},
+			expected: expected{
+				// Compacts before iterations 2, 3 and 4
+				compactions: []gent.Event{
+					tt.CompactionWithTokens(1, 1, 1, 1, 10, 10),
+					tt.CompactionWithTokens(1, 2, 2, 1, 20, 10),
+					tt.CompactionWithTokens(1, 3, 2, 1, 20, 10),
+				},
+				saved: 20,
+			},
+		},
+		{
+			name: "custom estimator",
+			input: input{
+				estimator: iterationCountEstimator{},
+				keep:      1,
+			},
+			expected: expected{
+				compactions: []gent.Event{
+					tt.CompactionWithTokens(1, 1, 1, 1, 100, 100),
+					tt.CompactionWithTokens(1, 2, 2, 1, 200, 100),
+					tt.CompactionWithTokens(1, 3, 2, 1, 200, 100),
+				},
+				saved: 200,
+			},
+		},
+		{
+			name:  "compaction that grows the scratchpad is not counted",
+			input: input{keep: 2},
+			expected: expected{
+				compactions: []gent.Event{
+					tt.CompactionWithTokens(1, 1, 1, 2, 10, 20),
+					tt.CompactionWithTokens(1, 2, 3, 2, 30, 20),
+					tt.CompactionWithTokens(1, 3, 3, 2, 30, 20),
+				},
+				saved: 20,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Keeps the last keep iterations, duplicating the
+			// only one if the scratchpad is shorter
+			strategy := tt.NewMockCompactionStrategy().WithCompactFunc(
+				func(ctx *gent.ExecutionContext) error {
+					sp := ctx.Data().GetScratchPad()
+					for len(sp) < tc.input.keep {
+						sp = append(sp, sp[0])
+					}
+					ctx.Data().SetScratchPad(sp[len(sp)-tc.input.keep:])
+					return nil
+				},
+			)
+
+			parent := gent.NewExecutionContext(context.Background(), "parent", nil)
+			data := gent.NewBasicLoopData(&gent.Task{Text: "test"})
+			execCtx := parent.SpawnChild("child", data)
+			execCtx.SetLimits(nil)
+			trigger := tt.NewMockCompactionTrigger().WithShouldCompact(true, true, true)
+			execCtx.SetCompaction(trigger, strategy)
+			if tc.input.estimator != nil {
+				execCtx.SetTokenEstimator(tc.input.estimator)
+			}
+
+			loop := &textIterationLoop{terminateAt: 4}
+			executor.New[*gent.BasicLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+			require.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+
+			var compactions []gent.Event
+			for _, event := range execCtx.Events() {
+				if e, ok := event.(*gent.CompactionEvent); ok {
+					compactions = append(compactions, e)
+				}
+			}
+			tt.AssertEventsEqual(t, tc.expected.compactions, compactions)
+
+			assert.Equal(t, tc.expected.saved,
+				execCtx.Stats().GetCounter(gent.SCCompactionTokensSaved))
+			assert.Equal(t, tc.expected.saved,
+				parent.Stats().GetCounter(gent.SCCompactionTokensSaved),
+				"should propagate to parent")
+		})
+	}
+}
+
+func TestStats_CachedPromptTokens(t *testing.T) {
+	type expected struct {
+		cached         int64
+		cachedForModel int64
+		inputTokens    int64
+	}
+
+	tests := []struct {
+		name     string
+		input    []*gent.GenerationInfo
+		expected expected
+	}{
+		{
+			name: "provider reports cached tokens",
+			input: []*gent.GenerationInfo{
+				{InputTokens: 1000, OutputTokens: 50},
+				{InputTokens: 1200, OutputTokens: 40, CachedInputTokens: 900},
+				{InputTokens: 1300, OutputTokens: 30, CachedInputTokens: 1100},
+			},
+			expected: expected{
+				cached:         2000,
+				cachedForModel: 2000,
+				inputTokens:    3500,
+			},
+		},
+		{
+			name: "provider without cached token data",
+			input: []*gent.GenerationInfo{
+				{InputTokens: 1000, OutputTokens: 50},
+				{InputTokens: 1200, OutputTokens: 40},
+			},
+			expected: expected{
+				inputTokens: 2200,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().WithName("cached-model")
+			for _, info := range tc.input {
+				model.AddRawResponse(&gent.ContentResponse{
+					Choices: []*gent.ContentChoice{{Content: "response"}},
+					Info:    info,
+				})
+			}
+
+			parent := gent.NewExecutionContext(context.Background(), "parent", nil)
+			execCtx := parent.SpawnChild("child", newMockLoopData())
+			execCtx.SetLimits(nil)
+
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					if _, err := model.GenerateContent(execCtx, "", "", nil); err != nil {
+						return nil, err
+					}
+					if execCtx.Iteration() == len(tc.input) {
+						return tt.Terminate("done"), nil
+					}
+					return tt.ContinueWithPrompt(mockObservation), nil
+				},
+			}
+			executor.New[*mockLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+			require.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+
+			for _, stats := range []*gent.ExecutionStats{execCtx.Stats(), parent.Stats()} {
+				assert.Equal(t, tc.expected.cached,
+					stats.GetCounter(gent.SCCachedPromptTokens))
+				assert.Equal(t, tc.expected.cachedForModel,
+					stats.GetCounter(gent.SCCachedPromptTokensFor+"cached-model"))
+				assert.Equal(t, tc.expected.inputTokens, stats.GetTotalInputTokens())
+			}
+		})
+	}
+}
+
+func TestStats_ReasoningTokens(t *testing.T) {
+	type expected struct {
+		reasoning         int64
+		reasoningForModel int64
+		outputTokens      int64
+	}
+
+	tests := []struct {
+		name     string
+		input    []*gent.GenerationInfo
+		expected expected
+	}{
+		{
+			name: "provider reports reasoning tokens",
+			input: []*gent.GenerationInfo{
+				{InputTokens: 1000, OutputTokens: 500, ReasoningTokens: 450},
+				{InputTokens: 1200, OutputTokens: 40},
+				{InputTokens: 1300, OutputTokens: 300, ReasoningTokens: 250},
+			},
+			expected: expected{
+				reasoning:         700,
+				reasoningForModel: 700,
+				outputTokens:      840,
+			},
+		},
+		{
+			name: "provider without reasoning token data",
+			input: []*gent.GenerationInfo{
+				{InputTokens: 1000, OutputTokens: 50},
+				{InputTokens: 1200, OutputTokens: 40},
+			},
+			expected: expected{
+				outputTokens: 90,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := tt.NewMockModel().WithName("reasoning-model")
+			for _, info := range tc.input {
+				model.AddRawResponse(&gent.ContentResponse{
+					Choices: []*gent.ContentChoice{{Content: "response"}},
+					Info:    info,
+				})
+			}
+
+			parent := gent.NewExecutionContext(context.Background(), "parent", nil)
+			execCtx := parent.SpawnChild("child", newMockLoopData())
+			execCtx.SetLimits(nil)
+
+			loop := &mockAgentLoop{
+				nextFn: func(execCtx *gent.ExecutionContext) (*gent.AgentLoopResult, error) {
+					if _, err := model.GenerateContent(execCtx, "", "", nil); err != nil {
+						return nil, err
+					}
+					if execCtx.Iteration() == len(tc.input) {
+						return tt.Terminate("done"), nil
+					}
+					return tt.ContinueWithPrompt(mockObservation), nil
+				},
+			}
+			executor.New[*mockLoopData](loop, executor.DefaultConfig()).Execute(execCtx)
+			require.Equal(t, gent.TerminationSuccess, execCtx.TerminationReason())
+
+			for _, stats := range []*gent.ExecutionStats{execCtx.Stats(), parent.Stats()} {
+				assert.Equal(t, tc.expected.reasoning,
+					stats.GetCounter(gent.SCReasoningTokens))
+				assert.Equal(t, tc.expected.reasoningForModel,
+					stats.GetCounter(gent.SCReasoningTokensFor+"reasoning-model"))
+				assert.Equal(t, tc.expected.outputTokens, stats.GetTotalOutputTokens())
+			}
+		})
+	}
+}
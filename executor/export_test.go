@@ -0,0 +1,8 @@
+package executor
+
+// PendingCompactions returns how many background compactions the executor tracks.
+func (e *Executor[Data]) PendingCompactions() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.pending)
+}
@@ -76,7 +76,9 @@ type FormattedSection struct {
 //   - Stats are auto-updated when the event is published
 //
 // On successful parse:
-//   - Call execCtx.Stats().ResetGauge(SGFormatParseErrorConsecutive)
+//   - Call execCtx.Stats().ResetGauge(SGFormatParseErrorConsecutive) and
+//     ResetGauge(SGFormatParseErrorIdenticalConsecutive)
+//   - Call execCtx.RecordSectionBytes(sections) to update the SGSectionBytesFor gauges
 //
 // Example implementation:
 //
@@ -90,6 +92,8 @@ type FormattedSection struct {
 //	    }
 //	    if execCtx != nil {
 //	        execCtx.Stats().ResetGauge(SGFormatParseErrorConsecutive)
+//	        execCtx.Stats().ResetGauge(SGFormatParseErrorIdenticalConsecutive)
+//	        execCtx.RecordSectionBytes(result)
 //	    }
 //	    return result, nil
 //	}
@@ -98,6 +102,8 @@ type FormattedSection struct {
 //
 //   - format.NewXML(): XML-style tags (<section>content</section>)
 //   - format.NewMarkdown(): Markdown headers (# Section)
+//   - format.NewJSON(): Single JSON object keyed by section name
+//   - format.NewAuto(): A primary format that falls back to others when parsing
 //
 // See: [TextSection] for section definitions.
 type TextFormat interface {
@@ -132,6 +138,56 @@ type TextFormat interface {
 	FormatSections(sections []FormattedSection) string
 }
 
+// SectionRemover is an optional extension of [TextFormat] for formats that can remove
+// sections from raw output while leaving the rest of it intact. Agent loops use it to
+// drop [EphemeralSection] content from the scratchpad.
+type SectionRemover interface {
+	TextFormat
+
+	// RemoveSections returns output with every instance of the named sections removed.
+	// Section names are matched case-insensitively. Output that cannot be parsed is
+	// returned unchanged.
+	RemoveSections(output string, names ...string) string
+}
+
+// StopSequenceFormat is an optional extension of [TextFormat] for formats that can
+// advise stop sequences for the model request. Agents pass them to the model so it stops
+// generating once the response is complete, e.g. instead of going on to imagine the
+// observation that follows an action. Formats that do not implement it advise none.
+type StopSequenceFormat interface {
+	TextFormat
+
+	// StopSequences returns the strings at which the model should stop generating.
+	// Returns nil if the format has no stops configured.
+	StopSequences() []string
+}
+
+// FormatStopSequences returns the stop sequences advised by f, or nil if f does not
+// implement [StopSequenceFormat].
+func FormatStopSequences(f TextFormat) []string {
+	if stopFormat, ok := f.(StopSequenceFormat); ok {
+		return stopFormat.StopSequences()
+	}
+	return nil
+}
+
+// RecordSectionBytes sets the [SGSectionBytesFor] gauge of each section to the byte
+// length of its content in sections, the result of TextFormat.Parse. Gauges of sections
+// absent from sections are reset to 0. Limits on these gauges are checked immediately.
+//
+// TextFormat implementations call this after each successful parse.
+func (ctx *ExecutionContext) RecordSectionBytes(sections map[string][]string) {
+	stats := ctx.Stats()
+	stats.resetGaugesByPrefix(SGSectionBytesFor)
+	for name, contents := range sections {
+		var size int
+		for _, content := range contents {
+			size += len(content)
+		}
+		stats.SetGauge(SGSectionBytesFor+StatKey(name), float64(size))
+	}
+}
+
 // TextOutputFormat is an alias for TextFormat for backward compatibility.
 // Deprecated: Use TextFormat instead.
 type TextOutputFormat = TextFormat
@@ -143,5 +199,8 @@ var (
 	ErrInvalidYAML     = errors.New("invalid YAML in section content")
 	ErrMissingToolName = errors.New("tool call missing 'tool' field")
 	ErrUnknownTool     = errors.New("unknown tool")
+	ErrToolUnavailable = errors.New("tool not available")
 	ErrInvalidToolArgs = errors.New("invalid tool arguments")
+
+	ErrDuplicateSection = errors.New("section appears more than once")
 )
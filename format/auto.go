@@ -0,0 +1,164 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rickchristie/gent"
+)
+
+// Auto implements [gent.TextFormat] by parsing with a primary format and falling back
+// to other formats when the output contains none of the primary format's sections.
+//
+// Use Auto when one agent serves several model families and some of them ignore the
+// format instructions, e.g. answering with markdown headers when asked for XML tags.
+// The model is always instructed with the primary format; the fallbacks only make
+// parsing forgiving.
+//
+// # Creating and Configuring
+//
+//	// Ask for XML, but accept markdown
+//	textFormat := format.NewAuto(format.NewXML(), format.NewMarkdown())
+//
+//	agent := react.NewAgent(model).
+//	    WithTextFormat(textFormat)
+//
+// # Parsing Behavior
+//
+// Parse tries the primary format first. Only when it fails with
+// [gent.ErrNoSectionsFound] are the fallbacks tried, in order; the first one that
+// parses the output wins and a [gent.FormatFallbackEvent] reports which format
+// matched. Other primary errors (e.g. [ErrAmbiguousTags] in strict mode) are returned
+// as-is, since the output did use the primary format.
+//
+// When no format matches, the primary format's error is returned. Auto publishes the
+// single resulting ParseErrorEvent; the wrapped formats are not given the
+// ExecutionContext, so failed attempts are not reported individually.
+//
+// # Other Methods
+//
+//   - RegisterSection registers the section with every format.
+//   - DescribeStructure and FormatSections use the primary format, so observations
+//     are presented in the format the model is asked to use.
+//   - RemoveSections uses the format that parses the output.
+type Auto struct {
+	primary   gent.TextFormat
+	fallbacks []gent.TextFormat
+}
+
+// NewAuto creates a format that parses with primary and falls back to fallbacks, in
+// order, when the output contains none of primary's sections.
+func NewAuto(primary gent.TextFormat, fallbacks ...gent.TextFormat) *Auto {
+	return &Auto{
+		primary:   primary,
+		fallbacks: fallbacks,
+	}
+}
+
+// RegisterSection adds a section to the primary and all fallback formats.
+// Returns self for chaining.
+func (f *Auto) RegisterSection(section gent.TextSection) gent.TextFormat {
+	f.primary.RegisterSection(section)
+	for _, fallback := range f.fallbacks {
+		fallback.RegisterSection(section)
+	}
+	return f
+}
+
+// DescribeStructure returns the primary format's structure description.
+func (f *Auto) DescribeStructure() string {
+	return f.primary.DescribeStructure()
+}
+
+// FormatSections formats sections with the primary format.
+func (f *Auto) FormatSections(sections []gent.FormattedSection) string {
+	return f.primary.FormatSections(sections)
+}
+
+// Parse extracts raw content for each section from the LLM output, trying the
+// fallback formats when the primary format finds no sections.
+func (f *Auto) Parse(execCtx *gent.ExecutionContext, output string) (map[string][]string, error) {
+	result, matched, err := f.doParse(output)
+	if err != nil {
+		// Publish parse error event (auto-updates stats)
+		if execCtx != nil {
+			execCtx.PublishParseError(gent.ParseErrorTypeFormat, output, err)
+		}
+		return nil, err
+	}
+
+	if execCtx != nil {
+		if matched != f.primary {
+			execCtx.PublishFormatFallback(FormatName(f.primary), FormatName(matched))
+		}
+		// Successful parse - reset consecutive error gauges and record section sizes
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+		execCtx.RecordSectionBytes(result)
+	}
+
+	return result, nil
+}
+
+// doParse performs the actual parsing logic and returns the format that matched.
+func (f *Auto) doParse(output string) (map[string][]string, gent.TextFormat, error) {
+	result, err := f.primary.Parse(nil, output)
+	if err == nil {
+		return result, f.primary, nil
+	}
+	if !errors.Is(err, gent.ErrNoSectionsFound) {
+		return nil, nil, err
+	}
+
+	for _, fallback := range f.fallbacks {
+		if fallbackResult, fallbackErr := fallback.Parse(nil, output); fallbackErr == nil {
+			return fallbackResult, fallback, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// RemoveSections removes the named sections using the format that parses output.
+// Output that no format parses, or whose format cannot remove sections, is returned
+// unchanged.
+func (f *Auto) RemoveSections(output string, names ...string) string {
+	_, matched, err := f.doParse(output)
+	if err != nil {
+		return output
+	}
+	remover, ok := matched.(gent.SectionRemover)
+	if !ok {
+		return output
+	}
+	return remover.RemoveSections(output, names...)
+}
+
+// StopSequences returns the primary format's stop sequences, since the prompt describes
+// the primary format.
+func (f *Auto) StopSequences() []string {
+	return gent.FormatStopSequences(f.primary)
+}
+
+// FormatName returns a short name for a format, used in [gent.FormatFallbackEvent]:
+// "xml", "markdown", "json" or "auto" for the formats in this package, and the Go
+// type name for others.
+func FormatName(f gent.TextFormat) string {
+	switch f.(type) {
+	case *XML:
+		return "xml"
+	case *Markdown:
+		return "markdown"
+	case *JSON:
+		return "json"
+	case *Auto:
+		return "auto"
+	default:
+		return fmt.Sprintf("%T", f)
+	}
+}
+
+// Compile-time check that Auto implements gent.SectionRemover.
+var _ gent.SectionRemover = (*Auto)(nil)
+
+// Compile-time check that Auto implements gent.StopSequenceFormat.
+var _ gent.StopSequenceFormat = (*Auto)(nil)
@@ -0,0 +1,190 @@
+package format
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuto_Parse(t *testing.T) {
+	type input struct {
+		format func() *Auto
+		output string
+	}
+
+	type expected struct {
+		sections    map[string][]string
+		err         error
+		events      []gent.Event
+		errorsTotal int64
+	}
+
+	xmlFirst := func() *Auto { return NewAuto(NewXML(), NewMarkdown()) }
+	markdownFirst := func() *Auto { return NewAuto(NewMarkdown(), NewXML()) }
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "primary xml matches",
+			input: input{
+				format: xmlFirst,
+				output: "<thinking>\nLook it up.\n</thinking>\n<answer>\nSunny.\n</answer>",
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"Look it up."},
+					"answer":   {"Sunny."},
+				},
+			},
+		},
+		{
+			name: "markdown falls back when xml was asked for",
+			input: input{
+				format: xmlFirst,
+				output: "# Thinking\nLook it up.\n\n# Answer\nSunny.",
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"Look it up."},
+					"answer":   {"Sunny."},
+				},
+				events: []gent.Event{tt.FormatFallback(0, 1, "xml", "markdown")},
+			},
+		},
+		{
+			name: "xml falls back when markdown was asked for",
+			input: input{
+				format: markdownFirst,
+				output: "<answer>\nSunny.\n</answer>",
+			},
+			expected: expected{
+				sections: map[string][]string{"answer": {"Sunny."}},
+				events:   []gent.Event{tt.FormatFallback(0, 1, "markdown", "xml")},
+			},
+		},
+		{
+			name: "no format matches",
+			input: input{
+				format: xmlFirst,
+				output: "The weather is sunny.",
+			},
+			expected: expected{
+				err:         gent.ErrNoSectionsFound,
+				errorsTotal: 1,
+			},
+		},
+		{
+			name: "primary error other than no sections is returned",
+			input: input{
+				format: func() *Auto { return NewAuto(NewXML().WithStrict(true), NewMarkdown()) },
+				output: "<thinking>\nWrite <answer>x</answer>\n</thinking>",
+			},
+			expected: expected{
+				err:         ErrAmbiguousTags,
+				errorsTotal: 1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format := tc.input.format()
+			format.RegisterSection(&mockSection{name: "thinking"})
+			format.RegisterSection(&mockSection{name: "answer"})
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+
+			sections, err := format.Parse(execCtx, tc.input.output)
+
+			assert.ErrorIs(t, err, tc.expected.err)
+			assert.Equal(t, tc.expected.sections, sections)
+
+			var fallbacks []gent.Event
+			for _, event := range execCtx.Events() {
+				if _, ok := event.(*gent.FormatFallbackEvent); ok {
+					fallbacks = append(fallbacks, event)
+				}
+			}
+			tt.AssertEventsEqual(t, tc.expected.events, fallbacks)
+			assert.Equal(t, int64(len(tc.expected.events)),
+				execCtx.Stats().GetCounter(gent.SCFormatFallbacks))
+			assert.Equal(t, tc.expected.errorsTotal,
+				execCtx.Stats().GetCounter(gent.SCFormatParseErrorTotal))
+		})
+	}
+}
+
+func TestAuto_UsesPrimaryForInstructions(t *testing.T) {
+	primary := NewXML()
+	format := NewAuto(primary, NewMarkdown())
+	format.RegisterSection(&mockSection{name: "answer", guidance: "Answer here"})
+
+	assert.Equal(t, primary.DescribeStructure(), format.DescribeStructure())
+
+	sections := []gent.FormattedSection{{Name: "observation", Content: "done"}}
+	assert.Equal(t, primary.FormatSections(sections), format.FormatSections(sections))
+}
+
+func TestAuto_RemoveSections(t *testing.T) {
+	format := NewAuto(NewXML(), NewMarkdown())
+	format.RegisterSection(&mockSection{name: "thinking"})
+	format.RegisterSection(&mockSection{name: "answer"})
+
+	output := format.RemoveSections("# Thinking\nLook it up.\n\n# Answer\nSunny.", "thinking")
+
+	sections, err := format.Parse(nil, output)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"answer": {"Sunny."}}, sections)
+	assert.Equal(t, "no sections", format.RemoveSections("no sections", "thinking"))
+}
+
+func TestFormatStopSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    gent.TextFormat
+		expected []string
+	}{
+		{
+			name:     "xml without stop sections",
+			input:    NewXML(),
+			expected: nil,
+		},
+		{
+			name:     "xml stops at opening tags",
+			input:    NewXML().WithStopSections("observation", "result"),
+			expected: []string{"<observation>", "<result>"},
+		},
+		{
+			name:     "markdown stops at top-level headers",
+			input:    NewMarkdown().WithStopSections("observation"),
+			expected: []string{"\n# observation"},
+		},
+		{
+			name: "auto uses primary format stops",
+			input: NewAuto(
+				NewXML().WithStopSections("observation"),
+				NewMarkdown().WithStopSections("observation"),
+			),
+			expected: []string{"<observation>"},
+		},
+		{
+			name:     "json advises no stops",
+			input:    NewJSON(),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, gent.FormatStopSequences(tc.input))
+		})
+	}
+}
@@ -13,6 +13,8 @@
 //
 //   - [XML]: XML-style tags (<section>content</section>) - recommended for most use cases
 //   - [Markdown]: Markdown headers (# Section) - for markdown-native models
+//   - [JSON]: A single JSON object keyed by section - for structured output models
+//   - [Auto]: A primary format with fallbacks - for models that ignore instructions
 //
 // # Choosing a Format
 //
@@ -26,6 +28,11 @@
 //   - You prefer markdown-style output aesthetics
 //   - The sections won't be referenced in content
 //
+// Use [JSON] when:
+//   - The model supports constrained decoding (e.g., Ollama's JSON mode)
+//   - Small local models frequently break the XML or Markdown envelope
+//   - You pair it with toolchain.JSON so tool calls are plain JSON values
+//
 // # Example Usage
 //
 //	// XML format (recommended)
@@ -36,6 +43,10 @@
 //	agent := react.NewAgent(model).
 //	    WithTextFormat(format.NewMarkdown())
 //
+//	// Ask for XML, but also accept markdown
+//	agent := react.NewAgent(model).
+//	    WithTextFormat(format.NewAuto(format.NewXML(), format.NewMarkdown()))
+//
 // # Section Registration
 //
 // Sections are typically registered automatically when you configure
@@ -54,6 +65,25 @@
 //	    // Feed error back to model if within retry limits
 //	}
 //
+// # Stop Sequences
+//
+// Models sometimes keep generating after their response is complete, typically by
+// imagining the observation that follows a tool call. [XML.WithStopSections] and
+// [Markdown.WithStopSections] advise the markers of such sections as stop sequences
+// (see [gent.StopSequenceFormat]), which the agent passes to the model request:
+//
+//	agent := react.NewAgent(model).
+//	    WithFormat(format.NewXML().WithStopSections("observation"))
+//
+// [JSON] advises none, since stopping inside the object would leave invalid JSON.
+//
+// # Literal Markup in Sections
+//
+// [XML.WithCDATA] makes the named sections carry their content in <![CDATA[ ... ]]>
+// blocks, so text such as a quoted "</answer>" in thinking can't split sections:
+//
+//	textFormat := format.NewXML().WithCDATA("thinking")
+//
 // # Custom Formats
 //
 // Implement [gent.TextFormat] to create custom output formats:
@@ -77,6 +107,7 @@
 //	) (map[string][]string, error) {
 //	    // Parse output into section map
 //	    // Publish errors via execCtx.PublishParseError() if execCtx != nil
+//	    // On success, record section sizes via execCtx.RecordSectionBytes()
 //	}
 //
 //	func (f *MyFormat) FormatSections(sections []gent.FormattedSection) string {
@@ -0,0 +1,366 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rickchristie/gent"
+)
+
+// JSON implements [gent.TextFormat] where the whole model output is a single JSON object
+// keyed by section name.
+//
+// JSON format is designed for models that support provider-side constrained decoding
+// (structured output), such as local models served by Ollama. It implements
+// [gent.StructuredOutputFormat], so agents pass the combined output schema to models
+// implementing [gent.StructuredOutputModel], constraining generation to valid output.
+// For models without that capability the schema is still embedded in the prompt via
+// DescribeStructure.
+//
+// # Creating and Configuring
+//
+//	textFormat := format.NewJSON()
+//
+// # Example LLM Output
+//
+//	{
+//	  "thinking": "I need to search for the weather in Tokyo.",
+//	  "action": {"tool": "search", "args": {"query": "weather in tokyo"}}
+//	}
+//
+// # Parsing Behavior
+//
+// Keys are matched case-insensitively against registered sections. String values are
+// returned as-is; any other value (object, array, number, boolean) is returned as its
+// raw JSON text so JSON sections and toolchains can parse it:
+//
+//	sections, _ := textFormat.Parse(execCtx, llmOutput)
+//	// sections["thinking"] = ["I need to search..."]
+//	// sections["action"] = [`{"tool": "search", "args": {"query": "weather in tokyo"}}`]
+//
+// Null and empty-string values are treated as absent sections.
+//
+// # Section Schemas
+//
+// Sections implementing [gent.SchemaSection] (e.g., toolchain.JSON, termination.JSON,
+// section.JSON) contribute their own schema to OutputSchema. All other sections are
+// described as strings. Pair this format with the JSON toolchain, since YAML tool calls
+// can't be expressed as a constrained JSON value.
+//
+// # Using with Agent
+//
+//	model := models.NewLCGWrapper(ollamaLLM).WithStructuredOutput(true)
+//	agent := react.NewAgent(model).
+//	    WithFormat(format.NewJSON()).
+//	    WithToolChain(toolchain.NewJSON().RegisterTool(searchTool)).
+//	    WithTermination(termination.NewText("answer"))
+type JSON struct {
+	sections      []gent.TextSection
+	knownSections map[string]string // lowercase key -> original name
+}
+
+// NewJSON creates a new JSON format.
+func NewJSON() *JSON {
+	return &JSON{
+		sections:      make([]gent.TextSection, 0),
+		knownSections: make(map[string]string),
+	}
+}
+
+// RegisterSection adds a section to the format.
+// If a section with the same name already exists, it is not added again.
+// Returns self for chaining.
+func (f *JSON) RegisterSection(section gent.TextSection) gent.TextFormat {
+	lowerName := strings.ToLower(section.Name())
+	if _, exists := f.knownSections[lowerName]; exists {
+		return f // Already registered
+	}
+	f.sections = append(f.sections, section)
+	f.knownSections[lowerName] = section.Name() // Store original name
+	return f
+}
+
+// OutputSchema returns the JSON Schema for the complete output object. Each registered
+// section becomes an optional property. Returns nil if no sections are registered.
+// Implements [gent.StructuredOutputFormat].
+func (f *JSON) OutputSchema() map[string]any {
+	if len(f.sections) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]any, len(f.sections))
+	for _, section := range f.sections {
+		if schemaSection, ok := section.(gent.SchemaSection); ok {
+			properties[section.Name()] = schemaSection.JSONSchema()
+		} else {
+			properties[section.Name()] = map[string]any{"type": "string"}
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// DescribeStructure generates the prompt explaining the output format structure.
+// It lists each section's key with its guidance, followed by the output JSON Schema.
+// The schema is always embedded so models without structured output support can
+// still follow it.
+func (f *JSON) DescribeStructure() string {
+	if len(f.sections) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Respond with a single JSON object. Use one key per section, ")
+	sb.WriteString("and only include the sections relevant to this response:\n\n")
+
+	for _, section := range f.sections {
+		fmt.Fprintf(&sb, "\"%s\":\n%s\n\n", section.Name(), section.Guidance())
+	}
+
+	schemaJSON, err := json.MarshalIndent(f.OutputSchema(), "", "  ")
+	if err == nil {
+		sb.WriteString("The response object must match this JSON Schema:\n")
+		sb.Write(schemaJSON)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// FormatSections formats sections as a JSON object, preserving section order.
+//
+// A section without children becomes a string value, or is embedded verbatim when its
+// content is already a JSON object or array. A section with children becomes a nested
+// object, with its own content (if any) under the "content" key. Sections sharing a
+// name are collected into an array.
+func (f *JSON) FormatSections(sections []gent.FormattedSection) string {
+	if len(sections) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	f.writeObject(&buf, "", sections)
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return buf.String()
+	}
+	return indented.String()
+}
+
+// writeObject writes sections as a JSON object. When content is non-empty it is
+// written first under the "content" key.
+func (f *JSON) writeObject(buf *bytes.Buffer, content string, sections []gent.FormattedSection) {
+	// Group by name, keeping the order of first appearance.
+	var order []string
+	groups := make(map[string][]gent.FormattedSection)
+	for _, section := range sections {
+		if _, exists := groups[section.Name]; !exists {
+			order = append(order, section.Name)
+		}
+		groups[section.Name] = append(groups[section.Name], section)
+	}
+
+	buf.WriteByte('{')
+	first := true
+	if content != "" {
+		buf.WriteString(`"content":`)
+		f.writeContent(buf, content)
+		first = false
+	}
+	for _, name := range order {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		writeJSONString(buf, name)
+		buf.WriteByte(':')
+
+		group := groups[name]
+		if len(group) == 1 {
+			f.writeSection(buf, group[0])
+			continue
+		}
+		buf.WriteByte('[')
+		for i, section := range group {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			f.writeSection(buf, section)
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte('}')
+}
+
+// writeSection writes a single section value.
+func (f *JSON) writeSection(buf *bytes.Buffer, section gent.FormattedSection) {
+	if len(section.Children) > 0 {
+		f.writeObject(buf, section.Content, section.Children)
+		return
+	}
+	f.writeContent(buf, section.Content)
+}
+
+// writeContent writes content verbatim if it is a JSON object or array, otherwise as a
+// JSON string.
+func (f *JSON) writeContent(buf *bytes.Buffer, content string) {
+	trimmed := strings.TrimSpace(content)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) &&
+		json.Valid([]byte(trimmed)) {
+		buf.WriteString(trimmed)
+		return
+	}
+	writeJSONString(buf, content)
+}
+
+// writeJSONString writes s as a JSON string literal without HTML escaping.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var tmp bytes.Buffer
+	enc := json.NewEncoder(&tmp)
+	enc.SetEscapeHTML(false)
+	// Encoding a string never fails.
+	_ = enc.Encode(s)
+	buf.Write(bytes.TrimSuffix(tmp.Bytes(), []byte("\n")))
+}
+
+// Parse extracts raw content for each section from the LLM output.
+func (f *JSON) Parse(
+	execCtx *gent.ExecutionContext,
+	output string,
+) (map[string][]string, error) {
+	result, err := f.doParse(output)
+	if err != nil {
+		// Publish parse error event (auto-updates stats)
+		if execCtx != nil {
+			execCtx.PublishParseError(gent.ParseErrorTypeFormat, output, err)
+		}
+		return nil, err
+	}
+
+	// Successful parse - reset consecutive error gauges and record section sizes
+	if execCtx != nil {
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+		execCtx.RecordSectionBytes(result)
+	}
+
+	return result, nil
+}
+
+// doParse performs the actual parsing logic.
+func (f *JSON) doParse(output string) (map[string][]string, error) {
+	content := stripCodeFence(strings.TrimSpace(output))
+	if content == "" {
+		return nil, gent.ErrNoSectionsFound
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", gent.ErrInvalidJSON, err)
+	}
+
+	// Iterate keys in sorted order so duplicate case-variants resolve deterministically.
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string][]string)
+	for _, key := range keys {
+		// If knownSections is populated, skip unrecognized sections and use original name
+		resultKey := key
+		if len(f.knownSections) > 0 {
+			originalName, exists := f.knownSections[strings.ToLower(key)]
+			if !exists {
+				continue
+			}
+			resultKey = originalName
+		}
+
+		value := bytes.TrimSpace(raw[key])
+		if bytes.Equal(value, []byte("null")) {
+			continue
+		}
+
+		var sectionContent string
+		if len(value) > 0 && value[0] == '"' {
+			if err := json.Unmarshal(value, &sectionContent); err != nil {
+				return nil, fmt.Errorf("%w: %v", gent.ErrInvalidJSON, err)
+			}
+			sectionContent = strings.TrimSpace(sectionContent)
+		} else {
+			sectionContent = string(value)
+		}
+
+		if sectionContent != "" {
+			result[resultKey] = append(result[resultKey], sectionContent)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, gent.ErrNoSectionsFound
+	}
+
+	return result, nil
+}
+
+// RemoveSections returns output with the named keys removed from the JSON object. The
+// remaining object is re-encoded compactly with keys in sorted order.
+func (f *JSON) RemoveSections(output string, names ...string) string {
+	var raw map[string]json.RawMessage
+	content := stripCodeFence(strings.TrimSpace(output))
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return output
+	}
+
+	removed := false
+	for key := range raw {
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				delete(raw, key)
+				removed = true
+				break
+			}
+		}
+	}
+	if !removed {
+		return output
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return output
+	}
+	return string(encoded)
+}
+
+// stripCodeFence removes a surrounding markdown code fence (```json ... ```), which
+// models without constrained decoding frequently add.
+func stripCodeFence(content string) string {
+	if !strings.HasPrefix(content, "```") || !strings.HasSuffix(content, "```") {
+		return content
+	}
+	content = strings.TrimSuffix(content, "```")
+	newline := strings.Index(content, "\n")
+	if newline < 0 {
+		return ""
+	}
+	return strings.TrimSpace(content[newline+1:])
+}
+
+// Compile-time checks that JSON implements gent.StructuredOutputFormat and
+// gent.SectionRemover.
+var (
+	_ gent.StructuredOutputFormat = (*JSON)(nil)
+	_ gent.SectionRemover         = (*JSON)(nil)
+)
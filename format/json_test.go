@@ -0,0 +1,343 @@
+package format
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSchemaSection is a TextSection that also implements gent.SchemaSection.
+type mockSchemaSection struct {
+	mockSection
+	schema map[string]any
+}
+
+func (m *mockSchemaSection) JSONSchema() map[string]any { return m.schema }
+
+func TestJSON_Parse(t *testing.T) {
+	type input struct {
+		sections []string
+		output   string
+	}
+
+	type expected struct {
+		sections map[string][]string
+		err      error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "string and object values",
+			input: input{
+				sections: []string{"thinking", "action"},
+				output: `{"thinking": "I should search.",
+"action": {"tool": "search", "args": {"q": "weather"}}}`,
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"I should search."},
+					"action":   {`{"tool": "search", "args": {"q": "weather"}}`},
+				},
+			},
+		},
+		{
+			name: "array value kept as raw JSON",
+			input: input{
+				sections: []string{"action"},
+				output:   `{"action": [{"tool": "a"}, {"tool": "b"}]}`,
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"action": {`[{"tool": "a"}, {"tool": "b"}]`},
+				},
+			},
+		},
+		{
+			name: "keys matched case-insensitively, unknown keys skipped",
+			input: input{
+				sections: []string{"Answer"},
+				output:   `{"ANSWER": "42", "extra": "ignored"}`,
+			},
+			expected: expected{
+				sections: map[string][]string{"Answer": {"42"}},
+			},
+		},
+		{
+			name: "null and empty values treated as absent",
+			input: input{
+				sections: []string{"thinking", "action", "answer"},
+				output:   `{"thinking": "", "action": null, "answer": "done"}`,
+			},
+			expected: expected{
+				sections: map[string][]string{"answer": {"done"}},
+			},
+		},
+		{
+			name: "code fence stripped",
+			input: input{
+				sections: []string{"answer"},
+				output:   "```json\n{\"answer\": \"fenced\"}\n```",
+			},
+			expected: expected{
+				sections: map[string][]string{"answer": {"fenced"}},
+			},
+		},
+		{
+			name: "no known sections",
+			input: input{
+				sections: []string{"answer"},
+				output:   `{"other": "value"}`,
+			},
+			expected: expected{err: gent.ErrNoSectionsFound},
+		},
+		{
+			name: "empty output",
+			input: input{
+				sections: []string{"answer"},
+				output:   "   ",
+			},
+			expected: expected{err: gent.ErrNoSectionsFound},
+		},
+		{
+			name: "invalid JSON",
+			input: input{
+				sections: []string{"answer"},
+				output:   `<answer>not json</answer>`,
+			},
+			expected: expected{err: gent.ErrInvalidJSON},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := NewJSON()
+			for _, name := range tt.input.sections {
+				format.RegisterSection(&mockSection{name: name})
+			}
+
+			result, err := format.Parse(nil, tt.input.output)
+
+			assert.ErrorIs(t, err, tt.expected.err)
+			assert.Equal(t, tt.expected.sections, result)
+		})
+	}
+}
+
+func TestJSON_Parse_TracesErrors(t *testing.T) {
+	type expected struct {
+		shouldError       bool
+		formatErrorTotal  int64
+		formatErrorConsec float64
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name:  "parse error publishes ParseErrorEvent",
+			input: "not json",
+			expected: expected{
+				shouldError:       true,
+				formatErrorTotal:  1,
+				formatErrorConsec: 1,
+			},
+		},
+		{
+			name:  "successful parse resets consecutive gauge",
+			input: `{"answer": "hello"}`,
+			expected: expected{
+				shouldError:       false,
+				formatErrorTotal:  0,
+				formatErrorConsec: 0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := NewJSON()
+			format.RegisterSection(&mockSection{name: "answer"})
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+			if !tt.expected.shouldError {
+				execCtx.Stats().IncrGauge(gent.SGFormatParseErrorConsecutive, 1)
+			}
+
+			_, err := format.Parse(execCtx, tt.input)
+
+			if tt.expected.shouldError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			stats := execCtx.Stats()
+			assert.Equal(t, tt.expected.formatErrorTotal,
+				stats.GetCounter(gent.SCFormatParseErrorTotal))
+			assert.Equal(t, tt.expected.formatErrorConsec,
+				stats.GetGauge(gent.SGFormatParseErrorConsecutive))
+		})
+	}
+}
+
+func TestJSON_FormatSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []gent.FormattedSection
+		expected string
+	}{
+		{
+			name:     "empty",
+			input:    nil,
+			expected: "",
+		},
+		{
+			name: "content sections keep order",
+			input: []gent.FormattedSection{
+				{Name: "task", Content: "Find <x> & y"},
+				{Name: "a", Content: "1"},
+			},
+			expected: `{
+  "task": "Find <x> & y",
+  "a": "1"
+}`,
+		},
+		{
+			name: "children, JSON content and duplicate names",
+			input: []gent.FormattedSection{{
+				Name:    "observation",
+				Content: "results",
+				Children: []gent.FormattedSection{
+					{Name: "search", Content: `{"hits": 2}`},
+					{Name: "search", Content: "plain"},
+				},
+			}},
+			expected: `{
+  "observation": {
+    "content": "results",
+    "search": [
+      {
+        "hits": 2
+      },
+      "plain"
+    ]
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewJSON().FormatSections(tt.input))
+		})
+	}
+}
+
+func TestJSON_OutputSchema(t *testing.T) {
+	format := NewJSON()
+	assert.Nil(t, format.OutputSchema())
+
+	format.RegisterSection(&mockSection{name: "thinking"})
+	format.RegisterSection(&mockSchemaSection{
+		mockSection: mockSection{name: "action"},
+		schema:      map[string]any{"type": "object"},
+	})
+
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"thinking": map[string]any{"type": "string"},
+			"action":   map[string]any{"type": "object"},
+		},
+		"additionalProperties": false,
+	}
+	assert.Equal(t, expected, format.OutputSchema())
+}
+
+func TestJSON_DescribeStructure(t *testing.T) {
+	assert.Equal(t, "", NewJSON().DescribeStructure())
+
+	format := NewJSON()
+	format.RegisterSection(&mockSection{name: "answer", guidance: "Write the answer."})
+
+	expected := `Respond with a single JSON object. Use one key per section, ` +
+		`and only include the sections relevant to this response:
+
+"answer":
+Write the answer.
+
+The response object must match this JSON Schema:
+{
+  "additionalProperties": false,
+  "properties": {
+    "answer": {
+      "type": "string"
+    }
+  },
+  "type": "object"
+}
+`
+	require.Equal(t, expected, format.DescribeStructure())
+}
+
+func TestJSON_RemoveSections(t *testing.T) {
+	type input struct {
+		output string
+		names  []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected string
+	}{
+		{
+			name: "removes key and re-encodes",
+			input: input{
+				output: "{\n  \"thinking\": \"hmm\",\n  \"answer\": \"42\"\n}",
+				names:  []string{"Thinking"},
+			},
+			expected: `{"answer":"42"}`,
+		},
+		{
+			name: "code fence is stripped",
+			input: input{
+				output: "```json\n{\"thinking\": \"hmm\", \"action\": {\"tool\": \"x\"}}\n```",
+				names:  []string{"thinking"},
+			},
+			expected: `{"action":{"tool":"x"}}`,
+		},
+		{
+			name: "missing key returns output unchanged",
+			input: input{
+				output: `{"answer": "42"}`,
+				names:  []string{"thinking"},
+			},
+			expected: `{"answer": "42"}`,
+		},
+		{
+			name: "invalid JSON returns output unchanged",
+			input: input{
+				output: `not json`,
+				names:  []string{"thinking"},
+			},
+			expected: `not json`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewJSON()
+			assert.Equal(t, tt.expected, f.RemoveSections(tt.input.output, tt.input.names...))
+		})
+	}
+}
@@ -65,6 +65,7 @@ import (
 type Markdown struct {
 	sections      []gent.TextSection
 	knownSections map[string]string // lowercase key -> original name
+	stopSections  []string
 }
 
 // NewMarkdown creates a new Markdown format.
@@ -75,6 +76,34 @@ func NewMarkdown() *Markdown {
 	}
 }
 
+// WithStopSections makes the format advise the header of each named section as a stop
+// sequence (see [gent.StopSequenceFormat]). Use it for sections the model must never
+// write itself, so generation stops instead of wasting output tokens on them:
+//
+//	// Stop when the model starts imagining the tool output
+//	textFormat := format.NewMarkdown().WithStopSections("observation")
+//
+// The stop string is excluded from the response, so never name a section the model is
+// expected to write.
+func (f *Markdown) WithStopSections(names ...string) *Markdown {
+	f.stopSections = names
+	return f
+}
+
+// StopSequences returns the top-level headers of the sections set by WithStopSections,
+// preceded by a newline so the section name mentioned in prose does not stop generation.
+// Implements [gent.StopSequenceFormat].
+func (f *Markdown) StopSequences() []string {
+	if len(f.stopSections) == 0 {
+		return nil
+	}
+	stops := make([]string, len(f.stopSections))
+	for i, name := range f.stopSections {
+		stops[i] = "\n# " + name
+	}
+	return stops
+}
+
 // RegisterSection adds a section to the format.
 // If a section with the same name already exists, it is not added again.
 // Returns self for chaining.
@@ -165,9 +194,11 @@ func (f *Markdown) Parse(
 		return nil, err
 	}
 
-	// Successful parse - reset consecutive error gauge
+	// Successful parse - reset consecutive error gauges and record section sizes
 	if execCtx != nil {
 		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+		execCtx.RecordSectionBytes(result)
 	}
 
 	return result, nil
@@ -228,3 +259,45 @@ func (f *Markdown) doParse(output string) (map[string][]string, error) {
 
 	return result, nil
 }
+
+// RemoveSections returns output with every instance of the named sections removed. A
+// section spans from its header to the next header, or to the end of the output.
+func (f *Markdown) RemoveSections(output string, names ...string) string {
+	remove := make(map[string]bool, len(names))
+	for _, name := range names {
+		remove[strings.ToLower(name)] = true
+	}
+
+	headerPattern := regexp.MustCompile(`(?m)^#\s+(.+?)\s*$`)
+	matches := headerPattern.FindAllStringSubmatchIndex(output, -1)
+
+	var sb strings.Builder
+	pos := 0
+	removed := false
+	for i, match := range matches {
+		sectionName := strings.ToLower(strings.TrimSpace(output[match[2]:match[3]]))
+		if !remove[sectionName] {
+			continue
+		}
+
+		sectionEnd := len(output)
+		if i+1 < len(matches) {
+			sectionEnd = matches[i+1][0]
+		}
+		sb.WriteString(output[pos:match[0]])
+		pos = sectionEnd
+		removed = true
+	}
+	if !removed {
+		return output
+	}
+	sb.WriteString(output[pos:])
+
+	return strings.TrimSpace(sb.String())
+}
+
+// Compile-time check that Markdown implements gent.SectionRemover.
+var _ gent.SectionRemover = (*Markdown)(nil)
+
+// Compile-time check that Markdown implements gent.StopSequenceFormat.
+var _ gent.StopSequenceFormat = (*Markdown)(nil)
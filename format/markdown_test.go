@@ -629,3 +629,48 @@ func TestMarkdown_RegisterSection(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdown_RemoveSections(t *testing.T) {
+	type input struct {
+		output string
+		names  []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected string
+	}{
+		{
+			name: "removes section up to next header",
+			input: input{
+				output: "# Thinking\nLet me search.\n\n# Action\ntool: search",
+				names:  []string{"thinking"},
+			},
+			expected: "# Action\ntool: search",
+		},
+		{
+			name: "removes last section",
+			input: input{
+				output: "# Action\ntool: search\n\n# Thinking\nDone.",
+				names:  []string{"thinking"},
+			},
+			expected: "# Action\ntool: search",
+		},
+		{
+			name: "missing section returns output unchanged",
+			input: input{
+				output: "# Answer\n42\n",
+				names:  []string{"thinking"},
+			},
+			expected: "# Answer\n42\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewMarkdown()
+			assert.Equal(t, tt.expected, f.RemoveSections(tt.input.output, tt.input.names...))
+		})
+	}
+}
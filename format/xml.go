@@ -3,6 +3,7 @@ package format
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/rickchristie/gent"
@@ -78,6 +79,30 @@ var ErrAmbiguousTags = fmt.Errorf("ambiguous tags: section tag found inside anot
 // In strict mode, Parse returns [ErrAmbiguousTags] if a section's content
 // contains another registered section's tags.
 //
+// # Literal Content (CDATA)
+//
+// When a section's content may legitimately contain tags, such as thinking that quotes
+// "</answer>" or a tool result with HTML, enable CDATA mode for it:
+//
+//	textFormat := format.NewXML().WithCDATA("thinking")
+//
+// DescribeStructure then asks the model to wrap the content of those sections in
+// <![CDATA[ ... ]]>, and FormatSections wraps their content the same way. While CDATA
+// mode is enabled, Parse ignores tags inside any CDATA block and returns the block's
+// content without the markers:
+//
+//	<thinking>
+//	<![CDATA[
+//	The final step is to write <answer>Sunny</answer>.
+//	]]>
+//	</thinking>
+//	<answer>
+//	Sunny
+//	</answer>
+//
+// Here "thinking" holds the quoted tags as text and "answer" has a single entry.
+// Content containing "]]>" is split across CDATA blocks when formatted, as in XML.
+//
 // # Nested Sections
 //
 // FormatSections supports hierarchical output with nested tags:
@@ -100,8 +125,13 @@ type XML struct {
 	sections      []gent.TextSection
 	knownSections map[string]string // lowercase key -> original name
 	strict        bool
+	stopSections  []string
+	cdataSections map[string]bool // lowercase names of sections wrapped in CDATA
 }
 
+// cdataPattern matches a CDATA block; the first group is its content.
+var cdataPattern = regexp.MustCompile(`(?s)<!\[CDATA\[(.*?)\]\]>`)
+
 // NewXML creates a new XML format.
 func NewXML() *XML {
 	return &XML{
@@ -118,6 +148,45 @@ func (f *XML) WithStrict(strict bool) *XML {
 	return f
 }
 
+// WithStopSections makes the format advise the opening tag of each named section as a
+// stop sequence (see [gent.StopSequenceFormat]). Use it for sections the model must never
+// write itself, so generation stops instead of wasting output tokens on them:
+//
+//	// Stop when the model starts imagining the tool output
+//	textFormat := format.NewXML().WithStopSections("observation")
+//
+// The stop string is excluded from the response, so never name a section the model is
+// expected to write.
+func (f *XML) WithStopSections(names ...string) *XML {
+	f.stopSections = names
+	return f
+}
+
+// WithCDATA enables CDATA mode for the named sections: their content is wrapped in
+// <![CDATA[ ... ]]> by FormatSections, and DescribeStructure asks the model to do the
+// same. Tags inside CDATA blocks are then treated as literal text by Parse. Use it for
+// sections likely to contain markup. Calling it again replaces the previous names.
+func (f *XML) WithCDATA(names ...string) *XML {
+	f.cdataSections = make(map[string]bool, len(names))
+	for _, name := range names {
+		f.cdataSections[strings.ToLower(name)] = true
+	}
+	return f
+}
+
+// StopSequences returns the opening tags of the sections set by WithStopSections.
+// Implements [gent.StopSequenceFormat].
+func (f *XML) StopSequences() []string {
+	if len(f.stopSections) == 0 {
+		return nil
+	}
+	stops := make([]string, len(f.stopSections))
+	for i, name := range f.stopSections {
+		stops[i] = "<" + name + ">"
+	}
+	return stops
+}
+
 // RegisterSection adds a section to the format.
 // If a section with the same name already exists, it is not added again.
 // Returns self for chaining.
@@ -152,7 +221,11 @@ func (f *XML) formatSection(section gent.FormattedSection) string {
 
 	// Add content if present
 	if section.Content != "" {
-		inner = append(inner, section.Content)
+		content := section.Content
+		if f.cdataSections[strings.ToLower(section.Name)] {
+			content = wrapCDATA(content)
+		}
+		inner = append(inner, content)
 	}
 
 	// Format children recursively
@@ -167,6 +240,12 @@ func (f *XML) formatSection(section gent.FormattedSection) string {
 	return fmt.Sprintf("<%s>\n%s\n</%s>", section.Name, innerContent, section.Name)
 }
 
+// wrapCDATA wraps content in a CDATA block, splitting it where content contains "]]>".
+func wrapCDATA(content string) string {
+	escaped := strings.ReplaceAll(content, "]]>", "]]]]><![CDATA[>")
+	return "<![CDATA[\n" + escaped + "\n]]>"
+}
+
 // DescribeStructure generates the prompt explaining the output format structure.
 // It shows the tag format with each section's prompt instructions.
 func (f *XML) DescribeStructure() string {
@@ -177,13 +256,24 @@ func (f *XML) DescribeStructure() string {
 	var sb strings.Builder
 	sb.WriteString("Format your response using XML-style tags for each section:\n\n")
 
+	var cdataTags []string
 	for _, section := range f.sections {
 		name := section.Name()
 		fmt.Fprintf(&sb, "<%s>\n", name)
-		fmt.Fprintf(&sb, "%s\n", section.Guidance())
+		if f.cdataSections[strings.ToLower(name)] {
+			cdataTags = append(cdataTags, "<"+name+">")
+			fmt.Fprintf(&sb, "<![CDATA[\n%s\n]]>\n", section.Guidance())
+		} else {
+			fmt.Fprintf(&sb, "%s\n", section.Guidance())
+		}
 		fmt.Fprintf(&sb, "</%s>\n", name)
 	}
 
+	if len(cdataTags) > 0 {
+		fmt.Fprintf(&sb, "\nAlways wrap the content of %s in <![CDATA[ and ]]>, so any tags "+
+			"you write inside it are read as plain text.\n", strings.Join(cdataTags, ", "))
+	}
+
 	return sb.String()
 }
 
@@ -198,9 +288,11 @@ func (f *XML) Parse(execCtx *gent.ExecutionContext, output string) (map[string][
 		return nil, err
 	}
 
-	// Successful parse - reset consecutive error gauge
+	// Successful parse - reset consecutive error gauges and record section sizes
 	if execCtx != nil {
 		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+		execCtx.RecordSectionBytes(result)
 	}
 
 	return result, nil
@@ -235,7 +327,10 @@ func (f *XML) doParse(output string) (map[string][]string, error) {
 	}
 
 	if len(result) == 0 {
-		return nil, gent.ErrNoSectionsFound
+		if len(f.knownSections) == 0 {
+			return nil, gent.ErrNoSectionsFound
+		}
+		return nil, f.locateMissingSections(output)
 	}
 
 	// In strict mode, check for ambiguities
@@ -248,25 +343,89 @@ func (f *XML) doParse(output string) (map[string][]string, error) {
 	return result, nil
 }
 
+// locateMissingSections explains why no registered section was found, as a
+// [gent.ParseError] wrapping [gent.ErrNoSectionsFound]. It reports the earliest of: an
+// opening tag that is never closed, a closing tag without an opening tag, or a section
+// with empty content. If no section tag appears at all, it points at the start of the
+// output and lists the opening tags of the registered sections as expected.
+func (f *XML) locateMissingSections(output string) error {
+	var located *gent.ParseError
+	locate := func(offset int, detail string, expected string) {
+		if located == nil || offset < located.Offset {
+			err := fmt.Errorf("%w: %s", gent.ErrNoSectionsFound, detail)
+			located = gent.NewParseErrorAt(err, output, offset, expected)
+		}
+	}
+
+	expected := make([]string, 0, len(f.sections))
+	for _, section := range f.sections {
+		name := strings.ToLower(section.Name())
+		expected = append(expected, "<"+name+">")
+
+		spans := f.findSectionSpans(output, name)
+		paired := make(map[int]bool, 2*len(spans))
+		for _, span := range spans {
+			paired[span.start] = true
+			paired[span.contentEnd] = true
+			locate(span.contentStart, fmt.Sprintf("<%s> is empty", name), "content")
+		}
+
+		for _, open := range f.findTags(output, fmt.Sprintf(`(?i)<%s>`, name)) {
+			if !paired[open[0]] {
+				locate(open[0], fmt.Sprintf("<%s> is not closed", name), "</"+name+">")
+			}
+		}
+		for _, closing := range f.findTags(output, fmt.Sprintf(`(?i)</%s>`, name)) {
+			if !paired[closing[0]] {
+				locate(closing[0], fmt.Sprintf("</%s> has no opening tag", name), "<"+name+">")
+			}
+		}
+	}
+
+	if located == nil {
+		return gent.NewParseErrorAt(gent.ErrNoSectionsFound, output, 0, expected...)
+	}
+	return located
+}
+
 // findSectionMatches finds all instances of a section by pairing closing tags with their
 // nearest preceding opening tags. This handles cases where the LLM writes literal tag names
 // in content (e.g., "provide <answer>." inside <thinking>).
 func (f *XML) findSectionMatches(output string, sectionName string) []string {
 	var results []string
+	for _, span := range f.findSectionSpans(output, sectionName) {
+		content := output[span.contentStart:span.contentEnd]
+		if f.cdataEnabled() {
+			content = cdataPattern.ReplaceAllString(content, "$1")
+		}
+		trimmed := strings.TrimSpace(content)
+		if trimmed != "" {
+			results = append(results, trimmed)
+		}
+	}
+	return results
+}
 
+// sectionSpan holds the byte offsets of one section instance in the output.
+type sectionSpan struct {
+	start        int // start of the opening tag
+	contentStart int // end of the opening tag
+	contentEnd   int // start of the closing tag
+	end          int // end of the closing tag
+}
+
+// findSectionSpans locates all instances of a section, pairing each closing tag with the
+// last unused opening tag before it.
+func (f *XML) findSectionSpans(output string, sectionName string) []sectionSpan {
 	// Find all closing tags
-	closePattern := fmt.Sprintf(`(?i)</%s>`, sectionName)
-	closeRe := regexp.MustCompile(closePattern)
-	closeMatches := closeRe.FindAllStringIndex(output, -1)
+	closeMatches := f.findTags(output, fmt.Sprintf(`(?i)</%s>`, sectionName))
 
 	if len(closeMatches) == 0 {
 		return nil
 	}
 
 	// Find all opening tags
-	openPattern := fmt.Sprintf(`(?i)<%s>`, sectionName)
-	openRe := regexp.MustCompile(openPattern)
-	openMatches := openRe.FindAllStringIndex(output, -1)
+	openMatches := f.findTags(output, fmt.Sprintf(`(?i)<%s>`, sectionName))
 
 	if len(openMatches) == 0 {
 		return nil
@@ -274,6 +433,7 @@ func (f *XML) findSectionMatches(output string, sectionName string) []string {
 
 	// For each closing tag, find the LAST opening tag before it that hasn't been used
 	// This correctly handles cases like: <thinking>...<answer>...</thinking>...<answer>...</answer>
+	var spans []sectionSpan
 	usedOpens := make(map[int]bool)
 
 	for _, closeMatch := range closeMatches {
@@ -290,24 +450,98 @@ func (f *XML) findSectionMatches(output string, sectionName string) []string {
 
 		if bestOpen != nil {
 			usedOpens[bestOpen[0]] = true
-			content := output[bestOpen[1]:closeStart]
-			trimmed := strings.TrimSpace(content)
-			if trimmed != "" {
-				results = append(results, trimmed)
+			spans = append(spans, sectionSpan{
+				start:        bestOpen[0],
+				contentStart: bestOpen[1],
+				contentEnd:   closeStart,
+				end:          closeMatch[1],
+			})
+		}
+	}
+
+	return spans
+}
+
+// findTags returns the locations of the tags matching pattern in output. In CDATA mode,
+// tags inside CDATA blocks are left out.
+func (f *XML) findTags(output string, pattern string) [][]int {
+	matches := regexp.MustCompile(pattern).FindAllStringIndex(output, -1)
+	if !f.cdataEnabled() || len(matches) == 0 {
+		return matches
+	}
+
+	blocks := cdataPattern.FindAllStringIndex(output, -1)
+	tags := matches[:0]
+	for _, match := range matches {
+		literal := false
+		for _, block := range blocks {
+			if match[0] >= block[0] && match[1] <= block[1] {
+				literal = true
+				break
 			}
 		}
+		if !literal {
+			tags = append(tags, match)
+		}
 	}
+	return tags
+}
 
-	return results
+// cdataEnabled reports whether CDATA mode is enabled for any section.
+func (f *XML) cdataEnabled() bool {
+	return len(f.cdataSections) > 0
+}
+
+// RemoveSections returns output with every instance of the named sections removed,
+// including their tags and the whitespace that follows them.
+func (f *XML) RemoveSections(output string, names ...string) string {
+	var spans []sectionSpan
+	for _, name := range names {
+		spans = append(spans, f.findSectionSpans(output, strings.ToLower(name))...)
+	}
+	if len(spans) == 0 {
+		return output
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var sb strings.Builder
+	pos := 0
+	for _, span := range spans {
+		if span.start < pos {
+			continue // nested inside a section that was already removed
+		}
+		sb.WriteString(output[pos:span.start])
+		pos = span.end
+		for pos < len(output) && strings.ContainsRune(" \t\r\n", rune(output[pos])) {
+			pos++
+		}
+	}
+	sb.WriteString(output[pos:])
+
+	return strings.TrimSpace(sb.String())
 }
 
 // validateNoAmbiguities checks if any parsed section's content contains another section's tags.
 // This is used in strict mode to detect potentially ambiguous parses.
+//
+// Sections are checked in registration order (not map order) so the reported error is
+// identical across runs for the same output. In CDATA mode, tags inside CDATA blocks are
+// literal and don't count.
 func (f *XML) validateNoAmbiguities(output string, result map[string][]string) error {
-	for sectionName, contents := range result {
+	for _, section := range f.sections {
+		sectionName := section.Name()
+		contents := result[sectionName]
+		if f.cdataEnabled() {
+			contents = nil
+			for _, span := range f.findSectionSpans(output, strings.ToLower(sectionName)) {
+				raw := output[span.contentStart:span.contentEnd]
+				contents = append(contents, cdataPattern.ReplaceAllString(raw, ""))
+			}
+		}
 		for _, content := range contents {
-			for otherSection := range f.knownSections {
-				if otherSection == sectionName {
+			for _, other := range f.sections {
+				otherSection := strings.ToLower(other.Name())
+				if otherSection == strings.ToLower(sectionName) {
 					continue
 				}
 				// Check if content contains another section's opening or closing tag
@@ -326,3 +560,9 @@ func (f *XML) validateNoAmbiguities(output string, result map[string][]string) e
 	}
 	return nil
 }
+
+// Compile-time check that XML implements gent.SectionRemover.
+var _ gent.SectionRemover = (*XML)(nil)
+
+// Compile-time check that XML implements gent.StopSequenceFormat.
+var _ gent.StopSequenceFormat = (*XML)(nil)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/rickchristie/gent"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestXML_Parse(t *testing.T) {
@@ -411,6 +412,228 @@ The actual answer.
 	}
 }
 
+func TestXML_Parse_CDATA(t *testing.T) {
+	const quotesAnswer = `<thinking>
+<![CDATA[
+I will finish with <answer>Sunny</answer> and then stop.
+Closing early with </answer> or </thinking> must not matter.
+]]>
+</thinking>
+<answer>
+Sunny
+</answer>`
+
+	type input struct {
+		cdata  []string
+		strict bool
+		output string
+	}
+
+	type expected struct {
+		sections map[string][]string
+		err      error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "tags inside CDATA are literal",
+			input: input{
+				cdata:  []string{"thinking"},
+				output: quotesAnswer,
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"I will finish with <answer>Sunny</answer> and then stop.\n" +
+						"Closing early with </answer> or </thinking> must not matter."},
+					"answer": {"Sunny"},
+				},
+			},
+		},
+		{
+			name: "strict mode accepts tags inside CDATA",
+			input: input{
+				cdata:  []string{"thinking"},
+				strict: true,
+				output: quotesAnswer,
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"I will finish with <answer>Sunny</answer> and then stop.\n" +
+						"Closing early with </answer> or </thinking> must not matter."},
+					"answer": {"Sunny"},
+				},
+			},
+		},
+		{
+			name: "strict mode still rejects tags outside CDATA",
+			input: input{
+				cdata:  []string{"thinking"},
+				strict: true,
+				output: "<thinking>\nI will write <answer>.\n</thinking>\n" +
+					"<answer>\nSunny\n</answer>",
+			},
+			expected: expected{err: ErrAmbiguousTags},
+		},
+		{
+			name: "split CDATA blocks are joined",
+			input: input{
+				cdata: []string{"answer"},
+				output: "<answer>\n<![CDATA[\nuse a[b[0]]]]><![CDATA[> c\n]]>\n" +
+					"</answer>",
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"answer": {"use a[b[0]]> c"},
+				},
+			},
+		},
+		{
+			name: "without CDATA mode the markers are kept and tags are parsed",
+			input: input{
+				output: quotesAnswer,
+			},
+			expected: expected{
+				sections: map[string][]string{
+					"thinking": {"<![CDATA[\nI will finish with <answer>Sunny</answer> " +
+						"and then stop.\nClosing early with </answer> or"},
+					"answer": {"Sunny", "Sunny"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := NewXML().WithStrict(tt.input.strict)
+			if len(tt.input.cdata) > 0 {
+				format = format.WithCDATA(tt.input.cdata...)
+			}
+			format.RegisterSection(&mockSection{name: "thinking"})
+			format.RegisterSection(&mockSection{name: "answer"})
+
+			result, err := format.Parse(nil, tt.input.output)
+
+			assert.ErrorIs(t, err, tt.expected.err)
+			assert.Equal(t, tt.expected.sections, result)
+		})
+	}
+}
+
+func TestXML_Parse_StrictErrorIsDeterministic(t *testing.T) {
+	// Both "action" and "answer" tags appear inside thinking; the reported ambiguity
+	// must not depend on map iteration order.
+	output := `<thinking>
+I will use <action> and then <answer>.
+</thinking>
+<action>tool: search</action>
+<answer>done</answer>`
+
+	expected := "ambiguous tags: section tag found inside another section: " +
+		"<action> found inside <thinking> content"
+
+	for i := 0; i < 100; i++ {
+		format := NewXML().WithStrict(true)
+		format.RegisterSection(&mockSection{name: "thinking"})
+		format.RegisterSection(&mockSection{name: "action"})
+		format.RegisterSection(&mockSection{name: "answer"})
+
+		_, err := format.Parse(nil, output)
+
+		assert.ErrorIs(t, err, ErrAmbiguousTags)
+		if !assert.EqualError(t, err, expected, "run %d differs", i) {
+			return
+		}
+	}
+}
+
+func TestXML_Parse_LocatesMissingSections(t *testing.T) {
+	type expected struct {
+		message  string
+		offset   int
+		line     int
+		column   int
+		expected []string
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name:  "no tags",
+			input: "I think the answer is 42.",
+			expected: expected{
+				message: "no recognized sections found in output " +
+					"(line 1, column 1; expected <thinking> or <answer>)",
+				offset:   0,
+				line:     1,
+				column:   1,
+				expected: []string{"<thinking>", "<answer>"},
+			},
+		},
+		{
+			name:  "unclosed tag",
+			input: "Let me see.\n<answer>\n42",
+			expected: expected{
+				message: "no recognized sections found in output: <answer> is not closed " +
+					"(line 2, column 1; expected </answer>)",
+				offset:   12,
+				line:     2,
+				column:   1,
+				expected: []string{"</answer>"},
+			},
+		},
+		{
+			name:  "closing tag without opening tag",
+			input: "<thinkng>hmm</thinking>",
+			expected: expected{
+				message: "no recognized sections found in output: </thinking> has no " +
+					"opening tag (line 1, column 13; expected <thinking>)",
+				offset:   12,
+				line:     1,
+				column:   13,
+				expected: []string{"<thinking>"},
+			},
+		},
+		{
+			name:  "earliest problem wins",
+			input: "<thinking>\n</thinking>\n<answer>42",
+			expected: expected{
+				message: "no recognized sections found in output: <thinking> is empty " +
+					"(line 1, column 11; expected content)",
+				offset:   10,
+				line:     1,
+				column:   11,
+				expected: []string{"content"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format := NewXML()
+			format.RegisterSection(&mockSection{name: "thinking"})
+			format.RegisterSection(&mockSection{name: "answer"})
+
+			_, err := format.Parse(nil, tc.input)
+
+			assert.ErrorIs(t, err, gent.ErrNoSectionsFound)
+			assert.EqualError(t, err, tc.expected.message)
+			var parseErr *gent.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tc.expected.offset, parseErr.Offset)
+			assert.Equal(t, tc.expected.line, parseErr.Line)
+			assert.Equal(t, tc.expected.column, parseErr.Column)
+			assert.Equal(t, tc.expected.expected, parseErr.Expected)
+		})
+	}
+}
+
 func TestXML_FormatSections(t *testing.T) {
 	type input struct {
 		sections []gent.FormattedSection
@@ -615,6 +838,89 @@ func TestXML_DescribeStructure(t *testing.T) {
 	}
 }
 
+func TestXML_WithCDATA(t *testing.T) {
+	type input struct {
+		cdata   []string
+		content string
+	}
+
+	type expected struct {
+		formatted string
+		describe  string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "named section is wrapped and described",
+			input: input{
+				cdata:   []string{"Thinking"},
+				content: "I'll end with </answer>.",
+			},
+			expected: expected{
+				formatted: "<thinking>\n<![CDATA[\nI'll end with </answer>.\n]]>\n</thinking>",
+				describe: "Format your response using XML-style tags for each section:\n\n" +
+					"<thinking>\n<![CDATA[\nThink it through.\n]]>\n</thinking>\n" +
+					"<answer>\nWrite the answer.\n</answer>\n" +
+					"\nAlways wrap the content of <thinking> in <![CDATA[ and ]]>, so any " +
+					"tags you write inside it are read as plain text.\n",
+			},
+		},
+		{
+			name: "CDATA end marker in content is split",
+			input: input{
+				cdata:   []string{"thinking"},
+				content: "a[b[0]]> c",
+			},
+			expected: expected{
+				formatted: "<thinking>\n<![CDATA[\na[b[0]]]]><![CDATA[> c\n]]>\n</thinking>",
+				describe: "Format your response using XML-style tags for each section:\n\n" +
+					"<thinking>\n<![CDATA[\nThink it through.\n]]>\n</thinking>\n" +
+					"<answer>\nWrite the answer.\n</answer>\n" +
+					"\nAlways wrap the content of <thinking> in <![CDATA[ and ]]>, so any " +
+					"tags you write inside it are read as plain text.\n",
+			},
+		},
+		{
+			name: "other sections are unchanged",
+			input: input{
+				cdata:   []string{"answer"},
+				content: "I'll end with </answer>.",
+			},
+			expected: expected{
+				formatted: "<thinking>\nI'll end with </answer>.\n</thinking>",
+				describe: "Format your response using XML-style tags for each section:\n\n" +
+					"<thinking>\nThink it through.\n</thinking>\n" +
+					"<answer>\n<![CDATA[\nWrite the answer.\n]]>\n</answer>\n" +
+					"\nAlways wrap the content of <answer> in <![CDATA[ and ]]>, so any " +
+					"tags you write inside it are read as plain text.\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := NewXML().WithCDATA(tt.input.cdata...)
+			format.RegisterSection(&mockSection{name: "thinking", guidance: "Think it through."})
+			format.RegisterSection(&mockSection{name: "answer", guidance: "Write the answer."})
+
+			formatted := format.FormatSections([]gent.FormattedSection{
+				{Name: "thinking", Content: tt.input.content},
+			})
+			assert.Equal(t, tt.expected.formatted, formatted)
+			assert.Equal(t, tt.expected.describe, format.DescribeStructure())
+
+			// Formatted content parses back to the original
+			parsed, err := format.Parse(nil, formatted)
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.input.content}, parsed["thinking"])
+		})
+	}
+}
+
 func TestXML_Parse_TracesErrors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -692,6 +998,147 @@ func TestXML_Parse_TracesErrors(t *testing.T) {
 	}
 }
 
+func TestXML_Parse_IdenticalConsecutiveErrors(t *testing.T) {
+	const (
+		stuck = "no sections here"
+		valid = "<answer>hello</answer>"
+	)
+
+	type input struct {
+		outputs []string
+	}
+
+	type expected struct {
+		// Gauge values after each parse
+		identical   []float64
+		consecutive []float64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "identical failing outputs increment",
+			input: input{outputs: []string{stuck, stuck, stuck}},
+			expected: expected{
+				identical:   []float64{1, 2, 3},
+				consecutive: []float64{1, 2, 3},
+			},
+		},
+		{
+			name:  "varied failing outputs restart at one",
+			input: input{outputs: []string{"first try", "second try", "second try"}},
+			expected: expected{
+				identical:   []float64{1, 1, 2},
+				consecutive: []float64{1, 2, 3},
+			},
+		},
+		{
+			name:  "successful parse resets both gauges",
+			input: input{outputs: []string{stuck, stuck, valid, stuck}},
+			expected: expected{
+				identical:   []float64{1, 2, 0, 1},
+				consecutive: []float64{1, 2, 0, 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format := NewXML()
+			format.RegisterSection(&mockSection{name: "answer"})
+
+			execCtx := gent.NewExecutionContext(
+				context.Background(), "test", nil,
+			)
+			execCtx.IncrementIteration()
+
+			var identical, consecutive []float64
+			for _, output := range tc.input.outputs {
+				_, _ = format.Parse(execCtx, output)
+				stats := execCtx.Stats()
+				identical = append(identical,
+					stats.GetGauge(gent.SGFormatParseErrorIdenticalConsecutive))
+				consecutive = append(consecutive,
+					stats.GetGauge(gent.SGFormatParseErrorConsecutive))
+			}
+
+			assert.Equal(t, tc.expected.identical, identical)
+			assert.Equal(t, tc.expected.consecutive, consecutive)
+		})
+	}
+}
+
+func TestXML_Parse_RecordsSectionBytes(t *testing.T) {
+	type input struct {
+		outputs []string
+	}
+
+	type expected struct {
+		thinking float64
+		action   float64
+		answer   float64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "records summed bytes per section",
+			input: input{outputs: []string{
+				"<thinking>abc</thinking><action>one</action><action>four</action>",
+			}},
+			expected: expected{thinking: 3, action: 7},
+		},
+		{
+			name: "sections missing from the latest parse are reset",
+			input: input{outputs: []string{
+				"<thinking>abcdef</thinking><action>call</action>",
+				"<answer>done</answer>",
+			}},
+			expected: expected{answer: 4},
+		},
+		{
+			name: "failed parse keeps previous sizes",
+			input: input{outputs: []string{
+				"<thinking>abcdef</thinking>",
+				"no sections here",
+			}},
+			expected: expected{thinking: 6},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format := NewXML()
+			for _, name := range []string{"thinking", "action", "answer"} {
+				format.RegisterSection(&mockSection{name: name})
+			}
+
+			execCtx := gent.NewExecutionContext(
+				context.Background(), "test", nil,
+			)
+			execCtx.IncrementIteration()
+
+			for _, output := range tc.input.outputs {
+				_, _ = format.Parse(execCtx, output)
+			}
+
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.thinking,
+				stats.GetGauge(gent.SGSectionBytesFor+"thinking"))
+			assert.Equal(t, tc.expected.action,
+				stats.GetGauge(gent.SGSectionBytesFor+"action"))
+			assert.Equal(t, tc.expected.answer,
+				stats.GetGauge(gent.SGSectionBytesFor+"answer"))
+		})
+	}
+}
+
 func TestXML_RegisterSection(t *testing.T) {
 	type input struct {
 		name     string
@@ -779,3 +1226,58 @@ func TestXML_RegisterSection(t *testing.T) {
 		})
 	}
 }
+
+func TestXML_RemoveSections(t *testing.T) {
+	type input struct {
+		output string
+		names  []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected string
+	}{
+		{
+			name: "removes section and following whitespace",
+			input: input{
+				output: "<thinking>\nLet me search.\n</thinking>\n\n" +
+					"<action>\ntool: search\n</action>",
+				names: []string{"thinking"},
+			},
+			expected: "<action>\ntool: search\n</action>",
+		},
+		{
+			name: "removes every instance case-insensitively",
+			input: input{
+				output: "<Thinking>first</Thinking>\n<answer>42</answer>\n" +
+					"<thinking>second</thinking>",
+				names: []string{"THINKING"},
+			},
+			expected: "<answer>42</answer>",
+		},
+		{
+			name: "literal tag in removed section does not leak",
+			input: input{
+				output: "<thinking>I will use <answer> next.</thinking>\n<answer>42</answer>",
+				names:  []string{"thinking", "answer"},
+			},
+			expected: "",
+		},
+		{
+			name: "missing section returns output unchanged",
+			input: input{
+				output: "<answer>42</answer>\n",
+				names:  []string{"thinking"},
+			},
+			expected: "<answer>42</answer>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewXML()
+			assert.Equal(t, tt.expected, f.RemoveSections(tt.input.output, tt.input.names...))
+		})
+	}
+}
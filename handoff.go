@@ -0,0 +1,108 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rickchristie/gent/schema"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// HandoffTarget runs an agent on an ExecutionContext until it terminates.
+// executor.Executor implements it.
+type HandoffTarget interface {
+	Execute(execCtx *ExecutionContext)
+}
+
+// HandoffInput is the input of a handoff tool.
+type HandoffInput struct {
+	// Request is what the target agent should do, written by the calling agent.
+	Request string `json:"request"`
+}
+
+// HandoffTaskMapper derives the target agent's LoopData from a handoff tool's input.
+// The LoopData must be the type the target's AgentLoop expects.
+type HandoffTaskMapper func(ctx context.Context, input HandoffInput) (LoopData, error)
+
+// NewHandoffTool creates a tool that hands a request off to another agent, for router
+// agents that pick which specialist handles a request.
+//
+// When called, the tool derives the target's task with taskMapper, runs target in a
+// child context of the caller (named after the tool), and returns the target's final
+// answer as the observation. The child inherits the caller's limits and its stats
+// propagate to the caller like any child context. A HandoffEvent is published on the
+// caller's context once the target finishes. If the target ends without an answer
+// (error, limit, cancellation), the tool call fails with that error.
+//
+// A nil taskMapper runs the target on NewBasicLoopData with the request as task text.
+// The tool must run inside an execution: it needs the ExecutionContext its toolchain
+// passes through the tool's context.
+//
+// Example:
+//
+//	billing := executor.New[*gent.BasicLoopData](billingAgent, executor.DefaultConfig())
+//	router := react.NewAgent(model).WithToolChain(toolchain.NewYAML().RegisterTool(
+//	    gent.NewHandoffTool("billing", "Hand billing questions to the billing agent",
+//	        billing, nil),
+//	))
+func NewHandoffTool(
+	name, description string,
+	target HandoffTarget,
+	taskMapper HandoffTaskMapper,
+) *ToolFunc[HandoffInput, string] {
+	if taskMapper == nil {
+		taskMapper = func(_ context.Context, input HandoffInput) (LoopData, error) {
+			return NewBasicLoopData(&Task{Text: input.Request}), nil
+		}
+	}
+	parameterSchema := schema.Object(map[string]*schema.Property{
+		"request": schema.String("What the " + name + " agent should do"),
+	}, "request")
+
+	return NewToolFunc(name, description, parameterSchema,
+		func(ctx context.Context, input HandoffInput) (string, error) {
+			execCtx := ExecutionContextFrom(ctx)
+			if execCtx == nil {
+				return "", fmt.Errorf("handoff %q: no ExecutionContext in context", name)
+			}
+			data, err := taskMapper(ctx, input)
+			if err != nil {
+				return "", fmt.Errorf("handoff %q: %w", name, err)
+			}
+
+			child := execCtx.SpawnChild(name, data)
+			target.Execute(child)
+			execCtx.CompleteChild(child)
+
+			reason, output, err := handoffOutcome(child)
+			execCtx.PublishHandoff(name, input.Request, reason, output, err)
+			if err != nil {
+				return "", fmt.Errorf("handoff %q: %w", name, err)
+			}
+			return output, nil
+		},
+	)
+}
+
+// handoffOutcome reads how a handoff's child execution ended: its termination reason,
+// its answer text, and the error if it did not end successfully. Successful endings
+// may carry a custom reason (ExecutionContext.SetTerminationReason).
+func handoffOutcome(child *ExecutionContext) (TerminationReason, string, error) {
+	result := child.Result()
+	if result == nil {
+		return "", "", errors.New("target agent did not terminate")
+	}
+	if result.Error != nil {
+		return result.TerminationReason, "", result.Error
+	}
+
+	var texts []string
+	for _, part := range result.Output {
+		if tc, ok := part.(llms.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	return result.TerminationReason, strings.Join(texts, "\n"), nil
+}
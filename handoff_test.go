@@ -0,0 +1,155 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// handoffTargetStub terminates the child context with a fixed answer or error and charges
+// input tokens to it, standing in for an Executor.
+type handoffTargetStub struct {
+	answer string
+	err    error
+	tokens int64
+	task   string
+}
+
+func (s *handoffTargetStub) Execute(execCtx *ExecutionContext) {
+	if data, ok := execCtx.Data().(*BasicLoopData); ok {
+		s.task = data.GetTask().Text
+	}
+	execCtx.Stats().IncrCounter(SCInputTokens, s.tokens)
+	if s.err != nil {
+		execCtx.SetTermination(TerminationError, nil, s.err)
+		return
+	}
+	execCtx.SetTermination(
+		TerminationSuccess,
+		[]ContentPart{llms.TextContent{Text: s.answer}},
+		nil,
+	)
+}
+
+func TestNewHandoffTool(t *testing.T) {
+	targetErr := errors.New("billing system down")
+
+	type input struct {
+		target  *handoffTargetStub
+		request string
+	}
+
+	type expected struct {
+		output       string
+		err          error
+		task         string
+		parentTokens int64
+		event        HandoffEvent
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "returns the target's answer",
+			input: input{
+				target:  &handoffTargetStub{answer: "Refund issued.", tokens: 30},
+				request: "Refund order 42",
+			},
+			expected: expected{
+				output:       "Refund issued.",
+				task:         "Refund order 42",
+				parentTokens: 30,
+				event: HandoffEvent{
+					Target:            "billing",
+					Request:           "Refund order 42",
+					TerminationReason: TerminationSuccess,
+					Output:            "Refund issued.",
+				},
+			},
+		},
+		{
+			name: "target error fails the tool call",
+			input: input{
+				target:  &handoffTargetStub{err: targetErr, tokens: 10},
+				request: "Refund order 42",
+			},
+			expected: expected{
+				err:          targetErr,
+				task:         "Refund order 42",
+				parentTokens: 10,
+				event: HandoffEvent{
+					Target:            "billing",
+					Request:           "Refund order 42",
+					TerminationReason: TerminationError,
+					Error:             targetErr,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := NewExecutionContext(context.Background(), "router", nil)
+			tool := NewHandoffTool("billing", "Billing questions", tc.input.target, nil)
+
+			result, err := tool.Call(parent.Context(), HandoffInput{Request: tc.input.request})
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected.output, result.Text)
+			}
+			assert.Equal(t, tc.expected.task, tc.input.target.task)
+			assert.Equal(t, tc.expected.parentTokens,
+				parent.Stats().GetCounter(SCInputTokens))
+
+			children := parent.Children()
+			require.Len(t, children, 1)
+			assert.Equal(t, "billing", children[0].Name())
+
+			var events []*HandoffEvent
+			for _, event := range parent.Events() {
+				if e, ok := event.(*HandoffEvent); ok {
+					events = append(events, e)
+				}
+			}
+			require.Len(t, events, 1)
+			event := *events[0]
+			event.BaseEvent = BaseEvent{}
+			assert.Equal(t, tc.expected.event, event)
+		})
+	}
+}
+
+func TestNewHandoffTool_TaskMapper(t *testing.T) {
+	target := &handoffTargetStub{answer: "done"}
+	mapper := func(_ context.Context, input HandoffInput) (LoopData, error) {
+		return NewBasicLoopData(&Task{Text: "[billing] " + input.Request}), nil
+	}
+	parent := NewExecutionContext(context.Background(), "router", nil)
+	tool := NewHandoffTool("billing", "Billing questions", target, mapper)
+
+	_, err := tool.Call(parent.Context(), HandoffInput{Request: "Refund order 42"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "[billing] Refund order 42", target.task)
+}
+
+func TestNewHandoffTool_NoExecutionContext(t *testing.T) {
+	target := &handoffTargetStub{answer: "done"}
+	tool := NewHandoffTool("billing", "Billing questions", target, nil)
+
+	_, err := tool.Call(context.Background(), HandoffInput{Request: "Refund order 42"})
+
+	assert.Error(t, err)
+	assert.Empty(t, target.task)
+}
@@ -0,0 +1,70 @@
+package gent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrHookPanic is wrapped by the error published when an event subscriber (hook)
+// panics and the panic is recovered. See [HookPanicPolicy].
+var ErrHookPanic = errors.New("event subscriber panicked")
+
+// HookPanicPolicy determines what happens when an event subscriber panics while
+// handling an event.
+type HookPanicPolicy int
+
+const (
+	// HookPanicRecover recovers the panic, publishes an ErrorEvent with a
+	// [HookPanicError] identifying the subscriber, and continues: the remaining
+	// subscribers still receive the event and the agent loop keeps running.
+	// This is the default, so a buggy third-party hook cannot crash an agent.
+	HookPanicRecover HookPanicPolicy = iota
+
+	// HookPanicPropagate lets the panic propagate to the caller that published the
+	// event, typically crashing the run. Useful in tests and during development.
+	HookPanicPropagate
+)
+
+// HookPanicError describes a recovered subscriber panic. It wraps [ErrHookPanic].
+type HookPanicError struct {
+	// Subscriber is the Go type of the subscriber that panicked, e.g. "*main.Metrics".
+	Subscriber string
+
+	// EventName is the name of the event being handled.
+	EventName string
+
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace captured when the panic was recovered.
+	Stack []byte
+}
+
+// Error implements error.
+func (e *HookPanicError) Error() string {
+	return fmt.Sprintf("%v: %s handling %s: %v", ErrHookPanic, e.Subscriber, e.EventName, e.Value)
+}
+
+// Unwrap returns [ErrHookPanic].
+func (e *HookPanicError) Unwrap() error {
+	return ErrHookPanic
+}
+
+// SetHookPanicPolicy sets how panics in event subscribers are handled. Child contexts
+// spawned afterwards inherit the policy. Usually configured through
+// executor.Config.HookPanicPolicy rather than called directly.
+//
+// The policy is applied by the event publisher (events.Registry); custom
+// [EventPublisher] implementations may ignore it.
+func (ctx *ExecutionContext) SetHookPanicPolicy(policy HookPanicPolicy) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.hookPanicPolicy = policy
+}
+
+// HookPanicPolicy returns how panics in event subscribers are handled.
+func (ctx *ExecutionContext) HookPanicPolicy() HookPanicPolicy {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.hookPanicPolicy
+}
@@ -257,6 +257,44 @@ func (h *LoggerSubscriber) OnAfterToolCall(
 	h.logYAML(event.Output)
 }
 
+// OnRepeatedToolCall logs tool calls skipped by loop detection.
+func (h *LoggerSubscriber) OnRepeatedToolCall(
+	execCtx *gent.ExecutionContext,
+	event *gent.RepeatedToolCallEvent,
+) {
+	h.logEvent(fmt.Sprintf(
+		"RepeatedToolCall: %s (count: %d, action: %s)",
+		event.ToolName, event.Count, event.Action,
+	))
+	h.logYAML(event.Args)
+}
+
+// OnToolProgress logs progress reported by long-running tools.
+func (h *LoggerSubscriber) OnToolProgress(
+	execCtx *gent.ExecutionContext,
+	event *gent.ToolProgressEvent,
+) {
+	if event.Percent < 0 {
+		h.logEvent(fmt.Sprintf("ToolProgress: %s: %s", event.ToolName, event.Message))
+		return
+	}
+	h.logEvent(fmt.Sprintf(
+		"ToolProgress: %s %.0f%%: %s",
+		event.ToolName, event.Percent, event.Message,
+	))
+}
+
+// OnFormatFallback logs output parsed by a fallback format.
+func (h *LoggerSubscriber) OnFormatFallback(
+	execCtx *gent.ExecutionContext,
+	event *gent.FormatFallbackEvent,
+) {
+	h.logEvent(fmt.Sprintf(
+		"FormatFallback: %s output parsed as %s",
+		event.Primary, event.Matched,
+	))
+}
+
 // OnCompaction logs compaction events.
 func (h *LoggerSubscriber) OnCompaction(
 	execCtx *gent.ExecutionContext,
@@ -293,15 +331,18 @@ func (h *LoggerSubscriber) OnLimitExceeded(
 
 // Compile-time checks that LoggerSubscriber implements all subscriber interfaces.
 var (
-	_ gent.BeforeExecutionSubscriber = (*LoggerSubscriber)(nil)
-	_ gent.AfterExecutionSubscriber  = (*LoggerSubscriber)(nil)
-	_ gent.BeforeIterationSubscriber = (*LoggerSubscriber)(nil)
-	_ gent.AfterIterationSubscriber  = (*LoggerSubscriber)(nil)
-	_ gent.ErrorSubscriber           = (*LoggerSubscriber)(nil)
-	_ gent.BeforeModelCallSubscriber = (*LoggerSubscriber)(nil)
-	_ gent.AfterModelCallSubscriber  = (*LoggerSubscriber)(nil)
-	_ gent.BeforeToolCallSubscriber  = (*LoggerSubscriber)(nil)
-	_ gent.AfterToolCallSubscriber   = (*LoggerSubscriber)(nil)
-	_ gent.CompactionSubscriber      = (*LoggerSubscriber)(nil)
-	_ gent.LimitExceededSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.BeforeExecutionSubscriber  = (*LoggerSubscriber)(nil)
+	_ gent.AfterExecutionSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.BeforeIterationSubscriber  = (*LoggerSubscriber)(nil)
+	_ gent.AfterIterationSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.ErrorSubscriber            = (*LoggerSubscriber)(nil)
+	_ gent.BeforeModelCallSubscriber  = (*LoggerSubscriber)(nil)
+	_ gent.AfterModelCallSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.BeforeToolCallSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.AfterToolCallSubscriber    = (*LoggerSubscriber)(nil)
+	_ gent.RepeatedToolCallSubscriber = (*LoggerSubscriber)(nil)
+	_ gent.ToolProgressSubscriber     = (*LoggerSubscriber)(nil)
+	_ gent.FormatFallbackSubscriber   = (*LoggerSubscriber)(nil)
+	_ gent.CompactionSubscriber       = (*LoggerSubscriber)(nil)
+	_ gent.LimitExceededSubscriber    = (*LoggerSubscriber)(nil)
 )
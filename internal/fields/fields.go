@@ -0,0 +1,174 @@
+// Package fields resolves the visible fields of a struct type the way encoding/json
+// does, promoting the fields of embedded structs into their parent.
+//
+// It is shared by the schema generators (which describe the fields a model must
+// produce) and the decoders that need to find promoted fields.
+package fields
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Field is a visible field of a struct type.
+type Field struct {
+	// Name is the key the field is encoded under: the tag name if set, otherwise the
+	// Go field name (lowercased for the "yaml" tag, matching yaml.v3).
+	Name string
+
+	// Options are the tag options after the name, e.g. "omitempty".
+	Options string
+
+	// Field is the struct field itself.
+	Field reflect.StructField
+
+	// Index is the index sequence for reflect.Value.FieldByIndex. Promoted fields have
+	// more than one element: the indexes of the embedded fields, then the field's own.
+	Index []int
+
+	// tagged reports whether the name came from the tag.
+	tagged bool
+}
+
+// Promoted reports whether the field is promoted from an embedded struct.
+func (f Field) Promoted() bool {
+	return len(f.Index) > 1
+}
+
+// HasOption reports whether the tag options contain option.
+func (f Field) HasOption(option string) bool {
+	for opts := f.Options; opts != ""; {
+		var current string
+		current, opts, _ = strings.Cut(opts, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Of returns the visible fields of struct type t in declaration order, using tagKey
+// ("json" or "yaml") for names and options. Pointers to structs are dereferenced.
+//
+// Fields follow encoding/json's rules:
+//   - Fields tagged "-" and unexported fields are skipped.
+//   - Fields of embedded structs without a tag name are promoted into t.
+//   - When promoted fields share a name, the shallowest one wins. At the same depth a
+//     tagged field wins over untagged ones; otherwise all of them are dropped.
+func Of(t reflect.Type, tagKey string) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var candidates []Field
+	depths := make(map[string]int)
+	visited := map[reflect.Type]bool{}
+	current := []level{{typ: t}}
+
+	for depth := 0; len(current) > 0; depth++ {
+		var next []level
+		for _, lvl := range current {
+			// A type embedded at a shallower depth is already covered. The same type
+			// embedded twice at this depth is walked twice, so its fields conflict.
+			if visited[lvl.typ] {
+				continue
+			}
+
+			for i := range lvl.typ.NumField() {
+				sf := lvl.typ.Field(i)
+				tag := sf.Tag.Get(tagKey)
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), lvl.index...), i)
+
+				if sf.Anonymous && name == "" {
+					embedded := sf.Type
+					if embedded.Kind() == reflect.Ptr {
+						embedded = embedded.Elem()
+					}
+					if embedded.Kind() == reflect.Struct {
+						next = append(next, level{typ: embedded, index: index})
+						continue
+					}
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+					if tagKey == "yaml" {
+						name = strings.ToLower(name)
+					}
+				}
+				if _, seen := depths[name]; !seen {
+					depths[name] = depth
+				}
+				candidates = append(candidates, Field{
+					Name:    name,
+					Options: opts,
+					Field:   sf,
+					Index:   index,
+					tagged:  tagged,
+				})
+			}
+		}
+		for _, lvl := range current {
+			visited[lvl.typ] = true
+		}
+		current = next
+	}
+
+	return dominantFields(candidates, depths)
+}
+
+// dominantFields resolves name conflicts between candidates and returns the winners in
+// declaration order. depths holds the shallowest depth each name appears at.
+func dominantFields(candidates []Field, depths map[string]int) []Field {
+	byName := make(map[string][]Field)
+	for _, f := range candidates {
+		if len(f.Index)-1 == depths[f.Name] {
+			byName[f.Name] = append(byName[f.Name], f)
+		}
+	}
+
+	var result []Field
+	for _, group := range byName {
+		if len(group) == 1 {
+			result = append(result, group[0])
+			continue
+		}
+		var tagged []Field
+		for _, f := range group {
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			result = append(result, tagged[0])
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Index, result[j].Index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return result
+}
@@ -24,8 +24,11 @@ func IsLifecycleEvent(event gent.Event) bool {
 		*gent.AfterModelCallEvent,
 		*gent.BeforeToolCallEvent,
 		*gent.AfterToolCallEvent,
+		*gent.RepeatedToolCallEvent,
+		*gent.ToolProgressEvent,
 		*gent.LimitExceededEvent,
 		*gent.ParseErrorEvent,
+		*gent.FormatFallbackEvent,
 		*gent.ValidatorCalledEvent,
 		*gent.ValidatorResultEvent,
 		*gent.ErrorEvent,
@@ -80,10 +83,16 @@ func CountEventTypes(events []gent.Event) map[string]int {
 			counts["BeforeToolCallEvent"]++
 		case *gent.AfterToolCallEvent:
 			counts["AfterToolCallEvent"]++
+		case *gent.RepeatedToolCallEvent:
+			counts["RepeatedToolCallEvent"]++
+		case *gent.ToolProgressEvent:
+			counts["ToolProgressEvent"]++
 		case *gent.LimitExceededEvent:
 			counts["LimitExceededEvent"]++
 		case *gent.ParseErrorEvent:
 			counts["ParseErrorEvent"]++
+		case *gent.FormatFallbackEvent:
+			counts["FormatFallbackEvent"]++
 		case *gent.ValidatorCalledEvent:
 			counts["ValidatorCalledEvent"]++
 		case *gent.ValidatorResultEvent:
@@ -174,6 +183,8 @@ func assertEventEqual(
 		// Skip Response comparison - compare InputTokens/OutputTokens instead
 		assert.Equal(t, exp.InputTokens, act.InputTokens, msgFmt("InputTokens"), index)
 		assert.Equal(t, exp.OutputTokens, act.OutputTokens, msgFmt("OutputTokens"), index)
+		assert.Equal(t, exp.CachedInputTokens, act.CachedInputTokens,
+			msgFmt("CachedInputTokens"), index)
 		assert.GreaterOrEqual(t, act.Duration, time.Duration(0),
 			msgFmt("Duration"), index)
 		assert.Equal(t, exp.Error, act.Error, msgFmt("Error"), index)
@@ -199,6 +210,27 @@ func assertEventEqual(
 			assert.Nil(t, act.Error, msgFmt("Error should be nil"), index)
 		}
 
+	case *gent.RepeatedToolCallEvent:
+		act := actual.(*gent.RepeatedToolCallEvent)
+		assertBaseEvent(t, index, exp.BaseEvent, act.BaseEvent, prevTimestamp)
+		assert.Equal(t, exp.ToolName, act.ToolName, msgFmt("ToolName"), index)
+		assert.Equal(t, exp.Args, act.Args, msgFmt("Args"), index)
+		assert.Equal(t, exp.Count, act.Count, msgFmt("Count"), index)
+		assert.Equal(t, exp.Action, act.Action, msgFmt("Action"), index)
+
+	case *gent.ToolProgressEvent:
+		act := actual.(*gent.ToolProgressEvent)
+		assertBaseEvent(t, index, exp.BaseEvent, act.BaseEvent, prevTimestamp)
+		assert.Equal(t, exp.ToolName, act.ToolName, msgFmt("ToolName"), index)
+		assert.Equal(t, exp.Percent, act.Percent, msgFmt("Percent"), index)
+		assert.Equal(t, exp.Message, act.Message, msgFmt("Message"), index)
+
+	case *gent.FormatFallbackEvent:
+		act := actual.(*gent.FormatFallbackEvent)
+		assertBaseEvent(t, index, exp.BaseEvent, act.BaseEvent, prevTimestamp)
+		assert.Equal(t, exp.Primary, act.Primary, msgFmt("Primary"), index)
+		assert.Equal(t, exp.Matched, act.Matched, msgFmt("Matched"), index)
+
 	case *gent.LimitExceededEvent:
 		act := actual.(*gent.LimitExceededEvent)
 		assertBaseEvent(t, index, exp.BaseEvent, act.BaseEvent, prevTimestamp)
@@ -230,6 +262,8 @@ func assertEventEqual(
 		assert.Equal(t, exp.Answer, act.Answer, msgFmt("Answer"), index)
 		assert.Equal(t, exp.Accepted, act.Accepted, msgFmt("Accepted"), index)
 		assert.Equal(t, exp.Feedback, act.Feedback, msgFmt("Feedback"), index)
+		assert.Equal(t, exp.ContinueWithoutRejection, act.ContinueWithoutRejection,
+			msgFmt("ContinueWithoutRejection"), index)
 
 	case *gent.ErrorEvent:
 		act := actual.(*gent.ErrorEvent)
@@ -253,6 +287,16 @@ func assertEventEqual(
 			act.ScratchpadLengthAfter,
 			msgFmt("ScratchpadLengthAfter"), index,
 		)
+		assert.Equal(t,
+			exp.EstimatedTokensBefore,
+			act.EstimatedTokensBefore,
+			msgFmt("EstimatedTokensBefore"), index,
+		)
+		assert.Equal(t,
+			exp.EstimatedTokensAfter,
+			act.EstimatedTokensAfter,
+			msgFmt("EstimatedTokensAfter"), index,
+		)
 		assert.GreaterOrEqual(t,
 			act.Duration, time.Duration(0),
 			msgFmt("Duration"), index,
@@ -312,10 +356,16 @@ func eventTypeName(event gent.Event) string {
 		return "BeforeToolCallEvent"
 	case *gent.AfterToolCallEvent:
 		return "AfterToolCallEvent"
+	case *gent.RepeatedToolCallEvent:
+		return "RepeatedToolCallEvent"
+	case *gent.ToolProgressEvent:
+		return "ToolProgressEvent"
 	case *gent.LimitExceededEvent:
 		return "LimitExceededEvent"
 	case *gent.ParseErrorEvent:
 		return "ParseErrorEvent"
+	case *gent.FormatFallbackEvent:
+		return "FormatFallbackEvent"
 	case *gent.ValidatorCalledEvent:
 		return "ValidatorCalledEvent"
 	case *gent.ValidatorResultEvent:
@@ -116,6 +116,22 @@ func Terminate(text string) *gent.AgentLoopResult {
 	}
 }
 
+// TerminateBy creates an AgentLoopResult with LATerminate action and text result,
+// accepted by the termination with the given section name.
+func TerminateBy(name, text string) *gent.AgentLoopResult {
+	result := Terminate(text)
+	result.TerminatedBy = name
+	return result
+}
+
+// TerminateByTool creates an AgentLoopResult with LATerminate action and text result,
+// ended by a successful call to the terminal tool with the given name.
+func TerminateByTool(name, text string) *gent.AgentLoopResult {
+	result := TerminateBy(name, text)
+	result.TerminatedByTool = true
+	return result
+}
+
 // -----------------------------------------------------------------------------
 // Limit Helpers
 // -----------------------------------------------------------------------------
@@ -267,6 +283,59 @@ func AfterToolCall(
 	}
 }
 
+// RepeatedToolCall creates a RepeatedToolCallEvent with all fields set.
+func RepeatedToolCall(
+	depth, iteration int,
+	toolName string,
+	args any,
+	count int,
+	action gent.RepeatedToolCallAction,
+) *gent.RepeatedToolCallEvent {
+	return &gent.RepeatedToolCallEvent{
+		BaseEvent: gent.BaseEvent{
+			EventName: gent.EventNameToolCallRepeated,
+			Iteration: iteration,
+			Depth:     depth,
+		},
+		ToolName: toolName,
+		Args:     args,
+		Count:    count,
+		Action:   action,
+	}
+}
+
+// ToolProgress creates a ToolProgressEvent with all fields set.
+func ToolProgress(
+	depth, iteration int,
+	toolName string,
+	percent float64,
+	message string,
+) *gent.ToolProgressEvent {
+	return &gent.ToolProgressEvent{
+		BaseEvent: gent.BaseEvent{
+			EventName: gent.EventNameToolCallProgress,
+			Iteration: iteration,
+			Depth:     depth,
+		},
+		ToolName: toolName,
+		Percent:  percent,
+		Message:  message,
+	}
+}
+
+// FormatFallback creates a FormatFallbackEvent with all fields set.
+func FormatFallback(depth, iteration int, primary, matched string) *gent.FormatFallbackEvent {
+	return &gent.FormatFallbackEvent{
+		BaseEvent: gent.BaseEvent{
+			EventName: gent.EventNameFormatFallback,
+			Iteration: iteration,
+			Depth:     depth,
+		},
+		Primary: primary,
+		Matched: matched,
+	}
+}
+
 // LimitExceeded creates a LimitExceededEvent with all fields set.
 func LimitExceeded(
 	depth, iteration int,
@@ -342,6 +411,19 @@ func ValidatorResult(
 	}
 }
 
+// ValidatorContinue creates a ValidatorResultEvent for a validator that asked for
+// another iteration without rejecting the answer.
+func ValidatorContinue(
+	depth, iteration int,
+	validatorName string,
+	answer any,
+	feedback []gent.FormattedSection,
+) *gent.ValidatorResultEvent {
+	event := ValidatorResult(depth, iteration, validatorName, answer, false, feedback)
+	event.ContinueWithoutRejection = true
+	return event
+}
+
 // Compaction creates a CompactionEvent with all fields set.
 func Compaction(
 	depth, iteration int,
@@ -358,6 +440,19 @@ func Compaction(
 	}
 }
 
+// CompactionWithTokens creates a CompactionEvent with the
+// estimated token counts set.
+func CompactionWithTokens(
+	depth, iteration int,
+	lengthBefore, lengthAfter int,
+	tokensBefore, tokensAfter int,
+) *gent.CompactionEvent {
+	event := Compaction(depth, iteration, lengthBefore, lengthAfter)
+	event.EstimatedTokensBefore = tokensBefore
+	event.EstimatedTokensAfter = tokensAfter
+	return event
+}
+
 // Error creates an ErrorEvent with all fields set.
 func Error(depth, iteration int, err error) *gent.ErrorEvent {
 	return &gent.ErrorEvent{
@@ -1,6 +1,7 @@
 package tt
 
 import (
+	"strings"
 	"time"
 
 	"github.com/rickchristie/gent"
@@ -14,15 +15,21 @@ import (
 // MockModel is a configurable mock that implements gent.Model.
 // It publishes BeforeModelCall and AfterModelCall events as required by the interface.
 type MockModel struct {
-	name      string
-	responses []*gent.ContentResponse
-	errors    []error
-	callCount int
+	name             string
+	responses        []*gent.ContentResponse
+	errors           []error
+	callCount        int
+	structuredOutput bool
+	stopWords        bool
 
 	// CapturedMessages stores the messages passed to each
 	// GenerateContent call. Populated automatically on
 	// every call.
 	CapturedMessages [][]llms.MessageContent
+
+	// CapturedOptions stores the resolved call options of
+	// each GenerateContent call.
+	CapturedOptions []llms.CallOptions
 }
 
 // NewMockModel creates a new MockModel with the default name "test-model".
@@ -36,6 +43,26 @@ func (m *MockModel) WithName(name string) *MockModel {
 	return m
 }
 
+// WithStructuredOutput sets the value returned by
+// SupportsStructuredOutput.
+func (m *MockModel) WithStructuredOutput(enabled bool) *MockModel {
+	m.structuredOutput = enabled
+	return m
+}
+
+// SupportsStructuredOutput implements gent.StructuredOutputModel.
+func (m *MockModel) SupportsStructuredOutput() bool {
+	return m.structuredOutput
+}
+
+// WithStopWordSupport makes the model honor llms.WithStopWords, as providers do:
+// response content is cut before the earliest stop word, and output tokens are scaled
+// down to the share of content kept.
+func (m *MockModel) WithStopWordSupport(enabled bool) *MockModel {
+	m.stopWords = enabled
+	return m
+}
+
 // AddResponse queues a response with the specified content and token counts.
 func (m *MockModel) AddResponse(content string, inputTokens, outputTokens int) *MockModel {
 	m.responses = append(m.responses, &gent.ContentResponse{
@@ -81,19 +108,33 @@ func (m *MockModel) GenerateContent(
 	messages []llms.MessageContent,
 	opts ...llms.CallOption,
 ) (*gent.ContentResponse, error) {
+	// Publish BeforeModelCall event, using the (possibly modified) request
+	if execCtx != nil {
+		beforeEvent := execCtx.PublishBeforeModelCall(m.name, messages)
+		if modified, ok := beforeEvent.Request.([]llms.MessageContent); ok {
+			messages = modified
+		}
+		// A subscriber supplied the response: the mock is not called
+		if resp, ok := beforeEvent.SkippedResponse(); ok {
+			execCtx.PublishSkippedModelCall(m.name, messages, resp)
+			return resp, nil
+		}
+	}
+
 	idx := m.callCount
 	m.callCount++
 
+	var callOpts llms.CallOptions
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+	m.CapturedOptions = append(m.CapturedOptions, callOpts)
+
 	// Capture messages for test verification
 	m.CapturedMessages = append(
 		m.CapturedMessages, messages,
 	)
 
-	// Publish BeforeModelCall event
-	if execCtx != nil {
-		execCtx.PublishBeforeModelCall(m.name, messages)
-	}
-
 	startTime := time.Now()
 
 	// Check for configured error
@@ -116,6 +157,10 @@ func (m *MockModel) GenerateContent(
 		}
 	}
 
+	if resp != nil && m.stopWords {
+		resp = applyStopWords(resp, callOpts.StopWords)
+	}
+
 	duration := time.Since(startTime)
 
 	// Publish AfterModelCall event (stats are auto-updated)
@@ -126,6 +171,35 @@ func (m *MockModel) GenerateContent(
 	return resp, err
 }
 
+// applyStopWords returns a copy of resp with the first choice cut before the earliest
+// stop word, and output tokens scaled to the share of content kept.
+func applyStopWords(resp *gent.ContentResponse, stopWords []string) *gent.ContentResponse {
+	if len(resp.Choices) == 0 {
+		return resp
+	}
+	content := resp.Choices[0].Content
+	cut := len(content)
+	for _, stop := range stopWords {
+		if idx := strings.Index(content, stop); idx >= 0 && idx < cut {
+			cut = idx
+		}
+	}
+	if cut == len(content) {
+		return resp
+	}
+
+	stopped := *resp
+	choice := *resp.Choices[0]
+	choice.Content = content[:cut]
+	stopped.Choices = append([]*gent.ContentChoice{&choice}, resp.Choices[1:]...)
+	if resp.Info != nil {
+		info := *resp.Info
+		info.OutputTokens = resp.Info.OutputTokens * cut / len(content)
+		stopped.Info = &info
+	}
+	return &stopped
+}
+
 // -----------------------------------------------------------------------------
 // MockToolChain - implements gent.ToolChain with proper event publishing
 // -----------------------------------------------------------------------------
@@ -474,8 +548,14 @@ func (t *MockTermination) ShouldTerminate(
 
 		result := t.validator.Validate(execCtx, content)
 		if !result.Accepted {
-			// Publish ValidatorResult event (stats are auto-updated)
-			execCtx.PublishValidatorResult(validatorName, content, false, result.Feedback)
+			status := gent.TerminationAnswerRejected
+			if result.ContinueWithoutRejection {
+				execCtx.PublishValidatorContinue(validatorName, content, result.Feedback)
+				status = gent.TerminationAnswerContinued
+			} else {
+				// Publish ValidatorResult event (stats are auto-updated)
+				execCtx.PublishValidatorResult(validatorName, content, false, result.Feedback)
+			}
 
 			var feedback []gent.ContentPart
 			for _, section := range result.Feedback {
@@ -485,7 +565,7 @@ func (t *MockTermination) ShouldTerminate(
 			}
 
 			return &gent.TerminationResult{
-				Status:  gent.TerminationAnswerRejected,
+				Status:  status,
 				Content: feedback,
 			}
 		}
@@ -557,18 +637,23 @@ func (f *MockFormat) Parse(
 			return nil, call.Err
 		}
 
-		// Success resets consecutive counter
+		// Success resets consecutive counters and records section sizes
 		if execCtx != nil {
 			execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+			execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+			execCtx.RecordSectionBytes(call.Result)
 		}
 		return call.Result, nil
 	}
 
 	// Default: terminate
+	result := map[string][]string{"answer": {"done"}}
 	if execCtx != nil {
 		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorConsecutive)
+		execCtx.Stats().ResetGauge(gent.SGFormatParseErrorIdenticalConsecutive)
+		execCtx.RecordSectionBytes(result)
 	}
-	return map[string][]string{"answer": {"done"}}, nil
+	return result, nil
 }
 
 // FormatSections implements gent.TextFormat.
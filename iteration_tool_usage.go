@@ -0,0 +1,46 @@
+package gent
+
+// IterationToolUsage is what toolchains executed in the current iteration so far; see
+// [ExecutionContext.IterationToolUsage].
+type IterationToolUsage struct {
+	// Calls is the number of tool calls executed.
+	Calls int
+
+	// ObservationBytes is the size of the tool outputs rendered into observations,
+	// truncation markers included.
+	ObservationBytes int
+}
+
+// iterationToolUsage is the IterationToolUsage recorded for one iteration.
+type iterationToolUsage struct {
+	iteration int
+	usage     IterationToolUsage
+}
+
+// IterationToolUsage returns the tool calls and observation bytes recorded for the
+// current iteration with AddIterationToolUsage.
+//
+// An AgentLoop that finds several action sections in a response calls the toolchain
+// once per section. Toolchains use this to apply their per-iteration caps (e.g.
+// toolchain.YAML.WithMaxToolCallsPerIteration) across all sections of the iteration
+// rather than to each section separately.
+func (ctx *ExecutionContext) IterationToolUsage() IterationToolUsage {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	if ctx.toolUsage.iteration != ctx.iteration {
+		return IterationToolUsage{}
+	}
+	return ctx.toolUsage.usage
+}
+
+// AddIterationToolUsage adds calls and observation bytes to the usage of the current
+// iteration. The usage starts over at zero with each iteration.
+func (ctx *ExecutionContext) AddIterationToolUsage(calls, observationBytes int) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.toolUsage.iteration != ctx.iteration {
+		ctx.toolUsage = iterationToolUsage{iteration: ctx.iteration}
+	}
+	ctx.toolUsage.usage.Calls += calls
+	ctx.toolUsage.usage.ObservationBytes += observationBytes
+}
@@ -0,0 +1,50 @@
+package gent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionContext_IterationToolUsage(t *testing.T) {
+	type input struct {
+		nextIteration bool
+	}
+
+	type expected struct {
+		usage IterationToolUsage
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "usage adds up within an iteration",
+			input:    input{},
+			expected: expected{usage: IterationToolUsage{Calls: 3, ObservationBytes: 150}},
+		},
+		{
+			name:     "usage starts over with the next iteration",
+			input:    input{nextIteration: true},
+			expected: expected{usage: IterationToolUsage{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+			execCtx.AddIterationToolUsage(2, 100)
+			execCtx.AddIterationToolUsage(1, 50)
+
+			if tc.input.nextIteration {
+				execCtx.IncrementIteration()
+			}
+
+			assert.Equal(t, tc.expected.usage, execCtx.IterationToolUsage())
+		})
+	}
+}
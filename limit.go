@@ -53,6 +53,58 @@ const (
 //	// Stop if ANY tool has more than 5 errors
 //	{Type: LimitKeyPrefix, Key: SCToolCallsErrorFor, MaxValue: 5}
 //
+// # Combining Exact and Prefix Limits
+//
+// Every limit is evaluated independently; there is no override
+// between limits that match the same key. An exact limit never relaxes
+// a prefix or total limit, so the tightest matching limit wins.
+//
+// When several limits are exceeded by the same update, the one that
+// is enforced and reported by [ExecutionContext.ExceededLimit] is
+// chosen deterministically:
+//  1. Exact key limits take precedence over prefix limits.
+//  2. Among those, the limit with the lowest MaxValue wins.
+//  3. Remaining ties go to the first limit in slice order.
+//
+// [ExecutionContext.ExceededLimitKey] reports the stat key that exceeded
+// it. For a prefix limit matching several exceeded keys, that is the
+// lexically smallest one, e.g. SCToolCallsFor+"lookup" before
+// SCToolCallsFor+"search".
+//
+// To give keys different budgets, use one exact limit per key rather
+// than a prefix (a prefix also matches longer names, e.g.
+// "schema" matches "schema_strict"):
+//
+//	// Schema validator may reject 5 times, toxicity only twice
+//	{Type: LimitExactKey, Key: SCAnswerRejectedBy + "schema", MaxValue: 5}
+//	{Type: LimitExactKey, Key: SCAnswerRejectedBy + "toxicity", MaxValue: 2}
+//
+// # Callback Limits
+//
+// Set OnExceeded to react to a threshold instead of terminating, e.g. to switch the
+// agent to a more conservative mode when the scratchpad grows or errors pile up. The
+// LimitExceededEvent is still published, then the callback runs synchronously inside
+// the stat update that crossed the threshold. Returning [LimitActionContinue] lets
+// execution proceed; returning [LimitActionTerminate] terminates as for any other limit.
+//
+//	conservative := false
+//	limits := append(gent.DefaultLimits(), gent.Limit{
+//	    Type:     gent.LimitExactKey,
+//	    Key:      gent.SGScratchpadLength,
+//	    MaxValue: 20,
+//	    OnExceeded: func(execCtx *gent.ExecutionContext) gent.LimitAction {
+//	        conservative = true // read by the agent, a compaction trigger, etc.
+//	        return gent.LimitActionContinue
+//	    },
+//	})
+//
+// After continuing, the callback does not fire again until the stat drops back to
+// MaxValue or below (e.g. a gauge is reset) and exceeds it again. Counters never
+// decrease, so a counter callback fires at most once per context.
+//
+// The callback must not block: it runs while the stat is being updated, typically in
+// the middle of a model or tool call. It may update stats itself.
+//
 // # Hierarchical Limits
 //
 // Counter stats propagate from child to parent contexts. A limit on
@@ -74,8 +126,26 @@ type Limit struct {
 	// The comparison is: currentValue > MaxValue (not >=).
 	// For counters, the int64 value is compared as float64.
 	MaxValue float64
+
+	// OnExceeded, if set, is called when the limit is exceeded and decides whether
+	// execution terminates. See "Callback Limits" above. Nil terminates immediately.
+	OnExceeded func(execCtx *ExecutionContext) LimitAction `json:"-"`
 }
 
+// LimitAction is returned by [Limit.OnExceeded] to decide what happens after a
+// callback limit is exceeded.
+type LimitAction int
+
+const (
+	// LimitActionTerminate terminates execution with [TerminationLimitExceeded],
+	// the same as a limit without a callback.
+	LimitActionTerminate LimitAction = iota
+
+	// LimitActionContinue lets execution proceed. The limit is not reported by
+	// [ExecutionContext.ExceededLimit].
+	LimitActionContinue
+)
+
 // DefaultLimits returns a set of sensible default limits.
 //
 // These defaults prevent runaway execution:
@@ -84,6 +154,7 @@ type Limit struct {
 //     termination)
 //   - 3 consecutive tool call errors
 //   - 3 consecutive code execution errors
+//   - 3 consecutive and 10 total empty model responses
 //   - 10 total answer rejections
 //
 // Override with ExecutionContext.SetLimits():
@@ -129,6 +200,13 @@ func DefaultLimits() []Limit {
 			MaxValue: 3,
 		},
 
+		// Stop after 3 consecutive empty model responses (gauge)
+		{
+			Type:     LimitExactKey,
+			Key:      SGEmptyResponseConsecutive,
+			MaxValue: 3,
+		},
+
 		// Stop after 3 consecutive toolchain parse errors (gauge)
 		{
 			Type:     LimitExactKey,
@@ -157,6 +235,13 @@ func DefaultLimits() []Limit {
 			MaxValue: 3,
 		},
 
+		// Stop after 10 total empty model responses, consecutive or not
+		{
+			Type:     LimitExactKey,
+			Key:      SCEmptyResponseTotal,
+			MaxValue: 10,
+		},
+
 		// Stop after 10 total answer rejections (by validators)
 		{
 			Type:     LimitExactKey,
@@ -165,3 +250,49 @@ func DefaultLimits() []Limit {
 		},
 	}
 }
+
+// NoProgressLimit returns a limit on [SGNoProgressConsecutive], terminating execution
+// once more than maxConsecutive iterations in a row made no progress according to
+// executor.Config.ProgressFunc.
+//
+// Example:
+//
+//	config.ProgressFunc = func(execCtx *gent.ExecutionContext) bool {
+//	    return execCtx.Stats().GetCounter("app:records_updated") > lastSeen
+//	}
+//	execCtx.SetLimits(append(gent.DefaultLimits(), gent.NoProgressLimit(3)))
+func NoProgressLimit(maxConsecutive float64) Limit {
+	return Limit{
+		Type:     LimitExactKey,
+		Key:      SGNoProgressConsecutive,
+		MaxValue: maxConsecutive,
+	}
+}
+
+// ScratchpadBytesLimit returns a limit on [SGScratchpadBytes], a byte cap on the
+// scratchpad that needs no tokenizer. When the scratchpad exceeds maxBytes:
+//   - With compaction configured (see ExecutionContext.SetCompaction), the executor
+//     compacts it before the next iteration, whether or not the trigger fires.
+//     Execution terminates with [TerminationLimitExceeded] only if the compacted
+//     scratchpad still exceeds maxBytes.
+//   - Without compaction, execution terminates as for any other limit.
+//
+// Example:
+//
+//	limits := append(gent.DefaultLimits(), gent.ScratchpadBytesLimit(64*1024))
+//	execCtx.SetLimits(limits)
+func ScratchpadBytesLimit(maxBytes float64) Limit {
+	limit := Limit{
+		Type:     LimitExactKey,
+		Key:      SGScratchpadBytes,
+		MaxValue: maxBytes,
+	}
+	limit.OnExceeded = func(execCtx *ExecutionContext) LimitAction {
+		if execCtx.CompactionStrategy() == nil {
+			return LimitActionTerminate
+		}
+		execCtx.RequestCompaction(limit)
+		return LimitActionContinue
+	}
+	return limit
+}
@@ -42,6 +42,12 @@ type Model interface {
 	//
 	// The streamId should be unique across concurrent calls. If empty, chunks
 	// are still emitted but cannot be filtered by stream ID.
+	//
+	// Skipped Calls:
+	// A BeforeModelCallEvent subscriber may supply the response itself (see
+	// BeforeModelCallEvent.SkippedResponse). Implementations must then not call the
+	// provider: they publish execCtx.PublishSkippedModelCall instead of
+	// PublishAfterModelCall, emit the response as a single chunk and return it.
 	GenerateContent(
 		execCtx *ExecutionContext,
 		streamId string,
@@ -75,6 +81,12 @@ type ContentChoice struct {
 	ToolCalls []llms.ToolCall
 
 	// ReasoningContent contains reasoning/thinking content if supported.
+	//
+	// Adapters for models with a dedicated reasoning channel (e.g. OpenAI reasoning
+	// summaries, Anthropic extended thinking) must put that text here and keep it out of
+	// Content, so Content holds only the final output the TextFormat parses. Streaming
+	// adapters emit it in [StreamChunk].ReasoningContent. Leave it empty when the model
+	// does not reason natively.
 	ReasoningContent string
 }
 
@@ -113,6 +125,9 @@ type GenerationInfo struct {
 	// This is normalized across providers:
 	//   - OpenAI: ReasoningTokens / CompletionReasoningTokens
 	//   - Anthropic: (extracted from ThinkingTokens if available)
+	//
+	// Adapters should report it whenever the provider does, counting the same tokens
+	// in OutputTokens as the provider bills them. Tracked in SCReasoningTokens.
 	ReasoningTokens int
 
 	// RawGenerationInfo contains the original provider-specific GenerationInfo map.
@@ -0,0 +1,151 @@
+package gent
+
+import (
+	"slices"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ModelMiddleware wraps a [Model] to add cross-cutting behavior such as caching,
+// retries, logging or recording, without changing the model or the agent using it.
+//
+// A middleware receives the next model in the chain and returns a model that usually
+// calls it. Because the returned model sees the messages before they are passed on and
+// the [ContentResponse] before it is returned, it can inspect or modify both, or skip
+// the call entirely (e.g. on a cache hit).
+//
+// # Writing a Middleware
+//
+// [ModelFunc] turns a function into a Model:
+//
+//	func WithSystemReminder(reminder string) gent.ModelMiddleware {
+//	    return func(next gent.Model) gent.Model {
+//	        return gent.ModelFunc(func(
+//	            execCtx *gent.ExecutionContext,
+//	            streamId, streamTopicId string,
+//	            messages []llms.MessageContent,
+//	            options ...llms.CallOption,
+//	        ) (*gent.ContentResponse, error) {
+//	            messages = append(messages, llms.TextParts(llms.ChatMessageTypeSystem, reminder))
+//	            return next.GenerateContent(execCtx, streamId, streamTopicId, messages, options...)
+//	        })
+//	    }
+//	}
+//
+// Middlewares that answer without calling next must still emit the response with
+// execCtx.EmitChunk, as required by [Model]. They do not publish model call events, so
+// no tokens are counted for calls that never reach the provider.
+//
+// See the models package for ready-made middlewares.
+type ModelMiddleware func(next Model) Model
+
+// ModelFunc is a function that implements [Model]. It is mainly used to write
+// [ModelMiddleware].
+type ModelFunc func(
+	execCtx *ExecutionContext,
+	streamId string,
+	streamTopicId string,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*ContentResponse, error)
+
+// GenerateContent calls f.
+func (f ModelFunc) GenerateContent(
+	execCtx *ExecutionContext,
+	streamId string,
+	streamTopicId string,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*ContentResponse, error) {
+	return f(execCtx, streamId, streamTopicId, messages, options...)
+}
+
+// Chain wraps base with middlewares. The first middleware is the outermost: it sees
+// each request first and each response last.
+//
+//	// The recorder sees every call, including cache hits.
+//	model := gent.Chain(base,
+//	    recorder.Middleware(),
+//	    models.WithResponseCache(models.NewMemoryResponseCache()),
+//	)
+//
+// The chained model implements [StructuredOutputModel], reporting whether base supports
+// structured output, so agents keep using constrained decoding. It does not implement
+// [StreamingModel]: streaming calls would bypass the middlewares.
+func Chain(base Model, middlewares ...ModelMiddleware) Model {
+	model := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		model = middlewares[i](model)
+	}
+	return &chainedModel{Model: model, base: base}
+}
+
+// chainedModel is the result of Chain. It forwards capability checks to the base model.
+type chainedModel struct {
+	Model
+	base Model
+}
+
+// SupportsStructuredOutput implements StructuredOutputModel.
+func (m *chainedModel) SupportsStructuredOutput() bool {
+	structured, ok := m.base.(StructuredOutputModel)
+	return ok && structured.SupportsStructuredOutput()
+}
+
+// Compile-time check that chainedModel implements StructuredOutputModel.
+var _ StructuredOutputModel = (*chainedModel)(nil)
+
+// modelCallInterceptor is a hook registered with InterceptModelCalls.
+type modelCallInterceptor struct {
+	fn func(event *BeforeModelCallEvent)
+}
+
+// InterceptModelCalls registers fn to run on each [BeforeModelCallEvent] published by
+// this context, after the event subscribers, until the returned remove function is
+// called.
+//
+// It lets a [ModelMiddleware] act on the request the model adapter is about to send,
+// including ephemeral messages and subscriber changes, and answer it through
+// BeforeModelCallEvent.Skip, so the adapter still publishes the model call events:
+//
+//	remove := execCtx.InterceptModelCalls(func(event *gent.BeforeModelCallEvent) {
+//	    if response, ok := lookup(event.Request); ok {
+//	        event.Response = response
+//	        event.Skip = true
+//	    }
+//	})
+//	defer remove()
+//	return next.GenerateContent(execCtx, streamId, streamTopicId, messages, options...)
+//
+// Interceptors belong to this context only, and see every model call made with it while
+// registered: register one per call, as model calls on a context are sequential.
+func (ctx *ExecutionContext) InterceptModelCalls(
+	fn func(event *BeforeModelCallEvent),
+) (remove func()) {
+	interceptor := &modelCallInterceptor{fn: fn}
+	ctx.mu.Lock()
+	ctx.modelCallInterceptors = append(ctx.modelCallInterceptors, interceptor)
+	ctx.mu.Unlock()
+
+	return func() {
+		ctx.mu.Lock()
+		defer ctx.mu.Unlock()
+		for i, registered := range ctx.modelCallInterceptors {
+			if registered == interceptor {
+				ctx.modelCallInterceptors = slices.Delete(ctx.modelCallInterceptors, i, i+1)
+				return
+			}
+		}
+	}
+}
+
+// interceptModelCall runs the registered interceptors on event, in registration order.
+func (ctx *ExecutionContext) interceptModelCall(event *BeforeModelCallEvent) {
+	ctx.mu.RLock()
+	interceptors := slices.Clone(ctx.modelCallInterceptors)
+	ctx.mu.RUnlock()
+
+	for _, interceptor := range interceptors {
+		interceptor.fn(event)
+	}
+}
@@ -0,0 +1,175 @@
+package gent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// structuredModel is a base Model that echoes the last message's text.
+type structuredModel struct {
+	structured bool
+}
+
+func (m *structuredModel) GenerateContent(
+	_ *ExecutionContext,
+	_, _ string,
+	messages []llms.MessageContent,
+	_ ...llms.CallOption,
+) (*ContentResponse, error) {
+	last := messages[len(messages)-1].Parts[0].(llms.TextContent).Text
+	return &ContentResponse{Choices: []*ContentChoice{{Content: last}}}, nil
+}
+
+func (m *structuredModel) SupportsStructuredOutput() bool { return m.structured }
+
+// tagMiddleware appends tag to the request text and to the response content, and logs
+// the order in which middlewares see the request and the response.
+func tagMiddleware(tag string, log *[]string) ModelMiddleware {
+	return func(next Model) Model {
+		return ModelFunc(func(
+			execCtx *ExecutionContext,
+			streamId, streamTopicId string,
+			messages []llms.MessageContent,
+			options ...llms.CallOption,
+		) (*ContentResponse, error) {
+			*log = append(*log, "request "+tag)
+			text := messages[0].Parts[0].(llms.TextContent).Text
+			messages = []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, text+tag)}
+
+			response, err := next.GenerateContent(
+				execCtx, streamId, streamTopicId, messages, options...)
+
+			*log = append(*log, "response "+tag)
+			response.Choices[0].Content += "|" + tag
+			return response, err
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	type input struct {
+		tags       []string
+		structured bool
+	}
+
+	type expected struct {
+		content    string
+		log        []string
+		structured bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "no middlewares",
+			input: input{},
+			expected: expected{
+				content: "hi",
+			},
+		},
+		{
+			name:  "first middleware is outermost",
+			input: input{tags: []string{"A", "B"}},
+			expected: expected{
+				content: "hiAB|B|A",
+				log:     []string{"request A", "request B", "response B", "response A"},
+			},
+		},
+		{
+			name:  "structured output support comes from the base model",
+			input: input{tags: []string{"A"}, structured: true},
+			expected: expected{
+				content:    "hiA|A",
+				log:        []string{"request A", "response A"},
+				structured: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var log []string
+			var middlewares []ModelMiddleware
+			for _, tag := range tc.input.tags {
+				middlewares = append(middlewares, tagMiddleware(tag, &log))
+			}
+
+			model := Chain(&structuredModel{structured: tc.input.structured}, middlewares...)
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			response, err := model.GenerateContent(execCtx, "", "",
+				[]llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "hi")})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected.content, response.Choices[0].Content)
+			assert.Equal(t, tc.expected.log, log)
+
+			structured, ok := model.(StructuredOutputModel)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected.structured, structured.SupportsStructuredOutput())
+		})
+	}
+}
+
+func TestExecutionContext_InterceptModelCalls(t *testing.T) {
+	type input struct {
+		removeBefore bool
+	}
+
+	type expected struct {
+		calls int
+		skip  bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "interceptor sees the request and can skip the call",
+			input:    input{},
+			expected: expected{calls: 1, skip: true},
+		},
+		{
+			name:     "removed interceptor no longer runs",
+			input:    input{removeBefore: true},
+			expected: expected{calls: 0, skip: false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.EnqueueEphemeralMessage(llms.ChatMessageTypeSystem, "reminder")
+
+			calls := 0
+			var seen any
+			remove := execCtx.InterceptModelCalls(func(event *BeforeModelCallEvent) {
+				calls++
+				seen = event.Request
+				event.Response = &ContentResponse{Choices: []*ContentChoice{{Content: "hit"}}}
+				event.Skip = true
+			})
+			if tc.input.removeBefore {
+				remove()
+			}
+
+			prompt := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "hi")}
+			event := execCtx.PublishBeforeModelCall("model", prompt)
+			remove()
+
+			assert.Equal(t, tc.expected.calls, calls)
+			assert.Equal(t, tc.expected.skip, event.Skip)
+			if tc.expected.calls > 0 {
+				assert.Equal(t, event.Request, seen, "interceptor sees ephemeral messages")
+				assert.Len(t, seen, 2)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package langchain adapts any LangChainGo model ([llms.Model]) to [gent.Model].
+//
+//	llm, _ := anthropic.New(anthropic.WithModel("claude-sonnet-4-5"))
+//	model := langchain.Wrap(llm)
+//
+// Wrap returns a [models.LCGWrapper], which:
+//   - Calls llms.Model.GenerateContent with gent's messages and call options, publishing
+//     BeforeModelCallEvent and AfterModelCallEvent on the ExecutionContext.
+//   - Reads token usage from the provider's GenerationInfo keys into
+//     [gent.GenerationInfo]: input, output, total, cached input and reasoning tokens,
+//     under OpenAI/Ollama, Anthropic and Google/Bedrock naming. Unknown providers
+//     report zero tokens; the raw map stays in GenerationInfo.RawGenerationInfo.
+//   - Implements [gent.StreamingModel]: GenerateContentStream maps to LangChainGo's
+//     llms.WithStreamingReasoningFunc, so reasoning and content chunks arrive
+//     separately. Providers that do not stream send no chunks; the whole response is
+//     still returned by the stream's Response.
+//
+// For default temperature, max tokens and stop sequences, or a model name in events,
+// use [models.NewLCGWrapper] with WithParams and WithModelName instead.
+package langchain
+
+import (
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/models"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Wrap returns a gent.Model that calls llm. See the package documentation.
+func Wrap(llm llms.Model) gent.Model {
+	return models.NewLCGWrapper(llm)
+}
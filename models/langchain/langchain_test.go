@@ -0,0 +1,202 @@
+package langchain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// providerModel is an llms.Model that answers "ok" with a fixed GenerationInfo, as a
+// LangChainGo provider would report it. When streaming, it sends one reasoning chunk
+// and then the content one byte at a time.
+type providerModel struct {
+	info map[string]any
+}
+
+func (m *providerModel) GenerateContent(
+	ctx context.Context,
+	_ []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if stream := opts.StreamingReasoningFunc; stream != nil {
+		if err := stream(ctx, []byte("thinking"), nil); err != nil {
+			return nil, err
+		}
+		for _, b := range []byte("ok") {
+			if err := stream(ctx, nil, []byte{b}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: "ok", GenerationInfo: m.info}},
+	}, nil
+}
+
+func (m *providerModel) Call(
+	ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestWrap_TokenUsage(t *testing.T) {
+	type input struct {
+		info map[string]any
+	}
+
+	type expected struct {
+		info gent.GenerationInfo
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "openai",
+			input: input{info: map[string]any{
+				"PromptTokens":       120,
+				"CompletionTokens":   30,
+				"TotalTokens":        150,
+				"ReasoningTokens":    10,
+				"ThinkingTokens":     10,
+				"PromptCachedTokens": 64,
+			}},
+			expected: expected{info: gent.GenerationInfo{
+				InputTokens:       120,
+				OutputTokens:      30,
+				TotalTokens:       150,
+				CachedInputTokens: 64,
+				ReasoningTokens:   10,
+			}},
+		},
+		{
+			name: "anthropic",
+			input: input{info: map[string]any{
+				"InputTokens":              200,
+				"OutputTokens":             40,
+				"CacheCreationInputTokens": 0,
+				"CacheReadInputTokens":     150,
+			}},
+			expected: expected{info: gent.GenerationInfo{
+				InputTokens:       200,
+				OutputTokens:      40,
+				TotalTokens:       240,
+				CachedInputTokens: 150,
+			}},
+		},
+		{
+			name: "google",
+			input: input{info: map[string]any{
+				"input_tokens":         int32(80),
+				"output_tokens":        int32(20),
+				"total_tokens":         int32(100),
+				"PromptTokens":         int32(80),
+				"CompletionTokens":     int32(20),
+				"TotalTokens":          int32(100),
+				"CachedTokens":         int32(32),
+				"CacheReadInputTokens": int32(32),
+				"ThinkingTokens":       0,
+			}},
+			expected: expected{info: gent.GenerationInfo{
+				InputTokens:       80,
+				OutputTokens:      20,
+				TotalTokens:       100,
+				CachedInputTokens: 32,
+			}},
+		},
+		{
+			name: "bedrock",
+			input: input{info: map[string]any{
+				"input_tokens":  5,
+				"output_tokens": 7,
+			}},
+			expected: expected{info: gent.GenerationInfo{
+				InputTokens:  5,
+				OutputTokens: 7,
+				TotalTokens:  12,
+			}},
+		},
+		{
+			name: "ollama",
+			input: input{info: map[string]any{
+				"PromptTokens":     60,
+				"CompletionTokens": 15,
+				"TotalTokens":      75,
+				"ThinkingTokens":   0,
+				"CachedTokens":     20,
+			}},
+			expected: expected{info: gent.GenerationInfo{
+				InputTokens:       60,
+				OutputTokens:      15,
+				TotalTokens:       75,
+				CachedInputTokens: 20,
+			}},
+		},
+		{
+			name:     "unknown provider reports no usage",
+			input:    input{info: map[string]any{"usage": "n/a"}},
+			expected: expected{info: gent.GenerationInfo{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			model := Wrap(&providerModel{info: tc.input.info})
+
+			response, err := model.GenerateContent(execCtx, "1", "llm", nil)
+
+			require.NoError(t, err)
+			info := *response.Info
+			info.Duration = 0
+			info.RawGenerationInfo = nil
+			assert.Equal(t, tc.expected.info, info)
+			assert.Equal(t, tc.input.info, response.Info.RawGenerationInfo)
+			assert.Equal(t, int64(tc.expected.info.InputTokens),
+				execCtx.Stats().GetTotalInputTokens())
+			assert.Equal(t, int64(tc.expected.info.OutputTokens),
+				execCtx.Stats().GetTotalOutputTokens())
+		})
+	}
+}
+
+func TestWrap_Streaming(t *testing.T) {
+	llm := &providerModel{info: map[string]any{"PromptTokens": 3, "CompletionTokens": 2}}
+	model, ok := Wrap(llm).(gent.StreamingModel)
+	require.True(t, ok, "the wrapped model streams")
+
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	stream, err := model.GenerateContentStream(execCtx, "1", "llm", nil)
+	require.NoError(t, err)
+
+	var reasoning, content []string
+	for chunk := range stream.Chunks() {
+		require.NoError(t, chunk.Err)
+		if chunk.ReasoningContent != "" {
+			reasoning = append(reasoning, chunk.ReasoningContent)
+		}
+		if chunk.Content != "" {
+			content = append(content, chunk.Content)
+		}
+	}
+	response, err := stream.Response()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"thinking"}, reasoning)
+	assert.Equal(t, []string{"o", "k"}, content)
+	assert.Equal(t, "ok", response.Choices[0].Content)
+	assert.Equal(t, 5, response.Info.TotalTokens)
+	assert.Equal(t, int64(3), execCtx.Stats().GetTotalInputTokens())
+}
@@ -19,9 +19,77 @@ import (
 //
 //	// Generate content with event publishing and streaming support
 //	response, err := model.GenerateContent(execCtx, "req-1", "llm", messages)
+//
+// Any LangChainGo provider works: token usage is read from the provider's GenerationInfo
+// keys (OpenAI/Ollama, Anthropic and Google/Bedrock naming) into gent.GenerationInfo.
+//
+// Streaming maps to LangChainGo's streaming callback: GenerateContentStream passes
+// llms.WithStreamingReasoningFunc, so reasoning and content chunks arrive separately
+// (llms.WithStreamThinking is on by default). Providers that do not stream still work;
+// the stream then yields the whole response when the call returns.
 type LCGWrapper struct {
-	model     llms.Model
-	modelName string // Optional model name for events
+	model            llms.Model
+	modelName        string      // Optional model name for events
+	structuredOutput bool        // Whether the provider honors JSON mode
+	params           ModelParams // Default call parameters
+}
+
+// ModelParams are default call parameters that LCGWrapper translates into LangChainGo
+// call options on every call. Unset fields are left to the provider's defaults. Options
+// passed to a single call take precedence, except stop words, which are merged with Stop.
+type ModelParams struct {
+	// Temperature is the sampling temperature. Nil uses the provider's default.
+	Temperature *float64
+
+	// MaxTokens caps the output tokens per call. Zero uses the provider's default.
+	MaxTokens int
+
+	// Stop lists sequences that end generation. Empty uses the provider's default.
+	Stop []string
+}
+
+// options returns the LangChainGo call options for the set fields.
+func (p ModelParams) options() []llms.CallOption {
+	var opts []llms.CallOption
+	if p.Temperature != nil {
+		opts = append(opts, llms.WithTemperature(*p.Temperature))
+	}
+	if p.MaxTokens > 0 {
+		opts = append(opts, llms.WithMaxTokens(p.MaxTokens))
+	}
+	if len(p.Stop) > 0 {
+		opts = append(opts, llms.WithStopWords(p.Stop))
+	}
+	return opts
+}
+
+// with returns the params' call options followed by options. Stop words set by options
+// are merged with Stop instead of replacing them, so a format's stop sequences do not
+// drop those configured on the model.
+func (p ModelParams) with(options []llms.CallOption) []llms.CallOption {
+	opts := make([]llms.CallOption, 0, len(options)+4)
+	opts = append(opts, p.options()...)
+	opts = append(opts, options...)
+	if len(p.Stop) == 0 || len(options) == 0 {
+		return opts
+	}
+
+	var call llms.CallOptions
+	for _, opt := range options {
+		opt(&call)
+	}
+	if len(call.StopWords) == 0 {
+		return opts
+	}
+	merged := make([]string, 0, len(p.Stop)+len(call.StopWords))
+	seen := make(map[string]bool, cap(merged))
+	for _, stop := range append(append([]string{}, p.Stop...), call.StopWords...) {
+		if !seen[stop] {
+			seen[stop] = true
+			merged = append(merged, stop)
+		}
+	}
+	return append(opts, llms.WithStopWords(merged))
 }
 
 // NewLCGWrapper creates a new LCGWrapper wrapping the given llms.Model.
@@ -38,6 +106,32 @@ func (m *LCGWrapper) WithModelName(name string) *LCGWrapper {
 	return m
 }
 
+// WithStructuredOutput marks the wrapped provider as supporting constrained JSON
+// decoding (e.g., Ollama's `format: json`). When enabled, agents using a
+// [gent.StructuredOutputFormat] pass [gent.WithStructuredOutput] on each call and
+// LangChainGo maps it to the provider's JSON mode.
+//
+// Leave disabled for providers without JSON mode; the schema is then only described
+// in the prompt.
+// Returns the model for chaining.
+func (m *LCGWrapper) WithStructuredOutput(enabled bool) *LCGWrapper {
+	m.structuredOutput = enabled
+	return m
+}
+
+// WithParams sets default call parameters (temperature, max tokens, stop sequences) for
+// every call. Options passed to GenerateContent or GenerateContentStream override them.
+// Returns the model for chaining.
+func (m *LCGWrapper) WithParams(params ModelParams) *LCGWrapper {
+	m.params = params
+	return m
+}
+
+// SupportsStructuredOutput implements [gent.StructuredOutputModel].
+func (m *LCGWrapper) SupportsStructuredOutput() bool {
+	return m.structuredOutput
+}
+
 // Unwrap returns the underlying llms.Model.
 func (m *LCGWrapper) Unwrap() llms.Model {
 	return m.model
@@ -65,11 +159,26 @@ func (m *LCGWrapper) GenerateContent(
 		requestMessages = modifiedRequest
 	}
 
+	// A subscriber supplied the response: skip the underlying model
+	if response, ok := beforeEvent.SkippedResponse(); ok {
+		execCtx.PublishSkippedModelCall(m.modelName, requestMessages, response)
+		if len(response.Choices) > 0 {
+			execCtx.EmitChunk(gent.StreamChunk{
+				Content:          response.Choices[0].Content,
+				ReasoningContent: response.Choices[0].ReasoningContent,
+				StreamId:         streamId,
+				StreamTopicId:    streamTopicId,
+			})
+		}
+		return response, nil
+	}
+
 	// Call the underlying model
 	ctx := execCtx.Context()
-	startTime := time.Now()
-	lcgResponse, err := m.model.GenerateContent(ctx, requestMessages, options...)
-	duration := time.Since(startTime)
+	startTime := execCtx.Clock().Now()
+	opts := m.params.with(options)
+	lcgResponse, err := m.model.GenerateContent(ctx, requestMessages, opts...)
+	duration := execCtx.Clock().Now().Sub(startTime)
 
 	// Convert response
 	var response *gent.ContentResponse
@@ -252,6 +361,9 @@ func getIntFromMap(m map[string]any, key string) int {
 // the producer even if the consumer is slow or not reading.
 //
 // When execCtx is provided, chunks are also emitted to streaming subscribers via EmitChunk.
+// Output tokens are counted as chunks arrive (see [gent.StreamTokenMeter]): once an
+// output-token limit is exceeded the request is cancelled and the stream ends with an
+// error.
 func (m *LCGWrapper) GenerateContentStream(
 	execCtx *gent.ExecutionContext,
 	streamId string,
@@ -270,11 +382,19 @@ func (m *LCGWrapper) GenerateContentStream(
 		requestMessages = modifiedRequest
 	}
 
+	// A subscriber supplied the response: stream it without calling the underlying model
+	if response, ok := beforeEvent.SkippedResponse(); ok {
+		return m.skippedStream(execCtx, streamId, streamTopicId, requestMessages, response), nil
+	}
+
 	ctx := execCtx.Context()
 
 	// Create stream with duration tracking
 	stream := gent.NewStreamWithDuration()
 
+	// Count output tokens as chunks arrive, so output-token limits stop the stream early
+	meter := execCtx.NewStreamTokenMeter(m.modelName)
+
 	// Set up streaming callback using WithStreamingReasoningFunc.
 	// This callback receives both reasoning and content chunks, avoiding duplication
 	// that would occur if we also used WithStreamingFunc.
@@ -288,6 +408,9 @@ func (m *LCGWrapper) GenerateContentStream(
 					StreamId:         streamId,
 					StreamTopicId:    streamTopicId,
 				})
+				if err := meter.Add(string(reasoningChunk)); err != nil {
+					return err
+				}
 			}
 			if len(contentChunk) > 0 {
 				stream.SendContent(string(contentChunk))
@@ -297,17 +420,19 @@ func (m *LCGWrapper) GenerateContentStream(
 					StreamId:      streamId,
 					StreamTopicId: streamTopicId,
 				})
+				if err := meter.Add(string(contentChunk)); err != nil {
+					return err
+				}
 			}
 			return nil
 		},
 	)
 
 	// Build options with streaming enabled.
-	// StreamThinking is added before user options so users can override it.
-	// The streaming callback is added last to ensure it takes effect.
-	opts := make([]llms.CallOption, 0, len(options)+2)
-	opts = append(opts, llms.WithStreamThinking(true))
-	opts = append(opts, options...)
+	// StreamThinking and the default params are added before user options so users can
+	// override them. The streaming callback is added last to ensure it takes effect.
+	opts := []llms.CallOption{llms.WithStreamThinking(true)}
+	opts = append(opts, m.params.with(options)...)
 	opts = append(opts, streamingCallback)
 
 	// Start the model call in a goroutine
@@ -332,8 +457,9 @@ func (m *LCGWrapper) GenerateContentStream(
 			}
 		}
 
-		// Publish AfterModelCall event (also updates stats for tokens)
-		execCtx.PublishAfterModelCall(m.modelName, requestMessages, response, duration, err)
+		// Publish AfterModelCall event (also updates stats for the tokens the meter
+		// has not counted yet)
+		meter.PublishAfterModelCall(requestMessages, response, duration, err)
 
 		// Emit error chunk if error occurred
 		if err != nil {
@@ -351,8 +477,36 @@ func (m *LCGWrapper) GenerateContentStream(
 	return stream, nil
 }
 
+// skippedStream returns a completed stream holding a response supplied by a
+// BeforeModelCallEvent subscriber, publishing the skipped call.
+func (m *LCGWrapper) skippedStream(
+	execCtx *gent.ExecutionContext,
+	streamId string,
+	streamTopicId string,
+	requestMessages []llms.MessageContent,
+	response *gent.ContentResponse,
+) gent.Stream {
+	stream := gent.NewStreamWithDuration()
+	if len(response.Choices) > 0 {
+		chunk := gent.StreamChunk{
+			Content:          response.Choices[0].Content,
+			ReasoningContent: response.Choices[0].ReasoningContent,
+			StreamId:         streamId,
+			StreamTopicId:    streamTopicId,
+		}
+		stream.Send(chunk)
+		execCtx.EmitChunk(chunk)
+	}
+	execCtx.PublishSkippedModelCall(m.modelName, requestMessages, response)
+	stream.Complete(response, nil)
+	return stream
+}
+
 // Compile-time check that LCGWrapper implements gent.Model.
 var _ gent.Model = (*LCGWrapper)(nil)
 
 // Compile-time check that LCGWrapper implements gent.StreamingModel.
 var _ gent.StreamingModel = (*LCGWrapper)(nil)
+
+// Compile-time check that LCGWrapper implements gent.StructuredOutputModel.
+var _ gent.StructuredOutputModel = (*LCGWrapper)(nil)
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// optionsModel is an llms.Model that records the call options it was called with.
+type optionsModel struct {
+	opts llms.CallOptions
+}
+
+func (m *optionsModel) GenerateContent(
+	_ context.Context,
+	_ []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	m.opts = llms.CallOptions{}
+	for _, opt := range options {
+		opt(&m.opts)
+	}
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: "ok"}},
+	}, nil
+}
+
+func (m *optionsModel) Call(
+	ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestLCGWrapper_WithParams(t *testing.T) {
+	temperature := 0.0
+
+	type input struct {
+		params  ModelParams
+		options []llms.CallOption
+	}
+
+	type expected struct {
+		temperature float64
+		maxTokens   int
+		stop        []string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "translates params into call options",
+			input: input{params: ModelParams{
+				Temperature: &temperature,
+				MaxTokens:   256,
+				Stop:        []string{"</answer>"},
+			}},
+			expected: expected{temperature: 0, maxTokens: 256, stop: []string{"</answer>"}},
+		},
+		{
+			name: "per-call options override params",
+			input: input{
+				params: ModelParams{Temperature: &temperature, MaxTokens: 256},
+				options: []llms.CallOption{
+					llms.WithTemperature(0.7),
+					llms.WithMaxTokens(64),
+				},
+			},
+			expected: expected{temperature: 0.7, maxTokens: 64},
+		},
+		{
+			name: "per-call stop words merge with params",
+			input: input{
+				params: ModelParams{Stop: []string{"</answer>", "STOP"}},
+				options: []llms.CallOption{
+					llms.WithStopWords([]string{"</action>", "STOP"}),
+				},
+			},
+			expected: expected{stop: []string{"</answer>", "STOP", "</action>"}},
+		},
+		{
+			name: "per-call stop words without params",
+			input: input{
+				options: []llms.CallOption{llms.WithStopWords([]string{"</action>"})},
+			},
+			expected: expected{stop: []string{"</action>"}},
+		},
+		{
+			name:     "unset params leave provider defaults",
+			input:    input{},
+			expected: expected{temperature: 0, maxTokens: 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			llm := &optionsModel{}
+			model := NewLCGWrapper(llm).WithParams(tc.input.params)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			_, err := model.GenerateContent(execCtx, "s", "t", nil, tc.input.options...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.temperature, llm.opts.Temperature)
+			assert.Equal(t, tc.expected.maxTokens, llm.opts.MaxTokens)
+			assert.Equal(t, tc.expected.stop, llm.opts.StopWords)
+
+			stream, err := model.GenerateContentStream(
+				execCtx, "s", "t", nil, tc.input.options...,
+			)
+			require.NoError(t, err)
+			for range stream.Chunks() {
+			}
+			_, err = stream.Response()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.temperature, llm.opts.Temperature)
+			assert.Equal(t, tc.expected.maxTokens, llm.opts.MaxTokens)
+			assert.Equal(t, tc.expected.stop, llm.opts.StopWords)
+		})
+	}
+}
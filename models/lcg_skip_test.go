@@ -0,0 +1,162 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// countingModel is an llms.Model that counts its calls.
+type countingModel struct {
+	calls int
+}
+
+func (m *countingModel) GenerateContent(
+	_ context.Context,
+	_ []llms.MessageContent,
+	_ ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	m.calls++
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{
+			Content:        "fresh",
+			GenerationInfo: map[string]any{"PromptTokens": 100, "CompletionTokens": 20},
+		}},
+	}, nil
+}
+
+func (m *countingModel) Call(
+	ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// promptCacheHook serves cached responses from BeforeModelCall and records the
+// AfterModelCall events.
+type promptCacheHook struct {
+	responses map[string]*gent.ContentResponse // by prompt hash
+	after     []*gent.AfterModelCallEvent
+}
+
+func (h *promptCacheHook) OnBeforeModelCall(
+	_ *gent.ExecutionContext,
+	event *gent.BeforeModelCallEvent,
+) {
+	messages, _ := event.Request.([]llms.MessageContent)
+	key, err := PromptHash(messages)
+	if err != nil {
+		return
+	}
+	if cached, ok := h.responses[key]; ok {
+		event.Response = cached
+		event.Skip = true
+	}
+}
+
+func (h *promptCacheHook) OnAfterModelCall(
+	_ *gent.ExecutionContext,
+	event *gent.AfterModelCallEvent,
+) {
+	h.after = append(h.after, event)
+}
+
+func TestLCGWrapper_SkippedModelCall(t *testing.T) {
+	cachedPrompt := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "What's the weather?"),
+	}
+	cached := &gent.ContentResponse{
+		Choices: []*gent.ContentChoice{{Content: "cached"}},
+		Info:    &gent.GenerationInfo{InputTokens: 100, OutputTokens: 20},
+	}
+
+	type input struct {
+		messages []llms.MessageContent
+		stream   bool
+	}
+
+	type expected struct {
+		content       string
+		providerCalls int
+		cached        bool
+		inputTokens   int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "cache hit skips the provider",
+			input: input{messages: cachedPrompt},
+			expected: expected{
+				content:     "cached",
+				cached:      true,
+				inputTokens: 100,
+			},
+		},
+		{
+			name:  "cache hit skips the provider when streaming",
+			input: input{messages: cachedPrompt, stream: true},
+			expected: expected{
+				content:     "cached",
+				cached:      true,
+				inputTokens: 100,
+			},
+		},
+		{
+			name: "cache miss calls the provider",
+			input: input{messages: []llms.MessageContent{
+				llms.TextParts(llms.ChatMessageTypeHuman, "What time is it?"),
+			}},
+			expected: expected{
+				content:       "fresh",
+				providerCalls: 1,
+				inputTokens:   100,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := PromptHash(cachedPrompt)
+			require.NoError(t, err)
+			hook := &promptCacheHook{responses: map[string]*gent.ContentResponse{key: cached}}
+
+			llm := &countingModel{}
+			model := NewLCGWrapper(llm).WithModelName("mock")
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetEventPublisher(events.NewRegistry().Subscribe(hook))
+
+			var response *gent.ContentResponse
+			if tc.input.stream {
+				stream, err := model.GenerateContentStream(execCtx, "s", "t", tc.input.messages)
+				require.NoError(t, err)
+				acc := gent.NewStreamAccumulator()
+				for chunk := range stream.Chunks() {
+					acc.Add(chunk)
+				}
+				response, err = stream.Response()
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected.content, acc.Content())
+			} else {
+				response, err = model.GenerateContent(execCtx, "s", "t", tc.input.messages)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected.content, response.Choices[0].Content)
+			assert.Equal(t, tc.expected.providerCalls, llm.calls)
+			require.Len(t, hook.after, 1)
+			assert.Equal(t, tc.expected.cached, hook.after[0].Cached)
+			assert.Equal(t, tc.expected.inputTokens,
+				execCtx.Stats().GetCounter(gent.SCInputTokens))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// chunkingModel is an llms.Model that streams chunks one by one through the streaming
+// callback, stopping when the callback or the context reports an error.
+type chunkingModel struct {
+	chunks       []string
+	outputTokens int // reported in the final GenerationInfo
+	emitted      int
+}
+
+func (m *chunkingModel) GenerateContent(
+	ctx context.Context,
+	_ []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	var content strings.Builder
+	for _, chunk := range m.chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m.emitted++
+		content.WriteString(chunk)
+		if err := opts.StreamingReasoningFunc(ctx, nil, []byte(chunk)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{
+			Content: content.String(),
+			GenerationInfo: map[string]any{
+				"PromptTokens":     10,
+				"CompletionTokens": m.outputTokens,
+			},
+		}},
+	}, nil
+}
+
+func (m *chunkingModel) Call(
+	ctx context.Context,
+	prompt string,
+	options ...llms.CallOption,
+) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestLCGWrapper_GenerateContentStream_OutputTokenLimit(t *testing.T) {
+	type input struct {
+		maxOutputTokens float64 // 0 means no limit
+		reportedOutput  int
+	}
+
+	type expected struct {
+		emitted      int
+		streamErr    bool
+		outputTokens int64
+		totalTokens  int64
+		exceeded     bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "limit crossed mid-stream cancels the request",
+			input: input{maxOutputTokens: 20, reportedOutput: 100},
+			expected: expected{
+				emitted:      21,
+				streamErr:    true,
+				outputTokens: 21,
+				totalTokens:  21,
+				exceeded:     true,
+			},
+		},
+		{
+			name:  "reported usage above the estimate adds the remainder",
+			input: input{reportedOutput: 120},
+			expected: expected{
+				emitted:      100,
+				outputTokens: 120,
+				totalTokens:  130,
+			},
+		},
+		{
+			name:  "reported usage below the estimate keeps the estimate",
+			input: input{reportedOutput: 80},
+			expected: expected{
+				emitted:      100,
+				outputTokens: 100,
+				totalTokens:  110,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// 100 chunks of 4 characters, one estimated token each
+			chunks := make([]string, 100)
+			for i := range chunks {
+				chunks[i] = "abcd"
+			}
+			llm := &chunkingModel{chunks: chunks, outputTokens: tc.input.reportedOutput}
+			model := NewLCGWrapper(llm).WithModelName("mock")
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			var limits []gent.Limit
+			if tc.input.maxOutputTokens > 0 {
+				limits = append(limits, gent.Limit{
+					Type:     gent.LimitExactKey,
+					Key:      gent.SCOutputTokens,
+					MaxValue: tc.input.maxOutputTokens,
+				})
+			}
+			execCtx.SetLimits(limits)
+
+			stream, err := model.GenerateContentStream(execCtx, "s", "t", nil)
+			require.NoError(t, err)
+			for range stream.Chunks() {
+			}
+			_, streamErr := stream.Response()
+
+			assert.Equal(t, tc.expected.emitted, llm.emitted)
+			assert.Equal(t, tc.expected.streamErr, streamErr != nil)
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.outputTokens, stats.GetCounter(gent.SCOutputTokens))
+			assert.Equal(t, tc.expected.outputTokens,
+				stats.GetCounter(gent.SCOutputTokensFor+"mock"))
+			assert.Equal(t, tc.expected.totalTokens, stats.GetCounter(gent.SCTotalTokens))
+			assert.Equal(t, tc.expected.exceeded, execCtx.ExceededLimit() != nil)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ResponseCache stores model responses by prompt hash for [WithResponseCache].
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the cached response for key, if any.
+	Get(key string) (*gent.ContentResponse, bool)
+
+	// Set stores response under key.
+	Set(key string, response *gent.ContentResponse)
+}
+
+// MemoryResponseCache is an in-memory [ResponseCache] without eviction. Use it for
+// tests, evaluations and short-lived processes.
+type MemoryResponseCache struct {
+	mu        sync.RWMutex
+	responses map[string]*gent.ContentResponse
+}
+
+// NewMemoryResponseCache creates an empty in-memory response cache.
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{responses: make(map[string]*gent.ContentResponse)}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryResponseCache) Get(key string) (*gent.ContentResponse, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	response, ok := c.responses[key]
+	return response, ok
+}
+
+// Set implements ResponseCache.
+func (c *MemoryResponseCache) Set(key string, response *gent.ContentResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = response
+}
+
+// Len returns the number of cached responses.
+func (c *MemoryResponseCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.responses)
+}
+
+// WithResponseCache returns a middleware that caches successful responses, keyed by
+// [PromptHash] of the request the model adapter sends and the call options.
+//
+// The cache is consulted through ExecutionContext.InterceptModelCalls, after the
+// adapter published BeforeModelCallEvent, so the key covers ephemeral messages and
+// subscriber changes, and the queued ephemeral messages are consumed on a hit as on a
+// miss. On a hit the response is supplied with BeforeModelCallEvent.Skip: the adapter
+// skips the provider and publishes AfterModelCallEvent with Cached set. The response
+// is a copy of the cached one with an empty GenerationInfo, since no tokens were spent.
+//
+// Errors and responses without choices are not cached. Calls without an
+// ExecutionContext, to a model adapter that publishes no BeforeModelCallEvent, or whose
+// request or options cannot be hashed (e.g. unserializable metadata) bypass the cache.
+//
+//	model := gent.Chain(base, models.WithResponseCache(models.NewMemoryResponseCache()))
+func WithResponseCache(cache ResponseCache) gent.ModelMiddleware {
+	return func(next gent.Model) gent.Model {
+		return gent.ModelFunc(func(
+			execCtx *gent.ExecutionContext,
+			streamId string,
+			streamTopicId string,
+			messages []llms.MessageContent,
+			options ...llms.CallOption,
+		) (*gent.ContentResponse, error) {
+			if execCtx == nil {
+				return next.GenerateContent(execCtx, streamId, streamTopicId, messages, options...)
+			}
+
+			var key string
+			intercepted, hit := false, false
+			remove := execCtx.InterceptModelCalls(func(event *gent.BeforeModelCallEvent) {
+				if intercepted || event.Skip {
+					return
+				}
+				intercepted = true
+				request, ok := event.Request.([]llms.MessageContent)
+				if !ok {
+					return
+				}
+				if key, _ = PromptHash(request, options...); key == "" {
+					return
+				}
+				if cached, ok := cache.Get(key); ok && cached != nil && len(cached.Choices) > 0 {
+					event.Response = copyResponse(cached, true)
+					event.Skip = true
+					hit = true
+				}
+			})
+			response, err := next.GenerateContent(
+				execCtx, streamId, streamTopicId, messages, options...)
+			remove()
+
+			if !hit && key != "" && err == nil && response != nil && len(response.Choices) > 0 {
+				cache.Set(key, copyResponse(response, false))
+			}
+			return response, err
+		})
+	}
+}
+
+// copyResponse returns a copy of response that shares no choice or info with it. With
+// noUsage set, the copy's info is empty: no tokens, no duration.
+func copyResponse(response *gent.ContentResponse, noUsage bool) *gent.ContentResponse {
+	copied := &gent.ContentResponse{Choices: make([]*gent.ContentChoice, len(response.Choices))}
+	for i, choice := range response.Choices {
+		if choice != nil {
+			c := *choice
+			copied.Choices[i] = &c
+		}
+	}
+	switch {
+	case noUsage:
+		copied.Info = &gent.GenerationInfo{}
+	case response.Info != nil:
+		info := *response.Info
+		copied.Info = &info
+	}
+	return copied
+}
+
+// PromptHash returns a hex SHA-256 hash of the messages and the resolved call options,
+// identifying a prompt for caching. Streaming callbacks are not part of the hash.
+// Returns an error if the messages or options cannot be serialized.
+func PromptHash(messages []llms.MessageContent, options ...llms.CallOption) (string, error) {
+	var opts llms.CallOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	payload, err := json.Marshal(struct {
+		Messages []llms.MessageContent `json:"messages"`
+		Options  llms.CallOptions      `json:"options"`
+	}{messages, opts})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ModelCallRecord is a model call captured by [Recorder].
+type ModelCallRecord struct {
+	// Messages are the messages passed to the next model.
+	Messages []llms.MessageContent
+
+	// Options are the resolved call options.
+	Options llms.CallOptions
+
+	// Response is the response returned by the next model, nil on error.
+	Response *gent.ContentResponse
+
+	// Err is the error returned by the next model.
+	Err error
+
+	// Duration is how long the call took.
+	Duration time.Duration
+}
+
+// Recorder captures model requests and responses through its middleware, e.g. to
+// build fixtures or inspect what an agent sent. Safe for concurrent use.
+//
+//	recorder := models.NewRecorder()
+//	model := gent.Chain(base, recorder.Middleware())
+//	// ... run the agent ...
+//	for _, call := range recorder.Records() {
+//	    fmt.Println(len(call.Messages), call.Response.Choices[0].Content)
+//	}
+type Recorder struct {
+	mu      sync.Mutex
+	records []ModelCallRecord
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Middleware returns a middleware that records every call passing through it.
+func (r *Recorder) Middleware() gent.ModelMiddleware {
+	return func(next gent.Model) gent.Model {
+		return gent.ModelFunc(func(
+			execCtx *gent.ExecutionContext,
+			streamId string,
+			streamTopicId string,
+			messages []llms.MessageContent,
+			options ...llms.CallOption,
+		) (*gent.ContentResponse, error) {
+			var opts llms.CallOptions
+			for _, opt := range options {
+				opt(&opts)
+			}
+
+			start := execCtx.Clock().Now()
+			response, err := next.GenerateContent(
+				execCtx, streamId, streamTopicId, messages, options...)
+
+			r.mu.Lock()
+			r.records = append(r.records, ModelCallRecord{
+				Messages: append([]llms.MessageContent(nil), messages...),
+				Options:  opts,
+				Response: response,
+				Err:      err,
+				Duration: execCtx.Clock().Now().Sub(start),
+			})
+			r.mu.Unlock()
+
+			return response, err
+		})
+	}
+}
+
+// Records returns a copy of the recorded calls in call order.
+func (r *Recorder) Records() []ModelCallRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ModelCallRecord(nil), r.records...)
+}
+
+// Reset discards all recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
@@ -0,0 +1,301 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/internal/tt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestWithResponseCache(t *testing.T) {
+	weather := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "What's the weather?"),
+	}
+	news := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "What's the news?"),
+	}
+
+	type call struct {
+		messages []llms.MessageContent
+		options  []llms.CallOption
+	}
+
+	type expected struct {
+		contents    []string
+		errs        []error
+		baseCalls   int
+		cached      int
+		inputTokens int64
+	}
+
+	errRateLimited := errors.New("rate limited")
+
+	tests := []struct {
+		name     string
+		base     func() *tt.MockModel
+		calls    []call
+		expected expected
+	}{
+		{
+			name: "repeated prompt is served from cache",
+			base: func() *tt.MockModel {
+				return tt.NewMockModel().AddResponse("sunny", 100, 10)
+			},
+			calls: []call{{messages: weather}, {messages: weather}},
+			expected: expected{
+				contents:    []string{"sunny", "sunny"},
+				errs:        []error{nil, nil},
+				baseCalls:   1,
+				cached:      1,
+				inputTokens: 100,
+			},
+		},
+		{
+			name: "different messages miss",
+			base: func() *tt.MockModel {
+				return tt.NewMockModel().
+					AddResponse("sunny", 100, 10).
+					AddResponse("quiet day", 100, 10)
+			},
+			calls: []call{{messages: weather}, {messages: news}},
+			expected: expected{
+				contents:    []string{"sunny", "quiet day"},
+				errs:        []error{nil, nil},
+				baseCalls:   2,
+				cached:      2,
+				inputTokens: 200,
+			},
+		},
+		{
+			name: "different options miss",
+			base: func() *tt.MockModel {
+				return tt.NewMockModel().
+					AddResponse("sunny", 100, 10).
+					AddResponse("Sunny!", 100, 10)
+			},
+			calls: []call{
+				{messages: weather},
+				{messages: weather, options: []llms.CallOption{llms.WithTemperature(0.9)}},
+			},
+			expected: expected{
+				contents:    []string{"sunny", "Sunny!"},
+				errs:        []error{nil, nil},
+				baseCalls:   2,
+				cached:      2,
+				inputTokens: 200,
+			},
+		},
+		{
+			name: "errors are not cached",
+			base: func() *tt.MockModel {
+				return tt.NewMockModel().
+					AddError(errRateLimited).
+					AddResponse("sunny", 100, 10)
+			},
+			calls: []call{{messages: weather}, {messages: weather}},
+			expected: expected{
+				contents:    []string{"", "sunny"},
+				errs:        []error{errRateLimited, nil},
+				baseCalls:   2,
+				cached:      1,
+				inputTokens: 100,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			base := tc.base()
+			cache := NewMemoryResponseCache()
+			model := gent.Chain(base, WithResponseCache(cache))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			var contents []string
+			var errs []error
+			for _, c := range tc.calls {
+				response, err := model.GenerateContent(
+					execCtx, "stream", "llm", c.messages, c.options...)
+				content := ""
+				if response != nil {
+					content = response.Choices[0].Content
+				}
+				contents = append(contents, content)
+				errs = append(errs, err)
+			}
+
+			assert.Equal(t, tc.expected.contents, contents)
+			assert.Equal(t, tc.expected.errs, errs)
+			assert.Equal(t, tc.expected.baseCalls, base.CallCount())
+			assert.Equal(t, tc.expected.cached, cache.Len())
+			assert.Equal(t, tc.expected.inputTokens, execCtx.Stats().GetTotalInputTokens(),
+				"cache hits should not count tokens")
+		})
+	}
+}
+
+func TestWithResponseCache_EmitsChunkOnHit(t *testing.T) {
+	messages := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "hi")}
+	cache := NewMemoryResponseCache()
+	key, err := PromptHash(messages)
+	require.NoError(t, err)
+	cache.Set(key, &gent.ContentResponse{Choices: []*gent.ContentChoice{{Content: "hello"}}})
+
+	base := &countingModel{}
+	model := gent.Chain(NewLCGWrapper(base), WithResponseCache(cache))
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	chunks, unsubscribe := execCtx.SubscribeToStream("stream")
+	defer unsubscribe()
+
+	response, err := model.GenerateContent(execCtx, "stream", "llm", messages)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello", response.Choices[0].Content)
+	assert.Equal(t, 0, base.calls)
+
+	chunk := <-chunks
+	assert.Equal(t, "hello", chunk.Content)
+	assert.Equal(t, "llm", chunk.StreamTopicId)
+}
+
+func TestWithResponseCache_SkipPath(t *testing.T) {
+	prompt := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "weather?")}
+	withReminder := append(append([]llms.MessageContent(nil), prompt...),
+		llms.TextParts(llms.ChatMessageTypeSystem, "Be brief."))
+
+	type input struct {
+		cached    map[string]*gent.ContentResponse // by the messages' text, joined
+		ephemeral string
+	}
+
+	type expected struct {
+		content    string
+		baseCalls  int
+		cachedCall bool
+		drained    bool
+	}
+
+	fresh := &gent.ContentResponse{Choices: []*gent.ContentChoice{{Content: "cached"}},
+		Info: &gent.GenerationInfo{InputTokens: 100, OutputTokens: 20}}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "hit is answered through BeforeModelCallEvent.Skip",
+			input:    input{cached: map[string]*gent.ContentResponse{"prompt": fresh}},
+			expected: expected{content: "cached", cachedCall: true},
+		},
+		{
+			name: "hit consumes queued ephemeral messages",
+			input: input{
+				cached:    map[string]*gent.ContentResponse{"reminder": fresh},
+				ephemeral: "Be brief.",
+			},
+			expected: expected{content: "cached", cachedCall: true, drained: true},
+		},
+		{
+			name: "ephemeral messages are part of the key",
+			input: input{
+				cached:    map[string]*gent.ContentResponse{"prompt": fresh},
+				ephemeral: "Be brief.",
+			},
+			expected: expected{content: "fresh", baseCalls: 1, drained: true},
+		},
+		{
+			name: "cached response without choices is ignored",
+			input: input{cached: map[string]*gent.ContentResponse{
+				"prompt": {Info: &gent.GenerationInfo{}},
+			}},
+			expected: expected{content: "fresh", baseCalls: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewMemoryResponseCache()
+			for name, response := range tc.input.cached {
+				messages := map[string][]llms.MessageContent{
+					"prompt": prompt, "reminder": withReminder,
+				}[name]
+				key, err := PromptHash(messages)
+				require.NoError(t, err)
+				cache.Set(key, response)
+			}
+			base := &countingModel{}
+			model := gent.Chain(NewLCGWrapper(base), WithResponseCache(cache))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			if tc.input.ephemeral != "" {
+				execCtx.EnqueueEphemeralMessage(llms.ChatMessageTypeSystem, tc.input.ephemeral)
+			}
+
+			response, err := model.GenerateContent(execCtx, "", "", prompt)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.content, response.Choices[0].Content)
+			assert.Equal(t, tc.expected.baseCalls, base.calls)
+			if tc.expected.drained {
+				assert.Empty(t, execCtx.PendingEphemeralMessages())
+			}
+
+			var after []*gent.AfterModelCallEvent
+			for _, event := range execCtx.Events() {
+				if e, ok := event.(*gent.AfterModelCallEvent); ok {
+					after = append(after, e)
+				}
+			}
+			require.Len(t, after, 1)
+			assert.Equal(t, tc.expected.cachedCall, after[0].Cached)
+			if tc.expected.cachedCall {
+				assert.Equal(t, int64(0), execCtx.Stats().GetTotalInputTokens(),
+					"cache hits should not count tokens")
+
+				// The response is a copy: changing it does not change the cache
+				response.Choices[0].Content = "changed"
+				if tc.input.ephemeral != "" {
+					execCtx.EnqueueEphemeralMessage(llms.ChatMessageTypeSystem, tc.input.ephemeral)
+				}
+				again, err := model.GenerateContent(execCtx, "", "", prompt)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected.content, again.Choices[0].Content)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	errOverloaded := errors.New("overloaded")
+	base := tt.NewMockModel().
+		AddError(errOverloaded).
+		AddResponse("sunny", 100, 10)
+
+	recorder := NewRecorder()
+	model := gent.Chain(base, recorder.Middleware())
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+	first := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "weather?")}
+	second := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "news?")}
+	_, _ = model.GenerateContent(execCtx, "", "", first, llms.WithMaxTokens(50))
+	_, _ = model.GenerateContent(execCtx, "", "", second)
+
+	records := recorder.Records()
+	require.Len(t, records, 2)
+
+	assert.Equal(t, first, records[0].Messages)
+	assert.Equal(t, 50, records[0].Options.MaxTokens)
+	assert.Nil(t, records[0].Response)
+	assert.ErrorIs(t, records[0].Err, errOverloaded)
+
+	assert.Equal(t, second, records[1].Messages)
+	assert.Equal(t, "sunny", records[1].Response.Choices[0].Content)
+	assert.NoError(t, records[1].Err)
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Records())
+}
@@ -0,0 +1,67 @@
+package gent
+
+// Outcome summarizes why an execution stopped, for dashboards and post-mortems. Get it
+// with [ExecutionContext.Outcome] once the execution has terminated.
+type Outcome struct {
+	// TerminationReason is how the execution ended (see [ExecutionContext.TerminationReason]).
+	// Empty if the execution has not terminated yet.
+	TerminationReason TerminationReason
+
+	// ExceededLimit is the limit that stopped the execution, or nil.
+	ExceededLimit *Limit
+
+	// ExceededLimitKey is the stat key that exceeded ExceededLimit, or "". For prefix
+	// limits this is the specific key.
+	ExceededLimitKey StatKey
+
+	// HasAnswer is true if the execution produced a final answer.
+	HasAnswer bool
+
+	// Iterations is the number of iterations that started.
+	Iterations int
+
+	// LastError is the error the execution terminated with or, if it has none, the error
+	// of the last [ErrorEvent] published on the context. Nil if neither exists.
+	LastError error
+
+	// ParseErrors counts parse errors by type, from the SC*ParseErrorTotal counters.
+	// Every ParseErrorType is present, with 0 if no such error occurred.
+	ParseErrors map[ParseErrorType]int64
+}
+
+// Outcome returns a summary of why the execution stopped, built from the context's
+// termination state and stats without scanning events:
+//
+//	outcome := execCtx.Outcome()
+//	if outcome.ExceededLimit != nil {
+//	    log.Printf("stopped by %s after %d iterations (%d format parse errors)",
+//	        outcome.ExceededLimitKey, outcome.Iterations,
+//	        outcome.ParseErrors[gent.ParseErrorTypeFormat])
+//	}
+//
+// Counts include child executions, like the stats they are read from. It can be called
+// at any time; before termination it reflects the execution so far.
+func (ctx *ExecutionContext) Outcome() Outcome {
+	ctx.mu.RLock()
+	outcome := Outcome{
+		TerminationReason: ctx.terminationReason,
+		ExceededLimit:     ctx.exceededLimit,
+		ExceededLimitKey:  ctx.exceededKey,
+		HasAnswer:         len(ctx.finalResult) > 0,
+		Iterations:        ctx.iteration,
+		LastError:         ctx.err,
+	}
+	if outcome.LastError == nil {
+		outcome.LastError = ctx.lastError
+	}
+	ctx.mu.RUnlock()
+
+	stats := ctx.Stats()
+	outcome.ParseErrors = map[ParseErrorType]int64{
+		ParseErrorTypeFormat:      stats.GetCounter(SCFormatParseErrorTotal),
+		ParseErrorTypeToolchain:   stats.GetCounter(SCToolchainParseErrorTotal),
+		ParseErrorTypeTermination: stats.GetCounter(SCTerminationParseErrorTotal),
+		ParseErrorTypeSection:     stats.GetCounter(SCSectionParseErrorTotal),
+	}
+	return outcome
+}
@@ -0,0 +1,157 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestExecutionContext_Outcome(t *testing.T) {
+	errModel := errors.New("model unavailable")
+	errHook := errors.New("subscriber failed")
+	iterationLimit := Limit{Type: LimitExactKey, Key: SCIterations, MaxValue: 2}
+
+	// Like the Executor at the start of each iteration
+	startIteration := func(execCtx *ExecutionContext) {
+		execCtx.IncrementIteration()
+		execCtx.PublishBeforeIteration()
+	}
+
+	noParseErrors := map[ParseErrorType]int64{
+		ParseErrorTypeFormat:      0,
+		ParseErrorTypeToolchain:   0,
+		ParseErrorTypeTermination: 0,
+		ParseErrorTypeSection:     0,
+	}
+
+	type input struct {
+		limits []Limit
+		run    func(execCtx *ExecutionContext)
+	}
+
+	type expected struct {
+		outcome Outcome
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "not terminated yet",
+			input: input{
+				run: func(execCtx *ExecutionContext) {},
+			},
+			expected: expected{
+				outcome: Outcome{ParseErrors: noParseErrors},
+			},
+		},
+		{
+			name: "success after recovering from parse errors",
+			input: input{
+				run: func(execCtx *ExecutionContext) {
+					startIteration(execCtx)
+					execCtx.PublishParseError(ParseErrorTypeFormat, "bad", errors.New("bad"))
+					execCtx.PublishParseError(ParseErrorTypeFormat, "bad", errors.New("bad"))
+					execCtx.PublishParseError(ParseErrorTypeSection, "{", errors.New("json"))
+					startIteration(execCtx)
+					execCtx.SetTermination(TerminationSuccess,
+						[]ContentPart{llms.TextContent{Text: "done"}}, nil)
+				},
+			},
+			expected: expected{
+				outcome: Outcome{
+					TerminationReason: TerminationSuccess,
+					HasAnswer:         true,
+					Iterations:        2,
+					ParseErrors: map[ParseErrorType]int64{
+						ParseErrorTypeFormat:      2,
+						ParseErrorTypeToolchain:   0,
+						ParseErrorTypeTermination: 0,
+						ParseErrorTypeSection:     1,
+					},
+				},
+			},
+		},
+		{
+			name: "limit exceeded",
+			input: input{
+				limits: []Limit{iterationLimit},
+				run: func(execCtx *ExecutionContext) {
+					for range 3 {
+						startIteration(execCtx)
+					}
+					execCtx.PublishParseError(ParseErrorTypeToolchain, "x", errors.New("yaml"))
+					execCtx.SetTermination(TerminationLimitExceeded, nil, nil)
+				},
+			},
+			expected: expected{
+				outcome: Outcome{
+					TerminationReason: TerminationLimitExceeded,
+					ExceededLimit:     &iterationLimit,
+					ExceededLimitKey:  SCIterations,
+					Iterations:        3,
+					ParseErrors: map[ParseErrorType]int64{
+						ParseErrorTypeFormat:      0,
+						ParseErrorTypeToolchain:   1,
+						ParseErrorTypeTermination: 0,
+						ParseErrorTypeSection:     0,
+					},
+				},
+			},
+		},
+		{
+			name: "terminal error wins over earlier error events",
+			input: input{
+				run: func(execCtx *ExecutionContext) {
+					startIteration(execCtx)
+					execCtx.PublishError(errHook)
+					execCtx.SetTermination(TerminationError, nil, errModel)
+				},
+			},
+			expected: expected{
+				outcome: Outcome{
+					TerminationReason: TerminationError,
+					Iterations:        1,
+					LastError:         errModel,
+					ParseErrors:       noParseErrors,
+				},
+			},
+		},
+		{
+			name: "last error event reported when the run succeeded",
+			input: input{
+				run: func(execCtx *ExecutionContext) {
+					startIteration(execCtx)
+					execCtx.PublishError(errors.New("first"))
+					execCtx.PublishError(errHook)
+					execCtx.SetTermination(TerminationSuccess,
+						[]ContentPart{llms.TextContent{Text: "done"}}, nil)
+				},
+			},
+			expected: expected{
+				outcome: Outcome{
+					TerminationReason: TerminationSuccess,
+					HasAnswer:         true,
+					Iterations:        1,
+					LastError:         errHook,
+					ParseErrors:       noParseErrors,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "main", nil)
+			execCtx.SetLimits(tc.input.limits)
+			tc.input.run(execCtx)
+
+			assert.Equal(t, tc.expected.outcome, execCtx.Outcome())
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package gent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError locates a parse failure within the parsed content, to speed up debugging
+// of model output. Parsers that can tell where parsing broke (the XML format and the
+// YAML toolchain) return it, and it reaches subscribers as [ParseErrorEvent].Error:
+//
+//	var parseErr *gent.ParseError
+//	if errors.As(event.Error, &parseErr) {
+//	    log.Printf("line %d, column %d: expected %v", parseErr.Line, parseErr.Column,
+//	        parseErr.Expected)
+//	}
+//
+// It wraps the underlying error, so errors.Is checks against [ErrNoSectionsFound],
+// [ErrInvalidYAML] and the other parse error sentinels keep working.
+type ParseError struct {
+	// Err is the underlying error, usually wrapping a parse error sentinel.
+	Err error
+
+	// Offset is the byte offset in the content where parsing failed.
+	Offset int
+
+	// Line is the 1-based line of Offset.
+	Line int
+
+	// Column is the 1-based byte column of Offset within Line.
+	Column int
+
+	// Expected lists the tokens or tags that would have been valid at Offset, if known.
+	Expected []string
+}
+
+// NewParseErrorAt returns a ParseError for err at byte offset in content, deriving Line
+// and Column. Offsets outside content are clamped to it.
+func NewParseErrorAt(err error, content string, offset int, expected ...string) *ParseError {
+	offset = max(0, min(offset, len(content)))
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	return &ParseError{
+		Err:      err,
+		Offset:   offset,
+		Line:     strings.Count(content[:offset], "\n") + 1,
+		Column:   offset - lineStart + 1,
+		Expected: expected,
+	}
+}
+
+// NewParseErrorAtLine returns a ParseError for err at the 1-based line and byte column
+// in content, deriving Offset. Positions past the end of a line or of content are
+// clamped to it.
+func NewParseErrorAtLine(
+	err error,
+	content string,
+	line, column int,
+	expected ...string,
+) *ParseError {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			offset = len(content)
+			break
+		}
+		offset += next + 1
+	}
+	lineEnd := strings.IndexByte(content[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(content) - offset
+	}
+	offset += max(0, min(column-1, lineEnd))
+	return NewParseErrorAt(err, content, offset, expected...)
+}
+
+// Error implements error. The location is appended to the underlying message.
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%v (line %d, column %d", e.Err, e.Line, e.Column)
+	if len(e.Expected) > 0 {
+		msg += "; expected " + strings.Join(e.Expected, " or ")
+	}
+	return msg + ")"
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
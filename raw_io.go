@@ -0,0 +1,69 @@
+package gent
+
+import "github.com/tmc/langchaingo/llms"
+
+// RawIOSink receives the exact messages sent to and the completion received from every
+// model call, before the response is parsed. Use it to archive model I/O, e.g. for
+// compliance. The framework hands over the data as is: redaction is the sink's
+// responsibility.
+//
+// Record is called synchronously when the model publishes its AfterModelCallEvent, so it
+// sees the request after any BeforeModelCall modifications. Failed calls are recorded
+// with an empty response. Parallel child contexts share their parent's sink, so
+// implementations must be safe for concurrent use.
+//
+// Usually configured through executor.Config.RawIOSink:
+//
+//	type archive struct{ w io.Writer }
+//
+//	func (a *archive) Record(
+//	    execCtx *gent.ExecutionContext,
+//	    model string,
+//	    request []llms.MessageContent,
+//	    response string,
+//	) {
+//	    json.NewEncoder(a.w).Encode(map[string]any{
+//	        "run": execCtx.Name(), "model": model,
+//	        "request": request, "response": response,
+//	    })
+//	}
+type RawIOSink interface {
+	// Record archives one model call. request is nil if the model published a request
+	// that is not a message slice. response is the content of the first choice.
+	Record(
+		execCtx *ExecutionContext,
+		model string,
+		request []llms.MessageContent,
+		response string,
+	)
+}
+
+// SetRawIOSink sets the sink receiving the raw I/O of every model call made with this
+// context. Child contexts spawned afterwards inherit the sink. Usually configured through
+// executor.Config.RawIOSink rather than called directly. A nil sink disables recording.
+func (ctx *ExecutionContext) SetRawIOSink(sink RawIOSink) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.rawIOSink = sink
+}
+
+// RawIOSink returns the sink set by SetRawIOSink, or nil if none is set.
+func (ctx *ExecutionContext) RawIOSink() RawIOSink {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.rawIOSink
+}
+
+// recordRawIO hands a model call to the raw I/O sink, if one is set.
+func (ctx *ExecutionContext) recordRawIO(model string, request any, response *ContentResponse) {
+	sink := ctx.RawIOSink()
+	if sink == nil {
+		return
+	}
+	messages, _ := request.([]llms.MessageContent)
+	var content string
+	if response != nil && len(response.Choices) > 0 {
+		content = response.Choices[0].Content
+	}
+	sink.Record(ctx, model, messages, content)
+}
@@ -0,0 +1,68 @@
+package gent
+
+import "fmt"
+
+// DefaultReflectionPrompt is the prompt used by a [ReflectionPass] without a Prompt.
+// The %s placeholder receives the candidate answer.
+const DefaultReflectionPrompt = `Before your answer is final, review it critically.
+
+Your candidate answer:
+%s
+
+Check it against the task and everything learned so far: is it correct, complete, ` +
+	`and does it follow every instruction? If it can be improved, write the improved ` +
+	`answer. Otherwise repeat the answer unchanged.
+
+Reply with the answer content only, without section markers or commentary.`
+
+// ReflectionPass configures an optional self-critique step before an answer is final.
+//
+// When enabled, an AgentLoop that is about to terminate with a candidate answer makes
+// one extra model call asking the model to critique and possibly revise it. The reply
+// replaces the candidate, which then goes through the normal termination path:
+// parsing, then validators. Iterations that call tools or produce no valid answer do
+// not trigger the pass, and an iteration with several candidates reflects on the first
+// valid one only.
+//
+// The extra call is a regular model call, so its tokens count toward the usual token
+// stats and limits. They are also tracked under [SCReflectionInputTokens] and
+// [SCReflectionOutputTokens], and each pass increments [SCReflectionCalls].
+//
+// Usually configured through executor.Config.ReflectionPass:
+//
+//	exec := executor.New[*gent.BasicLoopData](agent, executor.Config{
+//	    ReflectionPass: &gent.ReflectionPass{Enabled: true},
+//	})
+type ReflectionPass struct {
+	// Enabled turns the reflection pass on.
+	Enabled bool
+
+	// Prompt is the user message asking for the critique, a fmt.Sprintf template with
+	// one %s placeholder for the candidate answer. Defaults to DefaultReflectionPrompt.
+	Prompt string
+}
+
+// PromptFor returns the reflection prompt for the candidate answer.
+func (p ReflectionPass) PromptFor(answer string) string {
+	prompt := p.Prompt
+	if prompt == "" {
+		prompt = DefaultReflectionPrompt
+	}
+	return fmt.Sprintf(prompt, answer)
+}
+
+// SetReflectionPass configures the reflection pass for AgentLoops running on this
+// context. Child contexts spawned afterwards inherit it. Usually configured through
+// executor.Config.ReflectionPass rather than called directly.
+func (ctx *ExecutionContext) SetReflectionPass(pass ReflectionPass) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.reflectionPass = pass
+}
+
+// ReflectionPass returns the reflection pass configuration. See [ReflectionPass].
+func (ctx *ExecutionContext) ReflectionPass() ReflectionPass {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.reflectionPass
+}
@@ -0,0 +1,205 @@
+package gent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrRepeatedToolCall is returned for a tool call that was rejected because the agent
+// kept repeating it with identical arguments. See [RepeatedToolCallPolicy].
+var ErrRepeatedToolCall = errors.New("repeated tool call")
+
+// RepeatedToolCallAction determines what happens when a tool call loop is detected.
+type RepeatedToolCallAction string
+
+const (
+	// RepeatedToolCallNudge skips the call and tells the model it already made this
+	// call, together with the prior result. This is the default.
+	RepeatedToolCallNudge RepeatedToolCallAction = "nudge"
+
+	// RepeatedToolCallCachedResult skips the call and returns the prior result as if
+	// the tool had been called again.
+	RepeatedToolCallCachedResult RepeatedToolCallAction = "cached_result"
+
+	// RepeatedToolCallTerminate skips the call and terminates execution with
+	// [TerminationRepeatedToolCall] once the current iteration finishes.
+	RepeatedToolCallTerminate RepeatedToolCallAction = "terminate"
+)
+
+// RepeatedToolCallPolicy configures tool call loop detection for an ExecutionContext.
+//
+// Models sometimes get stuck calling the same tool with the same arguments over and
+// over. With a policy set, toolchains consult [ExecutionContext.CheckRepeatedToolCall]
+// before every call. Once the same call (same tool name and identical arguments) is
+// made Threshold times in a row, a RepeatedToolCallEvent is published and the call is
+// handled according to Action instead of being executed. A call that failed with a
+// [ToolErrorTransient] error doesn't count: the model is told it may retry it.
+//
+// Usually configured through executor.Config rather than set directly.
+type RepeatedToolCallPolicy struct {
+	// Threshold is the number of consecutive identical calls that counts as a loop.
+	// For example, 3 means the third identical call in a row is not executed.
+	// Values below 2 disable detection.
+	Threshold int
+
+	// Action is what to do with the repeated call. Defaults to RepeatedToolCallNudge.
+	Action RepeatedToolCallAction
+}
+
+// enabled reports whether the policy detects anything.
+func (p RepeatedToolCallPolicy) enabled() bool {
+	return p.Threshold >= 2
+}
+
+// RepeatedToolCall describes a detected tool call loop. It is returned by
+// [ExecutionContext.CheckRepeatedToolCall] for calls that must not be executed.
+type RepeatedToolCall struct {
+	// ToolName is the name of the repeated tool.
+	ToolName string
+
+	// Count is how many times in a row the call has been made, including this one.
+	Count int
+
+	// Action is the configured action the toolchain should take.
+	Action RepeatedToolCallAction
+
+	// PriorOutput is the output of the last executed identical call.
+	PriorOutput any
+
+	// PriorError is the error of the last executed identical call, if any.
+	PriorError error
+}
+
+// Err returns the error describing the loop, wrapping [ErrRepeatedToolCall].
+func (r *RepeatedToolCall) Err() error {
+	return fmt.Errorf("%w: %s called %d times in a row with identical arguments",
+		ErrRepeatedToolCall, r.ToolName, r.Count)
+}
+
+// toolCallTracker tracks consecutive identical tool calls for an ExecutionContext.
+type toolCallTracker struct {
+	policy RepeatedToolCallPolicy
+
+	lastHash string
+	count    int
+
+	// awaiting is true while the last checked call is being executed; its result is
+	// recorded from the next AfterToolCallEvent.
+	awaiting    bool
+	priorOutput any
+	priorError  error
+
+	// terminateErr is set when a loop was detected with RepeatedToolCallTerminate.
+	terminateErr error
+}
+
+// ToolCallHash returns a normalized hash of a tool call. Calls with the same tool name
+// and arguments that marshal to the same JSON hash the same; map keys are sorted, so
+// argument order does not matter.
+func ToolCallHash(toolName string, args any) string {
+	argsData, err := json.Marshal(args)
+	if err != nil {
+		argsData = fmt.Appendf(nil, "%#v", args)
+	}
+	sum := sha256.Sum256(append([]byte(toolName+"\x00"), argsData...))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetRepeatedToolCallPolicy enables tool call loop detection. Child contexts spawned
+// afterwards inherit the policy but track their own calls.
+//
+// Must be called before execution starts.
+func (ctx *ExecutionContext) SetRepeatedToolCallPolicy(policy RepeatedToolCallPolicy) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.toolCalls = toolCallTracker{policy: policy}
+}
+
+// RepeatedToolCallPolicy returns the configured loop detection policy.
+func (ctx *ExecutionContext) RepeatedToolCallPolicy() RepeatedToolCallPolicy {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.toolCalls.policy
+}
+
+// CheckRepeatedToolCall records a tool call about to be made and reports whether it
+// repeats the previous calls often enough to trigger the [RepeatedToolCallPolicy].
+//
+// It returns nil when the call should be executed normally; the result is then taken
+// from the AfterToolCallEvent published for the call. Otherwise it publishes a
+// RepeatedToolCallEvent and returns how the toolchain should handle the call instead
+// of executing it. Toolchains still publish an AfterToolCallEvent for the skipped call.
+//
+// Always returns nil when no policy is set.
+func (ctx *ExecutionContext) CheckRepeatedToolCall(toolName string, args any) *RepeatedToolCall {
+	if !ctx.RepeatedToolCallPolicy().enabled() {
+		return nil
+	}
+	hash := ToolCallHash(toolName, args)
+
+	var repeated *RepeatedToolCall
+	ctx.updateContextState(func() {
+		t := &ctx.toolCalls
+		if hash == t.lastHash {
+			t.count++
+		} else {
+			t.lastHash = hash
+			t.count = 1
+			t.priorOutput = nil
+			t.priorError = nil
+		}
+
+		if t.count < t.policy.Threshold {
+			t.awaiting = true
+			return
+		}
+		t.awaiting = false
+
+		action := t.policy.Action
+		if action == "" {
+			action = RepeatedToolCallNudge
+		}
+		repeated = &RepeatedToolCall{
+			ToolName:    toolName,
+			Count:       t.count,
+			Action:      action,
+			PriorOutput: t.priorOutput,
+			PriorError:  t.priorError,
+		}
+		if action == RepeatedToolCallTerminate && t.terminateErr == nil {
+			t.terminateErr = repeated.Err()
+		}
+	})
+
+	if repeated != nil {
+		ctx.PublishRepeatedToolCall(toolName, args, repeated.Count, repeated.Action)
+	}
+	return repeated
+}
+
+// RepeatedToolCallError returns the error recorded when a loop was detected with
+// RepeatedToolCallTerminate, or nil. The Executor terminates execution when it is set.
+func (ctx *ExecutionContext) RepeatedToolCallError() error {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.toolCalls.terminateErr
+}
+
+// recordToolCallResult stores the result of the call last passed to
+// CheckRepeatedToolCall. Must be called with lock held.
+func (t *toolCallTracker) recordToolCallResult(event *AfterToolCallEvent) {
+	if !t.awaiting {
+		return
+	}
+	t.awaiting = false
+	// A transient failure invites a retry of the same call, which is not a loop
+	if ToolErrorCategoryOf(event.Error) == ToolErrorTransient {
+		t.lastHash, t.count = "", 0
+		return
+	}
+	t.priorOutput = event.Output
+	t.priorError = event.Error
+}
@@ -0,0 +1,216 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallHash(t *testing.T) {
+	type input struct {
+		nameA, nameB string
+		argsA, argsB any
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected bool // whether the hashes are equal
+	}{
+		{
+			name: "same call",
+			input: input{
+				nameA: "search", argsA: map[string]any{"query": "weather"},
+				nameB: "search", argsB: map[string]any{"query": "weather"},
+			},
+			expected: true,
+		},
+		{
+			name: "key order does not matter",
+			input: input{
+				nameA: "search", argsA: map[string]any{"query": "weather", "limit": 5},
+				nameB: "search", argsB: map[string]any{"limit": 5, "query": "weather"},
+			},
+			expected: true,
+		},
+		{
+			name: "different args",
+			input: input{
+				nameA: "search", argsA: map[string]any{"query": "weather"},
+				nameB: "search", argsB: map[string]any{"query": "news"},
+			},
+			expected: false,
+		},
+		{
+			name: "different tool",
+			input: input{
+				nameA: "search", argsA: map[string]any{"query": "weather"},
+				nameB: "lookup", argsB: map[string]any{"query": "weather"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashA := ToolCallHash(tt.input.nameA, tt.input.argsA)
+			hashB := ToolCallHash(tt.input.nameB, tt.input.argsB)
+			assert.Equal(t, tt.expected, hashA == hashB)
+		})
+	}
+}
+
+func TestExecutionContext_CheckRepeatedToolCall(t *testing.T) {
+	type call struct {
+		args   string
+		output string
+	}
+
+	type expected struct {
+		counts  []int // Count of the returned RepeatedToolCall, 0 when nil
+		prior   any   // PriorOutput of the last detected repetition
+		events  int
+		stopErr bool
+	}
+
+	tests := []struct {
+		name     string
+		input    RepeatedToolCallPolicy
+		calls    []call
+		expected expected
+	}{
+		{
+			name:  "disabled by default",
+			input: RepeatedToolCallPolicy{},
+			calls: []call{{"a", "1"}, {"a", "1"}, {"a", "1"}},
+			expected: expected{
+				counts: []int{0, 0, 0},
+			},
+		},
+		{
+			name:  "third identical call is repeated",
+			input: RepeatedToolCallPolicy{Threshold: 3},
+			calls: []call{{"a", "1"}, {"a", "2"}, {"a", ""}, {"a", ""}},
+			expected: expected{
+				counts: []int{0, 0, 3, 4},
+				prior:  "2",
+				events: 2,
+			},
+		},
+		{
+			name:  "different args reset the count",
+			input: RepeatedToolCallPolicy{Threshold: 2},
+			calls: []call{{"a", "1"}, {"b", "2"}, {"a", "3"}, {"a", ""}},
+			expected: expected{
+				counts: []int{0, 0, 0, 2},
+				prior:  "3",
+				events: 1,
+			},
+		},
+		{
+			name:  "terminate records error",
+			input: RepeatedToolCallPolicy{Threshold: 2, Action: RepeatedToolCallTerminate},
+			calls: []call{{"a", "1"}, {"a", ""}},
+			expected: expected{
+				counts:  []int{0, 2},
+				prior:   "1",
+				events:  1,
+				stopErr: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetRepeatedToolCallPolicy(tt.input)
+
+			var counts []int
+			var last *RepeatedToolCall
+			for _, c := range tt.calls {
+				args := map[string]any{"id": c.args}
+				repeated := execCtx.CheckRepeatedToolCall("lookup", args)
+				if repeated == nil {
+					counts = append(counts, 0)
+					execCtx.PublishAfterToolCall("lookup", args, c.output, 0, nil)
+					continue
+				}
+				counts = append(counts, repeated.Count)
+				last = repeated
+			}
+
+			assert.Equal(t, tt.expected.counts, counts)
+			if tt.expected.events > 0 {
+				require.NotNil(t, last)
+				assert.Equal(t, tt.expected.prior, last.PriorOutput)
+			}
+			assert.Equal(t, int64(tt.expected.events),
+				execCtx.Stats().GetCounter(SCRepeatedToolCalls))
+			assert.Equal(t, int64(tt.expected.events),
+				execCtx.Stats().GetCounter(SCRepeatedToolCallsFor+"lookup"))
+			if tt.expected.stopErr {
+				assert.ErrorIs(t, execCtx.RepeatedToolCallError(), ErrRepeatedToolCall)
+			} else {
+				assert.NoError(t, execCtx.RepeatedToolCallError())
+			}
+		})
+	}
+}
+
+func TestExecutionContext_CheckRepeatedToolCall_TransientErrors(t *testing.T) {
+	type input struct {
+		category ToolErrorCategory
+	}
+
+	type expected struct {
+		counts []int // Count of the returned RepeatedToolCall, 0 when nil
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "retries of a transient failure are not repeats",
+			input:    input{category: ToolErrorTransient},
+			expected: expected{counts: []int{0, 0, 0, 2}},
+		},
+		{
+			name:     "retries of a permanent failure are repeats",
+			input:    input{category: ToolErrorPermanent},
+			expected: expected{counts: []int{0, 2, 3, 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetRepeatedToolCallPolicy(RepeatedToolCallPolicy{Threshold: 2})
+			args := map[string]any{"id": "a"}
+			// Two failed calls, then a success, then the same call again
+			results := []error{
+				NewToolError(tt.input.category, errors.New("timeout")),
+				NewToolError(tt.input.category, errors.New("timeout")),
+				nil,
+				nil,
+			}
+
+			var counts []int
+			for _, err := range results {
+				repeated := execCtx.CheckRepeatedToolCall("lookup", args)
+				if repeated != nil {
+					counts = append(counts, repeated.Count)
+					continue
+				}
+				counts = append(counts, 0)
+				execCtx.PublishAfterToolCall("lookup", args, "found", 0, err)
+			}
+
+			assert.Equal(t, tt.expected.counts, counts)
+		})
+	}
+}
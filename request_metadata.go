@@ -0,0 +1,56 @@
+package gent
+
+import (
+	"context"
+	"maps"
+)
+
+// SetRequestMetadata attaches per-run metadata, such as a tenant ID, trace ID or user
+// tier, for model adapters to read with RequestMetadata, e.g. to route to a deployment
+// or set request headers. The framework never interprets it or adds it to prompts.
+//
+// The map is copied, so later changes to it have no effect. Child contexts spawned
+// afterwards inherit the metadata; call SetRequestMetadata on a child to override it
+// for that child only. A nil or empty map clears it.
+//
+//	execCtx.SetRequestMetadata(map[string]string{"tenant_id": "acme", "tier": "pro"})
+func (ctx *ExecutionContext) SetRequestMetadata(metadata map[string]string) {
+	var stored map[string]string
+	if len(metadata) > 0 {
+		stored = maps.Clone(metadata)
+	}
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.requestMetadata = stored
+}
+
+// RequestMetadata returns a copy of the metadata set with SetRequestMetadata on this
+// context or inherited from its parent, or nil if there is none. It is safe to call on
+// a nil ExecutionContext, which returns nil.
+//
+// A custom [Model] reads it from the execCtx it is called with:
+//
+//	func (m *RoutedModel) GenerateContent(
+//	    execCtx *gent.ExecutionContext,
+//	    streamId, streamTopicId string,
+//	    messages []llms.MessageContent,
+//	    options ...llms.CallOption,
+//	) (*gent.ContentResponse, error) {
+//	    client := m.clients[execCtx.RequestMetadata()["tenant_id"]]
+//	    ...
+//	}
+func (ctx *ExecutionContext) RequestMetadata() map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return maps.Clone(ctx.requestMetadata)
+}
+
+// RequestMetadataFrom returns the request metadata of the ExecutionContext carried by
+// ctx (see ExecutionContextFrom), or nil. Use it in code that only receives the
+// context.Context, such as an HTTP transport below a model adapter.
+func RequestMetadataFrom(ctx context.Context) map[string]string {
+	return ExecutionContextFrom(ctx).RequestMetadata()
+}
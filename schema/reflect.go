@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/rickchristie/gent/internal/fields"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(url.URL{})
+	rawJSONType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// For generates a JSON Schema describing the JSON encoding of type T.
+//
+// It is used to describe structured tool output to the LLM. See [FromType] for the
+// supported types and struct tags.
+//
+// Example:
+//
+//	type Order struct {
+//	    ID     string   `json:"id" description:"Order identifier"`
+//	    Items  []string `json:"items"`
+//	    Note   string   `json:"note,omitempty"`
+//	}
+//
+//	schema.For[Order]()
+//	// {"type": "object", "properties": {...}, "required": ["id", "items"]}
+func For[T any]() map[string]any {
+	return FromType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// FromType generates a JSON Schema describing the JSON encoding of t.
+//
+// Mapping rules:
+//   - Structs become objects. Property names follow the `json` tag; fields tagged "-"
+//     and unexported fields are skipped, and the fields of
+//     embedded structs are promoted, following encoding/json's shadowing rules.
+//   - Fields without "omitempty" are listed as required, in declaration order.
+//   - A `description` struct tag sets the property description.
+//   - A `format` struct tag sets the format keyword (e.g. `format:"email"`).
+//   - Pointers are described by their element type.
+//   - Slices and arrays become arrays ([]byte becomes a string, as encoding/json does).
+//   - Maps become objects with additionalProperties describing the value type.
+//   - time.Time becomes a string with format "date-time"; time.Duration an integer.
+//   - Interfaces and json.RawMessage accept any value (empty schema).
+//
+// Recursive types are described as plain objects at the point of recursion.
+func FromType(t reflect.Type) map[string]any {
+	g := &generator{visiting: make(map[reflect.Type]bool)}
+	return g.fromType(t)
+}
+
+// ForInput generates a JSON Schema for tool input type T.
+//
+// Unlike [For], which describes T's JSON encoding, ForInput describes the values the
+// toolchains accept and convert into T, so well-known types are presented as strings
+// with a format keyword:
+//   - time.Time becomes a string with format "date-time"
+//   - time.Duration becomes a string with format "duration" (e.g. "1h30m" or "PT1H30M")
+//   - url.URL becomes a string with format "uri"
+//
+// All other rules, including the `description` and `format` struct tags, follow
+// [FromType].
+//
+// Example:
+//
+//	type ScheduleInput struct {
+//	    Start     time.Time     `json:"start" description:"When the meeting starts"`
+//	    Length    time.Duration `json:"length" description:"Meeting length"`
+//	    Organizer string        `json:"organizer" format:"email"`
+//	}
+//
+//	tool := gent.NewToolFunc("schedule", "Schedule a meeting",
+//	    schema.ForInput[ScheduleInput](), scheduleFunc)
+func ForInput[T any]() map[string]any {
+	return InputFromType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// InputFromType generates a JSON Schema for tool input type t. See [ForInput].
+func InputFromType(t reflect.Type, opts ...Option) map[string]any {
+	g := &generator{input: true, visiting: make(map[reflect.Type]bool)}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g.fromType(t)
+}
+
+// Option customizes schema generation by [InputFromType].
+type Option func(*generator)
+
+// NullablePointers describes pointers as nullable (e.g., "type": ["string", "null"])
+// and leaves pointer fields out of "required", for values the LLM may omit or set to
+// null.
+func NullablePointers() Option {
+	return func(g *generator) {
+		g.nullablePointers = true
+	}
+}
+
+// generator holds the state of a single schema generation.
+type generator struct {
+	input            bool // describe accepted tool input rather than the JSON encoding
+	nullablePointers bool
+	visiting         map[reflect.Type]bool
+}
+
+func (g *generator) fromType(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Ptr && g.nullablePointers {
+		schema := g.fromType(t.Elem())
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+		return schema
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		if g.input {
+			return map[string]any{"type": "string", "format": "duration"}
+		}
+		return map[string]any{"type": "integer"}
+	case urlType:
+		if g.input {
+			return map[string]any{"type": "string", "format": "uri"}
+		}
+	case rawJSONType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": g.fromType(t.Elem())}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.fromType(t.Elem()),
+		}
+	case reflect.Struct:
+		if g.visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		properties := make(map[string]any)
+		var required []string
+		g.collectFields(t, properties, &required)
+
+		schema := map[string]any{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		// Interfaces and anything else: accept any value
+		return map[string]any{}
+	}
+}
+
+// collectFields adds the JSON-visible fields of struct type t to properties,
+// promoting the fields of embedded structs the way encoding/json does.
+func (g *generator) collectFields(
+	t reflect.Type,
+	properties map[string]any,
+	required *[]string,
+) {
+	for _, field := range fields.Of(t, "json") {
+		prop := g.fromType(field.Field.Type)
+		if description := field.Field.Tag.Get("description"); description != "" {
+			prop["description"] = description
+		}
+		if format := field.Field.Tag.Get("format"); format != "" {
+			prop["format"] = format
+		}
+		properties[field.Name] = prop
+
+		if g.nullablePointers && field.Field.Type.Kind() == reflect.Ptr {
+			continue
+		}
+		if !field.HasOption("omitempty") && !field.HasOption("omitzero") {
+			*required = append(*required, field.Name)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectAddress struct {
+	City string `json:"city"`
+}
+
+type reflectBase struct {
+	ID string `json:"id" description:"Record identifier"`
+}
+
+type reflectOrder struct {
+	reflectBase
+	Status    string            `json:"status" description:"Order status"`
+	Total     float64           `json:"total"`
+	Quantity  int               `json:"quantity,omitempty"`
+	Paid      bool              `json:"paid"`
+	Items     []string          `json:"items"`
+	Address   *reflectAddress   `json:"address,omitempty"`
+	Labels    map[string]int    `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Timeout   time.Duration     `json:"timeout,omitempty"`
+	Extra     any               `json:"extra,omitempty"`
+	Raw       json.RawMessage   `json:"raw,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	Ignored   string            `json:"-"`
+	NoTag     string            // untagged fields use the Go field name
+	internal  string            // unexported fields are skipped
+	Nested    map[string][]bool `json:"nested,omitempty"`
+}
+
+type reflectNode struct {
+	Value    string         `json:"value"`
+	Children []*reflectNode `json:"children,omitempty"`
+}
+
+func TestFromType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    reflect.Type
+		expected map[string]any
+	}{
+		{
+			name:     "string",
+			input:    reflect.TypeOf(""),
+			expected: map[string]any{"type": "string"},
+		},
+		{
+			name:     "pointer to integer",
+			input:    reflect.TypeOf((*int64)(nil)),
+			expected: map[string]any{"type": "integer"},
+		},
+		{
+			name:  "slice of numbers",
+			input: reflect.TypeOf([]float32{}),
+			expected: map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "number"},
+			},
+		},
+		{
+			name:  "struct with tags, embedding and nested types",
+			input: reflect.TypeOf(reflectOrder{}),
+			expected: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{
+						"type":        "string",
+						"description": "Record identifier",
+					},
+					"status": map[string]any{
+						"type":        "string",
+						"description": "Order status",
+					},
+					"total":    map[string]any{"type": "number"},
+					"quantity": map[string]any{"type": "integer"},
+					"paid":     map[string]any{"type": "boolean"},
+					"items": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "string"},
+					},
+					"address": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"city": map[string]any{"type": "string"},
+						},
+						"required": []string{"city"},
+					},
+					"labels": map[string]any{
+						"type":                 "object",
+						"additionalProperties": map[string]any{"type": "integer"},
+					},
+					"created_at": map[string]any{"type": "string", "format": "date-time"},
+					"timeout":    map[string]any{"type": "integer"},
+					"extra":      map[string]any{},
+					"raw":        map[string]any{},
+					"data":       map[string]any{"type": "string"},
+					"NoTag":      map[string]any{"type": "string"},
+					"nested": map[string]any{
+						"type": "object",
+						"additionalProperties": map[string]any{
+							"type":  "array",
+							"items": map[string]any{"type": "boolean"},
+						},
+					},
+				},
+				"required": []string{
+					"id", "status", "total", "paid", "items", "created_at", "NoTag",
+				},
+			},
+		},
+		{
+			name:  "recursive type stops at recursion point",
+			input: reflect.TypeOf(reflectNode{}),
+			expected: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"value": map[string]any{"type": "string"},
+					"children": map[string]any{
+						"type":  "array",
+						"items": map[string]any{"type": "object"},
+					},
+				},
+				"required": []string{"value"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FromType(tt.input))
+		})
+	}
+}
+
+type reflectSchedule struct {
+	Start     time.Time     `json:"start" description:"When the meeting starts"`
+	Length    time.Duration `json:"length" description:"Meeting length"`
+	Link      *url.URL      `json:"link,omitempty"`
+	Organizer string        `json:"organizer" format:"email" description:"Organizer email"`
+}
+
+func TestInputFromType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    reflect.Type
+		expected map[string]any
+	}{
+		{
+			name:     "duration",
+			input:    reflect.TypeOf(time.Duration(0)),
+			expected: map[string]any{"type": "string", "format": "duration"},
+		},
+		{
+			name:     "url",
+			input:    reflect.TypeOf(url.URL{}),
+			expected: map[string]any{"type": "string", "format": "uri"},
+		},
+		{
+			name:  "struct keeps descriptions alongside formats",
+			input: reflect.TypeOf(reflectSchedule{}),
+			expected: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"start": map[string]any{
+						"type":        "string",
+						"format":      "date-time",
+						"description": "When the meeting starts",
+					},
+					"length": map[string]any{
+						"type":        "string",
+						"format":      "duration",
+						"description": "Meeting length",
+					},
+					"link": map[string]any{"type": "string", "format": "uri"},
+					"organizer": map[string]any{
+						"type":        "string",
+						"format":      "email",
+						"description": "Organizer email",
+					},
+				},
+				"required": []string{"start", "length", "organizer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InputFromType(tt.input))
+		})
+	}
+}
+
+func TestInputFromType_NullablePointers(t *testing.T) {
+	result := InputFromType(reflect.TypeOf(reflectSchedule{}), NullablePointers())
+
+	properties := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": []string{"string", "null"}, "format": "uri"},
+		properties["link"])
+	assert.Equal(t, []string{"start", "length", "organizer"}, result["required"])
+}
+
+func TestFromType_FormatTagAndOutputEncoding(t *testing.T) {
+	// Output schemas describe the JSON encoding: durations stay integers
+	result := FromType(reflect.TypeOf(reflectSchedule{}))
+
+	properties := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer", "description": "Meeting length"},
+		properties["length"])
+	assert.Equal(t, map[string]any{
+		"type": "string", "format": "email", "description": "Organizer email",
+	}, properties["organizer"])
+}
+
+func TestForInput(t *testing.T) {
+	assert.Equal(t, InputFromType(reflect.TypeOf(reflectSchedule{})), ForInput[reflectSchedule]())
+}
+
+func TestFor(t *testing.T) {
+	assert.Equal(t, FromType(reflect.TypeOf(reflectAddress{})), For[reflectAddress]())
+	assert.Equal(t, FromType(reflect.TypeOf(reflectAddress{})), For[*reflectAddress]())
+}
+
+type reflectAudit struct {
+	ID        string `json:"id" description:"Audit identifier"`
+	CreatedBy string `json:"created_by"`
+}
+
+type reflectRecord struct {
+	reflectAudit
+	Version int `json:"version,omitempty"`
+}
+
+type reflectLeft struct {
+	Shared string
+}
+
+type reflectRight struct {
+	Shared string
+}
+
+type reflectInvoice struct {
+	*reflectRecord
+	reflectLeft
+	reflectRight
+	ID    string `json:"id" description:"Invoice number"` // shadows reflectAudit.ID
+	Total int    `json:"total"`
+}
+
+func TestFromType_EmbeddedStructs(t *testing.T) {
+	// Fields are promoted through two levels of embedding, the tagged ID on the outer
+	// struct shadows the promoted one, and the ambiguous Shared fields are dropped, all
+	// matching what encoding/json does.
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string", "description": "Invoice number"},
+			"created_by": map[string]any{"type": "string"},
+			"version":    map[string]any{"type": "integer"},
+			"total":      map[string]any{"type": "integer"},
+		},
+		"required": []string{"created_by", "id", "total"},
+	}
+
+	assert.Equal(t, expected, FromType(reflect.TypeOf(reflectInvoice{})))
+
+	data, err := json.Marshal(reflectInvoice{
+		reflectRecord: &reflectRecord{
+			reflectAudit: reflectAudit{ID: "audit", CreatedBy: "alice"},
+			Version:      2,
+		},
+		ID:    "inv-1",
+		Total: 10,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"id":"inv-1","created_by":"alice","version":2,"total":10}`, string(data))
+}
@@ -65,6 +65,18 @@ func (s *Schema) Validate(data map[string]any) error {
 	return nil
 }
 
+// ValidateValue validates any decoded JSON value (object, array or primitive) against
+// the schema. Unlike [Schema.Validate], a nil value is validated as JSON null.
+func (s *Schema) ValidateValue(data any) error {
+	if s == nil || s.compiled == nil {
+		return nil
+	}
+	if err := s.compiled.Validate(data); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
 // requiredList returns a comma-separated list of required properties
 // from the raw schema, or "(none)" if there are none.
 func (s *Schema) requiredList() string {
@@ -172,6 +184,9 @@ func describeType(propMap map[string]any) string {
 	if typ == "" {
 		return "any"
 	}
+	if format, ok := propMap["format"].(string); ok && format != "" {
+		return typ + ", format: " + format
+	}
 	return typ
 }
 
@@ -240,6 +240,36 @@ func TestSchema_Validate_NilSchema(t *testing.T) {
 	assert.NoError(t, err, "nil schema should always pass validation")
 }
 
+func TestSchema_ValidateValue(t *testing.T) {
+	tags := MustCompile(map[string]any{
+		"type":  "array",
+		"items": map[string]any{"type": "string"},
+	})
+
+	tests := []struct {
+		name     string
+		input    any
+		expected bool // valid
+	}{
+		{name: "array of strings", input: []any{"a", "b"}, expected: true},
+		{name: "null", input: nil, expected: false},
+		{name: "wrong item type", input: []any{"a", 1.0}, expected: false},
+		{name: "object", input: map[string]any{}, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tags.ValidateValue(tc.input)
+			if tc.expected {
+				assert.NoError(t, err)
+			} else {
+				var validationErr *ValidationError
+				assert.ErrorAs(t, err, &validationErr)
+			}
+		})
+	}
+}
+
 func TestMustCompile(t *testing.T) {
 	type input struct {
 		raw map[string]any
@@ -511,6 +541,21 @@ func TestSchema_DescribeFields(t *testing.T) {
 					" array of string): List of tags\n",
 			},
 		},
+		{
+			name: "format is shown with the type",
+			input: input{
+				schema: MustCompile(Object(
+					map[string]*Property{
+						"start": String("Start time").Format("date-time"),
+					},
+					"start",
+				)),
+			},
+			expected: expected{
+				output: "  - 'start' (required," +
+					" string, format: date-time): Start time\n",
+			},
+		},
 		{
 			name: "array of object items",
 			input: input{
@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// StructFromJSONSchema builds a struct type from an object JSON Schema, the reverse of
+// [FromType]. Use it to decode arguments of schemas only known at runtime (e.g. from an
+// external tool registry) into a struct instead of a map[string]any.
+//
+// Mapping rules:
+//   - Each property becomes an exported field, in lexical order of the property names.
+//     The `json` tag keeps the property name, and the `description` tag its description.
+//   - Properties not listed in "required" get the "omitempty" option.
+//   - "string" becomes string, "integer" int64, "number" float64, "boolean" bool.
+//   - "array" becomes a slice of its items' type ([]any without "items").
+//   - "object" with properties becomes a nested struct; with additionalProperties a
+//     map[string] of their type; otherwise map[string]any.
+//   - A nullable type (e.g., ["string", "null"]) becomes a pointer.
+//   - A missing type, or several non-null types, becomes any.
+//
+// Keywords that only constrain values (enum, bounds, patterns, ...) are ignored: validate
+// the arguments with [Compile] first.
+//
+// Example:
+//
+//	inputType, err := schema.StructFromJSONSchema(rawSchema)
+//	if err != nil {
+//	    return err
+//	}
+//	input := reflect.New(inputType)
+//	if err := json.Unmarshal(argsJSON, input.Interface()); err != nil {
+//	    return err
+//	}
+func StructFromJSONSchema(raw map[string]any) (reflect.Type, error) {
+	if typ, _ := raw["type"].(string); typ != "object" && raw["properties"] == nil {
+		return nil, fmt.Errorf("schema must describe an object")
+	}
+	return structFromProperties(raw)
+}
+
+// structFromProperties builds the struct type of an object schema.
+func structFromProperties(raw map[string]any) (reflect.Type, error) {
+	properties, _ := raw["properties"].(map[string]any)
+	required := make(map[string]bool)
+	switch list := raw["required"].(type) {
+	case []any:
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	case []string:
+		for _, name := range list {
+			required[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]reflect.StructField, 0, len(names))
+	used := make(map[string]bool)
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("property %q: schema must be an object", name)
+		}
+		fieldType, err := typeFromSchema(prop)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+
+		jsonTag := name
+		if !required[name] {
+			jsonTag += ",omitempty"
+		} else if name == "-" {
+			jsonTag = "-," // a bare "-" would skip the field
+		}
+		tag := `json:` + strconv.Quote(jsonTag)
+		if description, ok := prop["description"].(string); ok && description != "" {
+			tag += ` description:` + strconv.Quote(description)
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: fieldName(name, used),
+			Type: fieldType,
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// typeFromSchema returns the Go type of a property schema.
+func typeFromSchema(prop map[string]any) (reflect.Type, error) {
+	var types []string
+	switch typ := prop["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []any:
+		for _, t := range typ {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+	case []string:
+		types = typ
+	case nil:
+		if prop["properties"] != nil {
+			types = []string{"object"}
+		}
+	}
+
+	nullable := false
+	var nonNull []string
+	for _, typ := range types {
+		if typ == "null" {
+			nullable = true
+			continue
+		}
+		nonNull = append(nonNull, typ)
+	}
+	if len(nonNull) != 1 {
+		return anyType, nil
+	}
+
+	t, err := typeFromName(nonNull[0], prop)
+	if err != nil {
+		return nil, err
+	}
+	if nullable && t.Kind() != reflect.Interface {
+		return reflect.PointerTo(t), nil
+	}
+	return t, nil
+}
+
+// typeFromName returns the Go type of a property schema of the given JSON Schema type.
+func typeFromName(typ string, prop map[string]any) (reflect.Type, error) {
+	switch typ {
+	case "string":
+		return reflect.TypeOf(""), nil
+	case "integer":
+		return reflect.TypeOf(int64(0)), nil
+	case "number":
+		return reflect.TypeOf(float64(0)), nil
+	case "boolean":
+		return reflect.TypeOf(false), nil
+	case "array":
+		items, ok := prop["items"].(map[string]any)
+		if !ok {
+			return reflect.SliceOf(anyType), nil
+		}
+		elem, err := typeFromSchema(items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		return reflect.SliceOf(elem), nil
+	case "object":
+		if _, ok := prop["properties"].(map[string]any); ok {
+			return structFromProperties(prop)
+		}
+		if additional, ok := prop["additionalProperties"].(map[string]any); ok {
+			elem, err := typeFromSchema(additional)
+			if err != nil {
+				return nil, fmt.Errorf("additionalProperties: %w", err)
+			}
+			return reflect.MapOf(reflect.TypeOf(""), elem), nil
+		}
+		return reflect.TypeOf(map[string]any(nil)), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+}
+
+// fieldName returns an exported Go identifier for a property name, e.g. "OrderId" for
+// "order_id", that is not in used yet, and marks it as used.
+func fieldName(property string, used map[string]bool) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range property {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+
+	name := sb.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Field" + name
+	}
+	base := name
+	for i := 2; used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructFromJSONSchema(t *testing.T) {
+	type input struct {
+		schema map[string]any
+	}
+
+	type expected struct {
+		fields []string // name, type and tag of each field
+		err    string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "scalars with required and descriptions",
+			input: input{schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"order_id": map[string]any{"type": "string", "description": "Order ID"},
+					"quantity": map[string]any{"type": "integer", "minimum": 1},
+					"price":    map[string]any{"type": "number"},
+					"gift":     map[string]any{"type": "boolean"},
+				},
+				"required": []any{"order_id", "quantity"},
+			}},
+			expected: expected{fields: []string{
+				`Gift bool json:"gift,omitempty"`,
+				`OrderId string json:"order_id" description:"Order ID"`,
+				`Price float64 json:"price,omitempty"`,
+				`Quantity int64 json:"quantity"`,
+			}},
+		},
+		{
+			name: "arrays, maps, nested objects and nullable types",
+			input: input{schema: map[string]any{
+				"properties": map[string]any{
+					"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"extra": map[string]any{"type": "array"},
+					"labels": map[string]any{
+						"type":                 "object",
+						"additionalProperties": map[string]any{"type": "integer"},
+					},
+					"meta": map[string]any{"type": "object"},
+					"address": map[string]any{
+						"properties": map[string]any{"city": map[string]any{"type": "string"}},
+						"required":   []string{"city"},
+					},
+					"note":  map[string]any{"type": []any{"string", "null"}},
+					"value": map[string]any{},
+				},
+			}},
+			expected: expected{fields: []string{
+				`Address struct { City string "json:\"city\"" } json:"address,omitempty"`,
+				`Extra []interface {} json:"extra,omitempty"`,
+				`Labels map[string]int64 json:"labels,omitempty"`,
+				`Meta map[string]interface {} json:"meta,omitempty"`,
+				`Note *string json:"note,omitempty"`,
+				`Tags []string json:"tags,omitempty"`,
+				`Value interface {} json:"value,omitempty"`,
+			}},
+		},
+		{
+			name: "property names that are not Go identifiers",
+			input: input{schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"2fa":     map[string]any{"type": "boolean"},
+					"user-id": map[string]any{"type": "string"},
+					"user_id": map[string]any{"type": "string"},
+					"-":       map[string]any{"type": "string"},
+				},
+			}},
+			expected: expected{fields: []string{
+				`Field string json:"-,omitempty"`,
+				`Field2fa bool json:"2fa,omitempty"`,
+				`UserId string json:"user-id,omitempty"`,
+				`UserId2 string json:"user_id,omitempty"`,
+			}},
+		},
+		{
+			name:     "not an object",
+			input:    input{schema: map[string]any{"type": "string"}},
+			expected: expected{err: "schema must describe an object"},
+		},
+		{
+			name: "unsupported type",
+			input: input{schema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"when": map[string]any{"type": "date"}},
+			}},
+			expected: expected{err: `property "when": unsupported type "date"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := StructFromJSONSchema(tt.input.schema)
+
+			if tt.expected.err != "" {
+				assert.EqualError(t, err, tt.expected.err)
+				return
+			}
+			require.NoError(t, err)
+			fields := make([]string, result.NumField())
+			for i := range fields {
+				f := result.Field(i)
+				fields[i] = f.Name + " " + f.Type.String() + " " + string(f.Tag)
+			}
+			assert.Equal(t, tt.expected.fields, fields)
+		})
+	}
+}
+
+func TestStructFromJSONSchema_DecodesArguments(t *testing.T) {
+	raw := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":  map[string]any{"type": "string"},
+			"days":  map[string]any{"type": "integer"},
+			"units": map[string]any{"type": []any{"string", "null"}},
+		},
+		"required": []any{"city"},
+	}
+	structType, err := StructFromJSONSchema(raw)
+	require.NoError(t, err)
+
+	value := reflect.New(structType)
+	require.NoError(t, json.Unmarshal([]byte(`{"city": "Oslo", "days": 3}`), value.Interface()))
+
+	assert.Equal(t, "Oslo", value.Elem().FieldByName("City").String())
+	assert.Equal(t, int64(3), value.Elem().FieldByName("Days").Int())
+	assert.True(t, value.Elem().FieldByName("Units").IsNil())
+
+	// The generated struct describes the same schema
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":  map[string]any{"type": "string"},
+			"days":  map[string]any{"type": "integer"},
+			"units": map[string]any{"type": "string"},
+		},
+		"required": []string{"city"},
+	}, FromType(structType))
+}
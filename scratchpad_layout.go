@@ -0,0 +1,59 @@
+package gent
+
+// ScratchpadLayout determines the order in which an AgentLoop renders the scratchpad
+// into the messages sent to the model. It changes the rendered order only; the
+// scratchpad stored in LoopData is left as is.
+type ScratchpadLayout int
+
+const (
+	// ScratchpadChronologicalOldestFirst renders the scratchpad in stored order, oldest
+	// iteration first and the most recent one last, where models attend most strongly.
+	// This is the default.
+	ScratchpadChronologicalOldestFirst ScratchpadLayout = iota
+
+	// ScratchpadSummaryFirstThenRecent renders the synopsis iterations produced by
+	// compaction (Origin [IterationCompactedSynthetic], e.g. by
+	// compaction.SummarizationStrategy) at the top of the scratchpad, followed by the
+	// remaining iterations in stored order. Useful for models that do better with the
+	// summary pinned above pinned, retrieved or recent iterations that precede it in
+	// the stored scratchpad.
+	ScratchpadSummaryFirstThenRecent
+)
+
+// Arrange returns the scratchpad iterations in the order this layout renders them.
+// The returned slice is new when the order differs; iterations are not copied. Nil
+// iterations are skipped when reordering.
+func (l ScratchpadLayout) Arrange(scratchpad []*Iteration) []*Iteration {
+	if l != ScratchpadSummaryFirstThenRecent {
+		return scratchpad
+	}
+
+	arranged := make([]*Iteration, 0, len(scratchpad))
+	for _, iter := range scratchpad {
+		if iter != nil && iter.Origin == IterationCompactedSynthetic {
+			arranged = append(arranged, iter)
+		}
+	}
+	for _, iter := range scratchpad {
+		if iter != nil && iter.Origin != IterationCompactedSynthetic {
+			arranged = append(arranged, iter)
+		}
+	}
+	return arranged
+}
+
+// SetScratchpadLayout sets the order in which AgentLoops render the scratchpad. Child
+// contexts spawned afterwards inherit the layout. Usually configured through
+// executor.Config.ScratchpadLayout rather than called directly.
+func (ctx *ExecutionContext) SetScratchpadLayout(layout ScratchpadLayout) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.scratchpadLayout = layout
+}
+
+// ScratchpadLayout returns the order in which AgentLoops render the scratchpad.
+func (ctx *ExecutionContext) ScratchpadLayout() ScratchpadLayout {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	return ctx.scratchpadLayout
+}
@@ -0,0 +1,85 @@
+package gent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScratchpadLayout_Arrange(t *testing.T) {
+	recent := &Iteration{}
+	pinned := &Iteration{Origin: IterationCompactedModified}
+	summary := &Iteration{Origin: IterationCompactedSynthetic}
+
+	type input struct {
+		layout     ScratchpadLayout
+		scratchpad []*Iteration
+	}
+
+	type expected struct {
+		arranged []*Iteration
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "chronological keeps stored order",
+			input: input{
+				layout:     ScratchpadChronologicalOldestFirst,
+				scratchpad: []*Iteration{pinned, summary, recent},
+			},
+			expected: expected{arranged: []*Iteration{pinned, summary, recent}},
+		},
+		{
+			name: "summary first moves synopsis to the top",
+			input: input{
+				layout:     ScratchpadSummaryFirstThenRecent,
+				scratchpad: []*Iteration{pinned, summary, recent},
+			},
+			expected: expected{arranged: []*Iteration{summary, pinned, recent}},
+		},
+		{
+			name: "summary first without synopsis keeps stored order",
+			input: input{
+				layout:     ScratchpadSummaryFirstThenRecent,
+				scratchpad: []*Iteration{pinned, recent},
+			},
+			expected: expected{arranged: []*Iteration{pinned, recent}},
+		},
+		{
+			name: "summary first skips nil iterations",
+			input: input{
+				layout:     ScratchpadSummaryFirstThenRecent,
+				scratchpad: []*Iteration{pinned, nil, summary, recent},
+			},
+			expected: expected{arranged: []*Iteration{summary, pinned, recent}},
+		},
+		{
+			name:     "empty scratchpad",
+			input:    input{layout: ScratchpadSummaryFirstThenRecent},
+			expected: expected{arranged: []*Iteration{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			arranged := tc.input.layout.Arrange(tc.input.scratchpad)
+
+			assert.Equal(t, tc.expected.arranged, arranged)
+		})
+	}
+}
+
+func TestExecutionContext_ScratchpadLayoutInherited(t *testing.T) {
+	parent := NewExecutionContext(context.Background(), "parent", nil)
+	assert.Equal(t, ScratchpadChronologicalOldestFirst, parent.ScratchpadLayout())
+
+	parent.SetScratchpadLayout(ScratchpadSummaryFirstThenRecent)
+	child := parent.SpawnChild("child", nil)
+
+	assert.Equal(t, ScratchpadSummaryFirstThenRecent, child.ScratchpadLayout())
+}
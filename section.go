@@ -1,5 +1,10 @@
 package gent
 
+import (
+	"fmt"
+	"strings"
+)
+
 // TextSection defines a section within the LLM's text output.
 //
 // # Concept
@@ -100,6 +105,105 @@ type TextSection interface {
 	ParseSection(execCtx *ExecutionContext, content string) (any, error)
 }
 
+// RepairableSection is an optional extension of [TextSection] for sections that carry
+// a section-specific hint to help the model fix its output after a parse failure.
+//
+// When ParseSection fails, agent loops append the repair guidance to the next
+// observation, alongside the parse error. The hint only appears for the section that
+// failed, and only in the iteration after the failure. The parse error is still
+// published, so [SGSectionParseErrorConsecutive] limits apply as usual.
+//
+// Example:
+//
+//	classify := section.NewJSON[Classification]("classify").
+//	    WithRepairGuidance("Remember to quote the category string.")
+type RepairableSection interface {
+	TextSection
+
+	// RepairGuidance returns the hint to show after a parse failure.
+	// An empty string disables the hint.
+	RepairGuidance() string
+}
+
+// EphemeralSection is an optional extension of [TextSection] for sections whose content
+// only matters in the iteration that produced it, such as free-form reasoning.
+//
+// Agent loops still parse ephemeral sections and keep the full response in the iteration
+// history, so hooks and subscribers see the content. The copy of the iteration added to
+// the scratchpad has ephemeral sections removed, so later prompts do not carry them.
+// Removal requires a [TextFormat] that implements [SectionRemover]; with other formats
+// the response is kept as-is.
+//
+// Compaction strategies operate on the scratchpad, so they never see or summarize
+// ephemeral content.
+//
+// Example:
+//
+//	thinking := section.NewText("thinking").WithEphemeral()
+type EphemeralSection interface {
+	TextSection
+
+	// Ephemeral reports whether the section should be left out of later prompts.
+	Ephemeral() bool
+}
+
+// SectionMultiplicity describes how agent loops handle a section that appears more than
+// once in a single response.
+type SectionMultiplicity int
+
+const (
+	// SectionRepeat parses each occurrence on its own. Tool call sections use this, since
+	// the model may legitimately emit several action blocks.
+	SectionRepeat SectionMultiplicity = iota
+
+	// SectionJoin joins all occurrences, separated by a blank line, and parses them once.
+	// Free-form text sections use this so a second thinking block is not dropped.
+	SectionJoin
+
+	// SectionSingle treats more than one occurrence as a parse error wrapping
+	// [ErrDuplicateSection]. Structured sections that yield a single value use this.
+	SectionSingle
+)
+
+// MultiplicitySection is an optional extension of [TextSection] for sections that define
+// what happens when the model emits them more than once in one response. Sections that
+// do not implement it are treated as [SectionRepeat].
+//
+// Agent loops apply the multiplicity with [SectionContents] before parsing. Built-in
+// sections expose it via WithMultiple() and WithSingle():
+//
+//	thinking := section.NewText("thinking").WithSingle()   // default: joined
+//	plan := section.NewJSON[Plan]("plan").WithMultiple()   // default: single
+//	tc := toolchain.NewYAML().WithSingle()                 // default: repeated
+type MultiplicitySection interface {
+	TextSection
+
+	// Multiplicity reports how repeated occurrences of the section are handled.
+	Multiplicity() SectionMultiplicity
+}
+
+// SectionContents applies the multiplicity of s to the contents a [TextFormat] extracted
+// for it, returning the contents to parse one by one. It returns an error wrapping
+// [ErrDuplicateSection] if s is [SectionSingle] and contents holds more than one entry.
+func SectionContents(s TextSection, contents []string) ([]string, error) {
+	if len(contents) <= 1 {
+		return contents, nil
+	}
+	ms, ok := s.(MultiplicitySection)
+	if !ok {
+		return contents, nil
+	}
+	switch ms.Multiplicity() {
+	case SectionJoin:
+		return []string{strings.Join(contents, "\n\n")}, nil
+	case SectionSingle:
+		return nil, fmt.Errorf("%w: section %q appears %d times, expected exactly one",
+			ErrDuplicateSection, s.Name(), len(contents))
+	default:
+		return contents, nil
+	}
+}
+
 // TextOutputSection is an alias for TextSection for backward compatibility.
 // Deprecated: Use TextSection instead.
 type TextOutputSection = TextSection
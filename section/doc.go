@@ -76,4 +76,12 @@
 //   - json/yaml: Field naming (e.g., `json:"field_name"`)
 //   - omitempty: Marks field as optional
 //   - description: Adds description to schema (e.g., `description:"helpful text"`)
+//   - format: Sets the format keyword (e.g., `format:"email"`)
+//
+// time.Time, time.Duration and url.URL fields get the "date-time", "duration" and
+// "uri" formats, as in tool input schemas generated with [schema.ForInput].
+//
+// Schema() returns the same schema compiled, for generating documentation or
+// validating data outside the agent. Registered tools' parameter schemas are listed the
+// same way by toolchains implementing [gent.ToolCatalog] ([gent.ToolInfo].Schema).
 package section
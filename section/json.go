@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/schema"
 )
 
 // JSON implements [gent.TextSection] for structured JSON content.
@@ -59,17 +60,23 @@ import (
 // When JSON parsing fails, a [gent.ParseErrorEvent] is published and the error
 // is returned. The framework tracks consecutive parse errors via
 // [gent.SGSectionParseErrorConsecutive] for limit enforcement.
+//
+// A section that appears more than once in a response is also a parse error, since it
+// parses into a single value. Use WithMultiple to parse each occurrence instead.
 type JSON[T any] struct {
-	sectionName string
-	guidance    string
-	example     *T
+	sectionName    string
+	guidance       string
+	example        *T
+	repairGuidance string
+	multiplicity   gent.SectionMultiplicity
 }
 
 // NewJSON creates a new JSON section with the given name.
 func NewJSON[T any](name string) *JSON[T] {
 	return &JSON[T]{
-		sectionName: name,
-		guidance:    "",
+		sectionName:  name,
+		guidance:     "",
+		multiplicity: gent.SectionSingle,
 	}
 }
 
@@ -85,11 +92,53 @@ func (j *JSON[T]) WithGuidance(guidance string) *JSON[T] {
 
 // WithExample sets an example value to include in the guidance.
 // The example is serialized to JSON and appended after the schema.
+//
+// Panics if the example does not conform to the schema generated from T (e.g. a nil
+// slice, which encodes as null where an array is required), so a wrong example is
+// caught when the agent is built rather than taught to the model.
 func (j *JSON[T]) WithExample(example T) *JSON[T] {
+	var zero T
+	if err := validateExample(reflect.TypeOf(zero), example); err != nil {
+		panic(fmt.Sprintf("section %q: example does not match schema: %v", j.sectionName, err))
+	}
 	j.example = &example
 	return j
 }
 
+// WithRepairGuidance sets a hint that is appended to the next observation when this
+// section fails to parse, e.g. "Remember to quote the category string.". Unlike the
+// generic JSON error message, the hint is specific to this section.
+func (j *JSON[T]) WithRepairGuidance(guidance string) *JSON[T] {
+	j.repairGuidance = guidance
+	return j
+}
+
+// RepairGuidance returns the hint set by WithRepairGuidance.
+// Implements [gent.RepairableSection].
+func (j *JSON[T]) RepairGuidance() string {
+	return j.repairGuidance
+}
+
+// WithMultiple allows the model to emit this section more than once in a response.
+// Each occurrence is parsed on its own.
+func (j *JSON[T]) WithMultiple() *JSON[T] {
+	j.multiplicity = gent.SectionRepeat
+	return j
+}
+
+// WithSingle makes a repeated occurrence of this section a parse error (the default),
+// since it parses into a single value.
+func (j *JSON[T]) WithSingle() *JSON[T] {
+	j.multiplicity = gent.SectionSingle
+	return j
+}
+
+// Multiplicity reports how repeated occurrences are handled.
+// Implements [gent.MultiplicitySection].
+func (j *JSON[T]) Multiplicity() gent.SectionMultiplicity {
+	return j.multiplicity
+}
+
 // Name returns the section identifier.
 func (j *JSON[T]) Name() string {
 	return j.sectionName
@@ -107,9 +156,7 @@ func (j *JSON[T]) Guidance() string {
 	sb.WriteString(j.sectionName)
 	sb.WriteString(" content must be valid JSON matching this schema:\n")
 
-	var zero T
-	schema := GenerateJSONSchema(reflect.TypeOf(zero))
-	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	schemaJSON, err := json.MarshalIndent(j.JSONSchema(), "", "  ")
 	if err == nil {
 		sb.Write(schemaJSON)
 	}
@@ -125,6 +172,20 @@ func (j *JSON[T]) Guidance() string {
 	return sb.String()
 }
 
+// JSONSchema returns the JSON Schema derived from T.
+// Implements [gent.SchemaSection].
+func (j *JSON[T]) JSONSchema() map[string]any {
+	var zero T
+	return GenerateJSONSchema(reflect.TypeOf(zero))
+}
+
+// Schema returns the compiled JSON Schema derived from T: the schema shown in Guidance
+// and returned by JSONSchema. Use it to document sections outside the agent, e.g. by
+// serializing Schema().Raw().
+func (j *JSON[T]) Schema() (*schema.Schema, error) {
+	return schema.Compile(j.JSONSchema())
+}
+
 // ParseSection parses the JSON content into type T.
 func (j *JSON[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (any, error) {
 	content = strings.TrimSpace(content)
@@ -153,3 +214,12 @@ func (j *JSON[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (
 
 // Compile-time check that JSON implements gent.TextOutputSection.
 var _ gent.TextOutputSection = (*JSON[any])(nil)
+
+// Compile-time check that JSON implements gent.SchemaSection.
+var _ gent.SchemaSection = (*JSON[any])(nil)
+
+// Compile-time check that JSON implements gent.RepairableSection.
+var _ gent.RepairableSection = (*JSON[any])(nil)
+
+// Compile-time check that JSON implements gent.MultiplicitySection.
+var _ gent.MultiplicitySection = (*JSON[any])(nil)
@@ -2,6 +2,8 @@ package section
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -544,6 +546,23 @@ func TestJSON_ParseSection_WithMap(t *testing.T) {
 	assert.Equal(t, "value2", parsed.Metadata["key2"])
 }
 
+func TestJSON_ParseSection_EmbeddedStructs(t *testing.T) {
+	section := NewJSON[TestEmbedOrder]("order")
+
+	input := `{"id": "order-1", "created_by": "alice", "version": 2, "status": "open"}`
+	result, err := section.ParseSection(nil, input)
+
+	require.NoError(t, err)
+	assert.Equal(t, TestEmbedOrder{
+		TestEmbedRecord: &TestEmbedRecord{
+			TestEmbedAudit: TestEmbedAudit{CreatedBy: "alice"},
+			Version:        2,
+		},
+		ID:     "order-1",
+		Status: "open",
+	}, result)
+}
+
 func TestJSON_MethodChaining(t *testing.T) {
 	section := NewJSON[SimpleStruct]("analysis").
 		WithGuidance("Analyze the data.").
@@ -555,6 +574,120 @@ func TestJSON_MethodChaining(t *testing.T) {
 	assert.Contains(t, section.Guidance(), "example")
 }
 
+func TestJSON_WithExample(t *testing.T) {
+	type expected struct {
+		panicMsg string
+		contains []string
+	}
+
+	tests := []struct {
+		name     string
+		input    func() *JSON[NestedStruct]
+		expected expected
+	}{
+		{
+			name: "conforming example is rendered as JSON",
+			input: func() *JSON[NestedStruct] {
+				return NewJSON[NestedStruct]("data").WithExample(NestedStruct{
+					ID:   1,
+					Data: SimpleStruct{Name: "example", Value: 100},
+					Tags: []string{"a"},
+				})
+			},
+			expected: expected{
+				contains: []string{
+					"Example:\n{\n  \"id\": 1,",
+					"\"tags\": [\n    \"a\"\n  ]",
+				},
+			},
+		},
+		{
+			name: "nil slice panics",
+			input: func() *JSON[NestedStruct] {
+				return NewJSON[NestedStruct]("data").WithExample(NestedStruct{ID: 1})
+			},
+			expected: expected{
+				panicMsg: `section "data": example does not match schema`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expected.panicMsg != "" {
+				defer func() {
+					r := recover()
+					require.NotNil(t, r, "expected panic")
+					assert.Contains(t, r, tc.expected.panicMsg)
+					assert.Contains(t, r, "/tags")
+				}()
+			}
+
+			guidance := tc.input().Guidance()
+
+			for _, want := range tc.expected.contains {
+				assert.Contains(t, guidance, want)
+			}
+		})
+	}
+}
+
+func TestJSON_WithRepairGuidance(t *testing.T) {
+	section := NewJSON[SimpleStruct]("analysis")
+	assert.Equal(t, "", section.RepairGuidance())
+
+	section.WithRepairGuidance("Quote every string value.")
+	assert.Equal(t, "Quote every string value.", section.RepairGuidance())
+	assert.NotContains(t, section.Guidance(), "Quote every string value.",
+		"repair guidance must only appear after a parse failure")
+}
+
+func TestJSON_Schema(t *testing.T) {
+	type input struct {
+		value map[string]any
+	}
+
+	type expected struct {
+		valid bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "value matching the schema",
+			input:    input{value: map[string]any{"required": "x", "pointer": nil}},
+			expected: expected{valid: true},
+		},
+		{
+			name:     "value missing a required field",
+			input:    input{value: map[string]any{"optional": "x"}},
+			expected: expected{valid: false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			section := NewJSON[StructWithOmitempty]("result")
+
+			compiled, err := section.Schema()
+			require.NoError(t, err)
+
+			// Same schema as the one shown to the model
+			_, rendered, found := strings.Cut(section.Guidance(), "matching this schema:\n")
+			require.True(t, found)
+			expectedJSON, err := json.Marshal(compiled.Raw())
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expectedJSON), rendered)
+			assert.Equal(t, section.JSONSchema(), compiled.Raw())
+
+			assert.Equal(t, tc.expected.valid, compiled.Validate(tc.input.value) == nil)
+		})
+	}
+}
+
 func TestJSON_ImplementsTextOutputSection(t *testing.T) {
 	var _ gent.TextOutputSection = (*JSON[any])(nil)
 }
@@ -1,11 +1,14 @@
 package section
 
 import (
+	"encoding/json"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateJSONSchema_Primitives(t *testing.T) {
@@ -140,8 +143,24 @@ func TestGenerateJSONSchema_SpecialTypes(t *testing.T) {
 			name:  "time.Duration",
 			input: reflect.TypeOf(time.Duration(0)),
 			expected: map[string]any{
-				"type":        "string",
-				"description": "Duration string (e.g., '1h30m', '2s')",
+				"type":   "string",
+				"format": "duration",
+			},
+		},
+		{
+			name:  "url.URL",
+			input: reflect.TypeOf(url.URL{}),
+			expected: map[string]any{
+				"type":   "string",
+				"format": "uri",
+			},
+		},
+		{
+			name:  "pointer to url.URL",
+			input: reflect.TypeOf(&url.URL{}),
+			expected: map[string]any{
+				"type":   []string{"string", "null"},
+				"format": "uri",
 			},
 		},
 	}
@@ -322,3 +341,68 @@ func TestGenerateJSONSchema_UntaggedFields(t *testing.T) {
 	// Untagged field uses the Go field name
 	assert.Contains(t, props, "UntaggedField")
 }
+
+func TestGenerateJSONSchema_DeterministicOutput(t *testing.T) {
+	type Address struct {
+		Street  string `json:"street"`
+		City    string `json:"city"`
+		Country string `json:"country" description:"ISO country code"`
+	}
+	type Order struct {
+		ID       string            `json:"id"`
+		Zeta     int               `json:"zeta"`
+		Alpha    float64           `json:"alpha,omitempty"`
+		Tags     map[string]string `json:"tags"`
+		Shipping *Address          `json:"shipping"`
+		Items    []Address         `json:"items"`
+		Created  time.Time         `json:"created"`
+	}
+
+	first, err := json.Marshal(GenerateJSONSchema(reflect.TypeOf(Order{})))
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		got, err := json.Marshal(GenerateJSONSchema(reflect.TypeOf(Order{})))
+		require.NoError(t, err)
+		require.Equal(t, string(first), string(got), "run %d differs", i)
+	}
+
+	// Required fields follow declaration order.
+	schema := GenerateJSONSchema(reflect.TypeOf(Order{}))
+	assert.Equal(t,
+		[]string{"id", "zeta", "tags", "items", "created"},
+		schema["required"],
+	)
+}
+
+type TestEmbedAudit struct {
+	ID        string `json:"id" yaml:"id"`
+	CreatedBy string `json:"created_by" yaml:"created_by" description:"Author"`
+}
+
+type TestEmbedRecord struct {
+	TestEmbedAudit
+	Version int `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+type TestEmbedOrder struct {
+	*TestEmbedRecord
+	ID     string `json:"id" yaml:"id"` // shadows TestEmbedAudit.ID
+	Status string `json:"status" yaml:"status"`
+}
+
+func TestGenerateJSONSchema_EmbeddedStructs(t *testing.T) {
+	result := GenerateJSONSchema(reflect.TypeOf(TestEmbedOrder{}))
+
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
+			"created_by": map[string]any{"type": "string", "description": "Author"},
+			"version":    map[string]any{"type": "integer"},
+			"status":     map[string]any{"type": "string"},
+		},
+		"required": []string{"created_by", "id", "status"},
+	}
+	assert.Equal(t, expected, result)
+}
@@ -39,16 +39,34 @@ import (
 //	        WithGuidance("Reason about the problem.")).
 //	    RegisterSection(section.NewText("plan").
 //	        WithGuidance("List your planned steps."))
+//
+// # Ephemeral Sections
+//
+// Reasoning is often only useful in the iteration that produced it. Mark the section
+// ephemeral to keep it out of the scratchpad used for later prompts:
+//
+//	thinking := section.NewText("thinking").WithEphemeral()
+//
+// See [gent.EphemeralSection] for details.
+//
+// # Repeated Sections
+//
+// If the model emits the section more than once in a response, the occurrences are
+// joined with a blank line and parsed as one. Use WithSingle to treat a repeat as a
+// parse error instead. See [gent.MultiplicitySection].
 type Text struct {
-	sectionName string
-	guidance    string
+	sectionName  string
+	guidance     string
+	ephemeral    bool
+	multiplicity gent.SectionMultiplicity
 }
 
 // NewText creates a new Text section with the given name.
 func NewText(name string) *Text {
 	return &Text{
-		sectionName: name,
-		guidance:    "",
+		sectionName:  name,
+		guidance:     "",
+		multiplicity: gent.SectionJoin,
 	}
 }
 
@@ -62,6 +80,36 @@ func (t *Text) WithGuidance(guidance string) *Text {
 	return t
 }
 
+// WithEphemeral marks this section as ephemeral. Its content stays available for the
+// current iteration but is removed from the scratchpad used to build later prompts.
+func (t *Text) WithEphemeral() *Text {
+	t.ephemeral = true
+	return t
+}
+
+// WithMultiple joins repeated occurrences of this section into one (the default).
+func (t *Text) WithMultiple() *Text {
+	t.multiplicity = gent.SectionJoin
+	return t
+}
+
+// WithSingle makes a repeated occurrence of this section a parse error.
+func (t *Text) WithSingle() *Text {
+	t.multiplicity = gent.SectionSingle
+	return t
+}
+
+// Multiplicity reports how repeated occurrences are handled.
+// Implements [gent.MultiplicitySection].
+func (t *Text) Multiplicity() gent.SectionMultiplicity {
+	return t.multiplicity
+}
+
+// Ephemeral reports whether this section is left out of later prompts.
+func (t *Text) Ephemeral() bool {
+	return t.ephemeral
+}
+
 // Name returns the section identifier.
 func (t *Text) Name() string {
 	return t.sectionName
@@ -82,5 +130,10 @@ func (t *Text) ParseSection(_ *gent.ExecutionContext, content string) (any, erro
 	return strings.TrimSpace(content), nil
 }
 
-// Compile-time check that Text implements gent.TextOutputSection.
-var _ gent.TextOutputSection = (*Text)(nil)
+// Compile-time checks that Text implements gent.TextOutputSection,
+// gent.EphemeralSection and gent.MultiplicitySection.
+var (
+	_ gent.TextOutputSection   = (*Text)(nil)
+	_ gent.EphemeralSection    = (*Text)(nil)
+	_ gent.MultiplicitySection = (*Text)(nil)
+)
@@ -278,6 +278,11 @@ func TestText_MethodChaining(t *testing.T) {
 	assert.Equal(t, "Think carefully about the problem.", section.Guidance())
 }
 
+func TestText_WithEphemeral(t *testing.T) {
+	assert.False(t, NewText("thinking").Ephemeral())
+	assert.True(t, NewText("thinking").WithEphemeral().Ephemeral())
+}
+
 func TestText_ImplementsTextOutputSection(t *testing.T) {
 	var _ gent.TextOutputSection = (*Text)(nil)
 }
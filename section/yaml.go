@@ -3,9 +3,12 @@ package section
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/internal/fields"
+	"github.com/rickchristie/gent/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -51,22 +54,32 @@ import (
 //	    Enabled  bool   `yaml:"enabled"`
 //	}
 //
+// Fields of embedded structs are promoted into the parent the way encoding/json does,
+// so the model writes them at the top level (yaml.v3 alone expects them nested under
+// the lowercased type name unless tagged `yaml:",inline"`).
+//
 // # Parse Error Handling
 //
 // When YAML parsing fails, a [gent.ParseErrorEvent] is published and the error
 // is returned. The framework tracks consecutive parse errors via
 // [gent.SGSectionParseErrorConsecutive] for limit enforcement.
+//
+// A section that appears more than once in a response is also a parse error, since it
+// parses into a single value. Use WithMultiple to parse each occurrence instead.
 type YAML[T any] struct {
-	sectionName string
-	guidance    string
-	example     *T
+	sectionName    string
+	guidance       string
+	example        *T
+	repairGuidance string
+	multiplicity   gent.SectionMultiplicity
 }
 
 // NewYAML creates a new YAML section with the given name.
 func NewYAML[T any](name string) *YAML[T] {
 	return &YAML[T]{
-		sectionName: name,
-		guidance:    "",
+		sectionName:  name,
+		guidance:     "",
+		multiplicity: gent.SectionSingle,
 	}
 }
 
@@ -82,11 +95,53 @@ func (y *YAML[T]) WithGuidance(guidance string) *YAML[T] {
 
 // WithExample sets an example value to include in the guidance.
 // The example is serialized to YAML and appended after the schema.
+//
+// Panics if the example does not conform to the schema generated from T (e.g. a nil
+// slice, which encodes as null where an array is required), so a wrong example is
+// caught when the agent is built rather than taught to the model.
 func (y *YAML[T]) WithExample(example T) *YAML[T] {
+	var zero T
+	if err := validateExample(reflect.TypeOf(zero), example); err != nil {
+		panic(fmt.Sprintf("section %q: example does not match schema: %v", y.sectionName, err))
+	}
 	y.example = &example
 	return y
 }
 
+// WithRepairGuidance sets a hint that is appended to the next observation when this
+// section fails to parse, e.g. "Remember to quote the category string.". Unlike the
+// generic YAML error message, the hint is specific to this section.
+func (y *YAML[T]) WithRepairGuidance(guidance string) *YAML[T] {
+	y.repairGuidance = guidance
+	return y
+}
+
+// RepairGuidance returns the hint set by WithRepairGuidance.
+// Implements [gent.RepairableSection].
+func (y *YAML[T]) RepairGuidance() string {
+	return y.repairGuidance
+}
+
+// WithMultiple allows the model to emit this section more than once in a response.
+// Each occurrence is parsed on its own.
+func (y *YAML[T]) WithMultiple() *YAML[T] {
+	y.multiplicity = gent.SectionRepeat
+	return y
+}
+
+// WithSingle makes a repeated occurrence of this section a parse error (the default),
+// since it parses into a single value.
+func (y *YAML[T]) WithSingle() *YAML[T] {
+	y.multiplicity = gent.SectionSingle
+	return y
+}
+
+// Multiplicity reports how repeated occurrences are handled.
+// Implements [gent.MultiplicitySection].
+func (y *YAML[T]) Multiplicity() gent.SectionMultiplicity {
+	return y.multiplicity
+}
+
 // Name returns the section identifier.
 func (y *YAML[T]) Name() string {
 	return y.sectionName
@@ -104,9 +159,7 @@ func (y *YAML[T]) Guidance() string {
 	sb.WriteString(y.sectionName)
 	sb.WriteString(" content must be valid YAML matching this schema:\n")
 
-	var zero T
-	schema := GenerateJSONSchema(reflect.TypeOf(zero))
-	schemaYAML, err := yaml.Marshal(schema)
+	schemaYAML, err := yaml.Marshal(y.jsonSchema())
 	if err == nil {
 		sb.Write(schemaYAML)
 	}
@@ -122,6 +175,19 @@ func (y *YAML[T]) Guidance() string {
 	return sb.String()
 }
 
+// Schema returns the compiled JSON Schema derived from T: the schema shown (as YAML) in
+// Guidance. Use it to document sections outside the agent, e.g. by serializing
+// Schema().Raw().
+func (y *YAML[T]) Schema() (*schema.Schema, error) {
+	return schema.Compile(y.jsonSchema())
+}
+
+// jsonSchema returns the JSON Schema derived from T.
+func (y *YAML[T]) jsonSchema() map[string]any {
+	var zero T
+	return GenerateJSONSchema(reflect.TypeOf(zero))
+}
+
 // ParseSection parses the YAML content into type T.
 func (y *YAML[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (any, error) {
 	content = strings.TrimSpace(content)
@@ -131,7 +197,7 @@ func (y *YAML[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (
 	}
 
 	var result T
-	if err := yaml.Unmarshal([]byte(content), &result); err != nil {
+	if err := decodeYAML(content, &result); err != nil {
 		parseErr := fmt.Errorf("%w: %v", gent.ErrInvalidYAML, err)
 		// Publish parse error event (auto-updates stats)
 		if execCtx != nil {
@@ -148,5 +214,119 @@ func (y *YAML[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (
 	return result, nil
 }
 
+// decodeYAML decodes content into out, first moving keys of fields promoted from
+// embedded structs to where yaml.v3 expects them.
+func decodeYAML(content string, out any) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		return err
+	}
+	nestPromotedKeys(&node, reflect.TypeOf(out))
+	return node.Decode(out)
+}
+
+// nestPromotedKeys rewrites node, which is decoded into type t, so that keys of
+// promoted fields sit in a nested mapping under the embedded field's key, where
+// yaml.v3 decodes them. Embedded structs tagged `yaml:",inline"` need no nesting.
+func nestPromotedKeys(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			nestPromotedKeys(child, t)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, child := range node.Content {
+				nestPromotedKeys(child, t.Elem())
+			}
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 1; i < len(node.Content); i += 2 {
+				nestPromotedKeys(node.Content[i], t.Elem())
+			}
+		case reflect.Struct:
+			nestStructKeys(node, t)
+		}
+	}
+}
+
+// nestStructKeys rewrites mapping node decoded into struct type t.
+func nestStructKeys(node *yaml.Node, t reflect.Type) {
+	byName := make(map[string]fields.Field)
+	for _, field := range fields.Of(t, "yaml") {
+		byName[field.Name] = field
+	}
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	nested := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		field, ok := byName[key.Value]
+		if !ok {
+			content = append(content, key, value)
+			continue
+		}
+		nestPromotedKeys(value, field.Field.Type)
+
+		path, ok := embeddedPath(t, field.Index)
+		if !ok || len(path) == 0 {
+			content = append(content, key, value)
+			continue
+		}
+
+		// Find or create the mapping for each embedded level
+		target := &content
+		for depth := range path {
+			id := strings.Join(path[:depth+1], ".")
+			mapping, exists := nested[id]
+			if !exists {
+				mapping = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				nested[id] = mapping
+				*target = append(*target,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[depth]},
+					mapping,
+				)
+			}
+			target = &mapping.Content
+		}
+		*target = append(*target, key, value)
+	}
+	node.Content = content
+}
+
+// embeddedPath returns the yaml.v3 keys of the embedded fields a promoted field at
+// index is reached through, skipping inlined ones. It returns false when an embedded
+// struct is unexported, since yaml.v3 cannot set fields through it.
+func embeddedPath(t reflect.Type, index []int) ([]string, bool) {
+	var path []string
+	for _, i := range index[:len(index)-1] {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			return nil, false
+		}
+		_, opts, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !slices.Contains(strings.Split(opts, ","), "inline") {
+			path = append(path, strings.ToLower(sf.Name))
+		}
+		t = sf.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return path, true
+}
+
 // Compile-time check that YAML implements gent.TextOutputSection.
 var _ gent.TextOutputSection = (*YAML[any])(nil)
+
+// Compile-time check that YAML implements gent.RepairableSection.
+var _ gent.RepairableSection = (*YAML[any])(nil)
+
+// Compile-time check that YAML implements gent.MultiplicitySection.
+var _ gent.MultiplicitySection = (*YAML[any])(nil)
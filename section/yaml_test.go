@@ -2,11 +2,14 @@ package section
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/rickchristie/gent"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // Test types for YAML parsing (reusing some from json_test.go)
@@ -394,6 +397,60 @@ tags: [a, b, c]`,
 	}
 }
 
+func TestYAML_ParseSection_EmbeddedStructs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected TestEmbedOrder
+	}{
+		{
+			name: "promoted fields at the top level",
+			input: `id: order-1
+created_by: alice
+version: 2
+status: open`,
+			expected: TestEmbedOrder{
+				TestEmbedRecord: &TestEmbedRecord{
+					TestEmbedAudit: TestEmbedAudit{CreatedBy: "alice"},
+					Version:        2,
+				},
+				ID:     "order-1",
+				Status: "open",
+			},
+		},
+		{
+			name: "shadowing field only",
+			input: `id: order-1
+status: open`,
+			expected: TestEmbedOrder{ID: "order-1", Status: "open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewYAML[TestEmbedOrder]("order").ParseSection(nil, tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("in sequence of structs", func(t *testing.T) {
+		input := `- id: order-1
+  created_by: bob
+  status: open`
+
+		result, err := NewYAML[[]TestEmbedOrder]("orders").ParseSection(nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, []TestEmbedOrder{{
+			TestEmbedRecord: &TestEmbedRecord{
+				TestEmbedAudit: TestEmbedAudit{CreatedBy: "bob"},
+			},
+			ID:     "order-1",
+			Status: "open",
+		}}, result)
+	})
+}
+
 func TestYAML_ParseSection_PrimitiveTypes(t *testing.T) {
 	t.Run("string type", func(t *testing.T) {
 		section := NewYAML[string]("data")
@@ -608,6 +665,89 @@ func TestYAML_MethodChaining(t *testing.T) {
 	assert.Contains(t, section.Guidance(), "example")
 }
 
+func TestYAML_WithExample(t *testing.T) {
+	type expected struct {
+		panicMsg string
+		contains []string
+	}
+
+	tests := []struct {
+		name     string
+		input    func() *YAML[YAMLStructWithMap]
+		expected expected
+	}{
+		{
+			name: "conforming example is rendered as YAML",
+			input: func() *YAML[YAMLStructWithMap] {
+				return NewYAML[YAMLStructWithMap]("meta").WithExample(YAMLStructWithMap{
+					Metadata: map[string]string{"owner": "alice"},
+				})
+			},
+			expected: expected{
+				contains: []string{"Example:\nmetadata:\n    owner: alice\n"},
+			},
+		},
+		{
+			name: "nil map panics",
+			input: func() *YAML[YAMLStructWithMap] {
+				return NewYAML[YAMLStructWithMap]("meta").WithExample(YAMLStructWithMap{})
+			},
+			expected: expected{
+				panicMsg: `section "meta": example does not match schema`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expected.panicMsg != "" {
+				defer func() {
+					r := recover()
+					require.NotNil(t, r, "expected panic")
+					assert.Contains(t, r, tc.expected.panicMsg)
+				}()
+			}
+
+			guidance := tc.input().Guidance()
+
+			for _, want := range tc.expected.contains {
+				assert.Contains(t, guidance, want)
+			}
+		})
+	}
+}
+
+func TestYAML_WithRepairGuidance(t *testing.T) {
+	section := NewYAML[YAMLSimpleStruct]("plan")
+	assert.Equal(t, "", section.RepairGuidance())
+
+	section.WithRepairGuidance("Quote every string value.")
+	assert.Equal(t, "Quote every string value.", section.RepairGuidance())
+	assert.NotContains(t, section.Guidance(), "Quote every string value.",
+		"repair guidance must only appear after a parse failure")
+}
+
+func TestYAML_Schema(t *testing.T) {
+	section := NewYAML[StructWithDescription]("result")
+
+	compiled, err := section.Schema()
+	require.NoError(t, err)
+
+	// Same schema as the one shown to the model
+	_, rendered, found := strings.Cut(section.Guidance(), "matching this schema:\n")
+	require.True(t, found)
+	var renderedSchema map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(rendered), &renderedSchema))
+	expectedJSON, err := json.Marshal(compiled.Raw())
+	require.NoError(t, err)
+	renderedJSON, err := json.Marshal(renderedSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(renderedJSON))
+
+	assert.NoError(t, compiled.Validate(map[string]any{"field1": "a", "field2": 1}))
+	assert.Error(t, compiled.Validate(map[string]any{"field1": "a"}))
+}
+
 func TestYAML_ImplementsTextOutputSection(t *testing.T) {
 	var _ gent.TextOutputSection = (*YAML[any])(nil)
 }
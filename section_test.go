@@ -0,0 +1,99 @@
+package gent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// plainSection does not implement MultiplicitySection.
+type plainSection struct{}
+
+func (s *plainSection) Name() string     { return "plan" }
+func (s *plainSection) Guidance() string { return "" }
+
+func (s *plainSection) ParseSection(_ *ExecutionContext, content string) (any, error) {
+	return content, nil
+}
+
+type stubSection struct {
+	plainSection
+	multiplicity SectionMultiplicity
+}
+
+func (s *stubSection) Multiplicity() SectionMultiplicity { return s.multiplicity }
+
+func TestSectionContents(t *testing.T) {
+	type input struct {
+		section  TextSection
+		contents []string
+	}
+
+	type expected struct {
+		contents []string
+		err      string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "repeat keeps each occurrence",
+			input: input{
+				section:  &stubSection{multiplicity: SectionRepeat},
+				contents: []string{"a", "b"},
+			},
+			expected: expected{contents: []string{"a", "b"}},
+		},
+		{
+			name: "join concatenates occurrences",
+			input: input{
+				section:  &stubSection{multiplicity: SectionJoin},
+				contents: []string{"a", "b"},
+			},
+			expected: expected{contents: []string{"a\n\nb"}},
+		},
+		{
+			name: "single rejects repeats",
+			input: input{
+				section:  &stubSection{multiplicity: SectionSingle},
+				contents: []string{"a", "b", "c"},
+			},
+			expected: expected{
+				err: "section appears more than once: " +
+					"section \"plan\" appears 3 times, expected exactly one",
+			},
+		},
+		{
+			name: "single accepts one occurrence",
+			input: input{
+				section:  &stubSection{multiplicity: SectionSingle},
+				contents: []string{"a"},
+			},
+			expected: expected{contents: []string{"a"}},
+		},
+		{
+			name: "sections without multiplicity repeat",
+			input: input{
+				section:  &plainSection{},
+				contents: []string{"a", "b"},
+			},
+			expected: expected{contents: []string{"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := SectionContents(tt.input.section, tt.input.contents)
+			if tt.expected.err != "" {
+				assert.ErrorIs(t, err, ErrDuplicateSection)
+				assert.EqualError(t, err, tt.expected.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected.contents, contents)
+		})
+	}
+}
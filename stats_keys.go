@@ -130,6 +130,18 @@ const (
 	SCToolCallsFor StatKey = "gent:tool_calls:" // + tool name
 )
 
+// Distinct tool tracking key (Gauge).
+//
+// Auto-updated when BeforeToolCallEvent is published. Holds the
+// number of unique tool names invoked in this context and all of
+// its children, so calling the same tool again leaves it unchanged.
+// Unlike other gauges, tool names used by children are merged into
+// every ancestor. Use to stop a run that reaches for too broad a
+// toolset:
+//
+//	{Type: LimitExactKey, Key: SGDistinctToolsUsed, MaxValue: 8}
+const SGDistinctToolsUsed StatKey = "gent:distinct_tools_used"
+
 // Tool call error tracking keys.
 //
 // Auto-updated when AfterToolCallEvent with Error is published.
@@ -148,21 +160,108 @@ const (
 	SGToolCallsErrorConsecutiveFor StatKey = "gent:tool_calls_error_consecutive:" // + tool
 )
 
+// Reflection pass tracking keys (Counters).
+//
+// Auto-updated by AgentLoops when a [ReflectionPass] runs: SCReflectionCalls counts the
+// passes, and the token keys hold the tokens of their model calls. These tokens are
+// also included in SCInputTokens and SCOutputTokens like any model call.
+const (
+	SCReflectionCalls        StatKey = "gent:reflection_calls"
+	SCReflectionInputTokens  StatKey = "gent:reflection_input_tokens"
+	SCReflectionOutputTokens StatKey = "gent:reflection_output_tokens"
+)
+
+// Unknown and unavailable tool call keys (Counters).
+//
+// Auto-updated when AfterToolCallEvent with Error is published, in addition to the
+// tool call error keys. SCToolCallsUnknown counts calls failing with ErrUnknownTool
+// (tools that are not registered). Calls answered by a toolchain's unknown tool handler
+// are counted by the toolchain in SCToolCallsUnknown only. SCToolCallsUnavailable counts
+// calls failing with ErrToolUnavailable (registered tools excluded by the current tool
+// filter, when the toolchain has an unavailable tool message set).
+const (
+	SCToolCallsUnknown     StatKey = "gent:tool_calls_unknown"
+	SCToolCallsUnavailable StatKey = "gent:tool_calls_unavailable"
+)
+
+// Repeated tool call tracking keys (Counters).
+//
+// Auto-updated when RepeatedToolCallEvent is published, i.e. when a
+// RepeatedToolCallPolicy detects the same call made too many times in
+// a row. Use to cap how often an agent may get stuck:
+//
+//	{Type: LimitExactKey, Key: SCRepeatedToolCalls, MaxValue: 3}
+const (
+	SCRepeatedToolCalls    StatKey = "gent:repeated_tool_calls"
+	SCRepeatedToolCallsFor StatKey = "gent:repeated_tool_calls:" // + tool name
+)
+
 // Format parse error tracking keys.
 //
 // Auto-updated when ParseErrorEvent with ErrorType="format" is
 // published. These track errors when the TextFormat fails to parse
 // LLM output structure.
 //
+// SGFormatParseErrorIdenticalConsecutive counts only a run of failing
+// outputs that are byte-identical to each other: it is incremented when
+// the raw output equals the previous failing output and restarts at 1
+// when it differs. A model stuck repeating the same malformed output is
+// less likely to recover than one making varied repair attempts, so a
+// low limit on it bails out early while a higher consecutive limit
+// tolerates varied mistakes:
+//
+//	{Type: LimitExactKey, Key: SGFormatParseErrorIdenticalConsecutive, MaxValue: 1}
+//
+// Both gauges reset to 0 on a successful parse.
+//
 // Default limit: 3 consecutive errors (see DefaultLimits).
 const (
 	// Counters
 	SCFormatParseErrorTotal StatKey = "gent:format_parse_error_total"
 
 	// Gauges
-	SGFormatParseErrorConsecutive StatKey = "gent:format_parse_error_consecutive"
+	SGFormatParseErrorConsecutive          StatKey = "gent:format_parse_error_consecutive"
+	SGFormatParseErrorIdenticalConsecutive StatKey = "gent:format_parse_error_identical_consecutive"
 )
 
+// Format fallback tracking key (Counter).
+//
+// Auto-updated when FormatFallbackEvent is published, i.e. when a format
+// created with format.NewAuto parsed output with a fallback format because
+// the model ignored the primary format's instructions.
+const SCFormatFallbacks StatKey = "gent:format_fallbacks"
+
+// Discarded answer tracking key (Counter).
+//
+// Auto-updated when AnswerDiscardedEvent is published, i.e. when a
+// response contained both actions and an answer and the answer was
+// discarded because actions take priority.
+const SCAnswersDiscarded StatKey = "gent:answers_discarded"
+
+// Empty response tracking keys.
+//
+// Auto-updated by agent loops when the model returns an empty or
+// whitespace-only response. Empty responses are handled separately from
+// format parse errors: they are not parsed and do not count towards
+// SCFormatParseErrorTotal.
+//
+// Default limits: 3 consecutive and 10 total empty responses (see DefaultLimits).
+const (
+	// Counters
+	SCEmptyResponseTotal StatKey = "gent:empty_response_total"
+
+	// Gauges (reset on a non-empty response)
+	SGEmptyResponseConsecutive StatKey = "gent:empty_response_consecutive"
+)
+
+// No-progress tracking key (Gauge).
+//
+// Updated by the executor after each iteration that continues the loop,
+// when executor.Config.ProgressFunc is set: incremented when the function
+// reports no progress, reset when it reports progress. Add
+// NoProgressLimit to the limits to stop an agent that is stuck.
+const SGNoProgressConsecutive StatKey = "gent:no_progress_consecutive"
+
 // Toolchain parse error tracking keys.
 //
 // Auto-updated when ParseErrorEvent with ErrorType="toolchain" is
@@ -184,10 +283,14 @@ const (
 // published. These track errors when the Termination fails to parse
 // answer content.
 //
+// Agents that route several answer sections to terminations (see the ReAct agent's
+// WithTerminations) also increment SCTerminationParseErrorFor + termination name.
+//
 // Default limit: 3 consecutive errors (see DefaultLimits).
 const (
 	// Counters
 	SCTerminationParseErrorTotal StatKey = "gent:termination_parse_error_total"
+	SCTerminationParseErrorFor   StatKey = "gent:termination_parse_error:" // + termination name
 
 	// Gauges
 	SGTerminationParseErrorConsecutive StatKey = "gent:termination_parse_error_consecutive"
@@ -208,17 +311,42 @@ const (
 	SGSectionParseErrorConsecutive StatKey = "gent:section_parse_error_consecutive"
 )
 
+// Section size tracking keys (Gauges).
+//
+// Auto-updated each time a TextFormat parses a model response (see
+// ExecutionContext.RecordSectionBytes). SGSectionBytesFor + section
+// name holds the byte length of that section's content in the latest
+// response, summed over repeated occurrences, and 0 if the section
+// was absent. Use to stop a runaway section, such as an enormous
+// thinking section, independently of output token limits:
+//
+//	{Type: LimitExactKey, Key: SGSectionBytesFor + "thinking", MaxValue: 8000}
+const SGSectionBytesFor StatKey = "gent:section_bytes:" // + section name
+
 // Scratchpad length tracking key (Gauge).
 //
-// Auto-updated when BasicLoopData.SetScratchPad is called.
-// Reflects the current number of iterations in the scratchpad.
-// Use for limits to prevent unbounded scratchpad growth.
+// Reflects the number of iterations currently retained in the
+// scratchpad, i.e. after compaction, not the length of the full
+// iteration history. Auto-updated when BasicLoopData.SetScratchPad
+// is called, after every compaction (CompactionEvent) and by the
+// executor after each iteration, so it is current for any LoopData.
+// Use for limits to prevent unbounded scratchpad growth, or as a
+// compaction.StatThresholdTrigger gauge.
 //
 // Example limit:
 //
 //	{Type: LimitExactKey, Key: SGScratchpadLength, MaxValue: 50}
 const SGScratchpadLength StatKey = "gent:scratchpad_length"
 
+// Scratchpad size tracking key (Gauge).
+//
+// Holds the byte length of the text in the scratchpad's messages (see
+// ScratchpadBytes), a crude but tokenizer-free measure of its size.
+// Updated wherever SGScratchpadLength is. Use ScratchpadBytesLimit to
+// compact or terminate once it grows too large, or as a
+// compaction.StatThresholdTrigger gauge.
+const SGScratchpadBytes StatKey = "gent:scratchpad_bytes"
+
 // Last-iteration token tracking keys (Gauge).
 //
 // These gauges track token usage for the current/last iteration
@@ -290,10 +418,28 @@ const (
 //	// Stop if ANY validator rejects 3 times
 //	{Type: LimitKeyPrefix, Key: SCAnswerRejectedBy, MaxValue: 3}
 //
+// Exact per-validator limits coexist with the total and prefix limits;
+// each applies independently (see [Limit] for precedence).
+//
+// Agents that route several answer sections to terminations also increment
+// SCAnswerRejectedFor + termination name, so limits can target one kind of answer.
+//
 // Default limit: 10 total rejections (see DefaultLimits).
 const (
 	SCAnswerRejectedTotal StatKey = "gent:answer_rejected_total"
 	SCAnswerRejectedBy    StatKey = "gent:answer_rejected_by:" // + validator name
+	SCAnswerRejectedFor   StatKey = "gent:answer_rejected:"    // + termination name
+)
+
+// Validator score tracking keys (Gauge).
+//
+// Auto-updated when a ValidatorResultEvent carrying a score is published,
+// i.e. by terminations with a minimum validator score (see
+// ValidationResult.Score). SGValidatorScoreLast holds the latest score and
+// SGValidatorScoreAvg the mean of all scores recorded in this context.
+const (
+	SGValidatorScoreLast StatKey = "gent:validator_score_last"
+	SGValidatorScoreAvg  StatKey = "gent:validator_score_avg"
 )
 
 // Code execution tracking keys (Programmatic Tool Calling).
@@ -324,6 +470,45 @@ const (
 // total compactions across the entire agent tree.
 const SCCompactions StatKey = "gent:compactions"
 
+// Compaction savings key (Counter).
+//
+// Auto-updated when CompactionEvent is published, by the
+// estimated scratchpad tokens removed by the compaction
+// (EstimatedTokensBefore - EstimatedTokensAfter, when
+// positive). Estimates come from the context's
+// TokenEstimator, so they measure prompt size saved on each
+// later model call, not exact provider token counts.
+//
+// Propagates to parent.
+const SCCompactionTokensSaved StatKey = "gent:compaction_tokens_saved"
+
+// Prompt caching keys (Counter).
+//
+// Auto-updated when AfterModelCallEvent is published, from
+// GenerationInfo.CachedInputTokens. Stays zero for providers
+// that do not report cached tokens. Cached tokens are also
+// counted in SCInputTokens.
+//
+// Propagates to parent.
+const (
+	SCCachedPromptTokens    StatKey = "gent:cached_prompt_tokens"
+	SCCachedPromptTokensFor StatKey = "gent:cached_prompt_tokens:" // + model name
+)
+
+// Reasoning token keys (Counter).
+//
+// Auto-updated when AfterModelCallEvent is published, from
+// GenerationInfo.ReasoningTokens. Stays zero for models that
+// do not reason natively or whose adapter does not report it.
+// Providers bill reasoning as output, so these tokens are
+// typically also counted in SCOutputTokens.
+//
+// Propagates to parent.
+const (
+	SCReasoningTokens    StatKey = "gent:reasoning_tokens"
+	SCReasoningTokensFor StatKey = "gent:reasoning_tokens:" // + model name
+)
+
 // protectedKeys contains keys that cannot be modified by user code
 // via IncrCounter. Protected keys can still be incremented internally
 // by the framework (e.g., the executor increments SCIterations).
@@ -0,0 +1,111 @@
+package gent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// StreamTokenMeter counts the output tokens of a streaming model call while its chunks
+// arrive, so output-token limits are enforced mid-stream instead of only once the full
+// response is in.
+//
+// Each Add estimates the tokens streamed so far with the context's TokenEstimator and
+// adds the increase to SCOutputTokens and SCTotalTokens (and their per-model keys). The
+// text is estimated in windows of about 1 KiB, each on its own, so metering stays linear
+// in the response size.
+// Limits are checked right away: when one is exceeded the context is cancelled, which
+// aborts the model request, and Add returns the context's error so the streaming
+// callback can stop. Publish the call's AfterModelCallEvent through
+// [StreamTokenMeter.PublishAfterModelCall], so the usage reported by the provider only
+// adds what the estimate missed. Counters never go down, so an estimate above the
+// reported usage stays counted.
+//
+// Streaming models create one meter per call:
+//
+//	meter := execCtx.NewStreamTokenMeter(modelName)
+//	// in the streaming callback:
+//	if err := meter.Add(chunk); err != nil {
+//	    return err // limit exceeded or cancelled: stop streaming
+//	}
+//	// once the call returns:
+//	meter.PublishAfterModelCall(request, response, duration, err)
+type StreamTokenMeter struct {
+	execCtx *ExecutionContext
+	model   string
+
+	mu        sync.Mutex
+	window    strings.Builder // text streamed since the last full window
+	estimated int             // estimated tokens of the full windows
+	counted   int
+}
+
+// streamEstimateWindow is the size in bytes from which a StreamTokenMeter stops
+// re-estimating the text of its current window and starts a new one.
+const streamEstimateWindow = 1024
+
+// NewStreamTokenMeter creates a meter for one streaming call to model.
+func (ctx *ExecutionContext) NewStreamTokenMeter(model string) *StreamTokenMeter {
+	return &StreamTokenMeter{execCtx: ctx, model: model}
+}
+
+// Add records a streamed content or reasoning chunk and counts the tokens it adds. It
+// returns the context's error once the context is cancelled, e.g. by an exceeded limit.
+func (m *StreamTokenMeter) Add(chunk string) error {
+	m.mu.Lock()
+	m.window.WriteString(chunk)
+	estimate := m.estimated + m.execCtx.TokenEstimator().EstimateTokens([]*Iteration{{
+		Messages: []*MessageContent{{
+			Role:  llms.ChatMessageTypeAI,
+			Parts: []ContentPart{llms.TextContent{Text: m.window.String()}},
+		}},
+	}})
+	// Windows end on chunk boundaries, so a cut never splits a character
+	if m.window.Len() >= streamEstimateWindow {
+		m.estimated = estimate
+		m.window.Reset()
+	}
+	delta := estimate - m.counted
+	if delta > 0 {
+		m.counted = estimate
+	}
+	m.mu.Unlock()
+
+	if delta > 0 {
+		m.execCtx.recordStreamedOutputTokens(m.model, int64(delta))
+	}
+	return m.execCtx.Context().Err()
+}
+
+// Counted returns the output tokens counted so far.
+func (m *StreamTokenMeter) Counted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counted
+}
+
+// PublishAfterModelCall publishes the AfterModelCallEvent of the metered call, like
+// [ExecutionContext.PublishAfterModelCall], without counting the streamed output tokens
+// again.
+func (m *StreamTokenMeter) PublishAfterModelCall(
+	request any,
+	response *ContentResponse,
+	duration time.Duration,
+	err error,
+) *AfterModelCallEvent {
+	return m.execCtx.publishAfterModelCall(
+		m.model, request, response, duration, err, m.Counted(),
+	)
+}
+
+// recordStreamedOutputTokens counts output tokens of a response that is still streaming.
+func (ctx *ExecutionContext) recordStreamedOutputTokens(model string, tokens int64) {
+	ctx.stats.incrCounterDirect(SCOutputTokens, tokens)
+	ctx.stats.incrCounterDirect(SCTotalTokens, tokens)
+	if model != "" {
+		ctx.stats.incrCounterDirect(SCOutputTokensFor+StatKey(model), tokens)
+		ctx.stats.incrCounterDirect(SCTotalTokensFor+StatKey(model), tokens)
+	}
+}
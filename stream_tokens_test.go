@@ -0,0 +1,69 @@
+package gent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// longestTextEstimator is a CharTokenEstimator that records the longest text it was
+// asked to estimate.
+type longestTextEstimator struct {
+	CharTokenEstimator
+	longest int
+}
+
+func (e *longestTextEstimator) EstimateTokens(scratchpad []*Iteration) int {
+	e.longest = max(e.longest, ScratchpadBytes(scratchpad))
+	return e.CharTokenEstimator.EstimateTokens(scratchpad)
+}
+
+func TestStreamTokenMeter_Add(t *testing.T) {
+	type input struct {
+		chunk  string
+		chunks int
+	}
+
+	type expected struct {
+		counted      int
+		outputTokens int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "short stream is estimated as a whole",
+			input:    input{chunk: "ab", chunks: 5},
+			expected: expected{counted: 3, outputTokens: 3},
+		},
+		{
+			name:     "long stream is estimated window by window",
+			input:    input{chunk: "abcd", chunks: 1000},
+			expected: expected{counted: 1000, outputTokens: 1000},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			estimator := &longestTextEstimator{}
+			execCtx := NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetTokenEstimator(estimator)
+			meter := execCtx.NewStreamTokenMeter("model")
+
+			for range tc.input.chunks {
+				assert.NoError(t, meter.Add(tc.input.chunk))
+			}
+
+			assert.Equal(t, tc.expected.counted, meter.Counted())
+			assert.Equal(t, tc.expected.outputTokens, execCtx.Stats().GetCounter(SCOutputTokens))
+			assert.LessOrEqual(t, estimator.longest, streamEstimateWindow+len(tc.input.chunk))
+			assert.LessOrEqual(t, estimator.longest,
+				len(strings.Repeat(tc.input.chunk, tc.input.chunks)))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package gent
+
+import "github.com/tmc/langchaingo/llms"
+
+// MetadataKeyStructuredOutputSchema is the [llms.CallOptions.Metadata] key under which
+// [WithStructuredOutput] stores the JSON Schema the response must conform to.
+//
+// Model adapters that can pass a full JSON Schema to the provider (e.g., Ollama's
+// "format" parameter) should read the schema from this key via [StructuredOutputSchema].
+const MetadataKeyStructuredOutputSchema = "gent_structured_output_schema"
+
+// StructuredOutputModel is an optional capability interface for models that support
+// provider-side constrained decoding (a.k.a. structured output or JSON mode).
+//
+// Local runtimes such as Ollama can constrain generation to a JSON Schema, which
+// drastically reduces parse errors for smaller models. Agent loops check for this
+// interface before attaching [WithStructuredOutput] to a model call.
+//
+// When a model does not implement this interface, or SupportsStructuredOutput returns
+// false, agents fall back to describing the schema in the prompt only.
+//
+// # Implementing
+//
+//	type MyModel struct { ... }
+//
+//	func (m *MyModel) SupportsStructuredOutput() bool { return true }
+//
+//	func (m *MyModel) GenerateContent(execCtx *gent.ExecutionContext, ...,
+//	    options ...llms.CallOption) (*gent.ContentResponse, error) {
+//	    opts := llms.CallOptions{}
+//	    for _, opt := range options {
+//	        opt(&opts)
+//	    }
+//	    if schema := gent.StructuredOutputSchema(opts); schema != nil {
+//	        // pass schema to the provider's structured output parameter
+//	    }
+//	    ...
+//	}
+type StructuredOutputModel interface {
+	Model
+
+	// SupportsStructuredOutput reports whether the model honors [WithStructuredOutput].
+	SupportsStructuredOutput() bool
+}
+
+// StructuredOutputFormat is an optional extension of [TextFormat] for formats whose
+// whole output can be described by a single JSON Schema.
+//
+// Agents pass OutputSchema to models implementing [StructuredOutputModel] so the
+// provider constrains decoding to the expected envelope.
+type StructuredOutputFormat interface {
+	TextFormat
+
+	// OutputSchema returns the JSON Schema of the complete model output, built from
+	// the registered sections. Returns nil if no sections are registered.
+	OutputSchema() map[string]any
+}
+
+// SchemaSection is an optional extension of [TextSection] for sections whose content
+// is JSON that can be described by a JSON Schema.
+//
+// [StructuredOutputFormat] implementations use this to embed each section's schema in
+// the output schema. Sections that don't implement it are treated as free-form strings.
+type SchemaSection interface {
+	TextSection
+
+	// JSONSchema returns the JSON Schema describing this section's content.
+	JSONSchema() map[string]any
+}
+
+// WithStructuredOutput returns an [llms.CallOption] that requests constrained decoding
+// against the given JSON Schema.
+//
+// The option enables [llms.CallOptions.JSONMode], which LangChainGo providers map to
+// their JSON output mode (e.g., Ollama's `format: json`), and stores the schema under
+// [MetadataKeyStructuredOutputSchema] for adapters that support full schemas.
+func WithStructuredOutput(schema map[string]any) llms.CallOption {
+	return func(o *llms.CallOptions) {
+		o.JSONMode = true
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]any)
+		}
+		o.Metadata[MetadataKeyStructuredOutputSchema] = schema
+	}
+}
+
+// StructuredOutputSchema returns the schema set by [WithStructuredOutput], or nil if
+// structured output was not requested.
+func StructuredOutputSchema(opts llms.CallOptions) map[string]any {
+	if opts.Metadata == nil {
+		return nil
+	}
+	schema, _ := opts.Metadata[MetadataKeyStructuredOutputSchema].(map[string]any)
+	return schema
+}
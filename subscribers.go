@@ -71,11 +71,26 @@ type AfterToolCallSubscriber interface {
 	OnAfterToolCall(execCtx *ExecutionContext, event *AfterToolCallEvent)
 }
 
+// RepeatedToolCallSubscriber receives RepeatedToolCallEvent events.
+type RepeatedToolCallSubscriber interface {
+	OnRepeatedToolCall(execCtx *ExecutionContext, event *RepeatedToolCallEvent)
+}
+
+// ToolProgressSubscriber receives ToolProgressEvent events.
+type ToolProgressSubscriber interface {
+	OnToolProgress(execCtx *ExecutionContext, event *ToolProgressEvent)
+}
+
 // ParseErrorSubscriber receives ParseErrorEvent events.
 type ParseErrorSubscriber interface {
 	OnParseError(execCtx *ExecutionContext, event *ParseErrorEvent)
 }
 
+// FormatFallbackSubscriber receives FormatFallbackEvent events.
+type FormatFallbackSubscriber interface {
+	OnFormatFallback(execCtx *ExecutionContext, event *FormatFallbackEvent)
+}
+
 // ValidatorCalledSubscriber receives ValidatorCalledEvent events.
 type ValidatorCalledSubscriber interface {
 	OnValidatorCalled(execCtx *ExecutionContext, event *ValidatorCalledEvent)
@@ -97,6 +112,17 @@ type CommonEventSubscriber interface {
 	OnCommonEvent(execCtx *ExecutionContext, event *CommonEvent)
 }
 
+// HandoffSubscriber receives HandoffEvent events.
+type HandoffSubscriber interface {
+	OnHandoff(execCtx *ExecutionContext, event *HandoffEvent)
+}
+
+// AnswerDiscardedSubscriber receives AnswerDiscardedEvent events.
+// Use it to measure how often the model answers prematurely.
+type AnswerDiscardedSubscriber interface {
+	OnAnswerDiscarded(execCtx *ExecutionContext, event *AnswerDiscardedEvent)
+}
+
 // CompactionSubscriber receives CompactionEvent events.
 // This is useful for observing scratchpad compaction in real time.
 type CompactionSubscriber interface {
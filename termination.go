@@ -16,6 +16,12 @@ const (
 	// TerminationAnswerAccepted indicates an answer was found and accepted.
 	// The content is the final parsed and validated answer.
 	TerminationAnswerAccepted
+
+	// TerminationAnswerContinued indicates an answer was found and the validator asked
+	// for another iteration without rejecting it (see
+	// [ValidationResult.ContinueWithoutRejection]). The guidance content should be added
+	// to the scratchpad like rejection feedback, but rejection stats are not updated.
+	TerminationAnswerContinued
 )
 
 // TerminationResult contains the result of a termination check.
@@ -25,9 +31,28 @@ type TerminationResult struct {
 
 	// Content varies by status:
 	//   - AnswerRejected: Feedback sections to display to the LLM
+	//   - AnswerContinued: Guidance sections to display to the LLM
 	//   - AnswerAccepted: The final parsed answer (typically as TextContent)
 	//   - Continue: Typically nil
 	Content []ContentPart
+
+	// Candidates is set on AnswerAccepted by terminations that take several candidate
+	// answers in one step (see termination.Candidates): every candidate in order, with
+	// its validation result. Content then holds one TextContent per candidate.
+	Candidates []AnswerCandidate
+}
+
+// AnswerCandidate is one of several candidate answers given in a single terminating
+// step, with the result of validating it.
+type AnswerCandidate struct {
+	// Answer is the candidate's text.
+	Answer string
+
+	// Accepted is true if the candidate passed validation, or no validator is set.
+	Accepted bool
+
+	// Feedback is the validator's feedback for a candidate that was not accepted.
+	Feedback []FormattedSection
 }
 
 // AnswerValidator validates the parsed answer content before acceptance.
@@ -105,6 +130,31 @@ type ValidationResult struct {
 	//       {Name: "hint", Content: "Use lookup_order to find the tracking number."},
 	//   }
 	Feedback []FormattedSection
+
+	// ContinueWithoutRejection, when Accepted is false, asks for one more iteration
+	// instead of rejecting the answer: Feedback is shown to the LLM as guidance, but
+	// [SCAnswerRejectedTotal] and [SCAnswerRejectedBy] are not incremented. Use it when
+	// the answer is good progress that needs refining, so rejection limits only count
+	// bad answers.
+	//
+	// Example:
+	//   &ValidationResult{
+	//       ContinueWithoutRejection: true,
+	//       Feedback: []FormattedSection{
+	//           {Name: "guidance", Content: "Almost done. Add the delivery date."},
+	//       },
+	//   }
+	ContinueWithoutRejection bool
+
+	// Score is an optional confidence score for graded acceptance, on a scale the
+	// validator and termination agree on (e.g., 0 to 1). Terminations configured with a
+	// minimum score (e.g., termination.Text's WithMinScore) reject answers scoring below
+	// it even when Accepted is true, telling the LLM the score, and record it in
+	// [SGValidatorScoreLast] and [SGValidatorScoreAvg]. Other terminations ignore it.
+	//
+	// Example:
+	//   &ValidationResult{Accepted: true, Score: 0.64}
+	Score float64
 }
 
 // Termination is a [TextSection] that signals when the agent should stop.
@@ -177,6 +227,10 @@ type ValidationResult struct {
 //
 //   - [ExecutionContext.PublishValidatorCalled]: Before calling validator
 //   - [ExecutionContext.PublishValidatorResult]: After validator returns (with accepted=true/false)
+//   - [ExecutionContext.PublishValidatorContinue]: Instead of PublishValidatorResult when the
+//     result has ContinueWithoutRejection set
+//   - [ExecutionContext.PublishScoredValidatorResult]: Instead of PublishValidatorResult when
+//     the termination enforces a minimum score (see [ValidationResult.Score])
 //
 // Stats are automatically updated when publishing ValidatorResultEvent.
 //
@@ -196,6 +250,7 @@ type Termination interface {
 	//   - TerminationContinue: Empty/invalid content, continue iterating
 	//   - TerminationAnswerRejected: Valid content but validator rejected it
 	//   - TerminationAnswerAccepted: Valid content, stop iterating
+	//   - TerminationAnswerContinued: Valid content, validator asked for another iteration
 	//
 	// Panics if execCtx is nil.
 	ShouldTerminate(execCtx *ExecutionContext, content string) *TerminationResult
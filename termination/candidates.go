@@ -0,0 +1,242 @@
+package termination
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rickchristie/gent"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Candidates implements [gent.Termination] for several candidate answers given in a
+// single terminating step, e.g. for offline evaluation where an external judge picks the
+// best one. The reasoning and tool calls leading to the answer are shared; only the final
+// answer branches.
+//
+// # Creating and Configuring
+//
+//	// Ask for 3 candidate answers in the "answer" section
+//	term := termination.NewCandidates("answer", 3)
+//
+// # Expected Model Output
+//
+// The section holds a JSON array of exactly n strings:
+//
+//	<answer>
+//	["It's sunny in Tokyo.", "Tokyo is sunny today, 24°C.", "Sunny, with a light breeze."]
+//	</answer>
+//
+// Content that is not such an array is a termination parse error, fed back to the model.
+//
+// # Validation
+//
+// A validator set with SetValidator runs on each candidate (as a string), publishing
+// ValidatorCalledEvent and ValidatorResultEvent for each one, so every rejected
+// candidate counts toward [gent.SCAnswerRejectedTotal]. The step is accepted if at
+// least one candidate passes; if none does, it is rejected with the feedback of every
+// candidate. A validator asking to continue without rejection counts as not passing.
+//
+// # Reading the Candidates
+//
+// When accepted, the final result holds one TextContent per candidate, in order, and
+// [gent.ExecutionContext.AnswerCandidates] (or [gent.ExecutionResult].Candidates) reports
+// each candidate with its validation result:
+//
+//	exec.Execute(execCtx)
+//	for _, candidate := range execCtx.AnswerCandidates() {
+//	    if candidate.Accepted {
+//	        judge.Consider(candidate.Answer)
+//	    }
+//	}
+type Candidates struct {
+	sectionName string
+	n           int
+	guidance    string
+	validator   gent.AnswerValidator
+	minScore    minScore
+}
+
+// NewCandidates creates a Candidates termination with the given section name, taking n
+// candidate answers. Panics if n is less than 1.
+func NewCandidates(name string, n int) *Candidates {
+	if n < 1 {
+		panic(fmt.Sprintf("termination: NewCandidates requires at least 1 candidate, got %d",
+			n))
+	}
+	return &Candidates{
+		sectionName: name,
+		n:           n,
+		guidance:    "Write your final answer here.",
+	}
+}
+
+// WithGuidance sets the guidance text for this termination. The instructions for the
+// JSON array of candidates are appended to it.
+func (t *Candidates) WithGuidance(guidance string) *Candidates {
+	t.guidance = guidance
+	return t
+}
+
+// WithMinScore makes the termination reject candidates whose validator score (see
+// [gent.ValidationResult.Score]) is below min, even if the validator accepted them; see
+// [Text.WithMinScore].
+func (t *Candidates) WithMinScore(min float64) *Candidates {
+	t.minScore = minScore{value: min, enabled: true}
+	return t
+}
+
+// Name returns the section identifier.
+func (t *Candidates) Name() string {
+	return t.sectionName
+}
+
+// Guidance returns the guidance text followed by the candidate array instructions.
+func (t *Candidates) Guidance() string {
+	var sb strings.Builder
+	if t.guidance != "" {
+		sb.WriteString(t.guidance)
+		sb.WriteString("\n\n")
+	}
+	fmt.Fprintf(&sb, "Give %d different candidate answers as a JSON array of %d strings:\n",
+		t.n, t.n)
+	sb.WriteString(`["first candidate", "second candidate", ...]`)
+	return sb.String()
+}
+
+// ParseSection parses the content into a []string of exactly n candidates.
+func (t *Candidates) ParseSection(execCtx *gent.ExecutionContext, content string) (any, error) {
+	candidates, err := t.parse(content)
+	if err != nil {
+		// Publish parse error event (auto-updates stats)
+		if execCtx != nil {
+			execCtx.PublishParseError(gent.ParseErrorTypeTermination, content, err)
+		}
+		return nil, err
+	}
+
+	// Successful parse - reset consecutive error gauge
+	if execCtx != nil {
+		execCtx.Stats().ResetGauge(gent.SGTerminationParseErrorConsecutive)
+	}
+
+	return candidates, nil
+}
+
+// parse decodes content into exactly n non-blank candidates.
+func (t *Candidates) parse(content string) ([]string, error) {
+	var candidates []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &candidates); err != nil {
+		return nil, fmt.Errorf("%w: %v", gent.ErrInvalidJSON, err)
+	}
+	if len(candidates) != t.n {
+		return nil, fmt.Errorf("%w: expected %d candidates, got %d",
+			gent.ErrInvalidJSON, t.n, len(candidates))
+	}
+	for i, candidate := range candidates {
+		candidates[i] = strings.TrimSpace(candidate)
+		if candidates[i] == "" {
+			return nil, fmt.Errorf("%w: candidate %d is empty", gent.ErrInvalidJSON, i+1)
+		}
+	}
+	return candidates, nil
+}
+
+// SetValidator sets the validator to run on each candidate before acceptance.
+func (t *Candidates) SetValidator(validator gent.AnswerValidator) {
+	t.validator = validator
+}
+
+// ShouldTerminate validates each candidate. It returns [gent.TerminationAnswerAccepted]
+// with every candidate if at least one passes, and [gent.TerminationAnswerRejected]
+// with the feedback of each candidate otherwise, or [gent.TerminationAnswerContinued]
+// if the validator asked for another iteration for every candidate without rejecting
+// any. Blank or unparsable content returns [gent.TerminationContinue]. Panics if execCtx
+// is nil.
+func (t *Candidates) ShouldTerminate(
+	execCtx *gent.ExecutionContext,
+	content string,
+) *gent.TerminationResult {
+	if execCtx == nil {
+		panic("termination: ShouldTerminate called with nil ExecutionContext")
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return &gent.TerminationResult{Status: gent.TerminationContinue}
+	}
+	answers, err := t.parse(content)
+	if err != nil {
+		return &gent.TerminationResult{Status: gent.TerminationContinue}
+	}
+
+	candidates := make([]gent.AnswerCandidate, len(answers))
+	anyAccepted, allContinued := false, true
+	for i, answer := range answers {
+		var continued bool
+		candidates[i], continued = t.validate(execCtx, answer)
+		anyAccepted = anyAccepted || candidates[i].Accepted
+		allContinued = allContinued && continued
+	}
+
+	if !anyAccepted {
+		// Convert each candidate's feedback to ContentPart
+		var feedback []gent.ContentPart
+		for i, candidate := range candidates {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "<candidate_%d>\n", i+1)
+			for _, section := range candidate.Feedback {
+				fmt.Fprintf(&sb, "<%s>\n%s\n</%s>\n", section.Name, section.Content, section.Name)
+			}
+			fmt.Fprintf(&sb, "</candidate_%d>", i+1)
+			feedback = append(feedback, llms.TextContent{Text: sb.String()})
+		}
+		status := gent.TerminationAnswerRejected
+		if allContinued {
+			status = gent.TerminationAnswerContinued
+		}
+		return &gent.TerminationResult{
+			Status:  status,
+			Content: feedback,
+		}
+	}
+
+	output := make([]gent.ContentPart, len(answers))
+	for i, answer := range answers {
+		output[i] = llms.TextContent{Text: answer}
+	}
+	return &gent.TerminationResult{
+		Status:     gent.TerminationAnswerAccepted,
+		Content:    output,
+		Candidates: candidates,
+	}
+}
+
+// validate runs the validator on a single candidate, publishing its events. It also
+// reports whether the validator asked for another iteration without rejecting the
+// candidate (see [gent.ValidationResult.ContinueWithoutRejection]).
+func (t *Candidates) validate(
+	execCtx *gent.ExecutionContext,
+	answer string,
+) (gent.AnswerCandidate, bool) {
+	if t.validator == nil {
+		return gent.AnswerCandidate{Answer: answer, Accepted: true}, false
+	}
+
+	validatorName := t.validator.Name()
+	execCtx.PublishValidatorCalled(validatorName, answer)
+
+	result := t.minScore.check(t.validator.Validate(execCtx, answer))
+	if !result.Accepted && result.ContinueWithoutRejection {
+		// Publish validator result (continue) - rejection stats are not updated
+		execCtx.PublishValidatorContinue(validatorName, answer, result.Feedback)
+		return gent.AnswerCandidate{Answer: answer, Feedback: result.Feedback}, true
+	}
+	t.minScore.publishResult(execCtx, validatorName, answer, result)
+	if !result.Accepted {
+		return gent.AnswerCandidate{Answer: answer, Feedback: result.Feedback}, false
+	}
+	return gent.AnswerCandidate{Answer: answer, Accepted: true}, false
+}
+
+// Compile-time check that Candidates implements gent.Termination.
+var _ gent.Termination = (*Candidates)(nil)
@@ -0,0 +1,243 @@
+package termination
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// noGuessValidator rejects candidates that admit to guessing.
+type noGuessValidator struct{}
+
+func (v *noGuessValidator) Name() string { return "no_guess" }
+func (v *noGuessValidator) Validate(_ *gent.ExecutionContext, answer any) *gent.ValidationResult {
+	if strings.Contains(answer.(string), "guess") {
+		return &gent.ValidationResult{Feedback: []gent.FormattedSection{
+			{Name: "error", Content: "Don't guess."},
+		}}
+	}
+	return &gent.ValidationResult{Accepted: true}
+}
+
+func TestCandidates_ParseSection(t *testing.T) {
+	type input struct {
+		content string
+	}
+
+	type expected struct {
+		candidates any
+		errMsg     string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "array of n candidates",
+			input: input{content: ` ["Sunny. ", "Clear skies"] `},
+			expected: expected{
+				candidates: []string{"Sunny.", "Clear skies"},
+			},
+		},
+		{
+			name:     "wrong number of candidates",
+			input:    input{content: `["Sunny"]`},
+			expected: expected{errMsg: "expected 2 candidates, got 1"},
+		},
+		{
+			name:     "blank candidate",
+			input:    input{content: `["Sunny", " "]`},
+			expected: expected{errMsg: "candidate 2 is empty"},
+		},
+		{
+			name:     "not an array",
+			input:    input{content: `Sunny`},
+			expected: expected{errMsg: "invalid JSON"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewCandidates("answer", 2)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			candidates, err := term.ParseSection(execCtx, tt.input.content)
+
+			assert.Equal(t, tt.expected.candidates, candidates)
+			if tt.expected.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, gent.ErrInvalidJSON)
+			assert.ErrorContains(t, err, tt.expected.errMsg)
+			assert.Equal(t, int64(1),
+				execCtx.Stats().GetCounter(gent.SCTerminationParseErrorTotal))
+		})
+	}
+}
+
+// draftValidator asks for another iteration, without rejecting, for candidates marked
+// as drafts, and rejects guesses.
+type draftValidator struct{}
+
+func (v *draftValidator) Name() string { return "draft" }
+func (v *draftValidator) Validate(_ *gent.ExecutionContext, answer any) *gent.ValidationResult {
+	switch {
+	case strings.Contains(answer.(string), "draft"):
+		return &gent.ValidationResult{
+			ContinueWithoutRejection: true,
+			Feedback:                 []gent.FormattedSection{{Name: "note", Content: "Finish it."}},
+		}
+	case strings.Contains(answer.(string), "guess"):
+		return &gent.ValidationResult{Feedback: []gent.FormattedSection{
+			{Name: "error", Content: "Don't guess."},
+		}}
+	}
+	return &gent.ValidationResult{Accepted: true}
+}
+
+func TestCandidates_ShouldTerminate(t *testing.T) {
+	type input struct {
+		validator gent.AnswerValidator
+		content   string
+	}
+
+	type expected struct {
+		status     gent.TerminationStatus
+		content    []gent.ContentPart
+		candidates []gent.AnswerCandidate
+		rejections int64
+	}
+
+	dontGuess := []gent.FormattedSection{{Name: "error", Content: "Don't guess."}}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "mixed validation results are accepted",
+			input: input{
+				validator: &noGuessValidator{},
+				content:   `["Sunny, 24°C", "My guess is rain", "Clear skies"]`,
+			},
+			expected: expected{
+				status: gent.TerminationAnswerAccepted,
+				content: []gent.ContentPart{
+					llms.TextContent{Text: "Sunny, 24°C"},
+					llms.TextContent{Text: "My guess is rain"},
+					llms.TextContent{Text: "Clear skies"},
+				},
+				candidates: []gent.AnswerCandidate{
+					{Answer: "Sunny, 24°C", Accepted: true},
+					{Answer: "My guess is rain", Feedback: dontGuess},
+					{Answer: "Clear skies", Accepted: true},
+				},
+				rejections: 1,
+			},
+		},
+		{
+			name: "all candidates rejected",
+			input: input{
+				validator: &noGuessValidator{},
+				content:   `["A guess", "Another guess", "Final guess"]`,
+			},
+			expected: expected{
+				status: gent.TerminationAnswerRejected,
+				content: []gent.ContentPart{
+					llms.TextContent{Text: "<candidate_1>\n<error>\nDon't guess.\n</error>\n" +
+						"</candidate_1>"},
+					llms.TextContent{Text: "<candidate_2>\n<error>\nDon't guess.\n</error>\n" +
+						"</candidate_2>"},
+					llms.TextContent{Text: "<candidate_3>\n<error>\nDon't guess.\n</error>\n" +
+						"</candidate_3>"},
+				},
+				rejections: 3,
+			},
+		},
+		{
+			name: "candidates continued without rejection are not counted as rejected",
+			input: input{
+				validator: &draftValidator{},
+				content:   `["A draft", "Another draft", "Final draft"]`,
+			},
+			expected: expected{
+				status: gent.TerminationAnswerContinued,
+				content: []gent.ContentPart{
+					llms.TextContent{Text: "<candidate_1>\n<note>\nFinish it.\n</note>\n" +
+						"</candidate_1>"},
+					llms.TextContent{Text: "<candidate_2>\n<note>\nFinish it.\n</note>\n" +
+						"</candidate_2>"},
+					llms.TextContent{Text: "<candidate_3>\n<note>\nFinish it.\n</note>\n" +
+						"</candidate_3>"},
+				},
+			},
+		},
+		{
+			name: "any rejected candidate rejects the answer",
+			input: input{
+				validator: &draftValidator{},
+				content:   `["A draft", "A guess", "Final draft"]`,
+			},
+			expected: expected{
+				status: gent.TerminationAnswerRejected,
+				content: []gent.ContentPart{
+					llms.TextContent{Text: "<candidate_1>\n<note>\nFinish it.\n</note>\n" +
+						"</candidate_1>"},
+					llms.TextContent{Text: "<candidate_2>\n<error>\nDon't guess.\n</error>\n" +
+						"</candidate_2>"},
+					llms.TextContent{Text: "<candidate_3>\n<note>\nFinish it.\n</note>\n" +
+						"</candidate_3>"},
+				},
+				rejections: 1,
+			},
+		},
+		{
+			name:  "no validator accepts every candidate",
+			input: input{content: `["A", "B", "C"]`},
+			expected: expected{
+				status: gent.TerminationAnswerAccepted,
+				content: []gent.ContentPart{
+					llms.TextContent{Text: "A"},
+					llms.TextContent{Text: "B"},
+					llms.TextContent{Text: "C"},
+				},
+				candidates: []gent.AnswerCandidate{
+					{Answer: "A", Accepted: true},
+					{Answer: "B", Accepted: true},
+					{Answer: "C", Accepted: true},
+				},
+			},
+		},
+		{
+			name:     "unparsable content continues",
+			input:    input{validator: &noGuessValidator{}, content: `["A", "B"]`},
+			expected: expected{status: gent.TerminationContinue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewCandidates("answer", 3)
+			if tt.input.validator != nil {
+				term.SetValidator(tt.input.validator)
+			}
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result := term.ShouldTerminate(execCtx, tt.input.content)
+
+			assert.Equal(t, tt.expected.status, result.Status)
+			assert.Equal(t, tt.expected.content, result.Content)
+			assert.Equal(t, tt.expected.candidates, result.Candidates)
+			assert.Equal(t, tt.expected.rejections,
+				execCtx.Stats().GetCounter(gent.SCAnswerRejectedTotal))
+		})
+	}
+}
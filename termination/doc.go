@@ -11,6 +11,7 @@
 //
 //   - [Text]: Plain text answers - any non-empty text terminates
 //   - [JSON]: Structured JSON answers - validates against a Go type
+//   - [Candidates]: Several candidate answers in one step, e.g. for A/B evaluation
 //
 // # Choosing a Termination Type
 //
@@ -24,6 +25,10 @@
 //   - The response will be processed by code
 //   - You want automatic schema validation
 //
+// Use [Candidates] when:
+//   - An external judge picks the best of several answers to the same task
+//   - The reasoning and tool calls should be shared, with only the answer branching
+//
 // # Validators
 //
 // Both termination types support optional validators via SetValidator:
@@ -55,6 +60,14 @@
 // The framework tracks rejection counts via [gent.SCAnswerRejectedTotal] and
 // [gent.SCAnswerRejectedBy] stats, allowing limits to be set on retries.
 //
+// For graded acceptance, validators set [gent.ValidationResult.Score] and the termination
+// requires a minimum with WithMinScore. Answers scoring below it are rejected with their
+// score in the feedback, and scores are tracked in [gent.SGValidatorScoreLast] and
+// [gent.SGValidatorScoreAvg]:
+//
+//	term := termination.NewText("answer").WithMinScore(0.7)
+//	term.SetValidator(&confidenceValidator{}) // Returns {Accepted: true, Score: 0.42}
+//
 // # Example Usage
 //
 //	// Text termination for conversational agent
@@ -0,0 +1,34 @@
+package termination
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/rickchristie/gent"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// DefaultRejectedAnswerMaxBytes is the default size limit of an answer echoed back with
+// its rejection feedback. Longer answers are truncated.
+const DefaultRejectedAnswerMaxBytes = 4096
+
+// RejectedAnswerSectionName is the name of the section that echoes a rejected answer.
+const RejectedAnswerSectionName = "rejected_answer"
+
+// rejectedAnswerPart formats a rejected answer as a section placed before the validator
+// feedback, so the model sees what it submitted next to what was wrong with it. Answers
+// longer than maxBytes are truncated; a maxBytes of zero or less means no limit.
+func rejectedAnswerPart(answer string, maxBytes int) gent.ContentPart {
+	if maxBytes > 0 && len(answer) > maxBytes {
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(answer[cut]) {
+			cut--
+		}
+		answer = fmt.Sprintf(
+			"%s\n[truncated %d of %d bytes]",
+			answer[:cut], len(answer)-cut, len(answer),
+		)
+	}
+	return llms.TextContent{Text: "<" + RejectedAnswerSectionName + ">\n" + answer +
+		"\n</" + RejectedAnswerSectionName + ">"}
+}
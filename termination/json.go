@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/internal/fields"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -42,6 +43,21 @@ import (
 //	        Total:   99.99,
 //	    })
 //
+// # Array Answers
+//
+// T may be a slice, for answers that are a list at the top level. The schema becomes
+// {"type": "array", "items": ...}, the section content must be a JSON array (an empty
+// array is a valid answer), and validators receive the decoded slice. An element that
+// does not match the item type is a parse error:
+//
+//	type Record struct {
+//	    ID   int    `json:"id"`
+//	    Name string `json:"name"`
+//	}
+//
+//	term := termination.NewJSON[[]Record]("answer")
+//	term.SetValidator(&recordsValidator{}) // Receives []Record
+//
 // # Using with Agent
 //
 //	agent := react.NewAgent(model).
@@ -71,19 +87,36 @@ import (
 //   - Empty content: Returns [gent.TerminationContinue]
 //   - Invalid JSON: Returns [gent.TerminationContinue] (agent should try again)
 //   - Valid JSON with validation failure: Returns [gent.TerminationAnswerRejected]
+//   - Valid JSON the validator wants refined: Returns [gent.TerminationAnswerContinued]
+//     (see [gent.ValidationResult.ContinueWithoutRejection])
 //   - Valid JSON passing validation: Returns [gent.TerminationAnswerAccepted]
+//
+// # Echoing Rejected Answers
+//
+// By default a rejection only shows the model the validator feedback. With
+// [JSON.WithEchoRejectedAnswer], the rejected answer is echoed back in a
+// <rejected_answer> section before the feedback, so the model can see exactly what it
+// submitted and change only what is wrong:
+//
+//	term := termination.NewJSON[OrderResponse]("answer").WithEchoRejectedAnswer()
+//	term.SetValidator(&orderValidator{})
 type JSON[T any] struct {
 	sectionName string
 	guidance    string
 	example     *T
 	validator   gent.AnswerValidator
+	minScore    minScore
+
+	echoRejected     bool
+	rejectedMaxBytes int
 }
 
 // NewJSON creates a new JSON termination with the given name.
 func NewJSON[T any](name string) *JSON[T] {
 	return &JSON[T]{
-		sectionName: name,
-		guidance:    "Write your final answer here.",
+		sectionName:      name,
+		guidance:         "Write your final answer here.",
+		rejectedMaxBytes: DefaultRejectedAnswerMaxBytes,
 	}
 }
 
@@ -104,6 +137,31 @@ func (t *JSON[T]) WithExample(example T) *JSON[T] {
 	return t
 }
 
+// WithEchoRejectedAnswer makes rejections echo the rejected answer back before the
+// validator feedback. Answers longer than [DefaultRejectedAnswerMaxBytes] are truncated;
+// use WithRejectedAnswerMaxBytes to change the limit.
+func (t *JSON[T]) WithEchoRejectedAnswer() *JSON[T] {
+	t.echoRejected = true
+	return t
+}
+
+// WithRejectedAnswerMaxBytes sets the size limit of an echoed rejected answer. Zero or
+// less disables truncation. Only has an effect with WithEchoRejectedAnswer.
+func (t *JSON[T]) WithRejectedAnswerMaxBytes(maxBytes int) *JSON[T] {
+	t.rejectedMaxBytes = maxBytes
+	return t
+}
+
+// WithMinScore makes the termination reject answers whose validator score (see
+// [gent.ValidationResult.Score]) is below min, even if the validator accepted them. The
+// rejection feedback ends with a "score" section telling the model its score. Scores are
+// recorded in [gent.SGValidatorScoreLast] and [gent.SGValidatorScoreAvg]. Only has an
+// effect with a validator; see [Text.WithMinScore].
+func (t *JSON[T]) WithMinScore(min float64) *JSON[T] {
+	t.minScore = minScore{value: min, enabled: true}
+	return t
+}
+
 // Name returns the section identifier.
 func (t *JSON[T]) Name() string {
 	return t.sectionName
@@ -164,6 +222,13 @@ func (t *JSON[T]) ParseSection(execCtx *gent.ExecutionContext, content string) (
 	return result, nil
 }
 
+// JSONSchema returns the JSON Schema derived from T.
+// Implements [gent.SchemaSection].
+func (t *JSON[T]) JSONSchema() map[string]any {
+	var zero T
+	return generateJSONSchema(reflect.TypeOf(zero))
+}
+
 // SetValidator sets the validator to run on parsed answers before acceptance.
 func (t *JSON[T]) SetValidator(validator gent.AnswerValidator) {
 	t.validator = validator
@@ -202,26 +267,36 @@ func (t *JSON[T]) ShouldTerminate(
 		// Publish validator called event
 		execCtx.PublishValidatorCalled(validatorName, result)
 
-		validationResult := t.validator.Validate(execCtx, result)
+		validationResult := t.minScore.check(t.validator.Validate(execCtx, result))
 		if !validationResult.Accepted {
-			// Publish validator result (rejection) - updates stats automatically
-			execCtx.PublishValidatorResult(validatorName, result, false, validationResult.Feedback)
+			status := gent.TerminationAnswerRejected
+			if validationResult.ContinueWithoutRejection {
+				// Publish validator result (continue) - rejection stats are not updated
+				execCtx.PublishValidatorContinue(validatorName, result, validationResult.Feedback)
+				status = gent.TerminationAnswerContinued
+			} else {
+				// Publish validator result (rejection) - updates stats automatically
+				t.minScore.publishResult(execCtx, validatorName, result, validationResult)
+			}
 
 			// Convert feedback to ContentPart
 			var feedback []gent.ContentPart
+			if t.echoRejected && status == gent.TerminationAnswerRejected {
+				feedback = append(feedback, rejectedAnswerPart(content, t.rejectedMaxBytes))
+			}
 			for _, section := range validationResult.Feedback {
 				formatted := "<" + section.Name + ">\n" + section.Content + "\n</" + section.Name + ">"
 				feedback = append(feedback, llms.TextContent{Text: formatted})
 			}
 
 			return &gent.TerminationResult{
-				Status:  gent.TerminationAnswerRejected,
+				Status:  status,
 				Content: feedback,
 			}
 		}
 
 		// Publish validator result (acceptance)
-		execCtx.PublishValidatorResult(validatorName, result, true, nil)
+		t.minScore.publishResult(execCtx, validatorName, result, validationResult)
 	}
 
 	// Re-serialize to ensure consistent formatting
@@ -299,47 +374,20 @@ func generateJSONSchema(t reflect.Type) map[string]any {
 		properties := make(map[string]any)
 		required := make([]string, 0)
 
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-
-			// Skip unexported fields
-			if !field.IsExported() {
-				continue
-			}
-
-			// Get JSON tag
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "-" {
-				continue
-			}
-
-			fieldName := field.Name
-			omitempty := false
-
-			if jsonTag != "" {
-				parts := strings.Split(jsonTag, ",")
-				if parts[0] != "" {
-					fieldName = parts[0]
-				}
-				for _, part := range parts[1:] {
-					if part == "omitempty" {
-						omitempty = true
-					}
-				}
-			}
-
-			fieldSchema := generateJSONSchema(field.Type)
+		// Fields of embedded structs are promoted, following encoding/json's rules
+		for _, field := range fields.Of(t, "json") {
+			fieldSchema := generateJSONSchema(field.Field.Type)
 
 			// Add description from struct tag if present
-			if desc := field.Tag.Get("description"); desc != "" {
+			if desc := field.Field.Tag.Get("description"); desc != "" {
 				fieldSchema["description"] = desc
 			}
 
-			properties[fieldName] = fieldSchema
+			properties[field.Name] = fieldSchema
 
 			// Required if not omitempty and not a pointer
-			if !omitempty && field.Type.Kind() != reflect.Ptr {
-				required = append(required, fieldName)
+			if !field.HasOption("omitempty") && field.Field.Type.Kind() != reflect.Ptr {
+				required = append(required, field.Name)
 			}
 		}
 
@@ -358,3 +406,6 @@ func generateJSONSchema(t reflect.Type) map[string]any {
 		return map[string]any{}
 	}
 }
+
+// Compile-time check that JSON implements gent.SchemaSection.
+var _ gent.SchemaSection = (*JSON[any])(nil)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/rickchristie/gent"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -414,6 +415,37 @@ func TestJSON_SchemaRequired(t *testing.T) {
 		"prompt should contain required field")
 }
 
+type EmbeddedAudit struct {
+	ID        string `json:"id"`
+	CreatedBy string `json:"created_by"`
+}
+
+type EmbeddedRecord struct {
+	EmbeddedAudit
+	Version int `json:"version,omitempty"`
+}
+
+type EmbeddedAnswer struct {
+	EmbeddedRecord
+	ID     int    `json:"id"` // shadows EmbeddedAudit.ID
+	Status string `json:"status"`
+}
+
+func TestJSON_SchemaEmbeddedStructs(t *testing.T) {
+	schema := NewJSON[EmbeddedAnswer]("answer").JSONSchema()
+
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "integer"},
+			"created_by": map[string]any{"type": "string"},
+			"version":    map[string]any{"type": "integer"},
+			"status":     map[string]any{"type": "string"},
+		},
+		"required": []string{"created_by", "id", "status"},
+	}, schema)
+}
+
 func TestJSON_ShouldTerminate(t *testing.T) {
 	type input struct {
 		content string
@@ -493,18 +525,143 @@ func TestJSON_ShouldTerminate(t *testing.T) {
 	}
 }
 
+type Record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// recordingJSONValidator accepts every answer and records what it received.
+type recordingJSONValidator struct {
+	received []any
+}
+
+func (v *recordingJSONValidator) Name() string { return "recording" }
+func (v *recordingJSONValidator) Validate(
+	_ *gent.ExecutionContext,
+	answer any,
+) *gent.ValidationResult {
+	v.received = append(v.received, answer)
+	return &gent.ValidationResult{Accepted: true}
+}
+
+func TestJSON_TopLevelArray(t *testing.T) {
+	type input struct {
+		content string
+	}
+
+	type expected struct {
+		parsed      any
+		parseErr    error
+		status      gent.TerminationStatus
+		answer      string
+		validated   []any
+		parseErrors int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "records decode into the slice",
+			input: input{content: `[{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]`},
+			expected: expected{
+				parsed:    []Record{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+				status:    gent.TerminationAnswerAccepted,
+				answer:    `[{"id":1,"name":"a"},{"id":2,"name":"b"}]`,
+				validated: []any{[]Record{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}},
+			},
+		},
+		{
+			name:  "empty array is a valid answer",
+			input: input{content: `[]`},
+			expected: expected{
+				parsed:    []Record{},
+				status:    gent.TerminationAnswerAccepted,
+				answer:    `[]`,
+				validated: []any{[]Record{}},
+			},
+		},
+		{
+			name:  "malformed element is a parse error",
+			input: input{content: `[{"id": 1, "name": "a"}, {"id": "two", "name": "b"}]`},
+			expected: expected{
+				parseErr:    gent.ErrInvalidJSON,
+				status:      gent.TerminationContinue,
+				parseErrors: 1,
+			},
+		},
+		{
+			name:  "object instead of array is a parse error",
+			input: input{content: `{"id": 1, "name": "a"}`},
+			expected: expected{
+				parseErr:    gent.ErrInvalidJSON,
+				status:      gent.TerminationContinue,
+				parseErrors: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewJSON[[]Record]("answer")
+			validator := &recordingJSONValidator{}
+			term.SetValidator(validator)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			parsed, err := term.ParseSection(execCtx, tt.input.content)
+			if tt.expected.parseErr != nil {
+				assert.ErrorIs(t, err, tt.expected.parseErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected.parsed, parsed)
+			}
+			assert.Equal(t, tt.expected.parseErrors,
+				execCtx.Stats().GetCounter(gent.SCTerminationParseErrorTotal))
+
+			result := term.ShouldTerminate(execCtx, tt.input.content)
+			assert.Equal(t, tt.expected.status, result.Status)
+			if tt.expected.answer != "" {
+				assert.Equal(t,
+					[]gent.ContentPart{llms.TextContent{Text: tt.expected.answer}},
+					result.Content)
+			}
+			assert.Equal(t, tt.expected.validated, validator.received)
+		})
+	}
+
+	t.Run("schema describes an array of records", func(t *testing.T) {
+		term := NewJSON[[]Record]("answer")
+
+		assert.Equal(t, map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":   map[string]any{"type": "integer"},
+					"name": map[string]any{"type": "string"},
+				},
+				"required": []string{"id", "name"},
+			},
+		}, term.JSONSchema())
+	})
+}
+
 // mockJSONValidator is a test validator for JSON termination.
 type mockJSONValidator struct {
-	name     string
-	accepted bool
-	feedback []gent.FormattedSection
+	name          string
+	accepted      bool
+	feedback      []gent.FormattedSection
+	continueAfter bool // ask for another iteration instead of rejecting
 }
 
 func (m *mockJSONValidator) Name() string { return m.name }
 func (m *mockJSONValidator) Validate(_ *gent.ExecutionContext, _ any) *gent.ValidationResult {
 	return &gent.ValidationResult{
-		Accepted: m.accepted,
-		Feedback: m.feedback,
+		Accepted:                 m.accepted,
+		Feedback:                 m.feedback,
+		ContinueWithoutRejection: m.continueAfter,
 	}
 }
 
@@ -548,6 +705,34 @@ func TestJSON_SetValidator(t *testing.T) {
 			))
 	})
 
+	t.Run("validator continues without rejection", func(t *testing.T) {
+		term := NewJSON[SimpleStruct]("answer").WithEchoRejectedAnswer()
+		term.SetValidator(&mockJSONValidator{
+			name:          "schema_validator",
+			continueAfter: true,
+			feedback: []gent.FormattedSection{
+				{Name: "guidance", Content: "Good start. Double-check the value."},
+			},
+		})
+
+		execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+		result := term.ShouldTerminate(execCtx, `{"name": "test", "value": 42}`)
+
+		// Guidance only: the answer is not echoed since it was not rejected
+		assert.Equal(t, gent.TerminationAnswerContinued, result.Status)
+		assert.Equal(t, []gent.ContentPart{llms.TextContent{
+			Text: "<guidance>\nGood start. Double-check the value.\n</guidance>",
+		}}, result.Content)
+
+		// Rejection stats stay flat
+		assert.Equal(t, int64(0),
+			execCtx.Stats().GetCounter(gent.SCAnswerRejectedTotal))
+		assert.Equal(t, int64(0),
+			execCtx.Stats().GetCounter(
+				gent.SCAnswerRejectedBy+"schema_validator",
+			))
+	})
+
 	t.Run("no validator means answer accepted", func(t *testing.T) {
 		term := NewJSON[SimpleStruct]("answer")
 		// No validator set
@@ -567,6 +752,82 @@ func TestJSON_SetValidator(t *testing.T) {
 	})
 }
 
+func TestJSON_WithEchoRejectedAnswer(t *testing.T) {
+	type input struct {
+		echo     bool
+		maxBytes int // 0 keeps the default
+		content  string
+	}
+
+	type expected struct {
+		parts []string
+	}
+
+	feedback := "<error>\nvalue must be positive\n</error>"
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "disabled by default",
+			input: input{content: `{"name": "test", "value": -1}`},
+			expected: expected{
+				parts: []string{feedback},
+			},
+		},
+		{
+			name:  "echoes answer before feedback",
+			input: input{echo: true, content: `{"name": "test", "value": -1}`},
+			expected: expected{
+				parts: []string{
+					"<rejected_answer>\n{\"name\": \"test\", \"value\": -1}\n</rejected_answer>",
+					feedback,
+				},
+			},
+		},
+		{
+			name:  "truncates large answers",
+			input: input{echo: true, maxBytes: 10, content: `{"name": "test", "value": -1}`},
+			expected: expected{
+				parts: []string{
+					"<rejected_answer>\n{\"name\": \"\n[truncated 19 of 29 bytes]\n</rejected_answer>",
+					feedback,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewJSON[SimpleStruct]("answer")
+			if tt.input.echo {
+				term.WithEchoRejectedAnswer()
+			}
+			if tt.input.maxBytes != 0 {
+				term.WithRejectedAnswerMaxBytes(tt.input.maxBytes)
+			}
+			term.SetValidator(&mockJSONValidator{
+				name: "range_validator",
+				feedback: []gent.FormattedSection{
+					{Name: "error", Content: "value must be positive"},
+				},
+			})
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			result := term.ShouldTerminate(execCtx, tt.input.content)
+
+			assert.Equal(t, gent.TerminationAnswerRejected, result.Status)
+			var parts []string
+			for _, part := range result.Content {
+				parts = append(parts, part.(llms.TextContent).Text)
+			}
+			assert.Equal(t, tt.expected.parts, parts)
+		})
+	}
+}
+
 func TestJSON_ParseSection_TracesErrors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,55 @@
+package termination
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rickchristie/gent"
+)
+
+// ScoreSectionName is the name of the feedback section reporting a validator score below
+// a termination's minimum (see Text.WithMinScore).
+const ScoreSectionName = "score"
+
+// minScore is the minimum validator score a termination requires. The zero value
+// requires none.
+type minScore struct {
+	value   float64
+	enabled bool
+}
+
+// check returns result, or a rejected copy of it with a score section added to its
+// feedback if its score is below the minimum. The validator's result is not modified.
+func (m minScore) check(result *gent.ValidationResult) *gent.ValidationResult {
+	if !m.enabled || result.Score >= m.value {
+		return result
+	}
+	checked := *result
+	checked.Accepted = false
+	checked.Feedback = append(slices.Clip(result.Feedback), gent.FormattedSection{
+		Name: ScoreSectionName,
+		Content: fmt.Sprintf("Your answer scored %g, below the required minimum of %g.",
+			result.Score, m.value),
+	})
+	return &checked
+}
+
+// publishResult publishes the ValidatorResultEvent of an accepted or rejected answer,
+// carrying the score when a minimum is set.
+func (m minScore) publishResult(
+	execCtx *gent.ExecutionContext,
+	validatorName string,
+	answer any,
+	result *gent.ValidationResult,
+) {
+	feedback := result.Feedback
+	if result.Accepted {
+		feedback = nil
+	}
+	if m.enabled {
+		execCtx.PublishScoredValidatorResult(validatorName, answer, result.Accepted, feedback,
+			result.Score)
+		return
+	}
+	execCtx.PublishValidatorResult(validatorName, answer, result.Accepted, feedback)
+}
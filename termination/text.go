@@ -35,17 +35,21 @@ import (
 //	term.SetValidator(&myValidator{})  // Implement gent.AnswerValidator
 //
 // When a validator rejects an answer, the status is [gent.TerminationAnswerRejected]
-// and feedback is provided for the agent to improve its answer.
+// and feedback is provided for the agent to improve its answer. Use WithMinScore to also
+// reject answers the validator scores too low.
 //
 // # Termination Behavior
 //
 //   - Empty content: Returns [gent.TerminationContinue]
 //   - Non-empty content with validation failure: Returns [gent.TerminationAnswerRejected]
+//   - Non-empty content the validator wants refined: Returns [gent.TerminationAnswerContinued]
+//     (see [gent.ValidationResult.ContinueWithoutRejection])
 //   - Non-empty content passing validation: Returns [gent.TerminationAnswerAccepted]
 type Text struct {
 	sectionName string
 	guidance    string
 	validator   gent.AnswerValidator
+	minScore    minScore
 }
 
 // NewText creates a new Text termination with the given name.
@@ -65,6 +69,25 @@ func (t *Text) WithGuidance(guidance string) *Text {
 	return t
 }
 
+// WithMinScore makes the termination reject answers whose validator score (see
+// [gent.ValidationResult.Score]) is below min, even if the validator accepted them. The
+// rejection feedback ends with a "score" section telling the model its score:
+//
+//	term := termination.NewText("answer").WithMinScore(0.7)
+//	term.SetValidator(&confidenceValidator{})
+//
+//	// Validator returns {Accepted: true, Score: 0.42}; the model sees:
+//	// <score>
+//	// Your answer scored 0.42, below the required minimum of 0.7.
+//	// </score>
+//
+// Scores are recorded in [gent.SGValidatorScoreLast] and [gent.SGValidatorScoreAvg].
+// Only has an effect with a validator.
+func (t *Text) WithMinScore(min float64) *Text {
+	t.minScore = minScore{value: min, enabled: true}
+	return t
+}
+
 // Name returns the section identifier.
 func (t *Text) Name() string {
 	return t.sectionName
@@ -113,10 +136,17 @@ func (t *Text) ShouldTerminate(
 		// Publish validator called event
 		execCtx.PublishValidatorCalled(validatorName, trimmed)
 
-		result := t.validator.Validate(execCtx, trimmed)
+		result := t.minScore.check(t.validator.Validate(execCtx, trimmed))
 		if !result.Accepted {
-			// Publish validator result (rejection) - updates stats automatically
-			execCtx.PublishValidatorResult(validatorName, trimmed, false, result.Feedback)
+			status := gent.TerminationAnswerRejected
+			if result.ContinueWithoutRejection {
+				// Publish validator result (continue) - rejection stats are not updated
+				execCtx.PublishValidatorContinue(validatorName, trimmed, result.Feedback)
+				status = gent.TerminationAnswerContinued
+			} else {
+				// Publish validator result (rejection) - updates stats automatically
+				t.minScore.publishResult(execCtx, validatorName, trimmed, result)
+			}
 
 			// Convert feedback to ContentPart
 			var feedback []gent.ContentPart
@@ -126,13 +156,13 @@ func (t *Text) ShouldTerminate(
 			}
 
 			return &gent.TerminationResult{
-				Status:  gent.TerminationAnswerRejected,
+				Status:  status,
 				Content: feedback,
 			}
 		}
 
 		// Publish validator result (acceptance)
-		execCtx.PublishValidatorResult(validatorName, trimmed, true, nil)
+		t.minScore.publishResult(execCtx, validatorName, trimmed, result)
 	}
 
 	return &gent.TerminationResult{
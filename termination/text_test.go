@@ -6,21 +6,26 @@ import (
 
 	"github.com/rickchristie/gent"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
 // mockValidator is a test validator that can accept or reject answers.
 type mockValidator struct {
-	name     string
-	accepted bool
-	feedback []gent.FormattedSection
+	name          string
+	accepted      bool
+	feedback      []gent.FormattedSection
+	continueAfter bool // ask for another iteration instead of rejecting
+	score         float64
 }
 
 func (m *mockValidator) Name() string { return m.name }
 func (m *mockValidator) Validate(_ *gent.ExecutionContext, _ any) *gent.ValidationResult {
 	return &gent.ValidationResult{
-		Accepted: m.accepted,
-		Feedback: m.feedback,
+		Accepted:                 m.accepted,
+		Feedback:                 m.feedback,
+		ContinueWithoutRejection: m.continueAfter,
+		Score:                    m.score,
 	}
 }
 
@@ -256,6 +261,39 @@ func TestText_SetValidator(t *testing.T) {
 			))
 	})
 
+	t.Run("validator continues without rejection", func(t *testing.T) {
+		term := NewText("answer")
+		term.SetValidator(&mockValidator{
+			name:          "test_validator",
+			continueAfter: true,
+			feedback: []gent.FormattedSection{
+				{Name: "guidance", Content: "Almost done. Add the delivery date."},
+			},
+		})
+
+		execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+		result := term.ShouldTerminate(execCtx, "draft answer")
+
+		assert.Equal(t, gent.TerminationAnswerContinued, result.Status)
+		assert.Equal(t, []gent.ContentPart{llms.TextContent{
+			Text: "<guidance>\nAlmost done. Add the delivery date.\n</guidance>",
+		}}, result.Content)
+
+		// Rejection stats stay flat
+		assert.Equal(t, int64(0),
+			execCtx.Stats().GetCounter(gent.SCAnswerRejectedTotal))
+		assert.Equal(t, int64(0),
+			execCtx.Stats().GetCounter(
+				gent.SCAnswerRejectedBy+"test_validator",
+			))
+
+		events := execCtx.Events()
+		resultEvent, ok := events[len(events)-1].(*gent.ValidatorResultEvent)
+		require.True(t, ok)
+		assert.False(t, resultEvent.Accepted)
+		assert.True(t, resultEvent.ContinueWithoutRejection)
+	})
+
 	t.Run("no validator means answer accepted", func(t *testing.T) {
 		term := NewText("answer")
 		// No validator set
@@ -357,3 +395,140 @@ func TestText_ValidatorTracing(t *testing.T) {
 		assert.Len(t, events, 0, "expected no trace events when no validator is set")
 	})
 }
+
+func TestText_MinScore(t *testing.T) {
+	type input struct {
+		minScore *float64
+		accepted bool
+		feedback []gent.FormattedSection
+		scores   []float64 // one answer per score
+	}
+
+	type expected struct {
+		statuses  []gent.TerminationStatus
+		content   []string // content of the last result
+		rejected  int64
+		lastScore float64
+		avgScore  float64
+	}
+
+	minScore := 0.7
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "score above minimum is accepted",
+			input: input{minScore: &minScore, accepted: true, scores: []float64{0.9}},
+			expected: expected{
+				statuses:  []gent.TerminationStatus{gent.TerminationAnswerAccepted},
+				content:   []string{"The answer."},
+				lastScore: 0.9,
+				avgScore:  0.9,
+			},
+		},
+		{
+			name:  "score equal to minimum is accepted",
+			input: input{minScore: &minScore, accepted: true, scores: []float64{0.7}},
+			expected: expected{
+				statuses:  []gent.TerminationStatus{gent.TerminationAnswerAccepted},
+				content:   []string{"The answer."},
+				lastScore: 0.7,
+				avgScore:  0.7,
+			},
+		},
+		{
+			name:  "score below minimum rejects an accepted answer",
+			input: input{minScore: &minScore, accepted: true, scores: []float64{0.42}},
+			expected: expected{
+				statuses: []gent.TerminationStatus{gent.TerminationAnswerRejected},
+				content: []string{"<score>\nYour answer scored 0.42, below the required " +
+					"minimum of 0.7.\n</score>"},
+				rejected:  1,
+				lastScore: 0.42,
+				avgScore:  0.42,
+			},
+		},
+		{
+			name: "score below minimum follows the validator feedback",
+			input: input{
+				minScore: &minScore,
+				feedback: []gent.FormattedSection{{Name: "error", Content: "Cite a source."}},
+				scores:   []float64{0.5},
+			},
+			expected: expected{
+				statuses: []gent.TerminationStatus{gent.TerminationAnswerRejected},
+				content: []string{
+					"<error>\nCite a source.\n</error>",
+					"<score>\nYour answer scored 0.5, below the required minimum of 0.7." +
+						"\n</score>",
+				},
+				rejected:  1,
+				lastScore: 0.5,
+				avgScore:  0.5,
+			},
+		},
+		{
+			name:  "average covers every scored answer",
+			input: input{minScore: &minScore, accepted: true, scores: []float64{0.4, 0.6, 0.8}},
+			expected: expected{
+				statuses: []gent.TerminationStatus{
+					gent.TerminationAnswerRejected,
+					gent.TerminationAnswerRejected,
+					gent.TerminationAnswerAccepted,
+				},
+				content:   []string{"The answer."},
+				rejected:  2,
+				lastScore: 0.8,
+				avgScore:  0.6,
+			},
+		},
+		{
+			name:  "without a minimum the score is ignored",
+			input: input{accepted: true, scores: []float64{0.1}},
+			expected: expected{
+				statuses: []gent.TerminationStatus{gent.TerminationAnswerAccepted},
+				content:  []string{"The answer."},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			term := NewText("answer")
+			if tc.input.minScore != nil {
+				term.WithMinScore(*tc.input.minScore)
+			}
+			validator := &mockValidator{
+				name:     "confidence",
+				accepted: tc.input.accepted,
+				feedback: tc.input.feedback,
+			}
+			term.SetValidator(validator)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			var statuses []gent.TerminationStatus
+			var result *gent.TerminationResult
+			for _, score := range tc.input.scores {
+				validator.score = score
+				result = term.ShouldTerminate(execCtx, "The answer.")
+				statuses = append(statuses, result.Status)
+			}
+
+			var content []string
+			for _, part := range result.Content {
+				content = append(content, part.(llms.TextContent).Text)
+			}
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.statuses, statuses)
+			assert.Equal(t, tc.expected.content, content)
+			assert.Equal(t, tc.expected.rejected, stats.GetCounter(gent.SCAnswerRejectedTotal))
+			assert.Equal(t, tc.expected.rejected,
+				stats.GetCounter(gent.SCAnswerRejectedBy+"confidence"))
+			assert.Equal(t, tc.expected.lastScore, stats.GetGauge(gent.SGValidatorScoreLast))
+			assert.InDelta(t, tc.expected.avgScore, stats.GetGauge(gent.SGValidatorScoreAvg),
+				1e-9)
+		})
+	}
+}
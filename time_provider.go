@@ -109,16 +109,28 @@ type TimeProvider interface {
 	RelativeDate(t time.Time) string
 }
 
-// DefaultTimeProvider is the standard TimeProvider using the system clock.
-type DefaultTimeProvider struct{}
+// DefaultTimeProvider is the standard TimeProvider using the system clock, or the
+// [Clock] it was created with.
+type DefaultTimeProvider struct {
+	clock Clock
+}
 
 // NewDefaultTimeProvider creates a new DefaultTimeProvider.
 func NewDefaultTimeProvider() *DefaultTimeProvider {
 	return &DefaultTimeProvider{}
 }
 
-// Now returns the current system time.
+// NewClockTimeProvider creates a DefaultTimeProvider that reads the time from clock.
+// A nil clock falls back to the system clock.
+func NewClockTimeProvider(clock Clock) *DefaultTimeProvider {
+	return &DefaultTimeProvider{clock: clock}
+}
+
+// Now returns the current time.
 func (p *DefaultTimeProvider) Now() time.Time {
+	if p.clock != nil {
+		return p.clock.Now()
+	}
 	return time.Now()
 }
 
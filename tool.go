@@ -2,6 +2,10 @@ package gent
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rickchristie/gent/schema"
 )
 
 // Tool represents a single callable tool with typed input and output.
@@ -167,13 +171,89 @@ type ToolResult[TextOutput any] struct {
 	Instructions string
 }
 
+// OutputSchemaTool is an optional interface for tools that describe the shape of their
+// output.
+//
+// Toolchains show the output schema in the tool catalog next to the parameters, so the
+// model knows which fields a result contains before calling the tool. The schema
+// describes the JSON encoding of TextOutput; YAML toolchains render the same fields, by
+// their json tag names.
+//
+// [ToolFunc] implements this interface. Use [NewStructuredToolFunc] to generate the
+// schema from the output type, or WithOutputSchema to set it explicitly.
+type OutputSchemaTool interface {
+	// OutputSchema returns the JSON Schema of the tool's output, or nil if unspecified.
+	OutputSchema() map[string]any
+}
+
+// CategorizedTool is an optional interface for tools that belong to a category, e.g.
+// "orders" or "billing". Toolchains report the category in [ToolInfo] so system prompt
+// builders can group related tools.
+//
+// [ToolFunc] implements this interface; set the category with WithCategory.
+type CategorizedTool interface {
+	// Category returns the tool's category, or "" if uncategorized.
+	Category() string
+}
+
+// TerminalTool is an optional interface for tools whose successful call is the final
+// action of a run, e.g. "submit_order". Toolchains flag successful calls to such tools
+// in [RawToolCallResult].Terminal, and agent loops end the run with the tool's output as
+// the answer instead of asking the model for one. A failed call does not end the run.
+//
+// [ToolFunc] implements this interface; mark it with WithTerminal:
+//
+//	submit := gent.NewToolFunc("submit_order", "Submit the order", schema, submitFn).
+//	    WithTerminal()
+type TerminalTool interface {
+	// IsTerminal returns true if a successful call ends the run.
+	IsTerminal() bool
+}
+
+// ObservationFormatterTool is an optional interface for tools that render their own
+// successful results into the observation, e.g. as a table or with links, instead of
+// the toolchain's default rendering. Unlike [FormatSections], which lays out the whole
+// observation, it only shapes the content of this tool's result.
+//
+// Toolchains call FormatObservation with the tool name and the output as text: the
+// output itself if it is a string, and the toolchain's default rendering otherwise.
+// Observation limits still apply to the returned text, and instructions are still
+// added next to it.
+type ObservationFormatterTool interface {
+	// FormatObservation returns the rendered output, or false to use the toolchain's
+	// default rendering.
+	FormatObservation(name, output string) (string, bool)
+}
+
+// ParseValidatedTool is an optional interface for tools whose arguments are validated
+// against their parameter schema while the action is parsed. Invalid arguments then fail
+// parsing as a toolchain parse error ([ParseErrorTypeToolchain]), fed back to the model
+// and counted by SCToolchainParseErrorTotal, instead of a tool call error.
+//
+// Tools created with [NewDynamicTool] implement this interface.
+type ParseValidatedTool interface {
+	// ValidatesAtParse returns true if arguments are validated while parsing.
+	ValidatesAtParse() bool
+}
+
+// ErrInvalidToolInput is wrapped by the error a [ToolFunc] returns when its input
+// validator (see WithValidate) rejects the input. Check with errors.Is.
+var ErrInvalidToolInput = errors.New("invalid tool input")
+
 // ToolFunc is a convenience type for creating tools from functions with typed I/O.
 type ToolFunc[I, TextOutput any] struct {
-	name        string
-	description string
-	policy      string
-	schema      map[string]any
-	fn          func(ctx context.Context, input I) (TextOutput, error)
+	name         string
+	description  string
+	policy       string
+	schema       map[string]any
+	outputSchema map[string]any
+	sideEffects  bool
+	terminal     bool
+	parseChecked bool
+	category     string
+	validate     func(input I) error
+	formatter    func(name string, output string) string
+	fn           func(ctx context.Context, input I) (TextOutput, error)
 }
 
 // NewToolFunc creates a new ToolFunc with typed input and output.
@@ -191,6 +271,84 @@ func NewToolFunc[I, TextOutput any](
 	}
 }
 
+// NewStructuredToolFunc creates a ToolFunc whose output is a struct serialized by the
+// ToolChain, with an output schema generated from Out (see [schema.FromType]).
+//
+// Returning a struct instead of a hand-encoded string keeps observations in the same
+// format as the rest of the toolchain (YAML for toolchain.YAML, JSON for
+// toolchain.JSON), and the generated schema appears in the tool catalog so the model
+// knows the result shape.
+//
+// Example:
+//
+//	type OrderStatus struct {
+//	    Status   string `json:"status" description:"shipped, pending, or cancelled"`
+//	    Tracking string `json:"tracking,omitempty"`
+//	}
+//
+//	tool := gent.NewStructuredToolFunc(
+//	    "lookup_order",
+//	    "Look up an order's status",
+//	    schema.Object(map[string]*schema.Property{
+//	        "order_id": schema.String("Order ID"),
+//	    }, "order_id"),
+//	    func(ctx context.Context, input OrderInput) (OrderStatus, error) {
+//	        return lookupOrder(ctx, input.OrderID)
+//	    },
+//	)
+func NewStructuredToolFunc[In, Out any](
+	name, description string,
+	parameterSchema map[string]any,
+	fn func(ctx context.Context, input In) (Out, error),
+) *ToolFunc[In, Out] {
+	tool := NewToolFunc(name, description, parameterSchema, fn)
+	tool.outputSchema = schema.For[Out]()
+	return tool
+}
+
+// NewDynamicTool creates a tool from a raw JSON Schema known only at runtime, e.g. one
+// fetched from an external tool registry, without a Go input struct.
+//
+// The handler receives the arguments as decoded JSON: objects are map[string]any,
+// arrays []any and numbers float64. Toolchains validate the arguments against the
+// schema (types, required properties, enums, bounds, patterns) while parsing the
+// action; invalid arguments are reported to the model as a toolchain parse error (see
+// [ParseValidatedTool]) and the handler is not called. The handler's output is
+// formatted by the ToolChain.
+//
+// To decode the arguments into a struct instead, generate its type with
+// schema.StructFromJSONSchema.
+//
+// Returns an error if parameterSchema is not a valid JSON Schema, since toolchains
+// cannot validate arguments against it.
+//
+// Example:
+//
+//	var raw map[string]any
+//	if err := json.Unmarshal(registryEntry.Schema, &raw); err != nil {
+//	    return err
+//	}
+//	tool, err := gent.NewDynamicTool(
+//	    registryEntry.Name,
+//	    registryEntry.Description,
+//	    raw,
+//	    func(ctx context.Context, args map[string]any) (any, error) {
+//	        return registry.Invoke(ctx, registryEntry.Name, args)
+//	    },
+//	)
+func NewDynamicTool(
+	name, description string,
+	parameterSchema map[string]any,
+	fn func(ctx context.Context, args map[string]any) (any, error),
+) (*ToolFunc[map[string]any, any], error) {
+	if _, err := schema.Compile(parameterSchema); err != nil {
+		return nil, fmt.Errorf("tool %q: invalid parameter schema: %w", name, err)
+	}
+	tool := NewToolFunc(name, description, parameterSchema, fn)
+	tool.parseChecked = true
+	return tool, nil
+}
+
 // Name returns the tool's identifier.
 func (t *ToolFunc[I, TextOutput]) Name() string {
 	return t.name
@@ -212,17 +370,133 @@ func (t *ToolFunc[I, TextOutput]) WithPolicy(policy string) *ToolFunc[I, TextOut
 	return t
 }
 
+// WithSideEffects marks this tool as side-effecting and returns self for chaining.
+// During a dry run, toolchains with dry-run stubbing enabled won't call it.
+// See [SideEffectTool].
+func (t *ToolFunc[I, TextOutput]) WithSideEffects() *ToolFunc[I, TextOutput] {
+	t.sideEffects = true
+	return t
+}
+
+// HasSideEffects reports whether the tool was marked with WithSideEffects.
+func (t *ToolFunc[I, TextOutput]) HasSideEffects() bool {
+	return t.sideEffects
+}
+
+// WithTerminal marks this tool as the final action of a run and returns self for
+// chaining. See [TerminalTool].
+func (t *ToolFunc[I, TextOutput]) WithTerminal() *ToolFunc[I, TextOutput] {
+	t.terminal = true
+	return t
+}
+
+// IsTerminal reports whether the tool was marked with WithTerminal.
+// Implements [TerminalTool].
+func (t *ToolFunc[I, TextOutput]) IsTerminal() bool {
+	return t.terminal
+}
+
+// ValidatesAtParse reports whether the tool was created with [NewDynamicTool].
+// Implements [ParseValidatedTool].
+func (t *ToolFunc[I, TextOutput]) ValidatesAtParse() bool {
+	return t.parseChecked
+}
+
+// WithCategory sets the tool's category and returns self for chaining.
+// See [CategorizedTool].
+func (t *ToolFunc[I, TextOutput]) WithCategory(category string) *ToolFunc[I, TextOutput] {
+	t.category = category
+	return t
+}
+
+// Category returns the category set with WithCategory.
+// Implements [CategorizedTool].
+func (t *ToolFunc[I, TextOutput]) Category() string {
+	return t.category
+}
+
+// WithObservationFormatter sets how this tool's successful results are rendered into
+// the observation, and returns self for chaining. See [ObservationFormatterTool].
+//
+// Example:
+//
+//	tool := gent.NewToolFunc("list_orders", "List the user's orders", schema, listOrders).
+//	    WithObservationFormatter(func(name string, output string) string {
+//	        return "| id | status |\n|----|--------|\n" + output
+//	    })
+func (t *ToolFunc[I, TextOutput]) WithObservationFormatter(
+	formatter func(name string, output string) string,
+) *ToolFunc[I, TextOutput] {
+	t.formatter = formatter
+	return t
+}
+
+// FormatObservation renders output with the formatter set by WithObservationFormatter,
+// and returns false if none is set. Implements [ObservationFormatterTool].
+func (t *ToolFunc[I, TextOutput]) FormatObservation(name, output string) (string, bool) {
+	if t.formatter == nil {
+		return "", false
+	}
+	return t.formatter(name, output), true
+}
+
+// WithValidate sets a validator for cross-field rules the parameter schema cannot
+// express, e.g. end_time after start_time, and returns self for chaining.
+//
+// The validator runs after the ToolChain has checked the arguments against the schema
+// and converted them to I, and before the tool function. A non-nil error is wrapped
+// with [ErrInvalidToolInput] and returned from Call without running the function, so the
+// ToolChain reports it to the model as a tool call error (counted in the
+// SCToolCallsError* stats) and the model can retry with corrected input.
+//
+// Example:
+//
+//	tool := gent.NewToolFunc("book_room", "Book a meeting room", schema, book).
+//	    WithValidate(func(input BookingInput) error {
+//	        if !input.EndTime.After(input.StartTime) {
+//	            return errors.New("end_time must be after start_time")
+//	        }
+//	        return nil
+//	    })
+func (t *ToolFunc[I, TextOutput]) WithValidate(
+	validate func(input I) error,
+) *ToolFunc[I, TextOutput] {
+	t.validate = validate
+	return t
+}
+
 // ParameterSchema returns the JSON Schema for the tool's parameters.
 func (t *ToolFunc[I, TextOutput]) ParameterSchema() map[string]any {
 	return t.schema
 }
 
-// Call executes the tool function with the given typed input.
+// OutputSchema returns the JSON Schema of the tool's output, or nil if unspecified.
+// Implements [OutputSchemaTool].
+func (t *ToolFunc[I, TextOutput]) OutputSchema() map[string]any {
+	return t.outputSchema
+}
+
+// WithOutputSchema sets the output schema shown in the tool catalog and returns self
+// for chaining. Use it to override the schema generated by [NewStructuredToolFunc].
+func (t *ToolFunc[I, TextOutput]) WithOutputSchema(
+	outputSchema map[string]any,
+) *ToolFunc[I, TextOutput] {
+	t.outputSchema = outputSchema
+	return t
+}
+
+// Call executes the tool function with the given typed input, after the validator set
+// with WithValidate accepts it.
 // Media is left nil for functions; use a full Tool implementation for media-producing tools.
 func (t *ToolFunc[I, TextOutput]) Call(
 	ctx context.Context,
 	input I,
 ) (*ToolResult[TextOutput], error) {
+	if t.validate != nil {
+		if err := t.validate(input); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToolInput, err)
+		}
+	}
 	output, err := t.fn(ctx, input)
 	if err != nil {
 		return nil, err
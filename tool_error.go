@@ -0,0 +1,85 @@
+package gent
+
+import "errors"
+
+// ToolErrorCategory tells the model, and the toolchain, how to react to a failed tool
+// call.
+type ToolErrorCategory string
+
+const (
+	// ToolErrorInvalid means the call's arguments were wrong. The model should call the
+	// tool again with different arguments. The tool itself is healthy, so these errors
+	// don't count toward a toolchain's circuit breaker.
+	ToolErrorInvalid ToolErrorCategory = "invalid"
+
+	// ToolErrorTransient means the call failed for a temporary reason, such as a timeout
+	// or a rate limit. The model may retry the same call later.
+	ToolErrorTransient ToolErrorCategory = "transient"
+
+	// ToolErrorPermanent means the call cannot succeed, such as a missing record or a
+	// denied permission. The model should not retry it.
+	ToolErrorPermanent ToolErrorCategory = "permanent"
+)
+
+// ToolError is an error a tool returns to say what kind of failure occurred, so the
+// toolchain can tell the model whether to fix its arguments, retry or give up:
+//
+//	func(ctx context.Context, input RefundInput) (string, error) {
+//	    order, err := orders.Get(ctx, input.OrderID)
+//	    if errors.Is(err, orders.ErrNotFound) {
+//	        return "", gent.NewToolError(gent.ToolErrorInvalid, err).
+//	            WithHint("Look up the order ID with search_orders first.")
+//	    }
+//	    if err != nil {
+//	        return "", gent.NewToolError(gent.ToolErrorTransient, err)
+//	    }
+//	    ...
+//	}
+//
+// Errors that are not a ToolError (see [ToolErrorCategoryOf]) are reported as before,
+// without guidance. ToolError wraps the underlying error, so errors.Is and errors.As
+// checks against it keep working.
+type ToolError struct {
+	// Category is the kind of failure.
+	Category ToolErrorCategory
+
+	// Hint is optional guidance for the model, added to the observation.
+	Hint string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// NewToolError returns a ToolError of the given category wrapping err.
+func NewToolError(category ToolErrorCategory, err error) *ToolError {
+	return &ToolError{Category: category, Err: err}
+}
+
+// WithHint sets the guidance added to the observation and returns the error for chaining.
+func (e *ToolError) WithHint(hint string) *ToolError {
+	e.Hint = hint
+	return e
+}
+
+// Error implements error. It returns the underlying error's message.
+func (e *ToolError) Error() string {
+	if e.Err == nil {
+		return string(e.Category) + " tool error"
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ToolErrorCategoryOf returns the category of the first [ToolError] in err's chain, or ""
+// if there is none.
+func ToolErrorCategoryOf(err error) ToolErrorCategory {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Category
+	}
+	return ""
+}
@@ -13,6 +13,10 @@ type ToolCall struct {
 type RawToolCallResult struct {
 	Name   string // Name of the tool that was called
 	Output any    // Raw typed output (type-erased)
+
+	// Terminal is true if the tool implements [TerminalTool] and the call succeeded,
+	// meaning the run should end with Output as the answer.
+	Terminal bool
 }
 
 // RawToolChainResult contains the raw results of tool execution for programmatic access.
@@ -145,3 +149,54 @@ type ToolChain interface {
 	// Panics if textFormat is nil.
 	Execute(execCtx *ExecutionContext, content string, textFormat TextFormat) (*ToolChainResult, error)
 }
+
+// ToolInfo describes a registered tool, for building prompts programmatically instead of
+// using the rendered [ToolChain.AvailableToolsPrompt].
+type ToolInfo struct {
+	// Name is the tool's identifier used in tool calls.
+	Name string
+
+	// Description is the tool's description for the LLM.
+	Description string
+
+	// Policy is the tool's usage policy, empty if unset.
+	Policy string
+
+	// Category groups related tools, empty if the tool does not implement
+	// [CategorizedTool].
+	Category string
+
+	// Schema is the JSON Schema of the tool's parameters, as shown in the toolchain's
+	// tool catalog and used to validate arguments.
+	Schema map[string]any
+
+	// OutputSchema is the JSON Schema of the tool's output, nil if the tool does not
+	// implement [OutputSchemaTool].
+	OutputSchema map[string]any
+}
+
+// ToolCatalog is an optional interface for toolchains that can list their registered
+// tools, in registration order.
+//
+// Agents expose the list to system prompt builders so they can render the tool catalog
+// in their own layout, e.g. grouped by [ToolInfo.Category]. Toolchains that reveal tools
+// on demand (such as search-based toolchains) need not implement it.
+type ToolCatalog interface {
+	// Tools returns the registered tools.
+	Tools() []ToolInfo
+}
+
+// FilterableToolChain is an optional interface for toolchains that can restrict which
+// registered tools are available, e.g. to unlock refund tools only after the user's
+// identity is verified, or to keep the catalog short when many tools are registered.
+//
+// Agents call SetAvailableTools before building each prompt (see the react agent's
+// WithToolFilter). Unavailable tools are left out of AvailableToolsPrompt, the
+// [ToolCatalog] list and the [SchemaSection] schema, and calls to them fail with
+// [ErrUnknownTool] like calls to unregistered tools.
+type FilterableToolChain interface {
+	// SetAvailableTools makes only the named tools available. Unknown names are ignored.
+	// A nil names makes all registered tools available; an empty non-nil slice makes
+	// none available.
+	SetAvailableTools(names []string)
+}
@@ -0,0 +1,82 @@
+package toolchain
+
+// UnavailableToolMessage returns the observation for a call to a registered tool that
+// the current tool filter excludes, e.g. "refund is not available until the customer's
+// identity is verified". It receives the requested tool name.
+type UnavailableToolMessage func(name string) string
+
+// toolAvailability restricts which registered tools a toolchain exposes. The zero
+// value makes all tools available.
+type toolAvailability struct {
+	names   map[string]bool        // nil means all tools are available
+	message UnavailableToolMessage // nil treats unavailable tools as unknown
+}
+
+// set makes only the named tools available, or all tools if names is nil.
+func (a *toolAvailability) set(names []string) {
+	if names == nil {
+		a.names = nil
+		return
+	}
+	a.names = make(map[string]bool, len(names))
+	for _, name := range names {
+		a.names[name] = true
+	}
+}
+
+// allows reports whether the named tool is available.
+func (a *toolAvailability) allows(name string) bool {
+	return a.names == nil || a.names[name]
+}
+
+// unavailableMessage returns the observation for a call to a registered tool excluded
+// by the filter, and true if such calls get one. It returns false for available or
+// unregistered tools, and when no message is set, so the call is treated as unknown.
+func (a *toolAvailability) unavailableMessage(name string, registered bool) (string, bool) {
+	if !registered || a.message == nil || a.allows(name) {
+		return "", false
+	}
+	return a.message(name), true
+}
+
+// filter returns the available tools, keeping their order.
+func (a *toolAvailability) filter(tools []any) []any {
+	if a.names == nil {
+		return tools
+	}
+	available := make([]any, 0, len(a.names))
+	for _, tool := range tools {
+		meta, err := GetToolMeta(tool)
+		if err == nil && a.allows(meta.Name()) {
+			available = append(available, tool)
+		}
+	}
+	return available
+}
+
+// toolGroup is a set of tools sharing a category.
+type toolGroup struct {
+	category string
+	tools    []*ToolMeta
+}
+
+// groupByCategory groups tools for the tool catalog. Uncategorized tools come first,
+// then each category in order of its first tool. Tools keep their order within a group.
+func groupByCategory(tools []any) []toolGroup {
+	groups := []toolGroup{{}}
+	index := map[string]int{"": 0}
+	for _, tool := range tools {
+		meta, err := GetToolMeta(tool)
+		if err != nil {
+			continue
+		}
+		i, ok := index[meta.Category()]
+		if !ok {
+			i = len(groups)
+			index[meta.Category()] = i
+			groups = append(groups, toolGroup{category: meta.Category()})
+		}
+		groups[i].tools = append(groups[i].tools, meta)
+	}
+	return groups
+}
@@ -0,0 +1,223 @@
+package toolchain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error of a call that an open circuit breaker short-circuited
+// instead of executing (see YAML.WithCircuitBreaker).
+var ErrCircuitOpen = errors.New("tool circuit open")
+
+// DefaultCircuitBreakerCooldown is how long a tripped circuit breaker stays open before
+// it lets a trial call through. Override it with WithCircuitBreakerCooldown.
+const DefaultCircuitBreakerCooldown = 30 * time.Second
+
+// CircuitState is the state of a tool's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls run and records their outcome.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen short-circuits calls with ErrCircuitOpen until the cooldown elapses.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a single trial call run after the cooldown. Its success
+	// closes the circuit; its failure opens it for another cooldown.
+	CircuitHalfOpen
+)
+
+// String returns the state name: "closed", "open" or "half_open".
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// ToolHealth holds the rolling stats of a tool guarded by a circuit breaker, over the
+// breaker's window of most recent executed calls. Calls short-circuited by the breaker,
+// and calls rejected before execution (e.g. invalid arguments), are not counted.
+type ToolHealth struct {
+	// State is the breaker's current state.
+	State CircuitState
+
+	// RecentCalls is the number of calls in the window.
+	RecentCalls int
+
+	// RecentErrors is the number of calls in the window that returned an error.
+	RecentErrors int
+
+	// AverageLatency is the mean duration of the calls in the window.
+	AverageLatency time.Duration
+}
+
+// ErrorRate returns RecentErrors / RecentCalls, or 0 if there are no recent calls.
+func (h ToolHealth) ErrorRate() float64 {
+	if h.RecentCalls == 0 {
+		return 0
+	}
+	return float64(h.RecentErrors) / float64(h.RecentCalls)
+}
+
+// circuitBreakers holds a toolchain's circuit breakers, keyed by tool name. The zero
+// value has no breakers and lets every call run.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	cooldown time.Duration // 0 means DefaultCircuitBreakerCooldown
+	byTool   map[string]*circuitBreaker
+}
+
+// circuitBreaker tracks the recent calls of one tool.
+type circuitBreaker struct {
+	threshold float64       // error rate that opens the circuit
+	window    int           // number of recent calls considered
+	recent    []callOutcome // oldest first, at most window entries
+	state     CircuitState
+	openedAt  time.Time
+}
+
+// callOutcome is the result of one executed call.
+type callOutcome struct {
+	failed  bool
+	latency time.Duration
+}
+
+// set guards the named tool with a breaker that opens once the window holds window calls
+// with an error rate of at least threshold. Panics on a threshold outside (0, 1] or a
+// window below 1.
+func (b *circuitBreakers) set(toolName string, threshold float64, window int) {
+	if threshold <= 0 || threshold > 1 {
+		panic(fmt.Sprintf("toolchain: circuit breaker threshold must be in (0, 1], got %v",
+			threshold))
+	}
+	if window < 1 {
+		panic(fmt.Sprintf("toolchain: circuit breaker window must be at least 1, got %d",
+			window))
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.byTool == nil {
+		b.byTool = make(map[string]*circuitBreaker)
+	}
+	b.byTool[toolName] = &circuitBreaker{threshold: threshold, window: window}
+}
+
+// setCooldown sets how long tripped breakers stay open.
+func (b *circuitBreakers) setCooldown(cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cooldown = cooldown
+}
+
+// allow reports whether a call to the named tool may run at now. An open breaker whose
+// cooldown has elapsed turns half-open and lets this call through as its trial. When
+// the call is refused, the returned error wraps ErrCircuitOpen.
+func (b *circuitBreakers) allow(toolName string, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker := b.byTool[toolName]
+	if breaker == nil {
+		return nil
+	}
+
+	switch breaker.state {
+	case CircuitOpen:
+		if now.Sub(breaker.openedAt) >= b.cooldownLocked() {
+			breaker.state = CircuitHalfOpen
+			return nil
+		}
+	case CircuitHalfOpen:
+		// A trial call is already running
+	default:
+		return nil
+	}
+
+	health := breaker.health()
+	return fmt.Errorf("%w: %s failed %d of its last %d calls",
+		ErrCircuitOpen, toolName, health.RecentErrors, health.RecentCalls)
+}
+
+// record adds the outcome of an executed call to the named tool's breaker, opening or
+// closing the circuit as needed.
+func (b *circuitBreakers) record(
+	toolName string,
+	now time.Time,
+	failed bool,
+	latency time.Duration,
+) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker := b.byTool[toolName]
+	if breaker == nil {
+		return
+	}
+
+	if breaker.state == CircuitHalfOpen {
+		if failed {
+			breaker.state = CircuitOpen
+			breaker.openedAt = now
+			return
+		}
+		// The trial succeeded: start over with a clean window
+		breaker.state = CircuitClosed
+		breaker.recent = nil
+	}
+
+	breaker.recent = append(breaker.recent, callOutcome{failed: failed, latency: latency})
+	if len(breaker.recent) > breaker.window {
+		breaker.recent = breaker.recent[len(breaker.recent)-breaker.window:]
+	}
+	full := len(breaker.recent) == breaker.window
+	if full && breaker.health().ErrorRate() >= breaker.threshold {
+		breaker.state = CircuitOpen
+		breaker.openedAt = now
+	}
+}
+
+// health returns the named tool's rolling stats, and false if it has no breaker.
+func (b *circuitBreakers) health(toolName string) (ToolHealth, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker := b.byTool[toolName]
+	if breaker == nil {
+		return ToolHealth{}, false
+	}
+	return breaker.health(), true
+}
+
+// cooldownLocked returns the configured cooldown. Must be called with b.mu held.
+func (b *circuitBreakers) cooldownLocked() time.Duration {
+	if b.cooldown <= 0 {
+		return DefaultCircuitBreakerCooldown
+	}
+	return b.cooldown
+}
+
+// health summarizes the breaker's window.
+func (c *circuitBreaker) health() ToolHealth {
+	health := ToolHealth{State: c.state, RecentCalls: len(c.recent)}
+	var total time.Duration
+	for _, outcome := range c.recent {
+		if outcome.failed {
+			health.RecentErrors++
+		}
+		total += outcome.latency
+	}
+	if health.RecentCalls > 0 {
+		health.AverageLatency = total / time.Duration(health.RecentCalls)
+	}
+	return health
+}
+
+// circuitOpenObservation returns the observation for a call refused with err.
+func circuitOpenObservation(err error) string {
+	return fmt.Sprintf("Error: %v. Use another tool or try again later.", err)
+}
@@ -0,0 +1,189 @@
+package toolchain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAML_WithCircuitBreaker(t *testing.T) {
+	const openText = "<weather>\nError: tool circuit open: weather failed 2 of its last 2 " +
+		"calls. Use another tool or try again later.\n</weather>"
+
+	type input struct {
+		advance time.Duration // clock advance before the call
+		fail    bool          // whether the tool fails if it runs
+	}
+
+	type expected struct {
+		executed bool
+		text     string
+		health   ToolHealth
+	}
+
+	steps := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "first failure keeps the circuit closed",
+			input: input{fail: true},
+			expected: expected{
+				executed: true,
+				text:     "<weather>\nError: upstream timeout\n</weather>",
+				health: ToolHealth{
+					State: CircuitClosed, RecentCalls: 1, RecentErrors: 1,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "full window of failures opens the circuit",
+			input: input{fail: true},
+			expected: expected{
+				executed: true,
+				text:     "<weather>\nError: upstream timeout\n</weather>",
+				health: ToolHealth{
+					State: CircuitOpen, RecentCalls: 2, RecentErrors: 2,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "open circuit short-circuits the call",
+			input: input{advance: 59 * time.Second},
+			expected: expected{
+				executed: false,
+				text:     openText,
+				health: ToolHealth{
+					State: CircuitOpen, RecentCalls: 2, RecentErrors: 2,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "failed trial after the cooldown reopens the circuit",
+			input: input{advance: time.Second, fail: true},
+			expected: expected{
+				executed: true,
+				text:     "<weather>\nError: upstream timeout\n</weather>",
+				health: ToolHealth{
+					State: CircuitOpen, RecentCalls: 2, RecentErrors: 2,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "cooldown restarts from the failed trial",
+			input: input{advance: 30 * time.Second},
+			expected: expected{
+				executed: false,
+				text:     openText,
+				health: ToolHealth{
+					State: CircuitOpen, RecentCalls: 2, RecentErrors: 2,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "successful trial closes the circuit with a fresh window",
+			input: input{advance: 30 * time.Second},
+			expected: expected{
+				executed: true,
+				text:     "<weather>\nsunny\n</weather>",
+				health: ToolHealth{
+					State: CircuitClosed, RecentCalls: 1, RecentErrors: 0,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+		{
+			name:  "closed circuit runs calls again",
+			input: input{fail: true},
+			expected: expected{
+				executed: true,
+				text:     "<weather>\nError: upstream timeout\n</weather>",
+				health: ToolHealth{
+					State: CircuitClosed, RecentCalls: 2, RecentErrors: 1,
+					AverageLatency: 100 * time.Millisecond,
+				},
+			},
+		},
+	}
+
+	clock := clocktest.NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	executed := false
+	fail := false
+	tc := NewYAML().
+		WithCircuitBreaker("weather", 1, 2).
+		WithCircuitBreakerCooldown(time.Minute)
+	tc.RegisterTool(gent.NewToolFunc(
+		"weather", "Get the weather", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			executed = true
+			clock.Advance(100 * time.Millisecond)
+			if fail {
+				return "", errors.New("upstream timeout")
+			}
+			return "sunny", nil
+		},
+	))
+
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	execCtx.SetClock(clock)
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			clock.Advance(step.input.advance)
+			executed = false
+			fail = step.input.fail
+
+			result, err := tc.Execute(execCtx, "tool: weather", yamlTestFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, step.expected.executed, executed)
+			assert.Equal(t, step.expected.text, result.Text)
+			if step.expected.executed {
+				assert.NotErrorIs(t, result.Raw.Errors[0], ErrCircuitOpen)
+			} else {
+				assert.ErrorIs(t, result.Raw.Errors[0], ErrCircuitOpen)
+			}
+
+			health, ok := tc.ToolHealth("weather")
+			require.True(t, ok)
+			assert.Equal(t, step.expected.health, health)
+		})
+	}
+
+	_, ok := tc.ToolHealth("search")
+	assert.False(t, ok, "tools without a breaker have no health")
+}
+
+func TestJSON_WithCircuitBreaker(t *testing.T) {
+	calls := 0
+	tc := NewJSON().WithCircuitBreaker("weather", 0.5, 1)
+	tc.RegisterTool(gent.NewToolFunc(
+		"weather", "Get the weather", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			calls++
+			return "", errors.New("upstream timeout")
+		},
+	))
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+	content := `[{"tool": "weather"}, {"tool": "weather"}]`
+	result, err := tc.Execute(execCtx, content, testFormat())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+	assert.NotErrorIs(t, result.Raw.Errors[0], ErrCircuitOpen)
+	assert.ErrorIs(t, result.Raw.Errors[1], ErrCircuitOpen)
+	assert.Equal(t, int64(2), execCtx.Stats().GetCounter(gent.SCToolCallsErrorFor+"weather"))
+}
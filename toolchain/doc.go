@@ -18,7 +18,10 @@
 //
 // JSON Schema is used to define expected inputs. The parser produces intermediary types:
 //   - JSON: All values are parsed as JSON types (strings, numbers, booleans, etc.)
-//   - YAML: Uses schema-aware parsing; fields with "type": "string" stay as strings
+//   - YAML: Uses schema-aware parsing; fields with "type": "string" stay as strings,
+//     "type": "boolean" fields accept yes/no/on/off as well as true/false, and null is
+//     kept only for nullable fields (a "null" type), dropped for other optional fields
+//     and rejected with gent.ErrInvalidToolArgs for required ones
 //
 // ## Type Conversion
 //
@@ -32,10 +35,14 @@
 //   - Date only: "2006-01-02"
 //   - Datetime with space: "2006-01-02 15:04:05"
 //
-//   - string -> time.Duration: Parses using time.ParseDuration:
+//   - string -> time.Duration: Parses Go durations and ISO 8601 durations:
 //   - "1h30m" -> 1 hour 30 minutes
 //   - "2h45m30s" -> 2 hours 45 minutes 30 seconds
 //   - "500ms" -> 500 milliseconds
+//   - "PT1H30M" -> 1 hour 30 minutes
+//   - "P2D" -> 48 hours
+//
+//   - string -> url.URL: Parses using url.Parse (user info is dropped)
 //
 //   - If target field is `any` or `map[string]any`, intermediary value is used as-is
 //
@@ -71,6 +78,40 @@
 //	  start_time: 2026-01-20T10:00:00Z
 //	  duration: 1h30m
 //
+// Alternatively, generate the schema from the input struct with [schema.ForInput]. It
+// adds a "format" keyword to well-known types ("date-time" for time.Time, "duration" for
+// time.Duration, "uri" for url.URL), giving schema-aware models a stronger hint:
+//
+//	type ScheduleInput struct {
+//	    EventName string        `json:"event_name" description:"Name of the event"`
+//	    StartTime time.Time     `json:"start_time" description:"Start time"`
+//	    Duration  time.Duration `json:"duration" description:"Meeting length"`
+//	    Organizer string        `json:"organizer" format:"email"`
+//	}
+//
+//	tool := gent.NewToolFunc("schedule_event", "Schedule an event",
+//	    schema.ForInput[ScheduleInput](), scheduleFunc)
+//
+// For schemas only known at runtime, [gent.NewDynamicTool] takes a raw JSON Schema and
+// passes the validated arguments to the handler as map[string]any. Its arguments are
+// validated while the action is parsed, so invalid ones are toolchain parse errors (see
+// [gent.ParseValidatedTool]). [schema.StructFromJSONSchema] builds a struct type from
+// such a schema for handlers that prefer decoding into a struct.
+//
+// # Tool Errors
+//
+// A tool can return a [gent.ToolError] to say whether the model should fix its arguments
+// (ToolErrorInvalid), retry (ToolErrorTransient) or give up on the call
+// (ToolErrorPermanent). The toolchains render its guidance and hint with
+// [ErrorObservation]; other errors are reported as "Error: <message>".
+//
+// # Tool Call Cap
+//
+// WithMaxToolCallsPerIteration caps the tool calls executed from one action section, so
+// a misbehaving model emitting hundreds of calls can't flood the tools. Extra calls are
+// skipped with a "tool_call_limit" observation, or, with [ToolCallOverflowError], the
+// whole action fails to parse with [ErrTooManyToolCalls].
+//
 // # Available ToolChains
 //
 //   - [YAML]: Parses YAML-formatted tool calls with schema-aware type handling
@@ -0,0 +1,24 @@
+package toolchain
+
+import (
+	"fmt"
+
+	"github.com/rickchristie/gent"
+)
+
+// DryRunOutput returns the canned observation used in place of a stubbed tool's output
+// during a dry run.
+func DryRunOutput(toolName string) string {
+	return fmt.Sprintf("[dry-run] would execute %s", toolName)
+}
+
+// shouldStubDryRun reports whether a call to tool must be stubbed: stubbing is enabled,
+// the execution is a dry run, and the tool declares side effects via
+// [gent.SideEffectTool].
+func shouldStubDryRun(execCtx *gent.ExecutionContext, enabled bool, tool any) bool {
+	if !enabled || execCtx == nil || !execCtx.IsDryRun() {
+		return false
+	}
+	sideEffectTool, ok := tool.(gent.SideEffectTool)
+	return ok && sideEffectTool.HasSideEffects()
+}
@@ -82,6 +82,34 @@ func (w *JsToolChainWrapper) RegisterTool(
 	return w
 }
 
+// Tools returns the wrapped ToolChain's tools if it implements [gent.ToolCatalog],
+// nil otherwise.
+func (w *JsToolChainWrapper) Tools() []gent.ToolInfo {
+	if catalog, ok := w.wrapped.(gent.ToolCatalog); ok {
+		return catalog.Tools()
+	}
+	return nil
+}
+
+// SetAvailableTools forwards to the wrapped ToolChain if it
+// implements [gent.FilterableToolChain]. Tool calls made from
+// code go through the wrapped ToolChain, so they respect it.
+func (w *JsToolChainWrapper) SetAvailableTools(names []string) {
+	if filterable, ok := w.wrapped.(gent.FilterableToolChain); ok {
+		filterable.SetAvailableTools(names)
+	}
+}
+
+// Multiplicity forwards to the wrapped ToolChain if it
+// implements [gent.MultiplicitySection], and is
+// [gent.SectionRepeat] otherwise.
+func (w *JsToolChainWrapper) Multiplicity() gent.SectionMultiplicity {
+	if ms, ok := w.wrapped.(gent.MultiplicitySection); ok {
+		return ms.Multiplicity()
+	}
+	return gent.SectionRepeat
+}
+
 // AvailableToolsPrompt returns the wrapped ToolChain's
 // prompt plus a JS environment description.
 func (w *JsToolChainWrapper) AvailableToolsPrompt() string {
@@ -451,3 +479,15 @@ console.log(JSON.stringify({customer, orders}));
 // Compile-time check that JsToolChainWrapper implements
 // gent.ToolChain.
 var _ gent.ToolChain = (*JsToolChainWrapper)(nil)
+
+// Compile-time check that JsToolChainWrapper implements
+// gent.ToolCatalog.
+var _ gent.ToolCatalog = (*JsToolChainWrapper)(nil)
+
+// Compile-time check that JsToolChainWrapper implements
+// gent.FilterableToolChain.
+var _ gent.FilterableToolChain = (*JsToolChainWrapper)(nil)
+
+// Compile-time check that JsToolChainWrapper implements
+// gent.MultiplicitySection.
+var _ gent.MultiplicitySection = (*JsToolChainWrapper)(nil)
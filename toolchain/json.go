@@ -65,10 +65,17 @@ import (
 //	result, err := tc.Execute(execCtx, actionContent, textFormat)
 //	// result.Text contains formatted observation to feed back to the model
 type JSON struct {
-	tools       []any
-	toolMap     map[string]any
-	schemaMap   map[string]*schema.Schema // compiled schemas for validation
-	sectionName string
+	tools        []any
+	toolMap      map[string]any
+	schemaMap    map[string]*schema.Schema // compiled schemas for validation
+	sectionName  string
+	dryRunStubs  bool
+	unknownTool  UnknownToolHandler
+	obsLimits    observationLimits
+	available    toolAvailability
+	multiplicity gent.SectionMultiplicity
+	breakers     circuitBreakers
+	callCap      toolCallCap
 }
 
 // NewJSON creates a new JSON toolchain with default section name "action".
@@ -87,6 +94,110 @@ func (c *JSON) WithSectionName(name string) *JSON {
 	return c
 }
 
+// WithMultiple lets the model emit several action sections in one response, each
+// executed in order (the default).
+func (c *JSON) WithMultiple() *JSON {
+	c.multiplicity = gent.SectionRepeat
+	return c
+}
+
+// WithSingle makes more than one action section in a response a parse error, for
+// agents that expect all tool calls in a single block.
+func (c *JSON) WithSingle() *JSON {
+	c.multiplicity = gent.SectionSingle
+	return c
+}
+
+// Multiplicity reports how repeated action sections are handled.
+// Implements [gent.MultiplicitySection].
+func (c *JSON) Multiplicity() gent.SectionMultiplicity {
+	return c.multiplicity
+}
+
+// WithDryRunStubs enables stubbing of side-effecting tools during a dry run.
+//
+// When enabled and the execution context was created with [gent.WithDryRun], tools
+// implementing [gent.SideEffectTool] are not called. The call returns
+// [DryRunOutput] as its observation instead, so the model continues as if the tool ran.
+// Tools without side effects are always called.
+func (c *JSON) WithDryRunStubs(enabled bool) *JSON {
+	c.dryRunStubs = enabled
+	return c
+}
+
+// WithUnknownToolHandler routes calls to unknown tools to handler instead of failing
+// them with [gent.ErrUnknownTool]. Calls to registered tools that are not available
+// count as unknown, unless WithUnavailableToolMessage is set. The handler's output
+// becomes the call's observation; if it returns an error, the call fails as if no
+// handler were set. See [UnknownToolHandler].
+func (c *JSON) WithUnknownToolHandler(handler UnknownToolHandler) *JSON {
+	c.unknownTool = handler
+	return c
+}
+
+// WithUnavailableToolMessage sets the observation returned when the model calls a
+// registered tool that the current tool filter excludes. See
+// [YAML.WithUnavailableToolMessage].
+func (c *JSON) WithUnavailableToolMessage(message UnavailableToolMessage) *JSON {
+	c.available.message = message
+	return c
+}
+
+// WithMaxObservationBytes limits each tool's formatted output to n bytes.
+// See [YAML.WithMaxObservationBytes].
+func (c *JSON) WithMaxObservationBytes(n int) *JSON {
+	c.obsLimits.maxBytes = n
+	return c
+}
+
+// WithToolMaxObservationBytes overrides WithMaxObservationBytes for a single tool.
+// Zero or less disables truncation for that tool.
+func (c *JSON) WithToolMaxObservationBytes(toolName string, n int) *JSON {
+	c.obsLimits.setFor(toolName, n)
+	return c
+}
+
+// WithMaxCombinedObservationBytes limits the combined output of all tool calls in one
+// iteration to n bytes; see [YAML.WithMaxCombinedObservationBytes].
+func (c *JSON) WithMaxCombinedObservationBytes(n int) *JSON {
+	c.obsLimits.maxCombined = n
+	return c
+}
+
+// WithCircuitBreaker stops calling toolName while its recent calls mostly fail.
+// See [YAML.WithCircuitBreaker].
+func (c *JSON) WithCircuitBreaker(toolName string, threshold float64, window int) *JSON {
+	c.breakers.set(toolName, threshold, window)
+	return c
+}
+
+// WithCircuitBreakerCooldown sets how long a tripped circuit breaker stays open.
+// See [YAML.WithCircuitBreakerCooldown].
+func (c *JSON) WithCircuitBreakerCooldown(cooldown time.Duration) *JSON {
+	c.breakers.setCooldown(cooldown)
+	return c
+}
+
+// WithMaxToolCallsPerIteration caps the tool calls executed in one iteration at n; see
+// [YAML.WithMaxToolCallsPerIteration]. Zero disables the cap, which is the default.
+func (c *JSON) WithMaxToolCallsPerIteration(n int) *JSON {
+	c.callCap.max = n
+	return c
+}
+
+// WithToolCallOverflow sets what happens to an action section whose tool calls exceed
+// what WithMaxToolCallsPerIteration leaves. Default: [ToolCallOverflowTruncate].
+func (c *JSON) WithToolCallOverflow(overflow ToolCallOverflow) *JSON {
+	c.callCap.overflow = overflow
+	return c
+}
+
+// ToolHealth returns the rolling stats of a tool guarded by WithCircuitBreaker.
+// See [YAML.ToolHealth].
+func (c *JSON) ToolHealth(toolName string) (ToolHealth, bool) {
+	return c.breakers.health(toolName)
+}
+
 // Name returns the section identifier.
 func (c *JSON) Name() string {
 	return c.sectionName
@@ -102,38 +213,106 @@ func (c *JSON) Guidance() string {
 	return sb.String()
 }
 
-// AvailableToolsPrompt returns the tool catalog with parameter schemas for each registered tool.
+// Tools returns the available tools in registration order.
+// Implements [gent.ToolCatalog].
+func (c *JSON) Tools() []gent.ToolInfo {
+	return toolInfos(c.available.filter(c.tools))
+}
+
+// SetAvailableTools makes only the named tools available: the tool catalog, Tools and
+// Execute ignore the others. A nil names makes all registered tools available again.
+// Implements [gent.FilterableToolChain].
+func (c *JSON) SetAvailableTools(names []string) {
+	c.available.set(names)
+}
+
+// AvailableToolsPrompt returns the tool catalog with parameter schemas for each available tool.
+// Tools implementing [gent.OutputSchemaTool] also list their output schema. Tools implementing
+// [gent.CategorizedTool] are listed under a "Category:" heading, after uncategorized tools.
 func (c *JSON) AvailableToolsPrompt() string {
 	var sb strings.Builder
 	sb.WriteString("Available tools:\n")
 
-	for _, tool := range c.tools {
-		meta, err := GetToolMeta(tool)
-		if err != nil {
-			continue
-		}
-		fmt.Fprintf(&sb, "\n- %s: %s\n", meta.Name(), meta.Description())
-		if policy := meta.Policy(); policy != "" {
-			sb.WriteString("  Policy: ")
-			sb.WriteString(policy)
-			sb.WriteString("\n")
+	for _, group := range groupByCategory(c.available.filter(c.tools)) {
+		if group.category != "" {
+			fmt.Fprintf(&sb, "\nCategory: %s\n", group.category)
 		}
-		if schema := meta.Schema(); schema != nil {
-			schemaJSON, err := json.MarshalIndent(schema, "  ", "  ")
-			if err == nil {
-				sb.WriteString("  Parameters: ")
-				sb.Write(schemaJSON)
+		for _, meta := range group.tools {
+			fmt.Fprintf(&sb, "\n- %s: %s\n", meta.Name(), meta.Description())
+			if policy := meta.Policy(); policy != "" {
+				sb.WriteString("  Policy: ")
+				sb.WriteString(policy)
 				sb.WriteString("\n")
 			}
+			if schema := meta.Schema(); schema != nil {
+				schemaJSON, err := json.MarshalIndent(schema, "  ", "  ")
+				if err == nil {
+					sb.WriteString("  Parameters: ")
+					sb.Write(schemaJSON)
+					sb.WriteString("\n")
+				}
+			}
+			if outputSchema := meta.OutputSchema(); outputSchema != nil {
+				outputJSON, err := json.MarshalIndent(outputSchema, "  ", "  ")
+				if err == nil {
+					sb.WriteString("  Returns: ")
+					sb.Write(outputJSON)
+					sb.WriteString("\n")
+				}
+			}
 		}
 	}
 
 	return sb.String()
 }
 
+// JSONSchema returns a JSON Schema describing a single tool call or an array of tool
+// calls. Each available tool contributes one variant with its name as a constant and
+// its parameter schema as "args", so constrained decoders can only emit known tools.
+// Implements [gent.SchemaSection].
+func (c *JSON) JSONSchema() map[string]any {
+	tools := c.available.filter(c.tools)
+	variants := make([]any, 0, len(tools))
+	for _, tool := range tools {
+		meta, err := GetToolMeta(tool)
+		if err != nil {
+			continue
+		}
+		args := meta.Schema()
+		if args == nil {
+			args = map[string]any{"type": "object"}
+		}
+		variants = append(variants, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"tool": map[string]any{"type": "string", "const": meta.Name()},
+				"args": args,
+			},
+			"required": []string{"tool", "args"},
+		})
+	}
+
+	call := map[string]any{"anyOf": variants}
+	if len(variants) == 0 {
+		call = map[string]any{"type": "object"}
+	}
+	return map[string]any{
+		"anyOf": []any{
+			call,
+			map[string]any{"type": "array", "items": call},
+		},
+	}
+}
+
 // ParseSection parses the raw text content and returns []*gent.ToolCall.
 func (c *JSON) ParseSection(execCtx *gent.ExecutionContext, content string) (any, error) {
 	result, err := c.doParse(content)
+	if err == nil {
+		err = c.callCap.check(result, iterationToolUsage(execCtx).Calls)
+	}
+	if err == nil {
+		err = validateParsedArgs(result, c.toolMap, c.schemaMap)
+	}
 	if err != nil {
 		// Publish parse error event (auto-updates stats)
 		if execCtx != nil {
@@ -243,7 +422,8 @@ func (c *JSON) Execute(
 		return nil, err
 	}
 
-	calls := parsed.([]*gent.ToolCall)
+	usage := iterationToolUsage(execCtx)
+	calls, skipped := c.callCap.truncate(parsed.([]*gent.ToolCall), usage.Calls)
 	raw := &gent.RawToolChainResult{
 		Calls:   calls,
 		Results: make([]*gent.RawToolCallResult, len(calls)),
@@ -256,7 +436,25 @@ func (c *JSON) Execute(
 
 	for i, call := range calls {
 		tool, ok := c.toolMap[call.Name]
-		if !ok {
+		if message, unavailable := c.available.unavailableMessage(call.Name, ok); unavailable {
+			raw.Errors[i] = fmt.Errorf("%w: %s", gent.ErrToolUnavailable, call.Name)
+			sections = append(sections, gent.FormattedSection{Name: call.Name, Content: message})
+			if execCtx != nil {
+				execCtx.PublishAfterToolCall(call.Name, call.Args, nil, 0, raw.Errors[i])
+			}
+			continue
+		}
+		if !ok || !c.available.allows(call.Name) {
+			if output, handled := callUnknownToolHandler(
+				execCtx, ctx, c.unknownTool, call,
+			); handled {
+				raw.Results[i] = &gent.RawToolCallResult{Name: call.Name, Output: output}
+				sections = append(sections, gent.FormattedSection{
+					Name:    call.Name,
+					Content: c.obsLimits.apply(call.Name, output),
+				})
+				continue
+			}
 			raw.Errors[i] = fmt.Errorf("%w: %s", gent.ErrUnknownTool, call.Name)
 			// Add error as a section
 			sections = append(sections, gent.FormattedSection{
@@ -276,13 +474,36 @@ func (c *JSON) Execute(
 			continue
 		}
 
+		// Tool call loop: answer a repeated identical call without executing it
+		if execCtx != nil {
+			if rep := execCtx.CheckRepeatedToolCall(call.Name, call.Args); rep != nil {
+				output, content, repErr := resolveRepeatedCall(rep, func(v any) (string, error) {
+					data, err := json.Marshal(v)
+					rendered := formatObservation(tool, call.Name, v, string(data))
+					return c.obsLimits.apply(call.Name, rendered), err
+				})
+				if repErr != nil {
+					raw.Errors[i] = repErr
+				} else {
+					raw.Results[i] = &gent.RawToolCallResult{
+						Name:     call.Name,
+						Output:   output,
+						Terminal: isTerminal(tool),
+					}
+				}
+				sections = append(sections, gent.FormattedSection{Name: call.Name, Content: content})
+				execCtx.PublishAfterToolCall(call.Name, call.Args, output, 0, repErr)
+				continue
+			}
+		}
+
 		// Validate args against schema before transformation
 		if compiledSchema, hasSchema := c.schemaMap[call.Name]; hasSchema {
 			if validationErr := compiledSchema.Validate(call.Args); validationErr != nil {
 				raw.Errors[i] = validationErr
 				sections = append(sections, gent.FormattedSection{
 					Name:    call.Name,
-					Content: fmt.Sprintf("Error: %v", validationErr),
+					Content: ErrorObservation(validationErr),
 				})
 
 				if execCtx != nil {
@@ -299,7 +520,7 @@ func (c *JSON) Execute(
 			raw.Errors[i] = transformErr
 			sections = append(sections, gent.FormattedSection{
 				Name:    call.Name,
-				Content: fmt.Sprintf("Error: %v", transformErr),
+				Content: ErrorObservation(transformErr),
 			})
 			if execCtx != nil {
 				execCtx.PublishAfterToolCall(call.Name, call.Args, nil, 0, transformErr)
@@ -314,15 +535,42 @@ func (c *JSON) Execute(
 			inputToUse = beforeEvent.Args
 		}
 
-		startTime := time.Now()
+		// Dry run: stub side-effecting tools without calling them
+		if shouldStubDryRun(execCtx, c.dryRunStubs, tool) {
+			stub := DryRunOutput(call.Name)
+			raw.Results[i] = &gent.RawToolCallResult{
+				Name:     call.Name,
+				Output:   stub,
+				Terminal: isTerminal(tool),
+			}
+			sections = append(sections, gent.FormattedSection{Name: call.Name, Content: stub})
+			execCtx.PublishAfterToolCall(call.Name, inputToUse, stub, 0, nil)
+			continue
+		}
+
+		// Circuit breaker: fail fast while the tool keeps failing
+		if openErr := c.breakers.allow(call.Name, execCtx.Clock().Now()); openErr != nil {
+			raw.Errors[i] = openErr
+			sections = append(sections, gent.FormattedSection{
+				Name:    call.Name,
+				Content: circuitOpenObservation(openErr),
+			})
+			if execCtx != nil {
+				execCtx.PublishAfterToolCall(call.Name, inputToUse, nil, 0, openErr)
+			}
+			continue
+		}
+
+		startTime := execCtx.Clock().Now()
 		output, err := CallToolWithTypedInputReflect(ctx, tool, inputToUse)
-		duration := time.Since(startTime)
+		duration := execCtx.Clock().Now().Sub(startTime)
+		c.breakers.record(call.Name, execCtx.Clock().Now(), countsAsToolFailure(err), duration)
 
 		if err != nil {
 			raw.Errors[i] = err
 			sections = append(sections, gent.FormattedSection{
 				Name:    call.Name,
-				Content: fmt.Sprintf("Error: %v", err),
+				Content: ErrorObservation(err),
 			})
 		} else {
 			// Successful tool call - reset consecutive error gauges
@@ -333,8 +581,9 @@ func (c *JSON) Execute(
 
 			// Store raw result
 			raw.Results[i] = &gent.RawToolCallResult{
-				Name:   output.Name,
-				Output: output.Text,
+				Name:     output.Name,
+				Output:   output.Text,
+				Terminal: isTerminal(tool),
 			}
 
 			// Format output as JSON
@@ -345,19 +594,21 @@ func (c *JSON) Execute(
 					Content: "error: failed to marshal output",
 				})
 			} else {
+				rendered := formatObservation(tool, call.Name, output.Text, string(jsonData))
+				content := c.obsLimits.apply(call.Name, rendered)
 				// If instructions present, create nested sections as children
 				if output.Instructions != "" {
 					sections = append(sections, gent.FormattedSection{
 						Name: call.Name,
 						Children: []gent.FormattedSection{
-							{Name: "result", Content: string(jsonData)},
+							{Name: "result", Content: content},
 							{Name: "instructions", Content: output.Instructions},
 						},
 					})
 				} else {
 					sections = append(sections, gent.FormattedSection{
 						Name:    call.Name,
-						Content: string(jsonData),
+						Content: content,
 					})
 				}
 			}
@@ -378,6 +629,15 @@ func (c *JSON) Execute(
 		}
 	}
 
+	observed := c.obsLimits.capCombined(
+		toolOutputs(sections, raw.Results), usage.ObservationBytes)
+	if execCtx != nil {
+		execCtx.AddIterationToolUsage(len(calls), observed)
+	}
+	if skipped != nil {
+		sections = append(sections, *skipped)
+	}
+
 	// Build formatted text using TextFormat
 	return &gent.ToolChainResult{
 		Text:  textFormat.FormatSections(sections),
@@ -397,5 +657,17 @@ func (c *JSON) GetToolSchema(
 // Compile-time check that JSON implements gent.ToolChain.
 var _ gent.ToolChain = (*JSON)(nil)
 
+// Compile-time check that JSON implements gent.ToolCatalog.
+var _ gent.ToolCatalog = (*JSON)(nil)
+
+// Compile-time check that JSON implements gent.FilterableToolChain.
+var _ gent.FilterableToolChain = (*JSON)(nil)
+
+// Compile-time check that JSON implements gent.MultiplicitySection.
+var _ gent.MultiplicitySection = (*JSON)(nil)
+
 // Compile-time check that JSON implements SchemaProvider.
 var _ SchemaProvider = (*JSON)(nil)
+
+// Compile-time check that JSON implements gent.SchemaSection.
+var _ gent.SchemaSection = (*JSON)(nil)
@@ -138,6 +138,57 @@ For multiple parallel calls, use an array:
 	assert.Equal(t, expectedGuidance, guidance)
 }
 
+func TestJSON_JSONSchema(t *testing.T) {
+	noop := func(ctx context.Context, args map[string]any) (string, error) {
+		return "", nil
+	}
+
+	t.Run("no tools", func(t *testing.T) {
+		call := map[string]any{"type": "object"}
+		expected := map[string]any{
+			"anyOf": []any{call, map[string]any{"type": "array", "items": call}},
+		}
+		assert.Equal(t, expected, NewJSON().JSONSchema())
+	})
+
+	t.Run("one variant per tool", func(t *testing.T) {
+		tc := NewJSON()
+		tc.RegisterTool(gent.NewToolFunc("search", "Search", schema.Object(
+			map[string]*schema.Property{"q": schema.String("Query")}, "q",
+		), noop))
+		tc.RegisterTool(gent.NewToolFunc("ping", "Ping", nil, noop))
+
+		call := map[string]any{"anyOf": []any{
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool": map[string]any{"type": "string", "const": "search"},
+					"args": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"q": map[string]any{"type": "string", "description": "Query"},
+						},
+						"required": []string{"q"},
+					},
+				},
+				"required": []string{"tool", "args"},
+			},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool": map[string]any{"type": "string", "const": "ping"},
+					"args": map[string]any{"type": "object"},
+				},
+				"required": []string{"tool", "args"},
+			},
+		}}
+		expected := map[string]any{
+			"anyOf": []any{call, map[string]any{"type": "array", "items": call}},
+		}
+		assert.Equal(t, expected, tc.JSONSchema())
+	})
+}
+
 func TestJSON_AvailableToolsPrompt(t *testing.T) {
 	type input struct {
 		toolName        string
@@ -203,6 +254,57 @@ func TestJSON_AvailableToolsPrompt(t *testing.T) {
 	}
 }
 
+func TestJSON_StructuredToolFunc(t *testing.T) {
+	tc := NewJSON()
+	tc.RegisterTool(newLookupOrderTool())
+
+	expectedCatalog := `Available tools:
+
+- lookup_order: Look up an order
+  Parameters: {
+    "properties": {
+      "order_id": {
+        "description": "Order ID",
+        "type": "string"
+      }
+    },
+    "required": [
+      "order_id"
+    ],
+    "type": "object"
+  }
+  Returns: {
+    "properties": {
+      "order_id": {
+        "type": "string"
+      },
+      "status": {
+        "description": "Order status",
+        "type": "string"
+      },
+      "tracking": {
+        "type": "string"
+      }
+    },
+    "required": [
+      "order_id",
+      "status"
+    ],
+    "type": "object"
+  }
+`
+	assert.Equal(t, expectedCatalog, tc.AvailableToolsPrompt())
+
+	result, err := tc.Execute(
+		nil, `{"tool": "lookup_order", "args": {"order_id": "A1"}}`, testFormat())
+
+	require.NoError(t, err)
+	assert.Equal(t,
+		"<lookup_order>\n{\"order_id\":\"A1\",\"status\":\"shipped\",\"tracking\":\"1Z999\"}"+
+			"\n</lookup_order>",
+		result.Text)
+}
+
 func TestJSON_ParseSection(t *testing.T) {
 	type input struct {
 		content string
@@ -467,6 +569,130 @@ func TestJSON_Execute(t *testing.T) {
 	}
 }
 
+func TestJSON_Execute_DryRunStubs(t *testing.T) {
+	type input struct {
+		dryRun       bool
+		stubsEnabled bool
+		sideEffects  bool
+	}
+
+	type expected struct {
+		called      bool
+		text        string
+		eventDryRun bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "dry run stubs side-effecting tool",
+			input: input{dryRun: true, stubsEnabled: true, sideEffects: true},
+			expected: expected{
+				called:      false,
+				text:        "<refund>\n[dry-run] would execute refund\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "dry run calls tool without side effects",
+			input: input{dryRun: true, stubsEnabled: true, sideEffects: false},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\n\"refunded A1\"\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "dry run without stubs calls tool",
+			input: input{dryRun: true, stubsEnabled: false, sideEffects: true},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\n\"refunded A1\"\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "regular run calls side-effecting tool",
+			input: input{dryRun: false, stubsEnabled: true, sideEffects: true},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\n\"refunded A1\"\n</refund>",
+				eventDryRun: false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			tool := gent.NewToolFunc(
+				"refund", "Issue a refund", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					called = true
+					return fmt.Sprintf("refunded %v", args["order"]), nil
+				},
+			)
+			if tt.input.sideEffects {
+				tool.WithSideEffects()
+			}
+			tc := NewJSON().WithDryRunStubs(tt.input.stubsEnabled)
+			tc.RegisterTool(tool)
+
+			goCtx := context.Background()
+			if tt.input.dryRun {
+				goCtx = gent.WithDryRun(goCtx)
+			}
+			execCtx := gent.NewExecutionContext(goCtx, "test", nil)
+
+			result, err := tc.Execute(execCtx, `{"tool": "refund", "args": {"order": "A1"}}`, testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.called, called)
+			assert.Equal(t, tt.expected.text, result.Text)
+			assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCToolCalls))
+
+			var afterEvents []*gent.AfterToolCallEvent
+			for _, event := range execCtx.Events() {
+				if after, ok := event.(*gent.AfterToolCallEvent); ok {
+					afterEvents = append(afterEvents, after)
+				}
+			}
+			require.Len(t, afterEvents, 1)
+			assert.Equal(t, tt.expected.eventDryRun, afterEvents[0].DryRun)
+		})
+	}
+}
+
+func TestJSON_Execute_MaxObservationBytes(t *testing.T) {
+	tc := NewJSON().
+		WithMaxObservationBytes(6).
+		WithToolMaxObservationBytes("status", 0)
+	tc.RegisterTool(gent.NewToolFunc(
+		"fetch", "Fetch a document", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			return "0123456789", nil
+		},
+	))
+	tc.RegisterTool(gent.NewToolFunc(
+		"status", "Get status", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			return "all systems go", nil
+		},
+	))
+
+	result, err := tc.Execute(
+		nil, `[{"tool": "fetch", "args": {}}, {"tool": "status", "args": {}}]`, testFormat())
+	require.NoError(t, err)
+
+	expected := "<fetch>\n\"01234\n[truncated 6 of 12 bytes]\n</fetch>\n" +
+		"<status>\n\"all systems go\"\n</status>"
+	assert.Equal(t, expected, result.Text)
+	assert.Equal(t, "0123456789", result.Raw.Results[0].Output)
+}
+
 func TestJSON_Execute_SchemaValidation(t *testing.T) {
 	type mockTool struct {
 		name        string
@@ -680,6 +906,106 @@ func TestJSON_Execute_SchemaValidation(t *testing.T) {
 	}
 }
 
+func TestJSON_Execute_DynamicTool(t *testing.T) {
+	rawSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":  map[string]any{"type": "string"},
+			"units": map[string]any{"type": "string", "enum": []any{"metric", "imperial"}},
+			"days":  map[string]any{"type": "integer", "minimum": 1, "maximum": 7},
+		},
+		"required": []any{"city"},
+	}
+
+	type expected struct {
+		args        map[string]any
+		errContains string
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name:  "valid args reach the handler",
+			input: `{"tool": "forecast", "args": {"city": "Oslo", "units": "metric", "days": 3}}`,
+			expected: expected{
+				args: map[string]any{"city": "Oslo", "units": "metric", "days": float64(3)},
+			},
+		},
+		{
+			name:     "missing required property",
+			input:    `{"tool": "forecast", "args": {"units": "metric"}}`,
+			expected: expected{errContains: "missing property 'city'"},
+		},
+		{
+			name:     "value outside enum",
+			input:    `{"tool": "forecast", "args": {"city": "Oslo", "units": "kelvin"}}`,
+			expected: expected{errContains: "schema validation failed"},
+		},
+		{
+			name:     "value above maximum",
+			input:    `{"tool": "forecast", "args": {"city": "Oslo", "days": 30}}`,
+			expected: expected{errContains: "schema validation failed"},
+		},
+		{
+			name:     "wrong type",
+			input:    `{"tool": "forecast", "args": {"city": 42}}`,
+			expected: expected{errContains: "schema validation failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]any
+			tool, err := gent.NewDynamicTool(
+				"forecast",
+				"Weather forecast",
+				rawSchema,
+				func(ctx context.Context, args map[string]any) (any, error) {
+					received = args
+					return map[string]any{"summary": "sunny"}, nil
+				},
+			)
+			require.NoError(t, err)
+
+			tc := NewJSON()
+			tc.RegisterTool(tool)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			result, err := tc.Execute(execCtx, tt.input, testFormat())
+
+			assert.Equal(t, tt.expected.args, received)
+			if tt.expected.errContains == "" {
+				require.NoError(t, err)
+				assert.NoError(t, result.Raw.Errors[0])
+				assert.Equal(t, "<forecast>\n{\"summary\":\"sunny\"}\n</forecast>", result.Text)
+				return
+			}
+
+			// Invalid arguments fail parsing: a toolchain parse error, not a tool error
+			assert.ErrorIs(t, err, gent.ErrInvalidToolInput)
+			assert.ErrorContains(t, err, tt.expected.errContains)
+			assert.Nil(t, result)
+			assert.Equal(t, int64(1),
+				execCtx.Stats().GetCounter(gent.SCToolchainParseErrorTotal))
+			assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCToolCallsErrorTotal))
+		})
+	}
+}
+
+func TestNewDynamicTool_InvalidSchema(t *testing.T) {
+	tool, err := gent.NewDynamicTool(
+		"forecast",
+		"Weather forecast",
+		map[string]any{"type": "object", "properties": map[string]any{"days": 7}},
+		func(ctx context.Context, args map[string]any) (any, error) { return nil, nil },
+	)
+
+	assert.Nil(t, tool)
+	assert.ErrorContains(t, err, `tool "forecast": invalid parameter schema`)
+}
+
 func TestJSON_ParseSection_DateAsString(t *testing.T) {
 	type input struct {
 		content string
@@ -977,6 +1303,130 @@ func TestJSON_Execute_DurationConversion(t *testing.T) {
 	}
 }
 
+func TestJSON_Tools(t *testing.T) {
+	noop := func(_ context.Context, _ map[string]any) (string, error) { return "", nil }
+	params := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"order_id": map[string]any{"type": "string"}},
+	}
+	output := map[string]any{"type": "string"}
+
+	tests := []struct {
+		name     string
+		input    []any
+		expected []gent.ToolInfo
+	}{
+		{
+			name:     "no tools",
+			input:    nil,
+			expected: []gent.ToolInfo{},
+		},
+		{
+			name: "metadata in registration order",
+			input: []any{
+				gent.NewToolFunc("refund", "Refund an order", params, noop).
+					WithPolicy("Only refund delivered orders").
+					WithCategory("billing").
+					WithOutputSchema(output),
+				gent.NewToolFunc("ping", "Check connectivity", nil, noop),
+			},
+			expected: []gent.ToolInfo{
+				{
+					Name:         "refund",
+					Description:  "Refund an order",
+					Policy:       "Only refund delivered orders",
+					Category:     "billing",
+					Schema:       params,
+					OutputSchema: output,
+				},
+				{Name: "ping", Description: "Check connectivity"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewJSON()
+			for _, tool := range tc.input {
+				chain.RegisterTool(tool)
+			}
+
+			assert.Equal(t, tc.expected, chain.Tools())
+			assert.Equal(t, tc.expected, NewJsToolChainWrapper(chain).Tools(),
+				"JS wrapper should list the wrapped tools")
+		})
+	}
+}
+
+func TestJSON_SetAvailableTools(t *testing.T) {
+	type expected struct {
+		tools       []string
+		schemaTools []any
+		output      string
+		err         error
+	}
+
+	tests := []struct {
+		name     string
+		input    []string // available tools
+		expected expected
+	}{
+		{
+			name:  "nil makes all tools available",
+			input: nil,
+			expected: expected{
+				tools:       []string{"lookup", "refund"},
+				schemaTools: []any{"lookup", "refund"},
+				output:      "refunded",
+			},
+		},
+		{
+			name:  "unavailable tool is unknown",
+			input: []string{"lookup"},
+			expected: expected{
+				tools:       []string{"lookup"},
+				schemaTools: []any{"lookup"},
+				err:         gent.ErrUnknownTool,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewJSON()
+			for _, name := range []string{"lookup", "refund"} {
+				chain.RegisterTool(gent.NewToolFunc(name, name+" tool", nil,
+					func(_ context.Context, _ map[string]any) (string, error) {
+						return name + "ed", nil
+					},
+				))
+			}
+			chain.SetAvailableTools(tc.input)
+
+			var tools []string
+			for _, info := range chain.Tools() {
+				tools = append(tools, info.Name)
+			}
+			assert.Equal(t, tc.expected.tools, tools)
+
+			var schemaTools []any
+			variants := chain.JSONSchema()["anyOf"].([]any)[0].(map[string]any)["anyOf"]
+			for _, variant := range variants.([]any) {
+				props := variant.(map[string]any)["properties"].(map[string]any)
+				schemaTools = append(schemaTools, props["tool"].(map[string]any)["const"])
+			}
+			assert.Equal(t, tc.expected.schemaTools, schemaTools)
+
+			result, err := chain.Execute(nil, `{"tool": "refund", "args": {}}`, yamlTestFormat())
+			require.NoError(t, err)
+			assert.ErrorIs(t, result.Raw.Errors[0], tc.expected.err)
+			if tc.expected.output != "" {
+				assert.Equal(t, tc.expected.output, result.Raw.Results[0].Output)
+			}
+		})
+	}
+}
+
 func TestJSON_AvailableToolsPrompt_SchemaFeatures(t *testing.T) {
 	type input struct {
 		toolName        string
@@ -1290,6 +1740,68 @@ func TestJSON_AvailableToolsPrompt_SchemaFeatures(t *testing.T) {
 	}
 }
 
+func TestJSON_AvailableToolsPrompt_SortedKeys(t *testing.T) {
+	// Properties are declared out of order; the catalog lists them sorted, so the
+	// prompt (and any cached prefix containing it) is byte-identical across runs
+	tc := NewJSON()
+	tc.RegisterTool(gent.NewToolFunc(
+		"create_order",
+		"Create an order",
+		schema.Object(map[string]*schema.Property{
+			"zeta":     schema.String("Last alphabetically"),
+			"alpha":    schema.Integer("First alphabetically"),
+			"customer": schema.String("Customer ID"),
+			"items":    schema.Array("Line items", map[string]any{"type": "string"}),
+			"priority": schema.String("Priority").Enum("low", "high"),
+		}, "customer", "items"),
+		func(ctx context.Context, args map[string]any) (string, error) {
+			return "", nil
+		},
+	))
+
+	expectedCatalog := `Available tools:
+
+- create_order: Create an order
+  Parameters: {
+    "properties": {
+      "alpha": {
+        "description": "First alphabetically",
+        "type": "integer"
+      },
+      "customer": {
+        "description": "Customer ID",
+        "type": "string"
+      },
+      "items": {
+        "description": "Line items",
+        "items": {
+          "type": "string"
+        },
+        "type": "array"
+      },
+      "priority": {
+        "description": "Priority",
+        "enum": [
+          "low",
+          "high"
+        ],
+        "type": "string"
+      },
+      "zeta": {
+        "description": "Last alphabetically",
+        "type": "string"
+      }
+    },
+    "required": [
+      "customer",
+      "items"
+    ],
+    "type": "object"
+  }
+`
+	assert.Equal(t, expectedCatalog, tc.AvailableToolsPrompt())
+}
+
 func TestJSON_AvailableToolsPrompt_MultipleTools(t *testing.T) {
 	type mockTool struct {
 		name        string
@@ -2456,3 +2968,88 @@ func TestJSON_UnknownToolErrorMessage(t *testing.T) {
 			`section for valid tool names.`,
 	)
 }
+
+func TestJSON_Execute_UnknownToolHandler(t *testing.T) {
+	type input struct {
+		handler UnknownToolHandler
+	}
+
+	type expected struct {
+		output      any
+		err         error
+		textContain string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "handler observation is returned",
+			input: input{
+				handler: func(ctx context.Context, name string, args map[string]any) (string, error) {
+					return fmt.Sprintf("no such tool %s (q=%v)", name, args["q"]), nil
+				},
+			},
+			expected: expected{
+				output:      "no such tool lookup (q=go)",
+				textContain: "no such tool lookup (q=go)",
+			},
+		},
+		{
+			name: "handler error falls back to unknown tool error",
+			input: input{
+				handler: func(ctx context.Context, name string, args map[string]any) (string, error) {
+					return "", errors.New("declined")
+				},
+			},
+			expected: expected{
+				err:         gent.ErrUnknownTool,
+				textContain: `Error: unknown tool "lookup".`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewJSON().WithUnknownToolHandler(tc.input.handler)
+
+			result, err := chain.Execute(
+				nil, `{"tool": "lookup", "args": {"q": "go"}}`, testFormat())
+			require.NoError(t, err)
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, result.Raw.Errors[0], tc.expected.err)
+			} else {
+				assert.NoError(t, result.Raw.Errors[0])
+				require.NotNil(t, result.Raw.Results[0])
+				assert.Equal(t, tc.expected.output, result.Raw.Results[0].Output)
+			}
+			assert.Contains(t, result.Text, tc.expected.textContain)
+		})
+	}
+}
+
+func TestJSON_Execute_UnavailableToolMessage(t *testing.T) {
+	chain := NewJSON().WithUnavailableToolMessage(func(name string) string {
+		return name + " is not available until the customer is verified."
+	})
+	chain.RegisterTool(gent.NewToolFunc(
+		"refund", "Refunds an order", nil,
+		func(ctx context.Context, args map[string]any) (string, error) {
+			return "refunded", nil
+		},
+	))
+	chain.SetAvailableTools([]string{})
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+	result, err := chain.Execute(
+		execCtx, `{"tool": "refund", "args": {"order": "42"}}`, testFormat())
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, result.Raw.Errors[0], gent.ErrToolUnavailable)
+	assert.Contains(t, result.Text, "refund is not available until the customer is verified.")
+	assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCToolCallsUnavailable))
+	assert.Equal(t, int64(0), execCtx.Stats().GetCounter(gent.SCToolCallsUnknown))
+}
@@ -0,0 +1,32 @@
+package toolchain
+
+import (
+	"fmt"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/schema"
+)
+
+// validateParsedArgs validates the arguments of parsed calls to tools implementing
+// [gent.ParseValidatedTool] against their compiled schemas. The error of the first
+// invalid call wraps [gent.ErrInvalidToolInput] and the schema validation error.
+func validateParsedArgs(
+	calls []*gent.ToolCall,
+	toolMap map[string]any,
+	schemaMap map[string]*schema.Schema,
+) error {
+	for _, call := range calls {
+		tool, ok := toolMap[call.Name].(gent.ParseValidatedTool)
+		if !ok || !tool.ValidatesAtParse() {
+			continue
+		}
+		compiled, ok := schemaMap[call.Name]
+		if !ok {
+			continue
+		}
+		if err := compiled.Validate(call.Args); err != nil {
+			return fmt.Errorf("%w: tool %q: %w", gent.ErrInvalidToolInput, call.Name, err)
+		}
+	}
+	return nil
+}
@@ -5,20 +5,27 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/internal/fields"
 )
 
 // ToolMeta holds metadata about a registered tool extracted via reflection.
 type ToolMeta struct {
-	name        string
-	description string
-	policy      string
-	schema      map[string]any
-	tool        any          // The actual tool (Tool[I, O])
-	inputType   reflect.Type // The input type I
+	name         string
+	description  string
+	policy       string
+	category     string
+	schema       map[string]any
+	outputSchema map[string]any
+	tool         any          // The actual tool (Tool[I, O])
+	inputType    reflect.Type // The input type I
 }
 
 // Name returns the tool's name.
@@ -30,18 +37,52 @@ func (m *ToolMeta) Description() string { return m.description }
 // Policy returns the tool's usage policy.
 func (m *ToolMeta) Policy() string { return m.policy }
 
+// Category returns the tool's category, or "" if the tool doesn't implement
+// [gent.CategorizedTool].
+func (m *ToolMeta) Category() string { return m.category }
+
 // Schema returns the tool's parameter schema.
 func (m *ToolMeta) Schema() map[string]any { return m.schema }
 
+// OutputSchema returns the tool's output schema, or nil if the tool doesn't implement
+// [gent.OutputSchemaTool].
+func (m *ToolMeta) OutputSchema() map[string]any { return m.outputSchema }
+
 // Tool returns the actual tool.
 func (m *ToolMeta) Tool() any { return m.tool }
 
+// Info returns the tool's metadata as a [gent.ToolInfo].
+func (m *ToolMeta) Info() gent.ToolInfo {
+	return gent.ToolInfo{
+		Name:         m.name,
+		Description:  m.description,
+		Policy:       m.policy,
+		Category:     m.category,
+		Schema:       m.schema,
+		OutputSchema: m.outputSchema,
+	}
+}
+
+// toolInfos returns the metadata of tools, skipping tools whose metadata cannot be read.
+func toolInfos(tools []any) []gent.ToolInfo {
+	infos := make([]gent.ToolInfo, 0, len(tools))
+	for _, tool := range tools {
+		meta, err := GetToolMeta(tool)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, meta.Info())
+	}
+	return infos
+}
+
 // TransformArgsReflect transforms raw args (map[string]any) to the tool's typed input.
 //
 // It converts args to the tool's input type. The conversion handles type coercion from
 // JSON Schema intermediary types to Go types:
 //   - string -> time.Time: Parses using common date/time formats (RFC3339, date-only, etc.)
-//   - string -> time.Duration: Parses using time.ParseDuration (e.g., "1h30m", "2h45m30s")
+//   - string -> time.Duration: Parses Go durations (e.g., "1h30m") and ISO 8601 (e.g., "PT1H30M")
+//   - string -> url.URL: Parses using url.Parse
 //   - If target field is `any`, the intermediary value is used as-is
 //
 // Returns the typed input as `any`. The actual type is the tool's input type I.
@@ -89,10 +130,11 @@ func TransformArgsReflect(tool any, args map[string]any) (any, error) {
 	// Marshal args to JSON, then unmarshal into input
 	argsJSON, err := json.Marshal(convertedArgs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal args: %w", err)
+		return nil, fmt.Errorf("%w: failed to marshal args: %w", gent.ErrInvalidToolInput, err)
 	}
 	if err := json.Unmarshal(argsJSON, inputVal.Interface()); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal args into input type: %w", err)
+		return nil, fmt.Errorf("%w: failed to unmarshal args into input type: %w",
+			gent.ErrInvalidToolInput, err)
 	}
 
 	// Return the actual value (pointer or value depending on input type)
@@ -142,9 +184,9 @@ func CallToolWithTypedInputReflect(
 	nameResult := nameMethod.Call(nil)
 	toolName := nameResult[0].String()
 
-	// Call the method
+	// Call the method, letting the tool attribute progress reports to itself
 	results := callMethod.Call([]reflect.Value{
-		reflect.ValueOf(ctx),
+		reflect.ValueOf(gent.ContextWithToolName(ctx, toolName)),
 		reflect.ValueOf(typedInput),
 	})
 
@@ -190,7 +232,8 @@ func CallToolWithTypedInputReflect(
 // It converts args (map[string]any) to the tool's input type. The conversion handles
 // type coercion from JSON Schema intermediary types to Go types:
 //   - string -> time.Time: Parses using common date/time formats (RFC3339, date-only, etc.)
-//   - string -> time.Duration: Parses using time.ParseDuration (e.g., "1h30m", "2h45m30s")
+//   - string -> time.Duration: Parses Go durations (e.g., "1h30m") and ISO 8601 (e.g., "PT1H30M")
+//   - string -> url.URL: Parses using url.Parse
 //   - If target field is `any`, the intermediary value is used as-is
 //
 // This is a convenience function that combines TransformArgsReflect and
@@ -210,7 +253,8 @@ func CallToolReflect(
 // convertArgsForType converts intermediary arg values to match the expected Go types
 // in the target struct. This handles conversions that Go's json package doesn't support:
 //   - string -> time.Time (various formats)
-//   - string -> time.Duration
+//   - string -> time.Duration (Go and ISO 8601 durations)
+//   - string -> url.URL
 func convertArgsForType(args map[string]any, structType reflect.Type) map[string]any {
 	if args == nil || structType.Kind() != reflect.Struct {
 		return args
@@ -234,42 +278,24 @@ func convertValueForField(value any, structType reflect.Type, fieldName string)
 	return convertValueToType(value, field.Type)
 }
 
-// findFieldByName finds a struct field by name or json tag.
+// findFieldByName finds the struct field that encoding/json decodes the key name into,
+// including fields promoted from embedded structs. Exact name matches win over
+// case-insensitive ones, as in encoding/json.
 func findFieldByName(structType reflect.Type, name string) (reflect.StructField, bool) {
-	for i := range structType.NumField() {
-		field := structType.Field(i)
-
-		// Check json tag first
-		jsonTag := field.Tag.Get("json")
-		if jsonTag != "" {
-			// Handle "fieldname,omitempty" style tags
-			tagName := jsonTag
-			if comma := findComma(jsonTag); comma >= 0 {
-				tagName = jsonTag[:comma]
-			}
-			if tagName == name {
-				return field, true
-			}
+	visible := fields.Of(structType, "json")
+	for _, field := range visible {
+		if field.Name == name {
+			return field.Field, true
 		}
-
-		// Check field name (case-insensitive for JSON compatibility)
+	}
+	for _, field := range visible {
 		if equalFold(field.Name, name) {
-			return field, true
+			return field.Field, true
 		}
 	}
 	return reflect.StructField{}, false
 }
 
-// findComma returns the index of the first comma in s, or -1 if not found.
-func findComma(s string) int {
-	for i := range len(s) {
-		if s[i] == ',' {
-			return i
-		}
-	}
-	return -1
-}
-
 // equalFold reports whether s and t are equal under case-folding.
 func equalFold(s, t string) bool {
 	if len(s) != len(t) {
@@ -322,7 +348,7 @@ func convertValueToType(value any, targetType reflect.Type) any {
 	// Check for time.Duration
 	if elemType == reflect.TypeOf(time.Duration(0)) {
 		if str, ok := value.(string); ok {
-			if d, err := time.ParseDuration(str); err == nil {
+			if d, err := parseDuration(str); err == nil {
 				// JSON unmarshal expects nanoseconds as int64
 				return d.Nanoseconds()
 			}
@@ -330,6 +356,16 @@ func convertValueToType(value any, targetType reflect.Type) any {
 		return value
 	}
 
+	// Check for url.URL
+	if elemType == reflect.TypeOf(url.URL{}) {
+		if str, ok := value.(string); ok {
+			if u, err := url.Parse(str); err == nil {
+				return urlFields(u)
+			}
+		}
+		return value
+	}
+
 	// Handle nested structs
 	if elemType.Kind() == reflect.Struct {
 		if m, ok := value.(map[string]any); ok {
@@ -375,6 +411,62 @@ func parseTime(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse time: %s", s)
 }
 
+// isoDurationPattern matches ISO 8601 durations made of weeks, days, hours, minutes and
+// seconds. Years and months are rejected because their length varies.
+var isoDurationPattern = regexp.MustCompile(
+	`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`,
+)
+
+// parseDuration parses a Go duration string ("1h30m") or an ISO 8601 duration
+// ("PT1H30M", "P2D").
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	match := isoDurationPattern.FindStringSubmatch(s)
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("unable to parse duration: %s", s)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse duration: %s", s)
+		}
+		total += time.Duration(n) * unit
+	}
+	if match[5] != "" {
+		seconds, err := strconv.ParseFloat(match[5], 64)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse duration: %s", s)
+		}
+		total += time.Duration(seconds * float64(time.Second))
+	}
+
+	return total, nil
+}
+
+// urlFields converts u to the object form encoding/json uses for url.URL, which has no
+// JSON unmarshaler of its own. User info is not carried over.
+func urlFields(u *url.URL) map[string]any {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	delete(fields, "User")
+	return fields
+}
+
 // GetToolMeta extracts metadata from a generic Tool[I, O] using reflection.
 func GetToolMeta(tool any) (*ToolMeta, error) {
 	toolVal := reflect.ValueOf(tool)
@@ -425,12 +517,26 @@ func GetToolMeta(tool any) (*ToolMeta, error) {
 	}
 	inputType := callType.In(1)
 
+	// Get OutputSchema (optional)
+	var outputSchema map[string]any
+	if outputSchemaTool, ok := tool.(gent.OutputSchemaTool); ok {
+		outputSchema = outputSchemaTool.OutputSchema()
+	}
+
+	// Get Category (optional)
+	var category string
+	if categorizedTool, ok := tool.(gent.CategorizedTool); ok {
+		category = categorizedTool.Category()
+	}
+
 	return &ToolMeta{
-		name:        name,
-		description: description,
-		policy:      policy,
-		schema:      schema,
-		tool:        tool,
-		inputType:   inputType,
+		name:         name,
+		description:  description,
+		policy:       policy,
+		category:     category,
+		schema:       schema,
+		outputSchema: outputSchema,
+		tool:         tool,
+		inputType:    inputType,
 	}, nil
 }
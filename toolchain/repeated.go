@@ -0,0 +1,56 @@
+package toolchain
+
+import (
+	"fmt"
+
+	"github.com/rickchristie/gent"
+)
+
+// RepeatedCallNudge returns the observation used in place of a repeated tool call's
+// output with [gent.RepeatedToolCallNudge]. prior is the formatted result of the last
+// executed identical call.
+func RepeatedCallNudge(toolName string, count int, prior string) string {
+	return fmt.Sprintf(
+		"You already called %s with these exact arguments %d times in a row, so it was "+
+			"not called again. The previous result was:\n%s\n"+
+			"Use this result, or try a different tool or different arguments.",
+		toolName, count, prior,
+	)
+}
+
+// resolveRepeatedCall handles a call flagged by ExecutionContext.CheckRepeatedToolCall
+// without executing it. It returns the output to report for the call, the formatted
+// observation, and the call error. format renders a tool output the way the toolchain
+// formats regular results.
+func resolveRepeatedCall(
+	rep *gent.RepeatedToolCall,
+	format func(output any) (string, error),
+) (any, string, error) {
+	prior := formatPrior(rep, format)
+
+	switch rep.Action {
+	case gent.RepeatedToolCallTerminate:
+		err := rep.Err()
+		return nil, fmt.Sprintf("Error: %v", err), err
+	case gent.RepeatedToolCallCachedResult:
+		if rep.PriorError != nil {
+			return nil, prior, rep.PriorError
+		}
+		return rep.PriorOutput, prior, nil
+	default:
+		nudge := RepeatedCallNudge(rep.ToolName, rep.Count, prior)
+		return nudge, nudge, nil
+	}
+}
+
+// formatPrior renders the prior result of a repeated call.
+func formatPrior(rep *gent.RepeatedToolCall, format func(output any) (string, error)) string {
+	if rep.PriorError != nil {
+		return ErrorObservation(rep.PriorError)
+	}
+	content, err := format(rep.PriorOutput)
+	if err != nil {
+		return "error: failed to marshal output"
+	}
+	return content
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/rickchristie/gent"
 	"github.com/rickchristie/gent/schema"
@@ -67,6 +66,14 @@ const (
 //
 //	agent := react.NewAgent(model).
 //	    WithToolChain(tc)
+//
+// # Circuit Breakers
+//
+// SearchJSON has no circuit breaker (see
+// [YAML.WithCircuitBreaker]): every call to a
+// discovered tool is executed, however often it
+// fails. Guard flaky tools inside the tool itself,
+// or use the YAML or JSON toolchain.
 type SearchJSON struct {
 	mu sync.RWMutex
 
@@ -90,6 +97,10 @@ type SearchJSON struct {
 	pinnedToolNames  []string
 	pageSize         int
 	noResultsMessage string
+	dryRunStubs      bool
+	unknownTool      UnknownToolHandler
+	obsLimits        observationLimits
+	callCap          toolCallCap
 
 	// Computed by Initialize()
 	initialized          bool
@@ -126,6 +137,65 @@ func (c *SearchJSON) WithSectionName(
 	return c
 }
 
+// WithDryRunStubs enables stubbing of side-effecting
+// tools during a dry run. See [YAML.WithDryRunStubs].
+func (c *SearchJSON) WithDryRunStubs(
+	enabled bool,
+) *SearchJSON {
+	c.dryRunStubs = enabled
+	return c
+}
+
+// WithUnknownToolHandler routes calls to unknown tools
+// to handler. See [YAML.WithUnknownToolHandler].
+func (c *SearchJSON) WithUnknownToolHandler(
+	handler UnknownToolHandler,
+) *SearchJSON {
+	c.unknownTool = handler
+	return c
+}
+
+// WithMaxObservationBytes limits each tool's formatted
+// output to n bytes. See [YAML.WithMaxObservationBytes].
+func (c *SearchJSON) WithMaxObservationBytes(
+	n int,
+) *SearchJSON {
+	c.obsLimits.maxBytes = n
+	return c
+}
+
+// WithToolMaxObservationBytes overrides
+// WithMaxObservationBytes for a single tool. Zero or less
+// disables truncation for that tool.
+func (c *SearchJSON) WithToolMaxObservationBytes(
+	toolName string,
+	n int,
+) *SearchJSON {
+	c.obsLimits.setFor(toolName, n)
+	return c
+}
+
+// WithMaxToolCallsPerIteration caps the tool calls
+// executed in one iteration at n, search calls included.
+// See [YAML.WithMaxToolCallsPerIteration].
+func (c *SearchJSON) WithMaxToolCallsPerIteration(
+	n int,
+) *SearchJSON {
+	c.callCap.max = n
+	return c
+}
+
+// WithToolCallOverflow sets what happens to an action
+// section holding more tool calls than
+// WithMaxToolCallsPerIteration allows. Default:
+// [ToolCallOverflowTruncate].
+func (c *SearchJSON) WithToolCallOverflow(
+	overflow ToolCallOverflow,
+) *SearchJSON {
+	c.callCap.overflow = overflow
+	return c
+}
+
 // WithPageSize sets the number of tools per search page.
 func (c *SearchJSON) WithPageSize(
 	size int,
@@ -325,6 +395,16 @@ func (c *SearchJSON) ParseSection(
 	content string,
 ) (any, error) {
 	result, err := c.doParse(content)
+	if err == nil {
+		err = c.callCap.check(
+			result, iterationToolUsage(execCtx).Calls,
+		)
+	}
+	if err == nil {
+		c.mu.RLock()
+		err = validateParsedArgs(result, c.toolMap, c.schemaMap)
+		c.mu.RUnlock()
+	}
 	if err != nil {
 		if execCtx != nil {
 			execCtx.PublishParseError(
@@ -428,7 +508,10 @@ func (c *SearchJSON) Execute(
 		return nil, err
 	}
 
-	calls := parsed.([]*gent.ToolCall)
+	calls, skipped := c.callCap.truncate(
+		parsed.([]*gent.ToolCall),
+		iterationToolUsage(execCtx).Calls,
+	)
 	raw := &gent.RawToolChainResult{
 		Calls:   calls,
 		Results: make([]*gent.RawToolCallResult, len(calls)),
@@ -453,6 +536,15 @@ func (c *SearchJSON) Execute(
 		}
 	}
 
+	// SearchJSON has no combined observation limit: only
+	// the calls count toward the iteration's usage
+	if execCtx != nil {
+		execCtx.AddIterationToolUsage(len(calls), 0)
+	}
+	if skipped != nil {
+		sections = append(sections, *skipped)
+	}
+
 	return &gent.ToolChainResult{
 		Text:  textFormat.FormatSections(sections),
 		Media: allMedia,
@@ -522,7 +614,7 @@ func (c *SearchJSON) executeSearch(
 		}
 	}
 
-	startTime := time.Now()
+	startTime := execCtx.Clock().Now()
 
 	// Look up engine
 	engine, ok := c.engineMap[queryType]
@@ -537,7 +629,7 @@ func (c *SearchJSON) executeSearch(
 				Content: fmt.Sprintf("Error: %v", err),
 			},
 		)
-		duration := time.Since(startTime)
+		duration := execCtx.Clock().Now().Sub(startTime)
 		if execCtx != nil {
 			execCtx.PublishAfterToolCall(
 				call.Name, argsToUse,
@@ -549,7 +641,7 @@ func (c *SearchJSON) executeSearch(
 
 	// Execute search
 	results, err := engine.Search(ctx, query)
-	duration := time.Since(startTime)
+	duration := execCtx.Clock().Now().Sub(startTime)
 
 	if err != nil {
 		raw.Errors[idx] = err
@@ -681,6 +773,20 @@ func (c *SearchJSON) executeRegularTool(
 ) {
 	tool, ok := c.toolMap[call.Name]
 	if !ok {
+		if output, handled := callUnknownToolHandler(
+			execCtx, ctx, c.unknownTool, call,
+		); handled {
+			raw.Results[idx] = &gent.RawToolCallResult{
+				Name: call.Name, Output: output,
+			}
+			*sections = append(
+				*sections, gent.FormattedSection{
+					Name:    call.Name,
+					Content: c.obsLimits.apply(call.Name, output),
+				},
+			)
+			return
+		}
 		raw.Errors[idx] = fmt.Errorf(
 			"%w: %s", gent.ErrUnknownTool, call.Name,
 		)
@@ -705,16 +811,55 @@ func (c *SearchJSON) executeRegularTool(
 		return
 	}
 
+	// Tool call loop: answer a repeated identical call
+	// without executing it
+	if execCtx != nil {
+		rep := execCtx.CheckRepeatedToolCall(
+			call.Name, call.Args,
+		)
+		if rep != nil {
+			output, content, repErr := resolveRepeatedCall(
+				rep, func(v any) (string, error) {
+					data, err := json.Marshal(v)
+					rendered := formatObservation(
+						tool, call.Name, v, string(data),
+					)
+					return c.obsLimits.apply(
+						call.Name, rendered,
+					), err
+				},
+			)
+			if repErr != nil {
+				raw.Errors[idx] = repErr
+			} else {
+				raw.Results[idx] = &gent.RawToolCallResult{
+					Name:     call.Name,
+					Output:   output,
+					Terminal: isTerminal(tool),
+				}
+			}
+			*sections = append(
+				*sections, gent.FormattedSection{
+					Name:    call.Name,
+					Content: content,
+				},
+			)
+			execCtx.PublishAfterToolCall(
+				call.Name, call.Args,
+				output, 0, repErr,
+			)
+			return
+		}
+	}
+
 	// Validate args against schema
 	if compiled, has := c.schemaMap[call.Name]; has {
 		if err := compiled.Validate(call.Args); err != nil {
 			raw.Errors[idx] = err
 			*sections = append(
 				*sections, gent.FormattedSection{
-					Name: call.Name,
-					Content: fmt.Sprintf(
-						"Error: %v", err,
-					),
+					Name:    call.Name,
+					Content: ErrorObservation(err),
 				},
 			)
 			if execCtx != nil {
@@ -735,10 +880,8 @@ func (c *SearchJSON) executeRegularTool(
 		raw.Errors[idx] = err
 		*sections = append(
 			*sections, gent.FormattedSection{
-				Name: call.Name,
-				Content: fmt.Sprintf(
-					"Error: %v", err,
-				),
+				Name:    call.Name,
+				Content: ErrorObservation(err),
 			},
 		)
 		if execCtx != nil {
@@ -758,20 +901,39 @@ func (c *SearchJSON) executeRegularTool(
 		inputToUse = beforeEvent.Args
 	}
 
-	startTime := time.Now()
+	// Dry run: stub side-effecting tools without calling
+	// them
+	if shouldStubDryRun(execCtx, c.dryRunStubs, tool) {
+		stub := DryRunOutput(call.Name)
+		raw.Results[idx] = &gent.RawToolCallResult{
+			Name:     call.Name,
+			Output:   stub,
+			Terminal: isTerminal(tool),
+		}
+		*sections = append(
+			*sections, gent.FormattedSection{
+				Name:    call.Name,
+				Content: stub,
+			},
+		)
+		execCtx.PublishAfterToolCall(
+			call.Name, inputToUse, stub, 0, nil,
+		)
+		return
+	}
+
+	startTime := execCtx.Clock().Now()
 	output, err := CallToolWithTypedInputReflect(
 		ctx, tool, inputToUse,
 	)
-	duration := time.Since(startTime)
+	duration := execCtx.Clock().Now().Sub(startTime)
 
 	if err != nil {
 		raw.Errors[idx] = err
 		*sections = append(
 			*sections, gent.FormattedSection{
-				Name: call.Name,
-				Content: fmt.Sprintf(
-					"Error: %v", err,
-				),
+				Name:    call.Name,
+				Content: ErrorObservation(err),
 			},
 		)
 	} else {
@@ -787,8 +949,9 @@ func (c *SearchJSON) executeRegularTool(
 		}
 
 		raw.Results[idx] = &gent.RawToolCallResult{
-			Name:   output.Name,
-			Output: output.Text,
+			Name:     output.Name,
+			Output:   output.Text,
+			Terminal: isTerminal(tool),
 		}
 
 		jsonData, marshalErr := json.Marshal(output.Text)
@@ -801,6 +964,13 @@ func (c *SearchJSON) executeRegularTool(
 				},
 			)
 		} else {
+			rendered := formatObservation(
+				tool, call.Name, output.Text,
+				string(jsonData),
+			)
+			content := c.obsLimits.apply(
+				call.Name, rendered,
+			)
 			if output.Instructions != "" {
 				*sections = append(
 					*sections,
@@ -809,7 +979,7 @@ func (c *SearchJSON) executeRegularTool(
 						Children: []gent.FormattedSection{
 							{
 								Name:    "result",
-								Content: string(jsonData),
+								Content: content,
 							},
 							{
 								Name:    "instructions",
@@ -822,7 +992,7 @@ func (c *SearchJSON) executeRegularTool(
 				*sections = append(
 					*sections, gent.FormattedSection{
 						Name:    call.Name,
-						Content: string(jsonData),
+						Content: content,
 					},
 				)
 			}
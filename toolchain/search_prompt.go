@@ -245,7 +245,7 @@ func formatToolDedup(name string) string {
 }
 
 // formatToolDefinitions formats a list of tool definitions
-// (name, description, policy, schema) for inclusion in search
+// (name, description, policy, schemas) for inclusion in search
 // results. Uses the same format as JSON.AvailableToolsPrompt().
 func formatToolDefinitions(tools []any) string {
 	var sb strings.Builder
@@ -273,6 +273,16 @@ func formatToolDefinitions(tools []any) string {
 				sb.WriteString("\n")
 			}
 		}
+		if s := meta.OutputSchema(); s != nil {
+			outputJSON, err := json.MarshalIndent(
+				s, "  ", "  ",
+			)
+			if err == nil {
+				sb.WriteString("  Returns: ")
+				sb.Write(outputJSON)
+				sb.WriteString("\n")
+			}
+		}
 	}
 	return sb.String()
 }
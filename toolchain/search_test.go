@@ -1610,6 +1610,40 @@ func TestSearchJSON_Execute_RegularTool(t *testing.T) {
 		)
 	})
 
+	t.Run("unknown tool goes to handler", func(t *testing.T) {
+		eng := &mockSearchEngine{
+			id: "m", guidance: "g",
+		}
+		tc := setupSearchJSON(
+			nil, []gent.SearchEngine{eng},
+		).WithUnknownToolHandler(
+			func(
+				_ context.Context,
+				name string,
+				_ map[string]any,
+			) (string, error) {
+				return "no tool " + name +
+					"; search for tools first", nil
+			},
+		)
+
+		content := `{"tool": "nonexistent", "args": {}}`
+		result, err := tc.Execute(
+			nil, content, searchTestFormat(),
+		)
+		require.NoError(t, err)
+		assert.NoError(t, result.Raw.Errors[0])
+		assert.Equal(
+			t,
+			"no tool nonexistent; search for tools first",
+			result.Raw.Results[0].Output,
+		)
+		assert.Contains(
+			t, result.Text,
+			"no tool nonexistent; search for tools first",
+		)
+	})
+
 	t.Run("schema validation fails", func(t *testing.T) {
 		tool := newIndexableToolWithSchema(
 			"strict_tool", "Strict", "D",
@@ -0,0 +1,75 @@
+package toolchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rickchristie/gent"
+)
+
+// ErrTooManyToolCalls is the parse error of an action section whose tool calls exceed
+// what the toolchain allows per iteration, with [ToolCallOverflowError] (see
+// YAML.WithMaxToolCallsPerIteration).
+var ErrTooManyToolCalls = errors.New("too many tool calls")
+
+// ToolCallLimitSectionName is the name of the observation section reporting tool calls
+// skipped with [ToolCallOverflowTruncate].
+const ToolCallLimitSectionName = "tool_call_limit"
+
+// ToolCallOverflow selects what a toolchain does with an action section whose tool calls
+// exceed what its per-iteration cap leaves.
+type ToolCallOverflow int
+
+const (
+	// ToolCallOverflowTruncate executes the first calls that fit the cap and skips the
+	// rest, adding an observation that tells the model how many were skipped. The
+	// default.
+	ToolCallOverflowTruncate ToolCallOverflow = iota
+
+	// ToolCallOverflowError executes none of the section's calls: parsing fails with
+	// [ErrTooManyToolCalls], which is fed back to the model as a toolchain parse error.
+	ToolCallOverflowError
+)
+
+// toolCallCap limits the tool calls executed in one iteration, across all its action
+// sections. A max of zero or less means no limit.
+type toolCallCap struct {
+	max      int
+	overflow ToolCallOverflow
+}
+
+// check returns an [ErrTooManyToolCalls] error if calls, on top of the used calls
+// already executed in the iteration, exceed the cap and the overflow mode is
+// ToolCallOverflowError.
+func (c toolCallCap) check(calls []*gent.ToolCall, used int) error {
+	if c.max <= 0 || used+len(calls) <= c.max || c.overflow != ToolCallOverflowError {
+		return nil
+	}
+	if used == 0 {
+		return fmt.Errorf("%w: %d calls in one action, at most %d are allowed; "+
+			"split them across several responses", ErrTooManyToolCalls, len(calls), c.max)
+	}
+	return fmt.Errorf("%w: %d calls in this action after %d in earlier actions of the "+
+		"same response, at most %d are allowed; split them across several responses",
+		ErrTooManyToolCalls, len(calls), used, c.max)
+}
+
+// truncate returns the calls to execute, given the used calls already executed in the
+// iteration. When calls exceed what the cap leaves, it returns the first calls that fit
+// and the observation section reporting the skipped ones.
+func (c toolCallCap) truncate(
+	calls []*gent.ToolCall,
+	used int,
+) ([]*gent.ToolCall, *gent.FormattedSection) {
+	if c.max <= 0 || used+len(calls) <= c.max {
+		return calls, nil
+	}
+	allowed := max(c.max-used, 0)
+	return calls[:allowed], &gent.FormattedSection{
+		Name: ToolCallLimitSectionName,
+		Content: fmt.Sprintf("Only %d of %d tool calls were executed; at most %d are "+
+			"allowed per response. The other %d were skipped: call them in a later "+
+			"response if they are still needed.",
+			allowed, len(calls), c.max, len(calls)-allowed),
+	}
+}
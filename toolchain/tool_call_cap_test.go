@@ -0,0 +1,291 @@
+package toolchain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAML_MaxToolCallsPerIteration(t *testing.T) {
+	type input struct {
+		maxCalls int
+		overflow ToolCallOverflow
+		content  string
+	}
+
+	type expected struct {
+		executed    []string
+		text        string
+		err         error
+		parseErrors int64
+	}
+
+	fourCalls := "- tool: lookup\n  args: {id: a}\n" +
+		"- tool: lookup\n  args: {id: b}\n" +
+		"- tool: lookup\n  args: {id: c}\n" +
+		"- tool: lookup\n  args: {id: d}"
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "calls within the cap all run",
+			input: input{maxCalls: 4, content: fourCalls},
+			expected: expected{
+				executed: []string{"a", "b", "c", "d"},
+				text: "<lookup>\nfound a\n</lookup>\n<lookup>\nfound b\n</lookup>\n" +
+					"<lookup>\nfound c\n</lookup>\n<lookup>\nfound d\n</lookup>",
+			},
+		},
+		{
+			name:  "truncate runs the first calls and reports the rest",
+			input: input{maxCalls: 2, content: fourCalls},
+			expected: expected{
+				executed: []string{"a", "b"},
+				text: "<lookup>\nfound a\n</lookup>\n<lookup>\nfound b\n</lookup>\n" +
+					"<tool_call_limit>\nOnly 2 of 4 tool calls were executed; " +
+					"at most 2 are allowed per response. The other 2 were skipped: call them " +
+					"in a later response if they are still needed.\n</tool_call_limit>",
+			},
+		},
+		{
+			name: "error mode runs no call",
+			input: input{
+				maxCalls: 2,
+				overflow: ToolCallOverflowError,
+				content:  fourCalls,
+			},
+			expected: expected{err: ErrTooManyToolCalls, parseErrors: 1},
+		},
+		{
+			name:  "zero disables the cap",
+			input: input{overflow: ToolCallOverflowError, content: fourCalls},
+			expected: expected{
+				executed: []string{"a", "b", "c", "d"},
+				text: "<lookup>\nfound a\n</lookup>\n<lookup>\nfound b\n</lookup>\n" +
+					"<lookup>\nfound c\n</lookup>\n<lookup>\nfound d\n</lookup>",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var executed []string
+			chain := NewYAML().
+				WithMaxToolCallsPerIteration(tc.input.maxCalls).
+				WithToolCallOverflow(tc.input.overflow)
+			chain.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up a record", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					id := args["id"].(string)
+					executed = append(executed, id)
+					return "found " + id, nil
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result, err := chain.Execute(execCtx, tc.input.content, yamlTestFormat())
+
+			assert.Equal(t, tc.expected.executed, executed)
+			assert.Equal(t, tc.expected.parseErrors,
+				execCtx.Stats().GetCounter(gent.SCToolchainParseErrorTotal))
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.text, result.Text)
+			assert.Len(t, result.Raw.Calls, len(tc.expected.executed))
+			assert.Len(t, result.Raw.Results, len(tc.expected.executed))
+		})
+	}
+}
+
+func TestYAML_MaxToolCallsPerIteration_AcrossActions(t *testing.T) {
+	type input struct {
+		overflow      ToolCallOverflow
+		nextIteration bool
+	}
+
+	type expected struct {
+		executed []string
+		err      error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "later action gets what earlier ones left",
+			input:    input{},
+			expected: expected{executed: []string{"a", "b", "c"}},
+		},
+		{
+			name:     "later action over the cap fails in error mode",
+			input:    input{overflow: ToolCallOverflowError},
+			expected: expected{executed: []string{"a", "b"}, err: ErrTooManyToolCalls},
+		},
+		{
+			name:     "cap starts over with the next iteration",
+			input:    input{nextIteration: true},
+			expected: expected{executed: []string{"a", "b", "c", "d"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var executed []string
+			chain := NewYAML().
+				WithMaxToolCallsPerIteration(3).
+				WithToolCallOverflow(tc.input.overflow)
+			chain.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up a record", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					id := args["id"].(string)
+					executed = append(executed, id)
+					return "found " + id, nil
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+
+			_, err := chain.Execute(execCtx,
+				"- tool: lookup\n  args: {id: a}\n- tool: lookup\n  args: {id: b}",
+				yamlTestFormat())
+			require.NoError(t, err)
+			if tc.input.nextIteration {
+				execCtx.IncrementIteration()
+			}
+			_, err = chain.Execute(execCtx,
+				"- tool: lookup\n  args: {id: c}\n- tool: lookup\n  args: {id: d}",
+				yamlTestFormat())
+
+			assert.Equal(t, tc.expected.executed, executed)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJSON_MaxToolCallsPerIteration(t *testing.T) {
+	type input struct {
+		overflow ToolCallOverflow
+	}
+
+	type expected struct {
+		executed int
+		err      error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "truncate",
+			input:    input{overflow: ToolCallOverflowTruncate},
+			expected: expected{executed: 1},
+		},
+		{
+			name:     "error",
+			input:    input{overflow: ToolCallOverflowError},
+			expected: expected{err: ErrTooManyToolCalls},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executed := 0
+			chain := NewJSON().
+				WithMaxToolCallsPerIteration(1).
+				WithToolCallOverflow(tc.input.overflow)
+			chain.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up a record", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					executed++
+					return "found", nil
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			_, err := chain.Execute(execCtx,
+				`[{"tool": "lookup", "args": {}}, {"tool": "lookup", "args": {}}]`,
+				yamlTestFormat())
+
+			assert.Equal(t, tc.expected.executed, executed)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSearchJSON_MaxToolCallsPerIteration(t *testing.T) {
+	type input struct {
+		overflow ToolCallOverflow
+	}
+
+	type expected struct {
+		executed int
+		err      error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "truncate",
+			input:    input{overflow: ToolCallOverflowTruncate},
+			expected: expected{executed: 1},
+		},
+		{
+			name:     "error",
+			input:    input{overflow: ToolCallOverflowError},
+			expected: expected{err: ErrTooManyToolCalls},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			executed := 0
+			chain := setupSearchJSON([]*indexableToolFunc{newIndexableTool(
+				"lookup", "Look up a record", "records", nil, nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					executed++
+					return "found", nil
+				},
+			)}, []gent.SearchEngine{NewBM25SearchEngine()}).
+				WithMaxToolCallsPerIteration(1).
+				WithToolCallOverflow(tc.input.overflow)
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result, err := chain.Execute(execCtx,
+				`[{"tool": "lookup", "args": {}}, {"tool": "lookup", "args": {}}]`,
+				yamlTestFormat())
+
+			assert.Equal(t, tc.expected.executed, executed)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, result.Text, "<tool_call_limit>")
+		})
+	}
+}
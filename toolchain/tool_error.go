@@ -0,0 +1,61 @@
+package toolchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/schema"
+)
+
+// ErrorObservation returns the observation for a tool call that failed with err. A
+// [gent.ToolError] adds guidance for its category and its hint, and invalid arguments
+// (a [schema.ValidationError], [gent.ErrInvalidToolInput], or a [TransformArgsReflect]
+// decoding error) the guidance of [gent.ToolErrorInvalid]:
+//
+//	Error: order 42 not found. The arguments were invalid; correct them before calling
+//	this tool again.
+//	Hint: Look up the order ID with search_orders first.
+//
+// Other errors are reported as "Error: <message>".
+func ErrorObservation(err error) string {
+	observation := fmt.Sprintf("Error: %v", err)
+
+	switch errorCategory(err) {
+	case gent.ToolErrorInvalid:
+		observation += ". The arguments were invalid; correct them before calling this " +
+			"tool again."
+	case gent.ToolErrorTransient:
+		observation += ". This is a temporary failure; you may retry the same call."
+	case gent.ToolErrorPermanent:
+		observation += ". This failure is permanent; do not retry this call, use another " +
+			"tool or approach instead."
+	}
+
+	var toolErr *gent.ToolError
+	if errors.As(err, &toolErr) && toolErr.Hint != "" {
+		observation += "\nHint: " + toolErr.Hint
+	}
+	return observation
+}
+
+// errorCategory returns the category of a failed call's error: the category of its
+// ToolError, or ToolErrorInvalid for arguments rejected before or by the tool. Other
+// errors have no category ("").
+func errorCategory(err error) gent.ToolErrorCategory {
+	if category := gent.ToolErrorCategoryOf(err); category != "" {
+		return category
+	}
+	var validationErr *schema.ValidationError
+	if errors.Is(err, gent.ErrInvalidToolInput) || errors.As(err, &validationErr) {
+		return gent.ToolErrorInvalid
+	}
+	return ""
+}
+
+// countsAsToolFailure reports whether a call that returned err counts as a failure of
+// the tool for its circuit breaker. Invalid arguments are the caller's fault, not a sign
+// the tool is unhealthy.
+func countsAsToolFailure(err error) bool {
+	return err != nil && errorCategory(err) != gent.ToolErrorInvalid
+}
@@ -0,0 +1,250 @@
+package toolchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rickchristie/gent"
+	"github.com/rickchristie/gent/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAML_ToolErrorObservation(t *testing.T) {
+	type input struct {
+		err error
+	}
+
+	type expected struct {
+		text     string
+		category gent.ToolErrorCategory
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "uncategorized error",
+			input: input{err: errors.New("order 42 not found")},
+			expected: expected{
+				text: "<refund>\nError: order 42 not found\n</refund>",
+			},
+		},
+		{
+			name: "invalid error asks for different arguments",
+			input: input{err: gent.NewToolError(gent.ToolErrorInvalid,
+				errors.New("order 42 not found"))},
+			expected: expected{
+				text: "<refund>\nError: order 42 not found. The arguments were invalid; " +
+					"correct them before calling this tool again.\n</refund>",
+				category: gent.ToolErrorInvalid,
+			},
+		},
+		{
+			name: "transient error allows a retry",
+			input: input{err: gent.NewToolError(gent.ToolErrorTransient,
+				errors.New("payment gateway timeout"))},
+			expected: expected{
+				text: "<refund>\nError: payment gateway timeout. This is a temporary " +
+					"failure; you may retry the same call.\n</refund>",
+				category: gent.ToolErrorTransient,
+			},
+		},
+		{
+			name: "permanent error forbids a retry",
+			input: input{err: gent.NewToolError(gent.ToolErrorPermanent,
+				errors.New("order 42 was already refunded"))},
+			expected: expected{
+				text: "<refund>\nError: order 42 was already refunded. This failure is " +
+					"permanent; do not retry this call, use another tool or approach " +
+					"instead.\n</refund>",
+				category: gent.ToolErrorPermanent,
+			},
+		},
+		{
+			name: "hint is added",
+			input: input{err: gent.NewToolError(gent.ToolErrorInvalid,
+				errors.New("order 42 not found")).
+				WithHint("Look up the order ID with search_orders first.")},
+			expected: expected{
+				text: "<refund>\nError: order 42 not found. The arguments were invalid; " +
+					"correct them before calling this tool again.\n" +
+					"Hint: Look up the order ID with search_orders first.\n</refund>",
+				category: gent.ToolErrorInvalid,
+			},
+		},
+		{
+			name:  "invalid tool input asks for different arguments",
+			input: input{err: fmt.Errorf("%w: amount must be positive", gent.ErrInvalidToolInput)},
+			expected: expected{
+				text: "<refund>\nError: invalid tool input: amount must be positive. The " +
+					"arguments were invalid; correct them before calling this tool " +
+					"again.\n</refund>",
+				category: gent.ToolErrorInvalid,
+			},
+		},
+		{
+			name: "wrapped tool error keeps its category",
+			input: input{err: fmt.Errorf("refund: %w", gent.NewToolError(
+				gent.ToolErrorTransient, errors.New("payment gateway timeout")))},
+			expected: expected{
+				text: "<refund>\nError: refund: payment gateway timeout. This is a " +
+					"temporary failure; you may retry the same call.\n</refund>",
+				category: gent.ToolErrorTransient,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewYAML()
+			chain.RegisterTool(gent.NewToolFunc(
+				"refund", "Refund an order", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "", tc.input.err
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result, err := chain.Execute(execCtx, "tool: refund", yamlTestFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected.text, result.Text)
+			assert.ErrorIs(t, result.Raw.Errors[0], tc.input.err)
+			assert.Equal(t, tc.expected.category, errorCategory(result.Raw.Errors[0]))
+		})
+	}
+}
+
+func TestYAML_InvalidArgumentsObservation(t *testing.T) {
+	type refundInput struct {
+		OrderID int `json:"order_id"`
+	}
+
+	type input struct {
+		schema map[string]any
+	}
+
+	type expected struct {
+		text string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "schema validation error",
+			input: input{schema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"order_id": map[string]any{"type": "integer"}},
+			}},
+			expected: expected{text: "The arguments were invalid; correct them before " +
+				"calling this tool again.\n</refund>"},
+		},
+		{
+			name:  "argument decoding error",
+			input: input{},
+			expected: expected{text: "The arguments were invalid; correct them before " +
+				"calling this tool again.\n</refund>"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			chain := NewYAML()
+			chain.RegisterTool(gent.NewToolFunc(
+				"refund", "Refund an order", tc.input.schema,
+				func(_ context.Context, _ refundInput) (string, error) {
+					called = true
+					return "refunded", nil
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result, err := chain.Execute(execCtx,
+				"tool: refund\nargs:\n  order_id: [42]", yamlTestFormat())
+			require.NoError(t, err)
+
+			assert.False(t, called)
+			assert.True(t, strings.HasSuffix(result.Text, tc.expected.text), result.Text)
+			assert.Equal(t, gent.ToolErrorInvalid, errorCategory(result.Raw.Errors[0]))
+		})
+	}
+}
+
+func TestYAML_ToolErrorCircuitBreaker(t *testing.T) {
+	type input struct {
+		err error
+	}
+
+	type expected struct {
+		health ToolHealth
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "invalid errors don't count as failures",
+			input:    input{err: gent.NewToolError(gent.ToolErrorInvalid, errors.New("failed"))},
+			expected: expected{health: ToolHealth{State: CircuitClosed, RecentCalls: 2}},
+		},
+		{
+			name:     "invalid tool input doesn't count as a failure",
+			input:    input{err: fmt.Errorf("%w: failed", gent.ErrInvalidToolInput)},
+			expected: expected{health: ToolHealth{State: CircuitClosed, RecentCalls: 2}},
+		},
+		{
+			name:  "transient errors open the circuit",
+			input: input{err: gent.NewToolError(gent.ToolErrorTransient, errors.New("failed"))},
+			expected: expected{
+				health: ToolHealth{State: CircuitOpen, RecentCalls: 2, RecentErrors: 2},
+			},
+		},
+		{
+			name:  "permanent errors open the circuit",
+			input: input{err: gent.NewToolError(gent.ToolErrorPermanent, errors.New("failed"))},
+			expected: expected{
+				health: ToolHealth{State: CircuitOpen, RecentCalls: 2, RecentErrors: 2},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// A fixed clock keeps AverageLatency at zero
+			clock := clocktest.NewFakeClock(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+			chain := NewYAML().WithCircuitBreaker("refund", 1, 2)
+			chain.RegisterTool(gent.NewToolFunc(
+				"refund", "Refund an order", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "", tc.input.err
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetClock(clock)
+
+			for range 2 {
+				_, err := chain.Execute(execCtx, "tool: refund", yamlTestFormat())
+				require.NoError(t, err)
+			}
+
+			health, ok := chain.ToolHealth("refund")
+			require.True(t, ok)
+			assert.Equal(t, tc.expected.health, health)
+			assert.Equal(t, int64(2),
+				execCtx.Stats().GetCounter(gent.SCToolCallsErrorFor+"refund"))
+		})
+	}
+}
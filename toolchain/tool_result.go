@@ -0,0 +1,28 @@
+package toolchain
+
+import "github.com/rickchristie/gent"
+
+// isTerminal reports whether tool declares itself the final action of a run via
+// [gent.TerminalTool].
+func isTerminal(tool any) bool {
+	terminalTool, ok := tool.(gent.TerminalTool)
+	return ok && terminalTool.IsTerminal()
+}
+
+// formatObservation returns the observation content for a successful call's output
+// text: the tool's own rendering if it implements [gent.ObservationFormatterTool], and
+// rendered, the toolchain's default, otherwise.
+func formatObservation(tool any, name string, text any, rendered string) string {
+	formatter, ok := tool.(gent.ObservationFormatterTool)
+	if !ok {
+		return rendered
+	}
+	output := rendered
+	if s, ok := text.(string); ok {
+		output = s
+	}
+	if formatted, ok := formatter.FormatObservation(name, output); ok {
+		return formatted
+	}
+	return rendered
+}
@@ -0,0 +1,187 @@
+package toolchain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/rickchristie/gent"
+)
+
+// TruncateObservation shortens content to at most maxBytes bytes and appends a
+// "[truncated N of M bytes]" marker, where N is the number of bytes removed and M the
+// original size. The cut never splits a UTF-8 character.
+//
+// Content within the limit, or a maxBytes of zero or less, returns content unchanged.
+func TruncateObservation(content string, maxBytes int) string {
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return content
+	}
+	return truncateTo(content, maxBytes, len(content))
+}
+
+// truncateTo cuts content to at most maxBytes bytes without splitting a UTF-8 character
+// and appends the truncation marker for an output that was originally original bytes.
+func truncateTo(content string, maxBytes, original int) string {
+	cut := min(max(maxBytes, 0), len(content))
+	for cut > 0 && cut < len(content) && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + marker(original-cut, original)
+}
+
+// truncationMarker matches the marker appended by TruncateObservation.
+var truncationMarker = regexp.MustCompile(`\n\[truncated \d+ of (\d+) bytes\]$`)
+
+// untruncated splits output already cut by TruncateObservation into the kept content
+// and the original size. Other output is returned as-is with its own size.
+func untruncated(output string) (string, int) {
+	m := truncationMarker.FindStringSubmatchIndex(output)
+	if m == nil {
+		return output, len(output)
+	}
+	original, err := strconv.Atoi(output[m[2]:m[3]])
+	if err != nil {
+		return output, len(output)
+	}
+	return output[:m[0]], original
+}
+
+// observationLimits holds a toolchain's observation size limits.
+type observationLimits struct {
+	maxBytes    int            // applies to all tools; 0 means unlimited
+	perTool     map[string]int // tool name -> override; 0 or less means unlimited
+	maxCombined int            // applies to all outputs of one iteration; 0 means unlimited
+}
+
+// setFor sets the override for a single tool.
+func (l *observationLimits) setFor(toolName string, maxBytes int) {
+	if l.perTool == nil {
+		l.perTool = make(map[string]int)
+	}
+	l.perTool[toolName] = maxBytes
+}
+
+// apply truncates a tool's formatted output according to the configured limits.
+func (l *observationLimits) apply(toolName, content string) string {
+	maxBytes := l.maxBytes
+	if override, ok := l.perTool[toolName]; ok {
+		maxBytes = override
+	}
+	return TruncateObservation(content, maxBytes)
+}
+
+// toolOutputs returns the outputs of the calls that produced a result, given the
+// observation sections of an Execute, one per call: the section's content, or its
+// "result" child for a call with instructions. Error observations are left out.
+func toolOutputs(sections []gent.FormattedSection, results []*gent.RawToolCallResult) []*string {
+	var outputs []*string
+	for i := range sections {
+		if i >= len(results) || results[i] == nil {
+			continue
+		}
+		if len(sections[i].Children) == 0 {
+			outputs = append(outputs, &sections[i].Content)
+			continue
+		}
+		for j := range sections[i].Children {
+			if sections[i].Children[j].Name == "result" {
+				outputs = append(outputs, &sections[i].Children[j].Content)
+			}
+		}
+	}
+	return outputs
+}
+
+// capCombined truncates the largest outputs so that their combined size, truncation
+// markers included, is at most what maxCombined leaves after used bytes, the size of the
+// outputs already observed in the iteration. The outputs cut share the budget equally,
+// so smaller outputs are kept whole whenever possible. When the budget cannot even hold
+// the markers, the largest outputs are cut down to their marker, as many as makes the
+// combined size smallest.
+// Returns the combined size of outputs after the cut.
+func (l *observationLimits) capCombined(outputs []*string, used int) int {
+	total := 0
+	for _, out := range outputs {
+		total += len(*out)
+	}
+	remaining := l.maxCombined - used
+	if l.maxCombined <= 0 || total <= remaining {
+		return total
+	}
+
+	// Outputs already cut by a per-tool limit are measured without their marker, and
+	// cut again relative to their original size
+	kept := make([]string, len(outputs))
+	originals := make([]int, len(outputs))
+	order := make([]int, len(outputs))
+	for i, out := range outputs {
+		kept[i], originals[i] = untruncated(*out)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(kept[order[a]]) > len(kept[order[b]])
+	})
+	// The longest marker an output can get, whatever is removed
+	markerSize := func(i int) int { return len(marker(originals[i], originals[i])) }
+
+	// Cut the k largest outputs, for the smallest k that fits with a common length no
+	// shorter than the outputs kept whole
+	cut, limit := 0, 0
+	for k := 1; k <= len(order) && cut == 0; k++ {
+		budget := remaining
+		for _, i := range order[:k] {
+			budget -= markerSize(i)
+		}
+		for _, i := range order[k:] {
+			budget -= len(*outputs[i])
+		}
+		if budget < 0 || (k < len(order) && budget/k < len(kept[order[k]])) {
+			continue
+		}
+		cut, limit = k, budget/k
+	}
+	if cut == 0 {
+		best := total
+		for k := 1; k <= len(order); k++ {
+			size := 0
+			for _, i := range order[:k] {
+				size += markerSize(i)
+			}
+			for _, i := range order[k:] {
+				size += len(*outputs[i])
+			}
+			if size < best {
+				cut, best = k, size
+			}
+		}
+	}
+
+	for _, i := range order[:cut] {
+		if limit < len(kept[i]) {
+			*outputs[i] = truncateTo(kept[i], limit, originals[i])
+		}
+	}
+	total = 0
+	for _, out := range outputs {
+		total += len(*out)
+	}
+	return total
+}
+
+// marker returns the truncation marker of an output of original bytes with removed bytes
+// cut.
+func marker(removed, original int) string {
+	return fmt.Sprintf("\n[truncated %d of %d bytes]", removed, original)
+}
+
+// iterationToolUsage returns the tool usage recorded for the current iteration of
+// execCtx, or zero usage without a context.
+func iterationToolUsage(execCtx *gent.ExecutionContext) gent.IterationToolUsage {
+	if execCtx == nil {
+		return gent.IterationToolUsage{}
+	}
+	return execCtx.IterationToolUsage()
+}
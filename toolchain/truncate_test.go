@@ -0,0 +1,188 @@
+package toolchain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rickchristie/gent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateObservation(t *testing.T) {
+	type input struct {
+		content  string
+		maxBytes int
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected string
+	}{
+		{
+			name:     "within limit unchanged",
+			input:    input{content: "hello", maxBytes: 5},
+			expected: "hello",
+		},
+		{
+			name:     "zero limit disables truncation",
+			input:    input{content: "hello", maxBytes: 0},
+			expected: "hello",
+		},
+		{
+			name:     "over limit truncated with marker",
+			input:    input{content: "hello world", maxBytes: 5},
+			expected: "hello\n[truncated 6 of 11 bytes]",
+		},
+		{
+			name:     "cut backs off to UTF-8 boundary",
+			input:    input{content: "a日本", maxBytes: 3},
+			expected: "a\n[truncated 6 of 7 bytes]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TruncateObservation(tt.input.content, tt.input.maxBytes))
+		})
+	}
+}
+
+func TestObservationLimits_CapCombined(t *testing.T) {
+	type input struct {
+		maxCombined int
+		used        int
+		outputs     []string
+	}
+
+	type expected struct {
+		outputs []string
+		total   int
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "zero disables the cap",
+			input:    input{outputs: []string{"aaaaaaaaaa"}},
+			expected: expected{outputs: []string{"aaaaaaaaaa"}, total: 10},
+		},
+		{
+			name:     "outputs within the cap unchanged",
+			input:    input{maxCombined: 10, outputs: []string{"aa", "bbb"}},
+			expected: expected{outputs: []string{"aa", "bbb"}, total: 5},
+		},
+		{
+			name: "markers count toward the cap",
+			input: input{
+				maxCombined: 40,
+				outputs:     []string{"aa", strings.Repeat("b", 60)},
+			},
+			expected: expected{
+				outputs: []string{"aa", strings.Repeat("b", 11) + "\n[truncated 49 of 60 bytes]"},
+				total:   40,
+			},
+		},
+		{
+			name: "largest outputs cut to a common length",
+			input: input{
+				maxCombined: 100,
+				outputs: []string{
+					"aaaaa", strings.Repeat("b", 100), strings.Repeat("c", 100),
+				},
+			},
+			expected: expected{
+				outputs: []string{
+					"aaaaa",
+					strings.Repeat("b", 18) + "\n[truncated 82 of 100 bytes]",
+					strings.Repeat("c", 18) + "\n[truncated 82 of 100 bytes]",
+				},
+				total: 97,
+			},
+		},
+		{
+			name: "output already truncated is measured against its original size",
+			input: input{
+				maxCombined: 31,
+				outputs:     []string{"aaaaaa\n[truncated 4 of 10 bytes]"},
+			},
+			expected: expected{
+				outputs: []string{"aaaa\n[truncated 6 of 10 bytes]"},
+				total:   30,
+			},
+		},
+		{
+			name: "bytes used by earlier sections reduce the budget",
+			input: input{
+				maxCombined: 70,
+				used:        30,
+				outputs:     []string{"aa", strings.Repeat("b", 60)},
+			},
+			expected: expected{
+				outputs: []string{"aa", strings.Repeat("b", 11) + "\n[truncated 49 of 60 bytes]"},
+				total:   40,
+			},
+		},
+		{
+			name: "budget too small for the markers cuts the fewest outputs",
+			input: input{
+				maxCombined: 50,
+				used:        20,
+				outputs:     []string{"aaaa", strings.Repeat("b", 40)},
+			},
+			expected: expected{
+				outputs: []string{"aaaa", "\n[truncated 40 of 40 bytes]"},
+				total:   31,
+			},
+		},
+		{
+			name: "spent budget keeps outputs shorter than their marker",
+			input: input{
+				maxCombined: 10,
+				used:        10,
+				outputs:     []string{"aaa", strings.Repeat("b", 40)},
+			},
+			expected: expected{
+				outputs: []string{"aaa", "\n[truncated 40 of 40 bytes]"},
+				total:   30,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := make([]*string, len(tt.input.outputs))
+			for i := range tt.input.outputs {
+				outputs[i] = &tt.input.outputs[i]
+			}
+			limits := observationLimits{maxCombined: tt.input.maxCombined}
+
+			total := limits.capCombined(outputs, tt.input.used)
+
+			assert.Equal(t, tt.expected.outputs, tt.input.outputs)
+			assert.Equal(t, tt.expected.total, total)
+		})
+	}
+}
+
+func TestToolOutputs(t *testing.T) {
+	sections := []gent.FormattedSection{
+		{Name: "a", Content: "aa"},
+		{Name: "b", Content: "Error: boom"},
+		{Name: "c", Children: []gent.FormattedSection{
+			{Name: "result", Content: "cc"},
+			{Name: "instructions", Content: "keep me"},
+		}},
+	}
+	results := []*gent.RawToolCallResult{{Name: "a"}, nil, {Name: "c"}}
+
+	outputs := toolOutputs(sections, results)
+
+	require.Len(t, outputs, 2)
+	assert.Same(t, &sections[0].Content, outputs[0])
+	assert.Same(t, &sections[2].Children[0].Content, outputs[1])
+}
@@ -0,0 +1,48 @@
+package toolchain
+
+import (
+	"context"
+
+	"github.com/rickchristie/gent"
+)
+
+// UnknownToolHandler handles a call to a tool the toolchain does not know: a name the
+// model made up, or a registered tool that is not currently available. It receives the
+// requested name and arguments and returns the observation fed back to the model, e.g.
+// "no such tool; available: ..." or the output of a dynamically resolved tool.
+//
+// Returning an error declines the call: it then fails with [gent.ErrUnknownTool] exactly
+// as if no handler were set.
+type UnknownToolHandler func(ctx context.Context, name string, args map[string]any) (string, error)
+
+// callUnknownToolHandler routes a call to an unknown tool to handler. It returns the
+// handler's observation and true when the call was handled. It returns false when handler
+// is nil or declined the call; the caller then reports gent.ErrUnknownTool.
+//
+// A handled call counts toward gent.SCToolCallsUnknown only, like a failed one: no
+// BeforeToolCall event is published, so it adds nothing to gent.SCToolCalls, the
+// per-tool counters or gent.SGDistinctToolsUsed.
+func callUnknownToolHandler(
+	execCtx *gent.ExecutionContext,
+	ctx context.Context,
+	handler UnknownToolHandler,
+	call *gent.ToolCall,
+) (string, bool) {
+	if handler == nil {
+		return "", false
+	}
+
+	startTime := execCtx.Clock().Now()
+	output, err := handler(ctx, call.Name, call.Args)
+	duration := execCtx.Clock().Now().Sub(startTime)
+	if err != nil {
+		return "", false
+	}
+
+	if execCtx != nil {
+		execCtx.Stats().IncrCounter(gent.SCToolCallsUnknown, 1)
+		execCtx.Stats().ResetGauge(gent.SGToolCallsErrorConsecutive)
+		execCtx.PublishAfterToolCall(call.Name, call.Args, output, duration, nil)
+	}
+	return output, true
+}
@@ -1,8 +1,12 @@
 package toolchain
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -62,6 +66,22 @@ import (
 //	    "phone": schema.String("Phone number"),  // "555-1234" stays as string
 //	})
 //
+// # Multiline Strings
+//
+// Block scalars follow the YAML spec: "|" keeps newlines, ">" folds lines into spaces,
+// and the "-" variants ("|-", ">-") drop the final newline. Lines inside the block that
+// look like YAML keys (e.g. "Subject: hi") stay part of the string:
+//
+//	tool: send_email
+//	args:
+//	  to: ann@example.com
+//	  body: |
+//	    Subject: hi
+//	    Your order has shipped.
+//
+// Section content that is indented as a whole is dedented before parsing, so the
+// relative indentation of block scalars is preserved.
+//
 // # Using with Agent
 //
 //	agent := react.NewAgent(model).
@@ -82,6 +102,13 @@ type YAML struct {
 	schemaMap    map[string]*schema.Schema // compiled schemas for validation
 	rawSchemaMap map[string]map[string]any // raw schemas for type-aware parsing
 	sectionName  string
+	dryRunStubs  bool
+	unknownTool  UnknownToolHandler
+	obsLimits    observationLimits
+	available    toolAvailability
+	multiplicity gent.SectionMultiplicity
+	breakers     circuitBreakers
+	callCap      toolCallCap
 }
 
 // NewYAML creates a new YAML toolchain with default section name "action".
@@ -101,6 +128,165 @@ func (c *YAML) WithSectionName(name string) *YAML {
 	return c
 }
 
+// WithMultiple lets the model emit several action sections in one response, each
+// executed in order (the default).
+func (c *YAML) WithMultiple() *YAML {
+	c.multiplicity = gent.SectionRepeat
+	return c
+}
+
+// WithSingle makes more than one action section in a response a parse error, for
+// agents that expect all tool calls in a single block.
+func (c *YAML) WithSingle() *YAML {
+	c.multiplicity = gent.SectionSingle
+	return c
+}
+
+// Multiplicity reports how repeated action sections are handled.
+// Implements [gent.MultiplicitySection].
+func (c *YAML) Multiplicity() gent.SectionMultiplicity {
+	return c.multiplicity
+}
+
+// WithDryRunStubs enables stubbing of side-effecting tools during a dry run.
+//
+// When enabled and the execution context was created with [gent.WithDryRun], tools
+// implementing [gent.SideEffectTool] are not called. The call returns
+// [DryRunOutput] as its observation instead, so the model continues as if the tool ran.
+// Tools without side effects are always called.
+func (c *YAML) WithDryRunStubs(enabled bool) *YAML {
+	c.dryRunStubs = enabled
+	return c
+}
+
+// WithUnknownToolHandler routes calls to unknown tools to handler instead of failing
+// them with [gent.ErrUnknownTool]. Calls to registered tools that are not available
+// count as unknown, unless WithUnavailableToolMessage is set. The handler's output
+// becomes the call's observation; if it returns an error, the call fails as if no
+// handler were set. Either way the call counts toward [gent.SCToolCallsUnknown], not
+// [gent.SCToolCalls]. See [UnknownToolHandler].
+func (c *YAML) WithUnknownToolHandler(handler UnknownToolHandler) *YAML {
+	c.unknownTool = handler
+	return c
+}
+
+// WithUnavailableToolMessage sets the observation returned when the model calls a
+// registered tool that the current tool filter excludes (see SetAvailableTools), instead
+// of treating the call as a call to an unknown tool. The call fails with
+// [gent.ErrToolUnavailable] and counts toward [gent.SCToolCallsUnavailable] rather than
+// [gent.ErrUnknownTool] and [gent.SCToolCallsUnknown]:
+//
+//	tc := toolchain.NewYAML().
+//	    RegisterTool(refundTool).
+//	    WithUnavailableToolMessage(func(name string) string {
+//	        return fmt.Sprintf("%s is not available until the customer is verified.", name)
+//	    })
+//
+// Unregistered tools still fail as unknown (or go to the [UnknownToolHandler]).
+func (c *YAML) WithUnavailableToolMessage(message UnavailableToolMessage) *YAML {
+	c.available.message = message
+	return c
+}
+
+// WithMaxObservationBytes limits each tool's formatted output to n bytes. Longer
+// output is cut and marked with "[truncated N of M bytes]" (see [TruncateObservation]).
+// Zero disables the limit, which is the default.
+//
+// Truncation only affects the observation fed back to the model. The AfterToolCall
+// event and ToolChainResult.Raw still carry the tool's full output. Use it as a
+// guardrail against tools returning huge documents, independent of compaction.
+func (c *YAML) WithMaxObservationBytes(n int) *YAML {
+	c.obsLimits.maxBytes = n
+	return c
+}
+
+// WithToolMaxObservationBytes overrides WithMaxObservationBytes for a single tool.
+// Zero or less disables truncation for that tool.
+func (c *YAML) WithToolMaxObservationBytes(toolName string, n int) *YAML {
+	c.obsLimits.setFor(toolName, n)
+	return c
+}
+
+// WithMaxCombinedObservationBytes limits the combined output of all tool calls in one
+// iteration to n bytes, truncation markers included, after per-tool limits are applied.
+// When the calls return more, the largest outputs are cut to a common length and marked
+// with "[truncated N of M bytes]", so smaller outputs stay whole. Error observations are
+// never cut. Zero disables the limit, which is the default.
+//
+// The limit spans all action sections of the response: each Execute gets what the
+// earlier ones of the iteration left (see [gent.ExecutionContext.IterationToolUsage]),
+// as their observations are already final. When the budget is spent, later outputs are
+// cut down to their marker.
+//
+// Use it alongside WithMaxObservationBytes when the model makes many calls at once: each
+// output may be moderate, while together they still overflow the next prompt.
+func (c *YAML) WithMaxCombinedObservationBytes(n int) *YAML {
+	c.obsLimits.maxCombined = n
+	return c
+}
+
+// WithCircuitBreaker stops calling toolName while its recent calls mostly fail. Once
+// the last window executed calls include at least threshold (0 to 1) errors, e.g. 4 of
+// 5 with threshold 0.8, the circuit opens: further calls are not executed and fail with
+// [ErrCircuitOpen], with an observation telling the model to use another tool or try
+// later. After the cooldown (see WithCircuitBreakerCooldown), the next call runs as a
+// trial: its success closes the circuit with a fresh window, its failure keeps it open
+// for another cooldown.
+//
+//	tc := toolchain.NewYAML().
+//	    RegisterTool(weatherTool).
+//	    WithCircuitBreaker("weather", 0.8, 5)
+//
+// Calls failing with a [gent.ToolError] of category [gent.ToolErrorInvalid] are counted
+// as calls, not errors: bad arguments don't mean the tool is unhealthy.
+//
+// Breaker state lives in the toolchain, so it carries over between executions sharing
+// it. Time is read from the execution context's clock. Panics if threshold is not in
+// (0, 1] or window is less than 1.
+func (c *YAML) WithCircuitBreaker(toolName string, threshold float64, window int) *YAML {
+	c.breakers.set(toolName, threshold, window)
+	return c
+}
+
+// WithCircuitBreakerCooldown sets how long a tripped circuit breaker stays open before
+// it lets a trial call through. Default: DefaultCircuitBreakerCooldown.
+func (c *YAML) WithCircuitBreakerCooldown(cooldown time.Duration) *YAML {
+	c.breakers.setCooldown(cooldown)
+	return c
+}
+
+// WithMaxToolCallsPerIteration caps the tool calls executed in one iteration at n, as a
+// safety valve against a model emitting far more calls than intended. By default extra
+// calls are skipped: the first n are executed and a "tool_call_limit" observation tells
+// the model how many were skipped. Use WithToolCallOverflow to fail the action over the
+// cap with [ErrTooManyToolCalls] instead. Zero disables the cap, which is the default.
+//
+//	tc := toolchain.NewYAML().
+//	    WithMaxToolCallsPerIteration(10).
+//	    WithToolCallOverflow(toolchain.ToolCallOverflowError)
+//
+// The cap spans all action sections of the response: each Execute gets the calls the
+// earlier ones of the iteration left (see [gent.ExecutionContext.IterationToolUsage]).
+// Without an ExecutionContext it applies to each Execute. Use a limit on
+// [gent.SCToolCalls] to cap tool calls across the whole execution.
+func (c *YAML) WithMaxToolCallsPerIteration(n int) *YAML {
+	c.callCap.max = n
+	return c
+}
+
+// WithToolCallOverflow sets what happens to an action section whose tool calls exceed
+// what WithMaxToolCallsPerIteration leaves. Default: [ToolCallOverflowTruncate].
+func (c *YAML) WithToolCallOverflow(overflow ToolCallOverflow) *YAML {
+	c.callCap.overflow = overflow
+	return c
+}
+
+// ToolHealth returns the rolling stats of a tool guarded by WithCircuitBreaker, and
+// false if the tool has no circuit breaker.
+func (c *YAML) ToolHealth(toolName string) (ToolHealth, bool) {
+	return c.breakers.health(toolName)
+}
+
 // Name returns the section identifier.
 func (c *YAML) Name() string {
 	return c.sectionName
@@ -129,33 +315,62 @@ func (c *YAML) Guidance() string {
 	return sb.String()
 }
 
-// AvailableToolsPrompt returns the tool catalog with parameter schemas for each registered tool.
+// Tools returns the available tools in registration order.
+// Implements [gent.ToolCatalog].
+func (c *YAML) Tools() []gent.ToolInfo {
+	return toolInfos(c.available.filter(c.tools))
+}
+
+// SetAvailableTools makes only the named tools available: the tool catalog, Tools and
+// Execute ignore the others. A nil names makes all registered tools available again.
+// Implements [gent.FilterableToolChain].
+func (c *YAML) SetAvailableTools(names []string) {
+	c.available.set(names)
+}
+
+// AvailableToolsPrompt returns the tool catalog with parameter schemas for each available tool.
+// Tools implementing [gent.OutputSchemaTool] also list their output schema. Tools implementing
+// [gent.CategorizedTool] are listed under a "Category:" heading, after uncategorized tools.
 func (c *YAML) AvailableToolsPrompt() string {
 	var sb strings.Builder
 	sb.WriteString("Available tools:\n")
 
-	for _, tool := range c.tools {
-		meta, err := GetToolMeta(tool)
-		if err != nil {
-			continue
+	for _, group := range groupByCategory(c.available.filter(c.tools)) {
+		if group.category != "" {
+			fmt.Fprintf(&sb, "\nCategory: %s\n", group.category)
 		}
-		fmt.Fprintf(&sb, "\n- %s: %s\n", meta.Name(), meta.Description())
-		if policy := meta.Policy(); policy != "" {
-			sb.WriteString("  Policy: ")
-			sb.WriteString(policy)
-			sb.WriteString("\n")
-		}
-		if schema := meta.Schema(); schema != nil {
-			schemaYAML, err := yaml.Marshal(schema)
-			if err == nil {
-				sb.WriteString("  Parameters:\n")
-				// Indent the YAML schema
-				lines := strings.Split(string(schemaYAML), "\n")
-				for _, line := range lines {
-					if line != "" {
-						sb.WriteString("    ")
-						sb.WriteString(line)
-						sb.WriteString("\n")
+		for _, meta := range group.tools {
+			fmt.Fprintf(&sb, "\n- %s: %s\n", meta.Name(), meta.Description())
+			if policy := meta.Policy(); policy != "" {
+				sb.WriteString("  Policy: ")
+				sb.WriteString(policy)
+				sb.WriteString("\n")
+			}
+			if schema := meta.Schema(); schema != nil {
+				schemaYAML, err := yaml.Marshal(schema)
+				if err == nil {
+					sb.WriteString("  Parameters:\n")
+					// Indent the YAML schema
+					lines := strings.Split(string(schemaYAML), "\n")
+					for _, line := range lines {
+						if line != "" {
+							sb.WriteString("    ")
+							sb.WriteString(line)
+							sb.WriteString("\n")
+						}
+					}
+				}
+			}
+			if outputSchema := meta.OutputSchema(); outputSchema != nil {
+				outputYAML, err := yaml.Marshal(outputSchema)
+				if err == nil {
+					sb.WriteString("  Returns:\n")
+					for _, line := range strings.Split(string(outputYAML), "\n") {
+						if line != "" {
+							sb.WriteString("    ")
+							sb.WriteString(line)
+							sb.WriteString("\n")
+						}
 					}
 				}
 			}
@@ -169,6 +384,12 @@ func (c *YAML) AvailableToolsPrompt() string {
 // It uses schema-aware parsing to preserve string types where the schema expects strings.
 func (c *YAML) ParseSection(execCtx *gent.ExecutionContext, content string) (any, error) {
 	result, err := c.doParse(content)
+	if err == nil {
+		err = c.callCap.check(result, iterationToolUsage(execCtx).Calls)
+	}
+	if err == nil {
+		err = validateParsedArgs(result, c.toolMap, c.schemaMap)
+	}
 	if err != nil {
 		// Publish parse error event (auto-updates stats)
 		if execCtx != nil {
@@ -185,17 +406,23 @@ func (c *YAML) ParseSection(execCtx *gent.ExecutionContext, content string) (any
 	return result, nil
 }
 
-// doParse performs the actual parsing logic.
+// doParse performs the actual parsing logic. Errors locate the failure in content as a
+// [gent.ParseError] where the position is known.
 func (c *YAML) doParse(content string) ([]*gent.ToolCall, error) {
-	content = strings.TrimSpace(content)
-	if content == "" {
+	normalized, shift := normalizeYAMLContent(content)
+	if normalized == "" {
 		return []*gent.ToolCall{}, nil
 	}
 
 	// Parse into yaml.Node to preserve raw values
 	var rootNode yaml.Node
-	if err := yaml.Unmarshal([]byte(content), &rootNode); err != nil {
-		return nil, fmt.Errorf("%w: %v", gent.ErrInvalidYAML, err)
+	if err := yaml.Unmarshal([]byte(normalized), &rootNode); err != nil {
+		parseErr := fmt.Errorf("%w: %v", gent.ErrInvalidYAML, err)
+		line, expected, ok := yamlErrorLocation(err)
+		if !ok {
+			return nil, parseErr
+		}
+		return nil, shift.locate(parseErr, content, line, 1, expected...)
 	}
 
 	// Root node is a document node, get its content
@@ -212,7 +439,7 @@ func (c *YAML) doParse(content string) ([]*gent.ToolCall, error) {
 		for _, itemNode := range contentNode.Content {
 			call, err := c.parseToolCallNode(itemNode)
 			if err != nil {
-				return nil, err
+				return nil, shift.locate(err, content, itemNode.Line, itemNode.Column)
 			}
 			calls = append(calls, call)
 		}
@@ -220,16 +447,122 @@ func (c *YAML) doParse(content string) ([]*gent.ToolCall, error) {
 		// Single tool call
 		call, err := c.parseToolCallNode(contentNode)
 		if err != nil {
-			return nil, err
+			return nil, shift.locate(err, content, contentNode.Line, contentNode.Column)
 		}
 		calls = append(calls, call)
 	default:
-		return nil, fmt.Errorf("%w: expected mapping or sequence", gent.ErrInvalidYAML)
+		err := fmt.Errorf("%w: expected mapping or sequence", gent.ErrInvalidYAML)
+		return nil, shift.locate(err, content, contentNode.Line, contentNode.Column,
+			"mapping", "sequence")
 	}
 
 	return calls, nil
 }
 
+// yamlErrorPattern matches the line and message of a yaml.v3 syntax error.
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlExpectedPattern matches the expected token in a yaml.v3 syntax error message,
+// e.g. "did not find expected ',' or ']'".
+var yamlExpectedPattern = regexp.MustCompile(`(?:did not|could not) find expected (.+)$`)
+
+// yamlParserProblems are the yaml.v3 errors raised by its parser rather than its
+// scanner. yaml.v3 reports 0-based lines for these, and for errors inside a collection,
+// the line where the collection starts.
+var yamlParserProblems = map[string]bool{
+	"did not find expected <stream-start>":   true,
+	"did not find expected <document start>": true,
+	"did not find expected node content":     true,
+	"did not find expected '-' indicator":    true,
+	"did not find expected key":              true,
+	"did not find expected ',' or ']'":       true,
+	"did not find expected ',' or '}'":       true,
+	"found undefined tag handle":             true,
+	"found duplicate %YAML directive":        true,
+	"found incompatible YAML document":       true,
+	"found duplicate %TAG directive":         true,
+}
+
+// yamlErrorLocation extracts the 1-based line and, if the message names it, the
+// expected token from a yaml.v3 syntax error.
+func yamlErrorLocation(err error) (line int, expected []string, ok bool) {
+	match := yamlErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, nil, false
+	}
+	line, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return 0, nil, false
+	}
+	if yamlParserProblems[match[2]] {
+		line++
+	}
+	if found := yamlExpectedPattern.FindStringSubmatch(match[2]); found != nil {
+		expected = strings.Split(found[1], " or ")
+	}
+	return line, expected, true
+}
+
+// yamlShift records how [normalizeYAMLContent] moved lines and columns, to map positions
+// in the normalized content back to the original content.
+type yamlShift struct {
+	lines  int // leading blank lines dropped
+	indent int // common indentation removed from each line
+}
+
+// locate returns err as a [gent.ParseError] at the given 1-based position in the
+// normalized content, mapped back to content.
+func (s yamlShift) locate(
+	err error,
+	content string,
+	line, column int,
+	expected ...string,
+) error {
+	return gent.NewParseErrorAtLine(err, content, line+s.lines, column+s.indent, expected...)
+}
+
+// normalizeYAMLContent prepares section content for YAML parsing. Surrounding blank
+// lines are dropped and the indentation shared by all lines is removed, so content that
+// is indented as a whole parses the same as unindented content. Unlike trimming, this
+// keeps the relative indentation of block scalars intact, and the content ends with a
+// newline so a trailing "|" block scalar keeps its final newline. The returned yamlShift
+// maps positions in the result back to content.
+func normalizeYAMLContent(content string) (string, yamlShift) {
+	var shift yamlShift
+	lines := strings.Split(content, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+		shift.lines++
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return "", shift
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+	shift.indent = indent
+
+	for i, line := range lines {
+		if len(line) >= indent {
+			lines[i] = strings.TrimRight(line[indent:], "\r")
+		} else {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", shift
+}
+
 // parseToolCallNode parses a single tool call from a yaml.Node.
 func (c *YAML) parseToolCallNode(node *yaml.Node) (*gent.ToolCall, error) {
 	if node.Kind != yaml.MappingNode {
@@ -257,41 +590,101 @@ func (c *YAML) parseToolCallNode(node *yaml.Node) (*gent.ToolCall, error) {
 	}
 
 	// Get the schema for this tool to guide parsing
-	var propTypes map[string]string
+	var props map[string]yamlProperty
 	if rawSchema, ok := c.rawSchemaMap[toolName]; ok {
-		propTypes = extractPropertyTypes(rawSchema)
+		props = extractProperties(rawSchema)
 	}
 
 	// Parse args with schema awareness
 	var args map[string]any
 	if argsNode != nil {
-		args = c.decodeArgsNode(argsNode, propTypes)
+		var err error
+		if args, err = c.decodeArgsNode(argsNode, props); err != nil {
+			return nil, err
+		}
 	}
 
 	return &gent.ToolCall{Name: toolName, Args: args}, nil
 }
 
-// extractPropertyTypes extracts a map of property name -> type from a raw schema.
-func extractPropertyTypes(rawSchema map[string]any) map[string]string {
-	result := make(map[string]string)
+// yamlProperty is what the schema says about a tool argument, as used to decode it.
+type yamlProperty struct {
+	typ      string // JSON Schema type, not counting "null"
+	nullable bool   // the type includes "null"
+	required bool
+}
+
+// extractProperties extracts the type, nullability and requiredness of each property of
+// a raw schema.
+func extractProperties(rawSchema map[string]any) map[string]yamlProperty {
+	result := make(map[string]yamlProperty)
 	props, ok := rawSchema["properties"].(map[string]any)
 	if !ok {
 		return result
 	}
 	for name, propDef := range props {
-		if propMap, ok := propDef.(map[string]any); ok {
-			if typ, ok := propMap["type"].(string); ok {
-				result[name] = typ
+		propMap, ok := propDef.(map[string]any)
+		if !ok {
+			continue
+		}
+		var prop yamlProperty
+		switch typ := propMap["type"].(type) {
+		case string:
+			prop.typ = typ
+			prop.nullable = typ == "null"
+		case []any:
+			for _, t := range typ {
+				if t == "null" {
+					prop.nullable = true
+				} else if s, ok := t.(string); ok && prop.typ == "" {
+					prop.typ = s
+				}
+			}
+		case []string:
+			for _, t := range typ {
+				if t == "null" {
+					prop.nullable = true
+				} else if prop.typ == "" {
+					prop.typ = t
+				}
+			}
+		}
+		result[name] = prop
+	}
+
+	switch required := rawSchema["required"].(type) {
+	case []string:
+		for _, name := range required {
+			markRequired(result, name)
+		}
+	case []any:
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				markRequired(result, s)
 			}
 		}
 	}
 	return result
 }
 
-// decodeArgsNode decodes args from a yaml.Node using schema type hints.
-func (c *YAML) decodeArgsNode(node *yaml.Node, propTypes map[string]string) map[string]any {
+// markRequired marks the named property as required.
+func markRequired(props map[string]yamlProperty, name string) {
+	prop := props[name]
+	prop.required = true
+	props[name] = prop
+}
+
+// decodeArgsNode decodes args from a yaml.Node using the schema's properties.
+//
+// A null value (null, ~ or, outside string properties, an empty value) stays nil for
+// nullable properties and properties the schema does not describe, is dropped for other
+// optional properties, and is an error for required ones.
+func (c *YAML) decodeArgsNode(
+	node *yaml.Node,
+	props map[string]yamlProperty,
+) (map[string]any, error) {
 	if node.Kind != yaml.MappingNode {
-		return nil
+		return nil, nil
 	}
 
 	result := make(map[string]any)
@@ -299,19 +692,52 @@ func (c *YAML) decodeArgsNode(node *yaml.Node, propTypes map[string]string) map[
 		keyNode := node.Content[i]
 		valueNode := node.Content[i+1]
 		key := keyNode.Value
+		prop := props[key]
 
-		// Check if schema expects a string for this property
-		expectedType := propTypes[key]
-		result[key] = c.decodeValueNode(valueNode, expectedType)
+		if isYAMLNull(valueNode, prop.typ) {
+			switch {
+			case prop.nullable || prop.typ == "":
+				result[key] = nil
+			case prop.required:
+				return nil, fmt.Errorf(
+					"%w: %q is required and cannot be null", gent.ErrInvalidToolArgs, key)
+			}
+			continue
+		}
+
+		result[key] = c.decodeValueNode(valueNode, prop.typ)
 	}
-	return result
+	return result, nil
+}
+
+// isYAMLNull reports whether node is a null scalar. An empty value for a string property
+// is the empty string rather than null.
+func isYAMLNull(node *yaml.Node, expectedType string) bool {
+	if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!null" {
+		return false
+	}
+	return expectedType != "string" || node.Value != ""
+}
+
+// yamlBooleans maps the YAML 1.1 boolean words to their value. yaml.v3 follows YAML 1.2,
+// where only true and false are booleans.
+var yamlBooleans = map[string]bool{
+	"true": true, "yes": true, "on": true,
+	"false": false, "no": false, "off": false,
 }
 
 // decodeValueNode decodes a single value node, using expectedType to guide decoding.
 func (c *YAML) decodeValueNode(node *yaml.Node, expectedType string) any {
-	// If schema expects a string, use the raw value regardless of YAML's auto-detection
-	if expectedType == "string" && node.Kind == yaml.ScalarNode {
-		return node.Value
+	if node.Kind == yaml.ScalarNode {
+		switch expectedType {
+		case "string":
+			// Use the raw value regardless of YAML's auto-detection
+			return node.Value
+		case "boolean":
+			if b, ok := yamlBooleans[strings.ToLower(node.Value)]; ok {
+				return b
+			}
+		}
 	}
 
 	// Otherwise, let YAML decode naturally
@@ -386,7 +812,8 @@ func (c *YAML) Execute(
 		return nil, err
 	}
 
-	calls := parsed.([]*gent.ToolCall)
+	usage := iterationToolUsage(execCtx)
+	calls, skipped := c.callCap.truncate(parsed.([]*gent.ToolCall), usage.Calls)
 	raw := &gent.RawToolChainResult{
 		Calls:   calls,
 		Results: make([]*gent.RawToolCallResult, len(calls)),
@@ -399,7 +826,25 @@ func (c *YAML) Execute(
 
 	for i, call := range calls {
 		tool, ok := c.toolMap[call.Name]
-		if !ok {
+		if message, unavailable := c.available.unavailableMessage(call.Name, ok); unavailable {
+			raw.Errors[i] = fmt.Errorf("%w: %s", gent.ErrToolUnavailable, call.Name)
+			sections = append(sections, gent.FormattedSection{Name: call.Name, Content: message})
+			if execCtx != nil {
+				execCtx.PublishAfterToolCall(call.Name, call.Args, nil, 0, raw.Errors[i])
+			}
+			continue
+		}
+		if !ok || !c.available.allows(call.Name) {
+			if output, handled := callUnknownToolHandler(
+				execCtx, ctx, c.unknownTool, call,
+			); handled {
+				raw.Results[i] = &gent.RawToolCallResult{Name: call.Name, Output: output}
+				sections = append(sections, gent.FormattedSection{
+					Name:    call.Name,
+					Content: c.obsLimits.apply(call.Name, output),
+				})
+				continue
+			}
 			raw.Errors[i] = fmt.Errorf("%w: %s", gent.ErrUnknownTool, call.Name)
 			// Add error as a section
 			sections = append(sections, gent.FormattedSection{
@@ -419,13 +864,38 @@ func (c *YAML) Execute(
 			continue
 		}
 
+		// Tool call loop: answer a repeated identical call without executing it
+		if execCtx != nil {
+			if rep := execCtx.CheckRepeatedToolCall(call.Name, call.Args); rep != nil {
+				output, content, repErr := resolveRepeatedCall(rep, func(v any) (string, error) {
+					data, err := marshalYAMLOutput(v)
+					rendered := formatObservation(
+						tool, call.Name, v, strings.TrimSpace(string(data)),
+					)
+					return c.obsLimits.apply(call.Name, rendered), err
+				})
+				if repErr != nil {
+					raw.Errors[i] = repErr
+				} else {
+					raw.Results[i] = &gent.RawToolCallResult{
+						Name:     call.Name,
+						Output:   output,
+						Terminal: isTerminal(tool),
+					}
+				}
+				sections = append(sections, gent.FormattedSection{Name: call.Name, Content: content})
+				execCtx.PublishAfterToolCall(call.Name, call.Args, output, 0, repErr)
+				continue
+			}
+		}
+
 		// Validate args against schema before transformation
 		if compiledSchema, hasSchema := c.schemaMap[call.Name]; hasSchema {
 			if validationErr := compiledSchema.Validate(call.Args); validationErr != nil {
 				raw.Errors[i] = validationErr
 				sections = append(sections, gent.FormattedSection{
 					Name:    call.Name,
-					Content: fmt.Sprintf("Error: %v", validationErr),
+					Content: ErrorObservation(validationErr),
 				})
 
 				if execCtx != nil {
@@ -442,7 +912,7 @@ func (c *YAML) Execute(
 			raw.Errors[i] = transformErr
 			sections = append(sections, gent.FormattedSection{
 				Name:    call.Name,
-				Content: fmt.Sprintf("Error: %v", transformErr),
+				Content: ErrorObservation(transformErr),
 			})
 			if execCtx != nil {
 				execCtx.PublishAfterToolCall(call.Name, call.Args, nil, 0, transformErr)
@@ -457,15 +927,42 @@ func (c *YAML) Execute(
 			inputToUse = beforeEvent.Args
 		}
 
-		startTime := time.Now()
+		// Dry run: stub side-effecting tools without calling them
+		if shouldStubDryRun(execCtx, c.dryRunStubs, tool) {
+			stub := DryRunOutput(call.Name)
+			raw.Results[i] = &gent.RawToolCallResult{
+				Name:     call.Name,
+				Output:   stub,
+				Terminal: isTerminal(tool),
+			}
+			sections = append(sections, gent.FormattedSection{Name: call.Name, Content: stub})
+			execCtx.PublishAfterToolCall(call.Name, inputToUse, stub, 0, nil)
+			continue
+		}
+
+		// Circuit breaker: fail fast while the tool keeps failing
+		if openErr := c.breakers.allow(call.Name, execCtx.Clock().Now()); openErr != nil {
+			raw.Errors[i] = openErr
+			sections = append(sections, gent.FormattedSection{
+				Name:    call.Name,
+				Content: circuitOpenObservation(openErr),
+			})
+			if execCtx != nil {
+				execCtx.PublishAfterToolCall(call.Name, inputToUse, nil, 0, openErr)
+			}
+			continue
+		}
+
+		startTime := execCtx.Clock().Now()
 		output, err := CallToolWithTypedInputReflect(ctx, tool, inputToUse)
-		duration := time.Since(startTime)
+		duration := execCtx.Clock().Now().Sub(startTime)
+		c.breakers.record(call.Name, execCtx.Clock().Now(), countsAsToolFailure(err), duration)
 
 		if err != nil {
 			raw.Errors[i] = err
 			sections = append(sections, gent.FormattedSection{
 				Name:    call.Name,
-				Content: fmt.Sprintf("Error: %v", err),
+				Content: ErrorObservation(err),
 			})
 		} else {
 			// Successful tool call - reset consecutive error gauges
@@ -476,31 +973,36 @@ func (c *YAML) Execute(
 
 			// Store raw result
 			raw.Results[i] = &gent.RawToolCallResult{
-				Name:   output.Name,
-				Output: output.Text,
+				Name:     output.Name,
+				Output:   output.Text,
+				Terminal: isTerminal(tool),
 			}
 
 			// Format output as YAML
-			yamlData, marshalErr := yaml.Marshal(output.Text)
+			yamlData, marshalErr := marshalYAMLOutput(output.Text)
 			if marshalErr != nil {
 				sections = append(sections, gent.FormattedSection{
 					Name:    call.Name,
 					Content: "error: failed to marshal output",
 				})
 			} else {
+				rendered := formatObservation(
+					tool, call.Name, output.Text, strings.TrimSpace(string(yamlData)),
+				)
+				content := c.obsLimits.apply(call.Name, rendered)
 				// If instructions present, create nested sections as children
 				if output.Instructions != "" {
 					sections = append(sections, gent.FormattedSection{
 						Name: call.Name,
 						Children: []gent.FormattedSection{
-							{Name: "result", Content: strings.TrimSpace(string(yamlData))},
+							{Name: "result", Content: content},
 							{Name: "instructions", Content: output.Instructions},
 						},
 					})
 				} else {
 					sections = append(sections, gent.FormattedSection{
 						Name:    call.Name,
-						Content: strings.TrimSpace(string(yamlData)),
+						Content: content,
 					})
 				}
 			}
@@ -521,6 +1023,15 @@ func (c *YAML) Execute(
 		}
 	}
 
+	observed := c.obsLimits.capCombined(
+		toolOutputs(sections, raw.Results), usage.ObservationBytes)
+	if execCtx != nil {
+		execCtx.AddIterationToolUsage(len(calls), observed)
+	}
+	if skipped != nil {
+		sections = append(sections, *skipped)
+	}
+
 	// Build formatted text using TextFormat
 	return &gent.ToolChainResult{
 		Text:  textFormat.FormatSections(sections),
@@ -529,6 +1040,49 @@ func (c *YAML) Execute(
 	}, nil
 }
 
+// marshalYAMLOutput renders a tool output as YAML. Outputs other than strings are
+// rendered through their JSON encoding, so fields use their json tag names and match
+// the output schema shown in the tool catalog.
+func marshalYAMLOutput(output any) ([]byte, error) {
+	if _, ok := output.(string); ok || output == nil {
+		return yaml.Marshal(output)
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(normalizeJSONNumbers(value))
+}
+
+// normalizeJSONNumbers replaces the json.Number values in a decoded JSON value with
+// int64 or float64, so YAML renders them as plain numbers.
+func normalizeJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeJSONNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeJSONNumbers(item)
+		}
+	}
+	return value
+}
+
 // GetToolSchema returns the compiled schema for the
 // named tool, or nil if not found.
 func (c *YAML) GetToolSchema(
@@ -540,5 +1094,14 @@ func (c *YAML) GetToolSchema(
 // Compile-time check that YAML implements gent.ToolChain.
 var _ gent.ToolChain = (*YAML)(nil)
 
+// Compile-time check that YAML implements gent.ToolCatalog.
+var _ gent.ToolCatalog = (*YAML)(nil)
+
+// Compile-time check that YAML implements gent.FilterableToolChain.
+var _ gent.FilterableToolChain = (*YAML)(nil)
+
+// Compile-time check that YAML implements gent.MultiplicitySection.
+var _ gent.MultiplicitySection = (*YAML)(nil)
+
 // Compile-time check that YAML implements SchemaProvider.
 var _ SchemaProvider = (*YAML)(nil)
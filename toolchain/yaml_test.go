@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -225,6 +226,149 @@ func TestYAML_AvailableToolsPrompt(t *testing.T) {
 	}
 }
 
+// orderStatus is the structured output type used by NewStructuredToolFunc tests.
+type orderStatus struct {
+	OrderID  string `json:"order_id"`
+	Status   string `json:"status" description:"Order status"`
+	Tracking string `json:"tracking,omitempty"`
+}
+
+// newLookupOrderTool creates a structured tool returning orderStatus.
+func newLookupOrderTool() *gent.ToolFunc[map[string]any, orderStatus] {
+	return gent.NewStructuredToolFunc(
+		"lookup_order",
+		"Look up an order",
+		schema.Object(map[string]*schema.Property{
+			"order_id": schema.String("Order ID"),
+		}, "order_id"),
+		func(ctx context.Context, input map[string]any) (orderStatus, error) {
+			return orderStatus{OrderID: "A1", Status: "shipped", Tracking: "1Z999"}, nil
+		},
+	)
+}
+
+func TestYAML_StructuredToolFunc(t *testing.T) {
+	tc := NewYAML()
+	tc.RegisterTool(newLookupOrderTool())
+
+	expectedCatalog := `Available tools:
+
+- lookup_order: Look up an order
+  Parameters:
+    properties:
+        order_id:
+            description: Order ID
+            type: string
+    required:
+        - order_id
+    type: object
+  Returns:
+    properties:
+        order_id:
+            type: string
+        status:
+            description: Order status
+            type: string
+        tracking:
+            type: string
+    required:
+        - order_id
+        - status
+    type: object
+`
+	assert.Equal(t, expectedCatalog, tc.AvailableToolsPrompt())
+
+	result, err := tc.Execute(
+		nil, "tool: lookup_order\nargs:\n  order_id: A1", yamlTestFormat())
+
+	require.NoError(t, err)
+	// Fields are rendered by their json tag names, as in the output schema
+	assert.Equal(t,
+		"<lookup_order>\norder_id: A1\nstatus: shipped\ntracking: 1Z999\n</lookup_order>",
+		result.Text)
+	assert.Equal(t, orderStatus{OrderID: "A1", Status: "shipped", Tracking: "1Z999"},
+		result.Raw.Results[0].Output)
+}
+
+func TestYAML_ParseSection_DynamicTool(t *testing.T) {
+	tool, err := gent.NewDynamicTool(
+		"forecast",
+		"Weather forecast",
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			"required":   []any{"city"},
+		},
+		func(ctx context.Context, args map[string]any) (any, error) { return "sunny", nil },
+	)
+	require.NoError(t, err)
+	tc := NewYAML()
+	tc.RegisterTool(tool)
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+	calls, err := tc.ParseSection(execCtx, "tool: forecast\nargs:\n  units: metric")
+
+	assert.Nil(t, calls)
+	assert.ErrorIs(t, err, gent.ErrInvalidToolInput)
+	assert.ErrorContains(t, err, "missing property 'city'")
+	assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCToolchainParseErrorTotal))
+}
+
+func TestMarshalYAMLOutput(t *testing.T) {
+	type input struct {
+		output any
+	}
+
+	type expected struct {
+		yaml string
+	}
+
+	type shipment struct {
+		OrderID string  `json:"order_id"`
+		Items   int     `json:"items"`
+		Weight  float64 `json:"weight_kg"`
+		Notes   []any   `json:"notes,omitempty"`
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "string is rendered as is",
+			input:    input{output: "done"},
+			expected: expected{yaml: "done\n"},
+		},
+		{
+			name:  "struct fields use json tag names",
+			input: input{output: shipment{OrderID: "A1", Items: 1000000, Weight: 2.5}},
+			expected: expected{
+				yaml: "items: 1000000\norder_id: A1\nweight_kg: 2.5\n",
+			},
+		},
+		{
+			name: "nested numbers stay plain",
+			input: input{output: []shipment{
+				{OrderID: "B2", Items: 3, Notes: []any{7, "fragile"}},
+			}},
+			expected: expected{
+				yaml: "- items: 3\n  notes:\n    - 7\n    - fragile\n" +
+					"  order_id: B2\n  weight_kg: 0\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := marshalYAMLOutput(tc.input.output)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.yaml, string(data))
+		})
+	}
+}
+
 func TestYAML_ParseSection(t *testing.T) {
 	type input struct {
 		content string
@@ -333,7 +477,7 @@ args:
 					{
 						Name: "write",
 						Args: map[string]any{
-							"content": "This is a multi-line\nstring argument that\nspans multiple lines.",
+							"content": "This is a multi-line\nstring argument that\nspans multiple lines.\n",
 						},
 					},
 				},
@@ -444,83 +588,672 @@ args: {}`,
 args: {}`,
 			},
 			expected: expected{
-				callCount: 1,
-				results:   []string{""},
-				errors:    []error{errors.New("tool execution failed")},
+				callCount: 1,
+				results:   []string{""},
+				errors:    []error{errors.New("tool execution failed")},
+			},
+		},
+		{
+			name: "multiple tools",
+			mocks: []mockTool{
+				{
+					name:        "search",
+					description: "Search",
+					fn: func(ctx context.Context, args map[string]any) (string, error) {
+						return "search result", nil
+					},
+				},
+				{
+					name:        "calendar",
+					description: "Calendar",
+					fn: func(ctx context.Context, args map[string]any) (string, error) {
+						return "calendar result", nil
+					},
+				},
+			},
+			input: input{
+				content: `- tool: search
+  args: {}
+- tool: calendar
+  args: {}`,
+			},
+			expected: expected{
+				callCount:   2,
+				results:     []string{"search result", "calendar result"},
+				errors:      []error{nil, nil},
+				resultNames: []string{"search", "calendar"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML()
+			for _, mock := range tt.mocks {
+				tool := gent.NewToolFunc(
+					mock.name,
+					mock.description,
+					mock.schema,
+					mock.fn,
+				)
+				tc.RegisterTool(tool)
+			}
+
+			result, err := tc.Execute(nil, tt.input.content, yamlTestFormat())
+
+			if tt.expected.executeErr != nil {
+				assert.ErrorIs(t, err, tt.expected.executeErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, result.Raw.Calls, tt.expected.callCount)
+
+			for i, expectedResult := range tt.expected.results {
+				if tt.expected.errors[i] != nil {
+					assert.Error(t, result.Raw.Errors[i])
+					if errors.Is(tt.expected.errors[i], gent.ErrUnknownTool) {
+						assert.ErrorIs(t, result.Raw.Errors[i], gent.ErrUnknownTool)
+					} else {
+						assert.Equal(t, tt.expected.errors[i].Error(), result.Raw.Errors[i].Error())
+					}
+				} else {
+					assert.NoError(t, result.Raw.Errors[i])
+					assert.Equal(t, expectedResult, getYAMLOutputString(result.Raw.Results[i].Output))
+					if len(tt.expected.resultNames) > i {
+						assert.Equal(t, tt.expected.resultNames[i], result.Raw.Results[i].Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestYAML_Execute_ToolInputValidation(t *testing.T) {
+	type bookingInput struct {
+		Start int `json:"start"`
+		End   int `json:"end"`
+	}
+
+	type input struct {
+		content string
+	}
+
+	type expected struct {
+		called     bool
+		text       string
+		errorTotal int64
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "valid input runs the tool",
+			input: input{content: "tool: book\nargs:\n  start: 9\n  end: 10"},
+			expected: expected{
+				called:     true,
+				text:       "<book>\nbooked 9-10\n</book>",
+				errorTotal: 0,
+			},
+		},
+		{
+			name:  "invalid input is reported without running the tool",
+			input: input{content: "tool: book\nargs:\n  start: 10\n  end: 9"},
+			expected: expected{
+				called: false,
+				text: "<book>\nError: invalid tool input: end must be after start. " +
+					"The arguments were invalid; correct them before calling this tool " +
+					"again.\n</book>",
+				errorTotal: 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			tool := gent.NewToolFunc(
+				"book", "Book a room", nil,
+				func(_ context.Context, input bookingInput) (string, error) {
+					called = true
+					return fmt.Sprintf("booked %d-%d", input.Start, input.End), nil
+				},
+			).WithValidate(func(input bookingInput) error {
+				if input.End <= input.Start {
+					return errors.New("end must be after start")
+				}
+				return nil
+			})
+			tc := NewYAML()
+			tc.RegisterTool(tool)
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+
+			result, err := tc.Execute(execCtx, tt.input.content, testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.called, called)
+			assert.Equal(t, tt.expected.text, result.Text)
+			assert.Equal(t, tt.expected.errorTotal,
+				execCtx.Stats().GetCounter(gent.SCToolCallsErrorTotal))
+			if tt.expected.errorTotal > 0 {
+				assert.ErrorIs(t, result.Raw.Errors[0], gent.ErrInvalidToolInput)
+			} else {
+				assert.NoError(t, result.Raw.Errors[0])
+			}
+		})
+	}
+}
+
+func TestYAML_Execute_DryRunStubs(t *testing.T) {
+	type input struct {
+		dryRun       bool
+		stubsEnabled bool
+		sideEffects  bool
+	}
+
+	type expected struct {
+		called      bool
+		text        string
+		eventDryRun bool
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "dry run stubs side-effecting tool",
+			input: input{dryRun: true, stubsEnabled: true, sideEffects: true},
+			expected: expected{
+				called:      false,
+				text:        "<refund>\n[dry-run] would execute refund\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "dry run calls tool without side effects",
+			input: input{dryRun: true, stubsEnabled: true, sideEffects: false},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\nrefunded A1\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "dry run without stubs calls tool",
+			input: input{dryRun: true, stubsEnabled: false, sideEffects: true},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\nrefunded A1\n</refund>",
+				eventDryRun: true,
+			},
+		},
+		{
+			name:  "regular run calls side-effecting tool",
+			input: input{dryRun: false, stubsEnabled: true, sideEffects: true},
+			expected: expected{
+				called:      true,
+				text:        "<refund>\nrefunded A1\n</refund>",
+				eventDryRun: false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			tool := gent.NewToolFunc(
+				"refund", "Issue a refund", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					called = true
+					return fmt.Sprintf("refunded %v", args["order"]), nil
+				},
+			)
+			if tt.input.sideEffects {
+				tool.WithSideEffects()
+			}
+			tc := NewYAML().WithDryRunStubs(tt.input.stubsEnabled)
+			tc.RegisterTool(tool)
+
+			goCtx := context.Background()
+			if tt.input.dryRun {
+				goCtx = gent.WithDryRun(goCtx)
+			}
+			execCtx := gent.NewExecutionContext(goCtx, "test", nil)
+
+			result, err := tc.Execute(execCtx, "tool: refund\nargs:\n  order: A1", testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.called, called)
+			assert.Equal(t, tt.expected.text, result.Text)
+			assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCToolCalls))
+
+			var afterEvents []*gent.AfterToolCallEvent
+			for _, event := range execCtx.Events() {
+				if after, ok := event.(*gent.AfterToolCallEvent); ok {
+					afterEvents = append(afterEvents, after)
+				}
+			}
+			require.Len(t, afterEvents, 1)
+			assert.Equal(t, tt.expected.eventDryRun, afterEvents[0].DryRun)
+		})
+	}
+}
+
+func TestYAML_Execute_RepeatedToolCall(t *testing.T) {
+	type expected struct {
+		calls int
+		text  string
+		err   error
+	}
+
+	tests := []struct {
+		name     string
+		input    gent.RepeatedToolCallAction
+		expected expected
+	}{
+		{
+			name:  "nudge",
+			input: gent.RepeatedToolCallNudge,
+			expected: expected{
+				calls: 1,
+				text: "<lookup>\nYou already called lookup with these exact arguments 2 times " +
+					"in a row, so it was not called again. The previous result was:\n" +
+					"status of A1\nUse this result, or try a different tool or different " +
+					"arguments.\n</lookup>",
+			},
+		},
+		{
+			name:  "cached result",
+			input: gent.RepeatedToolCallCachedResult,
+			expected: expected{
+				calls: 1,
+				text:  "<lookup>\nstatus of A1\n</lookup>",
+			},
+		},
+		{
+			name:  "terminate",
+			input: gent.RepeatedToolCallTerminate,
+			expected: expected{
+				calls: 1,
+				text: "<lookup>\nError: repeated tool call: lookup called 2 times in a row " +
+					"with identical arguments\n</lookup>",
+				err: gent.ErrRepeatedToolCall,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tc := NewYAML()
+			tc.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up an order", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					calls++
+					return fmt.Sprintf("status of %v", args["order"]), nil
+				},
+			))
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetRepeatedToolCallPolicy(gent.RepeatedToolCallPolicy{
+				Threshold: 2,
+				Action:    tt.input,
+			})
+
+			content := "tool: lookup\nargs:\n  order: A1"
+			_, err := tc.Execute(execCtx, content, testFormat())
+			require.NoError(t, err)
+			result, err := tc.Execute(execCtx, content, testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.calls, calls)
+			assert.Equal(t, tt.expected.text, result.Text)
+			if tt.expected.err != nil {
+				assert.ErrorIs(t, result.Raw.Errors[0], tt.expected.err)
+			} else {
+				assert.NoError(t, result.Raw.Errors[0])
+			}
+			assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCToolCalls))
+			assert.Equal(t, int64(1), execCtx.Stats().GetCounter(gent.SCRepeatedToolCalls))
+		})
+	}
+}
+
+func TestYAML_Execute_RepeatedToolCall_ObservationFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    gent.RepeatedToolCallAction
+		expected string
+	}{
+		{
+			name:  "nudge",
+			input: gent.RepeatedToolCallNudge,
+			expected: "<lookup>\nYou already called lookup with these exact arguments 2 " +
+				"times in a row, so it was not called again. The previous result was:\n" +
+				"## lookup\nstatus of A1\nUse this result, or try a different tool or " +
+				"different arguments.\n</lookup>",
+		},
+		{
+			name:     "cached result",
+			input:    gent.RepeatedToolCallCachedResult,
+			expected: "<lookup>\n## lookup\nstatus of A1\n</lookup>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML()
+			tc.RegisterTool(gent.NewToolFunc(
+				"lookup", "Look up an order", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					return fmt.Sprintf("status of %v", args["order"]), nil
+				},
+			).WithObservationFormatter(func(name string, output string) string {
+				return "## " + name + "\n" + output
+			}))
+
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.SetRepeatedToolCallPolicy(gent.RepeatedToolCallPolicy{
+				Threshold: 2,
+				Action:    tt.input,
+			})
+
+			content := "tool: lookup\nargs:\n  order: A1"
+			_, err := tc.Execute(execCtx, content, testFormat())
+			require.NoError(t, err)
+			result, err := tc.Execute(execCtx, content, testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, result.Text)
+		})
+	}
+}
+
+func TestYAML_Execute_TerminalResult(t *testing.T) {
+	type input struct {
+		dryRun   bool
+		repeated bool
+	}
+
+	tests := []struct {
+		name  string
+		input input
+	}{
+		{name: "executed call", input: input{}},
+		{name: "repeated call answered from cache", input: input{repeated: true}},
+		{name: "dry run stub", input: input{dryRun: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML().WithDryRunStubs(true)
+			tc.RegisterTool(gent.NewToolFunc(
+				"submit", "Submit the order", nil,
+				func(_ context.Context, args map[string]any) (string, error) {
+					return fmt.Sprintf("submitted %v", args["order"]), nil
+				},
+			).WithSideEffects().WithTerminal())
+
+			goCtx := context.Background()
+			if tt.input.dryRun {
+				goCtx = gent.WithDryRun(goCtx)
+			}
+			execCtx := gent.NewExecutionContext(goCtx, "test", nil)
+			execCtx.SetRepeatedToolCallPolicy(gent.RepeatedToolCallPolicy{
+				Threshold: 2,
+				Action:    gent.RepeatedToolCallCachedResult,
+			})
+
+			content := "tool: submit\nargs:\n  order: A1"
+			if tt.input.repeated {
+				_, err := tc.Execute(execCtx, content, testFormat())
+				require.NoError(t, err)
+			}
+			result, err := tc.Execute(execCtx, content, testFormat())
+			require.NoError(t, err)
+
+			require.NotNil(t, result.Raw.Results[0])
+			assert.True(t, result.Raw.Results[0].Terminal)
+		})
+	}
+}
+
+func TestYAML_Execute_MaxCombinedObservationBytes(t *testing.T) {
+	type input struct {
+		maxCombined int
+		maxBytes    int
+	}
+
+	type expected struct {
+		text string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "no limit by default",
+			input: input{},
+			expected: expected{
+				text: "<small>\n" + strings.Repeat("s", 10) + "\n</small>\n" +
+					"<medium>\n" + strings.Repeat("m", 40) + "\n</medium>\n" +
+					"<large>\n" + strings.Repeat("l", 60) + "\n</large>",
+			},
+		},
+		{
+			name:  "combined output within limit unchanged",
+			input: input{maxCombined: 110},
+			expected: expected{
+				text: "<small>\n" + strings.Repeat("s", 10) + "\n</small>\n" +
+					"<medium>\n" + strings.Repeat("m", 40) + "\n</medium>\n" +
+					"<large>\n" + strings.Repeat("l", 60) + "\n</large>",
+			},
+		},
+		{
+			name:  "largest outputs truncated to a common length",
+			input: input{maxCombined: 100},
+			expected: expected{
+				text: "<small>\n" + strings.Repeat("s", 10) + "\n</small>\n" +
+					"<medium>\n" + strings.Repeat("m", 18) +
+					"\n[truncated 22 of 40 bytes]\n</medium>\n" +
+					"<large>\n" + strings.Repeat("l", 18) +
+					"\n[truncated 42 of 60 bytes]\n</large>",
+			},
+		},
+		{
+			name:  "applied after per-tool limits",
+			input: input{maxCombined: 100, maxBytes: 30},
+			expected: expected{
+				text: "<small>\n" + strings.Repeat("s", 10) + "\n</small>\n" +
+					"<medium>\n" + strings.Repeat("m", 18) +
+					"\n[truncated 22 of 40 bytes]\n</medium>\n" +
+					"<large>\n" + strings.Repeat("l", 18) +
+					"\n[truncated 42 of 60 bytes]\n</large>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML().
+				WithMaxObservationBytes(tt.input.maxBytes).
+				WithMaxCombinedObservationBytes(tt.input.maxCombined)
+			for name, size := range map[string]int{"small": 10, "medium": 40, "large": 60} {
+				output := strings.Repeat(name[:1], size)
+				tc.RegisterTool(gent.NewToolFunc(
+					name, "Return "+name+" output", nil,
+					func(_ context.Context, _ map[string]any) (string, error) {
+						return output, nil
+					},
+				))
+			}
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+
+			result, err := tc.Execute(execCtx,
+				"- tool: small\n  args: {}\n- tool: medium\n  args: {}\n"+
+					"- tool: large\n  args: {}", testFormat())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected.text, result.Text)
+			assert.Equal(t, strings.Repeat("l", 60), result.Raw.Results[2].Output)
+		})
+	}
+}
+
+func TestYAML_Execute_MaxCombinedObservationBytes_AcrossActions(t *testing.T) {
+	tc := NewYAML().WithMaxCombinedObservationBytes(60)
+	tc.RegisterTool(gent.NewToolFunc(
+		"read", "Read a file", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			return strings.Repeat("r", 40), nil
+		},
+	))
+	tc.RegisterTool(gent.NewToolFunc(
+		"fail", "Always fail", nil,
+		func(_ context.Context, _ map[string]any) (string, error) {
+			return "", errors.New(strings.Repeat("e", 40))
+		},
+	))
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	execCtx.IncrementIteration()
+
+	first, err := tc.Execute(execCtx, "- tool: read\n  args: {}", testFormat())
+	require.NoError(t, err)
+	second, err := tc.Execute(execCtx,
+		"- tool: fail\n  args: {}\n- tool: read\n  args: {}", testFormat())
+	require.NoError(t, err)
+
+	// The second action only gets the 20 bytes the first left: its output is cut down
+	// to its marker, and the error is kept whole
+	assert.Equal(t, "<read>\n"+strings.Repeat("r", 40)+"\n</read>", first.Text)
+	assert.Equal(t, "<fail>\nError: "+strings.Repeat("e", 40)+"\n</fail>\n"+
+		"<read>\n\n[truncated 40 of 40 bytes]\n</read>", second.Text)
+	assert.Equal(t, gent.IterationToolUsage{Calls: 3, ObservationBytes: 67},
+		execCtx.IterationToolUsage())
+}
+
+func TestYAML_Execute_ToolProgress(t *testing.T) {
+	tc := NewYAML()
+	for _, name := range []string{"index", "export"} {
+		tc.RegisterTool(gent.NewToolFunc(
+			name, "Run a long job", nil,
+			func(ctx context.Context, _ map[string]any) (string, error) {
+				gent.ReportToolProgress(ctx, 50, "halfway")
+				gent.ReportToolProgress(ctx, 100, "done")
+				return "ok", nil
+			},
+		))
+	}
+
+	execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+	_, err := tc.Execute(
+		execCtx, "- tool: index\n  args: {}\n- tool: export\n  args: {}", testFormat())
+	require.NoError(t, err)
+
+	var progress []string
+	for _, event := range execCtx.Events() {
+		if e, ok := event.(*gent.ToolProgressEvent); ok {
+			progress = append(progress, fmt.Sprintf("%s %.0f %s", e.ToolName, e.Percent, e.Message))
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"index 50 halfway", "index 100 done", "export 50 halfway", "export 100 done",
+	}, progress)
+	assert.Equal(t, int64(2), execCtx.Stats().GetCounter(gent.SCToolCalls))
+}
+
+func TestYAML_Execute_MaxObservationBytes(t *testing.T) {
+	type input struct {
+		maxBytes  int
+		overrides map[string]int
+	}
+
+	type expected struct {
+		text string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "no limit by default",
+			input: input{},
+			expected: expected{
+				text: "<fetch>\nabcdefghij\n</fetch>\n<status>\nok\n</status>",
 			},
 		},
 		{
-			name: "multiple tools",
-			mocks: []mockTool{
-				{
-					name:        "search",
-					description: "Search",
-					fn: func(ctx context.Context, args map[string]any) (string, error) {
-						return "search result", nil
-					},
-				},
-				{
-					name:        "calendar",
-					description: "Calendar",
-					fn: func(ctx context.Context, args map[string]any) (string, error) {
-						return "calendar result", nil
-					},
-				},
+			name:  "global limit truncates every tool",
+			input: input{maxBytes: 4},
+			expected: expected{
+				text: "<fetch>\nabcd\n[truncated 6 of 10 bytes]\n</fetch>\n" +
+					"<status>\nok\n</status>",
 			},
-			input: input{
-				content: `- tool: search
-  args: {}
-- tool: calendar
-  args: {}`,
+		},
+		{
+			name:  "per-tool override takes precedence",
+			input: input{maxBytes: 4, overrides: map[string]int{"fetch": 8}},
+			expected: expected{
+				text: "<fetch>\nabcdefgh\n[truncated 2 of 10 bytes]\n</fetch>\n" +
+					"<status>\nok\n</status>",
 			},
+		},
+		{
+			name:  "per-tool override of zero disables truncation",
+			input: input{maxBytes: 4, overrides: map[string]int{"fetch": 0}},
 			expected: expected{
-				callCount:   2,
-				results:     []string{"search result", "calendar result"},
-				errors:      []error{nil, nil},
-				resultNames: []string{"search", "calendar"},
+				text: "<fetch>\nabcdefghij\n</fetch>\n<status>\nok\n</status>",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tc := NewYAML()
-			for _, mock := range tt.mocks {
-				tool := gent.NewToolFunc(
-					mock.name,
-					mock.description,
-					mock.schema,
-					mock.fn,
-				)
-				tc.RegisterTool(tool)
-			}
-
-			result, err := tc.Execute(nil, tt.input.content, yamlTestFormat())
-
-			if tt.expected.executeErr != nil {
-				assert.ErrorIs(t, err, tt.expected.executeErr)
-				return
+			tc := NewYAML().WithMaxObservationBytes(tt.input.maxBytes)
+			for name, n := range tt.input.overrides {
+				tc.WithToolMaxObservationBytes(name, n)
 			}
+			tc.RegisterTool(gent.NewToolFunc(
+				"fetch", "Fetch a document", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "abcdefghij", nil
+				},
+			))
+			tc.RegisterTool(gent.NewToolFunc(
+				"status", "Get status", nil,
+				func(_ context.Context, _ map[string]any) (string, error) {
+					return "ok", nil
+				},
+			))
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
 
+			result, err := tc.Execute(
+				execCtx, "- tool: fetch\n  args: {}\n- tool: status\n  args: {}", testFormat())
 			require.NoError(t, err)
-			assert.Len(t, result.Raw.Calls, tt.expected.callCount)
 
-			for i, expectedResult := range tt.expected.results {
-				if tt.expected.errors[i] != nil {
-					assert.Error(t, result.Raw.Errors[i])
-					if errors.Is(tt.expected.errors[i], gent.ErrUnknownTool) {
-						assert.ErrorIs(t, result.Raw.Errors[i], gent.ErrUnknownTool)
-					} else {
-						assert.Equal(t, tt.expected.errors[i].Error(), result.Raw.Errors[i].Error())
-					}
-				} else {
-					assert.NoError(t, result.Raw.Errors[i])
-					assert.Equal(t, expectedResult, getYAMLOutputString(result.Raw.Results[i].Output))
-					if len(tt.expected.resultNames) > i {
-						assert.Equal(t, tt.expected.resultNames[i], result.Raw.Results[i].Name)
-					}
+			assert.Equal(t, tt.expected.text, result.Text)
+
+			// Hooks and raw results still see the full output
+			assert.Equal(t, "abcdefghij", result.Raw.Results[0].Output)
+			var afterEvents []*gent.AfterToolCallEvent
+			for _, event := range execCtx.Events() {
+				if after, ok := event.(*gent.AfterToolCallEvent); ok {
+					afterEvents = append(afterEvents, after)
 				}
 			}
+			require.Len(t, afterEvents, 2)
+			assert.Equal(t, "abcdefghij", afterEvents[0].Output)
 		})
 	}
 }
@@ -752,6 +1485,103 @@ args:
 	}
 }
 
+func TestYAML_ParseSection_BlockScalars(t *testing.T) {
+	type expected struct {
+		body string
+		to   string
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name: "literal keeps newlines and one trailing newline",
+			input: "tool: send_email\nargs:\n  body: |\n    Subject: hi\n    to: someone\n\n" +
+				"    Thanks,\n    Ann\n  to: ann@example.com",
+			expected: expected{
+				body: "Subject: hi\nto: someone\n\nThanks,\nAnn\n",
+				to:   "ann@example.com",
+			},
+		},
+		{
+			name:  "literal at end of content keeps trailing newline",
+			input: "tool: send_email\nargs:\n  to: ann@example.com\n  body: |\n    Subject: hi\n    Hello",
+			expected: expected{
+				body: "Subject: hi\nHello\n",
+				to:   "ann@example.com",
+			},
+		},
+		{
+			name: "literal strip removes trailing newline",
+			input: "tool: send_email\nargs:\n  body: |-\n    Subject: hi\n      - indented: item\n" +
+				"    Hello\n\n  to: ann@example.com",
+			expected: expected{
+				body: "Subject: hi\n  - indented: item\nHello",
+				to:   "ann@example.com",
+			},
+		},
+		{
+			name: "folded joins lines and keeps paragraphs",
+			input: "tool: send_email\nargs:\n  body: >\n    Subject: hi\n    Hello there\n\n" +
+				"    Bye\n  to: ann@example.com",
+			expected: expected{
+				body: "Subject: hi Hello there\nBye\n",
+				to:   "ann@example.com",
+			},
+		},
+		{
+			name: "folded strip removes trailing newline",
+			input: "tool: send_email\nargs:\n  body: >-\n    Subject: hi\n    Hello there\n" +
+				"  to: ann@example.com",
+			expected: expected{
+				body: "Subject: hi Hello there",
+				to:   "ann@example.com",
+			},
+		},
+		{
+			name: "indented section content",
+			input: "\n    tool: send_email\n    args:\n      to: ann@example.com\n" +
+				"      body: |\n        Subject: hi\n        Hello\n    ",
+			expected: expected{
+				body: "Subject: hi\nHello\n",
+				to:   "ann@example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML()
+			tc.RegisterTool(gent.NewToolFunc(
+				"send_email",
+				"Send an email",
+				map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"to":   map[string]any{"type": "string"},
+						"body": map[string]any{"type": "string"},
+					},
+				},
+				func(ctx context.Context, args map[string]any) (string, error) {
+					return "sent", nil
+				},
+			))
+
+			result, err := tc.ParseSection(nil, tt.input)
+			require.NoError(t, err)
+
+			calls := result.([]*gent.ToolCall)
+			require.Len(t, calls, 1)
+			assert.Equal(t, map[string]any{
+				"body": tt.expected.body,
+				"to":   tt.expected.to,
+			}, calls[0].Args)
+		})
+	}
+}
+
 func TestYAML_ParseSection_DateAsString(t *testing.T) {
 	type input struct {
 		content string
@@ -1384,6 +2214,58 @@ func TestYAML_AvailableToolsPrompt_SchemaFeatures(t *testing.T) {
 	}
 }
 
+func TestYAML_AvailableToolsPrompt_SortedKeys(t *testing.T) {
+	// Properties are declared out of order; the catalog lists them sorted, so the
+	// prompt (and any cached prefix containing it) is byte-identical across runs
+	tc := NewYAML()
+	tc.RegisterTool(gent.NewToolFunc(
+		"create_order",
+		"Create an order",
+		schema.Object(map[string]*schema.Property{
+			"zeta":     schema.String("Last alphabetically"),
+			"alpha":    schema.Integer("First alphabetically"),
+			"customer": schema.String("Customer ID"),
+			"items":    schema.Array("Line items", map[string]any{"type": "string"}),
+			"priority": schema.String("Priority").Enum("low", "high"),
+		}, "customer", "items"),
+		func(ctx context.Context, args map[string]any) (string, error) {
+			return "", nil
+		},
+	))
+
+	expectedCatalog := `Available tools:
+
+- create_order: Create an order
+  Parameters:
+    properties:
+        alpha:
+            description: First alphabetically
+            type: integer
+        customer:
+            description: Customer ID
+            type: string
+        items:
+            description: Line items
+            items:
+                type: string
+            type: array
+        priority:
+            description: Priority
+            enum:
+                - low
+                - high
+            type: string
+        zeta:
+            description: Last alphabetically
+            type: string
+    required:
+        - customer
+        - items
+    type: object
+`
+	assert.Equal(t, expectedCatalog, tc.AvailableToolsPrompt())
+}
+
 func TestYAML_AvailableToolsPrompt_MultipleTools(t *testing.T) {
 	type mockTool struct {
 		name        string
@@ -1405,47 +2287,139 @@ func TestYAML_AvailableToolsPrompt_MultipleTools(t *testing.T) {
 		expected expected
 	}{
 		{
-			name: "two tools",
+			name: "two tools",
+			input: input{
+				tools: []mockTool{
+					{
+						name:        "search",
+						description: "Search for information",
+						schema: schema.Object(map[string]*schema.Property{
+							"query": schema.String("Search query"),
+						}, "query"),
+					},
+					{
+						name:        "calculate",
+						description: "Perform calculations",
+						schema: schema.Object(map[string]*schema.Property{
+							"expression": schema.String("Mathematical expression"),
+						}, "expression"),
+					},
+				},
+			},
+			expected: expected{
+				catalog: `Available tools:
+
+- search: Search for information
+  Parameters:
+    properties:
+        query:
+            description: Search query
+            type: string
+    required:
+        - query
+    type: object
+
+- calculate: Perform calculations
+  Parameters:
+    properties:
+        expression:
+            description: Mathematical expression
+            type: string
+    required:
+        - expression
+    type: object
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewYAML()
+			for _, mock := range tt.input.tools {
+				tool := gent.NewToolFunc(
+					mock.name,
+					mock.description,
+					mock.schema,
+					func(ctx context.Context, input map[string]any) (string, error) {
+						return "ok", nil
+					},
+				)
+				tc.RegisterTool(tool)
+			}
+
+			catalog := tc.AvailableToolsPrompt()
+
+			assert.Equal(t, tt.expected.catalog, catalog)
+		})
+	}
+}
+
+func TestYAML_AvailableToolsPrompt_Categories(t *testing.T) {
+	type mockTool struct {
+		name     string
+		category string
+	}
+
+	type input struct {
+		tools     []mockTool
+		available []string
+	}
+
+	type expected struct {
+		catalog string
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "uncategorized first, then categories in registration order",
+			input: input{
+				tools: []mockTool{
+					{name: "refund", category: "billing"},
+					{name: "lookup"},
+					{name: "track", category: "shipping"},
+					{name: "invoice", category: "billing"},
+				},
+			},
+			expected: expected{
+				catalog: `Available tools:
+
+- lookup: lookup tool
+
+Category: billing
+
+- refund: refund tool
+
+- invoice: invoice tool
+
+Category: shipping
+
+- track: track tool
+`,
+			},
+		},
+		{
+			name: "unavailable tools and empty categories are left out",
 			input: input{
 				tools: []mockTool{
-					{
-						name:        "search",
-						description: "Search for information",
-						schema: schema.Object(map[string]*schema.Property{
-							"query": schema.String("Search query"),
-						}, "query"),
-					},
-					{
-						name:        "calculate",
-						description: "Perform calculations",
-						schema: schema.Object(map[string]*schema.Property{
-							"expression": schema.String("Mathematical expression"),
-						}, "expression"),
-					},
+					{name: "refund", category: "billing"},
+					{name: "lookup"},
+					{name: "track", category: "shipping"},
 				},
+				available: []string{"lookup", "track", "unknown"},
 			},
 			expected: expected{
 				catalog: `Available tools:
 
-- search: Search for information
-  Parameters:
-    properties:
-        query:
-            description: Search query
-            type: string
-    required:
-        - query
-    type: object
+- lookup: lookup tool
 
-- calculate: Perform calculations
-  Parameters:
-    properties:
-        expression:
-            description: Mathematical expression
-            type: string
-    required:
-        - expression
-    type: object
+Category: shipping
+
+- track: track tool
 `,
 			},
 		},
@@ -1455,20 +2429,18 @@ func TestYAML_AvailableToolsPrompt_MultipleTools(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tc := NewYAML()
 			for _, mock := range tt.input.tools {
-				tool := gent.NewToolFunc(
+				tc.RegisterTool(gent.NewToolFunc(
 					mock.name,
-					mock.description,
-					mock.schema,
+					mock.name+" tool",
+					nil,
 					func(ctx context.Context, input map[string]any) (string, error) {
 						return "ok", nil
 					},
-				)
-				tc.RegisterTool(tool)
+				).WithCategory(mock.category))
 			}
+			tc.SetAvailableTools(tt.input.available)
 
-			catalog := tc.AvailableToolsPrompt()
-
-			assert.Equal(t, tt.expected.catalog, catalog)
+			assert.Equal(t, tt.expected.catalog, tc.AvailableToolsPrompt())
 		})
 	}
 }
@@ -1984,6 +2956,73 @@ func (r *yamlTestRegistry) MaxRecursion() int {
 	return 10
 }
 
+func TestYAML_ParseSection_ErrorLocation(t *testing.T) {
+	type expected struct {
+		sentinel error
+		line     int
+		column   int
+		expected []string
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected expected
+	}{
+		{
+			name:  "unclosed flow sequence in indented content",
+			input: "\n  tool: search\n  args:\n    query: [a, b\n",
+			expected: expected{
+				sentinel: gent.ErrInvalidYAML,
+				line:     4,
+				column:   3,
+				expected: []string{"','", "']'"},
+			},
+		},
+		{
+			name:  "scanner error",
+			input: "tool: search\n args: x\n",
+			expected: expected{
+				sentinel: gent.ErrInvalidYAML,
+				line:     2,
+				column:   1,
+			},
+		},
+		{
+			name:  "tool call without tool name",
+			input: "- tool: search\n- args: {}\n",
+			expected: expected{
+				sentinel: gent.ErrMissingToolName,
+				line:     2,
+				column:   3,
+			},
+		},
+		{
+			name:  "scalar instead of tool call",
+			input: "  search the web",
+			expected: expected{
+				sentinel: gent.ErrInvalidYAML,
+				line:     1,
+				column:   3,
+				expected: []string{"mapping", "sequence"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewYAML().ParseSection(nil, tc.input)
+
+			assert.ErrorIs(t, err, tc.expected.sentinel)
+			var parseErr *gent.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tc.expected.line, parseErr.Line)
+			assert.Equal(t, tc.expected.column, parseErr.Column)
+			assert.Equal(t, tc.expected.expected, parseErr.Expected)
+		})
+	}
+}
+
 func TestYAML_ParseSection_TracesErrors(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2469,3 +3508,372 @@ func TestYAML_UnknownToolErrorMessage(t *testing.T) {
 			`section for valid tool names.`,
 	)
 }
+
+func TestYAML_Execute_UnknownToolHandler(t *testing.T) {
+	type input struct {
+		content        string
+		handler        UnknownToolHandler
+		availableTools []string
+	}
+
+	type expected struct {
+		output      any
+		err         error
+		textContain string
+		unknown     int64
+		errorTotal  int64
+	}
+
+	observe := func(ctx context.Context, name string, args map[string]any) (string, error) {
+		return fmt.Sprintf("no such tool %s (args: %v); available: echo", name, args), nil
+	}
+	decline := func(ctx context.Context, name string, args map[string]any) (string, error) {
+		return "", errors.New("declined")
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name: "without handler unknown tool fails",
+			input: input{
+				content: "tool: search_web\nargs:\n  q: go",
+			},
+			expected: expected{
+				err:         gent.ErrUnknownTool,
+				textContain: `Error: unknown tool "search_web".`,
+				unknown:     1,
+				errorTotal:  1,
+			},
+		},
+		{
+			name: "handler observation is returned",
+			input: input{
+				content: "tool: search_web\nargs:\n  q: go",
+				handler: observe,
+			},
+			expected: expected{
+				output:      "no such tool search_web (args: map[q:go]); available: echo",
+				textContain: "no such tool search_web (args: map[q:go]); available: echo",
+				unknown:     1,
+			},
+		},
+		{
+			name: "handler error falls back to unknown tool error",
+			input: input{
+				content: "tool: search_web\nargs:\n  q: go",
+				handler: decline,
+			},
+			expected: expected{
+				err:         gent.ErrUnknownTool,
+				textContain: `Error: unknown tool "search_web".`,
+				unknown:     1,
+				errorTotal:  1,
+			},
+		},
+		{
+			name: "unavailable registered tool goes to handler",
+			input: input{
+				content:        "tool: echo\nargs:\n  q: go",
+				handler:        observe,
+				availableTools: []string{},
+			},
+			expected: expected{
+				output:      "no such tool echo (args: map[q:go]); available: echo",
+				textContain: "no such tool echo (args: map[q:go]); available: echo",
+				unknown:     1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewYAML().WithUnknownToolHandler(tc.input.handler)
+			chain.RegisterTool(gent.NewToolFunc(
+				"echo", "Echoes its input", nil,
+				func(ctx context.Context, args map[string]any) (string, error) {
+					return "echo", nil
+				},
+			))
+			if tc.input.availableTools != nil {
+				chain.SetAvailableTools(tc.input.availableTools)
+			}
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+
+			result, err := chain.Execute(execCtx, tc.input.content, yamlTestFormat())
+			require.NoError(t, err)
+
+			require.Len(t, result.Raw.Errors, 1)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, result.Raw.Errors[0], tc.expected.err)
+				assert.Nil(t, result.Raw.Results[0])
+			} else {
+				assert.NoError(t, result.Raw.Errors[0])
+				require.NotNil(t, result.Raw.Results[0])
+				assert.Equal(t, tc.expected.output, result.Raw.Results[0].Output)
+			}
+			assert.Contains(t, result.Text, tc.expected.textContain)
+
+			// Unknown calls are only counted as unknown, never as calls to a tool
+			stats := execCtx.Stats()
+			assert.Equal(t, tc.expected.unknown, stats.GetCounter(gent.SCToolCallsUnknown))
+			assert.Equal(t, int64(0), stats.GetCounter(gent.SCToolCalls))
+			for key := range stats.Counters() {
+				assert.NotContains(t, key, string(gent.SCToolCallsFor))
+			}
+			assert.Equal(t, float64(0), stats.GetGauge(gent.SGDistinctToolsUsed))
+			assert.Equal(t, tc.expected.errorTotal,
+				stats.GetCounter(gent.SCToolCallsErrorTotal))
+		})
+	}
+}
+
+func TestYAML_Execute_UnavailableToolMessage(t *testing.T) {
+	type input struct {
+		content string
+		message UnavailableToolMessage
+		handler UnknownToolHandler
+	}
+
+	type expected struct {
+		err         error
+		textContain string
+		unknown     int64
+		unavailable int64
+	}
+
+	notVerified := func(name string) string {
+		return name + " is not available until the customer is verified."
+	}
+	observe := func(ctx context.Context, name string, args map[string]any) (string, error) {
+		return "handled " + name, nil
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:  "without message filtered tool is unknown",
+			input: input{content: "tool: refund\nargs:\n  q: go"},
+			expected: expected{
+				err:         gent.ErrUnknownTool,
+				textContain: `Error: unknown tool "refund".`,
+				unknown:     1,
+			},
+		},
+		{
+			name: "filtered tool gets the message",
+			input: input{
+				content: "tool: refund\nargs:\n  q: go",
+				message: notVerified,
+			},
+			expected: expected{
+				err:         gent.ErrToolUnavailable,
+				textContain: "refund is not available until the customer is verified.",
+				unavailable: 1,
+			},
+		},
+		{
+			name: "message takes precedence over unknown tool handler",
+			input: input{
+				content: "tool: refund\nargs:\n  q: go",
+				message: notVerified,
+				handler: observe,
+			},
+			expected: expected{
+				err:         gent.ErrToolUnavailable,
+				textContain: "refund is not available until the customer is verified.",
+				unavailable: 1,
+			},
+		},
+		{
+			name: "unregistered tool stays unknown",
+			input: input{
+				content: "tool: search_web\nargs:\n  q: go",
+				message: notVerified,
+			},
+			expected: expected{
+				err:         gent.ErrUnknownTool,
+				textContain: `Error: unknown tool "search_web".`,
+				unknown:     1,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewYAML().
+				WithUnavailableToolMessage(tc.input.message).
+				WithUnknownToolHandler(tc.input.handler)
+			chain.RegisterTool(gent.NewToolFunc(
+				"refund", "Refunds an order", nil,
+				func(ctx context.Context, args map[string]any) (string, error) {
+					return "refunded", nil
+				},
+			))
+			chain.SetAvailableTools([]string{})
+			execCtx := gent.NewExecutionContext(context.Background(), "test", nil)
+			execCtx.IncrementIteration()
+
+			result, err := chain.Execute(execCtx, tc.input.content, yamlTestFormat())
+			require.NoError(t, err)
+
+			require.Len(t, result.Raw.Errors, 1)
+			assert.ErrorIs(t, result.Raw.Errors[0], tc.expected.err)
+			assert.Contains(t, result.Text, tc.expected.textContain)
+
+			stats := execCtx.Stats()
+			assert.Equal(t, int64(1), stats.GetCounter(gent.SCToolCallsErrorTotal))
+			assert.Equal(t, tc.expected.unknown, stats.GetCounter(gent.SCToolCallsUnknown))
+			assert.Equal(t, tc.expected.unavailable,
+				stats.GetCounter(gent.SCToolCallsUnavailable))
+		})
+	}
+}
+
+func TestYAML_ParseSection_SchemaScalars(t *testing.T) {
+	toolSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"enabled": map[string]any{"type": "boolean"},
+			"name":    map[string]any{"type": "string"},
+			"nick":    map[string]any{"type": "string"},
+			"count":   map[string]any{"type": "integer"},
+			"note":    map[string]any{"type": []any{"string", "null"}},
+			"limit":   map[string]any{"type": []string{"integer", "null"}},
+		},
+		"required": []any{"enabled", "name"},
+	}
+
+	type input struct {
+		args string
+	}
+
+	type expected struct {
+		args map[string]any
+		err  error
+	}
+
+	tests := []struct {
+		name     string
+		input    input
+		expected expected
+	}{
+		{
+			name:     "yes is true",
+			input:    input{args: "enabled: yes\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "a"}},
+		},
+		{
+			name:     "no is false",
+			input:    input{args: "enabled: no\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": false, "name": "a"}},
+		},
+		{
+			name:     "on is true",
+			input:    input{args: "enabled: on\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "a"}},
+		},
+		{
+			name:     "OFF is false",
+			input:    input{args: "enabled: OFF\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": false, "name": "a"}},
+		},
+		{
+			name:     "True is true",
+			input:    input{args: "enabled: True\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "a"}},
+		},
+		{
+			name:     "quoted yes is true",
+			input:    input{args: "enabled: \"yes\"\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "a"}},
+		},
+		{
+			name:     "unrecognized boolean word is left for validation",
+			input:    input{args: "enabled: maybe\n  name: a"},
+			expected: expected{args: map[string]any{"enabled": "maybe", "name": "a"}},
+		},
+		{
+			name:     "yes in a string field stays a string",
+			input:    input{args: "enabled: true\n  name: yes"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "yes"}},
+		},
+		{
+			name:  "null into nullable type array is nil",
+			input: input{args: "enabled: true\n  name: a\n  note: null"},
+			expected: expected{
+				args: map[string]any{"enabled": true, "name": "a", "note": nil},
+			},
+		},
+		{
+			name:  "tilde into nullable property is nil",
+			input: input{args: "enabled: true\n  name: a\n  limit: ~"},
+			expected: expected{
+				args: map[string]any{"enabled": true, "name": "a", "limit": nil},
+			},
+		},
+		{
+			name:     "null into optional non-nullable property is dropped",
+			input:    input{args: "enabled: true\n  name: a\n  count: null"},
+			expected: expected{args: map[string]any{"enabled": true, "name": "a"}},
+		},
+		{
+			name:     "null into required string property is an error",
+			input:    input{args: "enabled: true\n  name: null"},
+			expected: expected{err: gent.ErrInvalidToolArgs},
+		},
+		{
+			name:     "empty required boolean is an error",
+			input:    input{args: "enabled:\n  name: a"},
+			expected: expected{err: gent.ErrInvalidToolArgs},
+		},
+		{
+			name:     "quoted null is a string",
+			input:    input{args: "enabled: true\n  name: \"null\""},
+			expected: expected{args: map[string]any{"enabled": true, "name": "null"}},
+		},
+		{
+			name:  "empty string property is the empty string",
+			input: input{args: "enabled: true\n  name: a\n  nick:"},
+			expected: expected{
+				args: map[string]any{"enabled": true, "name": "a", "nick": ""},
+			},
+		},
+		{
+			name:  "null into property missing from the schema is nil",
+			input: input{args: "enabled: true\n  name: a\n  extra: null"},
+			expected: expected{
+				args: map[string]any{"enabled": true, "name": "a", "extra": nil},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := NewYAML()
+			chain.RegisterTool(gent.NewToolFunc(
+				"configure", "Configure", toolSchema,
+				func(ctx context.Context, args map[string]any) (string, error) {
+					return "", nil
+				},
+			))
+
+			parsed, err := chain.ParseSection(nil, "tool: configure\nargs:\n  "+tc.input.args)
+
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				return
+			}
+			require.NoError(t, err)
+			calls := parsed.([]*gent.ToolCall)
+			require.Len(t, calls, 1)
+			assert.Equal(t, tc.expected.args, calls[0].Args)
+		})
+	}
+}
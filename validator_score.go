@@ -0,0 +1,39 @@
+package gent
+
+// PublishScoredValidatorResult publishes a ValidatorResultEvent like
+// PublishValidatorResult, carrying the validator's score (see ValidationResult.Score).
+// Terminations enforcing a minimum score use it instead of PublishValidatorResult.
+// Stats updated: as PublishValidatorResult, plus SGValidatorScoreLast and
+// SGValidatorScoreAvg.
+func (ctx *ExecutionContext) PublishScoredValidatorResult(
+	validatorName string,
+	answer any,
+	accepted bool,
+	feedback []FormattedSection,
+	score float64,
+) *ValidatorResultEvent {
+	event := &ValidatorResultEvent{
+		BaseEvent:     BaseEvent{EventName: EventNameValidatorResult},
+		ValidatorName: validatorName,
+		Answer:        answer,
+		Accepted:      accepted,
+		Feedback:      feedback,
+		Scored:        true,
+		Score:         score,
+	}
+	ctx.publish(event)
+	return event
+}
+
+// recordValidatorScore updates SGValidatorScoreLast and the running mean in
+// SGValidatorScoreAvg.
+func (ctx *ExecutionContext) recordValidatorScore(score float64) {
+	var count int
+	ctx.updateContextState(func() {
+		ctx.validatorScores++
+		count = ctx.validatorScores
+	})
+	avg := ctx.stats.GetGauge(SGValidatorScoreAvg)
+	ctx.stats.SetGauge(SGValidatorScoreLast, score)
+	ctx.stats.SetGauge(SGValidatorScoreAvg, avg+(score-avg)/float64(count))
+}